@@ -0,0 +1,433 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// An Annotation changes how the parameters or results of a function given
+// to Annotate are resolved.
+type Annotation interface {
+	fmt.Stringer
+
+	applyAnnotation(*annotated) error
+}
+
+// Annotate returns a function that may be given to Provide, Decorate,
+// Invoke or RegisterInvoke in place of f, with names, value groups and
+// optional flags attached to its parameters and results. This avoids
+// declaring dig.In and dig.Out structs for plain functions.
+//
+//	c.Provide(dig.Annotate(NewServer,
+//		dig.ParamTags(`name:"addr"`, `optional:"true"`),
+//		dig.ResultTags(`group:"servers"`),
+//	))
+//
+// is equivalent to
+//
+//	type serverParams struct {
+//		dig.In
+//
+//		Addr   string  `name:"addr"`
+//		Logger *Logger `optional:"true"`
+//	}
+//
+//	type serverResult struct {
+//		dig.Out
+//
+//		Server *Server `group:"servers"`
+//	}
+//
+//	c.Provide(func(p serverParams) serverResult {
+//		return serverResult{Server: NewServer(p.Addr, p.Logger)}
+//	})
+//
+// Errors in the annotations are reported when the result is used.
+func Annotate(f interface{}, anns ...Annotation) interface{} {
+	a := annotated{Target: f}
+	for _, ann := range anns {
+		if err := ann.applyAnnotation(&a); err != nil && a.err == nil {
+			a.err = err
+		}
+	}
+	return a
+}
+
+// annotated is a function annotated with Annotate.
+type annotated struct {
+	Target     interface{}
+	ParamTags  []string
+	ResultTags []string
+
+	// Value group filling the variadic parameter, if set with Variadic.
+	Variadic *string
+
+	// Names of the keys of the parameters annotated with KeyedParam, by
+	// index.
+	KeyedParams map[int]string
+
+	// Types the results are provided as with ResultAs, by index of the
+	// result. A nil type stands for the type of the result.
+	ResultAs [][]reflect.Type
+
+	// Location reported for Target if it was synthesized by dig, as with
+	// Struct. Errors building such functions are not annotation errors.
+	Location *digreflect.Func
+
+	// First error reported by the annotations.
+	err error
+}
+
+func (a annotated) String() string {
+	return fmt.Sprintf("Annotate(%v)", a.location())
+}
+
+func (a annotated) location() *digreflect.Func {
+	if a.Location != nil {
+		return a.Location
+	}
+	if t := reflect.TypeOf(a.Target); t == nil || t.Kind() != reflect.Func {
+		return nil
+	}
+	return digreflect.InspectFunc(a.Target)
+}
+
+// ParamTags is an Annotation that attaches struct tags to the parameters
+// of the annotated function, in order. The tags are those of the fields of
+// dig.In structs, like `name:".."`, `group:".."` or `optional:"true"`.
+// Parameters after the last tag are left untagged.
+//
+// The variadic parameter of a function, if it has one, is resolved as a
+// slice: tagging it with a value group passes the values of the group.
+func ParamTags(tags ...string) Annotation {
+	return paramTagsAnnotation(tags)
+}
+
+type paramTagsAnnotation []string
+
+func (o paramTagsAnnotation) String() string {
+	return fmt.Sprintf("ParamTags(%q)", []string(o))
+}
+
+func (o paramTagsAnnotation) applyAnnotation(a *annotated) error {
+	if a.ParamTags != nil {
+		return newErrInvalidInput("cannot apply more than one ParamTags", nil)
+	}
+	a.ParamTags = append([]string{}, o...)
+	return nil
+}
+
+// ResultTags is an Annotation that attaches struct tags to the results of
+// the annotated function, in order, except for a trailing error. The tags
+// are those of the fields of dig.Out structs, like `name:".."` or
+// `group:".."`. Results after the last tag are left untagged.
+func ResultTags(tags ...string) Annotation {
+	return resultTagsAnnotation(tags)
+}
+
+type resultTagsAnnotation []string
+
+func (o resultTagsAnnotation) String() string {
+	return fmt.Sprintf("ResultTags(%q)", []string(o))
+}
+
+func (o resultTagsAnnotation) applyAnnotation(a *annotated) error {
+	if a.ResultTags != nil {
+		return newErrInvalidInput("cannot apply more than one ResultTags", nil)
+	}
+	a.ResultTags = append([]string{}, o...)
+	return nil
+}
+
+// Variadic is an Annotation that fills the variadic parameter of the
+// annotated function with the values of the named value group, instead of
+// leaving it empty.
+//
+//	// NewServer(log *Logger, opts ...Option) *Server
+//	c.Provide(dig.Annotate(NewServer, dig.Variadic("server_options")))
+//
+// The group may be given soft or tolerant flags as with the `group:".."`
+// tag, for example dig.Variadic("server_options,soft").
+func Variadic(group string) Annotation {
+	return variadicAnnotation(group)
+}
+
+type variadicAnnotation string
+
+func (o variadicAnnotation) String() string {
+	return fmt.Sprintf("Variadic(%q)", string(o))
+}
+
+func (o variadicAnnotation) applyAnnotation(a *annotated) error {
+	if a.Variadic != nil {
+		return newErrInvalidInput("cannot apply more than one Variadic", nil)
+	}
+	group := string(o)
+	a.Variadic = &group
+	return nil
+}
+
+// ResultAs is an Annotation that provides the results of the annotated
+// function as the given types instead of their own, like As does for the
+// result of a constructor. The nth type applies to the nth result, and each
+// type must be given as a pointer, like new(io.Reader), or as nil to keep
+// the type of the result. ResultAs may be given several times to provide
+// the results as several types.
+//
+//	// NewBuffer() *bytes.Buffer
+//	c.Provide(dig.Annotate(NewBuffer,
+//		dig.ResultAs(new(io.Reader)),
+//		dig.ResultAs(new(io.Writer)),
+//	))
+func ResultAs(types ...interface{}) Annotation {
+	return resultAsAnnotation(types)
+}
+
+type resultAsAnnotation []interface{}
+
+func (o resultAsAnnotation) String() string {
+	types := make([]string, len(o))
+	for i, t := range o {
+		types[i] = fmt.Sprint(reflect.TypeOf(t))
+	}
+	return fmt.Sprintf("ResultAs(%v)", strings.Join(types, ", "))
+}
+
+func (o resultAsAnnotation) applyAnnotation(a *annotated) error {
+	for i, iface := range o {
+		var t reflect.Type
+		if iface != nil {
+			t = reflect.TypeOf(iface)
+			if t.Kind() != reflect.Ptr {
+				return newErrInvalidInput(
+					fmt.Sprintf("argument %d of ResultAs must be a pointer to a type, got %v", i, t), nil)
+			}
+			t = t.Elem()
+		}
+
+		for len(a.ResultAs) <= i {
+			a.ResultAs = append(a.ResultAs, nil)
+		}
+		a.ResultAs[i] = append(a.ResultAs[i], t)
+	}
+	return nil
+}
+
+// unannotate returns the function to use in place of f, which may have
+// been returned by Annotate. The location of the annotated function is
+// returned as well, even if its annotations are invalid, or nil if f was
+// not an annotated function.
+func unannotate(f interface{}) (interface{}, *digreflect.Func, error) {
+	a, ok := f.(annotated)
+	if !ok {
+		return f, nil, nil
+	}
+
+	fn, err := a.build()
+	if err != nil {
+		if a.Location == nil {
+			err = newErrInvalidInput("invalid annotations", err)
+		}
+		return nil, a.location(), err
+	}
+	return fn, a.location(), nil
+}
+
+// build builds a function that calls the annotated function, taking a
+// dig.In struct whose fields are tagged with ParamTags, Variadic and KeyedParam,
+// and returning a dig.Out struct whose fields are tagged with ResultTags and
+// typed with ResultAs if there are any.
+func (a annotated) build() (interface{}, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	fv := reflect.ValueOf(a.Target)
+	if a.location() == nil {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("must annotate a function, got %v (type %T)", a.Target, a.Target), nil)
+	}
+	ft := fv.Type()
+
+	numResults := ft.NumOut()
+	returnsErr := numResults > 0 && isError(ft.Out(numResults-1))
+	if returnsErr {
+		numResults--
+	}
+	if len(a.ParamTags) > ft.NumIn() {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("got %d ParamTags for %d parameters", len(a.ParamTags), ft.NumIn()), nil)
+	}
+	if len(a.ResultTags) > numResults {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("got %d ResultTags for %d results", len(a.ResultTags), numResults), nil)
+	}
+
+	paramTags, err := a.paramTags(ft)
+	if err != nil {
+		return nil, err
+	}
+
+	var in, out reflect.Type
+	ins := make([]reflect.Type, ft.NumIn())
+	for i := range ins {
+		ins[i] = ft.In(i)
+	}
+	if paramTags != nil {
+		in = taggedStruct(_inType, ins, paramTags)
+		ins = []reflect.Type{in}
+	}
+
+	outs := make([]reflect.Type, ft.NumOut())
+	for i := range outs {
+		outs[i] = ft.Out(i)
+	}
+	var fields []int
+	if a.ResultTags != nil || a.ResultAs != nil {
+		out, fields, err = a.resultStruct(outs[:numResults])
+		if err != nil {
+			return nil, err
+		}
+		outs = append([]reflect.Type{out}, outs[numResults:]...)
+	}
+
+	wrapper := reflect.FuncOf(ins, outs, ft.IsVariadic() && in == nil)
+	return reflect.MakeFunc(wrapper, func(args []reflect.Value) []reflect.Value {
+		if in != nil {
+			params := args[0]
+			args = make([]reflect.Value, ft.NumIn())
+			for i := range args {
+				args[i] = params.Field(i + 1)
+			}
+		}
+
+		var results []reflect.Value
+		if ft.IsVariadic() {
+			results = fv.CallSlice(args)
+		} else {
+			results = fv.Call(args)
+		}
+		if out == nil {
+			return results
+		}
+
+		o := reflect.New(out).Elem()
+		for i, r := range fields {
+			o.Field(i + 1).Set(results[r])
+		}
+		return append([]reflect.Value{o}, results[numResults:]...)
+	}).Interface(), nil
+}
+
+// paramTags returns the tags of the parameters of the annotated function
+// of type ft: those of ParamTags, along with the tags implied by Variadic
+// and KeyedParam. It returns nil if no parameter is annotated.
+func (a annotated) paramTags(ft reflect.Type) ([]string, error) {
+	if a.Variadic == nil && len(a.KeyedParams) == 0 {
+		return a.ParamTags, nil
+	}
+
+	tags := make([]string, ft.NumIn())
+	copy(tags, a.ParamTags)
+	if a.Variadic != nil {
+		if !ft.IsVariadic() {
+			return nil, newErrInvalidInput(
+				fmt.Sprintf("cannot use Variadic with non-variadic function %v", ft), nil)
+		}
+		last := ft.NumIn() - 1
+		if tags[last] != "" {
+			return nil, newErrInvalidInput(
+				"cannot use Variadic with a ParamTags tag on the variadic parameter", nil)
+		}
+		tags[last] = fmt.Sprintf("group:%q", *a.Variadic)
+	}
+	for i, name := range a.KeyedParams {
+		if i >= ft.NumIn() {
+			return nil, newErrInvalidInput(
+				fmt.Sprintf("cannot use KeyedParam with parameter %d of %d", i, ft.NumIn()), nil)
+		}
+		if tags[i] != "" {
+			return nil, newErrInvalidInput(
+				fmt.Sprintf("cannot use KeyedParam with a tagged parameter %d", i), nil)
+		}
+		tags[i] = fmt.Sprintf("name:%q", name)
+	}
+	return tags, nil
+}
+
+// resultStruct returns the dig.Out struct holding the given results of the
+// annotated function, tagged with ResultTags and typed with ResultAs, along
+// with the index of the result held by each of its fields.
+func (a annotated) resultStruct(results []reflect.Type) (reflect.Type, []int, error) {
+	if len(a.ResultAs) > len(results) {
+		return nil, nil, newErrInvalidInput(
+			fmt.Sprintf("got %d types in ResultAs for %d results", len(a.ResultAs), len(results)), nil)
+	}
+
+	var (
+		types  []reflect.Type
+		tags   []string
+		fields []int
+	)
+	for i, rt := range results {
+		as := []reflect.Type{rt}
+		if i < len(a.ResultAs) && len(a.ResultAs[i]) > 0 {
+			as = a.ResultAs[i]
+		}
+
+		var tag string
+		if i < len(a.ResultTags) {
+			tag = a.ResultTags[i]
+		}
+		for _, t := range as {
+			if t == nil {
+				t = rt
+			}
+			if !rt.AssignableTo(t) {
+				return nil, nil, newErrInvalidInput(
+					fmt.Sprintf("result %d of type %v cannot be provided as %v", i, rt, t), nil)
+			}
+			types = append(types, t)
+			tags = append(tags, tag)
+			fields = append(fields, i)
+		}
+	}
+	return taggedStruct(_outType, types, tags), fields, nil
+}
+
+// taggedStruct returns a struct type embedding embed, followed by fields of
+// the given types, tagged in order with the given tags.
+func taggedStruct(embed reflect.Type, types []reflect.Type, tags []string) reflect.Type {
+	fields := []reflect.StructField{{Name: embed.Name(), Type: embed, Anonymous: true}}
+	for i, t := range types {
+		f := reflect.StructField{Name: fmt.Sprintf("Field%d", i), Type: t}
+		if i < len(tags) {
+			f.Tag = reflect.StructTag(tags[i])
+		}
+		fields = append(fields, f)
+	}
+	return reflect.StructOf(fields)
+}
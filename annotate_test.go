@@ -0,0 +1,362 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+
+	type Server struct {
+		Addr    string
+		Verbose bool
+	}
+
+	newServer := func(addr string, verbose *bool) *Server {
+		s := &Server{Addr: addr}
+		if verbose != nil {
+			s.Verbose = *verbose
+		}
+		return s
+	}
+
+	t.Run("param and result tags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return ":8080" }, dig.Name("addr"))
+		c.RequireProvide(dig.Annotate(newServer,
+			dig.ParamTags(`name:"addr"`, `optional:"true"`),
+			dig.ResultTags(`name:"public"`),
+		))
+
+		type params struct {
+			dig.In
+
+			Server *Server `name:"public"`
+		}
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, &Server{Addr: ":8080"}, p.Server)
+		})
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(dig.Annotate(func() (string, int, error) { return "a", 1, nil },
+			dig.ResultTags(`group:"letters"`)))
+		c.RequireProvide(dig.Annotate(func() string { return "b" },
+			dig.ResultTags(`group:"letters"`)))
+
+		c.RequireInvoke(dig.Annotate(func(letters []string, i int) {
+			assert.ElementsMatch(t, []string{"a", "b"}, letters)
+			assert.Equal(t, 1, i)
+		}, dig.ParamTags(`group:"letters"`)))
+	})
+
+	t.Run("variadic parameters", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("letters"))
+		c.RequireProvide(func() string { return "b" }, dig.Group("letters"))
+
+		c.RequireInvoke(dig.Annotate(func(letters ...string) {
+			assert.ElementsMatch(t, []string{"a", "b"}, letters)
+		}, dig.ParamTags(`group:"letters"`)))
+	})
+
+	t.Run("result types", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(dig.Annotate(func() (*bytes.Buffer, string) { return new(bytes.Buffer), "a" },
+			dig.ResultAs(nil), dig.ResultAs(new(io.Reader)), dig.ResultAs(new(io.Writer)),
+			dig.ResultTags(`name:"buf"`, `group:"letters"`)))
+
+		type params struct {
+			dig.In
+
+			Buffer  *bytes.Buffer `name:"buf"`
+			Reader  io.Reader     `name:"buf"`
+			Writer  io.Writer     `name:"buf"`
+			Letters []string      `group:"letters"`
+		}
+		c.RequireInvoke(func(p params) {
+			assert.Same(t, p.Buffer, p.Reader)
+			assert.Same(t, p.Buffer, p.Writer)
+			assert.Equal(t, []string{"a"}, p.Letters)
+		})
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "hello" }, dig.Name("greeting"))
+		c.RequireDecorate(dig.Annotate(func(s string) string { return s + " world" },
+			dig.ParamTags(`name:"greeting"`), dig.ResultTags(`name:"greeting"`)))
+
+		type params struct {
+			dig.In
+
+			Greeting string `name:"greeting"`
+		}
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "hello world", p.Greeting)
+		})
+	})
+
+	t.Run("registered invokes", func(t *testing.T) {
+		t.Parallel()
+
+		var got string
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "hello" }, dig.Name("greeting"))
+		require.NoError(t, c.RegisterInvoke(dig.Annotate(func(s string) { got = s },
+			dig.ParamTags(`name:"greeting"`))))
+		require.NoError(t, c.Run())
+		assert.Equal(t, "hello", got)
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(dig.Annotate(func() (*Server, error) {
+			return nil, errors.New("great sadness")
+		}, dig.ResultTags(`name:"public"`)))
+
+		type params struct {
+			dig.In
+
+			Server *Server `name:"public"`
+		}
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Contains(t, err.Error(), "TestAnnotate", "errors must name the annotated function")
+	})
+
+	t.Run("invalid annotations", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			wantErr string
+		}{
+			{
+				desc:    "too many param tags",
+				give:    dig.Annotate(newServer, dig.ParamTags(`name:"a"`, `name:"b"`, `name:"c"`)),
+				wantErr: "got 3 ParamTags for 2 parameters",
+			},
+			{
+				desc:    "too many result tags",
+				give:    dig.Annotate(func() (*Server, error) { return nil, nil }, dig.ResultTags(`name:"a"`, `name:"b"`)),
+				wantErr: "got 2 ResultTags for 1 results",
+			},
+			{
+				desc:    "ParamTags twice",
+				give:    dig.Annotate(newServer, dig.ParamTags(), dig.ParamTags()),
+				wantErr: "cannot apply more than one ParamTags",
+			},
+			{
+				desc:    "ResultTags twice",
+				give:    dig.Annotate(newServer, dig.ResultTags(), dig.ResultTags()),
+				wantErr: "cannot apply more than one ResultTags",
+			},
+			{
+				desc:    "not a function",
+				give:    dig.Annotate(42),
+				wantErr: "must annotate a function, got 42 (type int)",
+			},
+			{
+				desc:    "type not implemented",
+				give:    dig.Annotate(newServer, dig.ResultAs(new(io.Reader))),
+				wantErr: "result 0 of type *dig_test.Server cannot be provided as io.Reader",
+			},
+			{
+				desc:    "non-pointer ResultAs",
+				give:    dig.Annotate(newServer, dig.ResultAs(Server{})),
+				wantErr: "argument 0 of ResultAs must be a pointer to a type, got dig_test.Server",
+			},
+			{
+				desc:    "too many result types",
+				give:    dig.Annotate(newServer, dig.ResultAs(nil, new(io.Reader))),
+				wantErr: "got 2 types in ResultAs for 1 results",
+			},
+			{
+				desc:    "invalid tag",
+				give:    dig.Annotate(newServer, dig.ParamTags(`optional:"no"`)),
+				wantErr: `invalid value "no" for "optional" tag`,
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Provide(tt.give)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestVariadic(t *testing.T) {
+	t.Parallel()
+
+	type Option string
+	type Server struct {
+		Name string
+		Opts []Option
+	}
+
+	newServer := func(name string, opts ...Option) *Server {
+		return &Server{Name: name, Opts: opts}
+	}
+
+	t.Run("left empty without annotation", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "api" })
+		c.RequireProvide(func() Option { return "tls" }, dig.Group("opts"))
+		c.RequireProvide(newServer)
+		c.RequireInvoke(func(s *Server) {
+			assert.Equal(t, "api", s.Name)
+			assert.Empty(t, s.Opts)
+		})
+	})
+
+	t.Run("filled from a value group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "api" })
+		c.RequireProvide(func() Option { return "tls" }, dig.Group("opts"))
+		c.RequireProvide(func() Option { return "gzip" }, dig.Group("opts"))
+		c.RequireProvide(dig.Annotate(newServer, dig.Variadic("opts")))
+		c.RequireInvoke(func(s *Server) {
+			assert.Equal(t, "api", s.Name)
+			assert.ElementsMatch(t, []Option{"tls", "gzip"}, s.Opts)
+		})
+	})
+
+	t.Run("empty group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "api" })
+		c.RequireProvide(dig.Annotate(newServer, dig.Variadic("opts")))
+		c.RequireInvoke(func(s *Server) {
+			assert.Empty(t, s.Opts)
+		})
+	})
+
+	t.Run("with ParamTags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "api" }, dig.Name("name"))
+		c.RequireProvide(func() Option { return "tls" }, dig.Group("opts"))
+		c.RequireInvoke(dig.Annotate(func(name string, opts ...Option) {
+			assert.Equal(t, "api", name)
+			assert.Equal(t, []Option{"tls"}, opts)
+		}, dig.ParamTags(`name:"name"`), dig.Variadic("opts")))
+	})
+
+	t.Run("soft group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "api" })
+		c.RequireProvide(func() Option {
+			t.Fatal("soft group constructors must not be called")
+			return ""
+		}, dig.Group("opts"))
+		c.RequireProvide(dig.Annotate(newServer, dig.Variadic("opts,soft")))
+		c.RequireInvoke(func(s *Server) {
+			assert.Empty(t, s.Opts)
+		})
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			wantErr string
+		}{
+			{
+				desc:    "non-variadic function",
+				give:    dig.Annotate(func(string) *Server { return nil }, dig.Variadic("opts")),
+				wantErr: "cannot use Variadic with non-variadic function",
+			},
+			{
+				desc:    "tagged variadic parameter",
+				give:    dig.Annotate(newServer, dig.ParamTags("", `group:"other"`), dig.Variadic("opts")),
+				wantErr: "cannot use Variadic with a ParamTags tag on the variadic parameter",
+			},
+			{
+				desc:    "Variadic twice",
+				give:    dig.Annotate(newServer, dig.Variadic("a"), dig.Variadic("b")),
+				wantErr: "cannot apply more than one Variadic",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Provide(tt.give)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestAnnotationString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `ParamTags(["name:\"addr\"" ""])`, fmt.Sprint(dig.ParamTags(`name:"addr"`, "")))
+	assert.Equal(t, `ResultTags(["group:\"servers\""])`, fmt.Sprint(dig.ResultTags(`group:"servers"`)))
+	assert.Equal(t, `Variadic("opts")`, fmt.Sprint(dig.Variadic("opts")))
+	assert.Equal(t, `ResultAs(*io.Reader, <nil>)`, fmt.Sprint(dig.ResultAs(new(io.Reader), nil)))
+}
@@ -20,6 +20,8 @@
 
 package dig
 
+import "time"
+
 // CallbackInfo contains information about a provided function or decorator
 // called by Dig, and is passed to a [Callback] registered with
 // [WithProviderCallback] or [WithDecoratorCallback].
@@ -30,9 +32,34 @@ type CallbackInfo struct {
 	Name string
 
 	// Error contains the error returned by the [Callback]'s associated
-	// function, if any. When used in conjunction with [RecoverFromPanics],
-	// this will be set to a [PanicError] when the function panics.
+	// function, if any. This will be set to a [PanicError] when a
+	// constructor panics, or when a decorator panics and [RecoverFromPanics]
+	// is used.
 	Error error
+
+	// Runtime contains the duration it took for the associated function to
+	// run. It is zero when Cached is true.
+	Runtime time.Duration
+
+	// Outputs lists the types produced by the associated function.
+	Outputs []*Output
+
+	// ScopePath contains the names of the scopes, starting below the
+	// container, leading to the scope the function was provided or
+	// decorated in. It is empty for functions given to the container itself.
+	ScopePath []string
+
+	// Attempt numbers the calls of the associated constructor, starting at
+	// 1. Constructors provided with [Retry] are called again after failed
+	// attempts. It is zero when Cached is true, and for decorators.
+	Attempt int
+
+	// Cached reports whether the values of the associated constructor were
+	// served from the container's cache instead of running the constructor.
+	// It is only true for the callbacks registered with
+	// [WithCacheHitCallback], which are called every time a constructor's
+	// value is requested again after it was first built.
+	Cached bool
 }
 
 // Callback is a function that can be registered with a provided function
@@ -90,6 +117,29 @@ func WithDecoratorCallback(callback Callback) DecorateOption {
 	}
 }
 
+// WithCacheHitCallback returns a [ProvideOption] which has Dig call the
+// passed in [Callback] every time the values of the corresponding
+// constructor are served from the container's cache, instead of running
+// the constructor. The [CallbackInfo] it receives has Cached set.
+//
+//	c.Provide(myConstructor, dig.WithCacheHitCallback(func(ci dig.CallbackInfo) {
+//		hits.Add(1)
+//	}))
+//
+// Callbacks registered with [WithProviderCallback] are only called after
+// the constructor runs, and are not called on cache hits.
+func WithCacheHitCallback(callback Callback) ProvideOption {
+	return cacheHitCallbackOption{callback: callback}
+}
+
+type cacheHitCallbackOption struct {
+	callback Callback
+}
+
+func (o cacheHitCallbackOption) applyProvideOption(po *provideOptions) {
+	po.CacheHit = o.callback
+}
+
 type withCallbackOption struct {
 	callback Callback
 }
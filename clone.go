@@ -0,0 +1,283 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// A CloneOption modifies the default behavior of Clone.
+type CloneOption interface {
+	applyCloneOption(*cloneOptions)
+}
+
+type cloneOptions struct {
+	Values bool
+}
+
+// CloneValues is a CloneOption that specifies whether the values already
+// built by the Container are copied to the clone. By default they are not,
+// and the clone calls the constructors again when their values are needed.
+//
+//	base.Invoke(warmUp)
+//	tenant := base.Clone(dig.CloneValues(true))
+//
+// Copied values are shared with the original Container: the clone holds
+// the same pointers, maps and slices. Cleanup functions, lifecycle hooks,
+// health checks and values collected by AutoClose stay with the original
+// Container.
+func CloneValues(include bool) CloneOption {
+	return cloneValuesOption(include)
+}
+
+type cloneValuesOption bool
+
+func (o cloneValuesOption) String() string {
+	return fmt.Sprintf("CloneValues(%v)", bool(o))
+}
+
+func (o cloneValuesOption) applyCloneOption(opts *cloneOptions) {
+	opts.Values = bool(o)
+}
+
+// Clone returns a new Container with the constructors, decorators and
+// Scopes of the Container, and the options it was created with. The clone
+// is independent: constructors provided to it, or values it builds, don't
+// affect the original Container, and vice versa. This lets per-test or
+// per-tenant containers be derived from a shared base.
+//
+//	base := dig.New()
+//	base.Provide(NewConfig)
+//	base.Provide(NewServer)
+//
+//	c := base.Clone()
+//	c.Replace(NewTestConfig)
+//
+// Scopes of the clone are reached with Scopes and ScopeByName. Invokes
+// registered with RegisterInvoke are cloned too. The clone of a Container
+// created with NewWithParent has the same parent, which is not cloned.
+func (c *Container) Clone(opts ...CloneOption) *Container {
+	var options cloneOptions
+	for _, opt := range opts {
+		opt.applyCloneOption(&options)
+	}
+
+	cl := cloner{
+		values: options.Values,
+		scopes: make(map[*Scope]*Scope),
+		nodes:  make(map[*constructorNode]*constructorNode),
+		dnodes: make(map[*decoratorNode]*decoratorNode),
+	}
+	root := cl.cloneScopes(c.scope, nil)
+	for old, s := range cl.scopes {
+		cl.cloneState(old, s)
+	}
+
+	old := c.scope.state
+	root.state = &containerState{
+		groupLess:              copyMap(old.groupLess),
+		constructorCount:       old.constructorCount,
+		eagerAll:               old.eagerAll,
+		profiles:               copyMap(old.profiles),
+		interceptors:           append([]ProviderInterceptorCtx(nil), old.interceptors...),
+		lazyCycles:             old.lazyCycles,
+		strictIn:               old.strictIn,
+		missingHandler:         old.missingHandler,
+		missingHandlerLocation: old.missingHandlerLocation,
+		plugins:                copyMap(old.plugins),
+		pprofLabels:            old.pprofLabels,
+		metrics:                old.metrics,
+		// The parent Container of a Container built with NewWithParent is
+		// shared, not cloned.
+		delegate:  old.delegate,
+		lifecycle: new(lifecycle),
+		autoClose: old.autoClose,
+	}
+	for _, ri := range old.registeredInvokes {
+		ri.s = cl.scopes[ri.s]
+		root.state.registeredInvokes = append(root.state.registeredInvokes, ri)
+	}
+	return &Container{scope: root}
+}
+
+// cloner copies the Scopes of a Container, and the constructors and
+// decorators provided to them.
+type cloner struct {
+	values bool
+
+	// Clones of the Scopes, constructors and decorators of the original
+	// Container.
+	scopes map[*Scope]*Scope
+	nodes  map[*constructorNode]*constructorNode
+	dnodes map[*decoratorNode]*decoratorNode
+}
+
+// cloneScopes creates the clones of s and its descendant Scopes, without
+// their constructors and decorators.
+func (cl *cloner) cloneScopes(s, parent *Scope) *Scope {
+	cs := newEmptyScope()
+	cs.name = s.name
+	cs.module = s.module
+	cs.parentScope = parent
+	cs.invokerFn = s.invokerFn
+	cs.deferAcyclicVerification = s.deferAcyclicVerification
+	cs.recoverFromPanics = s.recoverFromPanics
+	cs.constructorTimeout = s.constructorTimeout
+	cs.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	if parent != nil {
+		cs.parallel = parent.parallel
+	} else if p := s.parallel; p != nil {
+		cs.parallel = new(parallel)
+		if p.sem != nil {
+			cs.parallel.sem = make(chan struct{}, cap(p.sem))
+		}
+	}
+	cl.scopes[s] = cs
+
+	for _, child := range s.childScopes {
+		cs.childScopes = append(cs.childScopes, cl.cloneScopes(child, cs))
+	}
+	return cs
+}
+
+// cloneState copies the constructors, decorators and graph of the Scope s
+// to its clone cs, along with its values if requested.
+func (cl *cloner) cloneState(s, cs *Scope) {
+	for _, n := range s.nodes {
+		cs.nodes = append(cs.nodes, cl.node(n))
+	}
+	for k, ps := range s.providers {
+		nodes := make([]*constructorNode, len(ps))
+		for i, n := range ps {
+			nodes[i] = cl.node(n)
+		}
+		cs.providers[k] = nodes
+	}
+	for _, d := range s.decoratorNodes {
+		cs.decoratorNodes = append(cs.decoratorNodes, cl.decorator(d))
+	}
+	for k, chain := range s.decorators {
+		decorators := make([]*decoratorNode, len(chain))
+		for i, d := range chain {
+			decorators[i] = cl.decorator(d)
+		}
+		cs.decorators[k] = decorators
+	}
+	cs.factories = copyMap(s.factories)
+
+	// The graph is copied as-is so that the orders of its nodes, held by
+	// constructors and value group parameters, remain valid.
+	for _, gn := range s.gh.nodes {
+		w := gn.Wrapped
+		if n, ok := w.(*constructorNode); ok {
+			w = cl.node(n)
+		}
+		cs.gh.nodes = append(cs.gh.nodes, &graphNode{Wrapped: w})
+	}
+	cs.isVerifiedAcyclic = s.isVerifiedAcyclic
+
+	if cl.values {
+		cs.values = copyMap(s.values)
+		cs.decoratedValues = copyMap(s.decoratedValues)
+		cs.groups = copyMap(s.groups)
+		cs.namedGroups = copyMap(s.namedGroups)
+		cs.decoratedGroups = copyMap(s.decoratedGroups)
+	}
+}
+
+// node returns the clone of the constructor n.
+func (cl *cloner) node(n *constructorNode) *constructorNode {
+	if cn, ok := cl.nodes[n]; ok {
+		return cn
+	}
+
+	cn := *n
+	cn.s = cl.scopes[n.s]
+	cn.origS = cl.scopes[n.origS]
+	cn.orders = cl.orders(n.orders)
+	cn.paramList = cl.paramList(n.paramList)
+	cn.called = cl.values && n.called
+	if !cn.called {
+		cn.duration = 0
+	}
+	cn.building = false
+	cl.nodes[n] = &cn
+	return &cn
+}
+
+// decorator returns the clone of the decorator d.
+func (cl *cloner) decorator(d *decoratorNode) *decoratorNode {
+	if cd, ok := cl.dnodes[d]; ok {
+		return cd
+	}
+
+	cd := *d
+	cd.s = cl.scopes[d.s]
+	cd.orders = cl.orders(d.orders)
+	cd.params = cl.paramList(d.params)
+	if !cl.values || d.state != decoratorCalled {
+		cd.state = decoratorReady
+	}
+	cl.dnodes[d] = &cd
+	return &cd
+}
+
+func (cl *cloner) orders(orders map[*Scope]int) map[*Scope]int {
+	cloned := make(map[*Scope]int, len(orders))
+	for s, o := range orders {
+		if cs, ok := cl.scopes[s]; ok {
+			cloned[cs] = o
+		}
+	}
+	return cloned
+}
+
+func (cl *cloner) paramList(pl paramList) paramList {
+	params := make([]param, len(pl.Params))
+	for i, p := range pl.Params {
+		params[i] = cl.param(p)
+	}
+	pl.Params = params
+	return pl
+}
+
+// param returns a copy of p whose value group parameters have their orders
+// in the cloned Scopes.
+func (cl *cloner) param(p param) param {
+	switch p := p.(type) {
+	case paramGroupedSlice:
+		p.orders = cl.orders(p.orders)
+		return p
+	case paramObject:
+		fields := make([]paramObjectField, len(p.Fields))
+		for i, f := range p.Fields {
+			f.Param = cl.param(f.Param)
+			fields[i] = f
+		}
+		p.Fields = fields
+		return p
+	case paramList:
+		return cl.paramList(p)
+	}
+	return p
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Name string }
+	type Server struct{ Config *Config }
+
+	newBase := func(t *testing.T, calls *int) *digtest.Container {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config {
+			*calls++
+			return &Config{Name: "base"}
+		})
+		c.RequireProvide(func(cfg *Config) *Server { return &Server{Config: cfg} })
+		return c
+	}
+
+	t.Run("independent containers", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		base := newBase(t, &calls)
+		var baseServer *Server
+		base.RequireInvoke(func(s *Server) { baseServer = s })
+
+		clone := base.Clone()
+		require.NoError(t, clone.Replace(func() *Config { return &Config{Name: "clone"} }))
+		require.NoError(t, clone.Invoke(func(s *Server) {
+			assert.Equal(t, "clone", s.Config.Name)
+		}))
+		require.NoError(t, clone.Provide(func() string { return "only in clone" }))
+
+		base.RequireInvoke(func(s *Server) {
+			assert.Same(t, baseServer, s)
+		})
+		assert.Error(t, base.Invoke(func(string) {}))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("values are built again by default", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		base := newBase(t, &calls)
+		base.RequireInvoke(func(*Server) {})
+
+		require.NoError(t, base.Clone().Invoke(func(*Server) {}))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("values are copied with CloneValues", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		base := newBase(t, &calls)
+		var baseServer *Server
+		base.RequireInvoke(func(s *Server) { baseServer = s })
+
+		require.NoError(t, base.Clone(dig.CloneValues(true)).Invoke(func(s *Server) {
+			assert.Same(t, baseServer, s)
+		}))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("scopes, groups and decorators", func(t *testing.T) {
+		t.Parallel()
+
+		type Param struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}
+
+		base := digtest.New(t)
+		base.RequireProvide(func() string { return "a" }, dig.Group("names"))
+		child := base.Scope("child")
+		child.RequireProvide(func() string { return "b" }, dig.Group("names"))
+		child.RequireDecorate(func(p Param) []string {
+			return append(p.Names, "c")
+		}, dig.DecorateGroup("names"))
+
+		clone := base.Clone()
+		cloneChild, ok := clone.ScopeByName("child")
+		require.True(t, ok)
+		require.NoError(t, cloneChild.Provide(func() string { return "d" }, dig.Group("names")))
+
+		require.NoError(t, cloneChild.Invoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, p.Names)
+		}))
+		child.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, p.Names)
+		})
+	})
+
+	t.Run("cycles are detected in the clone", func(t *testing.T) {
+		t.Parallel()
+
+		base := digtest.New(t)
+		base.RequireProvide(func(*Server) *Config { return nil })
+
+		clone := base.Clone()
+		err := clone.Provide(func(*Config) *Server { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+		base.RequireProvide(func(*Config) string { return "" })
+	})
+
+	t.Run("delegating containers", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		app := newBase(t, &calls)
+		lib := dig.NewWithParent(app.Container)
+		require.NoError(t, lib.Provide(func(s *Server) string { return s.Config.Name }))
+
+		clone := lib.Clone()
+		require.NoError(t, clone.Invoke(func(name string) {
+			assert.Equal(t, "base", name)
+		}), "the clone must look up missing values in the parent")
+		require.NoError(t, lib.Invoke(func(string) {}))
+		assert.Equal(t, 1, calls, "the parent must be shared")
+	})
+}
+
+func TestCloneValuesString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "CloneValues(true)", fmt.Sprint(dig.CloneValues(true)))
+	assert.Equal(t, "CloneValues(false)", fmt.Sprint(dig.CloneValues(false)))
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// Close closes the Scope and all its descendant Scopes.
+//
+// The cleanup functions returned by the constructors whose values are held
+// by the Scope are called in the reverse order of these constructors, and
+// the values are released. Constructors may return a cleanup function
+// after their other results, optionally followed by an error:
+//
+//	func NewConn(cfg *Config) (*Conn, func(), error) {
+//	  conn, err := dial(cfg.Addr)
+//	  if err != nil {
+//	    return nil, nil, err
+//	  }
+//	  return conn, func() { conn.Close() }, nil
+//	}
+//
+// The cleanup function is not registered if the constructor fails.
+//
+// Only a result of type func() is a cleanup function. Constructors that
+// provide a function as a value return it as a named type instead, such as
+// `type Shutdown func()`.
+//
+// Closing a Scope detaches it from its parent: it must not be used anymore.
+// Provide, Decorate and Invoke fail on a closed Scope. Close returns an
+// error if the Scope was already closed, or if cleanup functions panicked
+// and the RecoverFromPanics option was given to the container.
+func (s *Scope) Close() error {
+	var cleanups []func()
+	err := s.parallelism().do(func() error {
+		if s.closed {
+			return errScopeClosed{Scope: s.name}
+		}
+
+		cleanups = s.release()
+		if p := s.parentScope; p != nil {
+			for i, cs := range p.childScopes {
+				if cs == s {
+					p.childScopes = append(p.childScopes[:i:i], p.childScopes[i+1:]...)
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cleanup functions may use the container, so they run without holding
+	// its lock.
+	return s.runCleanups(cleanups)
+}
+
+// checkOpen returns an error if the Scope was closed.
+func (s *Scope) checkOpen() error {
+	return s.parallelism().do(func() error {
+		if s.closed {
+			return errScopeClosed{Scope: s.name}
+		}
+		return nil
+	})
+}
+
+// close closes the Scope and its descendants, and runs their cleanup
+// functions.
+func (s *Scope) close() error {
+	return s.runCleanups(s.release())
+}
+
+// release marks the Scope and its descendants closed and drops their
+// values. It returns their cleanup functions, in the order they must be
+// called.
+func (s *Scope) release() []func() {
+	var cleanups []func()
+	for i := len(s.childScopes) - 1; i >= 0; i-- {
+		cleanups = append(cleanups, s.childScopes[i].release()...)
+	}
+	s.childScopes = nil
+
+	for i := len(s.cleanups) - 1; i >= 0; i-- {
+		cleanups = append(cleanups, s.cleanups[i])
+	}
+	s.cleanups = nil
+
+	for k := range s.values {
+		delete(s.values, k)
+	}
+	for k := range s.decoratedValues {
+		delete(s.decoratedValues, k)
+	}
+	for k := range s.groups {
+		delete(s.groups, k)
+	}
+	for k := range s.namedGroups {
+		delete(s.namedGroups, k)
+	}
+	for k := range s.decoratedGroups {
+		delete(s.decoratedGroups, k)
+	}
+	s.closed = true
+	return cleanups
+}
+
+// runCleanups calls the given cleanup functions in order, and returns the
+// errors of those that panicked.
+func (s *Scope) runCleanups(cleanups []func()) error {
+	errs := make([]error, 0, len(cleanups))
+	for _, f := range cleanups {
+		errs = append(errs, s.runCleanup(f))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scope) runCleanup(f func()) (err error) {
+	if s.recoverFromPanics {
+		defer func() {
+			if p := recover(); p != nil {
+				err = newPanicError(digreflect.InspectFunc(f), false /* constructor */, p)
+			}
+		}()
+	}
+	f()
+	return nil
+}
+
+func (s *Scope) addCleanup(f func()) {
+	if f != nil {
+		s.cleanups = append(s.cleanups, f)
+	}
+}
+
+// errScopeClosed is returned when a closed Scope is used.
+type errScopeClosed struct {
+	Scope string
+}
+
+var _ digError = errScopeClosed{}
+
+func (e errScopeClosed) Error() string { return fmt.Sprint(e) }
+
+func (e errScopeClosed) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "scope %q is closed", e.Scope)
+}
+
+func (e errScopeClosed) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
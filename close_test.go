@@ -0,0 +1,212 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeClose(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("cleanup functions are called in reverse order", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() (*A, func()) {
+			calls = append(calls, "new A")
+			return &A{}, func() { calls = append(calls, "close A") }
+		})
+		child.RequireProvide(func(*A) (*B, func(), error) {
+			calls = append(calls, "new B")
+			return &B{}, func() { calls = append(calls, "close B") }, nil
+		})
+		child.RequireInvoke(func(*B) {})
+
+		require.NoError(t, child.Close())
+		assert.Equal(t, []string{"new A", "new B", "close B", "close A"}, calls)
+	})
+
+	t.Run("values of ancestors are kept", func(t *testing.T) {
+		t.Parallel()
+
+		var closed bool
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, func()) {
+			return &A{}, func() { closed = true }
+		})
+		child := c.Scope("child")
+		child.RequireInvoke(func(*A) {})
+
+		require.NoError(t, child.Close())
+		assert.False(t, closed)
+	})
+
+	t.Run("descendants are closed", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		child := c.Scope("child")
+		grandchild := child.Scope("grandchild")
+		child.RequireProvide(func() (*A, func()) {
+			return &A{}, func() { calls = append(calls, "child") }
+		})
+		grandchild.RequireProvide(func() (*B, func()) {
+			return &B{}, func() { calls = append(calls, "grandchild") }
+		})
+		grandchild.RequireInvoke(func(*A, *B) {})
+
+		require.NoError(t, child.Close())
+		assert.Equal(t, []string{"grandchild", "child"}, calls)
+		assert.Empty(t, c.Scopes(), "closed scope must be detached from its parent")
+		assert.Error(t, grandchild.Invoke(func(*B) {}))
+	})
+
+	t.Run("cleanup is not registered on failure", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() (*A, func(), error) {
+			return nil, func() { t.Fatal("cleanup must not be called") }, errors.New("great sadness")
+		})
+		require.Error(t, child.Invoke(func(*A) {}))
+		require.NoError(t, child.Close())
+	})
+
+	t.Run("transient constructors", func(t *testing.T) {
+		t.Parallel()
+
+		var closed int
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() (*A, func()) {
+			return &A{}, func() { closed++ }
+		}, dig.Transient())
+		child.RequireInvoke(func(*A, *A) {})
+
+		require.NoError(t, child.Close())
+		assert.Equal(t, 2, closed)
+	})
+
+	t.Run("func results", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() func() { return func() {} })
+		c.RequireInvoke(func(func()) {})
+	})
+
+	t.Run("named func results", func(t *testing.T) {
+		t.Parallel()
+
+		type Shutdown func()
+
+		var called bool
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() (*A, Shutdown) {
+			return &A{}, func() { called = true }
+		})
+		child.RequireInvoke(func(*A, Shutdown) {})
+
+		require.NoError(t, child.Close())
+		assert.False(t, called, "named func results must be provided as values")
+	})
+
+	t.Run("closed scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		require.NoError(t, child.Close())
+
+		err := child.Close()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `scope "child" is closed`)
+
+		assert.ErrorContains(t, child.Provide(func() *A { return &A{} }), `scope "child" is closed`)
+		assert.ErrorContains(t, child.Decorate(func(a *A) *A { return a }), `scope "child" is closed`)
+		assert.ErrorContains(t, child.Invoke(func() {}), `scope "child" is closed`)
+		assert.ErrorContains(t, child.Supply(&A{}), `scope "child" is closed`)
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.RecoverFromPanics())
+		child := c.Scope("child")
+		child.RequireProvide(func() (*A, func()) {
+			return &A{}, func() { panic("great sadness") }
+		})
+		child.RequireInvoke(func(*A) {})
+
+		err := child.Close()
+		require.Error(t, err)
+		var pe dig.PanicError
+		require.True(t, errors.As(err, &pe))
+		assert.Equal(t, "great sadness", pe.Panic)
+	})
+	t.Run("concurrent closes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		c.RequireProvide(func() *A { return &A{} })
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			child := c.Scope(fmt.Sprint("child", i))
+			child.RequireProvide(func(*A) (*B, func()) {
+				// Cleanup functions may use the container.
+				return &B{}, func() { assert.NoError(t, c.Invoke(func(*A) {})) }
+			})
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				_ = child.Invoke(func(*B) {})
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, child.Close())
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Invoke(func(*A) {}))
+			}()
+		}
+		wg.Wait()
+		assert.Empty(t, c.Scopes())
+	})
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package example is a composition root used to test diggen.
+package example
+
+import (
+	"github.com/alexisvisco/dig"
+)
+
+// Config is the configuration of the Server.
+type Config struct{ Addr string }
+
+// NewConfig builds the default Config.
+func NewConfig() *Config { return &Config{Addr: ":8080"} }
+
+// Server serves at the address of its Config.
+type Server struct{ Addr string }
+
+// NewServer builds a Server.
+func NewServer(cfg *Config) (*Server, error) { return &Server{Addr: cfg.Addr}, nil }
+
+// NewContainer provides the constructors of the package.
+func NewContainer() *dig.Container {
+	c := dig.New()
+	if err := c.Provide(NewConfig); err != nil {
+		panic(err)
+	}
+	if err := c.Provide(NewServer); err != nil {
+		panic(err)
+	}
+	return c
+}
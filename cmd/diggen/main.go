@@ -0,0 +1,281 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Diggen generates plain Go initialization code from a dig container.
+//
+// The container is built by a composition root: an exported function of
+// type func() *dig.Container, declared in an importable package. Diggen
+// compiles and runs a small program calling the root and dig.Generate, and
+// writes the injectors it generates.
+//
+//	diggen -root example.com/app.NewContainer \
+//		-inject 'newServer=*example.com/app/server.Server' \
+//		-package main -pkgpath example.com/app/cmd/server \
+//		-o dig_gen.go
+//
+// Each -inject flag adds an injector, a function of the given name
+// returning the comma-separated types that follow along with an error.
+// Types are written as their import path and name, prefixed with * for
+// pointers. Diggen must be run from the Go module of the composition root.
+//
+// See dig.Generate for the constructors the generated code supports.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "diggen:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// injectFlag collects the values of the repeatable -inject flag.
+type injectFlag []injector
+
+func (f *injectFlag) String() string {
+	s := make([]string, len(*f))
+	for i, inj := range *f {
+		s[i] = inj.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func (f *injectFlag) Set(v string) error {
+	inj, err := parseInjector(v)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, inj)
+	return nil
+}
+
+// config is the parsed command line of diggen.
+type config struct {
+	Root      symbol
+	Injectors []injector
+	Package   string
+	PkgPath   string
+	Output    string
+}
+
+func parseArgs(args []string, stderr io.Writer) (config, error) {
+	var (
+		cfg    config
+		root   string
+		inject injectFlag
+	)
+	fs := flag.NewFlagSet("diggen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&root, "root", "", "composition root, as importpath.Func, returning a *dig.Container")
+	fs.Var(&inject, "inject", "injector to generate, as name=type[,type...] (repeatable)")
+	fs.StringVar(&cfg.Package, "package", "main", "name of the package of the generated code")
+	fs.StringVar(&cfg.PkgPath, "pkgpath", "", "import path of the package of the generated code (default: the package of the root)")
+	fs.StringVar(&cfg.Output, "o", "", "file to write the generated code to (default: standard output)")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if root == "" {
+		return cfg, errors.New("missing -root")
+	}
+	var err error
+	if cfg.Root, err = parseSymbol(root); err != nil {
+		return cfg, fmt.Errorf("invalid -root %q: %v", root, err)
+	}
+	if len(inject) == 0 {
+		return cfg, errors.New("missing -inject")
+	}
+	cfg.Injectors = inject
+	if cfg.PkgPath == "" {
+		cfg.PkgPath = cfg.Root.Path
+	}
+	return cfg, nil
+}
+
+// symbol is a name declared at the top level of a package.
+type symbol struct {
+	Path string
+	Name string
+}
+
+func (s symbol) String() string { return s.Path + "." + s.Name }
+
+// parseSymbol parses an import path followed by a dot and a name.
+func parseSymbol(s string) (symbol, error) {
+	i := strings.LastIndexByte(s, '.')
+	if i <= 0 || i < strings.LastIndexByte(s, '/') {
+		return symbol{}, errors.New("must be an import path followed by a dot and a name")
+	}
+	return symbol{Path: s[:i], Name: s[i+1:]}, nil
+}
+
+// target is a type returned by an injector.
+type target struct {
+	symbol
+
+	Pointer bool
+}
+
+func (t target) String() string {
+	if t.Pointer {
+		return "*" + t.symbol.String()
+	}
+	return t.symbol.String()
+}
+
+// injector is a function to generate.
+type injector struct {
+	Name    string
+	Targets []target
+}
+
+func (inj injector) String() string {
+	s := make([]string, len(inj.Targets))
+	for i, t := range inj.Targets {
+		s[i] = t.String()
+	}
+	return inj.Name + "=" + strings.Join(s, ",")
+}
+
+// parseInjector parses the value of an -inject flag.
+func parseInjector(v string) (injector, error) {
+	name, types, ok := strings.Cut(v, "=")
+	if !ok || name == "" || types == "" {
+		return injector{}, fmt.Errorf("%q must be a name followed by = and types", v)
+	}
+	inj := injector{Name: name}
+	for _, t := range strings.Split(types, ",") {
+		t = strings.TrimSpace(t)
+		ptr := strings.HasPrefix(t, "*")
+		sym, err := parseSymbol(strings.TrimPrefix(t, "*"))
+		if err != nil {
+			return injector{}, fmt.Errorf("invalid type %q: %v", t, err)
+		}
+		inj.Targets = append(inj.Targets, target{symbol: sym, Pointer: ptr})
+	}
+	return inj, nil
+}
+
+var _driver = template.Must(template.New("driver").Parse(`// Code generated by diggen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alexisvisco/dig"
+{{- range $path, $alias := .Imports}}
+	{{$alias}} {{printf "%q" $path}}
+{{- end}}
+)
+
+func main() {
+	err := dig.Generate({{index .Imports .Root.Path}}.{{.Root.Name}}(), os.Stdout,
+		dig.GeneratePackage({{printf "%q" .Package}}, {{printf "%q" .PkgPath}}),
+{{- range .Injectors}}
+		dig.GenerateInjector({{printf "%q" .Name}}{{range .Targets}}, new({{if .Pointer}}*{{end}}{{index $.Imports .Path}}.{{.Name}}){{end}}),
+{{- end}}
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+// driver returns the source of the program that generates the code of
+// the given configuration.
+func driver(cfg config) ([]byte, error) {
+	imports := map[string]string{cfg.Root.Path: "p0"}
+	for _, inj := range cfg.Injectors {
+		for _, t := range inj.Targets {
+			if _, ok := imports[t.Path]; !ok {
+				imports[t.Path] = "p" + strconv.Itoa(len(imports))
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	err := _driver.Execute(&b, struct {
+		config
+
+		Imports map[string]string
+	}{cfg, imports})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	cfg, err := parseArgs(args, stderr)
+	if err != nil {
+		return err
+	}
+	src, err := driver(cfg)
+	if err != nil {
+		return err
+	}
+
+	// The driver is built from within the current module so that it may
+	// import the composition root and the version of dig it depends on.
+	dir, err := os.MkdirTemp(".", "diggen")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdout = &out
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cannot generate code: %v", err)
+	}
+
+	if cfg.Output == "" {
+		_, err = stdout.Write(out.Bytes())
+		return err
+	}
+	return os.WriteFile(cfg.Output, out.Bytes(), 0o644)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const _example = "github.com/alexisvisco/dig/cmd/diggen/internal/example"
+
+func TestParseArgs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := parseArgs([]string{
+			"-root", _example + ".NewContainer",
+			"-inject", "newServer=*" + _example + ".Server, " + _example + ".Config",
+			"-inject", "newConfig=*" + _example + ".Config",
+			"-o", "dig_gen.go",
+		}, new(bytes.Buffer))
+		require.NoError(t, err)
+		assert.Equal(t, config{
+			Root: symbol{Path: _example, Name: "NewContainer"},
+			Injectors: []injector{
+				{Name: "newServer", Targets: []target{
+					{symbol: symbol{Path: _example, Name: "Server"}, Pointer: true},
+					{symbol: symbol{Path: _example, Name: "Config"}},
+				}},
+				{Name: "newConfig", Targets: []target{
+					{symbol: symbol{Path: _example, Name: "Config"}, Pointer: true},
+				}},
+			},
+			Package: "main",
+			PkgPath: _example,
+			Output:  "dig_gen.go",
+		}, cfg)
+	})
+
+	tests := []struct {
+		desc string
+		args []string
+		msg  string
+	}{
+		{
+			desc: "missing root",
+			args: []string{"-inject", "f=*example.com/a.T"},
+			msg:  "missing -root",
+		},
+		{
+			desc: "invalid root",
+			args: []string{"-root", "example.com/a", "-inject", "f=*example.com/a.T"},
+			msg:  `invalid -root "example.com/a": must be an import path followed by a dot and a name`,
+		},
+		{
+			desc: "missing inject",
+			args: []string{"-root", "example.com/a.New"},
+			msg:  "missing -inject",
+		},
+		{
+			desc: "invalid inject",
+			args: []string{"-root", "example.com/a.New", "-inject", "f"},
+			msg:  `"f" must be a name followed by = and types`,
+		},
+		{
+			desc: "invalid type",
+			args: []string{"-root", "example.com/a.New", "-inject", "f=*T"},
+			msg:  `invalid type "*T"`,
+		},
+		{
+			desc: "arguments",
+			args: []string{"-root", "example.com/a.New", "-inject", "f=*example.com/a.T", "x"},
+			msg:  "unexpected arguments: [x]",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseArgs(tt.args, new(bytes.Buffer))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+}
+
+func TestDriver(t *testing.T) {
+	t.Parallel()
+
+	src, err := driver(config{
+		Root: symbol{Path: "example.com/app", Name: "NewContainer"},
+		Injectors: []injector{{Name: "newServer", Targets: []target{
+			{symbol: symbol{Path: "example.com/app/server", Name: "Server"}, Pointer: true},
+			{symbol: symbol{Path: "example.com/app", Name: "Config"}},
+		}}},
+		Package: "main",
+		PkgPath: "example.com/app/cmd/server",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by diggen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	p0 "example.com/app"
+	p1 "example.com/app/server"
+	"github.com/alexisvisco/dig"
+)
+
+func main() {
+	err := dig.Generate(p0.NewContainer(), os.Stdout,
+		dig.GeneratePackage("main", "example.com/app/cmd/server"),
+		dig.GenerateInjector("newServer", new(*p1.Server), new(p0.Config)),
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`, string(src))
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+
+	out := filepath.Join(t.TempDir(), "dig_gen.go")
+	var stderr bytes.Buffer
+	require.NoError(t, run([]string{
+		"-root", _example + ".NewContainer",
+		"-inject", "newServer=*" + _example + ".Server",
+		"-package", "example",
+		"-o", out,
+	}, new(bytes.Buffer), &stderr), stderr.String())
+
+	src, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by dig.Generate. DO NOT EDIT.
+
+package example
+
+func newServer() (t1 *Server, err error) {
+	v1 := NewConfig()
+	v2, err := NewServer(v1)
+	if err != nil {
+		return
+	}
+	return v2, nil
+}
+`, string(src))
+}
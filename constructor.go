@@ -21,8 +21,10 @@
 package dig
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/alexisvisco/dig/internal/digerror"
 	"github.com/alexisvisco/dig/internal/digreflect"
@@ -64,8 +66,61 @@ type constructorNode struct {
 	// This is different from s if and only if the constructor was Provided with ExportOption.
 	origS *Scope
 
+	// Position of this constructor among all the constructors provided to
+	// the Container. Used to order value groups consumed with the sorted
+	// option.
+	seq int
+
 	// Callback for this provided function, if there is one.
 	callback Callback
+
+	// Callback for the cache hits of this provided function, if there is
+	// one.
+	cacheHitCallback Callback
+
+	// Descriptive metadata attached to this constructor with WithTags.
+	tags map[string]string
+
+	// Lifecycle hooks to register when this constructor is called.
+	hooks []lifecycleHook
+
+	// Whether the results implementing Starter or Stopper should be
+	// registered with the lifecycle when this constructor is called.
+	lifecycle bool
+
+	// Values returned as-is by this constructor if it was built by Supply,
+	// or nil.
+	supplied *suppliedValues
+
+	// Whether this constructor is called by Container.Build rather than
+	// when one of its values is first needed.
+	eager bool
+
+	// Whether this constructor is called again each time one of its values
+	// is needed, instead of once.
+	transient bool
+
+	// Whether this constructor is being called. Calls in parallel are
+	// tracked by parallel.once instead.
+	building bool
+
+	// How long the call of this constructor that built its values took,
+	// not counting its dependencies. Zero for transient constructors.
+	duration time.Duration
+
+	// Whether this constructor was provided with IfMissing, and is
+	// replaced by constructors provided later for the same values.
+	fallback bool
+
+	// How long this constructor may run, if positive. See
+	// ConstructorTimeout.
+	timeout time.Duration
+
+	// How this constructor is called again when it fails. See Retry.
+	retry retryPolicy
+
+	// Health checks to register when this constructor is called.
+	checks []func(context.Context) error
 }
 
 type constructorOptions struct {
@@ -73,9 +128,24 @@ type constructorOptions struct {
 	// belong to the specified value group or implement any of the interfaces.
 	ResultName  string
 	ResultGroup string
+	ResultTags  []resultTag
 	ResultAs    []interface{}
+	Implemented []interface{}
 	Location    *digreflect.Func
 	Callback    Callback
+	CacheHit    Callback
+	Tags        map[string]string
+	Hooks       []lifecycleHook
+	Lifecycle   bool
+	Supplied    *suppliedValues
+	Eager       bool
+	Transient   bool
+	Fallback    bool
+	Timeout     time.Duration
+	Retry       retryPolicy
+	Checks      []func(context.Context) error
+	Defaults    []interface{}
+	BoundArgs   []interface{}
 }
 
 func newConstructorNode(ctor interface{}, s *Scope, origS *Scope, opts constructorOptions) (*constructorNode, error) {
@@ -87,13 +157,25 @@ func newConstructorNode(ctor interface{}, s *Scope, origS *Scope, opts construct
 	if err != nil {
 		return nil, err
 	}
+	if len(opts.BoundArgs) > 0 {
+		if params, err = params.bind(opts.BoundArgs); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Defaults) > 0 {
+		if params, err = params.withDefaults(opts.Defaults); err != nil {
+			return nil, err
+		}
+	}
 
 	results, err := newResultList(
 		ctype,
 		resultOptions{
-			Name:  opts.ResultName,
-			Group: opts.ResultGroup,
-			As:    opts.ResultAs,
+			Name:        opts.ResultName,
+			Group:       opts.ResultGroup,
+			Tags:        opts.ResultTags,
+			As:          opts.ResultAs,
+			Implemented: opts.Implemented,
 		},
 	)
 	if err != nil {
@@ -105,17 +187,38 @@ func newConstructorNode(ctor interface{}, s *Scope, origS *Scope, opts construct
 		location = digreflect.InspectFunc(ctor)
 	}
 
+	id := dot.CtorID(cptr)
+	if opts.Supplied != nil {
+		// Functions built by Supply all share the same code pointer.
+		id = opts.Supplied.id()
+	}
+
+	state := s.rootScope().state
+	state.constructorCount++
+
 	n := &constructorNode{
-		ctor:       ctor,
-		ctype:      ctype,
-		location:   location,
-		id:         dot.CtorID(cptr),
-		paramList:  params,
-		resultList: results,
-		orders:     make(map[*Scope]int),
-		s:          s,
-		origS:      origS,
-		callback:   opts.Callback,
+		seq:              state.constructorCount,
+		ctor:             ctor,
+		ctype:            ctype,
+		location:         location,
+		id:               id,
+		paramList:        params,
+		resultList:       results,
+		orders:           make(map[*Scope]int),
+		s:                s,
+		origS:            origS,
+		callback:         opts.Callback,
+		cacheHitCallback: opts.CacheHit,
+		tags:             opts.Tags,
+		hooks:            opts.Hooks,
+		lifecycle:        opts.Lifecycle,
+		supplied:         opts.Supplied,
+		eager:            opts.Eager,
+		transient:        opts.Transient,
+		fallback:         opts.Fallback,
+		timeout:          opts.Timeout,
+		retry:            opts.Retry,
+		checks:           opts.Checks,
 	}
 	s.newGraphNode(n, n.orders)
 	return n, nil
@@ -135,13 +238,81 @@ func (n *constructorNode) String() string {
 
 // Call calls this constructor if it hasn't already been called and
 // injects any values produced by it into the provided container.
-func (n *constructorNode) Call(c containerStore) (err error) {
+func (n *constructorNode) Call(c containerStore) error {
 	if n.called {
+		n.notifyCached()
 		return nil
 	}
 
+	// If the Container is used concurrently, callers that need this
+	// constructor while another goroutine calls it, from any Scope, wait
+	// for that call and share its results.
+	return c.parallelism().once(n, func() error { return n.callOnce(c) })
+}
+
+// callOnce calls this constructor and commits its results.
+func (n *constructorNode) callOnce(c containerStore) error {
+	if n.building {
+		// Only possible without parallelism, when a dig.Lazy dependency is
+		// resolved by a constructor that the dependency itself needs.
+		return newErrInvalidInput(fmt.Sprintf("cycle detected: %v is already being called", n.location), nil)
+	}
+	state := n.s.rootScope().state
+	if err, ok := state.failedCalls[n]; ok {
+		return err
+	}
+	n.building = true
+	defer func() { n.building = false }()
+
+	receiver, results, err := n.call(c)
+	if err != nil {
+		if state.failedCalls != nil {
+			state.failedCalls[n] = err
+		}
+		return err
+	}
+
+	// Commit the result to the original container that this constructor
+	// was supplied to. The provided constructor is only used for a view of
+	// the rest of the graph to instantiate the dependencies of this
+	// container.
+	receiver.dropOverridden(n)
+	receiver.Commit(n.s)
+	n.called = true
+	n.s.addCleanup(n.resultList.cleanup(results))
+
+	if n.lifecycle {
+		state.lifecycle.append(lifecycleHooksFromList(n.resultList, results)...)
+	}
+	state.lifecycle.append(n.hooks...)
+	state.healthChecks = append(state.healthChecks, n.healthChecks(results)...)
+	if state.autoClose {
+		state.collectShutdowns(n.shutdowns(results))
+	}
+	return nil
+}
+
+// CallTransient calls this transient constructor and returns the value it
+// produced for the given key, without injecting any value into a container.
+func (n *constructorNode) CallTransient(c containerStore, k key) (reflect.Value, error) {
+	receiver, results, err := n.call(c)
+	if err != nil {
+		return _noValue, err
+	}
+	n.s.addCleanup(n.resultList.cleanup(results))
+	return receiver.values[k], nil
+}
+
+// call calls this constructor, building its dependencies from the provided
+// container, and records the values it produced in the returned writer.
+func (n *constructorNode) call(c containerStore) (_ *stagingContainerWriter, _ []reflect.Value, err error) {
+	if t := n.s.tracer(); t != nil {
+		t.begin(n)
+		defer func() { t.end(n, err) }()
+	}
+
 	if err := shallowCheckDependencies(c, n.paramList); err != nil {
-		return errMissingDependencies{
+		return nil, nil, errMissingDependencies{
 			Func:   n.location,
 			Reason: err,
 		}
@@ -149,61 +320,149 @@ func (n *constructorNode) Call(c containerStore) (err error) {
 
 	args, err := n.paramList.BuildList(c)
 	if err != nil {
-		return errArgumentsFailed{
+		return nil, nil, errArgumentsFailed{
+			Func:   n.location,
+			Reason: err,
+		}
+	}
+
+	if err := c.invokeContext().Err(); err != nil {
+		return nil, nil, errArgumentsFailed{
 			Func:   n.location,
 			Reason: err,
 		}
 	}
 
+	for attempt := 1; ; attempt++ {
+		receiver, results, err := n.attempt(c, args, attempt)
+		if err == nil || attempt >= n.retry.Attempts {
+			return receiver, results, err
+		}
+
+		// Stop retrying if the invocation is canceled.
+		if c.parallelism().sleep(c.invokeContext(), n.retry.backoff(attempt)) != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// attempt calls this constructor once with the given arguments.
+func (n *constructorNode) attempt(c containerStore, args []reflect.Value, attempt int) (_ *stagingContainerWriter, _ []reflect.Value, err error) {
+	var runtime time.Duration
 	if n.callback != nil {
 		// Wrap in separate func to include PanicErrors
 		defer func() {
-			n.callback(CallbackInfo{
-				Name:  fmt.Sprintf("%v.%v", n.location.Package, n.location.Name),
-				Error: err,
-			})
+			info := n.callbackInfo(err, runtime, false /* cached */)
+			info.Attempt = attempt
+			n.callback(info)
 		}()
 	}
 
-	if n.s.recoverFromPanics {
+	var ran bool
+	if m := n.s.rootScope().state.metrics; m != nil {
 		defer func() {
-			if p := recover(); p != nil {
-				err = PanicError{
-					fn:    n.location,
-					Panic: p,
-				}
+			if ran {
+				m.ConstructorExecuted(ConstructorEvent{
+					Provider:  newProviderInfo(n),
+					ScopePath: n.s.path(),
+					Duration:  runtime,
+					Error:     err,
+				})
 			}
 		}()
 	}
 
-	receiver := newStagingContainerWriter()
-	results := c.invoker()(reflect.ValueOf(n.ctor), args)
-	if err = n.resultList.ExtractList(receiver, false /* decorating */, results); err != nil {
-		return errConstructorFailed{Func: n.location, Reason: err}
+	// Panics in constructors are always recovered, so that they don't
+	// leave the Container in an inconsistent state.
+	defer func() {
+		if p := recover(); p != nil {
+			err = newPanicError(n.location, true /* constructor */, p)
+		}
+	}()
+
+	receiver := newStagingContainerWriter(n.seq)
+	var results []reflect.Value
+	invoke := func() error {
+		ran = true
+		// Deferred so that the runtime of panicking constructors is known.
+		defer func(start time.Time) { runtime = time.Since(start) }(time.Now())
+
+		invoker, timedOut := c.invoker(), false
+		if n.timeout > 0 {
+			invoker = timeoutInvoker(invoker, n.timeout, &timedOut)
+		}
+		if n.s.rootScope().state.pprofLabels {
+			invoker = n.pprofInvoker(c.invokeContext(), invoker)
+		}
+		results = c.parallelism().call(invoker, reflect.ValueOf(n.ctor), args)
+		if timedOut {
+			return errConstructorTimedOut{Timeout: n.timeout}
+		}
+		return n.resultList.ExtractList(receiver, false /* decorating */, results)
+	}
+	err = n.s.intercept(n, invoke)
+	if ran && !n.transient {
+		n.duration = runtime
+	}
+	if t := n.s.tracer(); t != nil && ran {
+		t.ran(n, runtime)
 	}
+	if err != nil {
+		return nil, nil, errConstructorFailed{Func: n.location, Reason: err}
+	}
+	return receiver, results, nil
+}
 
-	// Commit the result to the original container that this constructor
-	// was supplied to. The provided constructor is only used for a view of
-	// the rest of the graph to instantiate the dependencies of this
-	// container.
-	receiver.Commit(n.s)
-	n.called = true
-	return nil
+// notifyCached tells the cache hit callback of this constructor, if any,
+// that its values were served from the cache.
+func (n *constructorNode) notifyCached() {
+	n.s.rootScope().state.cacheHits.Add(1)
+	if t := n.s.tracer(); t != nil {
+		t.cacheHit(n)
+	}
+	if n.cacheHitCallback != nil {
+		n.cacheHitCallback(n.callbackInfo(nil, 0, true /* cached */))
+	}
+}
+
+func (n *constructorNode) callbackInfo(err error, runtime time.Duration, cached bool) CallbackInfo {
+	return CallbackInfo{
+		Name:      fmt.Sprintf("%v.%v", n.location.Package, n.location.Name),
+		Error:     err,
+		Runtime:   runtime,
+		Outputs:   newOutputs(n.resultList.DotResult()),
+		ScopePath: n.s.path(),
+		Cached:    cached,
+	}
 }
 
 // stagingContainerWriter is a containerWriter that records the changes that
 // would be made to a containerWriter and defers them until Commit is called.
 type stagingContainerWriter struct {
-	values map[key]reflect.Value
-	groups map[key][]reflect.Value
+	values      map[key]reflect.Value
+	groups      map[key][]groupValue
+	namedGroups map[key][]stagedNamedValue
+
+	// Order of the constructor whose results are recorded, stamped on the
+	// values submitted to value groups.
+	order int
+}
+
+// stagedNamedValue is a value submitted to a value group under a name,
+// recorded by a stagingContainerWriter.
+type stagedNamedValue struct {
+	Name  string
+	Value groupValue
 }
 
 var _ containerWriter = (*stagingContainerWriter)(nil)
 
-func newStagingContainerWriter() *stagingContainerWriter {
+func newStagingContainerWriter(order int) *stagingContainerWriter {
 	return &stagingContainerWriter{
-		values: make(map[key]reflect.Value),
-		groups: make(map[key][]reflect.Value),
+		values:      make(map[key]reflect.Value),
+		groups:      make(map[key][]groupValue),
+		namedGroups: make(map[key][]stagedNamedValue),
+		order:       order,
 	}
 }
 
@@ -215,16 +474,33 @@ func (sr *stagingContainerWriter) setDecoratedValue(_ string, _ reflect.Type, _
 	digerror.BugPanicf("stagingContainerWriter.setDecoratedValue must never be called")
 }
 
-func (sr *stagingContainerWriter) submitGroupedValue(group string, t reflect.Type, v reflect.Value) {
+func (sr *stagingContainerWriter) submitGroupedValue(group string, t reflect.Type, v groupValue) {
 	k := key{t: t, group: group}
+	v.Order = sr.order
 	sr.groups[k] = append(sr.groups[k], v)
 }
 
+func (sr *stagingContainerWriter) submitNamedGroupedValue(group, name string, t reflect.Type, v groupValue) {
+	k := key{t: t, group: group}
+	v.Order = sr.order
+	sr.namedGroups[k] = append(sr.namedGroups[k], stagedNamedValue{Name: name, Value: v})
+}
+
 func (sr *stagingContainerWriter) submitDecoratedGroupedValue(_ string, _ reflect.Type, _ reflect.Value) {
 	digerror.BugPanicf("stagingContainerWriter.submitDecoratedGroupedValue must never be called")
 }
 
 // Commit commits the received results to the provided containerWriter.
+// dropOverridden drops the values of the given constructor for the keys it
+// no longer provides, because another constructor overrode them.
+func (sr *stagingContainerWriter) dropOverridden(n *constructorNode) {
+	for k := range sr.values {
+		if !n.s.providesKey(n, k) {
+			delete(sr.values, k)
+		}
+	}
+}
+
 func (sr *stagingContainerWriter) Commit(cw containerWriter) {
 	for k, v := range sr.values {
 		cw.setValue(k.name, k.t, v)
@@ -235,4 +511,10 @@ func (sr *stagingContainerWriter) Commit(cw containerWriter) {
 			cw.submitGroupedValue(k.group, k.t, v)
 		}
 	}
+
+	for k, nvs := range sr.namedGroups {
+		for _, nv := range nvs {
+			cw.submitNamedGroupedValue(k.group, nv.Name, k.t, nv.Value)
+		}
+	}
 }
@@ -21,6 +21,7 @@
 package dig
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -31,6 +32,7 @@ import (
 const (
 	_optionalTag         = "optional"
 	_nameTag             = "name"
+	_decoratedTag        = "decorated"
 	_ignoreUnexportedTag = "ignore-unexported"
 )
 
@@ -82,7 +84,11 @@ type containerWriter interface {
 
 	// submitGroupedValue submits a value to the value group with the provided
 	// name.
-	submitGroupedValue(name string, t reflect.Type, v reflect.Value)
+	submitGroupedValue(name string, t reflect.Type, v groupValue)
+
+	// submitNamedGroupedValue submits a value to the value group with the
+	// provided group name, under the given name.
+	submitNamedGroupedValue(group, name string, t reflect.Type, v groupValue)
 
 	// submitDecoratedGroupedValue submits a decorated value to the value group
 	// with the provided name.
@@ -99,6 +105,10 @@ type containerStore interface {
 	// Returns a slice containing all known types.
 	knownTypes() []reflect.Type
 
+	// Returns the names, including the empty name, under which values of
+	// the given type are provided.
+	knownNames(t reflect.Type) []string
+
 	// Retrieves the value with the provided name and type, if any.
 	getValue(name string, t reflect.Type) (v reflect.Value, ok bool)
 
@@ -110,6 +120,18 @@ type containerStore interface {
 	// The order in which the values are returned is undefined.
 	getValueGroup(name string, t reflect.Type) []reflect.Value
 
+	// Retrieves all values for the provided group and type, in the order in
+	// which they were submitted, along with their ordering information.
+	getOrderedValueGroup(name string, t reflect.Type) []groupValue
+
+	// Retrieves the less function registered for the provided group and
+	// type with RegisterGroupLess, if any.
+	getGroupLess(name string, t reflect.Type) (reflect.Value, bool)
+
+	// Retrieves the values submitted with a name for the provided group and
+	// type.
+	getNamedValueGroup(group string, t reflect.Type) []namedGroupValue
+
 	// Retrieves all decorated values for the provided group and type, if any.
 	getDecoratedValueGroup(name string, t reflect.Type) (reflect.Value, bool)
 
@@ -141,6 +163,17 @@ type containerStore interface {
 
 	// Returns invokerFn function to use when calling arguments.
 	invoker() invokerFn
+
+	// Returns the context of the current invocation.
+	invokeContext() context.Context
+
+	// Returns the state used to build values concurrently, or nil if values
+	// are built sequentially.
+	parallelism() *parallel
+
+	// Calls fn to resolve a dig.Lazy dependency, serialized with the other
+	// resolutions.
+	lazyGet(fn func() error) error
 }
 
 // New constructs a Container.
@@ -154,6 +187,30 @@ func New(opts ...Option) *Container {
 	return c
 }
 
+// NewWithParent constructs a Container that delegates to the given parent
+// Container: values that are not provided to the new Container are looked
+// up in the parent, as if it were a parent Scope.
+//
+//	app := dig.New()
+//	app.Provide(NewLogger)
+//
+//	lib := dig.NewWithParent(app)
+//	lib.Provide(NewPlugin) // may depend on the application's *Logger
+//
+// Unlike with a child Scope, the parent is never modified: constructors,
+// decorators and options given to the new Container, including exported
+// constructors, don't affect it. Values built by the constructors of the
+// parent are cached by the parent, and shared with all the Containers
+// delegating to it. Decorators of the parent apply to the values of the new
+// Container, unless they were registered with PropagateToDescendants(false).
+//
+// The options of the parent are not inherited.
+func NewWithParent(parent *Container, opts ...Option) *Container {
+	c := New(opts...)
+	c.scope.state.delegate = parent.scope
+	return c
+}
+
 // DeferAcyclicVerification is an Option to override the default behavior
 // of container.Provide, deferring the dependency graph validation to no longer
 // run after each call to container.Provide. The container will instead verify
@@ -180,6 +237,9 @@ func (deferAcyclicVerificationOption) applyOption(c *Container) {
 // will be placed into a [PanicError], and returned at the invoke callsite.
 // See [PanicError] for an example on how to handle panics with this option
 // enabled, and distinguish them from errors.
+//
+// Panics in constructors are recovered even without this option, and are
+// matched by [ErrConstructorPanicked].
 func RecoverFromPanics() Option {
 	return recoverFromPanicsOption{}
 }
@@ -259,6 +319,25 @@ func (c *Container) Scope(name string, opts ...ScopeOption) *Scope {
 	return c.scope.Scope(name, opts...)
 }
 
+// NewScope creates a child scope of the Container with the given name,
+// failing if the Container already has a child scope with this name. See
+// Scope.NewScope.
+func (c *Container) NewScope(name string, opts ...ScopeOption) (*Scope, error) {
+	return c.scope.NewScope(name, opts...)
+}
+
+// Scopes returns the child scopes of the Container, in the order in which
+// they were created.
+func (c *Container) Scopes() []*Scope {
+	return c.scope.Scopes()
+}
+
+// ScopeByName returns the child scope of the Container with the given name,
+// if any. See Scope.ScopeByName.
+func (c *Container) ScopeByName(name string) (*Scope, bool) {
+	return c.scope.ScopeByName(name)
+}
+
 type byTypeName []reflect.Type
 
 func (bs byTypeName) Len() int {
@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"reflect"
+)
+
+var _contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// InvokeCtx runs the given function after instantiating its dependencies,
+// like Invoke, making ctx available to the functions it calls.
+//
+// Parameters of type context.Context, in the invoked function and in any
+// constructor or decorator called to build its dependencies, receive ctx
+// unless a context.Context was explicitly provided to the container.
+// Constructors that take a context.Context can use it to honor the
+// cancellation and deadline of the invocation.
+//
+//	c.Provide(func(ctx context.Context, cfg *Config) (*sql.DB, error) {
+//	  db, err := sql.Open("postgres", cfg.DSN)
+//	  if err != nil {
+//	    return nil, err
+//	  }
+//	  return db, db.PingContext(ctx)
+//	})
+//	err := c.InvokeCtx(ctx, func(db *sql.DB) { ... })
+//
+// Once ctx is done, no further constructors are called and InvokeCtx fails
+// with an error wrapping ctx.Err().
+//
+// Constructors are called at most once: a constructor that takes a
+// context.Context receives the context of the invocation that first needed
+// its results. Dependencies resolved by Invoke receive context.Background().
+func (c *Container) InvokeCtx(ctx context.Context, function interface{}, opts ...InvokeOption) error {
+	return c.scope.InvokeCtx(ctx, function, opts...)
+}
+
+// InvokeCtx runs the given function after instantiating its dependencies,
+// making ctx available to the functions it calls.
+// See Container.InvokeCtx for details.
+func (s *Scope) InvokeCtx(ctx context.Context, function interface{}, opts ...InvokeOption) error {
+	return s.Invoke(function, append(opts, invokeContextOption{ctx})...)
+}
+
+type invokeContextOption struct{ ctx context.Context }
+
+func (o invokeContextOption) applyInvokeOption(opts *invokeOptions) {
+	opts.Context = o.ctx
+}
+
+// withInvokeContext makes ctx the context of the Container until the
+// returned function is called. A nil ctx leaves the context unchanged.
+func (s *Scope) withInvokeContext(ctx context.Context) (restore func()) {
+	state := s.rootScope().state
+	if ctx == nil {
+		return func() {}
+	}
+
+	// Concurrent Invokes may not finish in the order they started, so each
+	// one removes its own context rather than restoring the previous one.
+	entry := &ctx
+	state.contexts = append(state.contexts, entry)
+	return func() {
+		for i, e := range state.contexts {
+			if e == entry {
+				state.contexts = append(state.contexts[:i:i], state.contexts[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (s *Scope) invokeContext() context.Context {
+	if ctxs := s.rootScope().state.contexts; len(ctxs) > 0 {
+		return *ctxs[len(ctxs)-1]
+	}
+	return context.Background()
+}
+
+// isContext reports whether ps may be satisfied with the context of the
+// invocation.
+func (ps paramSingle) isContext() bool {
+	return ps.Type == _contextType && ps.Name == ""
+}
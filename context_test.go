@@ -0,0 +1,170 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeCtx(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey struct{}
+	type A struct{ ctx context.Context }
+	type B struct{}
+
+	t.Run("context is injected into constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(ctx context.Context) *A { return &A{ctx: ctx} })
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		require.NoError(t, c.InvokeCtx(ctx, func(a *A, invokeCtx context.Context) {
+			assert.Equal(t, "value", a.ctx.Value(ctxKey{}))
+			assert.Equal(t, "value", invokeCtx.Value(ctxKey{}))
+		}))
+	})
+
+	t.Run("context is injected into decorators", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireDecorate(func(ctx context.Context, a *A) *A { return &A{ctx: ctx} })
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		require.NoError(t, c.InvokeCtx(ctx, func(a *A) {
+			assert.Equal(t, "value", a.ctx.Value(ctxKey{}))
+		}))
+	})
+
+	t.Run("Invoke uses the background context", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(ctx context.Context) *A { return &A{ctx: ctx} })
+
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, context.Background(), a.ctx)
+		})
+	})
+
+	t.Run("provided context takes precedence", func(t *testing.T) {
+		t.Parallel()
+
+		provided := context.WithValue(context.Background(), ctxKey{}, "provided")
+
+		c := digtest.New(t)
+		c.RequireProvide(func() context.Context { return provided })
+		c.RequireProvide(func(ctx context.Context) *A { return &A{ctx: ctx} })
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "invoke")
+		require.NoError(t, c.InvokeCtx(ctx, func(a *A) {
+			assert.Equal(t, "provided", a.ctx.Value(ctxKey{}))
+		}))
+	})
+
+	t.Run("child scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func(ctx context.Context) *A { return &A{ctx: ctx} })
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		require.NoError(t, child.InvokeCtx(ctx, func(a *A) {
+			assert.Equal(t, "value", a.ctx.Value(ctxKey{}))
+		}))
+	})
+
+	t.Run("canceled context stops resolution", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			cancel()
+			return &A{}
+		})
+		var called bool
+		c.RequireProvide(func(*A) *B {
+			called = true
+			return &B{}
+		})
+
+		err := c.InvokeCtx(ctx, func(*B) {
+			t.Fatal("invoked function must not be called")
+		})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+		assert.False(t, called, "constructor must not be called after cancellation")
+
+		// Resolution resumes with a live context.
+		var invoked bool
+		require.NoError(t, c.InvokeCtx(context.Background(), func(*B) { invoked = true }))
+		assert.True(t, invoked)
+	})
+
+	t.Run("canceled context fails before invoking", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c := digtest.New(t)
+		err := c.InvokeCtx(ctx, func() {
+			t.Fatal("invoked function must not be called")
+		})
+		assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+	})
+
+	t.Run("named context is not injected", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			dig.In
+
+			Ctx context.Context `name:"ctx"`
+		}
+
+		c := digtest.New(t)
+		err := c.InvokeCtx(context.Background(), func(in) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing type: context.Context[name="ctx"]`)
+	})
+
+	t.Run("AssertWireable accepts context parameters", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(ctx context.Context) *A { return &A{ctx: ctx} })
+		assert.NoError(t, dig.AssertWireable(c.Container, func(*A, context.Context) {}))
+	})
+}
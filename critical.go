@@ -0,0 +1,240 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "time"
+
+// CriticalPath reports the depth of the constructors of a Container and its
+// longest chain of constructors. It is returned by Container.CriticalPath.
+type CriticalPath struct {
+	// Path is the chain of constructors depending on each other that took
+	// the longest to build, in the order they were called: each constructor
+	// is a dependency of the next one. Constructors that were not called
+	// count for no time, so before any Invoke, or if no constructor took a
+	// measurable time, Path is the longest chain.
+	Path []PathNode
+
+	// Nodes holds every constructor of the container and its Scopes, in
+	// the order of Container.Providers.
+	Nodes []PathNode
+
+	// Duration is the time spent in the constructors of Path.
+	Duration time.Duration
+}
+
+// PathNode is a constructor along with its position in the graph.
+type PathNode struct {
+	Provider ProviderInfo
+
+	// Depth is the length of the longest chain of constructors ending with
+	// this one. It is 1 for a constructor without dependencies.
+	Depth int
+
+	// Duration is the time the constructor took to build its values, not
+	// counting its dependencies. It is zero if the constructor was not
+	// called, and for transient constructors.
+	Duration time.Duration
+}
+
+// CriticalPath computes the depth of each constructor of the container and
+// the chain of constructors that took the longest to build, which helps
+// attribute the startup latency of an application to specific chains of
+// constructors. The durations are the ones measured by the Invokes of the
+// container so far.
+//
+//	cp := c.CriticalPath()
+//	for _, n := range cp.Path {
+//		fmt.Println(n.Provider.Name, n.Duration)
+//	}
+//
+// Use VisualizeDurations to draw the durations and the path on the graph of
+// the container.
+func (c *Container) CriticalPath() CriticalPath {
+	g := newProviderGraph(c)
+	var cp CriticalPath
+	for i, p := range g.providers {
+		cp.Nodes = append(cp.Nodes, PathNode{
+			Provider: p,
+			Depth:    g.depth(i),
+			Duration: g.nodes[i].duration,
+		})
+	}
+
+	// Longest chains ending with each constructor, as the total time spent
+	// in the chain and the dependency of the constructor in it, or -1.
+	type chain struct {
+		duration time.Duration
+		length   int
+		next     int
+	}
+	chains := make([]*chain, len(g.providers))
+	visiting := make([]bool, len(g.providers))
+	var longest func(i int) *chain
+	longest = func(i int) *chain {
+		if ch := chains[i]; ch != nil {
+			return ch
+		}
+		visiting[i] = true
+		best := chain{next: -1}
+		for _, j := range g.deps(i) {
+			if visiting[j] {
+				// Cycles are only allowed through dig.Lazy.
+				continue
+			}
+			if ch := longest(j); ch.duration > best.duration ||
+				(ch.duration == best.duration && ch.length > best.length) {
+				best = chain{duration: ch.duration, length: ch.length, next: j}
+			}
+		}
+		visiting[i] = false
+		best.duration += cp.Nodes[i].Duration
+		best.length++
+		chains[i] = &best
+		return &best
+	}
+
+	end := -1
+	for i := range g.providers {
+		ch := longest(i)
+		if end < 0 || ch.duration > chains[end].duration ||
+			(ch.duration == chains[end].duration && ch.length > chains[end].length) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return cp
+	}
+
+	cp.Duration = chains[end].duration
+	for i := end; i >= 0; i = chains[i].next {
+		cp.Path = append(cp.Path, cp.Nodes[i])
+	}
+	for i, j := 0, len(cp.Path)-1; i < j; i, j = i+1, j-1 {
+		cp.Path[i], cp.Path[j] = cp.Path[j], cp.Path[i]
+	}
+	return cp
+}
+
+// providerGraph is the graph of the constructors of a container and its
+// Scopes, with the constructors identified by their index.
+type providerGraph struct {
+	nodes     []*constructorNode
+	providers []ProviderInfo
+	producers map[Key][]int // indexes of the producers of each key
+	consumers map[Key][]int // indexes of the consumers of each key
+
+	// Depths of the constructors, or -1 while they are being computed,
+	// which stops at cycles such as the ones allowed through dig.Lazy.
+	depths []int
+}
+
+func newProviderGraph(c *Container) *providerGraph {
+	g := providerGraph{
+		producers: make(map[Key][]int),
+		consumers: make(map[Key][]int),
+	}
+	for _, s := range c.scope.appendSubscopes(nil) {
+		for _, n := range s.nodes {
+			g.nodes = append(g.nodes, n)
+			g.providers = append(g.providers, newProviderInfo(n))
+		}
+	}
+	g.depths = make([]int, len(g.nodes))
+
+	for i, p := range g.providers {
+		for _, o := range p.Outputs {
+			k := o.Key()
+			g.producers[k] = append(g.producers[k], i)
+		}
+		for _, k := range g.inputs(i) {
+			g.consumers[k] = append(g.consumers[k], i)
+		}
+	}
+	return &g
+}
+
+// inputs returns the distinct keys consumed by the constructor i.
+func (g *providerGraph) inputs(i int) []Key {
+	var keys []Key
+	seen := make(map[Key]struct{}, len(g.providers[i].Inputs))
+	for _, in := range g.providers[i].Inputs {
+		k := in.Key()
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// deps returns the distinct constructors that the constructor i depends on.
+func (g *providerGraph) deps(i int) []int {
+	var deps []int
+	seen := make(map[int]struct{})
+	for _, k := range g.inputs(i) {
+		for _, j := range g.producers[k] {
+			if _, ok := seen[j]; ok {
+				continue
+			}
+			seen[j] = struct{}{}
+			deps = append(deps, j)
+		}
+	}
+	return deps
+}
+
+// dependents returns the distinct constructors that depend on the
+// constructor i.
+func (g *providerGraph) dependents(i int) []int {
+	var dependents []int
+	seen := make(map[int]struct{})
+	for _, o := range g.providers[i].Outputs {
+		for _, j := range g.consumers[o.Key()] {
+			if _, ok := seen[j]; ok {
+				continue
+			}
+			seen[j] = struct{}{}
+			dependents = append(dependents, j)
+		}
+	}
+	return dependents
+}
+
+// depth returns the length of the longest chain of constructors ending with
+// the constructor i.
+func (g *providerGraph) depth(i int) int {
+	switch d := g.depths[i]; {
+	case d > 0:
+		return d
+	case d < 0:
+		return 0
+	}
+	g.depths[i] = -1
+	d := 0
+	for _, j := range g.deps(i) {
+		if dj := g.depth(j); dj > d {
+			d = dj
+		}
+	}
+	g.depths[i] = d + 1
+	return d + 1
+}
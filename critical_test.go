@@ -0,0 +1,149 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriticalPath(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{}
+	type Logger struct{}
+	type DB struct{}
+	type Cache struct{}
+	type Server struct{}
+
+	sleep := func(d time.Duration) { time.Sleep(d) }
+
+	newContainer := func(t *testing.T) *digtest.Container {
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *Logger { return &Logger{} })
+		c.RequireProvide(func(*Config) *DB {
+			sleep(20 * time.Millisecond)
+			return &DB{}
+		})
+		c.RequireProvide(func(*Config, *Logger) *Cache { return &Cache{} })
+		c.RequireProvide(func(*DB, *Cache) *Server { return &Server{} })
+		return c
+	}
+
+	names := func(nodes []dig.PathNode) []string {
+		var names []string
+		for _, n := range nodes {
+			names = append(names, n.Provider.Outputs[0].String())
+		}
+		return names
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, dig.CriticalPath{}, digtest.New(t).CriticalPath())
+	})
+
+	t.Run("longest chain before invoke", func(t *testing.T) {
+		t.Parallel()
+
+		cp := newContainer(t).CriticalPath()
+		assert.Equal(t, []string{
+			"*dig_test.Config", "*dig_test.Logger", "*dig_test.Cache", "*dig_test.Server",
+		}, names(cp.Path))
+		assert.Zero(t, cp.Duration)
+
+		require.Len(t, cp.Nodes, 5)
+		var depths []int
+		for _, n := range cp.Nodes {
+			assert.Zero(t, n.Duration)
+			depths = append(depths, n.Depth)
+		}
+		assert.Equal(t, []int{1, 2, 2, 3, 4}, depths)
+	})
+
+	t.Run("slowest chain after invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireInvoke(func(*Server) {})
+
+		cp := c.CriticalPath()
+		assert.Equal(t, []string{
+			"*dig_test.Config", "*dig_test.DB", "*dig_test.Server",
+		}, names(cp.Path))
+		assert.GreaterOrEqual(t, cp.Nodes[2].Duration, 20*time.Millisecond)
+
+		var total time.Duration
+		for _, n := range cp.Path {
+			total += n.Duration
+		}
+		assert.Equal(t, total, cp.Duration)
+	})
+
+	t.Run("cycles through dig.Lazy", func(t *testing.T) {
+		t.Parallel()
+
+		type A struct{}
+		type B struct{}
+
+		c := digtest.New(t, dig.AllowCyclesVia(dig.LazyDependency))
+		c.RequireProvide(func(dig.Lazy[*B]) *A { return &A{} })
+		c.RequireProvide(func(*A) *B { return &B{} })
+
+		cp := c.CriticalPath()
+		assert.Equal(t, []string{"*dig_test.B", "*dig_test.A"}, names(cp.Path))
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.Scope("child").RequireProvide(func(*Config) *Logger { return &Logger{} })
+
+		cp := c.CriticalPath()
+		assert.Equal(t, []string{"*dig_test.Config", "*dig_test.Logger"}, names(cp.Path))
+	})
+
+	t.Run("visualize durations", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		c.RequireInvoke(func(*Server) {})
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b, dig.VisualizeDurations()))
+		assert.Regexp(t, `constructor_2 \[shape=plaintext label="TestCriticalPath.func\S+ \(\d[^)]*\)"\];`+"\n\t*style=bold;", b.String())
+		// Config, DB and Server.
+		assert.Equal(t, 3, strings.Count(b.String(), "style=bold;"))
+		assert.Equal(t, "VisualizeDurations()", dig.VisualizeDurations().(fmt.Stringer).String())
+	})
+}
@@ -23,6 +23,7 @@ package dig
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/alexisvisco/dig/internal/digreflect"
 	"github.com/alexisvisco/dig/internal/dot"
@@ -40,6 +41,16 @@ type decorator interface {
 	Call(c containerStore) error
 	ID() dot.CtorID
 	State() decoratorState
+
+	// PropagatesToDescendants reports whether the decorator applies to the
+	// descendants of the Scope it was registered to.
+	PropagatesToDescendants() bool
+}
+
+// decoratorApplies reports whether the decorator d found in the Scope at
+// index i of storesToRoot applies to the Scope at index 0.
+func decoratorApplies(d decorator, i int) bool {
+	return i == 0 || d.PropagatesToDescendants()
 }
 
 type decoratorNode struct {
@@ -68,6 +79,21 @@ type decoratorNode struct {
 
 	// Callback for this decorator, if there is one.
 	callback Callback
+
+	// Whether this decorator applies only to the Scope it was provided to,
+	// and not to its descendants.
+	local bool
+
+	// Index of the parameter receiving each value of the decorated group,
+	// if the decorator was given DecorateEach. -1 otherwise.
+	eachIndex int
+
+	// Order of the decorator among the decorators of the same keys in its
+	// Scope. See DecorateOrder.
+	order int
+
+	// Keys of the values decorated by the decorator.
+	keys []key
 }
 
 func newDecoratorNode(dcor interface{}, s *Scope, opts decorateOptions) (*decoratorNode, error) {
@@ -85,26 +111,201 @@ func newDecoratorNode(dcor interface{}, s *Scope, opts decorateOptions) (*decora
 		return nil, err
 	}
 
+	location := opts.Location
+	if location == nil {
+		location = digreflect.InspectFunc(dcor)
+	}
+
+	if opts.Named {
+		if err := namedDecoration(dtype, &pl, &rl, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	eachIndex := -1
+	if opts.Grouped {
+		if eachIndex, err = groupDecoration(dtype, &pl, &rl, s, opts); err != nil {
+			return nil, err
+		}
+	}
+
 	n := &decoratorNode{
-		dcor:     dcor,
-		dtype:    dtype,
-		id:       dot.CtorID(dptr),
-		location: digreflect.InspectFunc(dcor),
-		orders:   make(map[*Scope]int),
-		params:   pl,
-		results:  rl,
-		s:        s,
-		callback: opts.Callback,
+		dcor:      dcor,
+		dtype:     dtype,
+		id:        dot.CtorID(dptr),
+		location:  location,
+		orders:    make(map[*Scope]int),
+		params:    pl,
+		results:   rl,
+		s:         s,
+		callback:  opts.Callback,
+		local:     opts.Local,
+		eachIndex: eachIndex,
+		order:     opts.Order,
 	}
 	return n, nil
 }
 
+// groupDecoration turns the decorator of a plain type, given DecorateGroup
+// or DecorateEach, into a decorator of the corresponding value group: its
+// result, and its parameters of the decorated type, are replaced by the
+// group. With DecorateEach, it returns the index of the parameter that
+// receives each value of the group.
+func groupDecoration(dtype reflect.Type, pl *paramList, rl *resultList, s *Scope, opts decorateOptions) (int, error) {
+	option := "DecorateGroup"
+	if opts.Each {
+		option = "DecorateEach"
+	}
+
+	if opts.Group == "" {
+		return -1, newErrInvalidInput(fmt.Sprintf(
+			"cannot use %v with %v: the name of the group must not be empty", option, dtype), nil)
+	}
+
+	var rs resultSingle
+	if len(rl.Results) == 1 {
+		rs, _ = rl.Results[0].(resultSingle)
+	}
+	if rs.Type == nil || rs.Name != "" || len(rs.As) > 0 {
+		return -1, newErrInvalidInput(fmt.Sprintf(
+			"cannot use %v(%q) with %v: the decorator must return a single value, and optionally an error",
+			option, opts.Group, dtype), nil)
+	}
+
+	sliceType := rs.Type
+	if opts.Each {
+		sliceType = reflect.SliceOf(rs.Type)
+	} else if rs.Type.Kind() != reflect.Slice {
+		return -1, newErrInvalidInput(fmt.Sprintf(
+			"cannot use %v(%q) with %v: the decorator must return a slice, not %v",
+			option, opts.Group, dtype, rs.Type), nil)
+	}
+	rl.Results[0] = resultGrouped{Group: opts.Group, Type: sliceType}
+
+	eachIndex := -1
+	for i, p := range pl.Params {
+		ps, ok := p.(paramSingle)
+		if !ok || ps.Type != rs.Type || ps.Name != "" {
+			continue
+		}
+		if opts.Each && eachIndex >= 0 {
+			return -1, newErrInvalidInput(fmt.Sprintf(
+				"cannot use %v(%q) with %v: the decorator must accept a single %v",
+				option, opts.Group, dtype, rs.Type), nil)
+		}
+
+		pg := paramGroupedSlice{Group: opts.Group, Type: sliceType, orders: make(map[*Scope]int)}
+		s.newGraphNode(&pg, pg.orders)
+		pl.Params[i] = pg
+		eachIndex = i
+	}
+	if !opts.Each {
+		return -1, nil
+	}
+	if eachIndex < 0 {
+		return -1, newErrInvalidInput(fmt.Sprintf(
+			"cannot use %v(%q) with %v: the decorator must accept the %v it returns",
+			option, opts.Group, dtype, rs.Type), nil)
+	}
+	return eachIndex, nil
+}
+
+// namedDecoration turns the decorator of plain types, given DecorateNamed,
+// into a decorator of the values with the given name: its results, and its
+// parameters of the same types, are given the name.
+func namedDecoration(dtype reflect.Type, pl *paramList, rl *resultList, opts decorateOptions) error {
+	if opts.Grouped {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot use DecorateNamed(%q) with %v: it cannot be used with DecorateGroup or DecorateEach",
+			opts.Name, dtype), nil)
+	}
+	if opts.Name == "" {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot use DecorateNamed with %v: the name must not be empty", dtype), nil)
+	}
+
+	types := make(map[reflect.Type]struct{}, len(rl.Results))
+	for i, r := range rl.Results {
+		rs, ok := r.(resultSingle)
+		if !ok || rs.Name != "" {
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot use DecorateNamed(%q) with %v: the decorator must return plain values, and optionally an error",
+				opts.Name, dtype), nil)
+		}
+		rs.Name = opts.Name
+		rl.Results[i] = rs
+		types[rs.Type] = struct{}{}
+	}
+
+	for i, p := range pl.Params {
+		switch p := p.(type) {
+		case paramSingle:
+			if _, ok := types[p.Type]; ok && p.Name == "" {
+				p.Name = opts.Name
+				pl.Params[i] = p
+			}
+		case paramOriginal:
+			if _, ok := types[p.Elem.Type]; ok && p.Elem.Name == "" {
+				p.Elem.Name = opts.Name
+				pl.Params[i] = p
+			}
+		}
+	}
+	return nil
+}
+
+// callEach calls the decorator once for each value of the decorated
+// group, and returns the results of the decorator with the decorated
+// values collected in a slice.
+func (n *decoratorNode) callEach(s containerStore, args []reflect.Value) []reflect.Value {
+	values := args[n.eachIndex]
+	decorated := reflect.MakeSlice(values.Type(), 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		eachArgs := append([]reflect.Value(nil), args...)
+		eachArgs[n.eachIndex] = values.Index(i)
+		results := s.invoker()(reflect.ValueOf(n.dcor), eachArgs)
+		if last := results[len(results)-1]; isError(last.Type()) && !last.IsNil() {
+			return results
+		}
+		decorated = reflect.Append(decorated, results[0])
+	}
+
+	results := make([]reflect.Value, n.dtype.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(n.dtype.Out(i))
+	}
+	results[0] = decorated
+	return results
+}
+
 func (n *decoratorNode) Call(s containerStore) (err error) {
 	if n.state == decoratorCalled {
 		return nil
 	}
 
 	n.state = decoratorOnStack
+	defer n.s.parallelism().lockExclusive()()
+	defer func() {
+		// A decorator that couldn't run must remain eligible for later
+		// resolutions, e.g. once its missing dependencies are provided.
+		if err != nil {
+			n.state = decoratorReady
+		}
+	}()
+
+	// The decorators applied before this one to the same keys run first,
+	// even if this decorator does not depend on their values, so that they
+	// never overwrite the values it decorates.
+	for _, k := range n.keys {
+		for _, d := range n.s.decorators[k] {
+			if d == n {
+				break
+			}
+			if err := d.Call(s); err != nil {
+				return err
+			}
+		}
+	}
 
 	if err := shallowCheckDependencies(s, n.params); err != nil {
 		return errMissingDependencies{
@@ -121,12 +322,16 @@ func (n *decoratorNode) Call(s containerStore) (err error) {
 		}
 	}
 
+	var runtime time.Duration
 	if n.callback != nil {
 		// Wrap in separate func to include PanicErrors
 		defer func() {
 			n.callback(CallbackInfo{
-				Name:  fmt.Sprintf("%v.%v", n.location.Package, n.location.Name),
-				Error: err,
+				Name:      fmt.Sprintf("%v.%v", n.location.Package, n.location.Name),
+				Error:     err,
+				Runtime:   runtime,
+				Outputs:   newOutputs(n.results.DotResult()),
+				ScopePath: n.s.path(),
 			})
 		}()
 	}
@@ -134,15 +339,19 @@ func (n *decoratorNode) Call(s containerStore) (err error) {
 	if n.s.recoverFromPanics {
 		defer func() {
 			if p := recover(); p != nil {
-				err = PanicError{
-					fn:    n.location,
-					Panic: p,
-				}
+				err = newPanicError(n.location, false /* constructor */, p)
 			}
 		}()
 	}
 
-	results := s.invoker()(reflect.ValueOf(n.dcor), args)
+	start := time.Now()
+	var results []reflect.Value
+	if n.eachIndex >= 0 {
+		results = n.callEach(s, args)
+	} else {
+		results = s.invoker()(reflect.ValueOf(n.dcor), args)
+	}
+	runtime = time.Since(start)
 	if err = n.results.ExtractList(n.s, true /* decorated */, results); err != nil {
 		return err
 	}
@@ -154,6 +363,8 @@ func (n *decoratorNode) ID() dot.CtorID { return n.id }
 
 func (n *decoratorNode) State() decoratorState { return n.state }
 
+func (n *decoratorNode) PropagatesToDescendants() bool { return !n.local }
+
 // DecorateOption modifies the default behavior of Decorate.
 type DecorateOption interface {
 	apply(*decorateOptions)
@@ -162,6 +373,26 @@ type DecorateOption interface {
 type decorateOptions struct {
 	Info     *DecorateInfo
 	Callback Callback
+	Local    bool
+
+	// Grouped is set if the decorator was given DecorateGroup or
+	// DecorateEach, in which case it decorates the value group Group, and
+	// Each reports whether it decorates the values one by one.
+	Grouped bool
+	Group   string
+	Each    bool
+
+	// Named is set if the decorator was given DecorateNamed, in which case
+	// it decorates the values named Name.
+	Named bool
+	Name  string
+
+	// Order of the decorator among the decorators of the same keys. See
+	// DecorateOrder.
+	Order int
+
+	// Location of the decorator, if it was annotated with Annotate.
+	Location *digreflect.Func
 }
 
 // FillDecorateInfo is a DecorateOption that writes info on what Dig was
@@ -180,6 +411,140 @@ func (o fillDecorateInfoOption) apply(opts *decorateOptions) {
 	opts.Info = o.info
 }
 
+// PropagateToDescendants is a DecorateOption that specifies whether the
+// decoration applies to the descendants of the Scope it is registered to.
+// Decorations propagate to descendants by default.
+//
+// With PropagateToDescendants(false), only functions resolved by the Scope
+// itself receive the decorated value; child Scopes, and their own children,
+// see the value as it was before the decoration.
+//
+//	child.Decorate(decorateLogger, dig.PropagateToDescendants(false))
+//
+// Decorations never apply to sibling Scopes or to ancestors, regardless of
+// this option.
+func PropagateToDescendants(propagate bool) DecorateOption {
+	return propagateToDescendantsOption(propagate)
+}
+
+type propagateToDescendantsOption bool
+
+func (o propagateToDescendantsOption) String() string {
+	return fmt.Sprintf("PropagateToDescendants(%v)", bool(o))
+}
+
+func (o propagateToDescendantsOption) apply(opts *decorateOptions) {
+	opts.Local = !bool(o)
+}
+
+// DecorateGroup is a DecorateOption that makes a decorator of the form
+//
+//	func([]T, ...) []T
+//
+// decorate the value group with the given name, without the need for
+// dig.In and dig.Out structs. Parameters of type []T receive the values of
+// the group, and the returned slice replaces them.
+//
+//	c.Decorate(func(hs []Handler) []Handler {
+//	  return append(hs, healthHandler)
+//	}, dig.DecorateGroup("handlers"))
+func DecorateGroup(group string) DecorateOption {
+	return decorateGroupOption{group: group}
+}
+
+// DecorateEach is a DecorateOption that makes a decorator of the form
+//
+//	func(T, ...) T
+//
+// decorate the values of the value group with the given name one by one.
+// The decorator is called once for each value of the group, which it
+// receives as its parameter of type T, and the value it returns replaces
+// it. Other parameters of the decorator are resolved once.
+//
+//	c.Decorate(func(h Handler, m *Metrics) Handler {
+//	  return m.Wrap(h)
+//	}, dig.DecorateEach("handlers"))
+//
+// If the decorator returns an error for any of the values, the decoration
+// fails with that error.
+func DecorateEach(group string) DecorateOption {
+	return decorateGroupOption{group: group, each: true}
+}
+
+// DecorateNamed is a DecorateOption that makes a decorator of plain types
+// decorate the values with the given name, without the need for dig.In and
+// dig.Out structs. The results of the decorator, and its parameters of the
+// same types, are the values named name; its other parameters are resolved
+// as usual.
+//
+//	c.Decorate(func(db *sql.DB, m *Metrics) *sql.DB {
+//	  return m.Instrument(db)
+//	}, dig.DecorateNamed("primary"))
+//
+// This decorates the *sql.DB named "primary", while the one named "replica"
+// and the unnamed one, if any, are left as they are.
+func DecorateNamed(name string) DecorateOption {
+	return decorateNamedOption(name)
+}
+
+type decorateNamedOption string
+
+func (o decorateNamedOption) String() string {
+	return fmt.Sprintf("DecorateNamed(%q)", string(o))
+}
+
+func (o decorateNamedOption) apply(opts *decorateOptions) {
+	opts.Named = true
+	opts.Name = string(o)
+}
+
+// DecorateOrder is a DecorateOption that sets the order of a decorator
+// among the decorators of the same values in a Scope. Decorators are
+// applied by increasing order, and in the order they were registered for
+// the same order, which is 0 by default. Each decorator receives the values
+// returned by the decorators applied before it.
+//
+//	c.Decorate(withTracing)
+//	c.Decorate(withMetrics)
+//	c.Decorate(withRecovery, dig.DecorateOrder(-1))
+//
+// Here the *Handler provided to the container is wrapped by withRecovery,
+// then withTracing, then withMetrics, and the functions depending on a
+// *Handler receive the one returned by withMetrics. Decorators of parent
+// Scopes are applied before those of their children, regardless of their
+// orders. Use Scope.DecorationChain to list the decorators of a value.
+func DecorateOrder(order int) DecorateOption {
+	return decorateOrderOption(order)
+}
+
+type decorateOrderOption int
+
+func (o decorateOrderOption) String() string {
+	return fmt.Sprintf("DecorateOrder(%d)", int(o))
+}
+
+func (o decorateOrderOption) apply(opts *decorateOptions) {
+	opts.Order = int(o)
+}
+
+type decorateGroupOption struct {
+	group string
+	each  bool
+}
+
+func (o decorateGroupOption) String() string {
+	if o.each {
+		return fmt.Sprintf("DecorateEach(%q)", o.group)
+	}
+	return fmt.Sprintf("DecorateGroup(%q)", o.group)
+}
+
+func (o decorateGroupOption) apply(opts *decorateOptions) {
+	opts.Grouped = true
+	opts.Group = o.group
+	opts.Each = o.each
+}
+
 // DecorateInfo provides information about the decorator's inputs and outputs
 // types as strings, as well as the ID of the decorator supplied to the Container.
 type DecorateInfo struct {
@@ -229,15 +594,58 @@ func (c *Container) Decorate(decorator interface{}, opts ...DecorateOption) erro
 //	  return log, scope
 //	})
 //
-// Decorating a Scope affects all the child scopes of this Scope.
+// Decorating a Scope affects all the child scopes of this Scope, unless
+// PropagateToDescendants(false) is specified.
+//
+// The parameters of a decorator are resolved against everything visible from
+// the Scope it was registered to: values provided to the Scope itself, values
+// inherited from its ancestors, and the decorations applied by those Scopes.
+// Decorations from ancestors are applied before decorations of the Scope, so
+// a decorator of *zap.Logger in a child Scope receives the *zap.Logger as
+// decorated by the parent. A decorator cannot depend on its own output: a
+// parameter of the decorated type always receives the value as it was before
+// this decorator ran.
+//
+// To derive a value of a Scope from the one provided to the container,
+// rather than from the one decorated by the Scopes in between, along with
+// values local to the Scope, depend on its Original:
+//
+//	tenant.Decorate(func(log dig.Original[*zap.Logger], cfg *TenantConfig) *zap.Logger {
+//	  return log.Get().Named(cfg.Name)
+//	})
+//
+// Functions of the Scope and of its descendants receive the decorated
+// *zap.Logger, while the rest of the container keeps the original one.
+//
+// Several decorators of the same values may be registered to a Scope. They
+// form a chain, applied in the order they were registered, or as set by
+// DecorateOrder: each decorator receives the values returned by the one
+// before it, and functions depending on the values receive the ones
+// returned by the last one. All the decorators of a chain must propagate to
+// descendants, or none of them. Scope.DecorationChain lists the decorators
+// applied to a value.
 //
 // Similar to a provider, the decorator function gets called *at most once*.
 func (s *Scope) Decorate(decorator interface{}, opts ...DecorateOption) error {
-	var options decorateOptions
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	decorator, location, err := unannotate(decorator)
+	if err != nil {
+		return err
+	}
+
+	options := decorateOptions{Location: location}
 	for _, opt := range opts {
 		opt.apply(&options)
 	}
+	return s.parallelism().do(func() error {
+		return s.decorate(decorator, options)
+	})
+}
 
+func (s *Scope) decorate(decorator interface{}, options decorateOptions) error {
 	dn, err := newDecoratorNode(decorator, s, options)
 	if err != nil {
 		return err
@@ -248,39 +656,50 @@ func (s *Scope) Decorate(decorator interface{}, opts ...DecorateOption) error {
 		return err
 	}
 	for _, k := range keys {
-		if _, ok := s.decorators[k]; ok {
-			return newErrInvalidInput(
-				fmt.Sprintf("cannot decorate using function %v: %s already decorated", dn.dtype, k), nil)
+		for _, d := range s.decorators[k] {
+			if d.local == dn.local {
+				continue
+			}
+			if d.local {
+				return newErrInvalidInput(fmt.Sprintf(
+					"cannot decorate using function %v: %s already decorated by %v, which does not propagate to descendants",
+					dn.dtype, k, d.location), nil)
+			}
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot decorate using function %v: %s already decorated by %v, which propagates to descendants",
+				dn.dtype, k, d.location), nil)
 		}
-		s.decorators[k] = dn
 	}
+	dn.keys = keys
+	for _, k := range keys {
+		s.decorators[k] = insertDecorator(s.decorators[k], dn)
+	}
+	s.decoratorNodes = append(s.decoratorNodes, dn)
 
 	if info := options.Info; info != nil {
 		params := dn.params.DotParam()
 		results := dn.results.DotResult()
 		info.ID = (ID)(dn.id)
-		info.Inputs = make([]*Input, len(params))
-		info.Outputs = make([]*Output, len(results))
-
-		for i, param := range params {
-			info.Inputs[i] = &Input{
-				t:        param.Type,
-				optional: param.Optional,
-				name:     param.Name,
-				group:    param.Group,
-			}
-		}
-		for i, res := range results {
-			info.Outputs[i] = &Output{
-				t:     res.Type,
-				name:  res.Name,
-				group: res.Group,
-			}
-		}
+		info.Inputs = newInputs(params)
+		info.Outputs = newOutputs(results)
 	}
 	return nil
 }
 
+// insertDecorator returns a copy of the decorators of a key, in the order
+// they are applied, with d inserted after the decorators of the same or a
+// lower order.
+func insertDecorator(chain []*decoratorNode, d *decoratorNode) []*decoratorNode {
+	i := len(chain)
+	for i > 0 && chain[i-1].order > d.order {
+		i--
+	}
+	inserted := make([]*decoratorNode, 0, len(chain)+1)
+	inserted = append(inserted, chain[:i]...)
+	inserted = append(inserted, d)
+	return append(inserted, chain[i:]...)
+}
+
 func findResultKeys(r resultList) ([]key, error) {
 	// use BFS to search for all keys included in a resultList.
 	var (
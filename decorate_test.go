@@ -23,11 +23,12 @@ package dig_test
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/alexisvisco/dig"
-	"github.com/alexisvisco/dig/internal/digtest"
+	"github.com/alexisvisco/dig/digtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -581,6 +582,432 @@ func TestDecorateSuccess(t *testing.T) {
 			assert.Equal(t, 42, i.Int)
 		})
 	})
+
+	t.Run("scope decorator combines parent value with scope-local value", func(t *testing.T) {
+		type Config struct {
+			Suffix string
+		}
+		type Service struct {
+			Name string
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Service { return &Service{Name: "root"} })
+
+		child := c.Scope("child")
+		child.RequireProvide(func() *Config { return &Config{Suffix: "-child"} })
+		child.RequireDecorate(func(s *Service, cfg *Config) *Service {
+			return &Service{Name: s.Name + cfg.Suffix}
+		})
+
+		grandchild := child.Scope("grandchild")
+
+		child.RequireInvoke(func(s *Service) {
+			assert.Equal(t, "root-child", s.Name)
+		})
+		grandchild.RequireInvoke(func(s *Service) {
+			assert.Equal(t, "root-child", s.Name)
+		})
+		c.RequireInvoke(func(s *Service) {
+			assert.Equal(t, "root", s.Name, "decoration must not leak to the parent")
+		})
+	})
+
+	t.Run("scope decorator sees decorations of its dependencies", func(t *testing.T) {
+		type Config struct {
+			Suffix string
+		}
+		type Service struct {
+			Name string
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Service { return &Service{Name: "root"} })
+		c.RequireProvide(func() *Config { return &Config{Suffix: "-cfg"} })
+		c.RequireDecorate(func(s *Service) *Service { return &Service{Name: s.Name + "+parent"} })
+
+		child := c.Scope("child")
+		child.RequireDecorate(func(s *Service, cfg *Config) *Service {
+			return &Service{Name: s.Name + cfg.Suffix}
+		})
+		child.RequireDecorate(func(cfg *Config) *Config {
+			return &Config{Suffix: cfg.Suffix + "+child"}
+		})
+
+		child.RequireInvoke(func(s *Service) {
+			assert.Equal(t, "root+parent-cfg+child", s.Name)
+		})
+	})
+
+	t.Run("decorator retried after its missing dependency is provided", func(t *testing.T) {
+		type Config struct {
+			Suffix string
+		}
+		type Service struct {
+			Name string
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Service { return &Service{Name: "root"} })
+
+		child := c.Scope("child")
+		child.RequireDecorate(func(s *Service, cfg *Config) *Service {
+			return &Service{Name: s.Name + cfg.Suffix}
+		})
+		require.Error(t, child.Invoke(func(*Service) {}))
+
+		child.RequireProvide(func() *Config { return &Config{Suffix: "-child"} })
+		child.RequireInvoke(func(s *Service) {
+			assert.Equal(t, "root-child", s.Name)
+		})
+	})
+
+	t.Run("decoration that does not propagate to descendants", func(t *testing.T) {
+		type A struct {
+			name string
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "A"} })
+		c.RequireDecorate(func(a *A) *A { return &A{name: a.name + "'"} })
+
+		child := c.Scope("child")
+		sibling := c.Scope("sibling")
+		grandchild := child.Scope("grandchild")
+		child.RequireDecorate(func(a *A) *A {
+			return &A{name: a.name + "+"}
+		}, dig.PropagateToDescendants(false))
+
+		child.RequireInvoke(func(a *A) {
+			assert.Equal(t, "A'+", a.name, "expected local decoration in child")
+		})
+		grandchild.RequireInvoke(func(a *A) {
+			assert.Equal(t, "A'", a.name, "expected only parent decoration in grandchild")
+		})
+		sibling.RequireInvoke(func(a *A) {
+			assert.Equal(t, "A'", a.name, "expected only parent decoration in sibling")
+		})
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "A'", a.name, "expected only parent decoration in root")
+		})
+	})
+
+	t.Run("value group decoration that does not propagate to descendants", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Values []string `group:"values"`
+		}
+		type Result struct {
+			dig.Out
+
+			Values []string `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("values"))
+
+		child := c.Scope("child")
+		grandchild := child.Scope("grandchild")
+		child.RequireDecorate(func(p Param) Result {
+			return Result{Values: append(p.Values, "b")}
+		}, dig.PropagateToDescendants(false))
+
+		child.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"a", "b"}, p.Values)
+		})
+		grandchild.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"a"}, p.Values)
+		})
+	})
+
+	t.Run("decoration propagates to descendants explicitly", func(t *testing.T) {
+		type A struct {
+			name string
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "A"} })
+
+		child := c.Scope("child")
+		grandchild := child.Scope("grandchild")
+		child.RequireDecorate(func(a *A) *A {
+			return &A{name: a.name + "'"}
+		}, dig.PropagateToDescendants(true))
+
+		grandchild.RequireInvoke(func(a *A) {
+			assert.Equal(t, "A'", a.name)
+		})
+	})
+
+	t.Run("decorate a whole value group", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Values []string `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("values"))
+		c.RequireProvide(func() string { return "b" }, dig.Group("values"))
+		c.RequireProvide(func() int { return 3 })
+
+		c.RequireDecorate(func(values []string, n int) []string {
+			return append(values, strconv.Itoa(n))
+		}, dig.DecorateGroup("values"))
+
+		c.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"a", "b", "3"}, p.Values)
+		})
+	})
+
+	t.Run("decorate each value of a value group", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Values []string `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("values"))
+		c.RequireProvide(func() string { return "b" }, dig.Group("values"))
+		c.RequireProvide(func() int { return 3 })
+
+		calls := 0
+		c.RequireDecorate(func(value string, n int) (string, error) {
+			calls++
+			return strings.Repeat(value, n), nil
+		}, dig.DecorateEach("values"))
+
+		c.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"aaa", "bbb"}, p.Values)
+		})
+		c.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"aaa", "bbb"}, p.Values)
+		})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("decorate each value of an empty value group", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Values []string `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireDecorate(func(value string) string {
+			return value + "'"
+		}, dig.DecorateEach("values"))
+
+		c.RequireInvoke(func(p Param) {
+			assert.Empty(t, p.Values)
+		})
+	})
+
+	t.Run("decorate a named value", func(t *testing.T) {
+		type A struct{ Name string }
+		type Param struct {
+			dig.In
+
+			Primary *A `name:"primary"`
+			Replica *A `name:"replica"`
+			Default *A
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{Name: "primary"} }, dig.Name("primary"))
+		c.RequireProvide(func() *A { return &A{Name: "replica"} }, dig.Name("replica"))
+		c.RequireProvide(func() *A { return &A{Name: "default"} })
+		c.RequireProvide(func() string { return "decorated" })
+
+		var info dig.DecorateInfo
+		c.RequireDecorate(func(a *A, suffix string) *A {
+			return &A{Name: a.Name + " " + suffix}
+		}, dig.DecorateNamed("primary"), dig.FillDecorateInfo(&info))
+
+		c.RequireInvoke(func(p Param) {
+			assert.Equal(t, "primary decorated", p.Primary.Name)
+			assert.Equal(t, "replica", p.Replica.Name)
+			assert.Equal(t, "default", p.Default.Name)
+		})
+
+		require.Len(t, info.Inputs, 2)
+		assert.Equal(t, `*dig_test.A[name = "primary"]`, info.Inputs[0].String())
+		assert.Equal(t, "string", info.Inputs[1].String())
+		require.Len(t, info.Outputs, 1)
+		assert.Equal(t, `*dig_test.A[name = "primary"]`, info.Outputs[0].String())
+	})
+
+	t.Run("decorate several named values", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() (int, string) { return 1, "a" }, dig.Name("x"))
+		c.RequireDecorate(func(n int, s string) (int, string) {
+			return n + 1, s + "'"
+		}, dig.DecorateNamed("x"))
+
+		type Param struct {
+			dig.In
+
+			N int    `name:"x"`
+			S string `name:"x"`
+		}
+		c.RequireInvoke(func(p Param) {
+			assert.Equal(t, 2, p.N)
+			assert.Equal(t, "a'", p.S)
+		})
+	})
+
+	t.Run("chain decorators in registration order", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" })
+		c.RequireDecorate(func(s string) string { return s + "b" })
+		c.RequireDecorate(func(s string) string { return s + "c" })
+		c.RequireDecorate(func(s string) string { return s + "d" })
+
+		c.RequireInvoke(func(s string) {
+			assert.Equal(t, "abcd", s)
+		})
+	})
+
+	t.Run("chain decorators by order", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" })
+		c.RequireDecorate(func(s string) string { return s + "b" }, dig.DecorateOrder(1))
+		c.RequireDecorate(func(s string) string { return s + "c" })
+		c.RequireDecorate(func(s string) string { return s + "d" }, dig.DecorateOrder(-1))
+		c.RequireDecorate(func(s string) string { return s + "e" }, dig.DecorateOrder(1))
+
+		child := c.Scope("child")
+		child.RequireDecorate(func(s string) string { return s + "f" }, dig.DecorateOrder(-10))
+
+		child.RequireInvoke(func(s string) {
+			assert.Equal(t, "adcbef", s)
+		})
+		c.RequireInvoke(func(s string) {
+			assert.Equal(t, "adcbe", s)
+		})
+	})
+
+	t.Run("chained decorators run in order even if unused", func(t *testing.T) {
+		type A struct{ Name string }
+		type B struct{ Name string }
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, *B) { return &A{Name: "a"}, &B{Name: "b"} })
+		var calls []string
+		c.RequireDecorate(func(a *A, b *B) (*A, *B) {
+			calls = append(calls, "first")
+			return &A{Name: a.Name + "'"}, &B{Name: b.Name + "'"}
+		})
+		c.RequireDecorate(func() *A {
+			calls = append(calls, "second")
+			return &A{Name: "replaced"}
+		})
+
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "replaced", a.Name)
+		})
+		c.RequireInvoke(func(a *A, b *B) {
+			assert.Equal(t, "replaced", a.Name)
+			assert.Equal(t, "b'", b.Name)
+		})
+		assert.Equal(t, []string{"first", "second"}, calls)
+	})
+
+	t.Run("chain value group decorators", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Values []string `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("values"))
+		c.RequireDecorate(func(values []string) []string {
+			return append(values, "b")
+		}, dig.DecorateGroup("values"))
+		c.RequireDecorate(func(value string) string {
+			return value + "'"
+		}, dig.DecorateEach("values"))
+
+		c.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"a'", "b'"}, p.Values)
+		})
+	})
+
+	t.Run("decorator receives the undecorated value", func(t *testing.T) {
+		type Store struct{ Name string }
+		type Param struct {
+			dig.In
+
+			Raw     *Store `decorated:"false"`
+			Current *Store
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Store { return &Store{Name: "raw"} })
+		c.RequireDecorate(func(s *Store) *Store { return &Store{Name: s.Name + "+metrics"} })
+		c.RequireDecorate(func(p Param) *Store {
+			return &Store{Name: p.Current.Name + "+cache(" + p.Raw.Name + ")"}
+		})
+
+		child := c.Scope("child")
+		child.RequireDecorate(func(s *Store) *Store { return &Store{Name: s.Name + "+child"} })
+		require.NoError(t, c.Validate())
+		child.RequireInvoke(func(p Param) {
+			assert.Equal(t, "raw", p.Raw.Name)
+			assert.Equal(t, "raw+metrics+cache(raw)+child", p.Current.Name)
+		})
+		c.RequireInvoke(func(p Param) {
+			assert.Equal(t, "raw", p.Raw.Name)
+			assert.Equal(t, "raw+metrics+cache(raw)", p.Current.Name)
+		})
+	})
+
+	t.Run("undecorated value group", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Raw     []string `group:"values" decorated:"false"`
+			Current []string `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("values"))
+		c.RequireDecorate(func(value string) string {
+			return value + "'"
+		}, dig.DecorateEach("values"))
+
+		c.RequireInvoke(func(p Param) {
+			assert.Equal(t, []string{"a"}, p.Raw)
+			assert.Equal(t, []string{"a'"}, p.Current)
+		})
+	})
+
+	t.Run("undecorated lazy and optional values", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Lazy     dig.Lazy[string]     `decorated:"false"`
+			Optional dig.Optional[string] `decorated:"false"`
+			Missing  int                  `decorated:"false" optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" })
+		c.RequireDecorate(func(s string) string { return s + "'" })
+
+		c.RequireInvoke(func(p Param) {
+			s, err := p.Lazy.Get()
+			require.NoError(t, err)
+			assert.Equal(t, "a", s)
+			s, ok := p.Optional.Get()
+			require.True(t, ok)
+			assert.Equal(t, "a", s)
+			assert.Zero(t, p.Missing)
+		})
+	})
 }
 
 func TestDecorateFailure(t *testing.T) {
@@ -598,7 +1025,7 @@ func TestDecorateFailure(t *testing.T) {
 		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
 	})
 
-	t.Run("decorate the same type twice", func(t *testing.T) {
+	t.Run("decorate the same type twice with different propagations", func(t *testing.T) {
 		t.Parallel()
 
 		c := digtest.New(t)
@@ -608,9 +1035,26 @@ func TestDecorateFailure(t *testing.T) {
 		c.RequireProvide(func() *A { return &A{Name: "A"} })
 		c.RequireDecorate(func(a *A) *A { return &A{Name: a.Name + "'"} })
 
+		err := c.Decorate(func(a *A) *A { return &A{Name: a.Name + "'"} }, dig.PropagateToDescendants(false))
+		require.Error(t, err, "expected second call to decorate to fail.")
+		assert.Contains(t, err.Error(), "*dig_test.A already decorated by")
+		assert.Regexp(t, `, which propagates to descendants$`, err.Error())
+	})
+
+	t.Run("decorate the same type twice, locally first", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		type A struct {
+			Name string
+		}
+		c.RequireProvide(func() *A { return &A{Name: "A"} })
+		c.RequireDecorate(func(a *A) *A { return &A{Name: a.Name + "'"} }, dig.PropagateToDescendants(false))
+
 		err := c.Decorate(func(a *A) *A { return &A{Name: a.Name + "'"} })
 		require.Error(t, err, "expected second call to decorate to fail.")
-		assert.Contains(t, err.Error(), "*dig_test.A already decorated")
+		assert.Contains(t, err.Error(), "*dig_test.A already decorated by")
+		assert.Regexp(t, `, which does not propagate to descendants$`, err.Error())
 	})
 
 	t.Run("decorator returns an error", func(t *testing.T) {
@@ -767,11 +1211,11 @@ func TestDecorateFailure(t *testing.T) {
 					Name: p.Value,
 				},
 			}
-		})
+		}, dig.PropagateToDescendants(false))
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "cannot decorate")
 		assert.Contains(t, err.Error(), "function func(dig_test.Param) dig_test.Result")
-		assert.Contains(t, err.Error(), "*dig_test.A already decorated")
+		assert.Contains(t, err.Error(), "*dig_test.A already decorated by")
 	})
 
 	t.Run("decorate value group with a single value", func(t *testing.T) {
@@ -789,6 +1233,180 @@ func TestDecorateFailure(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "decorating a value group requires decorating the entire value group")
 	})
+
+	t.Run("decorate each value fails", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			Values []string `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("values"))
+		c.RequireDecorate(func(value string) (string, error) {
+			return "", errors.New("great sadness")
+		}, dig.DecorateEach("values"))
+
+		err := c.Invoke(func(p Param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("invalid group decorators", func(t *testing.T) {
+		tests := []struct {
+			desc      string
+			decorator interface{}
+			opt       dig.DecorateOption
+			wantErr   string
+		}{
+			{
+				desc:      "empty group",
+				decorator: func(v []string) []string { return v },
+				opt:       dig.DecorateGroup(""),
+				wantErr:   "the name of the group must not be empty",
+			},
+			{
+				desc:      "multiple results",
+				decorator: func(v []string) ([]string, int) { return v, 0 },
+				opt:       dig.DecorateGroup("values"),
+				wantErr:   "the decorator must return a single value, and optionally an error",
+			},
+			{
+				desc:      "not a slice",
+				decorator: func(v string) string { return v },
+				opt:       dig.DecorateGroup("values"),
+				wantErr:   "the decorator must return a slice, not string",
+			},
+			{
+				desc:      "each without the value",
+				decorator: func(int) string { return "" },
+				opt:       dig.DecorateEach("values"),
+				wantErr:   "the decorator must accept the string it returns",
+			},
+			{
+				desc:      "each with the value twice",
+				decorator: func(a, b string) string { return a + b },
+				opt:       dig.DecorateEach("values"),
+				wantErr:   "the decorator must accept a single string",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				c := digtest.New(t)
+				err := c.Decorate(tt.decorator, tt.opt)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestDecorateNamedFailure(t *testing.T) {
+	type A struct{}
+	type Out struct {
+		dig.Out
+
+		A *A
+	}
+
+	tests := []struct {
+		desc      string
+		decorator interface{}
+		opts      []dig.DecorateOption
+		wantErr   string
+	}{
+		{
+			desc:      "empty name",
+			decorator: func(a *A) *A { return a },
+			opts:      []dig.DecorateOption{dig.DecorateNamed("")},
+			wantErr:   "cannot use DecorateNamed with func(*dig_test.A) *dig_test.A: the name must not be empty",
+		},
+		{
+			desc:      "dig.Out result",
+			decorator: func(a *A) Out { return Out{A: a} },
+			opts:      []dig.DecorateOption{dig.DecorateNamed("primary")},
+			wantErr:   "the decorator must return plain values, and optionally an error",
+		},
+		{
+			desc:      "with a group",
+			decorator: func(a []*A) []*A { return a },
+			opts:      []dig.DecorateOption{dig.DecorateNamed("primary"), dig.DecorateGroup("values")},
+			wantErr:   "it cannot be used with DecorateGroup or DecorateEach",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := digtest.New(t)
+			err := c.Decorate(tt.decorator, tt.opts...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("named value not provided", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireDecorate(func(a *A) *A { return a }, dig.DecorateNamed("primary"))
+
+		type Param struct {
+			dig.In
+
+			A *A `name:"primary"`
+		}
+		err := c.Invoke(func(Param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing type:`)
+		assert.Contains(t, err.Error(), `*dig_test.A[name="primary"]`)
+	})
+}
+
+func TestUndecoratedFailure(t *testing.T) {
+	t.Run("invalid tag", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			S string `decorated:"no"`
+		}
+
+		c := digtest.New(t)
+		err := c.Invoke(func(Param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid value "no" for "decorated" tag on field S`)
+	})
+
+	t.Run("tag on a dig.In field", func(t *testing.T) {
+		type Inner struct {
+			dig.In
+
+			S string
+		}
+		type Param struct {
+			dig.In
+
+			Inner Inner `decorated:"false"`
+		}
+
+		c := digtest.New(t)
+		err := c.Invoke(func(Param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot use "decorated" tag on field Inner`)
+	})
+
+	t.Run("missing undecorated value", func(t *testing.T) {
+		type Param struct {
+			dig.In
+
+			S string `decorated:"false"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Name("other"))
+		err := c.Invoke(func(Param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: string")
+	})
 }
 
 func TestMultipleDecorates(t *testing.T) {
@@ -937,3 +1555,19 @@ func TestFillDecorateInfoString(t *testing.T) {
 		assert.Contains(t, fmt.Sprint(opt), "FillDecorateInfo(0x")
 	})
 }
+
+func TestPropagateToDescendantsString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "PropagateToDescendants(false)", fmt.Sprint(dig.PropagateToDescendants(false)))
+	assert.Equal(t, "PropagateToDescendants(true)", fmt.Sprint(dig.PropagateToDescendants(true)))
+}
+
+func TestDecorateGroupString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `DecorateGroup("handlers")`, fmt.Sprint(dig.DecorateGroup("handlers")))
+	assert.Equal(t, `DecorateEach("handlers")`, fmt.Sprint(dig.DecorateEach("handlers")))
+	assert.Equal(t, `DecorateNamed("primary")`, fmt.Sprint(dig.DecorateNamed("primary")))
+	assert.Equal(t, `DecorateOrder(-1)`, fmt.Sprint(dig.DecorateOrder(-1)))
+}
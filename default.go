@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A DefaultOption is both a ProvideOption and an InvokeOption.
+type DefaultOption interface {
+	ProvideOption
+	InvokeOption
+}
+
+// Default is an option that gives the optional dependencies of the type of
+// value of a constructor, or of a function given to Invoke, the given value
+// instead of a zero value when they are not provided. This keeps defaults
+// next to where the function is provided.
+//
+//	type ClientParams struct {
+//	  dig.In
+//
+//	  Timeout time.Duration `name:"timeout" optional:"true"`
+//	}
+//
+//	c.Provide(NewClient, dig.Default(30*time.Second))
+//
+// Defaults apply to the parameters of the function, and the fields of its
+// dig.In parameter objects, that are tagged `optional:"true"` and whose
+// type value is assignable to, whatever their names. A default of the exact
+// type of a dependency is preferred over the others, so that interface
+// dependencies may get defaults too:
+//
+//	c.Provide(NewServer, dig.Default(zap.NewNop())) // optional Logger field
+//
+// Defaults are used when the dependency is not provided, or when the
+// dependencies of its constructor are not. Defaults are not added to the
+// container, and do not apply to Optional dependencies, which tell absent
+// values apart instead.
+//
+// Provide and Invoke fail if two defaults have the same type, if several
+// defaults of other types match the same dependency, or if a default does
+// not match any optional dependency of the function.
+func Default(value interface{}) DefaultOption {
+	return defaultOption{value}
+}
+
+type defaultOption struct{ value interface{} }
+
+func (o defaultOption) String() string {
+	return fmt.Sprintf("Default(%v)", o.value)
+}
+
+func (o defaultOption) applyProvideOption(opts *provideOptions) {
+	opts.Defaults = append(opts.Defaults, o.value)
+}
+
+func (o defaultOption) applyInvokeOption(opts *invokeOptions) {
+	opts.Defaults = append(opts.Defaults, o.value)
+}
+
+// withDefaults returns a copy of the paramList where the optional
+// parameters that the given values are assignable to default to these
+// values.
+func (pl paramList) withDefaults(values []interface{}) (paramList, error) {
+	defaults := make([]reflect.Value, len(values))
+	for i, v := range values {
+		t := reflect.TypeOf(v)
+		if t == nil {
+			return pl, newErrInvalidInput("cannot use an untyped nil with dig.Default", nil)
+		}
+		for _, d := range defaults[:i] {
+			if d.Type() == t {
+				return pl, newErrInvalidInput(
+					fmt.Sprintf("cannot use more than one dig.Default of type %v", t), nil)
+			}
+		}
+		defaults[i] = reflect.ValueOf(v)
+	}
+
+	used := make([]bool, len(defaults))
+	params := make([]param, len(pl.Params))
+	for i, p := range pl.Params {
+		var err error
+		if params[i], err = defaultParam(p, defaults, used); err != nil {
+			return pl, err
+		}
+	}
+
+	for i, d := range defaults {
+		if !used[i] {
+			return pl, newErrInvalidInput(
+				fmt.Sprintf("dig.Default of type %v does not match an optional dependency of the function", d.Type()), nil)
+		}
+	}
+
+	pl.Params = params
+	return pl, nil
+}
+
+func defaultParam(p param, defaults []reflect.Value, used []bool) (param, error) {
+	switch p := p.(type) {
+	case paramSingle:
+		if !p.Optional {
+			return p, nil
+		}
+		i, err := matchDefault(p.Type, defaults)
+		if err != nil || i < 0 {
+			return p, err
+		}
+		used[i] = true
+		p.Default = reflect.New(p.Type).Elem()
+		p.Default.Set(defaults[i])
+		return p, nil
+	case paramObject:
+		fields := make([]paramObjectField, len(p.Fields))
+		for i, f := range p.Fields {
+			var err error
+			if f.Param, err = defaultParam(f.Param, defaults, used); err != nil {
+				return p, err
+			}
+			fields[i] = f
+		}
+		p.Fields = fields
+		return p, nil
+	}
+	return p, nil
+}
+
+// matchDefault returns the index of the default for an optional dependency
+// of type t: the default of type t if any, or else the only default
+// assignable to t. It returns -1 if no default matches t.
+func matchDefault(t reflect.Type, defaults []reflect.Value) (int, error) {
+	match := -1
+	for i, d := range defaults {
+		switch {
+		case d.Type() == t:
+			return i, nil
+		case !d.Type().AssignableTo(t):
+			continue
+		case match >= 0:
+			return -1, newErrInvalidInput(fmt.Sprintf(
+				"dig.Default of types %v and %v both match the optional dependency on %v",
+				defaults[match].Type(), d.Type(), t), nil)
+		}
+		match = i
+	}
+	return match, nil
+}
+
+// absent returns the value of the optional parameter ps when it is not
+// provided.
+func (ps paramSingle) absent() reflect.Value {
+	if ps.Default.IsValid() {
+		return ps.Default
+	}
+	return reflect.Zero(ps.Type)
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type defaultName struct{}
+
+func (defaultName) String() string { return "dig_test.defaultName" }
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	type Client struct {
+		Timeout time.Duration
+		Name    string
+	}
+
+	type clientParams struct {
+		dig.In
+
+		Timeout time.Duration `name:"timeout" optional:"true"`
+		Name    string        `optional:"true"`
+	}
+
+	newClient := func(p clientParams) *Client {
+		return &Client{Timeout: p.Timeout, Name: p.Name}
+	}
+
+	t.Run("constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newClient, dig.Default(30*time.Second), dig.Default("client"))
+		c.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, &Client{Timeout: 30 * time.Second, Name: "client"}, cl)
+		})
+	})
+
+	t.Run("provided values take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() time.Duration { return time.Second }, dig.Name("timeout"))
+		c.RequireProvide(newClient, dig.Default(30*time.Second))
+		c.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, time.Second, cl.Timeout)
+			assert.Empty(t, cl.Name)
+		})
+	})
+
+	t.Run("missing dependencies of the constructor", func(t *testing.T) {
+		t.Parallel()
+
+		type Missing struct{}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(Missing) string { return "unreachable" })
+		c.RequireProvide(newClient, dig.Default("client"))
+		c.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, "client", cl.Name)
+		})
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(p clientParams) {
+			assert.Equal(t, time.Minute, p.Timeout)
+		}, dig.Default(time.Minute))
+	})
+
+	t.Run("interface dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Stringer fmt.Stringer `optional:"true"`
+			Count    int          `optional:"true"`
+			Name     defaultName  `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "dig_test.defaultName", p.Stringer.String())
+			assert.Equal(t, 42, p.Count)
+			assert.Equal(t, defaultName{}, p.Name)
+		}, dig.Default(42), dig.Default(defaultName{}))
+	})
+
+	t.Run("exact types are preferred", func(t *testing.T) {
+		t.Parallel()
+
+		type Names []string
+		type params struct {
+			dig.In
+
+			Strings []string `optional:"true"`
+			Names   Names    `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, []string{"exact"}, p.Strings)
+			assert.Equal(t, Names{"assignable"}, p.Names)
+		}, dig.Default(Names{"assignable"}), dig.Default([]string{"exact"}))
+	})
+
+	t.Run("ambiguous defaults", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Stringer fmt.Stringer `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		err := c.Invoke(func(params) {}, dig.Default(time.Minute), dig.Default(defaultName{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"dig.Default of types time.Duration and dig_test.defaultName both match the optional dependency on fmt.Stringer")
+	})
+
+	t.Run("Optional dependencies have no default", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(dig.Optional[time.Duration]) {}, dig.Default(time.Minute))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"dig.Default of type time.Duration does not match an optional dependency of the function")
+	})
+
+	t.Run("required dependencies have no default", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(time.Duration) {}, dig.Default(time.Minute))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"dig.Default of type time.Duration does not match an optional dependency of the function")
+	})
+
+	t.Run("invalid defaults", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(newClient, dig.Default(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use an untyped nil with dig.Default")
+
+		err = c.Provide(newClient, dig.Default("a"), dig.Default("b"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use more than one dig.Default of type string")
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "Default(30s)", fmt.Sprint(dig.Default(30*time.Second)))
+	})
+}
@@ -28,11 +28,12 @@ import (
 	"math/rand"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alexisvisco/dig"
-	"github.com/alexisvisco/dig/internal/digtest"
+	"github.com/alexisvisco/dig/digtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -818,6 +819,68 @@ func TestEndToEndSuccess(t *testing.T) {
 		}, dig.As(new(io.Reader), new(io.Closer)))
 	})
 
+	t.Run("As narrowing an interface", func(t *testing.T) {
+		c := digtest.New(t)
+		var buf bytes.Buffer
+		c.RequireProvide(func() io.ReadWriter { return &buf }, dig.As(new(io.Reader)))
+		c.RequireInvoke(func(r io.Reader) {
+			assert.Same(t, &buf, r)
+		})
+	})
+
+	t.Run("As on slices", func(t *testing.T) {
+		c := digtest.New(t)
+		bufs := []*bytes.Buffer{bytes.NewBufferString("foo"), bytes.NewBufferString("bar")}
+		c.RequireProvide(func() []*bytes.Buffer { return bufs }, dig.As(new(io.Reader), new(io.Writer)))
+		c.RequireInvoke(func(readers []io.Reader, writers []io.Writer) {
+			require.Len(t, readers, 2)
+			require.Len(t, writers, 2)
+			assert.Same(t, bufs[0], readers[0])
+			assert.Same(t, bufs[1], writers[1])
+		})
+	})
+
+	t.Run("As on nil slices", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() []*bytes.Buffer { return nil }, dig.As(new(io.Reader)))
+		c.RequireInvoke(func(readers []io.Reader) {
+			assert.Nil(t, readers)
+		})
+	})
+
+	t.Run("As on slices with Group", func(t *testing.T) {
+		type in struct {
+			dig.In
+
+			Readers [][]io.Reader `group:"readers"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() []*bytes.Buffer {
+			return []*bytes.Buffer{bytes.NewBufferString("foo")}
+		}, dig.As(new(io.Reader)), dig.Group("readers"))
+		c.RequireInvoke(func(got in) {
+			require.Len(t, got.Readers, 1)
+			assert.Len(t, got.Readers[0], 1)
+		})
+	})
+
+	t.Run("As on slices with flattened Group", func(t *testing.T) {
+		type in struct {
+			dig.In
+
+			Readers []io.Reader `group:"readers"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() []*bytes.Buffer {
+			return []*bytes.Buffer{bytes.NewBufferString("foo"), bytes.NewBufferString("bar")}
+		}, dig.As(new(io.Reader)), dig.Group("readers,flatten"))
+		c.RequireInvoke(func(got in) {
+			assert.Len(t, got.Readers, 2)
+		})
+	})
+
 	t.Run("invoke on a type that depends on named parameters", func(t *testing.T) {
 		c := digtest.New(t)
 		type A struct{ idx int }
@@ -1608,6 +1671,397 @@ func TestGroups(t *testing.T) {
 			assert.ElementsMatch(t, []string{"a"}, param.Value)
 		})
 	})
+	t.Run("tolerant value group drops failed members", func(t *testing.T) {
+		type tolerantParam struct {
+			dig.In
+
+			Values []string `group:"foo,tolerant"`
+		}
+		type strictParam struct {
+			dig.In
+
+			Values []string `group:"foo"`
+		}
+
+		var failures []error
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("foo"))
+		c.RequireProvide(func() (string, error) {
+			return "", errors.New("great sadness")
+		}, dig.Group("foo"), dig.WithProviderCallback(func(ci dig.CallbackInfo) {
+			if ci.Error != nil {
+				failures = append(failures, ci.Error)
+			}
+		}))
+		c.RequireProvide(func() string { return "c" }, dig.Group("foo"))
+
+		c.RequireInvoke(func(p tolerantParam) {
+			assert.ElementsMatch(t, []string{"a", "c"}, p.Values)
+		})
+		require.Len(t, failures, 1)
+		assert.Equal(t, "great sadness", dig.RootCause(failures[0]).Error())
+
+		err := c.Invoke(func(p strictParam) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+	t.Run("tolerant value group with only failed members is empty", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values []int `group:"foo,tolerant"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (int, error) {
+			return 0, errors.New("great sadness")
+		}, dig.Group("foo"))
+		c.RequireProvide(func(float32) int { return 1 }, dig.Group("foo"))
+
+		c.RequireInvoke(func(p param) {
+			assert.NotNil(t, p.Values)
+			assert.Empty(t, p.Values)
+		})
+	})
+	t.Run("tolerant value group records warnings", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values []int `group:"foo,tolerant"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.Group("foo"))
+		c.RequireProvide(func() (int, error) {
+			return 0, errors.New("great sadness")
+		}, dig.Group("foo"))
+		c.RequireProvide(func(float32) int { return 3 }, dig.Group("foo"))
+
+		var info dig.InvokeInfo
+		c.RequireInvoke(func(p param) {
+			assert.Equal(t, []int{1}, p.Values)
+		}, dig.FillInvokeInfo(&info))
+
+		require.Len(t, info.Warnings, 2)
+		for _, w := range info.Warnings {
+			assert.Equal(t, "foo", w.Group)
+			assert.Equal(t, reflect.TypeOf(0), w.Type)
+			assert.Contains(t, w.Provider.Name, "TestGroups")
+		}
+		assert.Equal(t, "great sadness", dig.RootCause(info.Warnings[0].Reason).Error())
+		assert.Contains(t, info.Warnings[1].Reason.Error(), "missing type: float32")
+		assert.Contains(t, info.Warnings[0].String(), `left out of int[group="foo"]: `)
+		assert.Equal(t, info.Warnings, c.Warnings())
+
+		// Failed constructors are tried again, and skipped again, without
+		// growing the warnings of the container.
+		c.RequireInvoke(func(p param) {}, dig.FillInvokeInfo(&info))
+		assert.Len(t, info.Warnings, 2)
+		assert.Equal(t, info.Warnings, c.Warnings())
+	})
+	t.Run("tolerant value group report", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Ints    []int    `group:"foo,tolerant"`
+			Strings []string `group:"bar,tolerant"`
+		}
+
+		c := digtest.New(t)
+		calls := 0
+		c.RequireProvide(func() (int, error) {
+			calls++
+			return 0, fmt.Errorf("failure %d", calls)
+		}, dig.Group("foo"))
+		c.RequireProvide(func() int { return 2 }, dig.Group("foo"))
+		c.RequireProvide(func() (string, error) {
+			return "", errors.New("great sadness")
+		}, dig.Group("bar"))
+
+		assert.Empty(t, c.GroupReport("foo"))
+		c.RequireInvoke(func(param) {})
+		c.RequireInvoke(func(param) {})
+
+		report := c.GroupReport("foo")
+		require.Len(t, report, 1)
+		assert.Equal(t, "failure 2", dig.RootCause(report[0].Reason).Error())
+
+		report = c.GroupReport("bar")
+		require.Len(t, report, 1)
+		assert.Equal(t, reflect.TypeOf(""), report[0].Type)
+		assert.Empty(t, c.GroupReport("baz"))
+	})
+	t.Run("tolerant in a result value group", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() int { return 10 }, dig.Group("foo,tolerant"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use tolerant with result value groups")
+	})
+	t.Run("consume named values as a map", func(t *testing.T) {
+		type out struct {
+			dig.Out
+
+			Value string `group:"handlers" name:"users"`
+		}
+		type param struct {
+			dig.In
+
+			Slice []string          `group:"handlers"`
+			Map   map[string]string `group:"handlers"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() out { return out{Value: "users handler"} })
+		c.RequireProvide(func() string { return "posts handler" }, dig.Group("handlers"), dig.Name("posts"))
+
+		c.RequireInvoke(func(p param) {
+			assert.ElementsMatch(t, []string{"users handler", "posts handler"}, p.Slice)
+			assert.Equal(t, map[string]string{
+				"users": "users handler",
+				"posts": "posts handler",
+			}, p.Map)
+		})
+	})
+	t.Run("consume named values as a map with As", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Readers map[string]io.Reader `group:"readers"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return bytes.NewBufferString("foo") },
+			dig.Group("readers"), dig.Name("foo"), dig.As(new(io.Reader)))
+		c.RequireProvide(func() *strings.Reader { return strings.NewReader("bar") },
+			dig.Group("readers"), dig.Name("bar"), dig.As(new(io.Reader)))
+
+		c.RequireInvoke(func(p param) {
+			require.Len(t, p.Readers, 2)
+			assert.Contains(t, p.Readers, "foo")
+			assert.Contains(t, p.Readers, "bar")
+		})
+	})
+	t.Run("consume named values from scopes as a map", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values map[string]int `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.Group("values"), dig.Name("one"))
+		child := c.Scope("child")
+		child.RequireProvide(func() int { return 2 }, dig.Group("values"), dig.Name("two"))
+
+		child.RequireInvoke(func(p param) {
+			assert.Equal(t, map[string]int{"one": 1, "two": 2}, p.Values)
+		})
+		c.RequireInvoke(func(p param) {
+			assert.Equal(t, map[string]int{"one": 1}, p.Values)
+		})
+	})
+	t.Run("empty map value group", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values map[string]int `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(p param) {
+			assert.NotNil(t, p.Values)
+			assert.Empty(t, p.Values)
+		})
+	})
+	t.Run("map value group with unnamed values", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values map[string]int `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.Group("values"), dig.Name("one"))
+		c.RequireProvide(func() int { return 2 }, dig.Group("values"))
+
+		err := c.Invoke(func(param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot consume value group "values" as a map (map[string]int): 1 value(s) were provided without a name`)
+	})
+	t.Run("map value group with duplicate names", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values map[string]int `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.Group("values"), dig.Name("one"))
+		c.RequireProvide(func() int { return 2 }, dig.Group("values"), dig.Name("one"))
+
+		err := c.Invoke(func(param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `name "one" was provided more than once`)
+	})
+	t.Run("decorated value group consumed as a map", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values map[string]int `group:"values"`
+		}
+		type decorateIn struct {
+			dig.In
+
+			Values []int `group:"values"`
+		}
+		type decorateOut struct {
+			dig.Out
+
+			Values []int `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.Group("values"), dig.Name("one"))
+		c.RequireDecorate(func(p decorateIn) decorateOut { return decorateOut{Values: p.Values} })
+
+		err := c.Invoke(func(param) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot consume decorated value group "values" as a map`)
+	})
+	t.Run("sorted in provide order", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values []int `group:"values,sorted"`
+		}
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		for i := 0; i < 10; i++ {
+			i := i
+			if i%2 == 1 {
+				child.RequireProvide(func() int { return i }, dig.Group("values"))
+			} else {
+				c.RequireProvide(func() int { return i }, dig.Group("values"))
+			}
+		}
+
+		// Build the last values first, through another consumer.
+		child.RequireInvoke(func(p struct {
+			dig.In
+
+			Values []int `group:"values"`
+		}) {
+			assert.Len(t, p.Values, 10)
+		})
+
+		child.RequireInvoke(func(p param) {
+			assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, p.Values)
+		})
+		c.RequireInvoke(func(p param) {
+			assert.Equal(t, []int{0, 2, 4, 6, 8}, p.Values)
+		})
+	})
+	t.Run("sorted by weight", func(t *testing.T) {
+		type out struct {
+			dig.Out
+
+			First  string   `group:"values" weight:"-10"`
+			Last   string   `group:"values" weight:"10"`
+			Middle []string `group:"values,flatten"`
+		}
+		type param struct {
+			dig.In
+
+			Values []string `group:"values,sorted"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "c" }, dig.Group("values"))
+		c.RequireProvide(func() out {
+			return out{First: "a", Last: "z", Middle: []string{"d", "b"}}
+		})
+
+		c.RequireInvoke(func(p param) {
+			assert.Equal(t, []string{"a", "c", "d", "b", "z"}, p.Values)
+		})
+	})
+	t.Run("sorted with a less function", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values []string `group:"values,sorted"`
+		}
+
+		c := digtest.New(t)
+		require.NoError(t, c.RegisterGroupLess("values", func(a, b string) bool {
+			return len(a) < len(b)
+		}))
+		c.RequireProvide(func() string { return "ccc" }, dig.Group("values"))
+		c.RequireProvide(func() string { return "bb" }, dig.Group("values"))
+		c.RequireProvide(func() string { return "aa" }, dig.Group("values"))
+		c.RequireProvide(func() string { return "d" }, dig.Group("values"))
+
+		c.RequireInvoke(func(p param) {
+			assert.Equal(t, []string{"d", "bb", "aa", "ccc"}, p.Values)
+		})
+	})
+	t.Run("invalid less function", func(t *testing.T) {
+		c := digtest.New(t)
+
+		err := c.RegisterGroupLess("values", func(a string, b int) bool { return false })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`less function for group "values" must be of the form func(T, T) bool: got func(string, int) bool`)
+
+		require.NoError(t, c.RegisterGroupLess("values", func(a, b string) bool { return a < b }))
+		err = c.RegisterGroupLess("values", func(a, b string) bool { return a > b })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `a less function is already registered for group "values" of string`)
+	})
+	t.Run("invalid weight", func(t *testing.T) {
+		type out struct {
+			dig.Out
+
+			Value string `group:"values" weight:"heavy"`
+		}
+
+		c := digtest.New(t)
+		err := c.Provide(func() out { return out{} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid weight "heavy" for field "Value" (string): weight must be an integer`)
+	})
+	t.Run("weight without a group", func(t *testing.T) {
+		type out struct {
+			dig.Out
+
+			Value string `weight:"1"`
+		}
+
+		c := digtest.New(t)
+		err := c.Provide(func() out { return out{} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`weight can be applied to value groups only: field "Value" (string) is not part of a value group`)
+	})
+	t.Run("sorted in a result value group", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() string { return "" }, dig.Group("values,sorted"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use sorted with result value groups")
+	})
+	t.Run("sorted map value group", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Invoke(func(struct {
+			dig.In
+
+			Values map[string]string `group:"values,sorted"`
+		}) {
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use sorted with value groups consumed as maps")
+	})
 }
 
 // --- END OF END TO END TESTS
@@ -1618,6 +2072,9 @@ func TestRecoverFromPanic(t *testing.T) {
 		setup   func(*digtest.Container)
 		invoke  interface{}
 		wantErr []string
+
+		// Whether the panic is recovered without the option.
+		alwaysRecovered bool
 	}{
 		{
 			name: "panic in provided function",
@@ -1626,7 +2083,8 @@ func TestRecoverFromPanic(t *testing.T) {
 					panic("terrible sadness")
 				})
 			},
-			invoke: func(i int) {},
+			invoke:          func(i int) {},
+			alwaysRecovered: true,
 			wantErr: []string{
 				`could not build arguments for function "github.com/alexisvisco/dig_test".TestRecoverFromPanic.\S+`,
 				`failed to build int:`,
@@ -1663,9 +2121,17 @@ func TestRecoverFromPanic(t *testing.T) {
 			t.Run("without option", func(t *testing.T) {
 				c := digtest.New(t)
 				tt.setup(c)
-				assert.Panics(t, func() { c.Container.Invoke(tt.invoke) },
-					"expected panic without dig.RecoverFromPanics() option",
-				)
+				if !tt.alwaysRecovered {
+					assert.Panics(t, func() { c.Container.Invoke(tt.invoke) },
+						"expected panic without dig.RecoverFromPanics() option",
+					)
+					return
+				}
+
+				err := c.Container.Invoke(tt.invoke)
+				require.Error(t, err)
+				dig.AssertErrorMatches(t, err, tt.wantErr[0], tt.wantErr[1:]...)
+				assert.ErrorIs(t, err, dig.ErrConstructorPanicked)
 			})
 
 			t.Run("with option", func(t *testing.T) {
@@ -1795,20 +2261,79 @@ func TestCallback(t *testing.T) {
 		c.Invoke(func(int) {})
 		assert.True(t, called)
 	})
-}
-
-func TestProvideConstructorErrors(t *testing.T) {
-	t.Run("multiple-type constructor returns multiple objects of same type", func(t *testing.T) {
-		c := digtest.New(t)
-		type A struct{}
-		constructor := func() (*A, *A, error) {
-			return &A{}, &A{}, nil
-		}
-		require.Error(t, c.Provide(constructor), "provide failed")
-	})
+	t.Run("details of fresh and cached calls", func(t *testing.T) {
+		var infos, hits []dig.CallbackInfo
 
-	t.Run("constructor consumes a dig.Out", func(t *testing.T) {
-		c := digtest.New(t)
+		root := digtest.New(t)
+		child := root.Scope("child").Scope("grandchild")
+		child.RequireProvide(
+			func() (int, string) {
+				time.Sleep(time.Millisecond)
+				return 1, "a"
+			},
+			dig.WithProviderCallback(func(ci dig.CallbackInfo) {
+				infos = append(infos, ci)
+			}),
+			dig.WithCacheHitCallback(func(ci dig.CallbackInfo) {
+				hits = append(hits, ci)
+			}),
+		)
+
+		child.RequireInvoke(func(int) {})
+		child.RequireInvoke(func(string) {})
+
+		require.Len(t, infos, 1, "provider callbacks must not be called on cache hits")
+		require.Len(t, hits, 1)
+
+		fresh := infos[0]
+		assert.NoError(t, fresh.Error)
+		assert.False(t, fresh.Cached)
+		assert.GreaterOrEqual(t, fresh.Runtime, time.Millisecond)
+		assert.Equal(t, []string{"child", "grandchild"}, fresh.ScopePath)
+		require.Len(t, fresh.Outputs, 2)
+		assert.Equal(t, "int", fresh.Outputs[0].String())
+		assert.Equal(t, "string", fresh.Outputs[1].String())
+
+		cached := hits[0]
+		assert.True(t, cached.Cached)
+		assert.Zero(t, cached.Runtime)
+		assert.Equal(t, fresh.Name, cached.Name)
+		assert.Equal(t, fresh.ScopePath, cached.ScopePath)
+	})
+
+	t.Run("details of decorators", func(t *testing.T) {
+		var info dig.CallbackInfo
+
+		c := digtest.New(t)
+		c.RequireProvide(giveInt)
+		c.RequireDecorate(
+			func(a int) int { return a + 1 },
+			dig.WithDecoratorCallback(func(ci dig.CallbackInfo) {
+				info = ci
+			}),
+		)
+
+		c.RequireInvoke(func(int) {})
+
+		assert.False(t, info.Cached)
+		assert.Empty(t, info.ScopePath)
+		require.Len(t, info.Outputs, 1)
+		assert.Equal(t, "int", info.Outputs[0].String())
+	})
+}
+
+func TestProvideConstructorErrors(t *testing.T) {
+	t.Run("multiple-type constructor returns multiple objects of same type", func(t *testing.T) {
+		c := digtest.New(t)
+		type A struct{}
+		constructor := func() (*A, *A, error) {
+			return &A{}, &A{}, nil
+		}
+		require.Error(t, c.Provide(constructor), "provide failed")
+	})
+
+	t.Run("constructor consumes a dig.Out", func(t *testing.T) {
+		c := digtest.New(t)
 		type out struct {
 			dig.Out
 
@@ -2129,6 +2654,15 @@ func TestProvideInvalidAs(t *testing.T) {
 			assert.Contains(t, err.Error(), tt.expectedErr)
 		})
 	}
+
+	t.Run("as param is not implemented by slice elements", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() []int { return nil }, dig.As(new(io.Reader)))
+		require.Error(t, err, "provide must fail")
+		assert.Contains(t, err.Error(), "invalid dig.As: []int does not implement io.Reader")
+	})
 }
 
 func TestAsExpectingOriginalType(t *testing.T) {
@@ -2147,130 +2681,746 @@ func TestAsExpectingOriginalType(t *testing.T) {
 		type in struct {
 			dig.In
 
-			Buffer *bytes.Buffer `name:"buff"`
-			Reader io.Reader     `name:"buff"`
+			Buffer *bytes.Buffer `name:"buff"`
+			Reader io.Reader     `name:"buff"`
+		}
+
+		require.Error(t, c.Invoke(func(got in) {
+			t.Fatal("*bytes.Buffer with name buff shouldn't be provided")
+		}))
+	})
+}
+
+func TestProvideNameForGroupFor(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ v int }
+	type B struct{ v int }
+
+	t.Run("name a single result", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (A, B) { return A{1}, B{2} }, dig.NameFor(new(A), "foo"))
+
+		c.RequireInvoke(func(in struct {
+			dig.In
+
+			A A `name:"foo"`
+			B B
+		}) {
+			assert.Equal(t, 1, in.A.v)
+			assert.Equal(t, 2, in.B.v)
+		})
+		assert.Error(t, c.Invoke(func(A) {}), "A must only be provided under its name")
+	})
+
+	t.Run("overrides Name", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (A, B) { return A{1}, B{2} },
+			dig.Name("all"), dig.NameFor(new(B), "b"))
+
+		c.RequireInvoke(func(in struct {
+			dig.In
+
+			A A `name:"all"`
+			B B `name:"b"`
+		}) {
+			assert.Equal(t, 1, in.A.v)
+			assert.Equal(t, 2, in.B.v)
+		})
+	})
+
+	t.Run("group a single result", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (A, B) { return A{1}, B{2} }, dig.GroupFor(new(A), "as"))
+		c.RequireProvide(func() A { return A{3} }, dig.Group("as"))
+
+		c.RequireInvoke(func(in struct {
+			dig.In
+
+			As []A `group:"as"`
+			B  B
+		}) {
+			assert.ElementsMatch(t, []A{{1}, {3}}, in.As)
+			assert.Equal(t, 2, in.B.v)
+		})
+	})
+
+	t.Run("type not produced", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() A { return A{} }, dig.NameFor(new(B), "foo"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot use dig.NameFor(new(dig_test.B), "foo"): func() dig_test.A does not produce a dig_test.B`)
+	})
+
+	t.Run("not a pointer", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() A { return A{} }, dig.GroupFor(A{}, "foo"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid dig.GroupFor(dig_test.A, "foo"): argument must be a pointer to a type`)
+	})
+
+	t.Run("group with incompatible options", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() A { return A{} }, dig.GroupFor(new(A), "as"), dig.Transient())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot use Transient with value groups: group:"as"`)
+	})
+}
+
+func TestProvideAsImplementedInterfaces(t *testing.T) {
+	t.Parallel()
+
+	interfaces := dig.AsImplementedInterfaces(new(io.Reader), new(io.Writer), new(io.Closer))
+	newBuffer := func() *bytes.Buffer { return bytes.NewBufferString("foo") }
+
+	t.Run("provides implemented interfaces and the type", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newBuffer, interfaces)
+		c.RequireInvoke(func(r io.Reader, w io.Writer, b *bytes.Buffer) {
+			assert.Same(t, b, r)
+			assert.Same(t, b, w)
+		})
+
+		err := c.Invoke(func(io.Closer) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: io.Closer")
+	})
+
+	t.Run("with As", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newBuffer, interfaces, dig.As(new(io.Reader)))
+		c.RequireInvoke(func(io.Reader, io.Writer) {})
+		require.Error(t, c.Invoke(func(*bytes.Buffer) {}))
+	})
+
+	t.Run("with Name", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			dig.In
+
+			Reader io.Reader     `name:"buf"`
+			Buffer *bytes.Buffer `name:"buf"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(newBuffer, interfaces, dig.Name("buf"))
+		c.RequireInvoke(func(got in) {
+			assert.Same(t, got.Buffer, got.Reader)
+		})
+	})
+
+	t.Run("with Group", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			dig.In
+
+			Readers []io.Reader     `group:"bufs"`
+			Buffers []*bytes.Buffer `group:"bufs"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(newBuffer, interfaces, dig.Group("bufs"))
+		c.RequireInvoke(func(got in) {
+			assert.Len(t, got.Readers, 1)
+			assert.Len(t, got.Buffers, 1)
+		})
+	})
+
+	t.Run("result objects", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			Buffer *bytes.Buffer
+			Count  int
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() out { return out{Buffer: newBuffer(), Count: 1} }, interfaces)
+		c.RequireInvoke(func(io.Reader, io.Writer, *bytes.Buffer, int) {})
+	})
+
+	t.Run("invalid interface", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(newBuffer, dig.AsImplementedInterfaces(new(bytes.Buffer)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"invalid dig.AsImplementedInterfaces(*bytes.Buffer): argument must be a pointer to an interface")
+	})
+}
+
+func TestProvideIncompatibleOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flattened group and name", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() []io.Reader {
+			t.Fatal("this function must not be called")
+			return nil
+		}, dig.Group("foo,flatten"), dig.Name("bar"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use flatten with named values in value groups: "+
+			`name:"bar" provided with group:"foo"`)
+	})
+}
+
+type testStruct struct{}
+
+func (testStruct) TestMethod(x int) float64 { return float64(x) }
+
+func TestProvideLocation(t *testing.T) {
+	t.Parallel()
+
+	c := digtest.New(t)
+	c.RequireProvide(func(x int) float64 {
+		return testStruct{}.TestMethod(x)
+	}, dig.LocationForPC(reflect.TypeOf(testStruct{}).Method(0).Func.Pointer()))
+
+	err := c.Invoke(func(y float64) {})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"github.com/alexisvisco/dig_test".testStruct.TestMethod`)
+	require.Contains(t, err.Error(), `dig/dig_test.go`)
+}
+
+func TestCantProvideUntypedNil(t *testing.T) {
+	t.Parallel()
+	c := digtest.New(t)
+	assert.Error(t, c.Provide(nil))
+}
+
+func TestCantProvideErrorLikeType(t *testing.T) {
+	t.Parallel()
+
+	tests := []interface{}{
+		func() *os.PathError { return &os.PathError{} },
+		func() error { return &os.PathError{} },
+		func() (*os.PathError, error) { return &os.PathError{}, nil },
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T", tt), func(t *testing.T) {
+			c := digtest.New(t)
+			assert.Error(t, c.Provide(tt), "providing errors should fail")
+		})
+	}
+}
+
+func TestCantProvideParameterObjects(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constructor", func(t *testing.T) {
+		type Args struct{ dig.In }
+
+		c := digtest.New(t)
+		err := c.Provide(func() (Args, error) {
+			panic("great sadness")
+		})
+		require.Error(t, err, "provide should fail")
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "github.com/alexisvisco/dig_test".TestCantProvideParameterObjects\S+`,
+			`dig_test.go:\d+`, // file:line
+			"bad result 1:",
+			"cannot provide parameter objects: dig_test.Args embeds a dig.In",
+		)
+	})
+
+	t.Run("pointer from constructor", func(t *testing.T) {
+		c := digtest.New(t)
+		type Args struct{ dig.In }
+
+		args := &Args{}
+
+		err := c.Provide(func() (*Args, error) { return args, nil })
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			`cannot provide function "github.com/alexisvisco/dig_test".TestCantProvideParameterObjects\S+`,
+			`dig_test.go:\d+`, // file:line
+			"bad result 1:",
+			`cannot provide parameter objects: \*dig_test.Args embeds a dig.In`,
+		)
+	})
+}
+
+func TestProvideKnownTypesFails(t *testing.T) {
+	t.Parallel()
+
+	provideArgs := []interface{}{
+		func() *bytes.Buffer { return nil },
+		func() (*bytes.Buffer, error) { return nil, nil },
+	}
+
+	for _, first := range provideArgs {
+		t.Run(fmt.Sprintf("%T", first), func(t *testing.T) {
+			c := digtest.New(t)
+			c.RequireProvide(first)
+
+			for _, second := range provideArgs {
+				assert.Error(t, c.Provide(second), "second provide must fail")
+			}
+		})
+	}
+	t.Run("provide constructor twice", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return nil })
+		assert.Error(t, c.Provide(func() *bytes.Buffer { return nil }))
+	})
+}
+
+func TestProvideOverride(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ name string }
+	type B struct{ name string }
+
+	t.Run("replaces the previous provider", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "real"} })
+		c.RequireProvide(func() *A { return &A{name: "fake"} }, dig.Override())
+
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "fake", a.name)
+		})
+	})
+
+	t.Run("replaces named values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "real"} }, dig.Name("a"))
+		c.RequireProvide(func() *A { return &A{name: "fake"} }, dig.Name("a"), dig.Override())
+
+		type in struct {
+			dig.In
+
+			A *A `name:"a"`
+		}
+		c.RequireInvoke(func(i in) {
+			assert.Equal(t, "fake", i.A.name)
+		})
+	})
+
+	t.Run("keeps other values of the replaced provider", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, *B) { return &A{name: "real"}, &B{name: "real"} })
+		c.RequireProvide(func() *A { return &A{name: "fake"} }, dig.Override())
+
+		c.RequireInvoke(func(a *A, b *B) {
+			assert.Equal(t, "fake", a.name)
+			assert.Equal(t, "real", b.name)
+		})
+	})
+
+	t.Run("replaced provider is not visualized", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "real"} })
+		c.RequireProvide(func() *A { return &A{name: "fake"} }, dig.Override())
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b))
+		assert.Contains(t, b.String(), "TestProvideOverride.func4.2")
+		assert.NotContains(t, b.String(), "TestProvideOverride.func4.1")
+	})
+
+	t.Run("without a previous provider", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "fake"} }, dig.Override())
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "fake", a.name)
+		})
+	})
+
+	t.Run("duplicates still fail without the option", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		err := c.Provide(func() *A { return &A{} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already provided")
+	})
+
+	t.Run("value already built", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "real"} })
+		c.RequireInvoke(func(*A) {})
+
+		err := c.Provide(func() *A { return &A{name: "fake"} }, dig.Override())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot override *dig_test.A: the value was already built")
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() *A { return &A{} }, dig.Group("a"), dig.Override())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot use Override with value groups: group:"a"`)
+	})
+
+	t.Run("cycle restores the previous provider", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "real"} })
+		c.RequireProvide(func(*A) *B { return &B{} })
+
+		err := c.Provide(func(*B) *A { return &A{name: "fake"} }, dig.Override())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "real", a.name)
+		})
+	})
+
+	t.Run("replaced provider does not commit overridden values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, *B) { return &A{name: "real"}, &B{name: "real"} })
+		c.RequireProvide(func() *A { return &A{name: "fake"} }, dig.Override())
+
+		// The replaced provider is called for B before A is built.
+		c.RequireInvoke(func(b *B, a *A) {
+			assert.Equal(t, "real", b.name)
+			assert.Equal(t, "fake", a.name)
+		})
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "fake", a.name)
+		})
+	})
+
+	t.Run("replaced provider called first", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, *B) { return &A{name: "real"}, &B{name: "real"} })
+		c.RequireProvide(func() *A { return &A{name: "fake"} }, dig.Override())
+
+		c.RequireInvoke(func(*B) {})
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "fake", a.name)
+		})
+	})
+}
+
+func TestProvideTransient(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ id int }
+	type B struct{ a1, a2 *A }
+
+	t.Run("called on every resolution", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var calls int
+		c.RequireProvide(func() *A {
+			calls++
+			return &A{id: calls}
+		}, dig.Transient())
+
+		c.RequireInvoke(func(a1, a2 *A) {
+			assert.NotSame(t, a1, a2)
+		})
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, 3, a.id)
+		})
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("singleton dependents keep their value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} }, dig.Transient())
+		c.RequireProvide(func(a1, a2 *A) *B { return &B{a1: a1, a2: a2} })
+
+		var first *B
+		c.RequireInvoke(func(b *B) {
+			assert.NotSame(t, b.a1, b.a2)
+			first = b
+		})
+		c.RequireInvoke(func(b *B) {
+			assert.Same(t, first, b)
+		})
+	})
+
+	t.Run("dependencies are built once", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var calls int
+		c.RequireProvide(func() string {
+			calls++
+			return "foo"
+		})
+		c.RequireProvide(func(string) *A { return &A{} }, dig.Transient())
+
+		c.RequireInvoke(func(*A, *A) {})
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var calls int
+		c.RequireProvide(func() *A {
+			calls++
+			return &A{}
+		}, dig.Transient())
+
+		child := c.Scope("child")
+		child.RequireInvoke(func(*A) {})
+		c.RequireInvoke(func(*A) {})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, error) {
+			return nil, errors.New("great sadness")
+		}, dig.Transient())
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+
+		// Optional parameters with missing dependencies are zero values.
+		c.RequireProvide(func(string) *B { return &B{} }, dig.Transient())
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			B *B `optional:"true"`
+		}) {
+			assert.Nil(t, p.B)
+		})
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			A *A `group:"as"`
 		}
 
-		require.Error(t, c.Invoke(func(got in) {
-			t.Fatal("*bytes.Buffer with name buff shouldn't be provided")
-		}))
+		c := digtest.New(t)
+		err := c.Provide(func() *A { return &A{} }, dig.Transient(), dig.Group("as"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot use Transient with value groups: group:"as"`)
+
+		err = c.Provide(func() out { return out{} }, dig.Transient())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `transient constructors cannot provide value groups`)
 	})
-}
 
-func TestProvideIncompatibleOptions(t *testing.T) {
-	t.Parallel()
+	t.Run("incompatible options", func(t *testing.T) {
+		t.Parallel()
 
-	t.Run("group and name", func(t *testing.T) {
 		c := digtest.New(t)
-		err := c.Provide(func() io.Reader {
-			t.Fatal("this function must not be called")
-			return nil
-		}, dig.Group("foo"), dig.Name("bar"))
+		err := c.Provide(func() *A { return &A{} }, dig.Transient(), dig.Eager())
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "cannot use named values with value groups: "+
-			`name:"bar" provided with group:"foo"`)
+		assert.Contains(t, err.Error(), "cannot use Transient with Eager")
+
+		err = c.Provide(func() *A { return &A{} }, dig.Transient(), dig.WithLifecycle())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use Transient with lifecycle hooks")
 	})
-}
 
-type testStruct struct{}
+	t.Run("not called by Build", func(t *testing.T) {
+		t.Parallel()
 
-func (testStruct) TestMethod(x int) float64 { return float64(x) }
+		c := digtest.New(t, dig.EagerAll())
+		c.RequireProvide(func() *A {
+			t.Fatal("transient constructor must not be called")
+			return nil
+		}, dig.Transient())
+		require.NoError(t, c.Build())
+	})
+}
 
-func TestProvideLocation(t *testing.T) {
-	t.Parallel()
+func TestProvideIfMissing(t *testing.T) {
+	type A struct{ name string }
+	type B struct{}
 
-	c := digtest.New(t)
-	c.RequireProvide(func(x int) float64 {
-		return testStruct{}.TestMethod(x)
-	}, dig.LocationForPC(reflect.TypeOf(testStruct{}).Method(0).Func.Pointer()))
+	newDefault := func() *A { return &A{"default"} }
+	newCustom := func() *A { return &A{"custom"} }
 
-	err := c.Invoke(func(y float64) {})
-	require.Error(t, err)
-	require.Contains(t, err.Error(), `"github.com/alexisvisco/dig_test".testStruct.TestMethod`)
-	require.Contains(t, err.Error(), `dig/dig_test.go`)
-}
+	t.Run("used when nothing else provides the value", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(newDefault, dig.IfMissing())
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "default", a.name)
+		})
+	})
 
-func TestCantProvideUntypedNil(t *testing.T) {
-	t.Parallel()
-	c := digtest.New(t)
-	assert.Error(t, c.Provide(nil))
-}
+	t.Run("replaced by later constructors", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(newDefault, dig.IfMissing())
+		c.RequireProvide(newCustom)
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "custom", a.name)
+		})
+	})
 
-func TestCantProvideErrorLikeType(t *testing.T) {
-	t.Parallel()
+	t.Run("ignored after other constructors", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(newCustom)
+		c.RequireProvide(newDefault, dig.IfMissing())
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "custom", a.name)
+		})
+	})
 
-	tests := []interface{}{
-		func() *os.PathError { return &os.PathError{} },
-		func() error { return &os.PathError{} },
-		func() (*os.PathError, error) { return &os.PathError{}, nil },
-	}
+	t.Run("ignored after other fallbacks", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(newCustom, dig.IfMissing())
+		c.RequireProvide(newDefault, dig.IfMissing())
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "custom", a.name)
+		})
+	})
 
-	for _, tt := range tests {
-		t.Run(fmt.Sprintf("%T", tt), func(t *testing.T) {
-			c := digtest.New(t)
-			assert.Error(t, c.Provide(tt), "providing errors should fail")
+	t.Run("partially replaced", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, *B) { return &A{"default"}, &B{} }, dig.IfMissing())
+		c.RequireProvide(newCustom)
+		c.RequireInvoke(func(a *A, _ *B) {
+			assert.Equal(t, "custom", a.name)
 		})
-	}
-}
+	})
 
-func TestCantProvideParameterObjects(t *testing.T) {
-	t.Parallel()
+	t.Run("with names", func(t *testing.T) {
+		type in struct {
+			dig.In
 
-	t.Run("constructor", func(t *testing.T) {
-		type Args struct{ dig.In }
+			A *A `name:"a"`
+		}
 
 		c := digtest.New(t)
-		err := c.Provide(func() (Args, error) {
-			panic("great sadness")
+		c.RequireProvide(newDefault, dig.IfMissing(), dig.Name("a"))
+		c.RequireProvide(newCustom)
+		c.RequireProvide(newCustom, dig.Name("a"))
+		c.RequireInvoke(func(got in) {
+			assert.Equal(t, "custom", got.A.name)
 		})
-		require.Error(t, err, "provide should fail")
-		dig.AssertErrorMatches(t, err,
-			`cannot provide function "github.com/alexisvisco/dig_test".TestCantProvideParameterObjects\S+`,
-			`dig_test.go:\d+`, // file:line
-			"bad result 1:",
-			"cannot provide parameter objects: dig_test.Args embeds a dig.In",
-		)
 	})
 
-	t.Run("pointer from constructor", func(t *testing.T) {
+	t.Run("cannot be replaced once built", func(t *testing.T) {
 		c := digtest.New(t)
-		type Args struct{ dig.In }
+		c.RequireProvide(newDefault, dig.IfMissing())
+		c.RequireInvoke(func(*A) {})
 
-		args := &Args{}
+		err := c.Provide(newCustom)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the value was already built")
+	})
 
-		err := c.Provide(func() (*Args, error) { return args, nil })
+	t.Run("incompatible options", func(t *testing.T) {
+		c := digtest.New(t)
+
+		err := c.Provide(newDefault, dig.IfMissing(), dig.Group("as"))
 		require.Error(t, err)
-		dig.AssertErrorMatches(t, err,
-			`cannot provide function "github.com/alexisvisco/dig_test".TestCantProvideParameterObjects\S+`,
-			`dig_test.go:\d+`, // file:line
-			"bad result 1:",
-			`cannot provide parameter objects: \*dig_test.Args embeds a dig.In`,
-		)
+		assert.Contains(t, err.Error(), "cannot use IfMissing with value groups")
+
+		err = c.Provide(newDefault, dig.IfMissing(), dig.Override())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use IfMissing with Override")
 	})
 }
 
-func TestProvideKnownTypesFails(t *testing.T) {
-	t.Parallel()
+func TestProvideWhen(t *testing.T) {
+	type A struct{ name string }
 
-	provideArgs := []interface{}{
-		func() *bytes.Buffer { return nil },
-		func() (*bytes.Buffer, error) { return nil, nil },
-	}
+	yes := func() bool { return true }
+	no := func() bool { return false }
 
-	for _, first := range provideArgs {
-		t.Run(fmt.Sprintf("%T", first), func(t *testing.T) {
-			c := digtest.New(t)
-			c.RequireProvide(first)
+	t.Run("provides when the predicates hold", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{"real"} }, dig.When(yes), dig.When(yes))
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "real", a.name)
+		})
+	})
 
-			for _, second := range provideArgs {
-				assert.Error(t, c.Provide(second), "second provide must fail")
-			}
+	t.Run("ignores the constructor otherwise", func(t *testing.T) {
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			t.Fatal("constructor must not be called")
+			return nil
+		}, dig.When(yes), dig.When(no))
+		c.RequireProvide(func() *A { return &A{"fallback"} })
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "fallback", a.name)
 		})
-	}
-	t.Run("provide constructor twice", func(t *testing.T) {
+	})
+
+	t.Run("predicates are called at provide time", func(t *testing.T) {
+		enabled := true
 		c := digtest.New(t)
-		c.RequireProvide(func() *bytes.Buffer { return nil })
-		assert.Error(t, c.Provide(func() *bytes.Buffer { return nil }))
+		c.RequireProvide(func() *A { return &A{} }, dig.When(func() bool { return enabled }))
+		enabled = false
+		c.RequireInvoke(func(*A) {})
+	})
+
+	t.Run("WhenEnv", func(t *testing.T) {
+		t.Setenv("DIG_TEST_FEATURE", "on")
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{"on"} }, dig.WhenEnv("DIG_TEST_FEATURE", "on"))
+		c.RequireProvide(func() string { return "off" }, dig.WhenEnv("DIG_TEST_FEATURE", "off"))
+		c.RequireInvoke(func(*A) {})
+		require.Error(t, c.Invoke(func(string) {}))
+	})
+
+	t.Run("Supply", func(t *testing.T) {
+		c := digtest.New(t)
+		require.NoError(t, c.Supply(&A{}, dig.When(no)))
+		require.Error(t, c.Invoke(func(*A) {}))
+	})
+
+	t.Run("nil predicate", func(t *testing.T) {
+		c := digtest.New(t)
+		err := c.Provide(func() *A { return &A{} }, dig.When(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.When(nil): predicate must not be nil")
 	})
 }
 
@@ -3380,6 +4530,40 @@ func testInvokeFailures(t *testing.T, dryRun bool) {
 		}
 	})
 
+	t.Run("requesting a type with the same name as a provided type", func(t *testing.T) {
+		type Buffer struct{}
+
+		c := digtest.New(t, dig.DryRun(dryRun))
+		c.RequireProvide(func() *bytes.Buffer { return new(bytes.Buffer) })
+
+		err := c.Invoke(func(*Buffer) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "*dig_test.Buffer (did you mean *bytes.Buffer?)")
+	})
+
+	t.Run("requesting a name when the type is provided under other names", func(t *testing.T) {
+		type A struct{}
+		type out struct {
+			dig.Out
+
+			A A `name:"foo"`
+		}
+
+		c := digtest.New(t, dig.DryRun(dryRun))
+		c.RequireProvide(func() A { return A{} })
+		c.RequireProvide(func() out { return out{} })
+
+		err := c.Invoke(func(struct {
+			dig.In
+
+			A A `name:"bar"`
+		}) {
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`dig_test.A[name="bar"] (did you mean dig_test.A, or dig_test.A[name="foo"]?)`)
+	})
+
 	t.Run("requesting a slice of values or pointers when the other is present", func(t *testing.T) {
 		type A struct{}
 		type outA struct {
@@ -4282,3 +5466,193 @@ func TestEndToEndSuccessWithAliases(t *testing.T) {
 		})
 	})
 }
+
+func TestNewWithParent(t *testing.T) {
+	t.Parallel()
+
+	type Logger struct{ Name string }
+	type Plugin struct{ Logger *Logger }
+
+	t.Run("missing values fall back to the parent", func(t *testing.T) {
+		t.Parallel()
+
+		parent := digtest.New(t)
+		calls := 0
+		parent.RequireProvide(func() *Logger {
+			calls++
+			return &Logger{Name: "app"}
+		})
+
+		c := dig.NewWithParent(parent.Container)
+		require.NoError(t, c.Provide(func(l *Logger) *Plugin { return &Plugin{Logger: l} }))
+
+		var plugin *Plugin
+		require.NoError(t, c.Invoke(func(p *Plugin) { plugin = p }))
+		assert.Equal(t, "app", plugin.Logger.Name)
+
+		parent.RequireInvoke(func(l *Logger) {
+			assert.Same(t, plugin.Logger, l, "values of the parent are shared")
+		})
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("provides never mutate the parent", func(t *testing.T) {
+		t.Parallel()
+
+		parent := digtest.New(t)
+		parent.RequireProvide(func() *Logger { return &Logger{Name: "app"} })
+
+		c := dig.NewWithParent(parent.Container)
+		require.NoError(t, c.Provide(func() *Plugin { return &Plugin{} }, dig.Export(true)))
+		require.NoError(t, c.Provide(func() *Logger { return &Logger{Name: "lib"} }))
+
+		require.NoError(t, c.Invoke(func(l *Logger) {
+			assert.Equal(t, "lib", l.Name, "values of the container shadow the parent's")
+		}))
+		parent.RequireInvoke(func(l *Logger) {
+			assert.Equal(t, "app", l.Name)
+		})
+		assert.Error(t, parent.Invoke(func(*Plugin) {}))
+	})
+
+	t.Run("value groups and decorators", func(t *testing.T) {
+		t.Parallel()
+
+		type Param struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}
+
+		parent := digtest.New(t)
+		parent.RequireProvide(func() string { return "app" }, dig.Group("names"))
+		parent.RequireProvide(func() *Logger { return &Logger{Name: "app"} })
+		parent.RequireDecorate(func(l *Logger) *Logger { return &Logger{Name: l.Name + "'"} })
+
+		c := dig.NewWithParent(parent.Container)
+		require.NoError(t, c.Provide(func() string { return "lib" }, dig.Group("names")))
+		require.NoError(t, c.Invoke(func(p Param, l *Logger) {
+			assert.ElementsMatch(t, []string{"app", "lib"}, p.Names)
+			assert.Equal(t, "app'", l.Name)
+		}))
+		parent.RequireInvoke(func(p Param) {
+			assert.Equal(t, []string{"app"}, p.Names)
+		})
+	})
+
+	t.Run("cycles within the container are detected", func(t *testing.T) {
+		t.Parallel()
+
+		parent := digtest.New(t)
+		parent.RequireProvide(func() *Logger { return &Logger{} })
+
+		c := dig.NewWithParent(parent.Container)
+		require.NoError(t, c.Provide(func(*Logger, *Plugin) string { return "" }))
+		err := c.Provide(func(string) *Plugin { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle")
+	})
+}
+
+func TestInvokeReportsAllFailures(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type missing struct{}
+
+	t.Parallel()
+
+	errSadness := errors.New("great sadness")
+
+	type in struct {
+		dig.In
+
+		A *A
+		B *B
+		C *C
+	}
+
+	t.Run("independent failures", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, error) { return nil, errSadness })
+		c.RequireProvide(func(*missing) *B { return &B{} })
+		c.RequireProvide(func() *C { return &C{} })
+
+		err := c.Invoke(func(in) {})
+		require.Error(t, err)
+		assert.Regexp(t, `^could not build arguments for function "github.com/alexisvisco/dig_test".TestInvokeReportsAllFailures\S+ \(\S+\): `+
+			`2 dependencies failed: failed to build \*dig_test.A: .+: great sadness; `+
+			`failed to build \*dig_test.B: missing dependencies for function .+: missing type: \*dig_test.missing$`, err.Error())
+		assert.True(t, errors.Is(err, errSadness))
+
+		var md dig.ErrMissingDependency
+		require.True(t, errors.As(err, &md))
+		assert.Equal(t, reflect.TypeOf(&missing{}), md.Key.Type)
+
+		var multi interface{ Unwrap() []error }
+		require.True(t, errors.As(err, &multi))
+		assert.Len(t, multi.Unwrap(), 2)
+
+		assert.Contains(t, fmt.Sprintf("%+v", err), "2 dependencies failed:\n\t- failed to build *dig_test.A:\n")
+
+		require.True(t, dig.CanVisualizeError(err))
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b, dig.VisualizeError(err)))
+		assert.Contains(t, b.String(), `"*dig_test.A" [color=red];`)
+		assert.Contains(t, b.String(), `"*dig_test.missing" [color=`)
+	})
+
+	t.Run("shared failure", func(t *testing.T) {
+		t.Parallel()
+
+		type D struct{}
+		calls := 0
+		c := digtest.New(t)
+		c.RequireProvide(func() (*D, error) { calls++; return nil, errSadness })
+		c.RequireProvide(func(*D) *A { return &A{} })
+		c.RequireProvide(func(*D) *B { return &B{} })
+		c.RequireProvide(func() *C { return &C{} })
+
+		err := c.Invoke(func(in) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 dependencies failed")
+		assert.Equal(t, 1, calls, "the failing constructor must be called once")
+
+		// The constructor is called again by later Invokes.
+		require.Error(t, c.Invoke(func(*A) {}))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("single failure", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, error) { return nil, errSadness })
+		c.RequireProvide(func() *B { return &B{} })
+		c.RequireProvide(func() *C { return &C{} })
+
+		err := c.Invoke(func(in) {})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "dependencies failed")
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("constructors fail fast", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct{}
+		calls := 0
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, error) { return nil, errSadness })
+		c.RequireProvide(func() (*B, error) { calls++; return nil, errSadness })
+		c.RequireProvide(func() *C { return &C{} })
+		c.RequireProvide(func(in) *out { return &out{} })
+
+		err := c.Invoke(func(*out) {})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "dependencies failed")
+		assert.Zero(t, calls)
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package digconfig provides configuration structs to a dig container,
+// loaded from environment variables, JSON or YAML documents.
+//
+//	type HTTPConfig struct {
+//	  Addr        string
+//	  ReadTimeout time.Duration `json:"read_timeout" yaml:"read_timeout"`
+//	}
+//
+//	err := digconfig.Provide[HTTPConfig](c, "http",
+//	  digconfig.YAMLFile("config.yaml"),
+//	  digconfig.Env(),
+//	)
+//
+// The configuration is loaded when the container first needs it, from each
+// of the sources in order: values of later sources override the values of
+// earlier ones, and fields missing from all the sources keep their zero
+// values. The constructors depending on HTTPConfig then receive it like any
+// other value of the container.
+package digconfig
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/alexisvisco/dig"
+)
+
+// A Source loads configuration values into structs.
+type Source interface {
+	// Load fills the fields of the struct pointed to by dst with the values
+	// of the source found under prefix. Fields without a value in the
+	// source are left unchanged.
+	Load(prefix string, dst interface{}) error
+}
+
+// Provider is a dig.Container or a dig.Scope.
+type Provider interface {
+	Provide(constructor interface{}, opts ...dig.ProvideOption) error
+}
+
+// Provide provides a constructor of T to c that loads T from the given
+// sources, under prefix. T must be a struct or a pointer to a struct. The
+// prefix selects where the values of T are in the sources: see Env, JSON and
+// YAML. If no source is given, T is loaded from the environment.
+//
+// Errors of the sources are returned by the constructor of T, so they are
+// reported by the Invoke calls that depend on it.
+func Provide[T any](c Provider, prefix string, sources ...Source) error {
+	pc, _, _, _ := runtime.Caller(1)
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot provide config %v: must be a struct or a pointer to a struct", t)
+	}
+	if len(sources) == 0 {
+		sources = []Source{Env()}
+	}
+
+	load := func() (T, error) {
+		ptr := reflect.New(st)
+		for _, src := range sources {
+			if err := src.Load(prefix, ptr.Interface()); err != nil {
+				var zero T
+				return zero, fmt.Errorf("cannot load config %v from %v: %w", t, src, err)
+			}
+		}
+		if t.Kind() == reflect.Ptr {
+			return ptr.Interface().(T), nil
+		}
+		return ptr.Elem().Interface().(T), nil
+	}
+	return c.Provide(load, dig.LocationForPC(pc))
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digconfig_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TLSConfig struct {
+	Cert string `json:"cert" yaml:"cert"`
+}
+
+type HTTPConfig struct {
+	Addr        string        `json:"addr" yaml:"addr"`
+	ReadTimeout time.Duration `json:"read_timeout" yaml:"read_timeout"`
+	Hosts       []string      `json:"hosts" yaml:"hosts"`
+	Debug       *bool         `json:"debug" yaml:"debug"`
+	IP          net.IP        `json:"ip" yaml:"ip"`
+	Port        int           `env:"PORT_NUMBER" json:"port" yaml:"port"`
+	Ignored     string        `env:"-" json:"-" yaml:"-"`
+	TLS         TLSConfig     `json:"tls" yaml:"tls"`
+}
+
+const _yamlConfig = `
+http:
+  server:
+    addr: ":8080"
+    read_timeout: 5s
+    hosts: [a, b]
+    tls:
+      cert: server.pem
+`
+
+const _jsonConfig = `{
+  "http": {
+    "server": {
+      "addr": ":9090",
+      "port": 9090,
+      "tls": {"cert": "override.pem"}
+    }
+  }
+}`
+
+func TestProvide(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sources override each other", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		require.NoError(t, digconfig.Provide[HTTPConfig](c, "http.server",
+			digconfig.YAML([]byte(_yamlConfig)),
+			digconfig.JSON([]byte(_jsonConfig)),
+		))
+
+		require.NoError(t, c.Invoke(func(cfg HTTPConfig) {
+			assert.Equal(t, ":9090", cfg.Addr)
+			assert.Equal(t, 9090, cfg.Port)
+			assert.Equal(t, 5*time.Second, cfg.ReadTimeout)
+			assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+			assert.Equal(t, "override.pem", cfg.TLS.Cert)
+		}))
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		require.NoError(t, digconfig.Provide[*HTTPConfig](c, "http.server", digconfig.YAML([]byte(_yamlConfig))))
+		require.NoError(t, c.Invoke(func(cfg *HTTPConfig) {
+			assert.Equal(t, ":8080", cfg.Addr)
+		}))
+	})
+
+	t.Run("files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		yamlPath := filepath.Join(dir, "config.yaml")
+		jsonPath := filepath.Join(dir, "config.json")
+		require.NoError(t, os.WriteFile(yamlPath, []byte(_yamlConfig), 0o600))
+		require.NoError(t, os.WriteFile(jsonPath, []byte(`{"tls": {"cert": "file.pem"}}`), 0o600))
+
+		c := dig.New()
+		require.NoError(t, digconfig.Provide[HTTPConfig](c, "http.server", digconfig.YAMLFile(yamlPath)))
+		require.NoError(t, digconfig.Provide[TLSConfig](c, "tls", digconfig.JSONFile(jsonPath)))
+		require.NoError(t, c.Invoke(func(cfg HTTPConfig, tls TLSConfig) {
+			assert.Equal(t, ":8080", cfg.Addr)
+			assert.Equal(t, "file.pem", tls.Cert)
+		}))
+	})
+
+	t.Run("missing prefix", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		require.NoError(t, digconfig.Provide[HTTPConfig](c, "grpc",
+			digconfig.YAML([]byte(_yamlConfig)),
+			digconfig.JSON([]byte(_jsonConfig)),
+		))
+		require.NoError(t, c.Invoke(func(cfg HTTPConfig) {
+			assert.Equal(t, HTTPConfig{}, cfg)
+		}))
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		s := dig.New().Scope("child")
+		require.NoError(t, digconfig.Provide[TLSConfig](s, "http.server.tls", digconfig.YAML([]byte(_yamlConfig))))
+		require.NoError(t, s.Invoke(func(cfg TLSConfig) {
+			assert.Equal(t, "server.pem", cfg.Cert)
+		}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		require.NoError(t, digconfig.Provide[HTTPConfig](c, "http.server.addr.value", digconfig.YAML([]byte(_yamlConfig))))
+		err := c.Invoke(func(HTTPConfig) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot load config digconfig_test.HTTPConfig from YAML(`)
+		assert.Contains(t, err.Error(), `"http.server.addr" is not a mapping`)
+
+		c = dig.New()
+		require.NoError(t, digconfig.Provide[HTTPConfig](c, "",
+			digconfig.JSONFile(filepath.Join(t.TempDir(), "missing.json"))))
+		err = c.Invoke(func(HTTPConfig) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `from JSONFile(`)
+		assert.Contains(t, err.Error(), "TestProvide.func6", "errors report where Provide was called")
+
+		err = digconfig.Provide[string](dig.New(), "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot provide config string: must be a struct or a pointer to a struct")
+	})
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("HTTP_SERVER_ADDR", ":7070")
+	t.Setenv("HTTP_SERVER_READ_TIMEOUT", "1m")
+	t.Setenv("HTTP_SERVER_HOSTS", "a, b,c")
+	t.Setenv("HTTP_SERVER_DEBUG", "true")
+	t.Setenv("HTTP_SERVER_IP", "127.0.0.1")
+	t.Setenv("HTTP_SERVER_PORT_NUMBER", "0x1F90")
+	t.Setenv("HTTP_SERVER_IGNORED", "ignored")
+	t.Setenv("HTTP_SERVER_TLS_CERT", "env.pem")
+
+	t.Run("fields", func(t *testing.T) {
+		var cfg HTTPConfig
+		require.NoError(t, digconfig.Env().Load("http.server", &cfg))
+
+		debug := true
+		assert.Equal(t, HTTPConfig{
+			Addr:        ":7070",
+			ReadTimeout: time.Minute,
+			Hosts:       []string{"a", "b", "c"},
+			Debug:       &debug,
+			IP:          net.ParseIP("127.0.0.1"),
+			Port:        8080,
+			TLS:         TLSConfig{Cert: "env.pem"},
+		}, cfg)
+	})
+
+	t.Run("default source", func(t *testing.T) {
+		c := dig.New()
+		require.NoError(t, digconfig.Provide[TLSConfig](c, "HTTP_SERVER_TLS"))
+		require.NoError(t, c.Invoke(func(cfg TLSConfig) {
+			assert.Equal(t, "env.pem", cfg.Cert)
+		}))
+	})
+
+	t.Run("overrides files", func(t *testing.T) {
+		c := dig.New()
+		require.NoError(t, digconfig.Provide[HTTPConfig](c, "http.server",
+			digconfig.YAML([]byte(_yamlConfig)),
+			digconfig.Env(),
+		))
+		require.NoError(t, c.Invoke(func(cfg HTTPConfig) {
+			assert.Equal(t, ":7070", cfg.Addr)
+		}))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("BAD_PORT_NUMBER", "eighty")
+
+		var cfg HTTPConfig
+		err := digconfig.Env().Load("bad", &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid value "eighty" of BAD_PORT_NUMBER for field Port`)
+
+		err = digconfig.Env().Load("bad", cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be a pointer to a struct")
+	})
+
+	t.Run("names", func(t *testing.T) {
+		type Names struct {
+			HTTPPort   int
+			MaxConns2  int
+			UserID     string
+			snakeCase_ string
+		}
+		t.Setenv("NAMES_HTTP_PORT", "1")
+		t.Setenv("NAMES_MAX_CONNS2", "2")
+		t.Setenv("NAMES_USER_ID", "3")
+
+		var n Names
+		require.NoError(t, digconfig.Env().Load("names", &n))
+		assert.Equal(t, Names{HTTPPort: 1, MaxConns2: 2, UserID: "3"}, n)
+	})
+}
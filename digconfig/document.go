@@ -0,0 +1,148 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSON is a Source that loads structs from the given JSON document. The
+// prefix is a dot-separated path of keys to the object that is decoded into
+// the struct, with encoding/json: with the prefix "http.server", the struct
+// is decoded from the object {"http": {"server": {...}}}. Structs are left
+// unchanged if the document has no such object.
+func JSON(data []byte) Source {
+	return documentSource{
+		name:   fmt.Sprintf("JSON(%d bytes)", len(data)),
+		read:   func() ([]byte, error) { return data, nil },
+		decode: decodeJSON,
+	}
+}
+
+// JSONFile is a Source that loads structs from the JSON document in the file
+// at path, which is read every time a struct is loaded. See JSON.
+func JSONFile(path string) Source {
+	return documentSource{
+		name:   fmt.Sprintf("JSONFile(%q)", path),
+		read:   func() ([]byte, error) { return os.ReadFile(path) },
+		decode: decodeJSON,
+	}
+}
+
+// YAML is a Source that loads structs from the given YAML document. The
+// prefix is a dot-separated path of keys to the mapping that is decoded into
+// the struct, with gopkg.in/yaml.v3: with the prefix "http.server", the
+// struct is decoded from the mapping under
+//
+//	http:
+//	  server:
+//
+// Structs are left unchanged if the document has no such mapping.
+func YAML(data []byte) Source {
+	return documentSource{
+		name:   fmt.Sprintf("YAML(%d bytes)", len(data)),
+		read:   func() ([]byte, error) { return data, nil },
+		decode: decodeYAML,
+	}
+}
+
+// YAMLFile is a Source that loads structs from the YAML document in the file
+// at path, which is read every time a struct is loaded. See YAML.
+func YAMLFile(path string) Source {
+	return documentSource{
+		name:   fmt.Sprintf("YAMLFile(%q)", path),
+		read:   func() ([]byte, error) { return os.ReadFile(path) },
+		decode: decodeYAML,
+	}
+}
+
+// documentSource is a Source loading structs from a JSON or YAML document.
+type documentSource struct {
+	name   string
+	read   func() ([]byte, error)
+	decode func(data []byte, keys []string, dst interface{}) error
+}
+
+func (s documentSource) String() string {
+	return s.name
+}
+
+func (s documentSource) Load(prefix string, dst interface{}) error {
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	var keys []string
+	if prefix != "" {
+		keys = strings.Split(prefix, ".")
+	}
+	return s.decode(data, keys, dst)
+}
+
+func decodeJSON(data []byte, keys []string, dst interface{}) error {
+	raw := json.RawMessage(data)
+	for i, key := range keys {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return fmt.Errorf("%q is not an object: %w", strings.Join(keys[:i], "."), err)
+		}
+		var ok bool
+		if raw, ok = obj[key]; !ok {
+			return nil
+		}
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+func decodeYAML(data []byte, keys []string, dst interface{}) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		// The document is empty.
+		return nil
+	}
+
+	node := doc.Content[0]
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%q is not a mapping", strings.Join(keys[:i], "."))
+		}
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				next = node.Content[j+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node.Decode(dst)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digconfig
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Env is a Source that loads the fields of structs from environment
+// variables. The name of the variable of a field is the prefix followed by
+// the name of the field, in upper snake case and separated by underscores:
+// with the prefix "http.server", the field ReadTimeout is loaded from
+// HTTP_SERVER_READ_TIMEOUT. The name of a field may be set with an env tag,
+// and fields tagged `env:"-"` are not loaded.
+//
+//	type Config struct {
+//	  Addr    string        // HTTP_ADDR
+//	  Timeout time.Duration `env:"TIMEOUT_MS"` // HTTP_TIMEOUT_MS
+//	  TLS     struct {
+//	    Cert string // HTTP_TLS_CERT
+//	  }
+//	}
+//
+// Fields may be strings, booleans, numbers, durations, types implementing
+// encoding.TextUnmarshaler, pointers to them, or slices of them given as
+// comma-separated values. Fields of nested structs are loaded recursively.
+func Env() Source {
+	return envSource{lookup: os.LookupEnv}
+}
+
+type envSource struct {
+	lookup func(string) (string, bool)
+}
+
+func (envSource) String() string {
+	return "Env()"
+}
+
+func (s envSource) Load(prefix string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot load %T: must be a pointer to a struct", dst)
+	}
+	return s.loadStruct(envName(prefix), v.Elem())
+}
+
+func (s envSource) loadStruct(prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Tag.Get("env")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = envName(f.Name)
+		}
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		fv := v.Field(i)
+		if f.Type.Kind() == reflect.Struct && !isTextUnmarshaler(f.Type) {
+			if err := s.loadStruct(name, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := s.lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setValue(fv, value); err != nil {
+			return fmt.Errorf("invalid value %q of %v for field %v: %w", value, name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+var (
+	_durationType        = reflect.TypeOf(time.Duration(0))
+	_textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(_textUnmarshalerType)
+}
+
+// setValue parses s into v.
+func setValue(v reflect.Value, s string) error {
+	t := v.Type()
+	switch {
+	case isTextUnmarshaler(t):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	case t == _durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Ptr:
+		elem := reflect.New(t.Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(t, len(parts), len(parts))
+		for i, p := range parts {
+			if err := setValue(slice.Index(i), strings.TrimSpace(p)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %v", t)
+	}
+	return nil
+}
+
+// envName converts a prefix or a field name to the name of an environment
+// variable: HTTPServer and http.server both become HTTP_SERVER.
+func envName(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '.' || r == '-' || r == '_' || r == ' ':
+			b.WriteRune('_')
+			continue
+		case i > 0 && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
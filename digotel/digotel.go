@@ -0,0 +1,99 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package digotel traces the constructors of a dig container with
+// OpenTelemetry.
+//
+// Each call to a constructor produces a span named after the constructor,
+// so that the resolution of the dependency graph shows up in traces:
+//
+//	c := dig.New(digotel.WithTracerProvider(tp))
+package digotel
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alexisvisco/dig"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/alexisvisco/dig/digotel"
+
+// Attributes recorded on the spans of constructors.
+const (
+	// OutputsKey lists the types produced by the constructor.
+	OutputsKey = attribute.Key("dig.outputs")
+
+	// ScopeKey is the path of the Scope the constructor was provided to,
+	// with the names of the scopes separated by "/". It is empty for
+	// constructors provided to the container itself.
+	ScopeKey = attribute.Key("dig.scope")
+)
+
+// WithTracerProvider is a dig.Option that starts a span, using a tracer of
+// the given TracerProvider, around every call to a constructor of the
+// container or of its Scopes. If tp is nil, the global TracerProvider is
+// used.
+//
+// The spans are started from the context of the Invoke the constructors are
+// called for, so that they join the trace of the caller of InvokeCtx:
+//
+//	ctx, span := tracer.Start(ctx, "startup")
+//	defer span.End()
+//	err := c.InvokeCtx(ctx, run) // constructor spans are children of span
+//
+// Spans of failed constructors record the error and have an error status.
+func WithTracerProvider(tp trace.TracerProvider) dig.Option {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	tracer := tp.Tracer(instrumentationName)
+
+	return dig.WithProviderInterceptorCtx(func(ctx context.Context, info dig.ProviderInfo, next func() error) error {
+		_, span := tracer.Start(ctx, info.Name,
+			trace.WithAttributes(attributes(info)...))
+		defer span.End()
+
+		err := next()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	})
+}
+
+func attributes(info dig.ProviderInfo) []attribute.KeyValue {
+	outputs := make([]string, len(info.Outputs))
+	for i, o := range info.Outputs {
+		outputs[i] = o.String()
+	}
+
+	return []attribute.KeyValue{
+		OutputsKey.StringSlice(outputs),
+		ScopeKey.String(strings.Join(info.ScopePath, "/")),
+		attribute.String("code.filepath", info.File),
+		attribute.Int("code.lineno", info.Line),
+	}
+}
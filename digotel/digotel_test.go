@@ -0,0 +1,113 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digotel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type A struct{}
+type B struct{}
+
+func newA() *A           { return &A{} }
+func newB(*A) *B         { return &B{} }
+func failA() (*A, error) { return nil, errors.New("great sadness") }
+
+func newTracing(t *testing.T) (dig.Option, *tracetest.SpanRecorder) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+	return digotel.WithTracerProvider(tp), sr
+}
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestWithTracerProvider(t *testing.T) {
+	t.Run("span per constructor", func(t *testing.T) {
+		opt, sr := newTracing(t)
+		c := dig.New(opt)
+		require.NoError(t, c.Provide(newA))
+		child := c.Scope("child")
+		require.NoError(t, child.Provide(newB))
+		require.NoError(t, child.Invoke(func(*B) {}))
+
+		spans := sr.Ended()
+		require.Len(t, spans, 2)
+
+		assert.Equal(t, "github.com/alexisvisco/dig/digotel_test.newA", spans[0].Name())
+		assert.Equal(t, []string{"*digotel_test.A"}, attrs(spans[0])[digotel.OutputsKey].AsStringSlice())
+		assert.Equal(t, "", attrs(spans[0])[digotel.ScopeKey].AsString())
+
+		assert.Equal(t, "github.com/alexisvisco/dig/digotel_test.newB", spans[1].Name())
+		assert.Equal(t, []string{"*digotel_test.B"}, attrs(spans[1])[digotel.OutputsKey].AsStringSlice())
+		assert.Equal(t, "child", attrs(spans[1])[digotel.ScopeKey].AsString())
+	})
+
+	t.Run("failing constructor", func(t *testing.T) {
+		opt, sr := newTracing(t)
+		c := dig.New(opt)
+		require.NoError(t, c.Provide(failA))
+		require.Error(t, c.Invoke(func(*A) {}))
+
+		spans := sr.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "great sadness", spans[0].Status().Description)
+	})
+
+	t.Run("spans join the invoke trace", func(t *testing.T) {
+		sr := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+		t.Cleanup(func() { assert.NoError(t, tp.Shutdown(context.Background())) })
+
+		c := dig.New(digotel.WithTracerProvider(tp))
+		require.NoError(t, c.Provide(newA))
+		require.NoError(t, c.Provide(newB))
+
+		ctx, parent := tp.Tracer("test").Start(context.Background(), "startup")
+		require.NoError(t, c.InvokeCtx(ctx, func(*B) {}))
+		parent.End()
+
+		spans := sr.Ended()
+		require.Len(t, spans, 3)
+		for _, span := range spans[:2] {
+			assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package digplugin loads dig plugins built as Go plugins, with
+// "go build -buildmode=plugin", so that applications can be extended at
+// runtime.
+//
+//	// In the plugin.
+//	package main
+//
+//	func Setup(c *dig.Container) error {
+//	  return c.Provide(NewGreeter)
+//	}
+//
+//	// In the application.
+//	if err := digplugin.Open("plugins/greeter.so"); err != nil {
+//	  return err
+//	}
+//	err := c.LoadPlugins("greeter")
+//
+// Plugins may also register themselves with dig.Register from their init
+// functions, which run when they are opened. The plugins must be built with
+// the same version of dig as the application, as with any Go plugin.
+package digplugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/alexisvisco/dig"
+)
+
+// SetupSymbol is the name of the function that Open registers as the plugin
+// of a Go plugin, if the Go plugin exports it. Its type must be
+// func(*dig.Container) error.
+const SetupSymbol = "Setup"
+
+// Open opens the Go plugin at the given path and registers its Setup
+// function, if any, to dig.DefaultRegistry under the base name of the path,
+// without its extension. See OpenRegistry.
+func Open(path string) error {
+	return OpenRegistry(dig.DefaultRegistry, path)
+}
+
+// OpenRegistry opens the Go plugin at the given path and registers its Setup
+// function, if any, to the registry under the base name of the path,
+// without its extension: "plugins/greeter.so" registers "greeter".
+//
+// Opening a Go plugin that was already opened does not register its Setup
+// function again.
+func OpenRegistry(r *dig.Registry, path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot open plugin %q: %w", path, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := opened[openedPlugin{r, p}]; ok {
+		return nil
+	}
+
+	sym, err := p.Lookup(SetupSymbol)
+	if err != nil {
+		// The plugin registers itself, if at all.
+		return nil
+	}
+
+	var setup dig.PluginFunc
+	switch f := sym.(type) {
+	case func(*dig.Container) error:
+		setup = f
+	case *func(*dig.Container) error:
+		setup = *f
+	default:
+		return fmt.Errorf("cannot open plugin %q: %v is %T, not func(*dig.Container) error", path, SetupSymbol, sym)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := r.Register(name, setup); err != nil {
+		return fmt.Errorf("cannot open plugin %q: %w", path, err)
+	}
+	opened[openedPlugin{r, p}] = struct{}{}
+	return nil
+}
+
+// Go plugins whose Setup function was registered, by registry. plugin.Open
+// returns the same *plugin.Plugin when a Go plugin is opened again.
+var (
+	mu     sync.Mutex
+	opened = make(map[openedPlugin]struct{})
+)
+
+type openedPlugin struct {
+	r *dig.Registry
+	p *plugin.Plugin
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digplugin_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digplugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		err := digplugin.OpenRegistry(dig.NewRegistry(), filepath.Join(t.TempDir(), "missing.so"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot open plugin")
+		assert.Contains(t, err.Error(), "missing.so")
+	})
+
+	t.Run("setup", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("builds a Go plugin")
+		}
+
+		path := filepath.Join(t.TempDir(), "greeter.so")
+		out, err := exec.Command("go", "build", "-buildmode=plugin", "-o", path, "./testdata/greeter").CombinedOutput()
+		require.NoError(t, err, "cannot build plugin: %s", out)
+
+		r := dig.NewRegistry()
+		require.NoError(t, digplugin.OpenRegistry(r, path))
+		require.NoError(t, digplugin.OpenRegistry(r, path), "opening a plugin again must succeed")
+		assert.Equal(t, []string{"greeter"}, r.Names())
+
+		c := dig.New()
+		require.NoError(t, r.Load(c, "greeter"))
+		type params struct {
+			dig.In
+
+			Greeting string `name:"greeting"`
+		}
+		require.NoError(t, c.Invoke(func(p params) {
+			assert.Equal(t, "hello", p.Greeting)
+		}))
+	})
+}
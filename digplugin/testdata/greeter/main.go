@@ -1,4 +1,4 @@
-// Copyright (c) 2022 Uber Technologies, Inc.
+// Copyright (c) 2021 Uber Technologies, Inc.
 //
 // Permission is hereby granted, free of charge, to any person obtaining a copy
 // of this software and associated documentation files (the "Software"), to deal
@@ -18,6 +18,14 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
 
-// Package digtest provides utilities used by dig internally to test its own
-// functionality.
-package digtest
+// Command greeter is a Go plugin used by the tests of digplugin.
+package main
+
+import "github.com/alexisvisco/dig"
+
+// Setup provides a string named "greeting" to the container.
+func Setup(c *dig.Container) error {
+	return c.Provide(func() string { return "hello" }, dig.Name("greeting"))
+}
+
+func main() {}
@@ -0,0 +1,90 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RequireValid checks the graph of the container with dig.Container.Validate,
+// halting the test if a constructor, decorator or registered Invoke has
+// missing dependencies or if the graph has a cycle.
+func (c *Container) RequireValid() {
+	c.t.Helper()
+
+	require.NoError(c.t, c.Validate(), "invalid graph")
+}
+
+// AssertProvides checks that a constructor of the container or one of its
+// scopes provides values of the type pointed to by t, failing the test
+// otherwise. Only the dig.Name and dig.Group options are honored, as with
+// dig.Container.WhoProvides.
+//
+//	c.AssertProvides(new(*sql.DB), dig.Name("ro"))
+//	c.AssertProvides(new(http.Handler), dig.Group("routes"))
+//
+// It returns whether the assertion succeeded.
+func (c *Container) AssertProvides(t interface{}, opts ...dig.ProvideOption) bool {
+	c.t.Helper()
+
+	return assert.NotEmpty(c.t, c.WhoProvides(t, opts...),
+		"no constructor provides %v", describeKey(t, opts))
+}
+
+// AssertNotProvides checks that no constructor of the container or its
+// scopes provides values of the type pointed to by t, failing the test
+// otherwise. See AssertProvides.
+func (c *Container) AssertNotProvides(t interface{}, opts ...dig.ProvideOption) bool {
+	c.t.Helper()
+
+	providers := c.WhoProvides(t, opts...)
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = fmt.Sprintf("%v (%v:%v)", p.Name, p.File, p.Line)
+	}
+	return assert.Empty(c.t, names, "%v is provided", describeKey(t, opts))
+}
+
+// VerifyGraph compares the graph of the container with a golden file. See
+// the VerifyGraph function.
+func (c *Container) VerifyGraph(name string, opts ...dig.VisualizeOption) {
+	c.t.Helper()
+
+	VerifyGraph(c.t, name, c.Container, opts...)
+}
+
+// describeKey describes the values looked up by AssertProvides for its
+// failure messages.
+func describeKey(t interface{}, opts []dig.ProvideOption) string {
+	s := "<nil>"
+	if typ := reflect.TypeOf(t); typ != nil && typ.Kind() == reflect.Ptr {
+		s = typ.Elem().String()
+	}
+	for _, o := range opts {
+		s += fmt.Sprintf(" %v", o)
+	}
+	return s
+}
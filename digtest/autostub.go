@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/alexisvisco/dig"
+)
+
+// StubRegistry holds constructors of stubs of interfaces, such as the
+// constructors of mocks generated by gomock or mockery, for AutoStub.
+//
+//	var stubs = digtest.NewStubRegistry()
+//
+//	func init() {
+//	  stubs.Register(mocks.NewMockClock, mocks.NewMockStore)
+//	}
+//
+// A StubRegistry is safe for concurrent use.
+type StubRegistry struct {
+	mu    sync.RWMutex
+	ctors []reflect.Value
+}
+
+// NewStubRegistry returns an empty StubRegistry.
+func NewStubRegistry() *StubRegistry {
+	return &StubRegistry{}
+}
+
+// Register registers the given constructors of stubs. Each constructor must
+// be a function returning a single value, which AutoStub supplies for the
+// interfaces it implements. Its parameters are filled with the arguments
+// given to AutoStub, such as *gomock.Controller or *testing.T.
+//
+// Register panics if a constructor is not such a function, as it is meant
+// to be called from init functions or TestMain.
+func (r *StubRegistry) Register(constructors ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, ctor := range constructors {
+		v := reflect.ValueOf(ctor)
+		if !v.IsValid() || v.Kind() != reflect.Func || v.Type().NumOut() != 1 {
+			panic(fmt.Sprintf("cannot register stub constructor %v (type %T): must be a function returning a single value", ctor, ctor))
+		}
+		r.ctors = append(r.ctors, v)
+	}
+}
+
+// AutoStub is a dig.Option that supplies a stub for each interface that a
+// function depends on but that nothing provides to the container, built by
+// the first constructor of the registry whose result implements the
+// interface and whose parameters are all filled by the given arguments.
+// Dependencies that are not interfaces, or that no constructor can stub,
+// are still reported missing.
+//
+//	ctrl := gomock.NewController(t)
+//	c := digtest.New(t, digtest.AutoStub(stubs, ctrl))
+//	c.RequireProvide(NewService) // depends on Clock and Store
+//
+// Stubs are built once per container, when first needed, and shared by all
+// the functions that depend on them. To set expectations on a stub, invoke
+// a function depending on its interface before the code under test:
+//
+//	c.RequireInvoke(func(clock Clock) {
+//	  clock.(*mocks.MockClock).EXPECT().Now().Return(now)
+//	})
+//
+// AutoStub is built on dig.WithMissingHandler, so a container cannot have
+// both.
+func AutoStub(r *StubRegistry, args ...interface{}) dig.Option {
+	values := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		values[i] = reflect.ValueOf(arg)
+	}
+	return dig.WithMissingHandler(func(k dig.Key) (interface{}, bool) {
+		if k.Type.Kind() != reflect.Interface {
+			return nil, false
+		}
+		return r.stub(k.Type, values)
+	})
+}
+
+// stub builds a stub implementing the interface t with the first
+// constructor that can build one from the given arguments.
+func (r *StubRegistry) stub(t reflect.Type, args []reflect.Value) (interface{}, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, ctor := range r.ctors {
+		ctype := ctor.Type()
+		if !ctype.Out(0).AssignableTo(t) {
+			continue
+		}
+		in, ok := stubArgs(ctype, args)
+		if !ok {
+			continue
+		}
+		return ctor.Call(in)[0].Interface(), true
+	}
+	return nil, false
+}
+
+// stubArgs returns the arguments to call a constructor of type ctype with:
+// the first of args assignable to each of its parameters.
+func stubArgs(ctype reflect.Type, args []reflect.Value) ([]reflect.Value, bool) {
+	if ctype.IsVariadic() {
+		return nil, false
+	}
+	in := make([]reflect.Value, ctype.NumIn())
+	for i := range in {
+		for _, arg := range args {
+			if arg.IsValid() && arg.Type().AssignableTo(ctype.In(i)) {
+				in[i] = arg
+				break
+			}
+		}
+		if !in[i].IsValid() {
+			return nil, false
+		}
+	}
+	return in, true
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	stubClock interface{ Now() time.Time }
+	stubStore interface{ Get(string) string }
+
+	// stubController stands for the controllers of mocking libraries.
+	stubController struct{ t testing.TB }
+
+	mockClock struct {
+		ctrl *stubController
+		now  time.Time
+	}
+	mockStore struct{ t testing.TB }
+)
+
+func (m *mockClock) Now() time.Time { return m.now }
+
+func (m *mockStore) Get(string) string { return "stub" }
+
+func newMockClock(ctrl *stubController) *mockClock { return &mockClock{ctrl: ctrl} }
+
+func newMockStore(t testing.TB) *mockStore { return &mockStore{t: t} }
+
+func TestAutoStub(t *testing.T) {
+	stubs := NewStubRegistry()
+	stubs.Register(newMockClock, newMockStore)
+
+	t.Run("stubs missing interfaces", func(t *testing.T) {
+		ctrl := &stubController{t: t}
+		c := New(t, AutoStub(stubs, ctrl, t))
+
+		now := time.Now()
+		c.RequireInvoke(func(clock stubClock) {
+			m, ok := clock.(*mockClock)
+			require.True(t, ok)
+			assert.Same(t, ctrl, m.ctrl)
+			m.now = now
+		})
+
+		type service struct {
+			clock stubClock
+			store stubStore
+		}
+		c.RequireProvide(func(clock stubClock, store stubStore) *service {
+			return &service{clock: clock, store: store}
+		})
+		c.RequireInvoke(func(s *service) {
+			assert.Equal(t, now, s.clock.Now(), "stubs must be shared")
+			assert.Equal(t, "stub", s.store.Get("key"))
+			assert.Same(t, t, s.store.(*mockStore).t)
+		})
+	})
+
+	t.Run("provided interfaces are not stubbed", func(t *testing.T) {
+		c := New(t, AutoStub(stubs, &stubController{t: t}))
+		clock := &mockClock{}
+		c.RequireProvide(func() stubClock { return clock })
+		c.RequireInvoke(func(got stubClock) { assert.Same(t, clock, got) })
+	})
+
+	t.Run("unstubbable dependencies", func(t *testing.T) {
+		// No argument fills the parameter of newMockClock.
+		c := New(t, AutoStub(stubs))
+		err := c.Invoke(func(stubClock) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type:")
+
+		c = New(t, AutoStub(stubs, &stubController{t: t}))
+		err = c.Invoke(func(*mockClock) {})
+		require.Error(t, err, "only interfaces are stubbed")
+	})
+
+	t.Run("invalid constructors", func(t *testing.T) {
+		r := NewStubRegistry()
+		assert.Panics(t, func() { r.Register(nil) })
+		assert.Panics(t, func() { r.Register(&mockClock{}) })
+		assert.Panics(t, func() { r.Register(func() {}) })
+		assert.Panics(t, func() { r.Register(func() (*mockClock, error) { return nil, nil }) })
+	})
+}
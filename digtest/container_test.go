@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer(t *testing.T) {
+	t.Run("require", func(t *testing.T) {
+		c := New(t)
+		c.RequireProvide(func() *graphConfig { return &graphConfig{} })
+		c.RequireDecorate(func(cfg *graphConfig) *graphConfig { return cfg })
+		s := c.Scope("child")
+		s.RequireProvide(func(*graphConfig) *graphServer { return &graphServer{} })
+		s.RequireInvoke(func(*graphServer) {})
+		c.RequireInvoke(func(*graphConfig) {})
+	})
+
+	t.Run("require fails", func(t *testing.T) {
+		r := &recordingTB{TB: t}
+		c := New(r)
+		r.run(func(testing.TB) { c.RequireInvoke(func(*graphConfig) {}) })
+		assert.True(t, r.failed)
+		require.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "failed to invoke")
+	})
+}
+
+func TestStub(t *testing.T) {
+	t.Run("replaces constructors", func(t *testing.T) {
+		c := New(t)
+		c.RequireProvide(func() io.Reader { return strings.NewReader("real") })
+		c.RequireProvide(func(r io.Reader) string {
+			b, err := io.ReadAll(r)
+			require.NoError(t, err)
+			return string(b)
+		})
+		c.RequireInvoke(func(s string) { assert.Equal(t, "real", s) })
+
+		Stub[io.Reader](c, bytes.NewBufferString("stub"))
+		c.RequireInvoke(func(s string) { assert.Equal(t, "stub", s) })
+	})
+
+	t.Run("provides missing values", func(t *testing.T) {
+		c := New(t)
+		Stub(c, &graphConfig{}, dig.Name("test"))
+
+		type params struct {
+			dig.In
+
+			Config *graphConfig `name:"test"`
+		}
+		c.RequireInvoke(func(p params) { assert.NotNil(t, p.Config) })
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		c := New(t)
+		s := c.Scope("child")
+		Stub(s, 42)
+		s.RequireInvoke(func(i int) { assert.Equal(t, 42, i) })
+	})
+}
+
+func TestAssertions(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := New(t)
+		c.RequireProvide(func() *graphConfig { return &graphConfig{} })
+		c.RequireProvide(func(*graphConfig) *graphServer { return &graphServer{} }, dig.Name("main"))
+		c.RequireValid()
+		c.AssertProvides(new(*graphConfig))
+		c.AssertProvides(new(*graphServer), dig.Name("main"))
+		c.AssertNotProvides(new(*graphServer))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		r := &recordingTB{TB: t}
+		c := New(r)
+		c.RequireProvide(func(*graphConfig) *graphServer { return &graphServer{} })
+
+		assert.False(t, c.AssertProvides(new(*graphConfig)))
+		assert.False(t, c.AssertNotProvides(new(*graphServer)))
+		r.run(func(testing.TB) { c.RequireValid() })
+		assert.True(t, r.failed)
+		require.Len(t, r.errors, 3)
+		assert.Contains(t, r.errors[0], "no constructor provides *digtest.graphConfig")
+		assert.Contains(t, r.errors[1], "*digtest.graphServer is provided")
+		assert.Contains(t, r.errors[1], "TestAssertions.func2.1")
+		assert.Contains(t, r.errors[2], "invalid graph")
+		assert.Contains(t, r.errors[2], "*digtest.graphConfig (did you mean to Provide it?)")
+	})
+
+	t.Run("graph", func(t *testing.T) {
+		c := New(t)
+		c.RequireProvide(func() *graphConfig { return &graphConfig{} })
+		c.RequireProvide(func(*graphConfig) *graphServer { return &graphServer{} })
+		c.VerifyGraph("container")
+	})
+}
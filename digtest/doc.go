@@ -0,0 +1,47 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package digtest provides utilities to test dig containers and the
+// applications wired with them.
+//
+// Container and Scope wrap their dig counterparts with methods that halt the
+// test on failure, which keeps the wiring of tests short:
+//
+//	c := digtest.New(t)
+//	c.RequireProvide(NewConfig)
+//	c.RequireProvide(NewServer)
+//	digtest.Stub[Clock](c, fakeClock{})
+//	c.RequireInvoke(func(s *Server) {
+//	  // ...
+//	})
+//
+// Stub replaces the constructors of a type with a stub value, and
+// RequireValid and AssertProvides check the graph of the container.
+//
+// VerifyGraph compares the graph of a container with a golden file, so that
+// changes to the wiring of an application show up in reviews:
+//
+//	func TestWiring(t *testing.T) {
+//	  c := app.NewContainer()
+//	  digtest.VerifyGraph(t, "wiring.dot", c)
+//	}
+//
+// Run the tests with the -digtest.update flag to write the golden files.
+package digtest
@@ -0,0 +1,77 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("digtest.update", false, "write the golden files of digtest.VerifyGraph")
+
+// VerifyGraph visualizes the container with the given options and compares
+// the output with the golden file of the given name in the testdata
+// directory of the package under test, failing the test if they differ.
+// The name is the name of the file, ".dot" is added if it has no extension:
+// use an extension matching the format of dig.VisualizeFormat, if any.
+//
+// When the tests are run with the -digtest.update flag, VerifyGraph writes
+// the golden file instead.
+//
+//	digtest.VerifyGraph(t, "wiring", c)
+//	digtest.VerifyGraph(t, "wiring.mmd", c, dig.VisualizeFormat(dig.FormatMermaid))
+//
+// The output only depends on the constructors provided to the container and
+// the order they were provided in, so it is stable across runs and
+// machines.
+func VerifyGraph(t testing.TB, name string, c *dig.Container, opts ...dig.VisualizeOption) {
+	t.Helper()
+
+	var b bytes.Buffer
+	require.NoError(t, dig.Visualize(c, &b, opts...), "failed to visualize")
+
+	if filepath.Ext(name) == "" {
+		name += ".dot"
+	}
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, b.Bytes(), 0o644))
+		t.Logf("wrote %v", path)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read golden file: run 'go test -digtest.update' to write it")
+
+	// Golden files may have been checked out with Windows line endings.
+	assert.Equal(t, strings.ReplaceAll(string(want), "\r\n", "\n"), b.String(),
+		"graph does not match %v: run 'go test -digtest.update' to update it", path)
+}
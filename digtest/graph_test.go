@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	graphConfig struct{}
+	graphServer struct{}
+)
+
+func newGraphContainer(t *testing.T) *dig.Container {
+	c := dig.New()
+	require.NoError(t, c.Provide(func() *graphConfig { return &graphConfig{} }))
+	require.NoError(t, c.Provide(func(*graphConfig) *graphServer { return &graphServer{} }))
+	return c
+}
+
+// recordingTB records the failures of a test instead of failing it.
+type recordingTB struct {
+	testing.TB
+
+	errors []string
+	failed bool
+	logs   []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Logf(format string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) FailNow() {
+	r.failed = true
+	panic(r)
+}
+
+// run runs f with the recording test, stopping at FailNow.
+func (r *recordingTB) run(f func(testing.TB)) {
+	defer func() {
+		if p := recover(); p != nil && p != r {
+			panic(p)
+		}
+	}()
+	f(r)
+}
+
+func TestVerifyGraph(t *testing.T) {
+	t.Run("match", func(t *testing.T) {
+		c := newGraphContainer(t)
+		VerifyGraph(t, "graph", c)
+		VerifyGraph(t, "graph.mmd", c, dig.VisualizeFormat(dig.FormatMermaid))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		c := newGraphContainer(t)
+		require.NoError(t, c.Provide(func(*graphServer) string { return "" }))
+
+		r := &recordingTB{TB: t}
+		r.run(func(t testing.TB) { VerifyGraph(t, "graph", c) })
+		require.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], filepath.Join("testdata", "graph.dot"))
+		assert.Contains(t, r.errors[0], "go test -digtest.update")
+	})
+
+	t.Run("missing golden file", func(t *testing.T) {
+		c := newGraphContainer(t)
+		r := &recordingTB{TB: t}
+		r.run(func(t testing.TB) { VerifyGraph(t, "missing", c) })
+		assert.True(t, r.failed)
+		require.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "failed to read golden file")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		dir := t.TempDir()
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+		*update = true
+		defer func() { *update = false }()
+
+		c := newGraphContainer(t)
+		r := &recordingTB{TB: t}
+		r.run(func(t testing.TB) { VerifyGraph(t, "sub/graph", c) })
+		assert.Empty(t, r.errors)
+		assert.Equal(t, []string{"wrote " + filepath.Join("testdata", "sub", "graph.dot")}, r.logs)
+
+		got, err := os.ReadFile(filepath.Join(dir, "testdata", "sub", "graph.dot"))
+		require.NoError(t, err)
+		want, err := os.ReadFile(filepath.Join(wd, "testdata", "graph.dot"))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/stretchr/testify/require"
+)
+
+// replacer is a Container or a Scope.
+type replacer interface {
+	Replace(constructor interface{}, opts ...dig.ProvideOption) error
+	testingT() testing.TB
+}
+
+func (c *Container) testingT() testing.TB { return c.t }
+
+func (s *Scope) testingT() testing.TB { return s.t }
+
+// Stub replaces the constructors of the values of type T of the container
+// or scope with one returning value, halting the test if it fails. Values
+// already built from the replaced constructors are built again, with the
+// stub, when next needed. T may be an interface, to stub the dependencies
+// that constructors take as interfaces:
+//
+//	digtest.Stub[io.Writer](c, &bytes.Buffer{})
+//	digtest.Stub(c, &Config{Addr: ":0"}, dig.Name("test"))
+//
+// The options may name the stubbed value or add it to a value group, as with
+// dig.Container.Replace. Stub also provides the value if nothing provided it
+// yet.
+func Stub[T any](c replacer, value T, opts ...dig.ProvideOption) {
+	t := c.testingT()
+	t.Helper()
+
+	require.NoError(t, c.Replace(func() T { return value }, opts...), "failed to stub")
+}
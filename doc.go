@@ -94,7 +94,10 @@
 //	func NewVoteGateway(db *sql.DB) *VoteGateway
 //
 // The constructor will be called with all other dependencies and no variadic
-// arguments.
+// arguments. To fill the variadic arguments from a value group of their
+// element type instead, annotate the constructor with Variadic.
+//
+//	c.Provide(dig.Annotate(NewVoteGateway, dig.Variadic("vote_options")))
 //
 // # Invoke
 //
@@ -218,6 +221,17 @@
 // The optional tag also allows adding new dependencies without breaking
 // existing consumers of the constructor.
 //
+// A zero value cannot be told apart from a provided value that happens to
+// be zero. Declare the dependency as a dig.Optional to tell whether it was
+// provided.
+//
+//	func NewClient(timeout dig.Optional[time.Duration]) *Client {
+//	  if d, ok := timeout.Get(); ok {
+//	    // ...
+//	  }
+//	  // ...
+//	}
+//
 // # Named Values
 //
 // Some use cases call for multiple values of the same type. Dig allows adding
@@ -345,4 +359,59 @@
 //	  Handler []int `group:"server"`         // [][]int from dig.In
 //	  Handler []int `group:"server,flatten"` // []int from dig.In
 //	}
+//
+// By default, if any of the constructors feeding a value group fails, the
+// consumer of the value group fails as well. Consumers that can operate with
+// a partial group can add the `tolerant` modifier to the group from a dig.In.
+// Values of constructors that failed are left out of the slice, and a group
+// whose constructors all failed is consumed as an empty slice. Failures of
+// the individual constructors may be observed with WithProviderCallback, and
+// are recorded as GroupWarnings reported by Container.Warnings.
+//
+//	type ServerParams struct {
+//	  dig.In
+//
+//	  Handlers []Handler `group:"server,tolerant"`
+//	}
+//
+// Values sent to a value group may also be given a name with the `name:".."`
+// tag, or with the Name option of Provide.
+//
+//	type HandlerResult struct {
+//	  dig.Out
+//
+//	  Handler Handler `group:"server" name:"users"`
+//	}
+//
+// A value group whose values all have a unique name can be consumed as a map
+// keyed by these names, instead of a slice.
+//
+//	type ServerParams struct {
+//	  dig.In
+//
+//	  Handlers map[string]Handler `group:"server"`
+//	}
+//
+// Names cannot be combined with the `flatten` modifier, and a value group
+// that was decorated can only be consumed as a slice.
+//
+// Consumers that need a deterministic order can add the `sorted` modifier to
+// the group from a dig.In. The values are then ordered by ascending
+// `weight:".."`, which defaults to 0, and values of the same weight in the
+// order their constructors were provided.
+//
+//	type HandlerResult struct {
+//	  dig.Out
+//
+//	  Handler Handler `group:"server" weight:"-10"`
+//	}
+//
+//	type ServerParams struct {
+//	  dig.In
+//
+//	  Handlers []Handler `group:"server,sorted"`
+//	}
+//
+// A custom order may be specified for a group with
+// Container.RegisterGroupLess.
 package dig // import "github.com/alexisvisco/dig"
@@ -0,0 +1,117 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"sort"
+
+	"github.com/alexisvisco/dig/internal/graph"
+)
+
+// Eager is a ProvideOption that makes the constructor eager: it is called
+// by Container.Build, rather than lazily the first time one of the values
+// it produces is needed.
+//
+//	c.Provide(NewDB, dig.Eager())
+//	...
+//	if err := c.Build(); err != nil {
+//	  log.Fatal(err)
+//	}
+//
+// This allows wiring errors and panics of critical singletons to surface at
+// startup rather than on first use.
+func Eager() ProvideOption {
+	return provideEagerOption{}
+}
+
+type provideEagerOption struct{}
+
+func (provideEagerOption) String() string {
+	return "Eager()"
+}
+
+func (provideEagerOption) applyProvideOption(opts *provideOptions) {
+	opts.Eager = true
+}
+
+// EagerAll is an Option that makes all the constructors provided to the
+// container, and to any of its Scopes, eager. See Eager.
+func EagerAll() Option {
+	return eagerAllOption{}
+}
+
+type eagerAllOption struct{}
+
+func (eagerAllOption) String() string {
+	return "EagerAll()"
+}
+
+func (eagerAllOption) applyOption(c *Container) {
+	c.scope.state.eagerAll = true
+}
+
+// Build calls the eager constructors provided to the container and to any
+// of its Scopes, in the order in which they were provided across all the
+// Scopes, along with their dependencies. Transient constructors are never
+// called by Build. Constructors that were already called are not called
+// again, so Build may be called again after providing more eager
+// constructors.
+//
+// Build stops at the first constructor that fails, and returns its error.
+func (c *Container) Build() error {
+	type eagerNode struct {
+		s *Scope
+		n *constructorNode
+	}
+
+	var nodes []eagerNode
+	eagerAll := c.scope.state.eagerAll
+	for _, s := range c.scope.appendSubscopes(nil) {
+		for _, n := range s.nodes {
+			if !n.transient && (n.eager || eagerAll) {
+				nodes = append(nodes, eagerNode{s: s, n: n})
+			}
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].n.seq < nodes[j].n.seq
+	})
+
+	for _, en := range nodes {
+		if err := en.s.buildEager(en.n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildEager calls a constructor provided to the Scope.
+func (s *Scope) buildEager(n *constructorNode) error {
+	return s.parallelism().do(func() error {
+		if !s.isVerifiedAcyclic {
+			if ok, cycle := graph.IsAcyclic(s.gh); !ok {
+				return newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(cycle))
+			}
+			s.isVerifiedAcyclic = true
+		}
+		return n.Call(n.OrigScope())
+	})
+}
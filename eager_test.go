@@ -0,0 +1,145 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEager(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("eager constructors are called by Build", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			calls = append(calls, "A")
+			return &A{}
+		})
+		c.RequireProvide(func(*A) *B {
+			calls = append(calls, "B")
+			return &B{}
+		}, dig.Eager())
+		c.RequireProvide(func() string {
+			calls = append(calls, "string")
+			return ""
+		})
+		assert.Empty(t, calls, "constructors must not be called on Provide")
+
+		require.NoError(t, c.Build())
+		assert.Equal(t, []string{"A", "B"}, calls)
+
+		require.NoError(t, c.Build())
+		c.RequireInvoke(func(*B) {})
+		assert.Equal(t, []string{"A", "B"}, calls, "constructors must be called once")
+	})
+
+	t.Run("EagerAll", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t, dig.EagerAll())
+		c.RequireProvide(func() *A {
+			calls = append(calls, "A")
+			return &A{}
+		})
+		child := c.Scope("child")
+		child.RequireProvide(func() *B {
+			calls = append(calls, "B")
+			return &B{}
+		})
+
+		require.NoError(t, c.Build())
+		assert.Equal(t, []string{"A", "B"}, calls)
+	})
+
+	t.Run("provide order across Scopes", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() *B {
+			calls = append(calls, "B")
+			return &B{}
+		}, dig.Eager())
+		c.RequireProvide(func() *A {
+			calls = append(calls, "A")
+			return &A{}
+		}, dig.Eager())
+
+		require.NoError(t, c.Build())
+		assert.Equal(t, []string{"B", "A"}, calls)
+	})
+
+	t.Run("errors surface at Build", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, error) {
+			return nil, errors.New("great sadness")
+		}, dig.Eager())
+
+		err := c.Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("missing dependencies surface at Build", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*A) *B { return &B{} }, dig.Eager())
+
+		err := c.Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("panics surface at Build", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.RecoverFromPanics())
+		c.RequireProvide(func() *A { panic("great sadness") }, dig.Eager())
+
+		err := c.Build()
+		require.Error(t, err)
+		var pe dig.PanicError
+		assert.True(t, errors.As(err, &pe))
+	})
+}
+
+func TestEagerString(t *testing.T) {
+	assert.Equal(t, "Eager()", fmt.Sprint(dig.Eager()))
+	assert.Equal(t, "EagerAll()", fmt.Sprint(dig.EagerAll()))
+}
@@ -25,7 +25,9 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"runtime/debug"
 	"sort"
+	"strings"
 
 	"github.com/alexisvisco/dig/internal/digreflect"
 	"github.com/alexisvisco/dig/internal/dot"
@@ -56,12 +58,14 @@ type digError interface {
 	fmt.Formatter
 }
 
-// A PanicError occurs when a panic occurs while running functions given to the container
-// with the [RecoverFromPanic] option being set. It contains the panic message from the
-// original panic. A PanicError does not wrap other errors, and it does not implement
-// dig.Error, meaning it will be returned from [RootCause]. With the [RecoverFromPanic]
-// option set, a panic can be distinguished from dig errors and errors from provided/
-// invoked/decorated functions like so:
+// A PanicError occurs when a panic occurs while running a constructor, or
+// other functions given to the container with the [RecoverFromPanic] option
+// being set. It contains the panic message from the original panic, and the
+// stack trace of the goroutine that panicked. A PanicError does not wrap
+// other errors, and it does not implement dig.Error, meaning it will be
+// returned from [RootCause]. With the [RecoverFromPanic] option set, a panic
+// can be distinguished from dig errors and errors from provided/invoked/
+// decorated functions like so:
 //
 //	rootCause := dig.RootCause(err)
 //
@@ -88,8 +92,36 @@ type PanicError struct {
 	// The function the panic occurred at
 	fn *digreflect.Func
 
+	// Whether fn is a constructor.
+	constructor bool
+
 	// The panic that was returned from recover()
 	Panic any
+
+	// Stack is the stack trace of the goroutine that panicked, as formatted
+	// by [runtime/debug.Stack].
+	Stack []byte
+}
+
+// ErrConstructorPanicked is matched by errors.Is in the errors returned when
+// a constructor panics. Use errors.As to get the [PanicError] holding the
+// panic and its stack trace.
+//
+//	var pe dig.PanicError
+//	if errors.Is(err, dig.ErrConstructorPanicked) && errors.As(err, &pe) {
+//		log.Printf("constructor panicked: %v\n%s", pe.Panic, pe.Stack)
+//	}
+var ErrConstructorPanicked = errors.New("constructor panicked")
+
+// newPanicError builds a PanicError for the panic p recovered while fn was
+// running. It must be called by the deferred function that recovered p.
+func newPanicError(fn *digreflect.Func, constructor bool, p any) PanicError {
+	stack := debug.Stack()
+	if gp, ok := p.(goroutinePanic); ok {
+		// Raised again after a panic on another goroutine.
+		p, stack = gp.Value, gp.Stack
+	}
+	return PanicError{fn: fn, constructor: constructor, Panic: p, Stack: stack}
 }
 
 // Format will format the PanicError, expanding the corresponding function if in +v mode.
@@ -105,6 +137,12 @@ func (e PanicError) Error() string {
 	return fmt.Sprint(e)
 }
 
+// Is reports whether target is ErrConstructorPanicked when the panic
+// occurred in a constructor.
+func (e PanicError) Is(target error) bool {
+	return e.constructor && target == ErrConstructorPanicked
+}
+
 // formatError will call a dig.Error's writeMessage() method to print the error message
 // and then will automatically attempt to print errors wrapped underneath (which can create
 // a recursive effect if the wrapped error's Format() method then points back to this function).
@@ -257,6 +295,42 @@ func (e errArgumentsFailed) Format(w fmt.State, c rune) {
 	formatError(e, w, c)
 }
 
+// errDependencyFailures is returned when several fields of a dig.In
+// argument of a function given to Invoke could not be built. errors.Is and
+// errors.As look for errors in all of them.
+type errDependencyFailures []error // inv: len > 1
+
+var _ digError = errDependencyFailures(nil)
+
+func (e errDependencyFailures) Error() string { return fmt.Sprint(e) }
+
+func (e errDependencyFailures) Unwrap() []error { return e }
+
+func (e errDependencyFailures) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "%d dependencies failed:", len(e))
+	for i, err := range e {
+		switch {
+		case verb == "%+v":
+			io.WriteString(w, "\n\t- ")
+		case i > 0:
+			io.WriteString(w, "; ")
+		default:
+			io.WriteString(w, " ")
+		}
+		fmt.Fprintf(w, verb, err)
+	}
+}
+
+func (e errDependencyFailures) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+func (e errDependencyFailures) updateGraph(g *dot.Graph) {
+	for _, err := range e {
+		visualizeChain(g, err)
+	}
+}
+
 // errMissingDependencies is returned when the dependencies of a function are
 // not available in the container.
 type errMissingDependencies struct {
@@ -437,6 +511,14 @@ func newErrMissingTypes(c containerStore, k key) errMissingTypes {
 	}
 
 	knownTypes := c.knownTypes()
+
+	// Maybe we have a type of the same name from another package.
+	for _, t := range knownTypes {
+		if isSameNameOtherPackage(t, k.t) {
+			suggestions = append(suggestions, t)
+		}
+	}
+
 	if k.t.Kind() == reflect.Interface {
 		// Maybe we have an implementation of the interface.
 		for _, t := range knownTypes {
@@ -460,16 +542,44 @@ func newErrMissingTypes(c containerStore, k key) errMissingTypes {
 	sort.Sort(byTypeName(suggestions))
 
 	mt := missingType{Key: k}
-	for _, t := range suggestions {
+	for i, t := range suggestions {
+		if i > 0 && suggestions[i-1] == t {
+			// Suggested for more than one reason.
+			continue
+		}
 		if len(c.getValueProviders(k.name, t)) > 0 {
-			k.t = t
-			mt.suggestions = append(mt.suggestions, k)
+			sug := k
+			sug.t = t
+			mt.suggestions = append(mt.suggestions, sug)
+		}
+	}
+
+	// Maybe we have the same type under another name.
+	if k.group == "" {
+		for _, name := range c.knownNames(k.t) {
+			if name != k.name {
+				sug := k
+				sug.name = name
+				mt.suggestions = append(mt.suggestions, sug)
+			}
 		}
 	}
 
 	return errMissingTypes{mt}
 }
 
+// isSameNameOtherPackage reports whether t and u, or the types they point
+// to, have the same name but are defined in different packages.
+func isSameNameOtherPackage(t, u reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if u.Kind() == reflect.Ptr {
+		u = u.Elem()
+	}
+	return t.Name() != "" && t.Name() == u.Name() && t.PkgPath() != u.PkgPath()
+}
+
 func (e errMissingTypes) Error() string { return fmt.Sprint(e) }
 
 func (e errMissingTypes) writeMessage(w io.Writer, v string) {
@@ -520,8 +630,224 @@ func (e errMissingTypes) updateGraph(g *dot.Graph) {
 		}
 	}
 	g.AddMissingNodes(missing)
+
+	for i, mt := range e {
+		suggested := make([]*dot.Result, len(mt.suggestions))
+		for j, sug := range mt.suggestions {
+			suggested[j] = &dot.Result{
+				Node: &dot.Node{
+					Name:  sug.name,
+					Group: sug.group,
+					Type:  sug.t,
+				},
+			}
+		}
+		g.AddSuggestions(missing[i], suggested)
+	}
 }
 
 type errVisualizer interface {
 	updateGraph(*dot.Graph)
 }
+
+// Key identifies a value of the container: its type, along with its name or
+// the value group it belongs to, if any.
+type Key struct {
+	Type  reflect.Type
+	Name  string
+	Group string
+}
+
+func newKey(k key) Key {
+	return Key{Type: k.t, Name: k.name, Group: k.group}
+}
+
+func (k Key) String() string {
+	return key{t: k.Type, name: k.Name, group: k.Group}.String()
+}
+
+// funcName returns the name of the function in the format:
+// <package_name>.<function_name>
+func funcName(f *digreflect.Func) string {
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v.%v", f.Package, f.Name)
+}
+
+// ErrMissingDependency describes a value that a function depends on but
+// that is not provided to the container. Use errors.As to retrieve it from
+// the errors returned by Dig:
+//
+//	var missing dig.ErrMissingDependency
+//	if errors.As(err, &missing) {
+//	  log.Printf("%v needs %v", missing.RequestedBy, missing.Key)
+//	}
+//
+// When multiple values are missing, it describes the first of them.
+type ErrMissingDependency struct {
+	// Key of the missing value.
+	Key Key
+
+	// RequestedBy is the name of the function depending on the missing
+	// value, in the format <package_name>.<function_name>, if known.
+	RequestedBy string
+
+	// Suggestions lists values that are provided to the container, which
+	// the function may have meant to depend on instead.
+	Suggestions []Key
+}
+
+func (e ErrMissingDependency) Error() string {
+	if e.RequestedBy == "" {
+		return fmt.Sprintf("missing dependency %v", e.Key)
+	}
+	return fmt.Sprintf("missing dependency %v of %v", e.Key, e.RequestedBy)
+}
+
+func (e errMissingTypes) As(target interface{}) bool {
+	t, ok := target.(*ErrMissingDependency)
+	if !ok {
+		return false
+	}
+	*t = e[0].export()
+	return true
+}
+
+func (e errMissingDependencies) As(target interface{}) bool {
+	t, ok := target.(*ErrMissingDependency)
+	if !ok {
+		return false
+	}
+	var missing errMissingTypes
+	if !errors.As(e.Reason, &missing) {
+		return false
+	}
+	*t = missing[0].export()
+	t.RequestedBy = funcName(e.Func)
+	return true
+}
+
+func (mt missingType) export() ErrMissingDependency {
+	e := ErrMissingDependency{Key: newKey(mt.Key)}
+	for _, k := range mt.suggestions {
+		e.Suggestions = append(e.Suggestions, newKey(k))
+	}
+	return e
+}
+
+// ErrCycleDetected describes a cycle in the dependency graph of the
+// container. Use errors.As to retrieve it from the errors returned by Dig.
+type ErrCycleDetected struct {
+	// Path lists the constructors of the cycle, each of them depending on
+	// a value produced by the next one. The last constructor is the first
+	// one.
+	Path []CycleStep
+}
+
+// CycleStep is a constructor of a cycle in the dependency graph.
+type CycleStep struct {
+	// Type of the constructor.
+	Type reflect.Type
+
+	// Func is the name of the constructor, in the format
+	// <package_name>.<function_name>.
+	Func string
+}
+
+func (e ErrCycleDetected) Error() string {
+	steps := make([]string, len(e.Path))
+	for i, s := range e.Path {
+		steps[i] = s.Func
+	}
+	return "cycle detected: " + strings.Join(steps, " -> ")
+}
+
+func (e errCycleDetected) As(target interface{}) bool {
+	t, ok := target.(*ErrCycleDetected)
+	if !ok {
+		return false
+	}
+	t.Path = make([]CycleStep, len(e.Path))
+	for i, entry := range e.Path {
+		t.Path[i] = CycleStep{Type: entry.Key.t, Func: funcName(entry.Func)}
+	}
+	return true
+}
+
+// ErrConstructorFailed describes a constructor that returned an error. Use
+// errors.As to retrieve it from the errors returned by Dig.
+type ErrConstructorFailed struct {
+	// Func is the name of the constructor, in the format
+	// <package_name>.<function_name>.
+	Func string
+
+	// Err is the error returned by the constructor.
+	Err error
+}
+
+func (e ErrConstructorFailed) Error() string {
+	return fmt.Sprintf("constructor %v failed: %v", e.Func, e.Err)
+}
+
+func (e ErrConstructorFailed) Unwrap() error { return e.Err }
+
+func (e errConstructorFailed) As(target interface{}) bool {
+	t, ok := target.(*ErrConstructorFailed)
+	if !ok {
+		return false
+	}
+	*t = ErrConstructorFailed{Func: funcName(e.Func), Err: e.Reason}
+	return true
+}
+
+// ResolutionError is implemented by the errors returned by Dig when a value
+// could not be built. Use errors.As to retrieve it:
+//
+//	var re dig.ResolutionError
+//	if errors.As(err, &re) {
+//	  log.Printf("failed to build %v", re.Path())
+//	}
+type ResolutionError interface {
+	error
+
+	// Path returns the keys of the values that were being built when the
+	// error occurred, starting from the dependency of the function that
+	// was called and leading to the value that failed to build. If the
+	// value failed because of a missing dependency, the path ends with
+	// the first missing key.
+	Path() []Key
+}
+
+var (
+	_ ResolutionError = errArgumentsFailed{}
+	_ ResolutionError = errMissingDependencies{}
+	_ ResolutionError = errParamSingleFailed{}
+	_ ResolutionError = errParamGroupFailed{}
+)
+
+func (e errArgumentsFailed) Path() []Key { return resolutionPath(e) }
+
+func (e errMissingDependencies) Path() []Key { return resolutionPath(e) }
+
+func (e errParamSingleFailed) Path() []Key { return resolutionPath(e) }
+
+func (e errParamGroupFailed) Path() []Key { return resolutionPath(e) }
+
+// resolutionPath collects the keys of the values that failed to build along
+// the chain of errors.
+func resolutionPath(err error) []Key {
+	var path []Key
+	for err != nil {
+		switch e := err.(type) {
+		case errParamSingleFailed:
+			path = append(path, newKey(e.Key))
+		case errParamGroupFailed:
+			path = append(path, newKey(e.Key))
+		case errMissingTypes:
+			return append(path, newKey(e[0].Key))
+		}
+		err = errors.Unwrap(err)
+	}
+	return path
+}
@@ -32,6 +32,7 @@ import (
 
 	"github.com/alexisvisco/dig/internal/digreflect"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // assertErrorMatches matches error messages against the provided list of
@@ -570,3 +571,144 @@ func TestErrorFormatting(t *testing.T) {
 		})
 	}
 }
+
+func TestExportedErrors(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("missing dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := New()
+		assert.NoError(t, c.Provide(func() A { return A{} }))
+		assert.NoError(t, c.Provide(func(*A) B { return B{} }))
+
+		err := c.Invoke(func(B) {})
+		var missing ErrMissingDependency
+		if assert.True(t, errors.As(err, &missing)) {
+			assert.Equal(t, Key{Type: reflect.TypeOf(&A{})}, missing.Key)
+			assert.Contains(t, missing.RequestedBy, "TestExportedErrors")
+			assert.Equal(t, []Key{{Type: reflect.TypeOf(A{})}}, missing.Suggestions)
+			assert.Contains(t, missing.Error(), "missing dependency *dig.A of ")
+		}
+	})
+
+	t.Run("missing named dependency", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			In
+
+			A A `name:"foo"`
+		}
+
+		c := New()
+		err := c.Invoke(func(in) {})
+		var missing ErrMissingDependency
+		if assert.True(t, errors.As(err, &missing)) {
+			assert.Equal(t, Key{Type: reflect.TypeOf(A{}), Name: "foo"}, missing.Key)
+			assert.Empty(t, missing.Suggestions)
+			assert.Equal(t, `dig.A[name="foo"]`, missing.Key.String())
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		t.Parallel()
+
+		c := New(DeferAcyclicVerification())
+		assert.NoError(t, c.Provide(func(B) A { return A{} }))
+		assert.NoError(t, c.Provide(func(A) B { return B{} }))
+
+		err := c.Invoke(func(A) {})
+		var cycle ErrCycleDetected
+		if assert.True(t, errors.As(err, &cycle)) {
+			assert.Len(t, cycle.Path, 3)
+			assert.Equal(t, cycle.Path[0], cycle.Path[2])
+			assert.Equal(t, reflect.TypeOf(func(B) A { return A{} }), cycle.Path[0].Type)
+			assert.Contains(t, cycle.Path[0].Func, "TestExportedErrors")
+			assert.Contains(t, cycle.Error(), "cycle detected: github.com/alexisvisco/dig.TestExportedErrors")
+		}
+	})
+
+	t.Run("constructor failed", func(t *testing.T) {
+		t.Parallel()
+
+		sadness := errors.New("great sadness")
+		c := New()
+		assert.NoError(t, c.Provide(func() (A, error) { return A{}, sadness }))
+
+		err := c.Invoke(func(A) {})
+		var failed ErrConstructorFailed
+		if assert.True(t, errors.As(err, &failed)) {
+			assert.Contains(t, failed.Func, "TestExportedErrors")
+			assert.Equal(t, sadness, failed.Err)
+			assert.ErrorIs(t, failed, sadness)
+		}
+
+		var missing ErrMissingDependency
+		assert.False(t, errors.As(err, &missing))
+	})
+}
+
+func TestResolutionErrorPath(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	t.Run("failing constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := New()
+		require.NoError(t, c.Provide(func() (A, error) { return A{}, errors.New("great sadness") }))
+		require.NoError(t, c.Provide(func(A) B { return B{} }, Group("bs")))
+		require.NoError(t, c.Provide(func(struct {
+			In
+
+			Bs []B `group:"bs"`
+		}) C {
+			return C{}
+		}, Name("c")))
+
+		err := c.Invoke(func(struct {
+			In
+
+			C C `name:"c"`
+		}) {
+		})
+		var re ResolutionError
+		require.True(t, errors.As(err, &re))
+		assert.Equal(t, []Key{
+			{Type: reflect.TypeOf(C{}), Name: "c"},
+			{Type: reflect.TypeOf(B{}), Group: "bs"},
+			{Type: reflect.TypeOf(A{})},
+		}, re.Path())
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := New()
+		require.NoError(t, c.Provide(func(A) B { return B{} }))
+
+		err := c.Invoke(func(B) {})
+		var re ResolutionError
+		require.True(t, errors.As(err, &re))
+		assert.Equal(t, []Key{
+			{Type: reflect.TypeOf(B{})},
+			{Type: reflect.TypeOf(A{})},
+		}, re.Path())
+	})
+
+	t.Run("missing direct dependency", func(t *testing.T) {
+		t.Parallel()
+
+		err := New().Invoke(func(A) {})
+		var re ResolutionError
+		require.True(t, errors.As(err, &re))
+		assert.Equal(t, []Key{{Type: reflect.TypeOf(A{})}}, re.Path())
+	})
+}
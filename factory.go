@@ -0,0 +1,176 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// Factory is a ProvideOption that makes the constructor a template for
+// factories of its result, rather than a constructor of it. A parameter of
+// type func(K1, K2, ...) (T, error), where T is the result of the template,
+// then receives a function that calls the template each time it is called:
+// the parameters of the template of types K1, K2, ... receive the arguments
+// of the factory, and the others are resolved from the container as usual.
+//
+//	c.Provide(func(tenantID string, pool *Pool) (*TenantDB, error) {
+//	  return pool.Open(tenantID)
+//	}, dig.Factory())
+//
+//	c.Provide(func(newDB func(tenantID string) (*TenantDB, error)) *Router {
+//	  return &Router{newDB: newDB}
+//	})
+//
+// The arguments of the factory must have distinct types, each matching an
+// unnamed parameter of the template, possibly a field of a dig.In struct.
+// The template may return T, or T and an error, and must not return a
+// dig.Out struct. A new value is built each time the factory is called; it
+// is not added to the container.
+//
+// The dependencies of the template are built, and checked to be provided,
+// when the factory is called, from the Scope the template was provided to.
+// The factory fails with the error of the template, or the error that
+// prevented its dependencies from being built. Factories may be called
+// concurrently only if the container is safe for concurrent use.
+//
+// Factory cannot be combined with options that change the values provided
+// by the constructor, such as Name, Group or As, nor with options that
+// change how it is called, such as Eager, Transient or Retry.
+func Factory() ProvideOption {
+	return provideFactoryOption{}
+}
+
+type provideFactoryOption struct{}
+
+func (provideFactoryOption) String() string {
+	return "Factory()"
+}
+
+func (provideFactoryOption) applyProvideOption(opts *provideOptions) {
+	opts.Factory = true
+}
+
+// factoryTemplate is a constructor provided with Factory.
+type factoryTemplate struct {
+	ctor     interface{}
+	location *digreflect.Func
+	params   paramList
+}
+
+// factoryArgsOption is the InvokeOption with which factories invoke their
+// template.
+type factoryArgsOption map[reflect.Type]reflect.Value
+
+func (o factoryArgsOption) applyInvokeOption(opts *invokeOptions) {
+	opts.factoryArgs = o
+}
+
+func (s *Scope) provideFactory(ctor interface{}, opts provideOptions) error {
+	ctype := reflect.TypeOf(ctor)
+	numOut := ctype.NumOut()
+	if numOut == 0 || numOut > 2 || (numOut == 2 && !isError(ctype.Out(1))) ||
+		isError(ctype.Out(0)) || IsOut(reflect.Zero(ctype.Out(0)).Interface()) {
+		return newErrInvalidInput(fmt.Sprintf(
+			"factory templates must return a value and an optional error, got %v", ctype), nil)
+	}
+
+	params, err := newParamList(ctype, s)
+	if err != nil {
+		return err
+	}
+
+	t := ctype.Out(0)
+	if f, ok := s.factories[t]; ok {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot provide factory template of %v: already provided by %v", t, f.location), nil)
+	}
+
+	location := opts.Location
+	if location == nil {
+		location = digreflect.InspectFunc(ctor)
+	}
+	s.factories[t] = &factoryTemplate{ctor: ctor, location: location, params: params}
+	return nil
+}
+
+// factory returns the template of the factory ps, and the Scope it was
+// provided to, if ps has the type of a factory of the result of a template
+// provided to c or its ancestors.
+func (ps paramSingle) factory(c containerStore) (*factoryTemplate, *Scope) {
+	s, ok := c.(*Scope)
+	t := ps.Type
+	if !ok || ps.Name != "" || t.Kind() != reflect.Func || t.NumOut() != 2 || t.Out(1) != _errType {
+		return nil, nil
+	}
+
+	for _, s := range s.ancestors() {
+		if f, ok := s.factories[t.Out(0)]; ok {
+			return f, s
+		}
+	}
+	return nil, nil
+}
+
+// build returns a factory of type ft that calls f in the Scope s.
+func (f *factoryTemplate) build(s *Scope, ft reflect.Type) (reflect.Value, error) {
+	if ft.IsVariadic() {
+		return _noValue, newErrInvalidInput(
+			fmt.Sprintf("factory %v of %v cannot be variadic", ft, f.location), nil)
+	}
+
+	args := make(map[reflect.Type]reflect.Value, ft.NumIn())
+	for i := 0; i < ft.NumIn(); i++ {
+		t := ft.In(i)
+		if _, ok := args[t]; ok {
+			return _noValue, newErrInvalidInput(
+				fmt.Sprintf("factory %v of %v has more than one argument of type %v", ft, f.location, t), nil)
+		}
+		args[t] = reflect.Zero(t)
+	}
+	_, used := f.params.supply(args)
+	for i := 0; i < ft.NumIn(); i++ {
+		t := ft.In(i)
+		if _, ok := used[t]; !ok {
+			return _noValue, newErrInvalidInput(
+				fmt.Sprintf("argument of type %v of factory %v is not a parameter of %v", t, ft, f.location), nil)
+		}
+	}
+
+	return reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		args := make(factoryArgsOption, len(in))
+		for i, v := range in {
+			args[ft.In(i)] = v
+		}
+
+		var info InvokeInfo
+		result := reflect.New(ft.Out(0)).Elem()
+		errv := reflect.New(_errType).Elem()
+		if err := s.Invoke(f.ctor, FillInvokeInfo(&info), args); err != nil {
+			errv.Set(reflect.ValueOf(err))
+		} else {
+			result.Set(info.ReturnedOutput[0])
+		}
+		return []reflect.Value{result, errv}
+	}), nil
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactory(t *testing.T) {
+	t.Parallel()
+
+	type Pool struct{ Name string }
+	type TenantDB struct {
+		Tenant string
+		Pool   *Pool
+	}
+
+	newTenantDB := func(tenant string, pool *Pool) (*TenantDB, error) {
+		if tenant == "" {
+			return nil, errors.New("empty tenant")
+		}
+		return &TenantDB{Tenant: tenant, Pool: pool}, nil
+	}
+
+	t.Run("arguments and dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		c := digtest.New(t)
+		c.RequireProvide(func() *Pool {
+			calls++
+			return &Pool{Name: "main"}
+		})
+		c.RequireProvide(newTenantDB, dig.Factory())
+
+		c.RequireInvoke(func(newDB func(string) (*TenantDB, error)) {
+			a, err := newDB("a")
+			require.NoError(t, err)
+			assert.Equal(t, &TenantDB{Tenant: "a", Pool: &Pool{Name: "main"}}, a)
+
+			b, err := newDB("b")
+			require.NoError(t, err)
+			assert.Equal(t, "b", b.Tenant)
+			assert.Same(t, a.Pool, b.Pool)
+
+			_, err = newDB("")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "empty tenant")
+		})
+		assert.Equal(t, 1, calls, "dependencies of the template must be built once")
+	})
+
+	t.Run("injected into constructors", func(t *testing.T) {
+		t.Parallel()
+
+		type Router struct {
+			newDB func(string) (*TenantDB, error)
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Pool { return &Pool{} })
+		c.RequireProvide(newTenantDB, dig.Factory())
+		c.RequireProvide(func(newDB func(string) (*TenantDB, error)) *Router {
+			return &Router{newDB: newDB}
+		})
+
+		c.RequireInvoke(func(r *Router) {
+			db, err := r.newDB("a")
+			require.NoError(t, err)
+			assert.Equal(t, "a", db.Tenant)
+		})
+	})
+
+	t.Run("dig.In templates", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Tenant string
+			ID     int
+			Pool   *Pool `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(p params) *TenantDB {
+			return &TenantDB{Tenant: fmt.Sprint(p.Tenant, p.ID), Pool: p.Pool}
+		}, dig.Factory())
+
+		c.RequireInvoke(func(newDB func(int, string) (*TenantDB, error)) {
+			db, err := newDB(1, "a")
+			require.NoError(t, err)
+			assert.Equal(t, &TenantDB{Tenant: "a1"}, db)
+		})
+	})
+
+	t.Run("missing dependencies are reported when called", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newTenantDB, dig.Factory())
+
+		c.RequireInvoke(func(newDB func(string) (*TenantDB, error)) {
+			_, err := newDB("a")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "missing type: *dig_test.Pool")
+		})
+	})
+
+	t.Run("child scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Pool { return &Pool{Name: "root"} })
+		c.RequireProvide(newTenantDB, dig.Factory())
+
+		child := c.Scope("child")
+		child.RequireInvoke(func(newDB func(string) (*TenantDB, error)) {
+			db, err := newDB("a")
+			require.NoError(t, err)
+			assert.Equal(t, "root", db.Pool.Name)
+		})
+	})
+
+	t.Run("provided functions take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newTenantDB, dig.Factory())
+		c.RequireProvide(func() func(string) (*TenantDB, error) {
+			return func(tenant string) (*TenantDB, error) {
+				return &TenantDB{Tenant: "provided"}, nil
+			}
+		})
+
+		c.RequireInvoke(func(newDB func(string) (*TenantDB, error)) {
+			db, err := newDB("a")
+			require.NoError(t, err)
+			assert.Equal(t, "provided", db.Tenant)
+		})
+	})
+
+	t.Run("template is not a constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Pool { return &Pool{} })
+		c.RequireProvide(newTenantDB, dig.Factory())
+
+		err := c.Invoke(func(*TenantDB) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.TenantDB")
+	})
+
+	t.Run("invalid factories", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			wantErr string
+		}{
+			{
+				desc:    "unknown argument",
+				give:    func(func(int) (*TenantDB, error)) {},
+				wantErr: "argument of type int of factory func(int) (*dig_test.TenantDB, error) is not a parameter of",
+			},
+			{
+				desc:    "duplicate argument",
+				give:    func(func(string, string) (*TenantDB, error)) {},
+				wantErr: "has more than one argument of type string",
+			},
+			{
+				desc:    "variadic",
+				give:    func(func(...string) (*TenantDB, error)) {},
+				wantErr: "cannot be variadic",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				c := digtest.New(t)
+				c.RequireProvide(func() *Pool { return &Pool{} })
+				c.RequireProvide(newTenantDB, dig.Factory())
+
+				err := c.Invoke(tt.give)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			opts    []dig.ProvideOption
+			wantErr string
+		}{
+			{
+				desc:    "no results",
+				give:    func(string) {},
+				wantErr: "factory templates must return a value and an optional error, got func(string)",
+			},
+			{
+				desc:    "two values",
+				give:    func(string) (*Pool, *TenantDB) { return nil, nil },
+				wantErr: "factory templates must return a value and an optional error",
+			},
+			{
+				desc: "dig.Out",
+				give: func(string) struct {
+					dig.Out
+
+					Pool *Pool
+				} {
+					panic("unreachable")
+				},
+				wantErr: "factory templates must return a value and an optional error",
+			},
+			{
+				desc:    "named",
+				give:    newTenantDB,
+				opts:    []dig.ProvideOption{dig.Name("db")},
+				wantErr: "cannot use Factory with named values or value groups",
+			},
+			{
+				desc:    "transient",
+				give:    newTenantDB,
+				opts:    []dig.ProvideOption{dig.Transient()},
+				wantErr: "cannot use Factory with Eager or Transient",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Provide(tt.give, append(tt.opts, dig.Factory())...)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("provided twice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newTenantDB, dig.Factory())
+		err := c.Provide(newTenantDB, dig.Factory())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot provide factory template of *dig_test.TenantDB: already provided by")
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "Factory()", fmt.Sprint(dig.Factory()))
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package fxcompat
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alexisvisco/dig"
+)
+
+// Annotated annotates a constructor given to Provide, or a value given to
+// Supply, with a name or a value group, like fx.Annotated. Name and Group
+// are mutually exclusive.
+//
+//	fx.Provide(fx.Annotated{Name: "primary", Target: NewDB})
+type Annotated struct {
+	// If set, the values are provided with this name.
+	Name string
+
+	// If set, the values are provided to this value group. The group may
+	// be followed by options, like "handlers,flatten".
+	Group string
+
+	// Target is the constructor or value being annotated.
+	Target interface{}
+}
+
+func (an Annotated) String() string {
+	var fields []string
+	if an.Name != "" {
+		fields = append(fields, fmt.Sprintf("Name: %q", an.Name))
+	}
+	if an.Group != "" {
+		fields = append(fields, fmt.Sprintf("Group: %q", an.Group))
+	}
+	fields = append(fields, fmt.Sprintf("Target: %v", describe1(an.Target)))
+	return fmt.Sprintf("fx.Annotated{%v}", strings.Join(fields, ", "))
+}
+
+func (an Annotated) provideOptions() []interface{} {
+	var opts []interface{}
+	if an.Name != "" {
+		opts = append(opts, dig.Name(an.Name))
+	}
+	if an.Group != "" {
+		opts = append(opts, dig.Group(an.Group))
+	}
+	return opts
+}
+
+// An Annotation changes how a function given to Annotate is wired. The
+// Annotations of dig, like dig.Variadic and dig.KeyedParam, may be given to
+// Annotate as well.
+type Annotation = dig.Annotation
+
+// Annotate annotates the function f, like fx.Annotate. The result may be
+// given to Provide, Decorate or Invoke.
+//
+//	fx.Provide(fx.Annotate(NewServer,
+//	  fx.ParamTags(`name:"addr"`, dig.Select("tier=hot")),
+//	  fx.ResultTags(`name:"public"`),
+//	))
+func Annotate(f interface{}, anns ...Annotation) interface{} {
+	return annotated{Target: f, fn: dig.Annotate(f, anns...)}
+}
+
+// annotated is a function annotated with Annotate.
+type annotated struct {
+	Target interface{}
+
+	// fn is the result of dig.Annotate for Target.
+	fn interface{}
+}
+
+func (a annotated) String() string {
+	return fmt.Sprintf("fx.Annotate(%v)", describe1(a.Target))
+}
+
+// fxAnnotation is an Annotation of dig standing for an annotation of fx.
+type fxAnnotation struct {
+	dig.Annotation
+
+	desc string
+}
+
+func (ann fxAnnotation) String() string { return ann.desc }
+
+// ParamTags sets the struct tags of the parameters of the annotated
+// function, in order, like fx.ParamTags. Parameters past the given tags
+// are left untagged.
+func ParamTags(tags ...string) Annotation {
+	return fxAnnotation{
+		Annotation: dig.ParamTags(tags...),
+		desc:       fmt.Sprintf("fx.ParamTags(%q)", tags),
+	}
+}
+
+// ResultTags sets the struct tags of the results of the annotated
+// function, in order, like fx.ResultTags. A trailing error result is not
+// tagged.
+func ResultTags(tags ...string) Annotation {
+	return fxAnnotation{
+		Annotation: dig.ResultTags(tags...),
+		desc:       fmt.Sprintf("fx.ResultTags(%q)", tags),
+	}
+}
+
+// As provides the results of the annotated function as the given
+// interfaces, instead of their own types, like fx.As. The nth interface
+// applies to the nth result, and each interface must be given as a pointer,
+// or as Self to keep the type of the result. As may be given several times
+// to provide the results as several types.
+//
+//	fx.Annotate(NewBuffer, fx.As(new(io.Reader)), fx.As(new(io.Writer)))
+func As(interfaces ...interface{}) Annotation {
+	types := make([]string, len(interfaces))
+	for i, iface := range interfaces {
+		if iface == nil {
+			types[i] = "fx.Self()"
+		} else {
+			types[i] = fmt.Sprint(reflect.TypeOf(iface))
+		}
+	}
+	return fxAnnotation{
+		Annotation: dig.ResultAs(interfaces...),
+		desc:       fmt.Sprintf("fx.As(%v)", strings.Join(types, ", ")),
+	}
+}
+
+// Self may be given to As to also provide a result as its own type, like
+// fx.Self.
+func Self() interface{} {
+	return nil
+}
+
+// constructor returns the constructor to provide for t, given to Provide,
+// along with the options to provide it with.
+func constructor(t interface{}) (interface{}, []dig.ProvideOption, error) {
+	an, ok := t.(Annotated)
+	if !ok {
+		return function(t), nil, nil
+	}
+
+	if an.Name != "" && an.Group != "" {
+		return nil, nil, errors.New("cannot use both Name and Group")
+	}
+	var opts []dig.ProvideOption
+	for _, opt := range an.provideOptions() {
+		opts = append(opts, opt.(dig.ProvideOption))
+	}
+	return function(an.Target), opts, nil
+}
+
+// function returns the function to give to dig for t, which may have been
+// annotated with Annotate.
+func function(t interface{}) interface{} {
+	if a, ok := t.(annotated); ok {
+		return a.fn
+	}
+	return t
+}
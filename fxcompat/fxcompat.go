@@ -0,0 +1,310 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package fxcompat applies fx-style wiring declarations to a dig container.
+//
+// It mirrors the part of the API of go.uber.org/fx that declares how an
+// application is wired, so that services can move off fx onto plain dig by
+// changing an import rather than rewriting their declarations:
+//
+//	import fx "github.com/alexisvisco/dig/fxcompat"
+//
+//	var Module = fx.Module("server",
+//	  fx.Provide(
+//	    NewHandler,
+//	    fx.Annotate(NewServer, fx.ParamTags(`name:"addr"`), fx.As(new(Runner))),
+//	  ),
+//	  fx.Supply(fx.Annotated{Name: "addr", Target: ":8080"}),
+//	  fx.Invoke(func(Runner) {}),
+//	)
+//
+//	err := fx.Apply(dig.New(), Module)
+//
+// As with fx, constructors are provided before decorators are registered,
+// and functions given to Invoke run last, in order. Lifecycle management,
+// logging and other application features of fx are not covered: see the
+// lifecycle support of dig for an alternative.
+package fxcompat
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/alexisvisco/dig"
+)
+
+// In can be embedded into a struct to mark it as a parameter struct, like
+// fx.In. It is dig.In.
+type In = dig.In
+
+// Out can be embedded into a struct to mark it as a result struct, like
+// fx.Out. It is dig.Out.
+type Out = dig.Out
+
+// An Option declares part of the wiring of an application, like fx.Option.
+type Option interface {
+	fmt.Stringer
+
+	apply(*app, scope)
+}
+
+// scope is implemented by dig.Container and dig.Scope.
+type scope interface {
+	Provide(interface{}, ...dig.ProvideOption) error
+	Supply(...interface{}) error
+	Decorate(interface{}, ...dig.DecorateOption) error
+	Invoke(interface{}, ...dig.InvokeOption) error
+	Scope(string, ...dig.ScopeOption) *dig.Scope
+}
+
+// Apply applies the given Options to the container. Constructors and values
+// are provided first, in order, then decorators are registered, and
+// finally the functions given to Invoke are called, in order.
+//
+// Apply stops at the first failure and returns its error.
+func Apply(c *dig.Container, opts ...Option) error {
+	var a app
+	for _, opt := range opts {
+		opt.apply(&a, c)
+	}
+	return a.run()
+}
+
+// app holds the declarations of the Options being applied, by phase.
+type app struct {
+	// Whether the Options are applied to the Scope of a Module, whose
+	// constructors must be exported unless they are Private.
+	module bool
+
+	provides  []func() error
+	decorates []func() error
+	invokes   []func() error
+}
+
+func (a *app) run() error {
+	for _, phase := range [][]func() error{a.provides, a.decorates, a.invokes} {
+		for _, op := range phase {
+			if err := op(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Provide declares constructors, like fx.Provide. Each of them may be a
+// function, an Annotated, or the result of Annotate. Constructors provided
+// by a Module are visible to the whole application unless Private is given
+// among them.
+func Provide(constructors ...interface{}) Option {
+	return provideOption{targets: constructors}
+}
+
+type provideOption struct {
+	targets []interface{}
+}
+
+func (o provideOption) String() string {
+	return fmt.Sprintf("fx.Provide(%v)", describe(o.targets))
+}
+
+func (o provideOption) apply(a *app, s scope) {
+	private := false
+	for _, t := range o.targets {
+		if t == Private {
+			private = true
+		}
+	}
+
+	export := a.module && !private
+	for _, t := range o.targets {
+		if t == Private {
+			continue
+		}
+
+		t := t
+		a.provides = append(a.provides, func() error {
+			ctor, opts, err := constructor(t)
+			if err != nil {
+				return fmt.Errorf("cannot provide %v: %w", describe1(t), err)
+			}
+			if export {
+				opts = append(opts, dig.Export(true))
+			}
+			return s.Provide(ctor, opts...)
+		})
+	}
+}
+
+// Private may be given to Provide to make the constructors visible only to
+// the Module they are provided to, like fx.Private.
+var Private = privateAnnotation{}
+
+type privateAnnotation struct{}
+
+func (privateAnnotation) String() string { return "fx.Private" }
+
+// Supply declares values that were already built, like fx.Supply. Each of
+// them may be wrapped in an Annotated to be given a name or a group.
+func Supply(values ...interface{}) Option {
+	return supplyOption{values: values}
+}
+
+type supplyOption struct {
+	values []interface{}
+}
+
+func (o supplyOption) String() string {
+	return fmt.Sprintf("fx.Supply(%v)", describe(o.values))
+}
+
+func (o supplyOption) apply(a *app, s scope) {
+	export := a.module
+	for _, v := range o.values {
+		v := v
+		a.provides = append(a.provides, func() error {
+			var args []interface{}
+			if an, ok := v.(Annotated); ok {
+				args = append(args, an.Target)
+				args = append(args, an.provideOptions()...)
+			} else {
+				args = append(args, v)
+			}
+			if export {
+				args = append(args, dig.Export(true))
+			}
+			return s.Supply(args...)
+		})
+	}
+}
+
+// Decorate declares decorators, like fx.Decorate. Each of them may be a
+// function or the result of Annotate. A decorator given to a Module only
+// affects the values seen by the Module.
+func Decorate(decorators ...interface{}) Option {
+	return decorateOption{targets: decorators}
+}
+
+type decorateOption struct {
+	targets []interface{}
+}
+
+func (o decorateOption) String() string {
+	return fmt.Sprintf("fx.Decorate(%v)", describe(o.targets))
+}
+
+func (o decorateOption) apply(a *app, s scope) {
+	for _, t := range o.targets {
+		t := t
+		a.decorates = append(a.decorates, func() error {
+			return s.Decorate(function(t))
+		})
+	}
+}
+
+// Invoke declares functions to call once the application is wired, like
+// fx.Invoke. Each of them may be a function or the result of Annotate.
+func Invoke(funcs ...interface{}) Option {
+	return invokeOption{targets: funcs}
+}
+
+type invokeOption struct {
+	targets []interface{}
+}
+
+func (o invokeOption) String() string {
+	return fmt.Sprintf("fx.Invoke(%v)", describe(o.targets))
+}
+
+func (o invokeOption) apply(a *app, s scope) {
+	for _, t := range o.targets {
+		t := t
+		a.invokes = append(a.invokes, func() error {
+			return s.Invoke(function(t))
+		})
+	}
+}
+
+// Options groups Options together, like fx.Options.
+func Options(opts ...Option) Option {
+	return optionGroup(opts)
+}
+
+type optionGroup []Option
+
+func (og optionGroup) String() string {
+	return fmt.Sprintf("fx.Options(%v)", describe(og))
+}
+
+func (og optionGroup) apply(a *app, s scope) {
+	for _, opt := range og {
+		opt.apply(a, s)
+	}
+}
+
+// Module groups Options under a name, like fx.Module. The Options are
+// applied to a child Scope named after the Module: constructors it
+// provides are visible to the whole application unless they are Private,
+// and its decorators only affect the Module.
+func Module(name string, opts ...Option) Option {
+	return moduleOption{name: name, opts: opts}
+}
+
+type moduleOption struct {
+	name string
+	opts []Option
+}
+
+func (o moduleOption) String() string {
+	return fmt.Sprintf("fx.Module(%q, %v)", o.name, describe(o.opts))
+}
+
+func (o moduleOption) apply(a *app, s scope) {
+	ms := s.Scope(o.name)
+	module := a.module
+	a.module = true
+	defer func() { a.module = module }()
+	for _, opt := range o.opts {
+		opt.apply(a, ms)
+	}
+}
+
+// describe returns a readable list of the given values.
+func describe[T any](values []T) string {
+	var b strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(describe1(v))
+	}
+	return b.String()
+}
+
+func describe1(v interface{}) string {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Func {
+		if f := runtime.FuncForPC(rv.Pointer()); f != nil {
+			return f.Name() + "()"
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fxcompat_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	fx "github.com/alexisvisco/dig/fxcompat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Config struct{ Addr string }
+
+type Server struct {
+	Addr string
+	Out  io.Writer
+}
+
+func NewServer(addr string, out io.Writer) *Server {
+	return &Server{Addr: addr, Out: out}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	t.Run("provide, supply and invoke", func(t *testing.T) {
+		t.Parallel()
+
+		var got *Server
+		c := dig.New()
+		err := fx.Apply(c,
+			// Invokes run after everything was provided.
+			fx.Invoke(func(s *Server) { got = s }),
+			fx.Provide(
+				fx.Annotate(NewServer, fx.ParamTags(`name:"addr"`)),
+				fx.Annotate(func() *bytes.Buffer { return new(bytes.Buffer) }, fx.As(new(io.Writer))),
+			),
+			fx.Supply(fx.Annotated{Name: "addr", Target: ":8080"}),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, ":8080", got.Addr)
+		assert.IsType(t, new(bytes.Buffer), got.Out)
+
+		assert.Error(t, c.Invoke(func(*bytes.Buffer) {}),
+			"values provided with As must not be provided as their own type")
+	})
+
+	t.Run("result tags and groups", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			fx.In
+
+			Primary string   `name:"primary"`
+			Names   []string `group:"names"`
+		}
+
+		var got params
+		err := fx.Apply(dig.New(),
+			fx.Provide(
+				fx.Annotate(func() (string, string, error) { return "a", "b", nil },
+					fx.ResultTags(`name:"primary"`, `group:"names"`)),
+				fx.Annotated{Group: "names", Target: func() string { return "c" }},
+			),
+			fx.Invoke(func(p params) { got = p }),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "a", got.Primary)
+		assert.ElementsMatch(t, []string{"b", "c"}, got.Names)
+	})
+
+	t.Run("As with Self", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		err := fx.Apply(c, fx.Provide(
+			fx.Annotate(func() *bytes.Buffer { return new(bytes.Buffer) },
+				fx.As(fx.Self()), fx.As(new(io.Reader)), fx.As(new(io.Writer))),
+		))
+		require.NoError(t, err)
+		require.NoError(t, c.Invoke(func(b *bytes.Buffer, r io.Reader, w io.Writer) {
+			assert.Same(t, b, r)
+			assert.Same(t, b, w)
+		}))
+	})
+
+	t.Run("annotations of dig", func(t *testing.T) {
+		t.Parallel()
+
+		type region string
+
+		c := dig.New()
+		require.NoError(t, c.Provide(func() *Config { return &Config{Addr: ":80"} }, dig.Keyed(region("eu"))))
+
+		var got []string
+		err := fx.Apply(c,
+			fx.Provide(
+				fx.Annotated{Group: "names", Target: func() string { return "a" }},
+				fx.Annotated{Group: "names", Target: func() string { return "b" }},
+			),
+			fx.Invoke(fx.Annotate(func(cfg *Config, names ...string) {
+				got = append([]string{cfg.Addr}, names...)
+			}, dig.KeyedParam(0, region("eu")), dig.Variadic("names"))),
+		)
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, ":80", got[0])
+		assert.ElementsMatch(t, []string{"a", "b"}, got[1:])
+	})
+
+	t.Run("errors of annotated functions", func(t *testing.T) {
+		t.Parallel()
+
+		err := fx.Apply(dig.New(),
+			fx.Provide(fx.Annotate(func() (*Config, error) {
+				return nil, errors.New("great sadness")
+			}, fx.ResultTags(`name:"cfg"`))),
+			fx.Invoke(fx.Annotate(func(*Config) {}, fx.ParamTags(`name:"cfg"`))),
+		)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("modules", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		var decorated, outside string
+		err := fx.Apply(c,
+			fx.Module("server",
+				fx.Provide(func() *Config { return &Config{Addr: ":80"} }),
+				fx.Provide(func() string { return "private" }, fx.Private),
+				fx.Decorate(func(cfg *Config) *Config { return &Config{Addr: cfg.Addr + "80"} }),
+				fx.Invoke(func(cfg *Config, s string) { decorated = cfg.Addr + " " + s }),
+			),
+			fx.Invoke(func(cfg *Config) { outside = cfg.Addr }),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, ":8080 private", decorated)
+		assert.Equal(t, ":80", outside, "decorations of a module must only affect the module")
+		assert.Error(t, c.Invoke(func(string) {}), "private constructors must not be exported")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+
+		var got *Config
+		err := fx.Apply(dig.New(), fx.Options(
+			fx.Supply(&Config{Addr: ":80"}),
+			fx.Options(fx.Invoke(func(cfg *Config) { got = cfg })),
+		))
+		require.NoError(t, err)
+		require.NotNil(t, got)
+	})
+
+	t.Run("invalid annotations", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			target  interface{}
+			wantErr string
+		}{
+			{
+				desc:    "too many param tags",
+				target:  fx.Annotate(NewServer, fx.ParamTags(`name:"a"`, `name:"b"`, `name:"c"`)),
+				wantErr: "got 3 ParamTags for 2 parameters",
+			},
+			{
+				desc:    "too many result tags",
+				target:  fx.Annotate(func() *Config { return nil }, fx.ResultTags(`name:"a"`, `name:"b"`)),
+				wantErr: "got 2 ResultTags for 1 results",
+			},
+			{
+				desc:    "type not implemented",
+				target:  fx.Annotate(func() *Config { return nil }, fx.As(new(io.Reader))),
+				wantErr: "result 0 of type *fxcompat_test.Config cannot be provided as io.Reader",
+			},
+			{
+				desc:    "non-pointer As",
+				target:  fx.Annotate(func() *Config { return nil }, fx.As(Config{})),
+				wantErr: "argument 0 of ResultAs must be a pointer to a type",
+			},
+			{
+				desc:    "ParamTags twice",
+				target:  fx.Annotate(NewServer, fx.ParamTags(), fx.ParamTags()),
+				wantErr: "cannot apply more than one ParamTags",
+			},
+			{
+				desc:    "name and group",
+				target:  fx.Annotated{Name: "a", Group: "b", Target: func() *Config { return nil }},
+				wantErr: "cannot use both Name and Group",
+			},
+			{
+				desc:    "not a function",
+				target:  fx.Annotate(42),
+				wantErr: "must annotate a function, got 42 (type int)",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := fx.Apply(dig.New(), fx.Provide(tt.target))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestOptionString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give fmt.Stringer
+		want string
+	}{
+		{
+			give: fx.Provide(NewServer, fx.Private),
+			want: "fx.Provide(github.com/alexisvisco/dig/fxcompat_test.NewServer(), fx.Private)",
+		},
+		{
+			give: fx.Supply(fx.Annotated{Name: "addr", Target: ":80"}),
+			want: `fx.Supply(fx.Annotated{Name: "addr", Target: :80})`,
+		},
+		{
+			give: fx.Module("server", fx.Invoke(fx.Annotate(NewServer, fx.ParamTags(`name:"addr"`)))),
+			want: `fx.Module("server", fx.Invoke(fx.Annotate(github.com/alexisvisco/dig/fxcompat_test.NewServer())))`,
+		},
+		{
+			give: fx.As(new(io.Reader), fx.Self()),
+			want: "fx.As(*io.Reader, fx.Self())",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.give.String())
+	}
+}
@@ -0,0 +1,708 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A GenerateOption modifies the code written by Generate.
+type GenerateOption interface {
+	applyGenerateOption(*generateOptions)
+}
+
+type generateOptions struct {
+	PackageName string
+	PackagePath string
+	Injectors   []generateInjector
+}
+
+type generateInjector struct {
+	Name    string
+	Targets []interface{}
+}
+
+// GeneratePackage is a GenerateOption that specifies the name and the
+// import path of the package the code is generated for. Constructors and
+// types of that package are referred to without qualifier, which allows
+// them to be unexported. The generated code is in package main by default.
+//
+//	dig.Generate(c, w, dig.GeneratePackage("app", "example.com/app"))
+func GeneratePackage(name, importPath string) GenerateOption {
+	return generatePackageOption{name, importPath}
+}
+
+type generatePackageOption struct{ name, path string }
+
+func (o generatePackageOption) String() string {
+	return fmt.Sprintf("GeneratePackage(%q, %q)", o.name, o.path)
+}
+
+func (o generatePackageOption) applyGenerateOption(opts *generateOptions) {
+	opts.PackageName = o.name
+	opts.PackagePath = o.path
+}
+
+// GenerateInjector is a GenerateOption that adds to the generated code a
+// function with the given name that builds the values of the types pointed
+// to by targets, and returns them along with an error.
+//
+//	// func newServer() (*http.Server, *log.Logger, error)
+//	dig.GenerateInjector("newServer", new(*http.Server), new(*log.Logger))
+//
+// Only the constructors the targets transitively depend on are called. A
+// dig.Key may be given instead of a pointer to target a named value or a
+// value group.
+func GenerateInjector(name string, targets ...interface{}) GenerateOption {
+	return generateInjectorOption{name, targets}
+}
+
+type generateInjectorOption struct {
+	name    string
+	targets []interface{}
+}
+
+func (o generateInjectorOption) String() string {
+	targets := make([]string, len(o.targets))
+	for i, t := range o.targets {
+		targets[i] = formatTarget(t)
+	}
+	return fmt.Sprintf("GenerateInjector(%q, %v)", o.name, strings.Join(targets, ", "))
+}
+
+func (o generateInjectorOption) applyGenerateOption(opts *generateOptions) {
+	opts.Injectors = append(opts.Injectors, generateInjector{Name: o.name, Targets: o.targets})
+}
+
+func formatTarget(t interface{}) string {
+	switch t := t.(type) {
+	case Key:
+		return t.String()
+	default:
+		if pt := reflect.TypeOf(t); pt != nil && pt.Kind() == reflect.Ptr {
+			return pt.Elem().String()
+		}
+		return fmt.Sprint(t)
+	}
+}
+
+// Generate writes to w Go code that builds the values of the container
+// without reflection, as the injectors given with GenerateInjector. The
+// code calls the constructors provided to the container directly, in the
+// order of their dependencies, so production builds may skip the
+// container entirely while development keeps its dynamic wiring. See the
+// diggen command to generate the code from a Go package.
+//
+//	err := dig.Generate(c, w,
+//		dig.GeneratePackage("main", "example.com/cmd/server"),
+//		dig.GenerateInjector("newServer", new(*http.Server)),
+//	)
+//
+// Only constructors provided to the container itself are used. They must
+// be functions declared at the top level of their package, and neither
+// annotated, partially applied nor transient: closures or values given to
+// Supply cannot be referred to from generated code. Decorators, value
+// groups consumed as maps or sorted, dig.Lazy, dig.Optional and parameters
+// with default values are not supported either. Generate fails with an
+// error naming the first unsupported constructor the injectors depend on.
+//
+// Lifecycle hooks, cleanup functions, callbacks and the other options of
+// Provide have no equivalent in the generated code.
+func Generate(c *Container, w io.Writer, opts ...GenerateOption) error {
+	options := generateOptions{PackageName: "main"}
+	for _, o := range opts {
+		o.applyGenerateOption(&options)
+	}
+	if len(options.Injectors) == 0 {
+		return newErrInvalidInput("cannot generate code without injectors: use dig.GenerateInjector", nil)
+	}
+	if len(c.scope.decoratorNodes) > 0 {
+		return newErrInvalidInput("cannot generate code for a container with decorators", nil)
+	}
+
+	g := generator{
+		s:       c.scope,
+		pkgPath: options.PackagePath,
+		imports: make(map[string]string),
+		aliases: map[string]struct{}{"err": {}},
+	}
+	var body bytes.Buffer
+	for _, inj := range options.Injectors {
+		if err := g.injector(&body, inj); err != nil {
+			return err
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by dig.Generate. DO NOT EDIT.\n\npackage %v\n", options.PackageName)
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		src.WriteString("\nimport (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&src, "\t%v %q\n", g.imports[path], path)
+		}
+		src.WriteString(")\n")
+	}
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("cannot format generated code: %v", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// generator writes the injectors of Generate.
+type generator struct {
+	s       *Scope
+	pkgPath string // import path of the generated package
+
+	// Aliases of the imported packages, keyed by import path.
+	imports map[string]string
+	aliases map[string]struct{}
+
+	// State of the injector being written: Go expressions of the values
+	// built so far, the constructors already called or being called, and
+	// the number of variables declared.
+	w       *bytes.Buffer
+	exprs   map[key]string
+	groups  map[key][]groupExpr
+	called  map[*constructorNode]bool
+	numVars int
+
+	// Variables of the results that were consumed. The other ones are
+	// assigned to _ at the end of the injector to keep the code valid.
+	used map[string]bool
+}
+
+// groupExpr is a value of a value group, or a slice of values if the
+// value was provided with flatten.
+type groupExpr struct {
+	expr    string
+	flatten bool
+}
+
+func (g *generator) injector(w *bytes.Buffer, inj generateInjector) error {
+	if !isIdentifier(inj.Name) {
+		return newErrInvalidInput(fmt.Sprintf("cannot generate injector %q: not a valid Go identifier", inj.Name), nil)
+	}
+	if len(inj.Targets) == 0 {
+		return newErrInvalidInput(fmt.Sprintf("cannot generate injector %v without targets", inj.Name), nil)
+	}
+
+	var body bytes.Buffer
+	g.w = &body
+	g.exprs = make(map[key]string)
+	g.groups = make(map[key][]groupExpr)
+	g.called = make(map[*constructorNode]bool)
+	g.numVars = 0
+
+	g.used = make(map[string]bool)
+
+	results := make([]string, 0, len(inj.Targets)+1)
+	values := make([]string, 0, len(inj.Targets)+1)
+	for _, target := range inj.Targets {
+		k, ok := target.(Key)
+		if !ok {
+			if t := reflect.TypeOf(target); t != nil && t.Kind() == reflect.Ptr {
+				k = Key{Type: t.Elem()}
+			}
+		}
+		if k.Type == nil {
+			return newErrInvalidInput(fmt.Sprintf(
+				"cannot generate injector %v for %v (type %T): targets must be pointers to types or dig.Key values",
+				inj.Name, target, target), nil)
+		}
+
+		var p param = paramSingle{Type: k.Type, Name: k.Name}
+		if k.Group != "" {
+			p = paramGroupedSlice{Type: reflect.SliceOf(k.Type), Group: k.Group}
+		}
+		texpr, err := g.typeExpr(paramType(p))
+		if err != nil {
+			return errGenerate{Injector: inj.Name, Reason: err}
+		}
+		expr, err := g.param(p)
+		if err != nil {
+			return errGenerate{Injector: inj.Name, Reason: err}
+		}
+		results = append(results, fmt.Sprintf("t%d %v", len(results)+1, texpr))
+		values = append(values, expr)
+	}
+	results = append(results, "err error")
+	values = append(values, "nil")
+
+	fmt.Fprintf(w, "\nfunc %v() (%v) {\n", inj.Name, strings.Join(results, ", "))
+	w.Write(body.Bytes())
+	for i := 1; i <= g.numVars; i++ {
+		if v := "v" + strconv.Itoa(i); !g.used[v] {
+			fmt.Fprintf(w, "_ = %v\n", v)
+		}
+	}
+	fmt.Fprintf(w, "return %v\n}\n", strings.Join(values, ", "))
+	return nil
+}
+
+// paramType returns the type of the values built for p.
+func paramType(p param) reflect.Type {
+	switch p := p.(type) {
+	case paramSingle:
+		return p.Type
+	case paramGroupedSlice:
+		return p.Type
+	case paramObject:
+		return p.Type
+	}
+	return nil
+}
+
+// param returns the expression of the value of p, writing the calls of the
+// constructors it depends on first.
+func (g *generator) param(p param) (string, error) {
+	switch p := p.(type) {
+	case paramSingle:
+		return g.paramSingle(p)
+	case paramObject:
+		return g.paramObject(p)
+	case paramGroupedSlice:
+		return g.paramGroupedSlice(p)
+	default:
+		return "", fmt.Errorf("%v is not supported in generated code", p)
+	}
+}
+
+func (g *generator) paramSingle(p paramSingle) (string, error) {
+	if len(p.Selector) > 0 || p.Default.IsValid() {
+		return "", fmt.Errorf("%v is not supported in generated code", p)
+	}
+
+	k := key{t: p.Type, name: p.Name}
+	providers := g.s.providers[k]
+	switch {
+	case len(providers) > 1:
+		return "", fmt.Errorf("%v has %d providers", p, len(providers))
+	case len(providers) == 1:
+		if err := g.call(providers[0]); err != nil {
+			return "", err
+		}
+		return g.use(g.exprs[k]), nil
+	case p.isContext():
+		return g.qualified("context", "Background") + "()", nil
+	case p.Optional:
+		texpr, err := g.typeExpr(p.Type)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("*new(%v)", texpr), nil
+	default:
+		return "", newErrMissingTypes(g.s, k)
+	}
+}
+
+func (g *generator) paramObject(p paramObject) (string, error) {
+	texpr, err := g.typeExpr(p.Type)
+	if err != nil {
+		return "", err
+	}
+
+	fields := make([]string, 0, len(p.Fields))
+	for _, f := range p.Fields {
+		expr, err := g.param(f.Param)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, fmt.Sprintf("%v: %v", f.FieldName, expr))
+	}
+	return fmt.Sprintf("%v{%v}", texpr, strings.Join(fields, ", ")), nil
+}
+
+func (g *generator) paramGroupedSlice(p paramGroupedSlice) (string, error) {
+	if p.Type.Kind() != reflect.Slice || p.Soft || p.Sorted {
+		return "", fmt.Errorf("%v is not supported in generated code", p)
+	}
+
+	k := key{t: p.Type.Elem(), group: p.Group}
+	for _, n := range g.s.providers[k] {
+		if err := g.call(n); err != nil {
+			return "", err
+		}
+	}
+
+	texpr, err := g.typeExpr(p.Type)
+	if err != nil {
+		return "", err
+	}
+	var values []string
+	for _, e := range g.groups[k] {
+		if !e.flatten {
+			values = append(values, g.use(e.expr))
+		}
+	}
+	v := g.newVar()
+	g.used[v] = true
+	fmt.Fprintf(g.w, "%v := %v{%v}\n", v, texpr, strings.Join(values, ", "))
+	for _, e := range g.groups[k] {
+		// Flattened values are appended once the other ones are in the
+		// slice.
+		if e.flatten {
+			fmt.Fprintf(g.w, "%v = append(%v, %v...)\n", v, v, g.use(e.expr))
+		}
+	}
+	return v, nil
+}
+
+// call writes the call of the constructor n, after the calls of the
+// constructors it depends on.
+func (g *generator) call(n *constructorNode) error {
+	switch done, ok := g.called[n]; {
+	case done:
+		return nil
+	case ok:
+		return fmt.Errorf("cycle detected: %v depends on itself", n.location)
+	}
+	g.called[n] = false
+
+	name, err := g.constructorExpr(n)
+	if err != nil {
+		return err
+	}
+
+	args := make([]string, 0, len(n.paramList.Params))
+	for _, p := range n.paramList.Params {
+		expr, err := g.param(p)
+		if err != nil {
+			return errGenerate{Func: n.location, Reason: err}
+		}
+		args = append(args, expr)
+	}
+
+	vars := make([]string, n.ctype.NumOut())
+	var fallible bool
+	for i := range vars {
+		switch {
+		case isError(n.ctype.Out(i)):
+			vars[i] = "err"
+			fallible = true
+		default:
+			vars[i] = g.newVar()
+		}
+	}
+	for i, ri := range n.resultList.resultIndexes {
+		if ri >= 0 {
+			if err := g.result(n.resultList.Results[ri], vars[i]); err != nil {
+				return errGenerate{Func: n.location, Reason: err}
+			}
+		}
+	}
+
+	call := fmt.Sprintf("%v(%v)", name, strings.Join(args, ", "))
+	switch {
+	case len(vars) == 0:
+		fmt.Fprintf(g.w, "%v\n", call)
+	case len(vars) == 1 && fallible:
+		fmt.Fprintf(g.w, "if err = %v; err != nil {\nreturn\n}\n", call)
+	default:
+		fmt.Fprintf(g.w, "%v := %v\n", strings.Join(vars, ", "), call)
+		if fallible {
+			fmt.Fprintf(g.w, "if err != nil {\nreturn\n}\n")
+		}
+	}
+	g.called[n] = true
+	return nil
+}
+
+// result records the expressions of the values of r, whose value is held
+// by the variable v.
+func (g *generator) result(r result, v string) error {
+	switch r := r.(type) {
+	case resultSingle:
+		g.exprs[key{t: r.Type, name: r.Name}] = v
+		for _, t := range r.As {
+			g.exprs[key{t: t, name: r.Name}] = v
+		}
+	case resultGrouped:
+		g.groups[key{t: r.Type, group: r.Group}] = append(g.groups[key{t: r.Type, group: r.Group}],
+			groupExpr{expr: v, flatten: r.Flatten})
+		for _, t := range r.As {
+			g.groups[key{t: t, group: r.Group}] = append(g.groups[key{t: t, group: r.Group}],
+				groupExpr{expr: v, flatten: r.Flatten})
+		}
+	case resultObject:
+		for _, f := range r.Fields {
+			if err := g.result(f.Result, v+"."+f.FieldName); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%v is not supported in generated code", r)
+	}
+	return nil
+}
+
+// use marks the variable of the expression e as used, and returns e.
+func (g *generator) use(e string) string {
+	if i := strings.IndexByte(e, '.'); i >= 0 {
+		g.used[e[:i]] = true
+	} else {
+		g.used[e] = true
+	}
+	return e
+}
+
+func (g *generator) newVar() string {
+	g.numVars++
+	return "v" + strconv.Itoa(g.numVars)
+}
+
+// constructorExpr returns the expression of the function of n.
+func (g *generator) constructorExpr(n *constructorNode) (string, error) {
+	unsupported := func(reason string) error {
+		return errGenerate{Func: n.location, Reason: fmt.Errorf("cannot generate code for %v", reason)}
+	}
+	switch {
+	case n.supplied != nil:
+		return "", unsupported("supplied values")
+	case n.transient:
+		return "", unsupported("transient constructors")
+	case n.resultList.cleanupIndex >= 0:
+		return "", unsupported("constructors returning cleanup functions")
+	}
+	for _, p := range n.paramList.Params {
+		if _, ok := p.(paramSupplied); ok {
+			return "", unsupported("partially applied constructors")
+		}
+	}
+
+	f := runtime.FuncForPC(reflect.ValueOf(n.ctor).Pointer())
+	if f == nil || f.Name() != n.location.Package+"."+n.location.Name {
+		return "", unsupported("annotated constructors")
+	}
+	if !isIdentifier(n.location.Name) {
+		return "", unsupported("constructors that are not declared at the top level of their package")
+	}
+
+	pkg := strings.TrimSuffix(n.location.Package, "_test")
+	if n.location.Package == g.pkgPath {
+		return n.location.Name, nil
+	}
+	if !isExported(n.location.Name) || pkg != n.location.Package {
+		return "", unsupported("constructors that are not exported from their package")
+	}
+	return g.qualified(n.location.Package, n.location.Name), nil
+}
+
+// qualified returns the expression of the identifier name of the package
+// at path, importing it if needed.
+func (g *generator) qualified(path, name string) string {
+	if path == g.pkgPath {
+		return name
+	}
+	alias, ok := g.imports[path]
+	if !ok {
+		base := path[strings.LastIndex(path, "/")+1:]
+		base = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '_'
+		}, base)
+		if base == "" || !unicode.IsLetter([]rune(base)[0]) {
+			base = "pkg" + base
+		}
+		alias = base
+		for i := 2; ; i++ {
+			if _, taken := g.aliases[alias]; !taken {
+				break
+			}
+			alias = base + strconv.Itoa(i)
+		}
+		g.imports[path] = alias
+		g.aliases[alias] = struct{}{}
+	}
+	return alias + "." + name
+}
+
+// typeExpr returns the Go expression of the type t.
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		switch {
+		case t.PkgPath() == "":
+			// Predeclared types, such as int or error.
+			return t.Name(), nil
+		case strings.ContainsAny(t.Name(), "[]"):
+			return "", fmt.Errorf("cannot refer to generic type %v in generated code", t)
+		case t.PkgPath() != g.pkgPath && !isExported(t.Name()):
+			return "", fmt.Errorf("cannot refer to unexported type %v in generated code", t)
+		case strings.HasSuffix(t.PkgPath(), "_test") && t.PkgPath() != g.pkgPath:
+			return "", fmt.Errorf("cannot refer to type %v of a test package in generated code", t)
+		}
+		return g.qualified(t.PkgPath(), t.Name()), nil
+	}
+
+	elem := func(prefix string) (string, error) {
+		e, err := g.typeExpr(t.Elem())
+		return prefix + e, err
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return elem("*")
+	case reflect.Slice:
+		return elem("[]")
+	case reflect.Array:
+		return elem(fmt.Sprintf("[%d]", t.Len()))
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return elem("<-chan ")
+		case reflect.SendDir:
+			return elem("chan<- ")
+		default:
+			return elem("chan ")
+		}
+	case reflect.Map:
+		k, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		return elem("map[" + k + "]")
+	case reflect.Func:
+		return g.funcTypeExpr(t)
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	case reflect.Struct:
+		return g.structTypeExpr(t)
+	}
+	return "", fmt.Errorf("cannot refer to type %v in generated code", t)
+}
+
+func (g *generator) funcTypeExpr(t reflect.Type) (string, error) {
+	in := make([]string, t.NumIn())
+	for i := range in {
+		pt := t.In(i)
+		prefix := ""
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			pt, prefix = pt.Elem(), "..."
+		}
+		e, err := g.typeExpr(pt)
+		if err != nil {
+			return "", err
+		}
+		in[i] = prefix + e
+	}
+	out := make([]string, t.NumOut())
+	for i := range out {
+		e, err := g.typeExpr(t.Out(i))
+		if err != nil {
+			return "", err
+		}
+		out[i] = e
+	}
+
+	expr := "func(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+		return expr, nil
+	case 1:
+		return expr + " " + out[0], nil
+	default:
+		return expr + " (" + strings.Join(out, ", ") + ")", nil
+	}
+}
+
+func (g *generator) structTypeExpr(t reflect.Type) (string, error) {
+	fields := make([]string, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			return "", fmt.Errorf("cannot refer to type %v with unexported fields in generated code", t)
+		}
+		e, err := g.typeExpr(f.Type)
+		if err != nil {
+			return "", err
+		}
+		if !f.Anonymous {
+			e = f.Name + " " + e
+		}
+		if f.Tag != "" {
+			e += " " + strconv.Quote(string(f.Tag))
+		}
+		fields[i] = e
+	}
+	return "struct{ " + strings.Join(fields, "; ") + " }", nil
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+func isExported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// errGenerate is returned when Generate cannot generate the code of a
+// constructor or an injector.
+type errGenerate struct {
+	Func     fmt.Stringer
+	Injector string
+	Reason   error
+}
+
+var _ digError = errGenerate{}
+
+func (e errGenerate) Error() string { return fmt.Sprint(e) }
+
+func (e errGenerate) Unwrap() error { return e.Reason }
+
+func (e errGenerate) writeMessage(w io.Writer, verb string) {
+	if e.Injector != "" {
+		fmt.Fprintf(w, "cannot generate injector %v", e.Injector)
+		return
+	}
+	fmt.Fprintf(w, "cannot generate the call of "+verb, e.Func)
+}
+
+func (e errGenerate) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type genConfig struct{ Addr string }
+
+func newGenConfig() *genConfig { return &genConfig{Addr: ":8080"} }
+
+type genLogger struct{}
+
+func newGenLogger(context.Context, *genConfig) (*genLogger, error) { return &genLogger{}, nil }
+
+type genRoute struct{ Path string }
+
+type genRoutes struct {
+	dig.Out
+
+	Routes []genRoute `group:"routes,flatten"`
+	Main   genRoute   `name:"main"`
+}
+
+func newGenRoutes() genRoutes { return genRoutes{} }
+
+func newGenHealthRoute() genRoute { return genRoute{Path: "/health"} }
+
+type genMissing struct{}
+
+type genServer struct{}
+
+type genServerParams struct {
+	dig.In
+
+	Config  *genConfig
+	Logger  *genLogger
+	Routes  []genRoute  `group:"routes"`
+	Main    genRoute    `name:"main"`
+	Missing *genMissing `optional:"true"`
+}
+
+func newGenServer(genServerParams) *genServer { return &genServer{} }
+
+func newGenUnused(*genConfig) *genMissing { return nil }
+
+func newGenString() string { return "dig" }
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	const pkg = "github.com/alexisvisco/dig_test"
+
+	t.Run("injectors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newGenConfig)
+		c.RequireProvide(newGenLogger)
+		c.RequireProvide(newGenRoutes)
+		c.RequireProvide(newGenHealthRoute, dig.Group("routes"))
+		c.RequireProvide(newGenServer)
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Generate(c.Container, &b,
+			dig.GeneratePackage("dig_test", pkg),
+			dig.GenerateInjector("initServer", new(*genServer)),
+			dig.GenerateInjector("initRoutes", dig.Key{Type: reflect.TypeOf(genRoute{}), Group: "routes"}),
+		))
+		assert.Equal(t, `// Code generated by dig.Generate. DO NOT EDIT.
+
+package dig_test
+
+import (
+	context "context"
+)
+
+func initServer() (t1 *genServer, err error) {
+	v1 := newGenConfig()
+	v2, err := newGenLogger(context.Background(), v1)
+	if err != nil {
+		return
+	}
+	v3 := newGenRoutes()
+	v4 := newGenHealthRoute()
+	v5 := []genRoute{v4}
+	v5 = append(v5, v3.Routes...)
+	v6 := newGenServer(genServerParams{Config: v1, Logger: v2, Routes: v5, Main: v3.Main, Missing: *new(*genMissing)})
+	return v6, nil
+}
+
+func initRoutes() (t1 []genRoute, err error) {
+	v1 := newGenRoutes()
+	v2 := newGenHealthRoute()
+	v3 := []genRoute{v2}
+	v3 = append(v3, v1.Routes...)
+	return v3, nil
+}
+`, b.String())
+	})
+
+	t.Run("other packages", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(bytes.NewBufferString)
+		c.RequireProvide(newGenString)
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Generate(c.Container, &b,
+			dig.GeneratePackage("dig_test", pkg),
+			dig.GenerateInjector("initBuffer", new(*bytes.Buffer))))
+		assert.Equal(t, `// Code generated by dig.Generate. DO NOT EDIT.
+
+package dig_test
+
+import (
+	bytes "bytes"
+)
+
+func initBuffer() (t1 *bytes.Buffer, err error) {
+	v1 := newGenString()
+	v2 := bytes.NewBufferString(v1)
+	return v2, nil
+}
+`, b.String())
+	})
+
+	t.Run("unsupported constructors", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			provide func(c *digtest.Container)
+			msg     string
+		}{
+			{
+				desc: "closure",
+				provide: func(c *digtest.Container) {
+					c.RequireProvide(func() *genConfig { return nil })
+				},
+				msg: "cannot generate code for constructors that are not declared at the top level of their package",
+			},
+			{
+				desc: "supplied value",
+				provide: func(c *digtest.Container) {
+					require.NoError(t, c.Supply(&genConfig{}))
+				},
+				msg: "cannot generate code for supplied values",
+			},
+			{
+				desc: "annotated",
+				provide: func(c *digtest.Container) {
+					c.RequireProvide(dig.Annotate(newGenConfig, dig.ResultTags(``)))
+				},
+				msg: "cannot generate code for annotated constructors",
+			},
+			{
+				desc: "transient",
+				provide: func(c *digtest.Container) {
+					c.RequireProvide(newGenConfig, dig.Transient())
+				},
+				msg: "cannot generate code for transient constructors",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				c := digtest.New(t)
+				tt.provide(c)
+				c.RequireProvide(newGenUnused)
+
+				err := dig.Generate(c.Container, new(bytes.Buffer),
+					dig.GeneratePackage("dig_test", pkg),
+					dig.GenerateInjector("initMissing", new(*genMissing)))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "cannot generate injector initMissing: ")
+				assert.Contains(t, err.Error(), tt.msg)
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(newGenConfig)
+
+		err := dig.Generate(c.Container, new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot generate code without injectors")
+
+		err = dig.Generate(c.Container, new(bytes.Buffer), dig.GenerateInjector("init config", new(*genConfig)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot generate injector "init config": not a valid Go identifier`)
+
+		err = dig.Generate(c.Container, new(bytes.Buffer), dig.GenerateInjector("initConfig", genConfig{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "targets must be pointers to types or dig.Key values")
+
+		err = dig.Generate(c.Container, new(bytes.Buffer),
+			dig.GeneratePackage("dig_test", pkg),
+			dig.GenerateInjector("initLogger", new(*genLogger)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.genLogger")
+
+		err = dig.Generate(c.Container, new(bytes.Buffer), dig.GenerateInjector("initConfig", new(*genConfig)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot refer to unexported type dig_test.genConfig in generated code")
+
+		c.RequireDecorate(func(c *genConfig) *genConfig { return c })
+		err = dig.Generate(c.Container, new(bytes.Buffer), dig.GenerateInjector("initConfig", new(*genConfig)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot generate code for a container with decorators")
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `GeneratePackage("app", "example.com/app")`,
+			dig.GeneratePackage("app", "example.com/app").(interface{ String() string }).String())
+		assert.Equal(t, `GenerateInjector("initServer", *dig_test.genServer, dig_test.genRoute[group="routes"])`,
+			dig.GenerateInjector("initServer", new(*genServer), dig.Key{Type: reflect.TypeOf(genRoute{}), Group: "routes"}).(interface{ String() string }).String())
+	})
+}
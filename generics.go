@@ -0,0 +1,178 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// A Provider accepts constructors. It is implemented by Container and
+// Scope.
+type Provider interface {
+	Provide(constructor interface{}, opts ...ProvideOption) error
+}
+
+var (
+	_ Provider = (*Container)(nil)
+	_ Provider = (*Scope)(nil)
+)
+
+// ProvideFn provides the given constructor of values of type T to the
+// Container or Scope, like Provide.
+//
+//	err := dig.ProvideFn[*Server](c, NewServer)
+//
+// The first result of the constructor must be a T, optionally followed by
+// an error, or ProvideFn fails. This guards against a constructor whose
+// signature drifted away from the type callers expect. The constructor may
+// have any parameters, which are resolved as with Provide.
+func ProvideFn[T any, F any](c Provider, constructor F, opts ...ProvideOption) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if ctype := reflect.TypeOf(constructor); ctype != nil && ctype.Kind() == reflect.Func {
+		if !producesFirst(ctype, t) {
+			var options provideOptions
+			for _, o := range opts {
+				o.applyProvideOption(&options)
+			}
+			errFunc := options.Location
+			if errFunc == nil {
+				errFunc = digreflect.InspectFunc(constructor)
+			}
+			return errProvide{
+				Func: errFunc,
+				Reason: newErrInvalidInput(
+					fmt.Sprintf("%v does not produce a value of type %v", ctype, t), nil),
+			}
+		}
+	}
+	return c.Provide(constructor, opts...)
+}
+
+// producesFirst reports whether a function of type ctype returns a t,
+// optionally followed by an error.
+func producesFirst(ctype, t reflect.Type) bool {
+	switch ctype.NumOut() {
+	case 1:
+		return ctype.Out(0) == t
+	case 2:
+		return ctype.Out(0) == t && isError(ctype.Out(1))
+	default:
+		return false
+	}
+}
+
+func returnsType(ctype, t reflect.Type) bool {
+	for i := 0; i < ctype.NumOut(); i++ {
+		if ctype.Out(i) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve builds a value of type T from the Container or Scope, along with
+// its dependencies, and returns it.
+//
+//	srv, err := dig.Resolve[*Server](c)
+//
+// This is equivalent to invoking a function that accepts a T and returns it,
+// so T may be a dig.In struct.
+func Resolve[T any](c Resolver) (T, error) {
+	var v T
+	err := c.Invoke(func(t T) { v = t })
+	return v, err
+}
+
+// ResolveNamed builds the value of type T with the given name from the
+// Container or Scope, along with its dependencies, and returns it.
+//
+//	ro, err := dig.ResolveNamed[*sql.DB](c, "ro")
+//
+// See also the package documentation about Named Values.
+func ResolveNamed[T any](c Resolver, name string) (T, error) {
+	var v T
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	in := reflect.StructOf([]reflect.StructField{
+		{Name: "In", Type: _inType, Anonymous: true},
+		{Name: "Value", Type: t, Tag: reflect.StructTag(fmt.Sprintf(`name:%q`, name))},
+	})
+
+	fn := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{in}, nil, false),
+		func(args []reflect.Value) []reflect.Value {
+			v = args[0].Field(1).Interface().(T)
+			return nil
+		},
+	)
+	err := c.Invoke(fn.Interface())
+	return v, err
+}
+
+// InvokeAndReturn invokes the given function on the Container or Scope like
+// Invoke, and returns the value of type T that the function returned.
+//
+//	srv, err := dig.InvokeAndReturn[*Server](c, func(cfg *Config) (*Server, error) {
+//	  return NewServer(cfg.Addr)
+//	})
+//
+// The function must return a T as one of its results. If it returns more
+// than one, the first one is returned. As with Invoke, an error returned by
+// the function is returned as-is, in which case the zero value of T is
+// returned with it.
+func InvokeAndReturn[T any](c Resolver, function interface{}, opts ...InvokeOption) (T, error) {
+	var v T
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	ftype := reflect.TypeOf(function)
+	if ftype != nil && ftype.Kind() == reflect.Func && !returnsType(ftype, t) {
+		return v, newErrInvalidInput(
+			fmt.Sprintf("%v does not return a value of type %v", ftype, t), nil)
+	}
+
+	// The returned values are read from the InvokeInfo, which replaces the
+	// one given with FillInvokeInfo, if any. The latter is filled too.
+	var options invokeOptions
+	for _, o := range opts {
+		o.applyInvokeOption(&options)
+	}
+	var info InvokeInfo
+	opts = append(opts[:len(opts):len(opts)], FillInvokeInfo(&info))
+	err := c.Invoke(function, opts...)
+	if options.Info != nil {
+		*options.Info = info
+	}
+	if err != nil {
+		return v, err
+	}
+
+	for i, out := range info.ReturnedOutput {
+		if ftype.Out(i) == t {
+			// The assertion fails for nil interface values.
+			if r, ok := out.Interface().(T); ok {
+				v = r
+			}
+			break
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvideFn(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constructor produces the type", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, dig.ProvideFn[*bytes.Buffer](c, func() (*bytes.Buffer, error) {
+			return bytes.NewBufferString("foo"), nil
+		}))
+		c.RequireInvoke(func(b *bytes.Buffer) {
+			assert.Equal(t, "foo", b.String())
+		})
+	})
+
+	t.Run("constructor with dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "foo" })
+		require.NoError(t, dig.ProvideFn[io.Reader](c, func(s string) (io.Reader, error) {
+			return bytes.NewBufferString(s), nil
+		}))
+		c.RequireInvoke(func(r io.Reader) {
+			b, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, "foo", string(b))
+		})
+	})
+
+	t.Run("constructor does not produce the type", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc string
+			give interface{}
+			want string
+		}{
+			{
+				desc: "other type",
+				give: func() *bytes.Buffer { return nil },
+				want: "func() *bytes.Buffer does not produce a value of type io.Reader",
+			},
+			{
+				desc: "not the first result",
+				give: func() (*bytes.Buffer, io.Reader) { return nil, nil },
+				want: "func() (*bytes.Buffer, io.Reader) does not produce a value of type io.Reader",
+			},
+			{
+				desc: "not followed by an error",
+				give: func() (io.Reader, *bytes.Buffer) { return nil, nil },
+				want: "func() (io.Reader, *bytes.Buffer) does not produce a value of type io.Reader",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := dig.ProvideFn[io.Reader](digtest.New(t), tt.give)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "cannot provide function")
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		s := c.Scope("child")
+		require.NoError(t, dig.ProvideFn[int](s, func() int { return 42 }))
+		s.RequireInvoke(func(i int) { assert.Equal(t, 42, i) })
+	})
+	t.Run("not a function", func(t *testing.T) {
+		t.Parallel()
+
+		err := dig.ProvideFn[int](digtest.New(t), 42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must provide constructor function")
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return bytes.NewBufferString("foo") })
+
+		b, err := dig.Resolve[*bytes.Buffer](c)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", b.String())
+	})
+
+	t.Run("parameter object", func(t *testing.T) {
+		t.Parallel()
+
+		type Params struct {
+			dig.In
+
+			Buffer *bytes.Buffer
+			Ints   []int `group:"ints"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return bytes.NewBufferString("foo") })
+		c.RequireProvide(func() int { return 1 }, dig.Group("ints"))
+
+		p, err := dig.Resolve[Params](c)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", p.Buffer.String())
+		assert.Equal(t, []int{1}, p.Ints)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := dig.Resolve[*bytes.Buffer](digtest.New(t))
+		require.Error(t, err)
+		assert.Nil(t, b)
+		assert.Contains(t, err.Error(), "missing type: *bytes.Buffer")
+	})
+
+	t.Run("named", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return bytes.NewBufferString("ro") }, dig.Name("ro"))
+		c.RequireProvide(func() *bytes.Buffer { return bytes.NewBufferString("rw") }, dig.Name("rw"))
+
+		b, err := dig.ResolveNamed[*bytes.Buffer](c, "rw")
+		require.NoError(t, err)
+		assert.Equal(t, "rw", b.String())
+
+		_, err = dig.ResolveNamed[*bytes.Buffer](c, "wo")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing type: *bytes.Buffer[name="wo"]`)
+	})
+
+	t.Run("named interface", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *bytes.Buffer { return bytes.NewBufferString("foo") },
+			dig.Name("foo"), dig.As(new(io.Reader)))
+
+		r, err := dig.ResolveNamed[io.Reader](c.Scope("child"), "foo")
+		require.NoError(t, err)
+		assert.NotNil(t, r)
+	})
+}
+
+func TestInvokeAndReturn(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "foo" })
+
+		b, err := dig.InvokeAndReturn[*bytes.Buffer](c, func(s string) (int, *bytes.Buffer, error) {
+			return 42, bytes.NewBufferString(s), nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "foo", b.String())
+	})
+
+	t.Run("nil interface", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := dig.InvokeAndReturn[io.Reader](digtest.New(t), func() io.Reader { return nil })
+		require.NoError(t, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("function error", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := dig.InvokeAndReturn[*bytes.Buffer](digtest.New(t), func() (*bytes.Buffer, error) {
+			return new(bytes.Buffer), errors.New("great sadness")
+		})
+		require.Error(t, err)
+		assert.Equal(t, "great sadness", err.Error())
+		assert.Nil(t, b)
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := dig.InvokeAndReturn[int](digtest.New(t), func(string) int { return 0 })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: string")
+	})
+
+	t.Run("wrong return type", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := dig.InvokeAndReturn[int](digtest.New(t), func() string { return "" })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "func() string does not return a value of type int")
+	})
+
+	t.Run("invoke info is filled", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "foo" })
+
+		var info dig.InvokeInfo
+		n, err := dig.InvokeAndReturn[int](c, func(s string) int { return len(s) }, dig.FillInvokeInfo(&info))
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		require.Len(t, info.Inputs, 1)
+		assert.Equal(t, "string", info.Inputs[0].String())
+	})
+}
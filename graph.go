@@ -71,12 +71,22 @@ func (gh *graphHolder) EdgesFrom(u int) []int {
 	case *paramGroupedSlice:
 		providers := gh.s.getAllGroupProviders(w.Group, w.Type.Elem())
 		for _, provider := range providers {
+			if gh.delegated(provider) {
+				continue
+			}
 			orders = append(orders, provider.Order(gh.s))
 		}
 	}
 	return orders
 }
 
+// delegated reports whether the provider belongs to a parent Container of
+// the graph's, which holds its own graph. Providers of a parent Container
+// cannot depend on values of the graph, and so cannot be part of a cycle.
+func (gh *graphHolder) delegated(p provider) bool {
+	return p.OrigScope().rootScope() != gh.s.rootScope()
+}
+
 // NewNode adds a new value to the graph and returns its order.
 func (gh *graphHolder) NewNode(wrapped interface{}) int {
 	order := len(gh.nodes)
@@ -23,17 +23,159 @@ package dig
 import (
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 )
 
 const (
-	_groupTag = "group"
+	_groupTag  = "group"
+	_weightTag = "weight"
 )
 
+var _boolType = reflect.TypeOf(true)
+
 type group struct {
-	Name    string
-	Flatten bool
-	Soft    bool
+	Name     string
+	Flatten  bool
+	Soft     bool
+	Tolerant bool
+	Sorted   bool
+}
+
+// groupValue is a value submitted to a value group along with the
+// information used to order it when the group is consumed with the sorted
+// option.
+type groupValue struct {
+	Value reflect.Value
+
+	// Weight of the value as specified in the `weight:".."` tag.
+	Weight int
+
+	// Position of the constructor that produced the value among all the
+	// constructors provided to the Container.
+	Order int
+}
+
+// namedGroupValue is a value submitted to a value group with a name, which
+// is its key when the group is consumed as a map.
+type namedGroupValue struct {
+	Name  string
+	Value reflect.Value
+}
+
+// RegisterGroupLess registers a function that orders the values of the
+// given value group when it is consumed with the sorted option.
+//
+// less must be a function of the form
+//
+//	func(a, b T) bool
+//
+// reporting whether a must be placed before b, where T is the type of the
+// values of the group. For example,
+//
+//	c.RegisterGroupLess("routes", func(a, b Route) bool {
+//	  return a.Pattern() < b.Pattern()
+//	})
+//
+//	type Params struct {
+//	  dig.In
+//
+//	  Routes []Route `group:"routes,sorted"`
+//	}
+//
+// The sort is stable: values that less considers equal remain ordered by
+// weight, and then in the order their constructors were provided.
+func (c *Container) RegisterGroupLess(group string, less interface{}) error {
+	lt := reflect.TypeOf(less)
+	if lt == nil || lt.Kind() != reflect.Func || lt.NumIn() != 2 || lt.In(0) != lt.In(1) ||
+		lt.NumOut() != 1 || lt.Out(0) != _boolType || lt.IsVariadic() {
+		return newErrInvalidInput(fmt.Sprintf(
+			"less function for group %q must be of the form func(T, T) bool: got %v", group, lt), nil)
+	}
+
+	k := key{group: group, t: lt.In(0)}
+	state := c.scope.state
+	if _, ok := state.groupLess[k]; ok {
+		return newErrInvalidInput(fmt.Sprintf(
+			"a less function is already registered for group %q of %v", group, k.t), nil)
+	}
+	state.groupLess[k] = reflect.ValueOf(less)
+	return nil
+}
+
+// A GroupWarning reports a constructor whose values were left out of a
+// value group consumed with the tolerant modifier because it failed, or
+// because some of its dependencies could not be built.
+type GroupWarning struct {
+	// Group is the name of the value group.
+	Group string
+
+	// Type is the type of the values of the group.
+	Type reflect.Type
+
+	// Provider describes the constructor that was skipped.
+	Provider ProviderInfo
+
+	// Reason is the error the constructor failed with.
+	Reason error
+}
+
+func (w GroupWarning) String() string {
+	return fmt.Sprintf("%v left out of %v[group=%q]: %v", w.Provider.Name, w.Type, w.Group, w.Reason)
+}
+
+// Warnings returns the constructors that were left out of tolerant value
+// groups built by the container and its Scopes, in the order they first
+// failed. A constructor is reported once per value group, with the error
+// of its latest failure.
+func (c *Container) Warnings() []GroupWarning {
+	return append([]GroupWarning(nil), c.scope.rootScope().state.warnings...)
+}
+
+// GroupReport returns the constructors that were left out of the value
+// group with the given name when it was consumed with the tolerant
+// modifier, so that applications may report that they are running in a
+// degraded state. As with Warnings, each constructor is reported once,
+// with the error of its latest failure, in the order they first failed.
+//
+//	for _, w := range c.GroupReport("handlers") {
+//	  log.Printf("handler %v is disabled: %v", w.Provider.Name, w.Reason)
+//	}
+func (c *Container) GroupReport(group string) []GroupWarning {
+	var report []GroupWarning
+	for _, w := range c.scope.rootScope().state.warnings {
+		if w.Group == group {
+			report = append(report, w)
+		}
+	}
+	return report
+}
+
+// warn records that a constructor was left out of a tolerant value group,
+// for the Invoke in progress and for the container. A constructor failing
+// again replaces its previous warning, so that the warnings of the
+// container do not grow with every Invoke.
+func (s *Scope) warn(w GroupWarning) {
+	state := s.rootScope().state
+	if state.invokeWarnings != nil {
+		*state.invokeWarnings = append(*state.invokeWarnings, w)
+	}
+	for i, old := range state.warnings {
+		if old.Provider.ID == w.Provider.ID && old.Group == w.Group && old.Type == w.Type {
+			state.warnings[i] = w
+			return
+		}
+	}
+	state.warnings = append(state.warnings, w)
+}
+
+// withWarnings records the warnings of the Invoke in progress to the given
+// slice until the returned function is called.
+func (s *Scope) withWarnings(warnings *[]GroupWarning) func() {
+	state := s.rootScope().state
+	prev := state.invokeWarnings
+	state.invokeWarnings = warnings
+	return func() { state.invokeWarnings = prev }
 }
 
 type errInvalidGroupOption struct{ Option string }
@@ -59,6 +201,10 @@ func parseGroupString(s string) (group, error) {
 			g.Flatten = true
 		case "soft":
 			g.Soft = true
+		case "tolerant":
+			g.Tolerant = true
+		case "sorted":
+			g.Sorted = true
 		default:
 			return g, errInvalidGroupOption{Option: c}
 		}
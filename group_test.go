@@ -48,6 +48,16 @@ func TestParseGroup(t *testing.T) {
 			group: "somegroup,soft",
 			wantG: group{Name: "somegroup", Soft: true},
 		},
+		{
+			name:  "tolerant group",
+			group: "somegroup,tolerant",
+			wantG: group{Name: "somegroup", Tolerant: true},
+		},
+		{
+			name:  "sorted group",
+			group: "somegroup,sorted",
+			wantG: group{Name: "somegroup", Sorted: true},
+		},
 		{
 			name:    "error",
 			group:   `somegroup,abc`,
@@ -0,0 +1,163 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// HealthChecker is implemented by values that can report whether they are
+// healthy, like connections to external systems. Values produced by
+// constructors that implement HealthChecker are checked by
+// Container.HealthCheck.
+type HealthChecker interface {
+	HealthCheck(context.Context) error
+}
+
+// WithHealthCheck is a ProvideOption that registers a health check to be
+// run by Container.HealthCheck once the constructor has been called
+// successfully.
+//
+//	c.Provide(NewBroker, dig.WithHealthCheck(func(ctx context.Context) error {
+//	  return ping(ctx)
+//	}))
+func WithHealthCheck(check func(context.Context) error) ProvideOption {
+	return provideHealthCheckOption{check: check}
+}
+
+type provideHealthCheckOption struct {
+	check func(context.Context) error
+}
+
+func (o provideHealthCheckOption) String() string {
+	return fmt.Sprintf("WithHealthCheck(%v)", digreflect.InspectFunc(o.check))
+}
+
+func (o provideHealthCheckOption) applyProvideOption(opts *provideOptions) {
+	if o.check != nil {
+		opts.HealthChecks = append(opts.HealthChecks, o.check)
+	}
+}
+
+// HealthStatus is the outcome of a health check run by
+// Container.HealthCheck.
+type HealthStatus struct {
+	// Component identifies what was checked. Values implementing
+	// HealthChecker are identified by their type, along with their name or
+	// value group. Checks registered with WithHealthCheck are identified by
+	// the name of their constructor.
+	Component string
+
+	// Provider is the constructor that registered the check.
+	Provider ProviderInfo
+
+	// Err is the error returned by the check, or a PanicError if it
+	// panicked. It is nil if the component is healthy.
+	Err error
+
+	// Duration is how long the check took.
+	Duration time.Duration
+}
+
+// Healthy reports whether the check succeeded.
+func (hs HealthStatus) Healthy() bool {
+	return hs.Err == nil
+}
+
+// healthCheck is a health check registered by a constructor when it was
+// called.
+type healthCheck struct {
+	Component string
+	Provider  *constructorNode
+	Check     func(context.Context) error
+}
+
+// HealthCheck runs the health checks registered by the constructors called
+// so far, and returns their status in the order in which they were
+// registered. See HealthChecker and WithHealthCheck.
+//
+// Checks run concurrently, and should return when ctx is done. Constructors
+// that were not called yet, like those whose values were never needed,
+// are not checked.
+func (c *Container) HealthCheck(ctx context.Context) []HealthStatus {
+	var checks []healthCheck
+	_ = c.scope.parallelism().do(func() error {
+		checks = append(checks, c.scope.state.healthChecks...)
+		return nil
+	})
+
+	statuses := make([]HealthStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, hc := range checks {
+		statuses[i] = HealthStatus{
+			Component: hc.Component,
+			Provider:  newProviderInfo(hc.Provider),
+		}
+
+		wg.Add(1)
+		go func(hs *HealthStatus, check func(context.Context) error) {
+			defer wg.Done()
+			defer func(start time.Time) { hs.Duration = time.Since(start) }(time.Now())
+			defer func() {
+				if p := recover(); p != nil {
+					hs.Err = newPanicError(digreflect.InspectFunc(check), false /* constructor */, p)
+				}
+			}()
+
+			hs.Err = check(ctx)
+		}(&statuses[i], hc.Check)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// healthChecks returns the health checks registered by this constructor
+// when it returned the given values.
+func (n *constructorNode) healthChecks(values []reflect.Value) []healthCheck {
+	var checks []healthCheck
+	eachResultValue(n.resultList, values, func(k key, v reflect.Value) {
+		if hc, ok := v.Interface().(HealthChecker); ok {
+			checks = append(checks, healthCheck{
+				Component: k.String(),
+				Provider:  n,
+				Check:     hc.HealthCheck,
+			})
+		}
+	})
+
+	if len(n.checks) > 0 {
+		name := fmt.Sprintf("%v.%v", n.location.Package, n.location.Name)
+		for _, check := range n.checks {
+			checks = append(checks, healthCheck{
+				Component: name,
+				Provider:  n,
+				Check:     check,
+			})
+		}
+	}
+	return checks
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type healthComponent struct {
+	err error
+}
+
+func (h *healthComponent) HealthCheck(context.Context) error { return h.err }
+
+func TestHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values implementing HealthChecker", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			Primary *healthComponent   `name:"primary"`
+			Workers []*healthComponent `group:"workers,flatten"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() out {
+			return out{
+				Primary: &healthComponent{},
+				Workers: []*healthComponent{{}, {err: errors.New("great sadness")}},
+			}
+		})
+		c.RequireProvide(func() *healthComponent { return &healthComponent{} })
+
+		assert.Empty(t, c.HealthCheck(context.Background()),
+			"constructors that were not called must not be checked")
+
+		type in struct {
+			dig.In
+
+			Primary *healthComponent `name:"primary"`
+		}
+		c.RequireInvoke(func(in, *healthComponent) {})
+
+		statuses := c.HealthCheck(context.Background())
+		require.Len(t, statuses, 4)
+		assert.Equal(t, `*dig_test.healthComponent[name="primary"]`, statuses[0].Component)
+		assert.True(t, statuses[0].Healthy())
+		assert.Equal(t, `*dig_test.healthComponent[group="workers"]`, statuses[1].Component)
+		assert.True(t, statuses[1].Healthy())
+		assert.Equal(t, `*dig_test.healthComponent[group="workers"]`, statuses[2].Component)
+		assert.False(t, statuses[2].Healthy())
+		assert.EqualError(t, statuses[2].Err, "great sadness")
+		assert.Equal(t, "*dig_test.healthComponent", statuses[3].Component)
+		assert.True(t, statuses[3].Healthy())
+		assert.Equal(t, statuses[0].Provider.ID, statuses[1].Provider.ID)
+		assert.NotEqual(t, statuses[0].Provider.ID, statuses[3].Provider.ID)
+	})
+
+	t.Run("WithHealthCheck", func(t *testing.T) {
+		t.Parallel()
+
+		var ctxs []context.Context
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() string { return "hello" },
+			dig.WithHealthCheck(func(ctx context.Context) error {
+				ctxs = append(ctxs, ctx)
+				return errors.New("great sadness")
+			}),
+		)
+		child.RequireInvoke(func(string) {})
+		child.RequireInvoke(func(string) {})
+
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		statuses := c.HealthCheck(ctx)
+		require.Len(t, statuses, 1, "checks must be registered once")
+		assert.Contains(t, statuses[0].Component, "TestHealthCheck")
+		assert.EqualError(t, statuses[0].Err, "great sadness")
+		assert.Equal(t, []string{"child"}, statuses[0].Provider.ScopePath)
+		assert.Equal(t, []context.Context{ctx}, ctxs)
+	})
+
+	t.Run("supplied values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Supply(&healthComponent{err: errors.New("great sadness")}))
+		c.RequireInvoke(func(*healthComponent) {})
+
+		statuses := c.HealthCheck(context.Background())
+		require.Len(t, statuses, 1)
+		assert.False(t, statuses[0].Healthy())
+	})
+
+	t.Run("panics are recovered", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 },
+			dig.WithHealthCheck(func(context.Context) error { panic("great sadness") }))
+		c.RequireInvoke(func(int) {})
+
+		statuses := c.HealthCheck(context.Background())
+		require.Len(t, statuses, 1)
+		var pe dig.PanicError
+		require.True(t, errors.As(statuses[0].Err, &pe), "expected a PanicError, got %v", statuses[0].Err)
+		assert.Equal(t, "great sadness", pe.Panic)
+	})
+
+	t.Run("transient constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() int { return 1 }, dig.Transient(),
+			dig.WithHealthCheck(func(context.Context) error { return nil }))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use Transient with health checks")
+	})
+}
+
+func TestWithHealthCheckString(t *testing.T) {
+	t.Parallel()
+
+	assert.Contains(t, fmt.Sprint(dig.WithHealthCheck(func(context.Context) error { return nil })),
+		"WithHealthCheck(")
+}
@@ -28,12 +28,13 @@ import (
 )
 
 var (
-	_noValue    reflect.Value
-	_errType    = reflect.TypeOf((*error)(nil)).Elem()
-	_inPtrType  = reflect.TypeOf((*In)(nil))
-	_inType     = reflect.TypeOf(In{})
-	_outPtrType = reflect.TypeOf((*Out)(nil))
-	_outType    = reflect.TypeOf(Out{})
+	_noValue     reflect.Value
+	_errType     = reflect.TypeOf((*error)(nil)).Elem()
+	_cleanupType = reflect.TypeOf(func() {})
+	_inPtrType   = reflect.TypeOf((*In)(nil))
+	_inType      = reflect.TypeOf(In{})
+	_outPtrType  = reflect.TypeOf((*Out)(nil))
+	_outType     = reflect.TypeOf(Out{})
 )
 
 // Placeholder type placed in dig.In/dig.out to make their special nature
@@ -57,6 +58,10 @@ type digSentinel struct{}
 //	group       Name of the Value Group from which this field will be filled.
 //	            The field must be a slice type. See Value Groups in the
 //	            package documentation for more information.
+//	decorated   If set to false, requests the value, or the values of the
+//	            group, as provided to the container, before any decorator
+//	            applies to them. This lets a decorator wrap the original
+//	            value even if other decorators replaced it.
 type In struct{ _ digSentinel }
 
 // Out is an embeddable type that signals to dig that the returned
@@ -173,3 +178,21 @@ func isFieldOptional(f reflect.StructField) (bool, error) {
 
 	return optional, err
 }
+
+// isFieldUndecorated reports whether the field of a dig.In struct receives
+// the value as it was provided, before any decorator, with the
+// `decorated:"false"` tag.
+func isFieldUndecorated(f reflect.StructField) (bool, error) {
+	tag := f.Tag.Get(_decoratedTag)
+	if tag == "" {
+		return false, nil
+	}
+
+	decorated, err := strconv.ParseBool(tag)
+	if err != nil {
+		err = newErrInvalidInput(
+			fmt.Sprintf("invalid value %q for %q tag on field %v", tag, _decoratedTag, f.Name), err)
+	}
+
+	return !decorated, err
+}
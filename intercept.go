@@ -0,0 +1,138 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ProviderInterceptor wraps calls to constructors. It receives information
+// about the constructor being called, and a function next that calls it.
+//
+// The error returned by next is the error returned by the constructor, if
+// any. The error returned by the interceptor is the one Dig acts on: an
+// interceptor may add context to the error of the constructor, fail a
+// constructor that succeeded, or recover from the panics of next.
+type ProviderInterceptor func(info ProviderInfo, next func() error) error
+
+// ProviderInterceptorCtx is a ProviderInterceptor that also receives the
+// context of the Invoke the constructor is called for: the context given to
+// InvokeCtx, or context.Background().
+type ProviderInterceptorCtx func(ctx context.Context, info ProviderInfo, next func() error) error
+
+// WithProviderInterceptor is an Option that wraps every call to a
+// constructor provided to the container, or to any of its Scopes, with the
+// given interceptor. This allows logging, tracing, or timing constructors
+// without modifying them.
+//
+//	c := dig.New(dig.WithProviderInterceptor(
+//	  func(info dig.ProviderInfo, next func() error) error {
+//	    start := time.Now()
+//	    err := next()
+//	    log.Printf("%v took %v", info.Name, time.Since(start))
+//	    return err
+//	  },
+//	))
+//
+// The interceptor must call next exactly once, unless it fails. When the
+// option is given multiple times, the first interceptor is the outermost.
+func WithProviderInterceptor(interceptor ProviderInterceptor) Option {
+	return providerInterceptorOption{interceptor}
+}
+
+type providerInterceptorOption struct{ interceptor ProviderInterceptor }
+
+func (o providerInterceptorOption) String() string {
+	return fmt.Sprintf("WithProviderInterceptor(%p)", o.interceptor)
+}
+
+func (o providerInterceptorOption) applyOption(c *Container) {
+	interceptor := o.interceptor
+	c.scope.state.interceptors = append(c.scope.state.interceptors,
+		func(_ context.Context, info ProviderInfo, next func() error) error {
+			return interceptor(info, next)
+		})
+}
+
+// WithProviderInterceptorCtx is an Option that wraps every call to a
+// constructor provided to the container, or to any of its Scopes, with the
+// given interceptor, like WithProviderInterceptor. The interceptor receives
+// the context of the Invoke the constructor is called for, so that the
+// tracing spans of constructors can join the trace of their caller.
+//
+//	c := dig.New(dig.WithProviderInterceptorCtx(
+//	  func(ctx context.Context, info dig.ProviderInfo, next func() error) error {
+//	    _, span := tracer.Start(ctx, info.Name)
+//	    defer span.End()
+//	    return next()
+//	  },
+//	))
+//	err := c.InvokeCtx(ctx, run)
+//
+// Interceptors given with WithProviderInterceptor and
+// WithProviderInterceptorCtx are applied in the order the options are
+// given, the first one being the outermost.
+func WithProviderInterceptorCtx(interceptor ProviderInterceptorCtx) Option {
+	return providerInterceptorCtxOption{interceptor}
+}
+
+type providerInterceptorCtxOption struct{ interceptor ProviderInterceptorCtx }
+
+func (o providerInterceptorCtxOption) String() string {
+	return fmt.Sprintf("WithProviderInterceptorCtx(%p)", o.interceptor)
+}
+
+func (o providerInterceptorCtxOption) applyOption(c *Container) {
+	c.scope.state.interceptors = append(c.scope.state.interceptors, o.interceptor)
+}
+
+// intercept calls the constructor through the interceptors of the Container.
+func (s *Scope) intercept(n *constructorNode, call func() error) error {
+	interceptors := s.rootScope().state.interceptors
+	if len(interceptors) == 0 {
+		return call()
+	}
+
+	var called bool
+	next := func() error {
+		if called {
+			return errors.New("provider interceptor called the constructor more than once")
+		}
+		called = true
+		return call()
+	}
+
+	info, ctx := newProviderInfo(n), s.invokeContext()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func() error { return interceptor(ctx, info, inner) }
+	}
+
+	if err := next(); err != nil {
+		return err
+	}
+	if !called {
+		return errors.New("provider interceptor returned without calling the constructor")
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderInterceptor(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("wraps every constructor", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		trace := func(prefix string) dig.Option {
+			return dig.WithProviderInterceptor(func(info dig.ProviderInfo, next func() error) error {
+				calls = append(calls, prefix+" before "+info.Outputs[0].String())
+				err := next()
+				calls = append(calls, prefix+" after "+info.Outputs[0].String())
+				return err
+			})
+		}
+
+		c := digtest.New(t, trace("outer"), trace("inner"))
+		c.RequireProvide(func() *A {
+			calls = append(calls, "new A")
+			return &A{}
+		})
+		child := c.Scope("child")
+		child.RequireProvide(func(*A) *B {
+			calls = append(calls, "new B")
+			return &B{}
+		})
+		child.RequireInvoke(func(*B) {})
+
+		assert.Equal(t, []string{
+			"outer before *dig_test.A",
+			"inner before *dig_test.A",
+			"new A",
+			"inner after *dig_test.A",
+			"outer after *dig_test.A",
+			"outer before *dig_test.B",
+			"inner before *dig_test.B",
+			"new B",
+			"inner after *dig_test.B",
+			"outer after *dig_test.B",
+		}, calls)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithProviderInterceptor(func(info dig.ProviderInfo, next func() error) error {
+			if err := next(); err != nil {
+				return fmt.Errorf("intercepted: %w", err)
+			}
+			return nil
+		}))
+		c.RequireProvide(func() (*A, error) { return nil, errors.New("great sadness") })
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "intercepted: great sadness")
+	})
+
+	t.Run("panics converted to errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithProviderInterceptor(func(info dig.ProviderInfo, next func() error) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = fmt.Errorf("panic in %v: %v", info.Name, p)
+				}
+			}()
+			return next()
+		}))
+		c.RequireProvide(func() *A { panic("great sadness") })
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Contains(t, err.Error(), "panic in github.com/alexisvisco/dig_test.TestProviderInterceptor")
+	})
+
+	t.Run("failing without calling the constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithProviderInterceptor(func(dig.ProviderInfo, func() error) error {
+			return errors.New("denied")
+		}))
+		c.RequireProvide(func() *A {
+			t.Fatal("constructor must not be called")
+			return nil
+		})
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "denied")
+	})
+
+	t.Run("not calling the constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithProviderInterceptor(func(dig.ProviderInfo, func() error) error {
+			return nil
+		}))
+		c.RequireProvide(func() *A { return &A{} })
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provider interceptor returned without calling the constructor")
+	})
+
+	t.Run("calling the constructor twice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithProviderInterceptor(func(_ dig.ProviderInfo, next func() error) error {
+			if err := next(); err != nil {
+				return err
+			}
+			return next()
+		}))
+		c.RequireProvide(func() *A { return &A{} })
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provider interceptor called the constructor more than once")
+	})
+
+	t.Run("invoke context", func(t *testing.T) {
+		t.Parallel()
+
+		type ctxKey struct{}
+		var calls []string
+		c := digtest.New(t,
+			dig.WithProviderInterceptor(func(info dig.ProviderInfo, next func() error) error {
+				calls = append(calls, "plain "+info.Outputs[0].String())
+				return next()
+			}),
+			dig.WithProviderInterceptorCtx(func(ctx context.Context, info dig.ProviderInfo, next func() error) error {
+				v, _ := ctx.Value(ctxKey{}).(string)
+				calls = append(calls, "ctx "+info.Outputs[0].String()+" "+v)
+				return next()
+			}),
+		)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func(*A) *B { return &B{} })
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		require.NoError(t, c.InvokeCtx(ctx, func(*B) {}))
+		assert.Equal(t, []string{
+			"plain *dig_test.A",
+			"ctx *dig_test.A value",
+			"plain *dig_test.B",
+			"ctx *dig_test.B value",
+		}, calls)
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteD2 writes the graph to w as a D2 diagram.
+//
+// The diagram holds the same nodes and edges as the DOT representation of
+// the graph: constructors are containers holding the results they produce,
+// supplied values are parallelograms, value groups are diamonds, decorators
+// are linked to the values they decorate with thick edges, and failed nodes
+// are outlined in red or orange.
+func (dg *Graph) WriteD2(w io.Writer) error {
+	dw := newD2Writer(w)
+	dw.write(dg)
+	return dw.Flush()
+}
+
+type d2Writer struct {
+	*bufio.Writer
+
+	// ids maps the string representation of each node to the path of the
+	// key it was assigned, which is qualified by the container holding it.
+	ids map[string]string
+}
+
+func newD2Writer(w io.Writer) *d2Writer {
+	return &d2Writer{
+		Writer: bufio.NewWriter(w),
+		ids:    make(map[string]string),
+	}
+}
+
+func (dw *d2Writer) write(dg *Graph) {
+	dw.WriteString("direction: left\n")
+
+	for i, c := range dg.Ctors {
+		if c.Supplied {
+			for _, r := range c.Results {
+				dw.declare(r.String(), "", "%v: %v {shape: parallelogram}\n", d2ResultLabel(r))
+			}
+			continue
+		}
+		container := fmt.Sprintf("cluster_%d", i)
+		fmt.Fprintf(dw, "%v: %v {\n", container, strconv.Quote(c.Label()))
+		fmt.Fprintf(dw, "\tconstructor: %v {shape: text}\n", strconv.Quote(c.Name))
+		for _, r := range c.Results {
+			dw.declare(r.String(), container+".", "\t%v: %v {shape: oval}\n", d2ResultLabel(r))
+		}
+		dw.WriteString("}\n")
+	}
+	for _, g := range dg.Groups {
+		dw.declare(g.String(), "", "%v: %v {shape: diamond}\n",
+			strconv.Quote(fmt.Sprintf("%v\nGroup: %v", g.Type, g.Name)))
+	}
+	for _, c := range dg.Ctors {
+		for _, p := range c.Params {
+			dw.declare(p.String(), "", "%v: %v\n", strconv.Quote(p.String()))
+		}
+	}
+	for i, d := range dg.Decorators {
+		fmt.Fprintf(dw, "decorator_%d: %v {shape: hexagon}\n", i, strconv.Quote(d.Name))
+		for _, p := range d.Params {
+			dw.declare(p.String(), "", "%v: %v\n", strconv.Quote(p.String()))
+		}
+		for _, r := range d.Results {
+			dw.declare(r.String(), "", "%v: %v {shape: oval}\n", d2ResultLabel(r))
+		}
+		for _, g := range d.Groups {
+			dw.declare(g.String(), "", "%v: %v {shape: diamond}\n",
+				strconv.Quote(fmt.Sprintf("%v\nGroup: %v", g.Type, g.Name)))
+		}
+	}
+	for _, r := range dg.Failed.TransitiveFailures {
+		dw.declare(r.String(), "", "%v: %v\n", strconv.Quote(r.String()))
+	}
+	for _, r := range dg.Failed.RootCauses {
+		dw.declare(r.String(), "", "%v: %v\n", strconv.Quote(r.String()))
+	}
+
+	for _, g := range dg.Groups {
+		for _, r := range g.Results {
+			fmt.Fprintf(dw, "%v -> %v\n", dw.ids[g.String()], dw.ids[r.String()])
+		}
+	}
+	for i, c := range dg.Ctors {
+		for _, p := range c.Params {
+			fmt.Fprintf(dw, "cluster_%d -> %v%v\n", i, dw.ids[p.String()], d2Optional(p))
+		}
+		for _, g := range c.GroupParams {
+			fmt.Fprintf(dw, "cluster_%d -> %v\n", i, dw.ids[g.String()])
+		}
+	}
+
+	for i, d := range dg.Decorators {
+		for _, p := range d.Params {
+			fmt.Fprintf(dw, "decorator_%d -> %v%v\n", i, dw.ids[p.String()], d2Optional(p))
+		}
+		for _, g := range d.GroupParams {
+			fmt.Fprintf(dw, "decorator_%d -> %v\n", i, dw.ids[g.String()])
+		}
+
+		// Decorations are drawn with thick edges to tell them apart from
+		// dependencies.
+		label := ""
+		if d.Local {
+			label = ": local"
+		}
+		for _, r := range d.Results {
+			fmt.Fprintf(dw, "decorator_%d -> %v%v {style.stroke-width: 4}\n", i, dw.ids[r.String()], label)
+		}
+		for _, g := range d.Groups {
+			fmt.Fprintf(dw, "decorator_%d -> %v%v {style.stroke-width: 4}\n", i, dw.ids[g.String()], label)
+		}
+	}
+
+	for i, c := range dg.Ctors {
+		if c.ErrorType != noError && !c.Supplied {
+			fmt.Fprintf(dw, "cluster_%d.style.stroke: %v\n", i, c.ErrorType.Color())
+		}
+	}
+	for _, g := range dg.Groups {
+		if g.ErrorType != noError {
+			fmt.Fprintf(dw, "%v.style.stroke: %v\n", dw.ids[g.String()], g.ErrorType.Color())
+		}
+	}
+	for _, r := range dg.Failed.TransitiveFailures {
+		fmt.Fprintf(dw, "%v.style.stroke: %v\n", dw.ids[r.String()], transitiveFailure.Color())
+	}
+	for _, r := range dg.Failed.RootCauses {
+		fmt.Fprintf(dw, "%v.style.stroke: %v\n", dw.ids[r.String()], rootCause.Color())
+	}
+}
+
+// declare assigns a key to the node with the given string representation
+// and writes its declaration using format, unless it was already declared.
+// The key is qualified by container, if any, when the node is referred to.
+// format receives the key and the label of the node.
+func (dw *d2Writer) declare(node, container, format, label string) {
+	if _, ok := dw.ids[node]; ok {
+		return
+	}
+	id := fmt.Sprintf("node_%d", len(dw.ids))
+	dw.ids[node] = container + id
+	fmt.Fprintf(dw, format, id, label)
+}
+
+func d2Optional(p *Param) string {
+	if p.Optional {
+		return " {style.stroke-dash: 3}"
+	}
+	return ""
+}
+
+func d2ResultLabel(r *Result) string {
+	switch {
+	case r.Group != "" && r.Name != "":
+		return strconv.Quote(fmt.Sprintf("%v\nGroup: %v, Name: %v", r.Type, r.Group, r.Name))
+	case r.Name != "":
+		return strconv.Quote(fmt.Sprintf("%v\nName: %v", r.Type, r.Name))
+	case r.Group != "":
+		return strconv.Quote(fmt.Sprintf("%v\nGroup: %v", r.Type, r.Group))
+	default:
+		return strconv.Quote(r.Type.String())
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteD2(t *testing.T) {
+	type1 := reflect.TypeOf(t1{})
+	type2 := reflect.TypeOf(t2{})
+
+	t.Run("labels are quoted", func(t *testing.T) {
+		dg := NewGraph()
+		dg.AddCtor(&Ctor{ID: 1, Name: `New"Quoted"`, Package: "pkg"}, nil,
+			[]*Result{{Node: &Node{Type: type1}}})
+
+		var b bytes.Buffer
+		require.NoError(t, dg.WriteD2(&b))
+		assert.Contains(t, b.String(), `constructor: "New\"Quoted\""`)
+	})
+
+	t.Run("failed nodes are styled", func(t *testing.T) {
+		dg := NewGraph()
+		r1 := &Result{Node: &Node{Type: type1}}
+		dg.AddCtor(&Ctor{ID: 1, Name: "NewT1", Package: "pkg"},
+			[]*Param{{Node: &Node{Type: type2}}}, []*Result{r1})
+		dg.AddMissingNodes([]*Result{{Node: &Node{Type: type2}}})
+		dg.FailNodes([]*Result{r1}, 1)
+
+		var b bytes.Buffer
+		require.NoError(t, dg.WriteD2(&b))
+		assert.Equal(t, `direction: left
+cluster_0: "pkg" {
+	constructor: "NewT1" {shape: text}
+	node_0: "dot.t1" {shape: oval}
+}
+node_1: "dot.t2"
+cluster_0 -> node_1
+cluster_0.style.stroke: orange
+cluster_0.node_0.style.stroke: orange
+node_1.style.stroke: red
+`, b.String())
+	})
+}
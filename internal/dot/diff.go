@@ -0,0 +1,269 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffStatus tells whether an element of a GraphDiff is in both graphs or
+// in only one of them.
+type DiffStatus int
+
+const (
+	// Unchanged elements are in both graphs.
+	Unchanged DiffStatus = iota
+	// Added elements are only in the new graph.
+	Added
+	// Removed elements are only in the old graph.
+	Removed
+)
+
+// Color returns the color elements with the given status are drawn in.
+func (s DiffStatus) Color() string {
+	switch s {
+	case Added:
+		return "green"
+	case Removed:
+		return "red"
+	default:
+		return "black"
+	}
+}
+
+// DiffCtor is a constructor of a GraphDiff.
+type DiffCtor struct {
+	Name string
+
+	// Key identifies the constructor across graphs: constructors are the
+	// same if they have the same name and produce the same results.
+	Key    string
+	Status DiffStatus
+}
+
+// DiffEdge is an edge of a GraphDiff between a constructor and a value.
+// Values point to the constructor that produces them, and constructors to
+// the values they depend on.
+type DiffEdge struct {
+	From, To string
+	Status   DiffStatus
+}
+
+// GraphDiff is the difference between two graphs.
+type GraphDiff struct {
+	Ctors []*DiffCtor
+	Edges []*DiffEdge
+}
+
+// DiffGraph holds the constructors and edges of a graph that Diff
+// compares. It may be built from a Graph, or read from the JSON
+// serialization of a Graph, so that graphs may be compared across
+// programs.
+type DiffGraph struct {
+	ctors []*DiffCtor
+	edges map[diffEdgeKey]struct{}
+}
+
+type diffEdgeKey struct{ From, To string }
+
+func newDiffGraph() *DiffGraph {
+	return &DiffGraph{edges: make(map[diffEdgeKey]struct{})}
+}
+
+func (d *DiffGraph) addCtor(name, key string) {
+	d.ctors = append(d.ctors, &DiffCtor{Name: name, Key: key})
+}
+
+func (d *DiffGraph) addEdge(from, to string) {
+	d.edges[diffEdgeKey{From: from, To: to}] = struct{}{}
+}
+
+// DiffGraph returns the constructors and edges of the graph, to compare
+// it with Diff.
+func (dg *Graph) DiffGraph() *DiffGraph {
+	d := newDiffGraph()
+	for _, c := range dg.Ctors {
+		results := make([]string, len(c.Results))
+		for i, r := range c.Results {
+			results[i] = diffNode(r.Type.String(), r.Name, r.Group)
+		}
+		k := diffKey(c.Package, c.Name, results)
+		d.addCtor(c.Name, k)
+		for _, r := range results {
+			d.addEdge(r, k)
+		}
+		for _, p := range c.Params {
+			d.addEdge(k, diffNode(p.Type.String(), p.Name, ""))
+		}
+		for _, g := range c.GroupParams {
+			d.addEdge(k, diffNode(g.Type.String(), "", g.Name))
+		}
+	}
+	return d
+}
+
+// ReadDiffGraph reads the constructors and edges of a graph serialized to
+// JSON by Graph.MarshalJSON, to compare it with Diff.
+func ReadDiffGraph(r io.Reader) (*DiffGraph, error) {
+	var jg jsonGraph
+	if err := json.NewDecoder(r).Decode(&jg); err != nil {
+		return nil, err
+	}
+
+	d := newDiffGraph()
+	for _, c := range jg.Constructors {
+		results := make([]string, len(c.Results))
+		for i, r := range c.Results {
+			results[i] = diffNode(r.Type, r.Name, r.Group)
+		}
+		k := diffKey(c.Package, c.Name, results)
+		d.addCtor(c.Name, k)
+		for _, r := range results {
+			d.addEdge(r, k)
+		}
+		for _, p := range c.Params {
+			d.addEdge(k, diffNode(p.Type, p.Name, ""))
+		}
+		for _, g := range c.GroupParams {
+			d.addEdge(k, diffNode(g.Type, "", g.Group))
+		}
+	}
+	return d, nil
+}
+
+// Diff compares the constructors and the edges of two graphs.
+//
+// Constructors are listed in the order of the new graph, followed by the
+// constructors that were removed from the old graph. Edges are sorted.
+func Diff(old, new *DiffGraph) *GraphDiff {
+	oldCtors := make(map[string]struct{}, len(old.ctors))
+	for _, c := range old.ctors {
+		oldCtors[c.Key] = struct{}{}
+	}
+	newCtors := make(map[string]struct{}, len(new.ctors))
+	for _, c := range new.ctors {
+		newCtors[c.Key] = struct{}{}
+	}
+
+	var d GraphDiff
+	for _, c := range new.ctors {
+		status := Added
+		if _, ok := oldCtors[c.Key]; ok {
+			status = Unchanged
+		}
+		d.Ctors = append(d.Ctors, &DiffCtor{Name: c.Name, Key: c.Key, Status: status})
+	}
+	for _, c := range old.ctors {
+		if _, ok := newCtors[c.Key]; !ok {
+			d.Ctors = append(d.Ctors, &DiffCtor{Name: c.Name, Key: c.Key, Status: Removed})
+		}
+	}
+
+	for e := range new.edges {
+		status := Added
+		if _, ok := old.edges[e]; ok {
+			status = Unchanged
+		}
+		d.Edges = append(d.Edges, &DiffEdge{From: e.From, To: e.To, Status: status})
+	}
+	for e := range old.edges {
+		if _, ok := new.edges[e]; !ok {
+			d.Edges = append(d.Edges, &DiffEdge{From: e.From, To: e.To, Status: Removed})
+		}
+	}
+	sort.Slice(d.Edges, func(i, j int) bool {
+		if d.Edges[i].From != d.Edges[j].From {
+			return d.Edges[i].From < d.Edges[j].From
+		}
+		return d.Edges[i].To < d.Edges[j].To
+	})
+
+	return &d
+}
+
+// diffNode returns the node of a value a GraphDiff links to, given the
+// name of its type. All values of a value group are drawn as the group.
+func diffNode(t, name, group string) string {
+	switch {
+	case group != "":
+		return fmt.Sprintf("[type=%v group=%v]", t, group)
+	case name != "":
+		return fmt.Sprintf("%v[name=%v]", t, name)
+	default:
+		return t
+	}
+}
+
+func diffKey(pkg, name string, results []string) string {
+	return fmt.Sprintf("%v.%v(%v)", pkg, name, strings.Join(results, ", "))
+}
+
+// HasChanges reports whether the graphs compared by the GraphDiff differ.
+func (d *GraphDiff) HasChanges() bool {
+	for _, c := range d.Ctors {
+		if c.Status != Unchanged {
+			return true
+		}
+	}
+	for _, e := range d.Edges {
+		if e.Status != Unchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteDOT writes the diff to w in the DOT format. Added constructors and
+// edges are drawn in green, removed ones are drawn dashed in red.
+func (d *GraphDiff) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph {\n\trankdir=RL;\n")
+	for _, c := range d.Ctors {
+		fmt.Fprintf(bw, "\t%v [shape=box label=%v%v];\n",
+			strconv.Quote(c.Key), strconv.Quote(c.Name), diffAttributes(c.Status))
+	}
+	for _, e := range d.Edges {
+		var attrs string
+		if e.Status != Unchanged {
+			attrs = " [" + strings.TrimPrefix(diffAttributes(e.Status), " ") + "]"
+		}
+		fmt.Fprintf(bw, "\t%v -> %v%v;\n", strconv.Quote(e.From), strconv.Quote(e.To), attrs)
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+func diffAttributes(s DiffStatus) string {
+	switch s {
+	case Added:
+		return " color=" + s.Color()
+	case Removed:
+		return " color=" + s.Color() + " style=dashed"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	type1 := reflect.TypeOf(t1{})
+	type2 := reflect.TypeOf(t2{})
+	type3 := reflect.TypeOf(t3{})
+
+	newGraph := func(ctors ...*Ctor) *DiffGraph {
+		dg := NewGraph()
+		for _, c := range ctors {
+			dg.AddCtor(c, c.Params, c.Results)
+		}
+		return dg.DiffGraph()
+	}
+
+	ctor := func(id CtorID, name string, params []reflect.Type, result reflect.Type) *Ctor {
+		c := &Ctor{ID: id, Name: name, Package: "pkg"}
+		for _, p := range params {
+			c.Params = append(c.Params, &Param{Node: &Node{Type: p}})
+		}
+		c.Results = []*Result{{Node: &Node{Type: result}}}
+		return c
+	}
+
+	t.Parallel()
+
+	t.Run("no changes", func(t *testing.T) {
+		d := Diff(
+			newGraph(ctor(1, "NewT1", nil, type1)),
+			newGraph(ctor(2, "NewT1", nil, type1)),
+		)
+		assert.False(t, d.HasChanges())
+		require.Len(t, d.Ctors, 1)
+		assert.Equal(t, Unchanged, d.Ctors[0].Status)
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		d := Diff(
+			newGraph(
+				ctor(1, "NewT1", nil, type1),
+				ctor(2, "NewT2", []reflect.Type{type1}, type2),
+			),
+			newGraph(
+				ctor(3, "NewT1", nil, type1),
+				ctor(4, "NewT3", []reflect.Type{type1}, type3),
+			),
+		)
+		assert.True(t, d.HasChanges())
+
+		var b bytes.Buffer
+		require.NoError(t, d.WriteDOT(&b))
+		assert.Equal(t, `digraph {
+	rankdir=RL;
+	"pkg.NewT1(dot.t1)" [shape=box label="NewT1"];
+	"pkg.NewT3(dot.t3)" [shape=box label="NewT3" color=green];
+	"pkg.NewT2(dot.t2)" [shape=box label="NewT2" color=red style=dashed];
+	"dot.t1" -> "pkg.NewT1(dot.t1)";
+	"dot.t2" -> "pkg.NewT2(dot.t2)" [color=red style=dashed];
+	"dot.t3" -> "pkg.NewT3(dot.t3)" [color=green];
+	"pkg.NewT2(dot.t2)" -> "dot.t1" [color=red style=dashed];
+	"pkg.NewT3(dot.t3)" -> "dot.t1" [color=green];
+}
+`, b.String())
+	})
+	t.Run("serialized graphs", func(t *testing.T) {
+		dg := NewGraph()
+		c1 := ctor(1, "NewT1", nil, type1)
+		c1.Results[0].Name = "primary"
+		dg.AddCtor(c1, c1.Params, c1.Results)
+		c2 := &Ctor{ID: 2, Name: "NewT2", Package: "pkg"}
+		c2.Params = []*Param{{Node: &Node{Type: type1, Name: "primary"}}}
+		c2.Results = []*Result{{Node: &Node{Type: type2, Group: "twos"}}}
+		dg.AddCtor(c2, c2.Params, c2.Results)
+
+		b, err := dg.MarshalJSON()
+		require.NoError(t, err)
+		read, err := ReadDiffGraph(bytes.NewReader(b))
+		require.NoError(t, err)
+
+		assert.False(t, Diff(read, dg.DiffGraph()).HasChanges(),
+			"graphs read from JSON must match the graphs they were written from")
+		assert.True(t, Diff(read, newGraph(ctor(3, "NewT1", nil, type1))).HasChanges())
+
+		_, err = ReadDiffGraph(bytes.NewReader([]byte("{")))
+		assert.Error(t, err)
+	})
+}
@@ -23,6 +23,8 @@ package dot
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"time"
 )
 
 // ErrorType of a constructor or group is updated when they fail to build.
@@ -41,6 +43,7 @@ type CtorID uintptr
 type Ctor struct {
 	Name        string
 	Package     string
+	Module      string
 	File        string
 	Line        int
 	ID          CtorID
@@ -48,6 +51,50 @@ type Ctor struct {
 	GroupParams []*Group
 	Results     []*Result
 	ErrorType   ErrorType
+
+	// Supplied is true if the constructor stands for values supplied to the
+	// container as-is. Its results are drawn as value nodes.
+	Supplied bool
+
+	// Fallback is true if the values were supplied by the missing handler
+	// of the container, because no constructor provided them.
+	Fallback bool
+
+	// Cluster is the cluster the constructor is drawn in, if any.
+	Cluster *Cluster
+
+	// Duration is the time the constructor took to build its values, if
+	// it was measured, and Critical is true if the constructor is on the
+	// critical path of the graph.
+	Duration time.Duration
+	Critical bool
+}
+
+// Cluster groups constructors together in the DOT graph.
+type Cluster struct {
+	ID    string
+	Label string
+}
+
+// Decorator encodes a decorator registered to the container for the DOT
+// graph.
+type Decorator struct {
+	Name        string
+	Package     string
+	File        string
+	Line        int
+	ID          CtorID
+	Params      []*Param
+	GroupParams []*Group
+
+	// Results are the decorated values, and Groups the decorated value
+	// groups.
+	Results []*Result
+	Groups  []*Group
+
+	// Local is true if the decoration does not propagate to descendant
+	// scopes.
+	Local bool
 }
 
 // removeParam deletes the dependency on the provided result's nodeKey.
@@ -95,6 +142,10 @@ type Result struct {
 	// representations are the same so we need indices to uniquely identify
 	// the values.
 	GroupIndex int
+
+	// Flatten is true if the result is a slice whose elements are added to
+	// the group individually. Type is then the type of the elements.
+	Flatten bool
 }
 
 // Group is a group node in the graph. Group represents an fx value group.
@@ -126,6 +177,8 @@ type Graph struct {
 	Ctors   []*Ctor
 	ctorMap map[CtorID]*Ctor
 
+	Decorators []*Decorator
+
 	Groups   []*Group
 	groupMap map[nodeKey]*Group
 
@@ -145,6 +198,10 @@ type FailedNodes struct {
 	// missing/failed dependencies.
 	TransitiveFailures []*Result
 
+	// Suggestions links missing nodes to the values the user may have
+	// meant to depend on instead.
+	Suggestions []*Suggestion
+
 	// ctors is a collection of failed constructors IDs that are populated as the graph is
 	// traversed for errors.
 	ctors map[CtorID]struct{}
@@ -177,25 +234,7 @@ func NewGroup(k nodeKey) *Group {
 
 // AddCtor adds the constructor with paramList and resultList into the graph.
 func (dg *Graph) AddCtor(c *Ctor, paramList []*Param, resultList []*Result) {
-	var (
-		params      []*Param
-		groupParams []*Group
-	)
-
-	// Loop through the paramList to separate them into regular params and
-	// grouped params. For grouped params, we use getGroup to find the actual
-	// group.
-	for _, param := range paramList {
-		if param.Group == "" {
-			// Not a value group.
-			params = append(params, param)
-			continue
-		}
-
-		k := nodeKey{t: param.Type.Elem(), group: param.Group}
-		group := dg.getGroup(k)
-		groupParams = append(groupParams, group)
-	}
+	params, groupParams := dg.splitParams(paramList)
 
 	for _, result := range resultList {
 		// If the result is a grouped value, we want to update its GroupIndex
@@ -219,6 +258,41 @@ func (dg *Graph) AddCtor(c *Ctor, paramList []*Param, resultList []*Result) {
 	dg.ctorMap[c.ID] = c
 }
 
+// AddDecorator adds the decorator with paramList and resultList into the
+// graph.
+func (dg *Graph) AddDecorator(d *Decorator, paramList []*Param, resultList []*Result) {
+	d.Params, d.GroupParams = dg.splitParams(paramList)
+	d.Results, d.Groups = nil, nil
+	for _, result := range resultList {
+		if result.Group == "" {
+			d.Results = append(d.Results, result)
+			continue
+		}
+		// Value groups are decorated as a whole.
+		k := nodeKey{t: result.Type.Elem(), group: result.Group}
+		d.Groups = append(d.Groups, dg.getGroup(k))
+	}
+
+	dg.Decorators = append(dg.Decorators, d)
+}
+
+// splitParams separates paramList into regular params and grouped params.
+// For grouped params, getGroup is used to find the actual group.
+func (dg *Graph) splitParams(paramList []*Param) (params []*Param, groupParams []*Group) {
+	for _, param := range paramList {
+		if param.Group == "" {
+			// Not a value group.
+			params = append(params, param)
+			continue
+		}
+
+		k := nodeKey{t: param.Type.Elem(), group: param.Group}
+		group := dg.getGroup(k)
+		groupParams = append(groupParams, group)
+	}
+	return params, groupParams
+}
+
 func (dg *Graph) failNode(r *Result, isRootCause bool) {
 	if isRootCause {
 		dg.addRootCause(r)
@@ -237,6 +311,24 @@ func (dg *Graph) AddMissingNodes(results []*Result) {
 	}
 }
 
+// Suggestion links a missing Result to a Result that is in the graph and
+// that the user may have meant instead.
+type Suggestion struct {
+	Missing   *Result
+	Suggested *Result
+}
+
+// AddSuggestions records that the given missing node may have been meant as
+// one of the suggested nodes.
+func (dg *Graph) AddSuggestions(missing *Result, suggested []*Result) {
+	for _, r := range suggested {
+		dg.Failed.Suggestions = append(dg.Failed.Suggestions, &Suggestion{
+			Missing:   missing,
+			Suggested: r,
+		})
+	}
+}
+
 // FailNodes adds results to the list of failed Results in the graph, and
 // updates the state of the constructor with the given id accordingly.
 func (dg *Graph) FailNodes(results []*Result, id CtorID) {
@@ -317,9 +409,220 @@ func (dg *Graph) addToGroup(r *Result, id CtorID) {
 // PruneSuccess removes elements from the graph that do not have failed results.
 // Removing elements that do not have failing results makes the graph easier to debug,
 // since non-failing nodes and edges can clutter the graph and don't help the user debug.
+//
+// Decorators are not tracked for failures and are always removed.
 func (dg *Graph) PruneSuccess() {
 	dg.pruneCtors(dg.Failed.ctors)
 	dg.pruneGroups(dg.Failed.groups)
+	dg.Decorators = nil
+}
+
+// Focus removes from the graph the constructors, decorators and groups that
+// are neither ancestors nor descendants of the values of type t. Ancestors are
+// the constructors t transitively depends on, and descendants the ones that
+// transitively depend on t.
+//
+// If depth is positive, only the constructors at most depth constructors
+// away from t are kept.
+func (dg *Graph) Focus(t reflect.Type, depth int) {
+	producers := dg.producers()
+
+	var focused []nodeKey
+	for k := range producers {
+		if k.t == t {
+			focused = append(focused, k)
+		}
+	}
+	for k := range dg.consumers {
+		if k.t == t && k.group == "" {
+			focused = append(focused, k)
+		}
+	}
+
+	keys := make(map[nodeKey]struct{})
+	kept := make(map[CtorID]struct{})
+
+	// Walk up the graph to the constructors that t depends on.
+	dg.walkDependencies(focused, depth, keys, kept, producers)
+
+	// Walk down the graph to the constructors that depend on t.
+	dg.walk(focused, depth, keys, kept, func(k nodeKey) []*Ctor {
+		if k.group != "" {
+			// Group consumers are tracked with the slice type.
+			k.t = reflect.SliceOf(k.t)
+		}
+		return dg.consumers[k]
+	}, func(c *Ctor) (next []nodeKey) {
+		for _, r := range c.Results {
+			next = append(next, r.nodeKey())
+		}
+		return next
+	})
+
+	dg.prune(keys, kept)
+}
+
+// FocusParams removes from the graph the constructors, decorators and groups
+// that the given parameters, such as the parameters of a function given to
+// Invoke, don't transitively depend on.
+//
+// If depth is positive, only the constructors at most depth constructors
+// away from the parameters are kept.
+func (dg *Graph) FocusParams(paramList []*Param, depth int) {
+	params, groupParams := dg.splitParams(paramList)
+	var from []nodeKey
+	for _, p := range params {
+		from = append(from, p.nodeKey())
+	}
+	for _, g := range groupParams {
+		from = append(from, g.nodeKey())
+	}
+
+	keys := make(map[nodeKey]struct{})
+	kept := make(map[CtorID]struct{})
+	dg.walkDependencies(from, depth, keys, kept, dg.producers())
+	dg.prune(keys, kept)
+}
+
+// producers returns the constructors producing each of the values of the
+// graph.
+func (dg *Graph) producers() map[nodeKey][]*Ctor {
+	producers := make(map[nodeKey][]*Ctor)
+	for _, c := range dg.Ctors {
+		for _, r := range c.Results {
+			k := r.nodeKey()
+			producers[k] = append(producers[k], c)
+		}
+	}
+	return producers
+}
+
+// walkDependencies walks up the graph from the given keys to the
+// constructors they transitively depend on.
+func (dg *Graph) walkDependencies(
+	from []nodeKey,
+	depth int,
+	keys map[nodeKey]struct{},
+	kept map[CtorID]struct{},
+	producers map[nodeKey][]*Ctor,
+) {
+	dg.walk(from, depth, keys, kept, func(k nodeKey) []*Ctor {
+		return producers[k]
+	}, func(c *Ctor) (next []nodeKey) {
+		for _, p := range c.Params {
+			next = append(next, p.nodeKey())
+		}
+		for _, g := range c.GroupParams {
+			next = append(next, g.nodeKey())
+		}
+		return next
+	})
+}
+
+// prune removes from the graph the constructors that were not kept, and the
+// groups, decorators and failures that are not about the given keys.
+func (dg *Graph) prune(keys map[nodeKey]struct{}, kept map[CtorID]struct{}) {
+	dg.pruneCtors(kept)
+	dg.pruneGroups(keys)
+
+	var decorators []*Decorator
+	for _, d := range dg.Decorators {
+		if d.touches(keys) {
+			decorators = append(decorators, d)
+		}
+	}
+	dg.Decorators = decorators
+
+	dg.Failed.RootCauses = filterResults(dg.Failed.RootCauses, keys)
+	dg.Failed.TransitiveFailures = filterResults(dg.Failed.TransitiveFailures, keys)
+
+	var suggestions []*Suggestion
+	for _, s := range dg.Failed.Suggestions {
+		if _, ok := keys[s.Missing.nodeKey()]; ok {
+			suggestions = append(suggestions, s)
+		}
+	}
+	dg.Failed.Suggestions = suggestions
+}
+
+// walk does a breadth first traversal of the graph from the given keys,
+// recording the keys and constructors it visits. ctors returns the
+// constructors reached from a key, and next the keys reached from a
+// constructor.
+func (dg *Graph) walk(
+	from []nodeKey,
+	depth int,
+	keys map[nodeKey]struct{},
+	kept map[CtorID]struct{},
+	ctors func(nodeKey) []*Ctor,
+	next func(*Ctor) []nodeKey,
+) {
+	visited := make(map[CtorID]struct{})
+	for level := 1; len(from) > 0 && (depth <= 0 || level <= depth); level++ {
+		var frontier []nodeKey
+		for _, k := range from {
+			keys[k] = struct{}{}
+			for _, c := range ctors(k) {
+				if _, ok := visited[c.ID]; ok {
+					continue
+				}
+				visited[c.ID] = struct{}{}
+				kept[c.ID] = struct{}{}
+				frontier = append(frontier, next(c)...)
+			}
+		}
+		from = frontier
+	}
+	for _, k := range from {
+		keys[k] = struct{}{}
+	}
+}
+
+// touches reports whether the decorator decorates one of the given keys.
+func (d *Decorator) touches(keys map[nodeKey]struct{}) bool {
+	for _, r := range d.Results {
+		if _, ok := keys[r.nodeKey()]; ok {
+			return true
+		}
+	}
+	for _, g := range d.Groups {
+		if _, ok := keys[g.nodeKey()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func filterResults(results []*Result, keys map[nodeKey]struct{}) []*Result {
+	var filtered []*Result
+	for _, r := range results {
+		if _, ok := keys[r.nodeKey()]; ok {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// ClusterBy puts the constructors of the graph in clusters. key returns the
+// label of the cluster of the given constructor, or an empty string to leave
+// it out of clusters.
+func (dg *Graph) ClusterBy(key func(*Ctor) string) {
+	clusters := make(map[string]*Cluster)
+	for _, c := range dg.Ctors {
+		label := key(c)
+		if label == "" {
+			continue
+		}
+		cl, ok := clusters[label]
+		if !ok {
+			cl = &Cluster{
+				ID:    fmt.Sprintf("cluster_group_%d", len(clusters)),
+				Label: label,
+			}
+			clusters[label] = cl
+		}
+		c.Cluster = cl
+	}
 }
 
 // pruneCtors removes constructors from the graph that do not have failing Results.
@@ -410,10 +713,10 @@ func (p *Param) String() string {
 // String implements fmt.Stringer for Result.
 func (r *Result) String() string {
 	switch {
-	case r.Name != "":
-		return fmt.Sprintf("%v[name=%v]", r.Type.String(), r.Name)
 	case r.Group != "":
 		return fmt.Sprintf("%v[group=%v]%v", r.Type.String(), r.Group, r.GroupIndex)
+	case r.Name != "":
+		return fmt.Sprintf("%v[name=%v]", r.Type.String(), r.Name)
 	default:
 		return r.Type.String()
 	}
@@ -424,11 +727,32 @@ func (g *Group) String() string {
 	return fmt.Sprintf("[type=%v group=%v]", g.Type.String(), g.Name)
 }
 
+// Label returns the label of the cluster holding the constructor.
+func (c *Ctor) Label() string {
+	if c.Module == "" {
+		return c.Package
+	}
+	return fmt.Sprintf("%v (module %v)", c.Package, c.Module)
+}
+
+// Caption returns the text drawn for the constructor: its name, followed
+// by its duration if it was measured.
+func (c *Ctor) Caption() string {
+	if c.Duration <= 0 {
+		return c.Name
+	}
+	return fmt.Sprintf("%v (%v)", c.Name, c.Duration)
+}
+
 // Attributes composes and returns a string of the Result node's attributes.
 func (r *Result) Attributes() string {
 	switch {
+	case r.Group != "" && r.Name != "":
+		return fmt.Sprintf(`label=<%v<BR /><FONT POINT-SIZE="10">Group: %v, Name: %v</FONT>>`, r.Type, r.Group, r.Name)
 	case r.Name != "":
 		return fmt.Sprintf(`label=<%v<BR /><FONT POINT-SIZE="10">Name: %v</FONT>>`, r.Type, r.Name)
+	case r.Group != "" && r.Flatten:
+		return fmt.Sprintf(`label=<[]%v<BR /><FONT POINT-SIZE="10">Group: %v (flattened)</FONT>>`, r.Type, r.Group)
 	case r.Group != "":
 		return fmt.Sprintf(`label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, r.Type, r.Group)
 	default:
@@ -438,13 +762,37 @@ func (r *Result) Attributes() string {
 
 // Attributes composes and returns a string of the Group node's attributes.
 func (g *Group) Attributes() string {
-	attr := fmt.Sprintf(`shape=diamond label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, g.Type, g.Name)
+	attr := "shape=diamond " + g.label()
 	if g.ErrorType != noError {
 		attr += " color=" + g.ErrorType.Color()
 	}
 	return attr
 }
 
+// label returns the label attribute of the Group node.
+func (g *Group) label() string {
+	return fmt.Sprintf(`label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, g.Type, g.Name)
+}
+
+// EdgeAttributes composes and returns a string of the attributes of the edges
+// between the Decorator and the values it decorates.
+func (d *Decorator) EdgeAttributes() string {
+	attr := "style=bold color=blue arrowhead=odiamond"
+	if label := d.edgeLabel(); label != "" {
+		attr += " label=" + strconv.Quote(label)
+	}
+	return attr
+}
+
+// edgeLabel returns the label of the edges between the Decorator and the
+// values it decorates.
+func (d *Decorator) edgeLabel() string {
+	if d.Local {
+		return "local"
+	}
+	return ""
+}
+
 // Color returns the color representation of each ErrorType.
 func (s ErrorType) Color() string {
 	switch s {
@@ -23,6 +23,7 @@ package dot
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -374,6 +375,127 @@ func TestPruneSuccess(t *testing.T) {
 	})
 }
 
+func TestFocus(t *testing.T) {
+	type1 := reflect.TypeOf(t1{})
+	type2 := reflect.TypeOf(t2{})
+	type3 := reflect.TypeOf(t3{})
+
+	newGraph := func() *Graph {
+		dg := NewGraph()
+		dg.AddCtor(&Ctor{ID: 1}, nil, []*Result{{Node: &Node{Type: type1, Group: "foo"}}})
+		dg.AddCtor(&Ctor{ID: 2},
+			[]*Param{{Node: &Node{Type: reflect.SliceOf(type1), Group: "foo"}}},
+			[]*Result{{Node: &Node{Type: type2}}})
+		dg.AddCtor(&Ctor{ID: 3},
+			[]*Param{{Node: &Node{Type: type2}}},
+			[]*Result{{Node: &Node{Type: type3}}})
+		return dg
+	}
+
+	ids := func(dg *Graph) []CtorID {
+		var ids []CtorID
+		for _, c := range dg.Ctors {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	t.Parallel()
+
+	t.Run("ancestors through groups", func(t *testing.T) {
+		dg := newGraph()
+		dg.Focus(type2, 0)
+
+		assert.Equal(t, []CtorID{1, 2, 3}, ids(dg))
+		assert.Len(t, dg.Groups, 1)
+	})
+
+	t.Run("descendants through groups", func(t *testing.T) {
+		dg := newGraph()
+		dg.Focus(type1, 1)
+
+		assert.Equal(t, []CtorID{1, 2}, ids(dg))
+		assert.Len(t, dg.Groups, 1)
+	})
+
+	t.Run("limited depth", func(t *testing.T) {
+		dg := newGraph()
+		dg.Focus(type3, 1)
+
+		assert.Equal(t, []CtorID{3}, ids(dg))
+		assert.Empty(t, dg.Groups)
+		assert.Empty(t, dg.Ctors[0].Params)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		dg := newGraph()
+		dg.Focus(reflect.TypeOf(0), 0)
+
+		assert.Empty(t, dg.Ctors)
+		assert.Empty(t, dg.Groups)
+	})
+}
+
+func TestFocusParams(t *testing.T) {
+	type1 := reflect.TypeOf(t1{})
+	type2 := reflect.TypeOf(t2{})
+	type3 := reflect.TypeOf(t3{})
+
+	newGraph := func() *Graph {
+		dg := NewGraph()
+		dg.AddCtor(&Ctor{ID: 1}, nil, []*Result{{Node: &Node{Type: type1, Group: "foo"}}})
+		dg.AddCtor(&Ctor{ID: 2},
+			[]*Param{{Node: &Node{Type: reflect.SliceOf(type1), Group: "foo"}}},
+			[]*Result{{Node: &Node{Type: type2}}})
+		dg.AddCtor(&Ctor{ID: 3},
+			[]*Param{{Node: &Node{Type: type2}}},
+			[]*Result{{Node: &Node{Type: type3}}})
+		return dg
+	}
+
+	ids := func(dg *Graph) []CtorID {
+		var ids []CtorID
+		for _, c := range dg.Ctors {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	t.Parallel()
+
+	t.Run("dependencies only", func(t *testing.T) {
+		dg := newGraph()
+		dg.FocusParams([]*Param{{Node: &Node{Type: type2}}}, 0)
+
+		assert.Equal(t, []CtorID{1, 2}, ids(dg))
+		assert.Len(t, dg.Groups, 1)
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		dg := newGraph()
+		dg.FocusParams([]*Param{{Node: &Node{Type: reflect.SliceOf(type1), Group: "foo"}}}, 0)
+
+		assert.Equal(t, []CtorID{1}, ids(dg))
+		assert.Len(t, dg.Groups, 1)
+	})
+
+	t.Run("limited depth", func(t *testing.T) {
+		dg := newGraph()
+		dg.FocusParams([]*Param{{Node: &Node{Type: type3}}}, 1)
+
+		assert.Equal(t, []CtorID{3}, ids(dg))
+		assert.Empty(t, dg.Groups)
+	})
+
+	t.Run("no parameters", func(t *testing.T) {
+		dg := newGraph()
+		dg.FocusParams(nil, 0)
+
+		assert.Empty(t, dg.Ctors)
+		assert.Empty(t, dg.Groups)
+	})
+}
+
 func TestGetGroup(t *testing.T) {
 	type1 := reflect.TypeOf(t1{})
 	type2 := reflect.TypeOf(t2{})
@@ -446,6 +568,30 @@ func TestStringerAndAttribute(t *testing.T) {
 		assert.Equal(t, `shape=diamond label=<dot.t2<BR /><FONT POINT-SIZE="10">Group: group2</FONT>> color=red`, g2.Attributes())
 		assert.Equal(t, `shape=diamond label=<dot.t3<BR /><FONT POINT-SIZE="10">Group: group3</FONT>> color=orange`, g3.Attributes())
 	})
+
+	t.Run("default styles", func(t *testing.T) {
+		s := NewGraph().Styles(nil)
+		assert.Equal(t, `shape=diamond label=<dot.t1<BR /><FONT POINT-SIZE="10">Group: group1</FONT>>`, s.Group(g1))
+		assert.Equal(t, `shape=diamond label=<dot.t2<BR /><FONT POINT-SIZE="10">Group: group2</FONT>> color=red`, s.Group(g2))
+		assert.Equal(t, `shape=diamond label=<dot.t3<BR /><FONT POINT-SIZE="10">Group: group3</FONT>> color=orange`, s.Group(g3))
+	})
+
+	t.Run("styled attributes", func(t *testing.T) {
+		s := NewGraph().Styles(func(e Element, st *Style) {
+			assert.Equal(t, GroupElement, e.Kind)
+			assert.Equal(t, e.Group == g2, e.RootCause())
+			st.Shape = ""
+			st.Color = "#ff0000"
+			st.Tooltip = `the "second" group`
+		})
+		assert.Equal(t, `label=<dot.t2<BR /><FONT POINT-SIZE="10">Group: group2</FONT>> color="#ff0000" tooltip="the \"second\" group"`, s.Group(g2))
+		assert.Equal(t, `label=<dot.t1<BR /><FONT POINT-SIZE="10">Group: group1</FONT>> color="#ff0000" tooltip="the \"second\" group"`, s.Group(g1))
+	})
+
+	t.Run("ctor caption", func(t *testing.T) {
+		assert.Equal(t, "NewT1", (&Ctor{Name: "NewT1"}).Caption())
+		assert.Equal(t, "NewT1 (1.5ms)", (&Ctor{Name: "NewT1", Duration: 1500 * time.Microsecond}).Caption())
+	})
 }
 
 func TestColor(t *testing.T) {
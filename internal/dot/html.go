@@ -0,0 +1,43 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	_ "embed" // for the HTML page template
+	"html/template"
+	"io"
+)
+
+//go:embed html.tmpl
+var _htmlTmplText string
+
+var _htmlTmpl = template.Must(template.New("HTMLGraph").Parse(_htmlTmplText))
+
+// WriteHTML writes the graph to w as a self-contained HTML page.
+//
+// The page embeds the JSON representation of the graph along with a small
+// viewer that lays constructors and value groups out in columns by depth.
+// The graph may be zoomed and panned, searched by type name, and clicking a
+// node highlights the nodes it transitively depends on and the ones that
+// transitively depend on it.
+func (dg *Graph) WriteHTML(w io.Writer) error {
+	return _htmlTmpl.Execute(w, dg)
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import "encoding/json"
+
+// jsonGraph is the serialized form of a Graph.
+//
+// Types are encoded by their string representation and constructors are
+// referred to by their index in Constructors so that the output is stable
+// across runs of the same program.
+type jsonGraph struct {
+	Constructors []jsonCtor      `json:"constructors"`
+	Decorators   []jsonDecorator `json:"decorators,omitempty"`
+	Groups       []jsonGroup     `json:"groups"`
+	Failed       *jsonFailed     `json:"failed,omitempty"`
+}
+
+type jsonCtor struct {
+	Name        string        `json:"name"`
+	Package     string        `json:"package"`
+	Module      string        `json:"module,omitempty"`
+	Supplied    bool          `json:"supplied,omitempty"`
+	Fallback    bool          `json:"fallback,omitempty"`
+	File        string        `json:"file"`
+	Line        int           `json:"line"`
+	Params      []jsonParam   `json:"params"`
+	GroupParams []jsonNode    `json:"groupParams"`
+	Results     []jsonNode    `json:"results"`
+	Error       jsonErrorType `json:"error,omitempty"`
+}
+
+type jsonDecorator struct {
+	Name        string      `json:"name"`
+	Package     string      `json:"package"`
+	File        string      `json:"file"`
+	Line        int         `json:"line"`
+	Params      []jsonParam `json:"params"`
+	GroupParams []jsonNode  `json:"groupParams"`
+	Results     []jsonNode  `json:"results"`
+	Groups      []jsonNode  `json:"groups"`
+	Local       bool        `json:"local,omitempty"`
+}
+
+type jsonNode struct {
+	Type    string `json:"type"`
+	Name    string `json:"name,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Flatten bool   `json:"flatten,omitempty"`
+}
+
+type jsonParam struct {
+	jsonNode
+
+	Optional bool `json:"optional,omitempty"`
+}
+
+type jsonGroup struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+
+	// Constructors holds the index of the constructor that produced each
+	// value of the group.
+	Constructors []int         `json:"constructors"`
+	Error        jsonErrorType `json:"error,omitempty"`
+}
+
+type jsonFailed struct {
+	RootCauses         []jsonNode `json:"rootCauses"`
+	TransitiveFailures []jsonNode `json:"transitiveFailures"`
+}
+
+type jsonErrorType ErrorType
+
+func (e jsonErrorType) MarshalText() ([]byte, error) {
+	switch ErrorType(e) {
+	case rootCause:
+		return []byte("rootCause"), nil
+	case transitiveFailure:
+		return []byte("transitiveFailure"), nil
+	default:
+		return nil, nil
+	}
+}
+
+// MarshalJSON implements json.Marshaler for Graph.
+func (dg *Graph) MarshalJSON() ([]byte, error) {
+	jg := jsonGraph{
+		Constructors: make([]jsonCtor, 0, len(dg.Ctors)),
+		Groups:       make([]jsonGroup, 0, len(dg.Groups)),
+	}
+
+	producers := make(map[*Result]int)
+	for i, c := range dg.Ctors {
+		jc := jsonCtor{
+			Name:        c.Name,
+			Package:     c.Package,
+			Module:      c.Module,
+			Supplied:    c.Supplied,
+			Fallback:    c.Fallback,
+			File:        c.File,
+			Line:        c.Line,
+			Params:      newJSONParams(c.Params),
+			GroupParams: newJSONGroupNodes(c.GroupParams),
+			Results:     make([]jsonNode, 0, len(c.Results)),
+			Error:       jsonErrorType(c.ErrorType),
+		}
+		for _, r := range c.Results {
+			producers[r] = i
+			jn := newJSONNode(r.Node)
+			jn.Flatten = r.Flatten
+			jc.Results = append(jc.Results, jn)
+		}
+		jg.Constructors = append(jg.Constructors, jc)
+	}
+
+	for _, d := range dg.Decorators {
+		jg.Decorators = append(jg.Decorators, jsonDecorator{
+			Name:        d.Name,
+			Package:     d.Package,
+			File:        d.File,
+			Line:        d.Line,
+			Params:      newJSONParams(d.Params),
+			GroupParams: newJSONGroupNodes(d.GroupParams),
+			Results:     newJSONNodes(d.Results),
+			Groups:      newJSONGroupNodes(d.Groups),
+			Local:       d.Local,
+		})
+	}
+
+	for _, g := range dg.Groups {
+		jgr := jsonGroup{
+			Type:         g.Type.String(),
+			Name:         g.Name,
+			Constructors: make([]int, 0, len(g.Results)),
+			Error:        jsonErrorType(g.ErrorType),
+		}
+		for _, r := range g.Results {
+			if i, ok := producers[r]; ok {
+				jgr.Constructors = append(jgr.Constructors, i)
+			}
+		}
+		jg.Groups = append(jg.Groups, jgr)
+	}
+
+	if f := dg.Failed; len(f.RootCauses) > 0 || len(f.TransitiveFailures) > 0 {
+		jg.Failed = &jsonFailed{
+			RootCauses:         newJSONNodes(f.RootCauses),
+			TransitiveFailures: newJSONNodes(f.TransitiveFailures),
+		}
+	}
+
+	return json.Marshal(jg)
+}
+
+func newJSONNode(n *Node) jsonNode {
+	return jsonNode{
+		Type:  n.Type.String(),
+		Name:  n.Name,
+		Group: n.Group,
+	}
+}
+
+func newJSONNodes(results []*Result) []jsonNode {
+	nodes := make([]jsonNode, 0, len(results))
+	for _, r := range results {
+		nodes = append(nodes, newJSONNode(r.Node))
+	}
+	return nodes
+}
+
+func newJSONParams(params []*Param) []jsonParam {
+	nodes := make([]jsonParam, 0, len(params))
+	for _, p := range params {
+		nodes = append(nodes, jsonParam{
+			jsonNode: newJSONNode(p.Node),
+			Optional: p.Optional,
+		})
+	}
+	return nodes
+}
+
+func newJSONGroupNodes(groups []*Group) []jsonNode {
+	nodes := make([]jsonNode, 0, len(groups))
+	for _, g := range groups {
+		nodes = append(nodes, jsonNode{
+			Type:  g.Type.String(),
+			Group: g.Name,
+		})
+	}
+	return nodes
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMermaid writes the graph to w as a Mermaid flowchart.
+//
+// The flowchart holds the same nodes and edges as the DOT representation of
+// the graph: constructors are subgraphs holding the results they produce,
+// supplied values are parallelograms, value groups are rhombuses, decorators
+// are linked to the values they decorate with thick edges, and failed nodes
+// are outlined in red or orange.
+func (dg *Graph) WriteMermaid(w io.Writer) error {
+	mw := newMermaidWriter(w)
+	mw.write(dg)
+	return mw.Flush()
+}
+
+type mermaidWriter struct {
+	*bufio.Writer
+
+	// Mermaid node IDs must be plain identifiers. ids maps the string
+	// representation of each node to the ID it was assigned.
+	ids map[string]string
+}
+
+func newMermaidWriter(w io.Writer) *mermaidWriter {
+	return &mermaidWriter{
+		Writer: bufio.NewWriter(w),
+		ids:    make(map[string]string),
+	}
+}
+
+func (mw *mermaidWriter) write(dg *Graph) {
+	mw.WriteString("flowchart RL\n")
+
+	// Declare all nodes before any edge so that results are placed in the
+	// subgraph of their constructor.
+	for i, c := range dg.Ctors {
+		if c.Supplied {
+			for _, r := range c.Results {
+				mw.declare(r.String(), "\t%v[/%v/]\n", mermaidResultLabel(r))
+			}
+			continue
+		}
+		fmt.Fprintf(mw, "\tsubgraph cluster_%d [%v]\n", i, mermaidLabel(c.Label()))
+		fmt.Fprintf(mw, "\t\tconstructor_%d[%v]\n", i, mermaidLabel(c.Name))
+		for _, r := range c.Results {
+			mw.declare(r.String(), "\t\t%v([%v])\n", mermaidResultLabel(r))
+		}
+		mw.WriteString("\tend\n")
+	}
+	for _, g := range dg.Groups {
+		mw.declare(g.String(), "\t%v{%v}\n",
+			mermaidLabel(fmt.Sprintf("%v<br/>Group: %v", g.Type, g.Name)))
+	}
+	for _, c := range dg.Ctors {
+		for _, p := range c.Params {
+			mw.declare(p.String(), "\t%v[%v]\n", mermaidLabel(p.String()))
+		}
+	}
+	for i, d := range dg.Decorators {
+		fmt.Fprintf(mw, "\tdecorator_%d[[%v]]\n", i, mermaidLabel(d.Name))
+		for _, p := range d.Params {
+			mw.declare(p.String(), "\t%v[%v]\n", mermaidLabel(p.String()))
+		}
+		for _, r := range d.Results {
+			mw.declare(r.String(), "\t%v([%v])\n", mermaidResultLabel(r))
+		}
+		for _, g := range d.Groups {
+			mw.declare(g.String(), "\t%v{%v}\n",
+				mermaidLabel(fmt.Sprintf("%v<br/>Group: %v", g.Type, g.Name)))
+		}
+	}
+	for _, r := range dg.Failed.TransitiveFailures {
+		mw.declare(r.String(), "\t%v[%v]\n", mermaidLabel(r.String()))
+	}
+	for _, r := range dg.Failed.RootCauses {
+		mw.declare(r.String(), "\t%v[%v]\n", mermaidLabel(r.String()))
+	}
+
+	for _, g := range dg.Groups {
+		for _, r := range g.Results {
+			fmt.Fprintf(mw, "\t%v --> %v\n", mw.ids[g.String()], mw.ids[r.String()])
+		}
+	}
+	for i, c := range dg.Ctors {
+		for _, p := range c.Params {
+			arrow := "-->"
+			if p.Optional {
+				arrow = "-.->"
+			}
+			fmt.Fprintf(mw, "\tcluster_%d %v %v\n", i, arrow, mw.ids[p.String()])
+		}
+		for _, g := range c.GroupParams {
+			fmt.Fprintf(mw, "\tcluster_%d --> %v\n", i, mw.ids[g.String()])
+		}
+	}
+
+	for i, d := range dg.Decorators {
+		for _, p := range d.Params {
+			arrow := "-->"
+			if p.Optional {
+				arrow = "-.->"
+			}
+			fmt.Fprintf(mw, "\tdecorator_%d %v %v\n", i, arrow, mw.ids[p.String()])
+		}
+		for _, g := range d.GroupParams {
+			fmt.Fprintf(mw, "\tdecorator_%d --> %v\n", i, mw.ids[g.String()])
+		}
+
+		// Decorations are drawn with thick edges to tell them apart from
+		// dependencies.
+		arrow := "==>"
+		if d.Local {
+			arrow = "==>|local|"
+		}
+		for _, r := range d.Results {
+			fmt.Fprintf(mw, "\tdecorator_%d %v %v\n", i, arrow, mw.ids[r.String()])
+		}
+		for _, g := range d.Groups {
+			fmt.Fprintf(mw, "\tdecorator_%d %v %v\n", i, arrow, mw.ids[g.String()])
+		}
+	}
+
+	for i, c := range dg.Ctors {
+		if c.ErrorType != noError && !c.Supplied {
+			fmt.Fprintf(mw, "\tstyle cluster_%d stroke:%v\n", i, c.ErrorType.Color())
+		}
+	}
+	for _, g := range dg.Groups {
+		if g.ErrorType != noError {
+			fmt.Fprintf(mw, "\tstyle %v stroke:%v\n", mw.ids[g.String()], g.ErrorType.Color())
+		}
+	}
+	for _, r := range dg.Failed.TransitiveFailures {
+		fmt.Fprintf(mw, "\tstyle %v stroke:%v\n", mw.ids[r.String()], transitiveFailure.Color())
+	}
+	for _, r := range dg.Failed.RootCauses {
+		fmt.Fprintf(mw, "\tstyle %v stroke:%v\n", mw.ids[r.String()], rootCause.Color())
+	}
+}
+
+// declare assigns an ID to the node with the given string representation
+// and writes its declaration using format, unless it was already declared.
+// format receives the ID and the label of the node.
+func (mw *mermaidWriter) declare(node string, format string, label string) {
+	if _, ok := mw.ids[node]; ok {
+		return
+	}
+	id := fmt.Sprintf("node_%d", len(mw.ids))
+	mw.ids[node] = id
+	fmt.Fprintf(mw, format, id, label)
+}
+
+func mermaidResultLabel(r *Result) string {
+	switch {
+	case r.Group != "" && r.Name != "":
+		return mermaidLabel(fmt.Sprintf("%v<br/>Group: %v, Name: %v", r.Type, r.Group, r.Name))
+	case r.Name != "":
+		return mermaidLabel(fmt.Sprintf("%v<br/>Name: %v", r.Type, r.Name))
+	case r.Group != "":
+		return mermaidLabel(fmt.Sprintf("%v<br/>Group: %v", r.Type, r.Group))
+	default:
+		return mermaidLabel(r.Type.String())
+	}
+}
+
+// mermaidLabel quotes s for use as a Mermaid label.
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
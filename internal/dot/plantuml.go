@@ -0,0 +1,208 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WritePlantUML writes the graph to w as a PlantUML component diagram.
+//
+// The diagram holds the same nodes and edges as the DOT representation of
+// the graph: constructors are packages holding the components they produce,
+// supplied values are artifacts, value groups are collections, decorators
+// are linked to the values they decorate with bold edges, and failed nodes
+// are outlined in red or orange.
+func (dg *Graph) WritePlantUML(w io.Writer) error {
+	pw := newPlantUMLWriter(w, dg)
+	pw.write(dg)
+	return pw.Flush()
+}
+
+type plantUMLWriter struct {
+	*bufio.Writer
+
+	// PlantUML aliases must be plain identifiers. ids maps the string
+	// representation of each node to the alias it was assigned.
+	ids map[string]string
+
+	// PlantUML elements are styled when they are declared. failed maps the
+	// string representation of failed nodes to their color.
+	failed map[string]string
+}
+
+func newPlantUMLWriter(w io.Writer, dg *Graph) *plantUMLWriter {
+	failed := make(map[string]string)
+	for _, r := range dg.Failed.TransitiveFailures {
+		failed[r.String()] = transitiveFailure.Color()
+	}
+	for _, r := range dg.Failed.RootCauses {
+		failed[r.String()] = rootCause.Color()
+	}
+	for _, g := range dg.Groups {
+		if g.ErrorType != noError {
+			failed[g.String()] = g.ErrorType.Color()
+		}
+	}
+
+	return &plantUMLWriter{
+		Writer: bufio.NewWriter(w),
+		ids:    make(map[string]string),
+		failed: failed,
+	}
+}
+
+func (pw *plantUMLWriter) write(dg *Graph) {
+	pw.WriteString("@startuml\n")
+	pw.WriteString("left to right direction\n")
+
+	for i, c := range dg.Ctors {
+		if c.Supplied {
+			for _, r := range c.Results {
+				pw.declare(r.String(), "artifact %v as %v%v\n", plantUMLResultLabel(r))
+			}
+			continue
+		}
+		var style string
+		if c.ErrorType != noError {
+			style = " #line:" + c.ErrorType.Color()
+		}
+		fmt.Fprintf(pw, "package %v as cluster_%d%v {\n", plantUMLLabel(c.Label()), i, style)
+		fmt.Fprintf(pw, "\trectangle %v as constructor_%d\n", plantUMLLabel(c.Name), i)
+		for _, r := range c.Results {
+			pw.declare(r.String(), "\tcomponent %v as %v%v\n", plantUMLResultLabel(r))
+		}
+		pw.WriteString("}\n")
+	}
+	for _, g := range dg.Groups {
+		pw.declare(g.String(), "collections %v as %v%v\n",
+			plantUMLLabel(fmt.Sprintf("%v\\nGroup: %v", g.Type, g.Name)))
+	}
+	for _, c := range dg.Ctors {
+		for _, p := range c.Params {
+			pw.declare(p.String(), "component %v as %v%v\n", plantUMLLabel(p.String()))
+		}
+	}
+	for i, d := range dg.Decorators {
+		fmt.Fprintf(pw, "card %v as decorator_%d\n", plantUMLLabel(d.Name), i)
+		for _, p := range d.Params {
+			pw.declare(p.String(), "component %v as %v%v\n", plantUMLLabel(p.String()))
+		}
+		for _, r := range d.Results {
+			pw.declare(r.String(), "component %v as %v%v\n", plantUMLResultLabel(r))
+		}
+		for _, g := range d.Groups {
+			pw.declare(g.String(), "collections %v as %v%v\n",
+				plantUMLLabel(fmt.Sprintf("%v\\nGroup: %v", g.Type, g.Name)))
+		}
+	}
+	for _, r := range dg.Failed.TransitiveFailures {
+		pw.declare(r.String(), "component %v as %v%v\n", plantUMLLabel(r.String()))
+	}
+	for _, r := range dg.Failed.RootCauses {
+		pw.declare(r.String(), "component %v as %v%v\n", plantUMLLabel(r.String()))
+	}
+
+	for _, g := range dg.Groups {
+		for _, r := range g.Results {
+			fmt.Fprintf(pw, "%v --> %v\n", pw.ids[g.String()], pw.ids[r.String()])
+		}
+	}
+	for i, c := range dg.Ctors {
+		for _, p := range c.Params {
+			arrow := "-->"
+			if p.Optional {
+				arrow = "..>"
+			}
+			fmt.Fprintf(pw, "constructor_%d %v %v\n", i, arrow, pw.ids[p.String()])
+		}
+		for _, g := range c.GroupParams {
+			fmt.Fprintf(pw, "constructor_%d --> %v\n", i, pw.ids[g.String()])
+		}
+	}
+
+	for i, d := range dg.Decorators {
+		for _, p := range d.Params {
+			arrow := "-->"
+			if p.Optional {
+				arrow = "..>"
+			}
+			fmt.Fprintf(pw, "decorator_%d %v %v\n", i, arrow, pw.ids[p.String()])
+		}
+		for _, g := range d.GroupParams {
+			fmt.Fprintf(pw, "decorator_%d --> %v\n", i, pw.ids[g.String()])
+		}
+
+		// Decorations are drawn with bold edges to tell them apart from
+		// dependencies.
+		label := ""
+		if d.Local {
+			label = " : local"
+		}
+		for _, r := range d.Results {
+			fmt.Fprintf(pw, "decorator_%d -[bold]-> %v%v\n", i, pw.ids[r.String()], label)
+		}
+		for _, g := range d.Groups {
+			fmt.Fprintf(pw, "decorator_%d -[bold]-> %v%v\n", i, pw.ids[g.String()], label)
+		}
+	}
+
+	pw.WriteString("@enduml\n")
+}
+
+// declare assigns an alias to the node with the given string representation
+// and writes its declaration using format, unless it was already declared.
+// format receives the label, the alias and the style of the node.
+func (pw *plantUMLWriter) declare(node string, format string, label string) {
+	if _, ok := pw.ids[node]; ok {
+		return
+	}
+	id := fmt.Sprintf("node_%d", len(pw.ids))
+	pw.ids[node] = id
+
+	var style string
+	if color, ok := pw.failed[node]; ok {
+		style = " #line:" + color
+	}
+	fmt.Fprintf(pw, format, label, id, style)
+}
+
+func plantUMLResultLabel(r *Result) string {
+	switch {
+	case r.Group != "" && r.Name != "":
+		return plantUMLLabel(fmt.Sprintf("%v\\nGroup: %v, Name: %v", r.Type, r.Group, r.Name))
+	case r.Name != "":
+		return plantUMLLabel(fmt.Sprintf("%v\\nName: %v", r.Type, r.Name))
+	case r.Group != "":
+		return plantUMLLabel(fmt.Sprintf("%v\\nGroup: %v", r.Type, r.Group))
+	default:
+		return plantUMLLabel(r.Type.String())
+	}
+}
+
+// plantUMLLabel quotes s for use as a PlantUML label. PlantUML has no escape
+// for double quotes so they are replaced by single quotes.
+func plantUMLLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import "io"
+
+// Renderer renders a Graph in a text format.
+type Renderer interface {
+	Render(w io.Writer, dg *Graph) error
+}
+
+// MermaidRenderer renders graphs as Mermaid flowcharts. See
+// Graph.WriteMermaid.
+type MermaidRenderer struct{}
+
+var _ Renderer = MermaidRenderer{}
+
+// Render implements Renderer.
+func (MermaidRenderer) Render(w io.Writer, dg *Graph) error {
+	return dg.WriteMermaid(w)
+}
+
+// D2Renderer renders graphs as D2 diagrams. See Graph.WriteD2.
+type D2Renderer struct{}
+
+var _ Renderer = D2Renderer{}
+
+// Render implements Renderer.
+func (D2Renderer) Render(w io.Writer, dg *Graph) error {
+	return dg.WriteD2(w)
+}
+
+// PlantUMLRenderer renders graphs as PlantUML component diagrams. See
+// Graph.WritePlantUML.
+type PlantUMLRenderer struct{}
+
+var _ Renderer = PlantUMLRenderer{}
+
+// Render implements Renderer.
+func (PlantUMLRenderer) Render(w io.Writer, dg *Graph) error {
+	return dg.WritePlantUML(w)
+}
@@ -0,0 +1,358 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Style holds the attributes a node or an edge of the DOT graph is drawn
+// with. Empty attributes are left out of the graph.
+type Style struct {
+	Color     string
+	Shape     string
+	Style     string
+	ArrowHead string
+	Tooltip   string
+}
+
+// ElementKind is the kind of a node or an edge of the DOT graph.
+type ElementKind int
+
+const (
+	// CtorElement is the node of a constructor.
+	CtorElement ElementKind = iota
+
+	// DecoratorElement is the node of a decorator.
+	DecoratorElement
+
+	// ValueElement is the node of a value.
+	ValueElement
+
+	// GroupElement is the node of a value group.
+	GroupElement
+
+	// DependencyEdge links a constructor or a decorator to a value or a
+	// value group it depends on.
+	DependencyEdge
+
+	// MemberEdge links a value group to one of its values.
+	MemberEdge
+
+	// DecorationEdge links a decorator to a value or a value group it
+	// decorates.
+	DecorationEdge
+
+	// SuggestionEdge links a missing value to a value it may have been
+	// meant as.
+	SuggestionEdge
+)
+
+// Element is a node or an edge of the DOT graph that a Style is computed
+// for.
+type Element struct {
+	Kind ElementKind
+
+	// Ctor is the constructor of the element: the constructor itself, the
+	// one producing a value, or the one an edge starts from. Decorator is
+	// the decorator of the element in the same way.
+	Ctor      *Ctor
+	Decorator *Decorator
+
+	// Node is the value of the element, or the value an edge points to,
+	// and Group is the value group of the element, or the one an edge
+	// points to or starts from.
+	Node  *Node
+	Group *Group
+
+	// Optional is true for the edges of optional dependencies.
+	Optional bool
+
+	// ErrorType is the state of the element in the visualized error.
+	ErrorType ErrorType
+}
+
+// Failed reports whether the element failed to build.
+func (e Element) Failed() bool { return e.ErrorType != noError }
+
+// RootCause reports whether the element is the root cause of the
+// visualized error.
+func (e Element) RootCause() bool { return e.ErrorType == rootCause }
+
+// Styles computes the attributes of the DOT graph from the Styles of its
+// elements. It is meant to be used from the template rendering the graph.
+type Styles struct {
+	dg     *Graph
+	styler func(Element, *Style)
+
+	// failed holds the error state of the failed values, keyed by their
+	// string representation.
+	failed map[string]ErrorType
+
+	// ctors holds the Styles of the constructors, which are used both for
+	// their nodes and their clusters.
+	ctors map[*Ctor]Style
+}
+
+// Styles returns the Styles of the graph. If styler is not nil, it is
+// called with the default Style of each element to customize it.
+func (dg *Graph) Styles(styler func(Element, *Style)) *Styles {
+	failed := make(map[string]ErrorType)
+	for _, r := range dg.Failed.TransitiveFailures {
+		failed[r.String()] = transitiveFailure
+	}
+	for _, r := range dg.Failed.RootCauses {
+		failed[r.String()] = rootCause
+	}
+	return &Styles{dg: dg, styler: styler, failed: failed, ctors: make(map[*Ctor]Style)}
+}
+
+func (s *Styles) style(e Element) Style {
+	var st Style
+	switch e.Kind {
+	case CtorElement:
+		st.Shape = "plaintext"
+		if e.Ctor.Critical {
+			st.Style = "bold"
+		}
+	case DecoratorElement:
+		st.Shape = "box"
+		st.Style = "dashed"
+	case ValueElement:
+		if e.Ctor != nil && e.Ctor.Supplied {
+			st.Shape = "parallelogram"
+		}
+		if e.Ctor != nil && e.Ctor.Fallback {
+			st.Style = "dashed"
+		}
+	case GroupElement:
+		st.Shape = "diamond"
+	case DependencyEdge:
+		if e.Optional {
+			st.Style = "dashed"
+		}
+	case DecorationEdge:
+		st.Style = "bold"
+		st.Color = "blue"
+		st.ArrowHead = "odiamond"
+	case SuggestionEdge:
+		st.Style = "dotted"
+	}
+	if e.Failed() {
+		st.Color = e.ErrorType.Color()
+	}
+	if s.styler != nil {
+		s.styler(e, &st)
+	}
+	return st
+}
+
+func (s *Styles) ctor(c *Ctor) Style {
+	st, ok := s.ctors[c]
+	if !ok {
+		st = s.style(Element{Kind: CtorElement, Ctor: c, ErrorType: c.ErrorType})
+		s.ctors[c] = st
+	}
+	return st
+}
+
+// Ctor returns the attributes of the node of the constructor. Only its
+// shape is taken from the Style of the constructor: the other attributes
+// apply to its cluster.
+func (s *Styles) Ctor(c *Ctor) string {
+	return nodeAttributes(s.ctor(c).Shape, "label="+strconv.Quote(c.Caption()), Style{})
+}
+
+// Cluster returns the attribute statements of the cluster of the
+// constructor.
+func (s *Styles) Cluster(c *Ctor) string {
+	st := s.ctor(c)
+	var b strings.Builder
+	for _, a := range [][2]string{{"color", st.Color}, {"style", st.Style}, {"tooltip", st.Tooltip}} {
+		if a[1] != "" {
+			b.WriteString(a[0] + "=" + dotID(a[1]) + ";")
+		}
+	}
+	return b.String()
+}
+
+// Decorator returns the attributes of the node of the decorator.
+func (s *Styles) Decorator(d *Decorator) string {
+	st := s.style(Element{Kind: DecoratorElement, Decorator: d})
+	return nodeAttributes(st.Shape, "label="+strconv.Quote(d.Name), st)
+}
+
+// Value returns the attributes of the node of a result of the
+// constructor.
+func (s *Styles) Value(c *Ctor, r *Result) string {
+	st := s.style(Element{Kind: ValueElement, Ctor: c, Node: r.Node, ErrorType: s.failed[r.String()]})
+	return nodeAttributes(st.Shape, r.Attributes(), st)
+}
+
+// Group returns the attributes of the node of the value group.
+func (s *Styles) Group(g *Group) string {
+	st := s.style(Element{Kind: GroupElement, Group: g, ErrorType: g.ErrorType})
+	return nodeAttributes(st.Shape, g.label(), st)
+}
+
+// Member returns the attributes of the edge from the value group to one of
+// its values.
+func (s *Styles) Member(g *Group, r *Result) string {
+	return edgeAttributes("", s.style(Element{Kind: MemberEdge, Group: g, Node: r.Node}), "")
+}
+
+// Dependency returns the attributes of the edge from the constructor to a
+// value it depends on.
+func (s *Styles) Dependency(c *Ctor, p *Param, ltail string) string {
+	st := s.style(Element{Kind: DependencyEdge, Ctor: c, Node: p.Node, Optional: p.Optional})
+	return edgeAttributes(ltail, st, "")
+}
+
+// GroupDependency returns the attributes of the edge from the constructor
+// to a value group it depends on.
+func (s *Styles) GroupDependency(c *Ctor, g *Group, ltail string) string {
+	return edgeAttributes(ltail, s.style(Element{Kind: DependencyEdge, Ctor: c, Group: g}), "")
+}
+
+// DecoratorDependency returns the attributes of the edge from the
+// decorator to a value it depends on.
+func (s *Styles) DecoratorDependency(d *Decorator, p *Param) string {
+	st := s.style(Element{Kind: DependencyEdge, Decorator: d, Node: p.Node, Optional: p.Optional})
+	return edgeAttributes("", st, "")
+}
+
+// DecoratorGroupDependency returns the attributes of the edge from the
+// decorator to a value group it depends on.
+func (s *Styles) DecoratorGroupDependency(d *Decorator, g *Group) string {
+	return edgeAttributes("", s.style(Element{Kind: DependencyEdge, Decorator: d, Group: g}), "")
+}
+
+// Decoration returns the attributes of the edge from the decorator to a
+// value it decorates.
+func (s *Styles) Decoration(d *Decorator, r *Result) string {
+	return edgeAttributes("", s.style(Element{Kind: DecorationEdge, Decorator: d, Node: r.Node}), d.edgeLabel())
+}
+
+// GroupDecoration returns the attributes of the edge from the decorator to
+// a value group it decorates.
+func (s *Styles) GroupDecoration(d *Decorator, g *Group) string {
+	return edgeAttributes("", s.style(Element{Kind: DecorationEdge, Decorator: d, Group: g}), d.edgeLabel())
+}
+
+// Suggestion returns the attributes of the edge of the suggestion.
+func (s *Styles) Suggestion(sg *Suggestion) string {
+	st := s.style(Element{Kind: SuggestionEdge, Node: sg.Missing.Node})
+	return edgeAttributes("", st, "did you mean?")
+}
+
+// Failures returns the failed values that are not produced by any of the
+// constructors of the graph, such as missing types.
+func (s *Styles) Failures() []*Result {
+	declared := make(map[string]struct{})
+	for _, c := range s.dg.Ctors {
+		for _, r := range c.Results {
+			declared[r.String()] = struct{}{}
+		}
+	}
+
+	var failures []*Result
+	for _, results := range [][]*Result{s.dg.Failed.TransitiveFailures, s.dg.Failed.RootCauses} {
+		for _, r := range results {
+			if _, ok := declared[r.String()]; !ok {
+				declared[r.String()] = struct{}{}
+				failures = append(failures, r)
+			}
+		}
+	}
+	return failures
+}
+
+// Failure returns the attributes of the node of a failed value that is not
+// produced by any constructor.
+func (s *Styles) Failure(r *Result) string {
+	st := s.style(Element{Kind: ValueElement, Node: r.Node, ErrorType: s.failed[r.String()]})
+	return nodeAttributes(st.Shape, "", st)
+}
+
+// nodeAttributes joins the attributes of a node with the given shape and
+// label.
+func nodeAttributes(shape, label string, st Style) string {
+	return joinAttributes(
+		[2]string{"shape", shape},
+		[2]string{"", label},
+		[2]string{"style", st.Style},
+		[2]string{"color", st.Color},
+		[2]string{"tooltip", st.Tooltip},
+	)
+}
+
+// edgeAttributes joins the attributes of an edge leaving the given
+// cluster, if any.
+func edgeAttributes(ltail string, st Style, label string) string {
+	if label != "" {
+		label = strconv.Quote(label)
+	}
+	return joinAttributes(
+		[2]string{"ltail", ltail},
+		[2]string{"style", st.Style},
+		[2]string{"color", st.Color},
+		[2]string{"arrowhead", st.ArrowHead},
+		[2]string{"label", label},
+		[2]string{"tooltip", st.Tooltip},
+	)
+}
+
+// joinAttributes joins the non-empty attributes, given as names and
+// values. Attributes without a name are written as-is.
+func joinAttributes(attrs ...[2]string) string {
+	var b strings.Builder
+	for _, a := range attrs {
+		if a[1] == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		if a[0] == "" {
+			b.WriteString(a[1])
+			continue
+		}
+		b.WriteString(a[0] + "=")
+		if a[0] == "label" {
+			b.WriteString(a[1])
+		} else {
+			b.WriteString(dotID(a[1]))
+		}
+	}
+	return b.String()
+}
+
+// dotID returns v as a DOT identifier, quoted unless it is made of
+// letters, digits and underscores only.
+func dotID(v string) string {
+	for _, r := range v {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9') {
+			return strconv.Quote(v)
+		}
+	}
+	return v
+}
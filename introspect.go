@@ -0,0 +1,325 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ProviderInfo describes a constructor that was provided to a Container or
+// one of its Scopes.
+type ProviderInfo struct {
+	// ID is the unique identifier of the constructor, matching the ID
+	// reported by FillProvideInfo.
+	ID ID
+
+	// Name is the name of the constructor in the format:
+	// <package_name>.<function_name>
+	Name string
+
+	// Package is the import path of the package defining the constructor.
+	Package string
+
+	// File and Line report where the constructor was defined.
+	File string
+	Line int
+
+	// Inputs and Outputs describe the values consumed and produced by the
+	// constructor.
+	Inputs  []*Input
+	Outputs []*Output
+
+	// ScopePath contains the names of the scopes, starting below the
+	// container, leading to the Scope the constructor was provided to.
+	ScopePath []string
+
+	// Tags holds the metadata attached to the constructor with WithTags,
+	// if any.
+	Tags map[string]string
+}
+
+func newProviderInfo(n *constructorNode) ProviderInfo {
+	return ProviderInfo{
+		ID:        ID(n.id),
+		Name:      fmt.Sprintf("%v.%v", n.location.Package, n.location.Name),
+		Package:   n.location.Package,
+		File:      n.location.File,
+		Line:      n.location.Line,
+		Inputs:    newInputs(n.paramList.DotParam()),
+		Outputs:   newOutputs(n.resultList.DotResult()),
+		ScopePath: n.s.path(),
+		Tags:      copyTags(n.tags),
+	}
+}
+
+// WithTags is a ProvideOption that attaches descriptive key/value metadata
+// to a constructor. Tags have no effect on how dependencies are resolved;
+// they are reported on ProviderInfo and may be queried with
+// Container.ProvidersWithTag.
+//
+//	c.Provide(NewDB, dig.WithTags(map[string]string{"layer": "infra"}))
+//
+// Multiple WithTags options on the same constructor are merged, with later
+// options taking precedence for duplicate keys.
+func WithTags(tags map[string]string) ProvideOption {
+	return provideTagsOption(copyTags(tags))
+}
+
+type provideTagsOption map[string]string
+
+func (o provideTagsOption) String() string {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, len(keys))
+	for i, k := range keys {
+		items[i] = fmt.Sprintf("%q: %q", k, o[k])
+	}
+	return fmt.Sprintf("WithTags(%v)", strings.Join(items, ", "))
+}
+
+func (o provideTagsOption) applyProvideOption(opts *provideOptions) {
+	if len(o) == 0 {
+		return
+	}
+	if opts.Tags == nil {
+		opts.Tags = make(map[string]string, len(o))
+	}
+	for k, v := range o {
+		opts.Tags[k] = v
+	}
+}
+
+func copyTags(tags map[string]string) map[string]string {
+	if tags == nil {
+		return nil
+	}
+	c := make(map[string]string, len(tags))
+	for k, v := range tags {
+		c[k] = v
+	}
+	return c
+}
+
+// Providers returns information about all the constructors provided to the
+// Container or any of its Scopes, in the order they were provided to each
+// Scope, starting with the Container.
+//
+// Along with Input.Key and Output.Key, this tells which constructors
+// produce or consume a value and where they are defined:
+//
+//	for _, p := range c.Providers() {
+//	  for _, o := range p.Outputs {
+//	    if o.Key().Type == reflect.TypeOf(db) {
+//	      fmt.Printf("%v provided by %v at %v:%v\n", o, p.Name, p.File, p.Line)
+//	    }
+//	  }
+//	}
+func (c *Container) Providers() []ProviderInfo {
+	var infos []ProviderInfo
+	for _, s := range c.scope.appendSubscopes(nil) {
+		for _, n := range s.nodes {
+			infos = append(infos, newProviderInfo(n))
+		}
+	}
+	return infos
+}
+
+// WhoProvides returns information about the constructors provided to the
+// Container or any of its Scopes that produce values of the type pointed to
+// by t.
+//
+//	providers := c.WhoProvides(new(*sql.DB), dig.Name("ro"))
+//
+// Only the Name and Group options are honored, to look for named values or
+// for the providers of a value group. Other options are ignored.
+func (c *Container) WhoProvides(t interface{}, opts ...ProvideOption) []ProviderInfo {
+	k, ok := queryKey(t, opts)
+	if !ok {
+		return nil
+	}
+
+	var infos []ProviderInfo
+	for _, p := range c.Providers() {
+		for _, o := range p.Outputs {
+			if o.Key() == k {
+				infos = append(infos, p)
+				break
+			}
+		}
+	}
+	return infos
+}
+
+// WhoConsumes returns information about the constructors provided to the
+// Container or any of its Scopes that depend on values of the type pointed
+// to by t, optionally or not.
+//
+// As with WhoProvides, only the Name and Group options are honored.
+func (c *Container) WhoConsumes(t interface{}, opts ...ProvideOption) []ProviderInfo {
+	k, ok := queryKey(t, opts)
+	if !ok {
+		return nil
+	}
+
+	var infos []ProviderInfo
+	for _, p := range c.Providers() {
+		for _, i := range p.Inputs {
+			if i.Key() == k {
+				infos = append(infos, p)
+				break
+			}
+		}
+	}
+	return infos
+}
+
+// GroupMembers returns information about the constructors provided to the
+// Container or any of its Scopes that feed the value group with the given
+// name, with values of the type pointed to by elemType. Constructors adding
+// several values to the group, e.g. with the flatten option, are reported
+// once.
+//
+//	for _, p := range c.GroupMembers("handlers", new(http.Handler)) {
+//	  fmt.Printf("handler provided by %v at %v:%v\n", p.Name, p.File, p.Line)
+//	}
+func (c *Container) GroupMembers(name string, elemType interface{}) []ProviderInfo {
+	return c.WhoProvides(elemType, Group(name))
+}
+
+// queryKey returns the key looked for by WhoProvides and WhoConsumes. It
+// returns false if t is not a pointer.
+func queryKey(t interface{}, opts []ProvideOption) (Key, bool) {
+	pt := reflect.TypeOf(t)
+	if pt == nil || pt.Kind() != reflect.Ptr {
+		return Key{}, false
+	}
+
+	var options provideOptions
+	for _, o := range opts {
+		o.applyProvideOption(&options)
+	}
+	return Key{Type: pt.Elem(), Name: options.Name, Group: options.Group}, true
+}
+
+// ProvidersWithTag returns information about all the constructors provided
+// to the Container or any of its Scopes that were tagged with the given key
+// and value using WithTags.
+func (c *Container) ProvidersWithTag(key, value string) []ProviderInfo {
+	var infos []ProviderInfo
+	for _, s := range c.scope.appendSubscopes(nil) {
+		for _, n := range s.nodes {
+			if v, ok := n.tags[key]; ok && v == value {
+				infos = append(infos, newProviderInfo(n))
+			}
+		}
+	}
+	return infos
+}
+
+// DecoratorInfo describes a decorator that was registered to a Container or
+// one of its Scopes.
+type DecoratorInfo struct {
+	// ID is the unique identifier of the decorator, matching the ID
+	// reported by FillDecorateInfo.
+	ID ID
+
+	// Name is the name of the decorator in the format:
+	// <package_name>.<function_name>
+	Name string
+
+	// Package is the import path of the package defining the decorator.
+	Package string
+
+	// File and Line report where the decorator was defined.
+	File string
+	Line int
+
+	// Inputs and Outputs describe the values consumed and produced by the
+	// decorator.
+	Inputs  []*Input
+	Outputs []*Output
+
+	// ScopePath contains the names of the scopes, starting below the
+	// container, leading to the Scope the decorator was registered to.
+	ScopePath []string
+
+	// Order is the order of the decorator given with DecorateOrder.
+	Order int
+}
+
+func newDecoratorInfo(n *decoratorNode) DecoratorInfo {
+	return DecoratorInfo{
+		ID:        ID(n.id),
+		Name:      fmt.Sprintf("%v.%v", n.location.Package, n.location.Name),
+		Package:   n.location.Package,
+		File:      n.location.File,
+		Line:      n.location.Line,
+		Inputs:    newInputs(n.params.DotParam()),
+		Outputs:   newOutputs(n.results.DotResult()),
+		ScopePath: n.s.path(),
+		Order:     n.order,
+	}
+}
+
+// DecorationChain returns information about the decorators that apply to
+// the values of the type pointed to by t resolved from the Container, in
+// the order they are applied. See Scope.DecorationChain.
+func (c *Container) DecorationChain(t interface{}, opts ...ProvideOption) []DecoratorInfo {
+	return c.scope.DecorationChain(t, opts...)
+}
+
+// DecorationChain returns information about the decorators that apply to
+// the values of the type pointed to by t resolved from the Scope, in the
+// order they are applied: the decorators of its ancestors that propagate to
+// their descendants, starting with the Container, then the decorators of
+// the Scope, each sorted by DecorateOrder. Each decorator receives the
+// values returned by the previous one.
+//
+//	for _, d := range c.DecorationChain(new(*sql.DB), dig.Name("primary")) {
+//	  fmt.Printf("decorated by %v at %v:%v\n", d.Name, d.File, d.Line)
+//	}
+//
+// As with Container.WhoProvides, only the Name and Group options are
+// honored.
+func (s *Scope) DecorationChain(t interface{}, opts ...ProvideOption) []DecoratorInfo {
+	k, ok := queryKey(t, opts)
+	if !ok {
+		return nil
+	}
+
+	var infos []DecoratorInfo
+	scopes := s.ancestors()
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for _, d := range scopes[i].decorators[internalKey(k)] {
+			if decoratorApplies(d, i) {
+				infos = append(infos, newDecoratorInfo(d))
+			}
+		}
+	}
+	return infos
+}
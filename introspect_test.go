@@ -0,0 +1,240 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvidersWithTag(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{}
+	type Cache struct{}
+	type Handler struct{}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() *DB { return &DB{} },
+		dig.WithTags(map[string]string{"layer": "infra"}))
+	c.RequireProvide(func() *Handler { return &Handler{} },
+		dig.WithTags(map[string]string{"layer": "http"}))
+	c.Scope("child").RequireProvide(func() *Cache { return &Cache{} },
+		dig.WithTags(map[string]string{"layer": "infra"}),
+		dig.WithTags(map[string]string{"owner": "core"}))
+
+	infos := c.ProvidersWithTag("layer", "infra")
+	require.Len(t, infos, 2)
+
+	assert.Equal(t, "*dig_test.DB", infos[0].Outputs[0].String())
+	assert.Equal(t, map[string]string{"layer": "infra"}, infos[0].Tags)
+	assert.Contains(t, infos[0].Name, "TestProvidersWithTag")
+	assert.NotEmpty(t, infos[0].File)
+	assert.Empty(t, infos[0].ScopePath)
+
+	assert.Equal(t, "*dig_test.Cache", infos[1].Outputs[0].String())
+	assert.Equal(t, map[string]string{"layer": "infra", "owner": "core"}, infos[1].Tags)
+	assert.Equal(t, []string{"child"}, infos[1].ScopePath)
+
+	assert.Empty(t, c.ProvidersWithTag("layer", "storage"))
+	assert.Empty(t, c.ProvidersWithTag("missing", "infra"))
+
+	t.Run("tags do not affect resolution", func(t *testing.T) {
+		c.RequireInvoke(func(*DB, *Handler) {})
+	})
+
+	t.Run("returned tags are copies", func(t *testing.T) {
+		c.ProvidersWithTag("layer", "http")[0].Tags["layer"] = "changed"
+		assert.Len(t, c.ProvidersWithTag("layer", "http"), 1)
+	})
+}
+
+func TestProviders(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{}
+	type Handler struct{}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() *DB { return &DB{} })
+	c.Scope("child").RequireProvide(func(struct {
+		dig.In
+
+		DB      *DB
+		Clients []string `group:"clients"`
+		Name    string   `name:"name" optional:"true"`
+	}) *Handler {
+		return &Handler{}
+	}, dig.Name("main"))
+
+	infos := c.Providers()
+	require.Len(t, infos, 2)
+
+	db := infos[0]
+	assert.Contains(t, db.Name, "TestProviders")
+	assert.Equal(t, "github.com/alexisvisco/dig_test", db.Package)
+	assert.Contains(t, db.File, "introspect_test.go")
+	assert.NotZero(t, db.Line)
+	assert.Empty(t, db.Inputs)
+	require.Len(t, db.Outputs, 1)
+	assert.Equal(t, dig.Key{Type: reflect.TypeOf(&DB{})}, db.Outputs[0].Key())
+
+	handler := infos[1]
+	assert.Equal(t, []string{"child"}, handler.ScopePath)
+	require.Len(t, handler.Outputs, 1)
+	assert.Equal(t, dig.Key{Type: reflect.TypeOf(&Handler{}), Name: "main"}, handler.Outputs[0].Key())
+
+	require.Len(t, handler.Inputs, 3)
+	assert.Equal(t, dig.Key{Type: reflect.TypeOf(&DB{})}, handler.Inputs[0].Key())
+	assert.False(t, handler.Inputs[0].Optional())
+	assert.Equal(t, dig.Key{Type: reflect.TypeOf(""), Group: "clients"}, handler.Inputs[1].Key())
+	assert.Equal(t, dig.Key{Type: reflect.TypeOf(""), Name: "name"}, handler.Inputs[2].Key())
+	assert.True(t, handler.Inputs[2].Optional())
+}
+
+func TestWhoProvidesWhoConsumes(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{}
+	type Server struct{}
+	type Handler struct{}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() *Config { return &Config{} })
+	c.RequireProvide(func() *Config { return &Config{} }, dig.Name("override"))
+	c.RequireProvide(func(*Config) *Handler { return &Handler{} }, dig.Group("handlers"))
+	c.Scope("child").RequireProvide(func(struct {
+		dig.In
+
+		Config   *Config    `name:"override"`
+		Handlers []*Handler `group:"handlers"`
+	}) *Server {
+		return &Server{}
+	})
+
+	t.Run("providers", func(t *testing.T) {
+		require.Len(t, c.WhoProvides(new(*Config)), 1)
+		require.Len(t, c.WhoProvides(new(*Config), dig.Name("override")), 1)
+		assert.Empty(t, c.WhoProvides(new(*Config), dig.Name("missing")))
+
+		handlers := c.WhoProvides(new(*Handler), dig.Group("handlers"))
+		require.Len(t, handlers, 1)
+		assert.Empty(t, c.WhoProvides(new(*Handler)))
+
+		servers := c.WhoProvides(new(*Server))
+		require.Len(t, servers, 1)
+		assert.Equal(t, []string{"child"}, servers[0].ScopePath)
+	})
+
+	t.Run("consumers", func(t *testing.T) {
+		consumers := c.WhoConsumes(new(*Config))
+		require.Len(t, consumers, 1)
+		assert.Equal(t, "*dig_test.Handler[group = \"handlers\"]", consumers[0].Outputs[0].String())
+
+		require.Len(t, c.WhoConsumes(new(*Config), dig.Name("override")), 1)
+		require.Len(t, c.WhoConsumes(new(*Handler), dig.Group("handlers")), 1)
+		assert.Empty(t, c.WhoConsumes(new(*Server)))
+	})
+
+	t.Run("non-pointer", func(t *testing.T) {
+		assert.Empty(t, c.WhoProvides(Config{}))
+		assert.Empty(t, c.WhoConsumes(nil))
+	})
+}
+
+func TestGroupMembers(t *testing.T) {
+	t.Parallel()
+
+	type Handler struct{}
+	type out struct {
+		dig.Out
+
+		Handlers []*Handler `group:"handlers,flatten"`
+	}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() *Handler { return &Handler{} }, dig.Group("handlers"))
+	c.RequireProvide(func() out { return out{} })
+	c.RequireProvide(func() *Handler { return &Handler{} }, dig.Group("other"))
+	c.Scope("child").RequireProvide(func() *Handler { return &Handler{} }, dig.Group("handlers"))
+
+	members := c.GroupMembers("handlers", new(*Handler))
+	require.Len(t, members, 3)
+	for _, m := range members {
+		assert.Contains(t, m.Name, "TestGroupMembers")
+		assert.Contains(t, m.File, "introspect_test.go")
+		assert.NotZero(t, m.Line)
+	}
+	assert.Equal(t, []string{"child"}, members[2].ScopePath)
+
+	assert.Len(t, c.GroupMembers("other", new(*Handler)), 1)
+	assert.Empty(t, c.GroupMembers("missing", new(*Handler)))
+	assert.Empty(t, c.GroupMembers("handlers", new(Handler)))
+	assert.Empty(t, c.GroupMembers("handlers", Handler{}))
+}
+
+func TestDecorationChain(t *testing.T) {
+	t.Parallel()
+
+	type Handler struct{}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() *Handler { return &Handler{} })
+	c.RequireProvide(func() *Handler { return &Handler{} }, dig.Group("handlers"))
+	c.RequireDecorate(func(h *Handler) *Handler { return h })
+	c.RequireDecorate(func(h *Handler) *Handler { return h }, dig.DecorateOrder(-1))
+	c.RequireDecorate(func(hs []*Handler) []*Handler { return hs }, dig.DecorateGroup("handlers"))
+	child := c.Scope("child")
+	child.RequireDecorate(func(h *Handler) *Handler { return h }, dig.DecorateOrder(-2))
+	local := c.Scope("local")
+	local.RequireDecorate(func(h *Handler) *Handler { return h }, dig.PropagateToDescendants(false))
+
+	names := func(infos []dig.DecoratorInfo) []string {
+		var names []string
+		for _, d := range infos {
+			assert.Contains(t, d.File, "introspect_test.go")
+			names = append(names, strings.TrimPrefix(d.Name, "github.com/alexisvisco/dig_test.TestDecorationChain."))
+		}
+		return names
+	}
+
+	chain := c.DecorationChain(new(*Handler))
+	assert.Equal(t, []string{"func4", "func3"}, names(chain))
+	assert.Equal(t, -1, chain[0].Order)
+	assert.Empty(t, chain[0].ScopePath)
+
+	chain = child.DecorationChain(new(*Handler))
+	assert.Equal(t, []string{"func4", "func3", "func6"}, names(chain))
+	assert.Equal(t, []string{"child"}, chain[2].ScopePath)
+
+	assert.Equal(t, []string{"func4", "func3", "func7"}, names(local.DecorationChain(new(*Handler))))
+	assert.Equal(t, []string{"func4", "func3"}, names(local.Scope("grandchild").DecorationChain(new(*Handler))))
+
+	assert.Equal(t, []string{"func5"}, names(c.DecorationChain(new(*Handler), dig.Group("handlers"))))
+	assert.Empty(t, c.DecorationChain(new(*Handler), dig.Name("missing")))
+	assert.Empty(t, c.DecorationChain(Handler{}))
+}
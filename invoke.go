@@ -21,6 +21,7 @@
 package dig
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -35,7 +36,17 @@ type InvokeOption interface {
 
 type invokeOptions struct {
 	Info             *InvokeInfo
+	Context          context.Context
+	With             []interface{}
+	Defaults         []interface{}
+	Trace            *Trace
 	hookBeforeInvoke func()
+
+	// Report of the dependencies skipped by TryInvoke.
+	report *InvokeReport
+
+	// Values of the arguments of a factory, keyed by their type.
+	factoryArgs map[reflect.Type]reflect.Value
 }
 
 // InvokeInfo provides information about an Invoke.
@@ -43,6 +54,10 @@ type InvokeInfo struct {
 	Inputs []*Input
 
 	ReturnedOutput []reflect.Value
+
+	// Warnings lists the constructors that were left out of tolerant value
+	// groups while the dependencies of the function were built.
+	Warnings []GroupWarning
 }
 
 // FillInvokeInfo is an InvokeOption that writes information on the types
@@ -106,69 +121,109 @@ func (c *Container) Invoke(function interface{}, opts ...InvokeOption) error {
 // The function may return an error to indicate failure. The error will be
 // returned to the caller as-is.
 func (s *Scope) Invoke(function interface{}, opts ...InvokeOption) (err error) {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
 	options := invokeOptions{}
 	for _, opt := range opts {
 		opt.applyInvokeOption(&options)
 	}
 
-	ftype := reflect.TypeOf(function)
-	if ftype == nil {
-		return newErrInvalidInput("can't invoke an untyped nil", nil)
+	function, location, err := unannotate(function)
+	if err != nil {
+		return err
 	}
-	if ftype.Kind() != reflect.Func {
-		return newErrInvalidInput(
-			fmt.Sprintf("can't invoke non-function %v (type %v)", function, ftype), nil)
+	inspect := func() *digreflect.Func {
+		if location != nil {
+			return location
+		}
+		return digreflect.InspectFunc(function)
 	}
 
-	pl, err := newParamList(ftype, s)
+	ftype, err := invokeType(function)
 	if err != nil {
 		return err
 	}
 
-	if err := shallowCheckDependencies(s, pl); err != nil {
-		return errMissingDependencies{
-			Func:   digreflect.InspectFunc(function),
-			Reason: err,
+	var (
+		pl       paramList
+		args     []reflect.Value
+		warnings []GroupWarning
+	)
+	err = s.parallelism().do(func() error {
+		var err error
+		if pl, err = s.invokeParamList(ftype); err != nil {
+			return err
+		}
+		if len(options.With) > 0 {
+			if pl, err = pl.supplyValues(options.With); err != nil {
+				return err
+			}
+		}
+		if len(options.Defaults) > 0 {
+			if pl, err = pl.withDefaults(options.Defaults); err != nil {
+				return err
+			}
+		}
+		if options.factoryArgs != nil {
+			pl, _ = pl.supply(options.factoryArgs)
 		}
-	}
 
-	if !s.isVerifiedAcyclic {
-		if ok, cycle := graph.IsAcyclic(s.gh); !ok {
-			return newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(cycle))
+		defer s.withInvokeContext(options.Context)()
+		if options.Trace != nil {
+			fn := inspect()
+			defer s.withTrace(options.Trace, fmt.Sprintf("%v.%v", fn.Package, fn.Name))()
+		}
+		defer s.withWarnings(&warnings)()
+		if report := options.report; report != nil {
+			defer s.withReport(report)()
+			defer func() {
+				report.Warnings = append([]GroupWarning(nil), warnings...)
+			}()
 		}
-		s.isVerifiedAcyclic = true
-	}
 
-	args, err := pl.BuildList(s)
-	if err != nil {
-		return errArgumentsFailed{
-			Func:   digreflect.InspectFunc(function),
-			Reason: err,
+		if err := shallowCheckDependencies(s, pl); err != nil {
+			return errMissingDependencies{
+				Func:   inspect(),
+				Reason: err,
+			}
+		}
+
+		if !s.isVerifiedAcyclic {
+			if ok, cycle := graph.IsAcyclic(s.gh); !ok {
+				return newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(cycle))
+			}
+			s.isVerifiedAcyclic = true
 		}
+
+		args, err = s.buildInvokeArgs(pl)
+		if err == nil && options.Context != nil {
+			err = options.Context.Err()
+		}
+		if err != nil {
+			return errArgumentsFailed{
+				Func:   inspect(),
+				Reason: err,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	if s.recoverFromPanics {
 		defer func() {
 			if p := recover(); p != nil {
-				err = PanicError{
-					fn:    digreflect.InspectFunc(function),
-					Panic: p,
-				}
+				err = newPanicError(inspect(), false /* constructor */, p)
 			}
 		}()
 	}
 
 	// Record info for the invoke if requested
 	if info := options.Info; info != nil {
-		params := pl.DotParam()
-		info.Inputs = make([]*Input, len(params))
-		for i, p := range params {
-			info.Inputs[i] = &Input{
-				t:        p.Type,
-				optional: p.Optional,
-				name:     p.Name,
-				group:    p.Group,
-			}
-		}
+		info.Inputs = newInputs(pl.DotParam())
+		info.Warnings = warnings
 	}
 
 	if options.hookBeforeInvoke != nil {
@@ -197,12 +252,88 @@ func (s *Scope) Invoke(function interface{}, opts ...InvokeOption) (err error) {
 	return nil
 }
 
+// invokeType returns the type of a function given to Invoke.
+func invokeType(function interface{}) (reflect.Type, error) {
+	ftype := reflect.TypeOf(function)
+	if ftype == nil {
+		return nil, newErrInvalidInput("can't invoke an untyped nil", nil)
+	}
+	if ftype.Kind() != reflect.Func {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("can't invoke non-function %v (type %v)", function, ftype), nil)
+	}
+	return ftype, nil
+}
+
+// buildInvokeArgs builds the arguments of a function given to Invoke.
+// Unlike the dependencies of constructors, the fields of its dig.In
+// arguments are all built even if some of them fail, so that their
+// failures are reported at once.
+func (s *Scope) buildInvokeArgs(pl paramList) ([]reflect.Value, error) {
+	state := s.rootScope().state
+	if state.failedCalls == nil {
+		state.failedCalls = make(map[*constructorNode]error)
+		defer func() { state.failedCalls = nil }()
+	}
+
+	params := make([]param, len(pl.Params))
+	for i, p := range pl.Params {
+		if po, ok := p.(paramObject); ok {
+			p = invokeObject{po}
+		}
+		params[i] = p
+	}
+	return buildParams(s, params)
+}
+
+// invokeObject is a dig.In argument of a function given to Invoke.
+type invokeObject struct{ paramObject }
+
+func (o invokeObject) Build(c containerStore) (reflect.Value, error) {
+	return o.build(c, false /* failFast */)
+}
+
+// invokeParamList returns the parameters of the functions of type ftype
+// invoked from the Scope. They are parsed the first time a function of the
+// type is invoked, and reused by later Invokes.
+func (s *Scope) invokeParamList(ftype reflect.Type) (paramList, error) {
+	if pl, ok := s.invokeParams[ftype]; ok {
+		return pl, nil
+	}
+	pl, err := newParamList(ftype, s)
+	if err != nil {
+		return pl, err
+	}
+	if s.invokeParams == nil {
+		s.invokeParams = make(map[reflect.Type]paramList)
+	}
+	s.invokeParams[ftype] = pl
+	return pl, nil
+}
+
 // Checks that all direct dependencies of the provided parameters are present in
-// the container. Returns an error if not.
+// the container, after asking the missing handler of the container for the
+// missing ones. Returns an error if not.
 func shallowCheckDependencies(c containerStore, pl paramList) error {
-	var err errMissingTypes
-
 	missingDeps := findMissingDependencies(c, pl.Params...)
+	if len(missingDeps) > 0 {
+		var err error
+		if missingDeps, err = handleMissingDependencies(c, missingDeps); err != nil {
+			return err
+		}
+	}
+	return missingDependenciesError(c, missingDeps)
+}
+
+// staticCheckDependencies is shallowCheckDependencies for the checks of
+// the graph that must not modify the container: the missing handler of
+// the container is not asked for the missing dependencies.
+func staticCheckDependencies(c containerStore, pl paramList) error {
+	return missingDependenciesError(c, findMissingDependencies(c, pl.Params...))
+}
+
+func missingDependenciesError(c containerStore, missingDeps []paramSingle) error {
+	var err errMissingTypes
 	for _, dep := range missingDeps {
 		err = append(err, newErrMissingTypes(c, key{name: dep.Name, t: dep.Type})...)
 	}
@@ -219,8 +350,16 @@ func findMissingDependencies(c containerStore, params ...param) []paramSingle {
 	for _, param := range params {
 		switch p := param.(type) {
 		case paramSingle:
+			if p.isImplicit() || len(p.Selector) > 0 {
+				// Selected values are looked up when they are built.
+				continue
+			}
+			if f, _ := p.factory(c); f != nil {
+				continue
+			}
 			allProviders := c.getAllValueProviders(p.Name, p.Type)
 			_, hasDecoratedValue := c.getDecoratedValue(p.Name, p.Type)
+			hasDecoratedValue = hasDecoratedValue && !p.Undecorated
 			// This means that there is no provider that provides this value,
 			// and it is NOT being decorated and is NOT optional.
 			// In the case that there is no providers but there is a decorated value
@@ -232,6 +371,10 @@ func findMissingDependencies(c containerStore, params ...param) []paramSingle {
 			for _, f := range p.Fields {
 				missingDeps = append(missingDeps, findMissingDependencies(c, f.Param)...)
 			}
+		case paramLazy:
+			missingDeps = append(missingDeps, findMissingDependencies(c, p.Elem)...)
+		case paramOriginal:
+			missingDeps = append(missingDeps, findMissingDependencies(c, p.Elem)...)
 		}
 	}
 	return missingDeps
@@ -0,0 +1,242 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Keyed is a ProvideOption that names all values produced by a constructor
+// with a comparable key, instead of a string as Name does. Keys make it
+// possible to identify values by enums, tenant identifiers or any other
+// comparable type.
+//
+//	type Env int
+//
+//	const (
+//	  Staging Env = iota
+//	  Prod
+//	)
+//
+//	c.Provide(NewProdDB, dig.Keyed(Prod))
+//	c.Provide(NewStagingDB, dig.Keyed(Staging))
+//
+// Keyed values are consumed by annotating functions with KeyedParam.
+//
+//	c.Invoke(dig.Annotate(func(db *sql.DB) {
+//	  ..
+//	}, dig.KeyedParam(0, Prod)))
+//
+// Two keys are the same if they are equal with ==, in which case their
+// types are identical too. Keyed values never collide with the values
+// named with Name or name tags, and Keyed cannot be combined with Name.
+func Keyed(key interface{}) ProvideOption {
+	name, err := keyName(key)
+	return provideKeyOption{key: key, name: name, err: err}
+}
+
+type provideKeyOption struct {
+	key  interface{}
+	name string
+	err  error
+}
+
+func (o provideKeyOption) String() string {
+	return fmt.Sprintf("Keyed(%v)", formatKey(o.key))
+}
+
+func (o provideKeyOption) applyProvideOption(opts *provideOptions) {
+	opts.Key = &o
+}
+
+// KeyedParam is an Annotation that makes the parameter at index i of the
+// annotated function depend on the value provided with Keyed(key), rather
+// than on the unnamed value of its type. See Keyed.
+//
+// KeyedParam may be combined with ParamTags, provided that the parameter
+// has no tag.
+func KeyedParam(i int, key interface{}) Annotation {
+	name, err := keyName(key)
+	return keyedParamAnnotation{index: i, key: key, name: name, err: err}
+}
+
+type keyedParamAnnotation struct {
+	index int
+	key   interface{}
+	name  string
+	err   error
+}
+
+func (o keyedParamAnnotation) String() string {
+	return fmt.Sprintf("KeyedParam(%d, %v)", o.index, formatKey(o.key))
+}
+
+func (o keyedParamAnnotation) applyAnnotation(a *annotated) error {
+	if o.err != nil {
+		return o.err
+	}
+	if o.index < 0 {
+		return newErrInvalidInput(fmt.Sprintf("invalid %v: index must not be negative", o), nil)
+	}
+	if _, ok := a.KeyedParams[o.index]; ok {
+		return newErrInvalidInput(
+			fmt.Sprintf("cannot apply more than one KeyedParam to parameter %d", o.index), nil)
+	}
+	if a.KeyedParams == nil {
+		a.KeyedParams = make(map[int]string)
+	}
+	a.KeyedParams[o.index] = o.name
+	return nil
+}
+
+// keyName returns the name under which values provided with the given key
+// are stored. The name is derived from the type and the value of the key,
+// so that two keys get the same name if and only if they are equal. Names
+// contain a backquote, which user-supplied names cannot contain, so that
+// they never collide with them.
+//
+// Keys holding pointers or channels are identified by their addresses.
+func keyName(key interface{}) (string, error) {
+	t := reflect.TypeOf(key)
+	if t == nil {
+		return "", newErrInvalidInput("invalid nil key: keys must not be nil", nil)
+	}
+	if !t.Comparable() {
+		return "", newErrInvalidInput(
+			fmt.Sprintf("invalid key %v: keys must be comparable", formatKey(key)), nil)
+	}
+
+	var b strings.Builder
+	b.WriteString("`")
+	writeKeyType(&b, t)
+	b.WriteString("(")
+	if err := writeKeyValue(&b, reflect.ValueOf(key)); err != nil {
+		return "", newErrInvalidInput(
+			fmt.Sprintf("invalid key %v: %v", formatKey(key), err), nil)
+	}
+	b.WriteString(")")
+	return b.String(), nil
+}
+
+// writeKeyType writes the name of a key type, qualified with the import
+// path of its package if it is a named type.
+func writeKeyType(b *strings.Builder, t reflect.Type) {
+	if t.PkgPath() != "" && t.Name() != "" {
+		b.WriteString(t.PkgPath())
+		b.WriteString(".")
+		b.WriteString(t.Name())
+		return
+	}
+	b.WriteString(t.String())
+}
+
+// writeKeyValue writes a representation of a comparable value, which is
+// the same for two values of the same type if and only if they are equal.
+func writeKeyValue(b *strings.Builder, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return writeKeyFloat(b, v.Float(), v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		bits := v.Type().Bits() / 2
+		if err := writeKeyFloat(b, real(v.Complex()), bits); err != nil {
+			return err
+		}
+		b.WriteString("+")
+		if err := writeKeyFloat(b, imag(v.Complex()), bits); err != nil {
+			return err
+		}
+		b.WriteString("i")
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		fmt.Fprintf(b, "%#x", v.Pointer())
+	case reflect.Array:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			if err := writeKeyValue(b, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteString("]")
+	case reflect.Struct:
+		b.WriteString("{")
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			if err := writeKeyValue(b, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteString("}")
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return nil
+		}
+		elem := v.Elem()
+		if !elem.Type().Comparable() {
+			return fmt.Errorf("%v is not comparable", elem.Type())
+		}
+		writeKeyType(b, elem.Type())
+		b.WriteString("(")
+		if err := writeKeyValue(b, elem); err != nil {
+			return err
+		}
+		b.WriteString(")")
+	default:
+		return fmt.Errorf("%v is not comparable", v.Type())
+	}
+	return nil
+}
+
+// writeKeyFloat writes a floating-point number of a key. Zeros are equal
+// whatever their sign, and NaNs are equal to no key, not even themselves.
+func writeKeyFloat(b *strings.Builder, f float64, bits int) error {
+	if math.IsNaN(f) {
+		return errors.New("keys must not hold NaN")
+	}
+	if f == 0 {
+		f = 0 // turns -0 into 0
+	}
+	b.WriteString(strconv.FormatFloat(f, 'g', -1, bits))
+	return nil
+}
+
+// formatKey returns a representation of the key with its type, like
+// main.Env(1).
+func formatKey(key interface{}) string {
+	return fmt.Sprintf("%T(%v)", key, key)
+}
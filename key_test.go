@@ -0,0 +1,254 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyEnv int
+
+const (
+	keyStaging keyEnv = iota
+	keyProd
+)
+
+func (e keyEnv) String() string {
+	return [...]string{"staging", "prod"}[e]
+}
+
+func TestKeyed(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{ Env string }
+
+	t.Run("keyed values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{Env: "prod"} }, dig.Keyed(keyProd))
+		c.RequireProvide(func() *DB { return &DB{Env: "staging"} }, dig.Keyed(keyStaging))
+		c.RequireProvide(func() *DB { return &DB{Env: "unnamed"} })
+
+		c.RequireInvoke(dig.Annotate(func(prod, staging, unnamed *DB) {
+			assert.Equal(t, "prod", prod.Env)
+			assert.Equal(t, "staging", staging.Env)
+			assert.Equal(t, "unnamed", unnamed.Env)
+		}, dig.KeyedParam(0, keyProd), dig.KeyedParam(1, keyStaging)))
+	})
+
+	t.Run("struct keys", func(t *testing.T) {
+		t.Parallel()
+
+		type tenant struct {
+			Region string
+			ID     int
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{Env: "eu"} }, dig.Keyed(tenant{"eu", 1}))
+		c.RequireProvide(func() *DB { return &DB{Env: "us"} }, dig.Keyed(tenant{"us", 1}))
+
+		c.RequireInvoke(dig.Annotate(func(db *DB) {
+			assert.Equal(t, "us", db.Env)
+		}, dig.KeyedParam(0, tenant{"us", 1})))
+	})
+
+	t.Run("keys do not collide with names", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{Env: "keyed"} }, dig.Keyed("prod"))
+		c.RequireProvide(func() *DB { return &DB{Env: "named"} }, dig.Name("prod"))
+
+		type params struct {
+			dig.In
+
+			Named *DB `name:"prod"`
+		}
+		c.RequireInvoke(dig.Annotate(func(keyed *DB, p params) {
+			assert.Equal(t, "keyed", keyed.Env)
+			assert.Equal(t, "named", p.Named.Env)
+		}, dig.KeyedParam(0, "prod")))
+	})
+
+	t.Run("keys identified by value", func(t *testing.T) {
+		t.Parallel()
+
+		type tenant struct {
+			Labels [2]interface{}
+			Weight float64
+			Ref    *int
+		}
+		ref, other := new(int), new(int)
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{Env: "a"} },
+			dig.Keyed(tenant{Labels: [2]interface{}{"eu", 1}, Weight: 0, Ref: ref}))
+		c.RequireProvide(func() *DB { return &DB{Env: "b"} },
+			dig.Keyed(tenant{Labels: [2]interface{}{"eu", int64(1)}, Weight: 0, Ref: ref}))
+		c.RequireProvide(func() *DB { return &DB{Env: "c"} },
+			dig.Keyed(tenant{Labels: [2]interface{}{"eu", 1}, Weight: 0, Ref: other}))
+
+		c.RequireInvoke(dig.Annotate(func(a, b, c *DB) {
+			assert.Equal(t, "a", a.Env)
+			assert.Equal(t, "b", b.Env)
+			assert.Equal(t, "c", c.Env)
+		},
+			dig.KeyedParam(0, tenant{Labels: [2]interface{}{"eu", 1}, Weight: math.Copysign(0, -1), Ref: ref}),
+			dig.KeyedParam(1, tenant{Labels: [2]interface{}{"eu", int64(1)}, Ref: ref}),
+			dig.KeyedParam(2, tenant{Labels: [2]interface{}{"eu", 1}, Ref: other}),
+		))
+	})
+
+	t.Run("keys of different types", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{} }, dig.Keyed(1))
+
+		err := c.Invoke(dig.Annotate(func(*DB) {}, dig.KeyedParam(0, int64(1))))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.DB[name=\"`int64(1)")
+	})
+
+	t.Run("with ParamTags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB { return &DB{Env: "prod"} }, dig.Keyed(keyProd))
+		c.RequireProvide(func() string { return "addr" }, dig.Name("addr"))
+
+		c.RequireInvoke(dig.Annotate(func(addr string, db *DB) {
+			assert.Equal(t, "addr", addr)
+			assert.Equal(t, "prod", db.Env)
+		}, dig.ParamTags(`name:"addr"`), dig.KeyedParam(1, keyProd)))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			opts    []dig.ProvideOption
+			wantErr string
+		}{
+			{
+				desc:    "nil",
+				opts:    []dig.ProvideOption{dig.Keyed(nil)},
+				wantErr: "invalid nil key",
+			},
+			{
+				desc:    "not comparable",
+				opts:    []dig.ProvideOption{dig.Keyed([]string{"a"})},
+				wantErr: "invalid key []string([a]): keys must be comparable",
+			},
+			{
+				desc:    "holding a non comparable value",
+				opts:    []dig.ProvideOption{dig.Keyed([1]interface{}{[]string{"a"}})},
+				wantErr: "invalid key [1]interface {}([[a]])",
+			},
+			{
+				desc:    "NaN",
+				opts:    []dig.ProvideOption{dig.Keyed(math.NaN())},
+				wantErr: "invalid key float64(NaN): keys must not hold NaN",
+			},
+			{
+				desc:    "with Name",
+				opts:    []dig.ProvideOption{dig.Keyed(keyProd), dig.Name("prod")},
+				wantErr: "cannot use Keyed with Name",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Provide(func() *DB { return nil }, tt.opts...)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("invalid KeyedParam", func(t *testing.T) {
+		t.Parallel()
+
+		fn := func(*DB) {}
+		tests := []struct {
+			desc    string
+			anns    []dig.Annotation
+			wantErr string
+		}{
+			{
+				desc:    "out of range",
+				anns:    []dig.Annotation{dig.KeyedParam(1, keyProd)},
+				wantErr: "cannot use KeyedParam with parameter 1 of 1",
+			},
+			{
+				desc:    "negative",
+				anns:    []dig.Annotation{dig.KeyedParam(-1, keyProd)},
+				wantErr: "index must not be negative",
+			},
+			{
+				desc:    "twice",
+				anns:    []dig.Annotation{dig.KeyedParam(0, keyProd), dig.KeyedParam(0, keyStaging)},
+				wantErr: "cannot apply more than one KeyedParam to parameter 0",
+			},
+			{
+				desc:    "tagged",
+				anns:    []dig.Annotation{dig.ParamTags(`optional:"true"`), dig.KeyedParam(0, keyProd)},
+				wantErr: "cannot use KeyedParam with a tagged parameter 0",
+			},
+			{
+				desc:    "invalid key",
+				anns:    []dig.Annotation{dig.KeyedParam(0, nil)},
+				wantErr: "invalid nil key",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Invoke(dig.Annotate(fn, tt.anns...))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "Keyed(dig_test.keyEnv(prod))", fmt.Sprint(dig.Keyed(keyProd)))
+		assert.Equal(t, "KeyedParam(1, int(42))", fmt.Sprint(dig.KeyedParam(1, 42)))
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	_selectTag = "select"
+
+	// Prefix of the names under which labeled values are stored. Names
+	// given with Name cannot contain backquotes, so they never collide with
+	// labeled values.
+	_labelsNamePrefix = "`labels:"
+)
+
+// Labels is a ProvideOption that attaches labels of the form "key=value" to
+// all values produced by a constructor. Labeled values are not provided as
+// the unnamed values of their types: they are consumed with selectors, which
+// pick the only value of a type whose labels match all the selector's.
+//
+//	c.Provide(NewEUHotCache, dig.Labels("region=eu", "tier=hot"))
+//	c.Provide(NewEUColdCache, dig.Labels("region=eu", "tier=cold"))
+//	c.Provide(NewUSHotCache, dig.Labels("region=us", "tier=hot"))
+//
+//	type params struct {
+//	  dig.In
+//
+//	  Cache *Cache `select:"region=eu,tier=hot"`
+//	}
+//
+// Two constructors cannot provide values of the same type with the same
+// labels. Labels cannot be combined with Name or Keyed, nor with value
+// groups. Multiple Labels options on the same constructor are merged.
+func Labels(labels ...string) ProvideOption {
+	return provideLabelsOption(labels)
+}
+
+type provideLabelsOption []string
+
+func (o provideLabelsOption) String() string {
+	return fmt.Sprintf("Labels(%q)", []string(o))
+}
+
+func (o provideLabelsOption) applyProvideOption(opts *provideOptions) {
+	opts.Labels = append(opts.Labels, o...)
+}
+
+// Select returns a `select:".."` struct tag holding the given selectors of
+// the form "key=value", for use with ParamTags or in dig.In structs. The
+// parameter receives the only value of its type provided with Labels that
+// match all the selectors. See Labels.
+//
+//	c.Invoke(dig.Annotate(func(c *Cache) {
+//	  ..
+//	}, dig.ParamTags(dig.Select("region=eu", "tier=hot"))))
+//
+// Resolving the parameter fails if several values match the selectors, or
+// if none does and the parameter is not optional.
+func Select(selectors ...string) string {
+	return fmt.Sprintf("%v:%q", _selectTag, strings.Join(selectors, ","))
+}
+
+// parseLabels validates the given labels and returns them sorted, without
+// duplicates.
+func parseLabels(labels []string) ([]string, error) {
+	values := make(map[string]string, len(labels))
+	for _, l := range labels {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok || k == "" || strings.ContainsAny(l, ",`") {
+			return nil, newErrInvalidInput(fmt.Sprintf(
+				"invalid label %q: labels must be of the form key=value, without commas or backquotes", l), nil)
+		}
+		if old, ok := values[k]; ok && old != v {
+			return nil, newErrInvalidInput(
+				fmt.Sprintf("conflicting labels %q and %q", k+"="+old, l), nil)
+		}
+		values[k] = v
+	}
+
+	parsed := make([]string, 0, len(values))
+	for k, v := range values {
+		parsed = append(parsed, k+"="+v)
+	}
+	sort.Strings(parsed)
+	return parsed, nil
+}
+
+// labelsName returns the name under which values with the given parsed
+// labels are stored.
+func labelsName(labels []string) string {
+	return _labelsNamePrefix + strings.Join(labels, ",")
+}
+
+// fieldSelector returns the selectors of the `select:".."` tag of the
+// field, if any.
+func fieldSelector(f reflect.StructField) ([]string, error) {
+	tag, ok := f.Tag.Lookup(_selectTag)
+	if !ok {
+		return nil, nil
+	}
+	if f.Tag.Get(_nameTag) != "" {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot use %q and %q tags on the same field %v", _nameTag, _selectTag, f.Name), nil)
+	}
+
+	var selectors []string
+	if tag != "" {
+		selectors = strings.Split(tag, ",")
+	}
+	selectors, err := parseLabels(selectors)
+	if err == nil && len(selectors) == 0 {
+		err = newErrInvalidInput("selectors must not be empty", nil)
+	}
+	if err != nil {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("invalid value %q for %q tag on field %v", tag, _selectTag, f.Name), err)
+	}
+	return selectors, nil
+}
+
+// selectName returns the name of the value of the type of ps whose labels
+// match the selectors of ps, looking up c and its ancestors. It returns an
+// empty name if there is no such value.
+func (ps paramSingle) selectName(c containerStore) (string, error) {
+	var matches []string
+	seen := make(map[string]struct{})
+	for _, s := range c.storesToRoot() {
+		for _, name := range s.knownNames(ps.Type) {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			if ps.selects(name) {
+				matches = append(matches, name)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	}
+
+	labels := make([]string, len(matches))
+	for i, name := range matches {
+		labels[i] = fmt.Sprintf("%q", strings.TrimPrefix(name, _labelsNamePrefix))
+	}
+	sort.Strings(labels)
+	return "", newErrInvalidInput(fmt.Sprintf("selector %q of %v matches several values, labeled %v",
+		strings.Join(ps.Selector, ","), ps.Type, strings.Join(labels, ", ")), nil)
+}
+
+// selects reports whether the value stored under the given name has all
+// the labels selected by ps.
+func (ps paramSingle) selects(name string) bool {
+	if !strings.HasPrefix(name, _labelsNamePrefix) {
+		return false
+	}
+
+	labels := strings.Split(strings.TrimPrefix(name, _labelsNamePrefix), ",")
+	for _, sel := range ps.Selector {
+		i := sort.SearchStrings(labels, sel)
+		if i == len(labels) || labels[i] != sel {
+			return false
+		}
+	}
+	return true
+}
+
+// noSelectedValue returns the error reported when no value matches the
+// selectors of ps.
+func (ps paramSingle) noSelectedValue() error {
+	return newErrInvalidInput(fmt.Sprintf(
+		"no value of %v is labeled to match selector %q", ps.Type, strings.Join(ps.Selector, ",")), nil)
+}
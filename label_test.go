@@ -0,0 +1,243 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	t.Parallel()
+
+	type Cache struct{ Name string }
+
+	provideCaches := func(c *digtest.Container) {
+		c.RequireProvide(func() *Cache { return &Cache{Name: "eu-hot"} }, dig.Labels("region=eu", "tier=hot"))
+		c.RequireProvide(func() *Cache { return &Cache{Name: "eu-cold"} }, dig.Labels("region=eu"), dig.Labels("tier=cold"))
+		c.RequireProvide(func() *Cache { return &Cache{Name: "us-hot"} }, dig.Labels("tier=hot", "region=us"))
+	}
+
+	t.Run("select tags", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			EUHot  *Cache `select:"tier=hot,region=eu"`
+			Cold   *Cache `select:"tier=cold"`
+			USHot  *Cache `select:"region=us"`
+			Absent *Cache `select:"region=ap" optional:"true"`
+		}
+
+		c := digtest.New(t)
+		provideCaches(c)
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "eu-hot", p.EUHot.Name)
+			assert.Equal(t, "eu-cold", p.Cold.Name)
+			assert.Equal(t, "us-hot", p.USHot.Name)
+			assert.Nil(t, p.Absent)
+		})
+	})
+
+	t.Run("Select with ParamTags", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideCaches(c)
+		c.RequireInvoke(dig.Annotate(func(c *Cache) {
+			assert.Equal(t, "eu-cold", c.Name)
+		}, dig.ParamTags(dig.Select("region=eu", "tier=cold"))))
+	})
+
+	t.Run("labeled values are not unnamed values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideCaches(c)
+		c.RequireProvide(func() *Cache { return &Cache{Name: "default"} })
+		c.RequireInvoke(func(c *Cache) {
+			assert.Equal(t, "default", c.Name)
+		})
+	})
+
+	t.Run("dependencies of constructors", func(t *testing.T) {
+		t.Parallel()
+
+		type Server struct{ Cache *Cache }
+		type params struct {
+			dig.In
+
+			Cache *Cache `select:"region=us"`
+		}
+
+		c := digtest.New(t)
+		provideCaches(c)
+		child := c.Scope("child")
+		child.RequireProvide(func(p params) *Server { return &Server{Cache: p.Cache} })
+		child.RequireInvoke(func(s *Server) {
+			assert.Equal(t, "us-hot", s.Cache.Name)
+		})
+	})
+
+	t.Run("ambiguous selector", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Cache *Cache `select:"region=eu"`
+		}
+
+		c := digtest.New(t)
+		provideCaches(c)
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`selector "region=eu" of *dig_test.Cache matches several values, labeled "region=eu,tier=cold", "region=eu,tier=hot"`)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Cache *Cache `select:"region=ap"`
+		}
+
+		c := digtest.New(t)
+		provideCaches(c)
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no value of *dig_test.Cache is labeled to match selector "region=ap"`)
+	})
+
+	t.Run("same labels twice", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provideCaches(c)
+		err := c.Provide(func() *Cache { return nil }, dig.Labels("tier=hot", "region=eu"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already provided")
+	})
+
+	t.Run("invalid labels", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			opts    []dig.ProvideOption
+			wantErr string
+		}{
+			{
+				desc:    "no value",
+				opts:    []dig.ProvideOption{dig.Labels("region")},
+				wantErr: `invalid label "region": labels must be of the form key=value`,
+			},
+			{
+				desc:    "comma",
+				opts:    []dig.ProvideOption{dig.Labels("region=eu,tier=hot")},
+				wantErr: `invalid label "region=eu,tier=hot"`,
+			},
+			{
+				desc:    "conflicting",
+				opts:    []dig.ProvideOption{dig.Labels("region=eu"), dig.Labels("region=us")},
+				wantErr: `conflicting labels "region=eu" and "region=us"`,
+			},
+			{
+				desc:    "with Name",
+				opts:    []dig.ProvideOption{dig.Labels("region=eu"), dig.Name("eu")},
+				wantErr: "cannot use Labels with Name or Keyed",
+			},
+			{
+				desc:    "with Group",
+				opts:    []dig.ProvideOption{dig.Labels("region=eu"), dig.Group("caches")},
+				wantErr: `cannot use Labels with value groups: group:"caches"`,
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Provide(func() *Cache { return nil }, tt.opts...)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("invalid selectors", func(t *testing.T) {
+		t.Parallel()
+
+		type empty struct {
+			dig.In
+
+			Cache *Cache `select:""`
+		}
+		type named struct {
+			dig.In
+
+			Cache *Cache `name:"eu" select:"region=eu"`
+		}
+		type malformed struct {
+			dig.In
+
+			Cache *Cache `select:"region"`
+		}
+
+		tests := []struct {
+			desc    string
+			give    interface{}
+			wantErr string
+		}{
+			{"empty", func(empty) {}, `invalid value "" for "select" tag on field Cache`},
+			{"named", func(named) {}, `cannot use "name" and "select" tags on the same field Cache`},
+			{"malformed", func(malformed) {}, `invalid value "region" for "select" tag on field Cache`},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).Invoke(tt.give)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `Labels(["region=eu" "tier=hot"])`, fmt.Sprint(dig.Labels("region=eu", "tier=hot")))
+		assert.Equal(t, `select:"region=eu,tier=hot"`, dig.Select("region=eu", "tier=hot"))
+	})
+}
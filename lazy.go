@@ -0,0 +1,189 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alexisvisco/dig/internal/dot"
+)
+
+// Lazy is a dependency on a value of type T that is only built when Get is
+// called, rather than before the function depending on it is called.
+//
+//	c.Provide(func(db dig.Lazy[*sql.DB]) *Handler {
+//	  return &Handler{db: db}
+//	})
+//
+//	func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//	  db, err := h.db.Get()
+//	  ..
+//	}
+//
+// Lazy dependencies may be used as parameters of constructors, decorators,
+// and functions given to Invoke, as well as fields of dig.In structs, where
+// they support the name and optional tags. The value of a Lazy dependency
+// is resolved from the Container or Scope the function was called with, and
+// its dependencies are checked to be provided when the function is called.
+//
+// Lazy dependencies are edges of the dependency graph like any other
+// dependency, unless the container was built with
+// AllowCyclesVia(LazyDependency).
+//
+// The zero Lazy is not usable: Lazy values are only produced by dig.
+type Lazy[T any] struct {
+	get func() (reflect.Value, error)
+}
+
+// Get builds the value of the dependency, along with its own dependencies,
+// and returns it. The value is built once, as any value of the container,
+// unless its constructor is transient: further calls return the same value.
+// Failures are not remembered: after a failed call, further calls try to
+// build the value again.
+//
+// Get is safe for concurrent use, such as from HTTP handlers: concurrent
+// calls are serialized, and a value needed by several of them is built
+// once. Get must not be called while the container is used by another
+// goroutine, unless it was built with ConcurrencySafe or MaxConcurrency.
+func (l Lazy[T]) Get() (T, error) {
+	var zero T
+	if l.get == nil {
+		return zero, newErrInvalidInput(fmt.Sprintf("%v was not provided by dig", reflect.TypeOf(l)), nil)
+	}
+	v, err := l.get()
+	if err != nil {
+		return zero, err
+	}
+	return v.Interface().(T), nil
+}
+
+func (Lazy[T]) lazyElem() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (Lazy[T]) withGetter(get func() (reflect.Value, error)) reflect.Value {
+	return reflect.ValueOf(Lazy[T]{get: get})
+}
+
+// DeferredDependency is a kind of dependency whose value is not needed to
+// call the function depending on it. See AllowCyclesVia.
+type DeferredDependency int
+
+const (
+	// LazyDependency designates dig.Lazy dependencies.
+	LazyDependency DeferredDependency = iota + 1
+)
+
+func (d DeferredDependency) String() string {
+	switch d {
+	case LazyDependency:
+		return "LazyDependency"
+	default:
+		return fmt.Sprintf("DeferredDependency(%d)", int(d))
+	}
+}
+
+// AllowCyclesVia is an Option that allows cycles in the dependency graph,
+// provided that they contain a dependency of one of the given kinds. Such
+// dependencies are left out of cycle detection, since their values are not
+// needed while the functions depending on them are called.
+//
+//	c := dig.New(dig.AllowCyclesVia(dig.LazyDependency))
+//	c.Provide(func(b dig.Lazy[*B]) *A { .. })
+//	c.Provide(func(a *A) *B { .. })
+//
+// The values of the cycle are still built one after the other: calling
+// Get on a Lazy dependency from within a function that its value itself
+// depends on fails with a cycle error, or never returns if the container
+// resolves values in parallel.
+func AllowCyclesVia(deps ...DeferredDependency) Option {
+	return allowCyclesViaOption(deps)
+}
+
+type allowCyclesViaOption []DeferredDependency
+
+func (o allowCyclesViaOption) String() string {
+	deps := make([]string, len(o))
+	for i, d := range o {
+		deps[i] = d.String()
+	}
+	return fmt.Sprintf("AllowCyclesVia(%v)", strings.Join(deps, ", "))
+}
+
+func (o allowCyclesViaOption) applyOption(c *Container) {
+	for _, d := range o {
+		if d == LazyDependency {
+			c.scope.state.lazyCycles = true
+		}
+	}
+}
+
+// lazyDependency is implemented by all the instantiations of Lazy.
+type lazyDependency interface {
+	lazyElem() reflect.Type
+	withGetter(get func() (reflect.Value, error)) reflect.Value
+}
+
+var _lazyDependencyType = reflect.TypeOf((*lazyDependency)(nil)).Elem()
+
+func isLazy(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(_lazyDependencyType)
+}
+
+// paramLazy is a Lazy dependency on the value requested by Elem.
+type paramLazy struct {
+	// Type of the Lazy dependency.
+	Type reflect.Type
+
+	Elem paramSingle
+}
+
+var _ param = paramLazy{}
+
+func newParamLazy(t reflect.Type) (paramLazy, error) {
+	elem := reflect.Zero(t).Interface().(lazyDependency).lazyElem()
+	if isLazy(elem) || isOptional(elem) || isOriginal(elem) || IsIn(elem) || IsOut(elem) {
+		return paramLazy{}, newErrInvalidInput(fmt.Sprintf(
+			"cannot depend on %v: lazy dependencies must be single values", t), nil)
+	}
+	return paramLazy{Type: t, Elem: paramSingle{Type: elem}}, nil
+}
+
+func (pl paramLazy) String() string {
+	return fmt.Sprintf("Lazy[%v]", pl.Elem)
+}
+
+func (pl paramLazy) DotParam() []*dot.Param {
+	return pl.Elem.DotParam()
+}
+
+func (pl paramLazy) Build(c containerStore) (reflect.Value, error) {
+	get := func() (v reflect.Value, err error) {
+		err = c.lazyGet(func() error {
+			v, err = pl.Elem.Build(c)
+			return err
+		})
+		return v, err
+	}
+	return reflect.Zero(pl.Type).Interface().(lazyDependency).withGetter(get), nil
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazy(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ name string }
+	type B struct{ a dig.Lazy[*A] }
+
+	t.Run("resolved on Get", func(t *testing.T) {
+		t.Parallel()
+
+		var built bool
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			built = true
+			return &A{"a"}
+		})
+		c.RequireProvide(func(a dig.Lazy[*A]) *B { return &B{a} })
+
+		var b *B
+		c.RequireInvoke(func(got *B) { b = got })
+		assert.False(t, built, "A must not be built before Get")
+
+		a, err := b.a.Get()
+		require.NoError(t, err)
+		assert.True(t, built)
+		assert.Equal(t, "a", a.name)
+
+		again, err := b.a.Get()
+		require.NoError(t, err)
+		assert.Same(t, a, again)
+	})
+
+	t.Run("fields of parameter objects", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			dig.In
+
+			Named    dig.Lazy[*A] `name:"named"`
+			Optional dig.Lazy[*B] `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{"named"} }, dig.Name("named"))
+		c.RequireInvoke(func(got in) {
+			a, err := got.Named.Get()
+			require.NoError(t, err)
+			assert.Equal(t, "named", a.name)
+
+			b, err := got.Optional.Get()
+			require.NoError(t, err)
+			assert.Nil(t, b)
+		})
+	})
+
+	t.Run("missing dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(dig.Lazy[*A]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("errors are returned by Get", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, error) { return nil, errors.New("great sadness") })
+		c.RequireInvoke(func(a dig.Lazy[*A]) {
+			_, err := a.Get()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "great sadness")
+		})
+	})
+
+	t.Run("failures are not remembered", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		c := digtest.New(t)
+		c.RequireProvide(func() (*A, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("great sadness")
+			}
+			return &A{"a"}, nil
+		})
+		c.RequireInvoke(func(a dig.Lazy[*A]) {
+			_, err := a.Get()
+			require.Error(t, err)
+
+			got, err := a.Get()
+			require.NoError(t, err)
+			assert.Equal(t, "a", got.name)
+			assert.Equal(t, 2, calls)
+		})
+	})
+
+	t.Run("concurrent Gets", func(t *testing.T) {
+		t.Parallel()
+
+		type C struct{ a *A }
+
+		var calls atomic.Int32
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			calls.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return &A{"a"}
+		})
+		// Get from a constructor resolved by another Get.
+		c.RequireProvide(func(a dig.Lazy[*A]) (*C, error) {
+			got, err := a.Get()
+			return &C{got}, err
+		})
+
+		var (
+			lazyA dig.Lazy[*A]
+			lazyC dig.Lazy[*C]
+		)
+		c.RequireInvoke(func(a dig.Lazy[*A], c dig.Lazy[*C]) {
+			lazyA, lazyC = a, c
+		})
+
+		var wg sync.WaitGroup
+		results := make([]*A, 10)
+		for i := range results {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if i%2 == 0 {
+					got, err := lazyA.Get()
+					assert.NoError(t, err)
+					results[i] = got
+					return
+				}
+				got, err := lazyC.Get()
+				assert.NoError(t, err)
+				results[i] = got.a
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		for _, a := range results {
+			assert.Same(t, results[0], a)
+		}
+	})
+
+	t.Run("cycles are detected by default", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(a dig.Lazy[*A]) *B { return &B{a} })
+		err := c.Provide(func(*B) *A { return &A{} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "this function introduces a cycle")
+	})
+
+	t.Run("AllowCyclesVia", func(t *testing.T) {
+		t.Parallel()
+
+		type C struct{ b *B }
+
+		c := digtest.New(t, dig.AllowCyclesVia(dig.LazyDependency))
+		c.RequireProvide(func(b *B) *C { return &C{b} })
+		c.RequireProvide(func(a dig.Lazy[*A]) *B { return &B{a} })
+		c.RequireProvide(func(c dig.Lazy[*C]) *A { return &A{"a"} })
+
+		c.RequireInvoke(func(got *C) {
+			a, err := got.b.a.Get()
+			require.NoError(t, err)
+			assert.Equal(t, "a", a.name)
+		})
+	})
+
+	t.Run("Get within a cycle", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.AllowCyclesVia(dig.LazyDependency))
+		c.RequireProvide(func(a dig.Lazy[*A]) (*B, error) {
+			_, err := a.Get()
+			return &B{a}, err
+		})
+		c.RequireProvide(func(*B) *A { return &A{} })
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle detected")
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		t.Parallel()
+
+		var a dig.Lazy[*A]
+		_, err := a.Get()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "was not provided by dig")
+	})
+
+	t.Run("cannot be provided", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() dig.Lazy[*A] { return dig.Lazy[*A]{} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot provide lazy dependencies")
+	})
+
+	t.Run("AllowCyclesVia string", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "AllowCyclesVia(LazyDependency)", fmt.Sprint(dig.AllowCyclesVia(dig.LazyDependency)))
+		assert.Equal(t, "DeferredDependency(42)", dig.DeferredDependency(42).String())
+	})
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// Starter is implemented by values that must be started before the
+// application runs. See WithLifecycle.
+type Starter interface {
+	Start(context.Context) error
+}
+
+// Stopper is implemented by values that must be stopped when the
+// application shuts down. See WithLifecycle.
+type Stopper interface {
+	Stop(context.Context) error
+}
+
+// OnStart is a ProvideOption that registers a hook to be run by
+// Container.Start once the constructor has been called successfully.
+//
+//	c.Provide(NewServer, dig.OnStart(func(ctx context.Context) error {
+//	  return migrate(ctx)
+//	}))
+//
+// Hooks are registered when the constructor is called. Since dependencies
+// are built before their dependents, hooks run in dependency order.
+func OnStart(hook func(context.Context) error) ProvideOption {
+	return provideHookOption{onStart: hook}
+}
+
+// OnStop is a ProvideOption that registers a hook to be run by
+// Container.Stop once the constructor has been called successfully.
+//
+// Stop hooks run in the reverse order of their registration, so values
+// are stopped before their dependencies.
+func OnStop(hook func(context.Context) error) ProvideOption {
+	return provideHookOption{onStop: hook}
+}
+
+type provideHookOption struct {
+	onStart func(context.Context) error
+	onStop  func(context.Context) error
+}
+
+func (o provideHookOption) String() string {
+	if o.onStart != nil {
+		return fmt.Sprintf("OnStart(%v)", digreflect.InspectFunc(o.onStart))
+	}
+	return fmt.Sprintf("OnStop(%v)", digreflect.InspectFunc(o.onStop))
+}
+
+func (o provideHookOption) applyProvideOption(opts *provideOptions) {
+	if o.onStart != nil || o.onStop != nil {
+		opts.Hooks = append(opts.Hooks, lifecycleHook{
+			OnStart: o.onStart,
+			OnStop:  o.onStop,
+		})
+	}
+}
+
+// WithLifecycle is a ProvideOption that registers the values produced by
+// the constructor that implement Starter or Stopper with the lifecycle of
+// the Container when the constructor is called.
+//
+//	c.Provide(NewServer, dig.WithLifecycle())
+//
+// Container.Start then calls the Start method of the values, and
+// Container.Stop calls their Stop method. See OnStart and OnStop for
+// details on ordering.
+func WithLifecycle() ProvideOption {
+	return provideLifecycleOption{}
+}
+
+type provideLifecycleOption struct{}
+
+func (provideLifecycleOption) String() string {
+	return "WithLifecycle()"
+}
+
+func (provideLifecycleOption) applyProvideOption(opts *provideOptions) {
+	opts.Lifecycle = true
+}
+
+// lifecycleHook is a pair of functions to run when the Container is started
+// and stopped.
+type lifecycleHook struct {
+	OnStart func(context.Context) error
+	OnStop  func(context.Context) error
+}
+
+// lifecycle holds the hooks registered by constructors, in the order in
+// which they were registered.
+type lifecycle struct {
+	hooks []lifecycleHook
+
+	// Number of hooks at the start of hooks that were started.
+	numStarted int
+}
+
+func (l *lifecycle) append(hooks ...lifecycleHook) {
+	l.hooks = append(l.hooks, hooks...)
+}
+
+// start runs the OnStart hooks that haven't been started yet. If a hook
+// fails, the hooks started so far are stopped in reverse order.
+func (l *lifecycle) start(ctx context.Context) error {
+	for l.numStarted < len(l.hooks) {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(err, l.stop(ctx))
+		}
+
+		h := l.hooks[l.numStarted]
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				return errors.Join(err, l.stop(ctx))
+			}
+		}
+		l.numStarted++
+	}
+	return nil
+}
+
+// stop runs the OnStop hooks of the started hooks in reverse order. All
+// hooks are run, even if some of them fail.
+func (l *lifecycle) stop(ctx context.Context) error {
+	var errs []error
+	for ; l.numStarted > 0; l.numStarted-- {
+		if h := l.hooks[l.numStarted-1]; h.OnStop != nil {
+			if err := h.OnStop(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start runs the start hooks registered by the constructors called so far,
+// in the order in which they were registered. See OnStart and WithLifecycle.
+//
+// If a hook fails, the hooks started so far are stopped and the error is
+// returned. Hooks registered by constructors called after Start returns
+// are started by the next call to Start.
+func (c *Container) Start(ctx context.Context) error {
+	return c.scope.state.lifecycle.start(ctx)
+}
+
+// Stop runs the stop hooks of everything started by Start, in the reverse
+// order of their registration. See OnStop and WithLifecycle.
+//
+// All stop hooks are run even if some of them fail, in which case the
+// returned error holds all the failures.
+func (c *Container) Stop(ctx context.Context) error {
+	return c.scope.state.lifecycle.stop(ctx)
+}
+
+// lifecycleHooks returns the lifecycle hooks for the values of the given
+// result that implement Starter or Stopper.
+func lifecycleHooks(r result, v reflect.Value) []lifecycleHook {
+	switch r := r.(type) {
+	case resultSingle:
+		return lifecycleHooksOf(v)
+	case resultGrouped:
+		if !r.Flatten {
+			return lifecycleHooksOf(v)
+		}
+		var hooks []lifecycleHook
+		for i := 0; i < v.Len(); i++ {
+			hooks = append(hooks, lifecycleHooksOf(v.Index(i))...)
+		}
+		return hooks
+	case resultObject:
+		var hooks []lifecycleHook
+		for _, f := range r.Fields {
+			hooks = append(hooks, lifecycleHooks(f.Result, v.Field(f.FieldIndex))...)
+		}
+		return hooks
+	}
+	return nil
+}
+
+// lifecycleHooksFromList returns the lifecycle hooks for the values
+// returned by a constructor with the given resultList.
+func lifecycleHooksFromList(rl resultList, values []reflect.Value) []lifecycleHook {
+	var hooks []lifecycleHook
+	for i, v := range values {
+		if idx := rl.resultIndexes[i]; idx >= 0 {
+			hooks = append(hooks, lifecycleHooks(rl.Results[idx], v)...)
+		}
+	}
+	return hooks
+}
+
+func lifecycleHooksOf(v reflect.Value) []lifecycleHook {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	var h lifecycleHook
+	if s, ok := v.Interface().(Starter); ok {
+		h.OnStart = s.Start
+	}
+	if s, ok := v.Interface().(Stopper); ok {
+		h.OnStop = s.Stop
+	}
+	if h.OnStart == nil && h.OnStop == nil {
+		return nil
+	}
+	return []lifecycleHook{h}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lifecycleComponent struct {
+	name   string
+	events *[]string
+	err    error
+}
+
+func (c *lifecycleComponent) Start(context.Context) error {
+	*c.events = append(*c.events, "start "+c.name)
+	return c.err
+}
+
+func (c *lifecycleComponent) Stop(context.Context) error {
+	*c.events = append(*c.events, "stop "+c.name)
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	t.Parallel()
+
+	type DB struct{ *lifecycleComponent }
+	type Server struct{ *lifecycleComponent }
+
+	t.Run("hooks run in dependency order", func(t *testing.T) {
+		t.Parallel()
+
+		var events []string
+		hook := func(event string) func(context.Context) error {
+			return func(context.Context) error {
+				events = append(events, event)
+				return nil
+			}
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*DB) *Server {
+			return &Server{&lifecycleComponent{name: "server", events: &events}}
+		}, dig.WithLifecycle(), dig.OnStart(hook("start hook")), dig.OnStop(hook("stop hook")))
+		c.RequireProvide(func() *DB {
+			return &DB{&lifecycleComponent{name: "db", events: &events}}
+		}, dig.WithLifecycle())
+		c.RequireInvoke(func(*Server) {})
+
+		require.NoError(t, c.Start(context.Background()))
+		assert.Equal(t, []string{"start db", "start server", "start hook"}, events)
+
+		events = nil
+		require.NoError(t, c.Stop(context.Background()))
+		assert.Equal(t, []string{"stop hook", "stop server", "stop db"}, events)
+	})
+
+	t.Run("values are not registered without WithLifecycle", func(t *testing.T) {
+		t.Parallel()
+
+		var events []string
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB {
+			return &DB{&lifecycleComponent{name: "db", events: &events}}
+		})
+		c.RequireInvoke(func(*DB) {})
+
+		require.NoError(t, c.Start(context.Background()))
+		require.NoError(t, c.Stop(context.Background()))
+		assert.Empty(t, events)
+	})
+
+	t.Run("values produced by result objects", func(t *testing.T) {
+		t.Parallel()
+
+		type Result struct {
+			dig.Out
+
+			DB     *DB
+			Server *Server `group:"servers"`
+		}
+
+		var events []string
+		c := digtest.New(t)
+		c.RequireProvide(func() Result {
+			return Result{
+				DB:     &DB{&lifecycleComponent{name: "db", events: &events}},
+				Server: &Server{&lifecycleComponent{name: "server", events: &events}},
+			}
+		}, dig.WithLifecycle())
+		c.RequireInvoke(func(*DB) {})
+
+		require.NoError(t, c.Start(context.Background()))
+		assert.Equal(t, []string{"start db", "start server"}, events)
+	})
+
+	t.Run("failed start stops started hooks", func(t *testing.T) {
+		t.Parallel()
+
+		var events []string
+		c := digtest.New(t)
+		c.RequireProvide(func() *DB {
+			return &DB{&lifecycleComponent{name: "db", events: &events}}
+		}, dig.WithLifecycle())
+		c.RequireProvide(func(*DB) *Server {
+			return &Server{&lifecycleComponent{
+				name:   "server",
+				events: &events,
+				err:    errors.New("great sadness"),
+			}}
+		}, dig.WithLifecycle())
+		c.RequireInvoke(func(*Server) {})
+
+		err := c.Start(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Equal(t, []string{"start db", "start server", "stop db"}, events)
+
+		events = nil
+		require.NoError(t, c.Stop(context.Background()))
+		assert.Empty(t, events, "nothing left to stop")
+	})
+
+	t.Run("stop runs all hooks and reports all errors", func(t *testing.T) {
+		t.Parallel()
+
+		var stopped int
+		failStop := func(msg string) func(context.Context) error {
+			return func(context.Context) error {
+				stopped++
+				return errors.New(msg)
+			}
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.OnStop(failStop("first")))
+		c.RequireProvide(func(int) string { return "" }, dig.OnStop(failStop("second")))
+		c.RequireInvoke(func(string) {})
+
+		require.NoError(t, c.Start(context.Background()))
+		err := c.Stop(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, 2, stopped)
+		assert.Contains(t, err.Error(), "first")
+		assert.Contains(t, err.Error(), "second")
+	})
+
+	t.Run("hooks of scoped constructors", func(t *testing.T) {
+		t.Parallel()
+
+		var started bool
+		c := digtest.New(t)
+		s := c.Scope("child")
+		s.RequireProvide(func() int { return 1 }, dig.OnStart(func(context.Context) error {
+			started = true
+			return nil
+		}))
+		s.RequireInvoke(func(int) {})
+
+		require.NoError(t, c.Start(context.Background()))
+		assert.True(t, started)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.OnStart(func(context.Context) error {
+			t.Fatal("hook must not run")
+			return nil
+		}))
+		c.RequireInvoke(func(int) {})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, c.Start(ctx), context.Canceled)
+	})
+}
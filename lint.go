@@ -0,0 +1,198 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Tags that dig reads on the fields of dig.Out structs.
+var _outTags = []string{_nameTag, _groupTag, _weightTag}
+
+// A LintIssue is a problem found by LintTypes in a dig.In or dig.Out
+// struct.
+type LintIssue struct {
+	// Type of the struct with the issue.
+	Type reflect.Type
+
+	// Name of the field with the issue, or empty if the issue is with the
+	// type itself.
+	Field string
+
+	// Message describing the issue.
+	Message string
+}
+
+func (i LintIssue) String() string {
+	switch {
+	case i.Type == nil:
+		return i.Message
+	case i.Field == "":
+		return fmt.Sprintf("%v: %v", i.Type, i.Message)
+	default:
+		return fmt.Sprintf("%v.%v: %v", i.Type, i.Field, i.Message)
+	}
+}
+
+// LintTypes checks the given dig.In and dig.Out structs without providing
+// them to a container, and returns the issues found in them. This lets
+// projects catch wiring mistakes in their unit tests:
+//
+//	func TestWiring(t *testing.T) {
+//		for _, issue := range dig.LintTypes(ServerParams{}, HandlerResult{}, NewClient) {
+//			t.Error(issue)
+//		}
+//	}
+//
+// Each of the types may be a struct, a pointer to one, its reflect.Type,
+// or a function whose dig.In parameters and dig.Out results are checked.
+// The issues include the errors Provide would return for the structs, such
+// as value groups consumed by non-slice fields, names used with value
+// groups or unexported fields, as well as the issues rejected by StrictIn
+// for dig.In structs, such as misspelled tags. Structs nested in the given
+// ones are checked too.
+func LintTypes(types ...interface{}) []LintIssue {
+	l := linter{
+		store: New().scope,
+		seen:  make(map[reflect.Type]struct{}),
+	}
+	for _, v := range types {
+		l.lint(v)
+	}
+	return l.issues
+}
+
+// linter collects the issues of the types given to LintTypes.
+type linter struct {
+	// Scope the fields of dig.In structs are parsed against.
+	store  *Scope
+	seen   map[reflect.Type]struct{}
+	issues []LintIssue
+}
+
+func (l *linter) report(t reflect.Type, field string, err error) {
+	l.issues = append(l.issues, LintIssue{Type: t, Field: field, Message: err.Error()})
+}
+
+func (l *linter) lint(v interface{}) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		l.report(nil, "", newErrInvalidInput("cannot lint an untyped nil", nil))
+		return
+	}
+	if t.Kind() == reflect.Ptr && (IsIn(t.Elem()) || IsOut(t.Elem())) {
+		t = t.Elem()
+	}
+
+	switch {
+	case IsIn(t):
+		l.lintIn(t)
+	case IsOut(t):
+		l.lintOut(t)
+	case t.Kind() == reflect.Func:
+		for i := 0; i < t.NumIn(); i++ {
+			if IsIn(t.In(i)) {
+				l.lintIn(t.In(i))
+			}
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			if IsOut(t.Out(i)) {
+				l.lintOut(t.Out(i))
+			}
+		}
+	default:
+		l.report(t, "", newErrInvalidInput(
+			fmt.Sprintf("%v is not a dig.In or dig.Out struct", t), nil))
+	}
+}
+
+func (l *linter) lintIn(t reflect.Type) {
+	if _, ok := l.seen[t]; ok {
+		return
+	}
+	l.seen[t] = struct{}{}
+
+	var ignoreUnexported bool
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if err := checkFieldTags(f, _inTags); err != nil {
+			l.report(t, f.Name, err)
+			continue
+		}
+		if f.Type == _inType {
+			var err error
+			if ignoreUnexported, err = isIgnoreUnexportedSet(f); err != nil {
+				l.report(t, f.Name, err)
+			}
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch {
+		case f.Type == _inType:
+			continue
+		case f.PkgPath != "" && ignoreUnexported:
+			continue
+		case f.PkgPath == "" && f.Anonymous && !IsIn(f.Type):
+			l.report(t, f.Name, errEmbeddedField(f, "dig.In"))
+			continue
+		case f.PkgPath == "" && IsIn(f.Type):
+			l.lintIn(f.Type)
+			continue
+		}
+		if _, err := newParamObjectField(i, f, l.store); err != nil {
+			l.report(t, f.Name, err)
+		}
+	}
+}
+
+func (l *linter) lintOut(t reflect.Type) {
+	if _, ok := l.seen[t]; ok {
+		return
+	}
+	l.seen[t] = struct{}{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if err := checkFieldTags(f, _outTags); err != nil {
+			l.report(t, f.Name, err)
+			continue
+		}
+		switch {
+		case f.Type == _outType:
+			continue
+		case f.PkgPath == "" && f.Anonymous && !IsOut(f.Type):
+			l.report(t, f.Name, errEmbeddedField(f, "dig.Out"))
+			continue
+		case f.PkgPath == "" && IsOut(f.Type) && f.Tag.Get(_nameTag) == "" && f.Tag.Get(_groupTag) == "":
+			l.lintOut(f.Type)
+			continue
+		}
+		if _, err := newResultObjectField(i, f, resultOptions{}); err != nil {
+			l.report(t, f.Name, err)
+		}
+	}
+}
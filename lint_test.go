@@ -0,0 +1,185 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lintConfig struct{ Addr string }
+
+type lintInnerParams struct {
+	dig.In
+
+	Handlers []string `grop:"handlers"`
+}
+
+type lintParams struct {
+	dig.In
+
+	Config  *lintConfig `name:"main" optional:"true" json:"config"`
+	Servers string      `group:"servers"`
+	Clients []string    `name:"http" group:"clients"`
+	Inner   lintInnerParams
+	secret  string
+}
+
+type lintResult struct {
+	dig.Out
+
+	Config  *lintConfig `name:"main"`
+	Handler string      `group:"handlers" weight:"high"`
+	Server  string      `weigth:"1"`
+}
+
+func TestLintTypes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		type Params struct {
+			dig.In `ignore-unexported:"true"`
+
+			Config   *lintConfig           `name:"main" optional:"true" yaml:"config"`
+			Handlers []string              `group:"handlers,soft"`
+			Lazy     dig.Lazy[*lintConfig] `optional:"true"`
+			secret   string
+		}
+		type Result struct {
+			dig.Out
+
+			Config  *lintConfig `name:"main"`
+			Handler string      `group:"handlers" weight:"2"`
+		}
+
+		assert.Empty(t, dig.LintTypes(Params{}, &Result{}, reflect.TypeOf(Params{}), func(Params) Result { return Result{} }))
+	})
+
+	t.Run("dig.In", func(t *testing.T) {
+		t.Parallel()
+
+		issues := dig.LintTypes(lintParams{})
+		require.Len(t, issues, 4)
+
+		params := reflect.TypeOf(lintParams{})
+		assert.Equal(t, params, issues[0].Type)
+		assert.Equal(t, "Servers", issues[0].Field)
+		assert.Contains(t, issues[0].Message, "value groups may be consumed as slices only")
+		assert.Equal(t, "Clients", issues[1].Field)
+		assert.Contains(t, issues[1].Message, "cannot use named values with value groups")
+
+		assert.Equal(t, reflect.TypeOf(lintInnerParams{}), issues[2].Type)
+		assert.Equal(t, "Handlers", issues[2].Field)
+		assert.Equal(t, `unknown tag "grop" on field Handlers, did you mean "group"?`, issues[2].Message)
+
+		assert.Equal(t, params, issues[3].Type)
+		assert.Equal(t, "secret", issues[3].Field)
+		assert.Contains(t, issues[3].Message, `unexported fields not allowed in dig.In, did you mean to export "secret" (string)?`)
+	})
+
+	t.Run("dig.Out", func(t *testing.T) {
+		t.Parallel()
+
+		issues := dig.LintTypes(lintResult{})
+		require.Len(t, issues, 2)
+
+		assert.Equal(t, "Handler", issues[0].Field)
+		assert.Contains(t, issues[0].Message, `invalid weight "high" for field "Handler" (string)`)
+		assert.Equal(t, "Server", issues[1].Field)
+		assert.Equal(t, `unknown tag "weigth" on field Server, did you mean "weight"?`, issues[1].Message)
+		assert.Equal(t,
+			`dig_test.lintResult.Server: unknown tag "weigth" on field Server, did you mean "weight"?`,
+			issues[1].String())
+	})
+
+	t.Run("embedded structs", func(t *testing.T) {
+		t.Parallel()
+
+		type Config struct{ Addr string }
+		type Params struct {
+			dig.In
+
+			Config
+		}
+		type Result struct {
+			dig.Out
+
+			Config
+		}
+
+		issues := dig.LintTypes(Params{}, Result{})
+		require.Len(t, issues, 2)
+		assert.Equal(t, "embedded field dig_test.Config is not a dig.In struct, did you mean to name the field?", issues[0].Message)
+		assert.Equal(t, "embedded field dig_test.Config is not a dig.Out struct, did you mean to name the field?", issues[1].Message)
+	})
+
+	t.Run("functions", func(t *testing.T) {
+		t.Parallel()
+
+		issues := dig.LintTypes(func(string, lintInnerParams) (lintResult, error) {
+			return lintResult{}, nil
+		})
+		require.Len(t, issues, 3)
+		assert.Equal(t, "Handlers", issues[0].Field)
+		assert.Equal(t, "Handler", issues[1].Field)
+		assert.Equal(t, "Server", issues[2].Field)
+	})
+
+	t.Run("types are checked once", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Len(t, dig.LintTypes(lintInnerParams{}, &lintInnerParams{}, lintParams{}), 4)
+	})
+
+	t.Run("decorated tag", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Config   *lintConfig `decorated:"false"`
+			Misspelt *lintConfig `decorate:"false"`
+			Invalid  *lintConfig `decorated:"no"`
+		}
+
+		issues := dig.LintTypes(params{})
+		require.Len(t, issues, 2)
+		assert.Equal(t, "Misspelt", issues[0].Field)
+		assert.Equal(t, `unknown tag "decorate" on field Misspelt, did you mean "decorated"?`, issues[0].Message)
+		assert.Equal(t, "Invalid", issues[1].Field)
+		assert.Contains(t, issues[1].Message, `invalid value "no" for "decorated" tag on field Invalid`)
+	})
+
+	t.Run("invalid types", func(t *testing.T) {
+		t.Parallel()
+
+		issues := dig.LintTypes(nil, lintConfig{})
+		require.Len(t, issues, 2)
+		assert.Equal(t, "cannot lint an untyped nil", issues[0].String())
+		assert.Equal(t, "dig_test.lintConfig: dig_test.lintConfig is not a dig.In or dig.Out struct", issues[1].String())
+	})
+}
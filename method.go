@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// ProvideMethod provides the results of a method of a type provided to the
+// container, given as a method expression. The receiver of the method is
+// resolved from the container like the other parameters of a constructor,
+// which saves wrapping the method in a closure.
+//
+//	// Equivalent to
+//	//   c.Provide(func(r *Registry, cfg ClientConfig) *Client {
+//	//     return r.NewClient(cfg)
+//	//   })
+//	err := c.ProvideMethod((*Registry).NewClient)
+//
+// The method otherwise follows the rules of constructors given to Provide,
+// and supports the same options. Method values bound to a receiver, such as
+// registry.NewClient, are rejected: use Provide to provide them as-is.
+func (c *Container) ProvideMethod(method interface{}, opts ...ProvideOption) error {
+	return c.scope.ProvideMethod(method, opts...)
+}
+
+// ProvideMethod provides the results of a method of a type provided to the
+// Scope, given as a method expression. See Container.ProvideMethod.
+func (s *Scope) ProvideMethod(method interface{}, opts ...ProvideOption) error {
+	if err := validateMethodExpression(method); err != nil {
+		return err
+	}
+	return s.Provide(method, opts...)
+}
+
+// validateMethodExpression checks that method is a method expression, such
+// as (*T).M or I.M.
+func validateMethodExpression(method interface{}) error {
+	mtype := reflect.TypeOf(method)
+	if mtype == nil || mtype.Kind() != reflect.Func {
+		return newErrInvalidInput(
+			fmt.Sprintf("must provide a method expression, got %v (type %v)", method, mtype), nil)
+	}
+
+	var name string
+	if f := runtime.FuncForPC(reflect.ValueOf(method).Pointer()); f != nil {
+		name = f.Name()
+	}
+	if strings.HasSuffix(name, "-fm") {
+		return newErrInvalidInput(fmt.Sprintf(
+			"must provide a method expression, got method value %v bound to a receiver: use Provide instead",
+			strings.TrimSuffix(name, "-fm")), nil)
+	}
+	if mtype.NumIn() == 0 {
+		return newErrInvalidInput(fmt.Sprintf("must provide a method expression, got function %v", name), nil)
+	}
+
+	recv := mtype.In(0)
+	m, ok := recv.MethodByName(name[strings.LastIndexByte(name, '.')+1:])
+	if !ok || !isMethodType(recv, m.Type, mtype) {
+		return newErrInvalidInput(fmt.Sprintf(
+			"must provide a method expression, got function %v: it is not a method of %v", name, recv), nil)
+	}
+	return nil
+}
+
+// isMethodType reports whether mtype is the type of the method expression
+// of a method of recv of type t. The types of the methods of interfaces do
+// not include their receiver.
+func isMethodType(recv, t, mtype reflect.Type) bool {
+	if recv.Kind() != reflect.Interface {
+		return t == mtype
+	}
+	if t.NumIn()+1 != mtype.NumIn() || t.NumOut() != mtype.NumOut() || t.IsVariadic() != mtype.IsVariadic() {
+		return false
+	}
+	for i := 0; i < t.NumIn(); i++ {
+		if t.In(i) != mtype.In(i+1) {
+			return false
+		}
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		if t.Out(i) != mtype.Out(i) {
+			return false
+		}
+	}
+	return true
+}
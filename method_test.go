@@ -0,0 +1,172 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type methodConfig struct{ Name string }
+
+type methodClient struct{ Name string }
+
+type methodRegistry struct{ prefix string }
+
+func (r *methodRegistry) NewClient(cfg methodConfig) *methodClient {
+	return &methodClient{Name: r.prefix + cfg.Name}
+}
+
+func (r methodRegistry) Prefix() (string, error) {
+	if r.prefix == "" {
+		return "", errors.New("no prefix")
+	}
+	return r.prefix, nil
+}
+
+type methodNamer interface {
+	Name() string
+}
+
+type methodNamerImpl struct{}
+
+func (methodNamerImpl) Name() string { return "namer" }
+
+func newMethodClient() *methodClient { return nil }
+
+func TestProvideMethod(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pointer receiver", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *methodRegistry { return &methodRegistry{prefix: "p-"} })
+		c.RequireProvide(func() methodConfig { return methodConfig{Name: "client"} })
+		require.NoError(t, c.ProvideMethod((*methodRegistry).NewClient))
+
+		c.RequireInvoke(func(cl *methodClient) {
+			assert.Equal(t, "p-client", cl.Name)
+		})
+	})
+
+	t.Run("value receiver with options", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() methodRegistry { return methodRegistry{prefix: "p-"} })
+		require.NoError(t, c.ProvideMethod(methodRegistry.Prefix, dig.Name("prefix")))
+
+		type in struct {
+			dig.In
+
+			Prefix string `name:"prefix"`
+		}
+		c.RequireInvoke(func(p in) {
+			assert.Equal(t, "p-", p.Prefix)
+		})
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() methodNamer { return methodNamerImpl{} })
+		require.NoError(t, c.ProvideMethod(methodNamer.Name))
+
+		c.RequireInvoke(func(name string) {
+			assert.Equal(t, "namer", name)
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *methodRegistry { return &methodRegistry{} })
+		c.RequireProvide(func() methodConfig { return methodConfig{Name: "child"} })
+		s := c.Scope("child")
+		require.NoError(t, s.ProvideMethod((*methodRegistry).NewClient))
+
+		require.NoError(t, s.Invoke(func(cl *methodClient) {
+			assert.Equal(t, "child", cl.Name)
+		}))
+	})
+
+	t.Run("missing receiver", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() methodConfig { return methodConfig{} })
+		require.NoError(t, c.ProvideMethod((*methodRegistry).NewClient))
+
+		err := c.Invoke(func(*methodClient) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.methodRegistry")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		r := &methodRegistry{}
+		tests := []struct {
+			desc   string
+			method interface{}
+			msg    string
+		}{
+			{
+				desc:   "not a function",
+				method: r,
+				msg:    "must provide a method expression, got &{} (type *dig_test.methodRegistry)",
+			},
+			{
+				desc:   "method value",
+				method: r.NewClient,
+				msg:    "got method value github.com/alexisvisco/dig_test.(*methodRegistry).NewClient bound to a receiver: use Provide instead",
+			},
+			{
+				desc:   "function",
+				method: newMethodClient,
+				msg:    "must provide a method expression, got function github.com/alexisvisco/dig_test.newMethodClient",
+			},
+			{
+				desc:   "function with parameters",
+				method: func(*methodRegistry) *methodClient { return nil },
+				msg:    "it is not a method of *dig_test.methodRegistry",
+			},
+		}
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				err := digtest.New(t).ProvideMethod(tt.method)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.msg)
+			})
+		}
+	})
+}
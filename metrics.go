@@ -0,0 +1,87 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"time"
+)
+
+// Collector receives events about the behavior of a container for the
+// purpose of recording metrics, e.g. with Prometheus or OpenTelemetry.
+//
+// Collectors are called synchronously, and should return quickly. When the
+// container is built in parallel, they may be called concurrently.
+type Collector interface {
+	// ProviderRegistered is called after a constructor was successfully
+	// provided to the container or one of its Scopes.
+	ProviderRegistered(ProvideEvent)
+
+	// ConstructorExecuted is called after a constructor ran, whether it
+	// succeeded or not.
+	ConstructorExecuted(ConstructorEvent)
+}
+
+// ProvideEvent describes a constructor provided to a container.
+type ProvideEvent struct {
+	// Provider describes the constructor.
+	Provider ProviderInfo
+
+	// ScopePath contains the names of the scopes, starting below the
+	// container, leading to the Scope the constructor was provided to.
+	ScopePath []string
+}
+
+// ConstructorEvent describes a single run of a constructor.
+type ConstructorEvent struct {
+	// Provider describes the constructor.
+	Provider ProviderInfo
+
+	// ScopePath contains the names of the scopes, starting below the
+	// container, leading to the Scope the constructor was provided to.
+	ScopePath []string
+
+	// Duration is the time the constructor took to run.
+	Duration time.Duration
+
+	// Error is the error the constructor failed with, if any. When the
+	// constructor panics, this is a [PanicError] matched by
+	// [ErrConstructorPanicked].
+	Error error
+}
+
+// WithMetrics is an Option that reports the events of the container, and
+// of all its Scopes, to the given Collector.
+//
+//	c := dig.New(dig.WithMetrics(collector))
+func WithMetrics(collector Collector) Option {
+	return metricsOption{collector}
+}
+
+type metricsOption struct{ collector Collector }
+
+func (o metricsOption) String() string {
+	return fmt.Sprintf("WithMetrics(%v)", o.collector)
+}
+
+func (o metricsOption) applyOption(c *Container) {
+	c.scope.state.metrics = o.collector
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingCollector struct {
+	provided []dig.ProvideEvent
+	executed []dig.ConstructorEvent
+}
+
+func (c *recordingCollector) ProviderRegistered(e dig.ProvideEvent) {
+	c.provided = append(c.provided, e)
+}
+
+func (c *recordingCollector) ConstructorExecuted(e dig.ConstructorEvent) {
+	c.executed = append(c.executed, e)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("records provides and constructor runs", func(t *testing.T) {
+		t.Parallel()
+
+		var col recordingCollector
+		c := digtest.New(t, dig.WithMetrics(&col))
+		c.RequireProvide(func() *A { return &A{} })
+		child := c.Scope("child")
+		child.RequireProvide(func(*A) *B { return &B{} })
+
+		require.Len(t, col.provided, 2)
+		assert.Equal(t, "*dig_test.A", col.provided[0].Provider.Outputs[0].String())
+		assert.Empty(t, col.provided[0].ScopePath)
+		assert.Equal(t, "*dig_test.B", col.provided[1].Provider.Outputs[0].String())
+		assert.Equal(t, []string{"child"}, col.provided[1].ScopePath)
+
+		child.RequireInvoke(func(*B) {})
+		child.RequireInvoke(func(*B) {})
+
+		require.Len(t, col.executed, 2, "constructors must be reported once")
+		assert.Equal(t, col.provided[0].Provider.ID, col.executed[0].Provider.ID)
+		assert.Equal(t, col.provided[1].Provider.ID, col.executed[1].Provider.ID)
+		assert.Equal(t, []string{"child"}, col.executed[1].ScopePath)
+		for _, e := range col.executed {
+			assert.NoError(t, e.Error)
+		}
+	})
+
+	t.Run("records failures", func(t *testing.T) {
+		t.Parallel()
+
+		var col recordingCollector
+		c := digtest.New(t, dig.WithMetrics(&col))
+		c.RequireProvide(func() (*A, error) { return nil, errors.New("great sadness") })
+
+		require.Error(t, c.Invoke(func(*A) {}))
+		require.Len(t, col.executed, 1)
+		assert.ErrorContains(t, col.executed[0].Error, "great sadness")
+	})
+
+	t.Run("records panics", func(t *testing.T) {
+		t.Parallel()
+
+		var col recordingCollector
+		c := digtest.New(t, dig.WithMetrics(&col), dig.RecoverFromPanics())
+		c.RequireProvide(func() *A { panic("great sadness") })
+
+		require.Error(t, c.Invoke(func(*A) {}))
+		require.Len(t, col.executed, 1)
+		var pe dig.PanicError
+		assert.True(t, errors.As(col.executed[0].Error, &pe))
+	})
+
+	t.Run("does not record constructors that did not run", func(t *testing.T) {
+		t.Parallel()
+
+		var col recordingCollector
+		c := digtest.New(t, dig.WithMetrics(&col))
+		c.RequireProvide(func(*B) *A { return &A{} })
+
+		require.Error(t, c.Invoke(func(*A) {}))
+		assert.Empty(t, col.executed)
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// MissingHandler supplies the value of a Key that no constructor provides.
+// It returns false if it cannot supply the value either.
+type MissingHandler func(key Key) (value interface{}, ok bool)
+
+// WithMissingHandler is an Option that calls the given handler when a
+// function depends on a value that is provided neither to the container
+// nor to any of its Scopes, before failing because of the missing
+// dependency. This lets values come from outside the container, such as a
+// service locator, a plugin registry, or a factory of mocks in tests.
+//
+//	c := dig.New(dig.WithMissingHandler(func(k dig.Key) (interface{}, bool) {
+//	  return registry.Lookup(k.Type, k.Name)
+//	}))
+//
+// The value returned by the handler must be assignable to the type of the
+// key. It is supplied to the container, as with Supply, so the handler is
+// not called again for that key and the value appears in the graph
+// rendered by Visualize, drawn with dashed lines. Declined keys are not
+// remembered: the handler is asked again each time a function depends on
+// them, so it may supply values that become available later. The handler
+// is not called for
+// optional dependencies, value groups or values selected by their labels,
+// nor by Validate and the other functions that check the graph without
+// building values.
+func WithMissingHandler(h MissingHandler) Option {
+	return missingHandlerOption{h}
+}
+
+type missingHandlerOption struct{ h MissingHandler }
+
+func (o missingHandlerOption) String() string {
+	return fmt.Sprintf("WithMissingHandler(%p)", o.h)
+}
+
+func (o missingHandlerOption) applyOption(c *Container) {
+	c.scope.state.missingHandler = o.h
+	c.scope.state.missingHandlerLocation = nil
+	if o.h != nil {
+		c.scope.state.missingHandlerLocation = digreflect.InspectFunc(o.h)
+	}
+}
+
+// handleMissingDependencies asks the missing handler of the container for
+// the values of the given missing dependencies, and returns the ones it did
+// not supply.
+func handleMissingDependencies(c containerStore, deps []paramSingle) ([]paramSingle, error) {
+	s, ok := c.(*Scope)
+	if !ok {
+		return deps, nil
+	}
+	root := s.rootScope()
+	if root.state.missingHandler == nil {
+		return deps, nil
+	}
+
+	var missing []paramSingle
+	for _, dep := range deps {
+		if len(c.getAllValueProviders(dep.Name, dep.Type)) > 0 {
+			// Supplied for another parameter of the same function.
+			continue
+		}
+		k := Key{Type: dep.Type, Name: dep.Name}
+		v, ok := root.state.missingHandler(k)
+		if !ok {
+			missing = append(missing, dep)
+			continue
+		}
+		if err := root.supplyMissing(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+// supplyMissing supplies the value returned by the missing handler for the
+// given key to the Scope.
+func (s *Scope) supplyMissing(k Key, v interface{}) error {
+	location := s.rootScope().state.missingHandlerLocation
+	value := reflect.New(k.Type).Elem()
+	switch rv := reflect.ValueOf(v); {
+	case rv.IsValid() && rv.Type().AssignableTo(k.Type):
+		value.Set(rv)
+	case !rv.IsValid() && isNillable(k.Type):
+		// Leave the nil value of the type.
+	default:
+		return newErrInvalidInput(fmt.Sprintf(
+			"missing handler %v returned %v (type %T) for %v, which is not assignable to %v",
+			location, v, v, k, k.Type), nil)
+	}
+
+	sv := &suppliedValues{values: []reflect.Value{value}, missing: true}
+	return s.supplyValuesAs(sv, provideOptions{Name: k.Name, Location: location})
+}
+
+// isNillable reports whether nil is a value of type t.
+func isNillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return true
+	default:
+		return false
+	}
+}
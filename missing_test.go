@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMissingHandler(t *testing.T) {
+	type A struct{ Name string }
+	type B struct{ A *A }
+
+	t.Parallel()
+
+	t.Run("supplies missing values", func(t *testing.T) {
+		t.Parallel()
+
+		var keys []dig.Key
+		c := digtest.New(t, dig.WithMissingHandler(func(k dig.Key) (interface{}, bool) {
+			keys = append(keys, k)
+			switch {
+			case k.Type == reflect.TypeOf(&A{}):
+				return &A{Name: k.Name}, true
+			case k.Type == reflect.TypeOf((*io.Reader)(nil)).Elem():
+				return strings.NewReader("hello"), true
+			}
+			return nil, false
+		}))
+		c.RequireProvide(func(a *A) *B { return &B{A: a} })
+
+		type in struct {
+			dig.In
+
+			B      *B
+			Named  *A `name:"named"`
+			Reader io.Reader
+		}
+		c.RequireInvoke(func(p in) {
+			assert.Equal(t, "", p.B.A.Name)
+			assert.Equal(t, "named", p.Named.Name)
+			assert.NotNil(t, p.Reader)
+		})
+		c.RequireInvoke(func(*A, *B) {})
+
+		assert.ElementsMatch(t, []dig.Key{
+			{Type: reflect.TypeOf(&A{})},
+			{Type: reflect.TypeOf(&A{}), Name: "named"},
+			{Type: reflect.TypeOf((*io.Reader)(nil)).Elem()},
+		}, keys, "the handler must be called once per key")
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b))
+		assert.Contains(t, b.String(), `"*dig_test.A" [label=<*dig_test.A> shape=parallelogram style=dashed];`)
+	})
+
+	t.Run("unsupplied values are missing", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithMissingHandler(func(dig.Key) (interface{}, bool) {
+			return nil, false
+		}))
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("nil values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithMissingHandler(func(dig.Key) (interface{}, bool) {
+			return nil, true
+		}))
+		c.RequireInvoke(func(a *A) {
+			assert.Nil(t, a)
+		})
+
+		err := c.Invoke(func(A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "returned <nil> (type <nil>) for dig_test.A, which is not assignable to dig_test.A")
+	})
+
+	t.Run("values of the wrong type", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithMissingHandler(func(dig.Key) (interface{}, bool) {
+			return "not an A", true
+		}))
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing handler "github.com/alexisvisco/dig_test".TestWithMissingHandler.func`)
+		assert.Contains(t, err.Error(), "returned not an A (type string) for *dig_test.A, which is not assignable to *dig_test.A")
+	})
+
+	t.Run("optional dependencies and validation", func(t *testing.T) {
+		t.Parallel()
+
+		called := false
+		c := digtest.New(t, dig.WithMissingHandler(func(dig.Key) (interface{}, bool) {
+			called = true
+			return &A{}, true
+		}))
+
+		type in struct {
+			dig.In
+
+			A *A `optional:"true"`
+		}
+		c.RequireInvoke(func(p in) {
+			assert.Nil(t, p.A)
+		})
+		assert.False(t, called)
+
+		c.RequireProvide(func(*A) *B { return &B{} })
+		require.Error(t, c.Validate())
+		assert.False(t, called)
+	})
+
+	t.Run("scopes and constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithMissingHandler(func(dig.Key) (interface{}, bool) {
+			return &A{Name: "handled"}, true
+		}))
+		s := c.Scope("child")
+		require.NoError(t, s.Provide(func(a *A) *B { return &B{A: a} }))
+		require.NoError(t, s.Invoke(func(b *B) {
+			assert.Equal(t, "handled", b.A.Name)
+		}))
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "handled", a.Name)
+		})
+	})
+
+	t.Run("errors of constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.WithMissingHandler(func(dig.Key) (interface{}, bool) {
+			return 42, true
+		}))
+		c.RequireProvide(func(*A) *B { return &B{} })
+		err := c.Invoke(func(*B) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing dependencies for function")
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		opt := dig.WithMissingHandler(func(dig.Key) (interface{}, bool) { return nil, false })
+		assert.Contains(t, opt.(interface{ String() string }).String(), "WithMissingHandler(0x")
+	})
+}
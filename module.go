@@ -0,0 +1,141 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+)
+
+// Module bundles a set of related constructors, decorators and invocations
+// under a name so that they can be applied to a Container together.
+//
+//	var DBModule = dig.NewModule("db")
+//
+//	func init() {
+//	  DBModule.Provide(newConnectionPool)
+//	  DBModule.Provide(newUserRepository, dig.Export(true))
+//	  DBModule.Invoke(runMigrations)
+//	}
+//
+//	err := c.Apply(DBModule)
+//
+// Applying a Module creates a child Scope named after it. Values provided by
+// the Module are private to it unless they are provided with Export(true),
+// in which case they are visible to the whole Container. Decorations made
+// by the Module only affect the Module.
+//
+// Calls recorded on the Module are replayed in order each time it is
+// applied.
+type Module struct {
+	name string
+	ops  []moduleOp
+}
+
+// moduleOp is a call recorded on a Module, replayed against the Scope of
+// the Module when it is applied.
+type moduleOp func(s *Scope) error
+
+// NewModule builds a new, empty Module with the given name.
+func NewModule(name string) *Module {
+	return &Module{name: name}
+}
+
+// Name returns the name of the Module.
+func (m *Module) Name() string { return m.name }
+
+// String returns a readable representation of the Module.
+func (m *Module) String() string {
+	return fmt.Sprintf("Module(%q)", m.name)
+}
+
+// Provide records a constructor to provide to the Module.
+// See Container.Provide for details.
+func (m *Module) Provide(constructor interface{}, opts ...ProvideOption) {
+	m.ops = append(m.ops, func(s *Scope) error {
+		return s.Provide(constructor, opts...)
+	})
+}
+
+// Decorate records a decorator to register with the Module.
+// See Scope.Decorate for details.
+func (m *Module) Decorate(decorator interface{}, opts ...DecorateOption) {
+	m.ops = append(m.ops, func(s *Scope) error {
+		return s.Decorate(decorator, opts...)
+	})
+}
+
+// Invoke records a function to invoke with the dependencies visible from the
+// Module. See Container.Invoke for details.
+func (m *Module) Invoke(function interface{}, opts ...InvokeOption) {
+	m.ops = append(m.ops, func(s *Scope) error {
+		return s.Invoke(function, opts...)
+	})
+}
+
+// RegisterInvoke records a function to register with RegisterInvoke on the
+// Scope of the Module, to be invoked when Run is called on the Container.
+func (m *Module) RegisterInvoke(function interface{}, opts ...InvokeOption) {
+	m.ops = append(m.ops, func(s *Scope) error {
+		return s.RegisterInvoke(function, opts...)
+	})
+}
+
+// Apply applies the Module to the Container: the calls recorded on the
+// Module are made, in order, on a new child Scope named after the Module.
+//
+// Apply stops at the first call that fails and returns its error, which
+// names the Module.
+func (c *Container) Apply(m *Module) error {
+	return c.scope.Apply(m)
+}
+
+// Apply applies the Module to the Scope. See Container.Apply for details.
+func (s *Scope) Apply(m *Module) error {
+	ms := s.Scope(m.name)
+	ms.module = m.name
+	for _, op := range m.ops {
+		if err := op(ms); err != nil {
+			return errModule{Module: m.name, Reason: err}
+		}
+	}
+	return nil
+}
+
+// errModule is returned when a call recorded on a Module failed.
+type errModule struct {
+	Module string
+	Reason error
+}
+
+var _ digError = errModule{}
+
+func (e errModule) Error() string { return fmt.Sprint(e) }
+
+func (e errModule) Unwrap() error { return e.Reason }
+
+func (e errModule) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "module %q", e.Module)
+}
+
+func (e errModule) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
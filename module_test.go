@@ -0,0 +1,124 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModule(t *testing.T) {
+	t.Parallel()
+
+	type pool struct{ name string }
+	type repository struct{ pool *pool }
+
+	newDBModule := func() *dig.Module {
+		m := dig.NewModule("db")
+		m.Provide(func() *pool { return &pool{name: "pool"} })
+		m.Provide(func(p *pool) *repository { return &repository{pool: p} }, dig.Export(true))
+		return m
+	}
+
+	t.Run("exported values are visible", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Apply(newDBModule()))
+		c.RequireInvoke(func(r *repository) {
+			assert.Equal(t, "pool", r.pool.name)
+		})
+	})
+
+	t.Run("private values are not visible", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Apply(newDBModule()))
+		err := c.Invoke(func(*pool) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.pool")
+	})
+
+	t.Run("calls are replayed in order", func(t *testing.T) {
+		t.Parallel()
+
+		var got []string
+		m := newDBModule()
+		m.Decorate(func(p *pool) *pool { return &pool{name: p.name + "'"} })
+		m.Invoke(func(p *pool) { got = append(got, p.name) })
+
+		c := digtest.New(t)
+		require.NoError(t, c.Apply(m))
+		assert.Equal(t, []string{"pool'"}, got)
+	})
+
+	t.Run("errors name the module", func(t *testing.T) {
+		t.Parallel()
+
+		m := dig.NewModule("db")
+		m.Invoke(func() error { return errors.New("great sadness") })
+		m.Provide(func() *pool {
+			t.Fatal("calls after a failure must not be made")
+			return nil
+		})
+
+		c := digtest.New(t)
+		err := c.Apply(m)
+		require.Error(t, err)
+		assert.Equal(t, `module "db": great sadness`, err.Error())
+		assert.Equal(t, "great sadness", dig.RootCause(err).Error())
+	})
+
+	t.Run("apply to a scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		require.NoError(t, child.Apply(newDBModule()))
+		child.RequireInvoke(func(*repository) {})
+	})
+
+	t.Run("visualization names the module", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Apply(newDBModule()))
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b))
+		assert.Contains(t, b.String(), `label = "github.com/alexisvisco/dig_test (module db)";`)
+	})
+
+	t.Run("name and string", func(t *testing.T) {
+		t.Parallel()
+
+		m := dig.NewModule("db")
+		assert.Equal(t, "db", m.Name())
+		assert.Equal(t, `Module("db")`, m.String())
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/dot"
+)
+
+// Optional is a dependency on a value of type T that may not be provided
+// to the container. Unlike fields tagged with `optional:"true"`, which
+// receive the zero value of their type when it is not provided, Optional
+// dependencies tell absent values apart from zero values.
+//
+//	c.Provide(func(timeout dig.Optional[time.Duration]) *Client {
+//	  if d, ok := timeout.Get(); ok {
+//	    return &Client{Timeout: d}
+//	  }
+//	  return &Client{Timeout: defaultTimeout}
+//	})
+//
+// Optional dependencies may be used as parameters of constructors,
+// decorators, and functions given to Invoke, as well as fields of dig.In
+// structs, where they support the name and select tags. A value is absent
+// if it is not provided, or if the dependencies of its constructor are
+// not; other errors building it are returned as usual.
+//
+// The zero Optional is absent.
+type Optional[T any] struct {
+	value   T
+	present bool
+}
+
+// Get returns the value of the dependency, and whether it was provided.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.present
+}
+
+// IsPresent reports whether the value of the dependency was provided.
+func (o Optional[T]) IsPresent() bool {
+	return o.present
+}
+
+func (Optional[T]) optionalElem() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (Optional[T]) withValue(v reflect.Value) reflect.Value {
+	return reflect.ValueOf(Optional[T]{value: v.Interface().(T), present: true})
+}
+
+// optionalDependency is implemented by all the instantiations of Optional.
+type optionalDependency interface {
+	optionalElem() reflect.Type
+	withValue(v reflect.Value) reflect.Value
+}
+
+var _optionalDependencyType = reflect.TypeOf((*optionalDependency)(nil)).Elem()
+
+func isOptional(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(_optionalDependencyType)
+}
+
+// paramOptional is an Optional dependency on the value requested by Elem.
+type paramOptional struct {
+	// Type of the Optional dependency.
+	Type reflect.Type
+
+	Elem paramSingle
+}
+
+var _ param = paramOptional{}
+
+func newParamOptional(t reflect.Type) (paramOptional, error) {
+	elem := reflect.Zero(t).Interface().(optionalDependency).optionalElem()
+	if isOptional(elem) || isLazy(elem) || isOriginal(elem) || IsIn(elem) || IsOut(elem) {
+		return paramOptional{}, newErrInvalidInput(fmt.Sprintf(
+			"cannot depend on %v: optional dependencies must be single values", t), nil)
+	}
+	return paramOptional{Type: t, Elem: paramSingle{Type: elem, Optional: true}}, nil
+}
+
+func (po paramOptional) String() string {
+	return fmt.Sprintf("Optional[%v]", po.Elem)
+}
+
+func (po paramOptional) DotParam() []*dot.Param {
+	return po.Elem.DotParam()
+}
+
+func (po paramOptional) Build(c containerStore) (reflect.Value, error) {
+	absent := reflect.Zero(po.Type)
+	if !po.Elem.provided(c) {
+		return absent, nil
+	}
+
+	elem := po.Elem
+	elem.Optional = false
+	v, err := elem.Build(c)
+	if err != nil {
+		k := key{t: elem.Type, name: elem.Name}
+		if pe, ok := err.(errParamSingleFailed); ok {
+			if _, ok := pe.Reason.(errMissingDependencies); ok {
+				skipDependency(c, k, err)
+				return absent, nil
+			}
+		}
+		if skipDependency(c, k, err) {
+			return absent, nil
+		}
+		return _noValue, err
+	}
+	return absent.Interface().(optionalDependency).withValue(v), nil
+}
+
+// provided reports whether the value requested by ps may be built from c,
+// as far as can be told without building it.
+func (ps paramSingle) provided(c containerStore) bool {
+	if len(ps.Selector) > 0 {
+		// Ambiguous selectors are reported when the value is built.
+		name, err := ps.selectName(c)
+		return err != nil || name != ""
+	}
+	if ps.isImplicit() {
+		return true
+	}
+	if f, _ := ps.factory(c); f != nil {
+		return true
+	}
+	_, decorated := c.getDecoratedValue(ps.Name, ps.Type)
+	return decorated || len(c.getAllValueProviders(ps.Name, ps.Type)) > 0
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptional(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Retries int }
+
+	t.Run("present zero value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 0 })
+		c.RequireInvoke(func(o dig.Optional[int]) {
+			v, ok := o.Get()
+			assert.True(t, ok)
+			assert.True(t, o.IsPresent())
+			assert.Equal(t, 0, v)
+		})
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireInvoke(func(o dig.Optional[Config]) {
+			v, ok := o.Get()
+			assert.False(t, ok)
+			assert.False(t, o.IsPresent())
+			assert.Zero(t, v)
+		})
+	})
+
+	t.Run("constructor dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		type Client struct{ Retries int }
+
+		c := digtest.New(t)
+		c.RequireProvide(func(cfg dig.Optional[Config]) *Client {
+			if cfg, ok := cfg.Get(); ok {
+				return &Client{Retries: cfg.Retries}
+			}
+			return &Client{Retries: 3}
+		})
+		c.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, 3, cl.Retries)
+		})
+	})
+
+	t.Run("dig.In fields", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Primary dig.Optional[*Config] `name:"primary"`
+			Replica dig.Optional[*Config] `name:"replica"`
+			EU      dig.Optional[*Config] `select:"region=eu"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Retries: 1} }, dig.Name("primary"))
+		c.RequireProvide(func() *Config { return &Config{Retries: 2} }, dig.Labels("region=eu"))
+		c.RequireInvoke(func(p params) {
+			primary, ok := p.Primary.Get()
+			require.True(t, ok)
+			assert.Equal(t, 1, primary.Retries)
+
+			assert.False(t, p.Replica.IsPresent())
+
+			eu, ok := p.EU.Get()
+			require.True(t, ok)
+			assert.Equal(t, 2, eu.Retries)
+		})
+	})
+
+	t.Run("missing dependencies of the constructor", func(t *testing.T) {
+		t.Parallel()
+
+		type Missing struct{}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(Missing) *Config { return &Config{} })
+		c.RequireInvoke(func(o dig.Optional[*Config]) {
+			assert.False(t, o.IsPresent())
+		})
+	})
+
+	t.Run("constructor errors are returned", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Config, error) { return nil, errors.New("great sadness") })
+		err := c.Invoke(func(dig.Optional[*Config]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("decorated values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 })
+		c.RequireDecorate(func(i int) int { return i + 1 })
+		c.RequireInvoke(func(o dig.Optional[int]) {
+			v, _ := o.Get()
+			assert.Equal(t, 2, v)
+		})
+	})
+
+	t.Run("invalid dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(dig.Optional[dig.Lazy[int]]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "optional dependencies must be single values")
+
+		err = c.Invoke(func(dig.Lazy[dig.Optional[int]]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lazy dependencies must be single values")
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/dot"
+)
+
+// Original is a dependency on a value of type T as it was provided, before
+// any decorator. It lets a decorator build on the value of the container
+// rather than on the one decorated by the Scopes in between, and lets any
+// function bypass the decorations of a value. It is the function parameter
+// counterpart of the `decorated:"false"` tag.
+//
+//	child.Decorate(func(log dig.Original[*zap.Logger], cfg *TenantConfig) *zap.Logger {
+//	  return log.Get().Named(cfg.Name)
+//	})
+//
+// Original dependencies may be used as parameters of constructors,
+// decorators, and functions given to Invoke, as well as fields of dig.In
+// structs, where they support the name tag.
+//
+// The zero Original holds the zero value of T: Original values are only
+// produced by dig.
+type Original[T any] struct {
+	value T
+}
+
+// Get returns the value of the dependency.
+func (o Original[T]) Get() T {
+	return o.value
+}
+
+func (Original[T]) originalElem() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (Original[T]) withValue(v reflect.Value) reflect.Value {
+	return reflect.ValueOf(Original[T]{value: v.Interface().(T)})
+}
+
+// originalDependency is implemented by all the instantiations of Original.
+type originalDependency interface {
+	originalElem() reflect.Type
+	withValue(v reflect.Value) reflect.Value
+}
+
+var _originalDependencyType = reflect.TypeOf((*originalDependency)(nil)).Elem()
+
+func isOriginal(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(_originalDependencyType)
+}
+
+// paramOriginal is an Original dependency on the value requested by Elem.
+type paramOriginal struct {
+	// Type of the Original dependency.
+	Type reflect.Type
+
+	Elem paramSingle
+}
+
+var _ param = paramOriginal{}
+
+func newParamOriginal(t reflect.Type) (paramOriginal, error) {
+	elem := reflect.Zero(t).Interface().(originalDependency).originalElem()
+	if isOriginal(elem) || isOptional(elem) || isLazy(elem) || IsIn(elem) || IsOut(elem) {
+		return paramOriginal{}, newErrInvalidInput(fmt.Sprintf(
+			"cannot depend on %v: original dependencies must be single values", t), nil)
+	}
+	return paramOriginal{Type: t, Elem: paramSingle{Type: elem, Undecorated: true}}, nil
+}
+
+func (po paramOriginal) String() string {
+	elem := po.Elem
+	elem.Undecorated = false
+	return fmt.Sprintf("Original[%v]", elem)
+}
+
+func (po paramOriginal) DotParam() []*dot.Param {
+	return po.Elem.DotParam()
+}
+
+func (po paramOriginal) Build(c containerStore) (reflect.Value, error) {
+	v, err := po.Elem.Build(c)
+	if err != nil {
+		return _noValue, err
+	}
+	return reflect.Zero(po.Type).Interface().(originalDependency).withValue(v), nil
+}
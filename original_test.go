@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOriginal(t *testing.T) {
+	t.Parallel()
+
+	type Logger struct{ Name string }
+	type TenantConfig struct{ Name string }
+
+	t.Run("scope decorator", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{Name: "app"} })
+		c.RequireDecorate(func(l *Logger) *Logger { return &Logger{Name: l.Name + ".root"} })
+
+		s := c.Scope("tenant")
+		s.RequireProvide(func() *TenantConfig { return &TenantConfig{Name: "acme"} })
+		s.RequireDecorate(func(l dig.Original[*Logger], cfg *TenantConfig) *Logger {
+			return &Logger{Name: l.Get().Name + "." + cfg.Name}
+		})
+
+		s.RequireInvoke(func(l *Logger) {
+			assert.Equal(t, "app.acme", l.Name)
+		})
+		c.RequireInvoke(func(l *Logger) {
+			assert.Equal(t, "app.root", l.Name)
+		})
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 })
+		c.RequireDecorate(func(i int) int { return i + 1 })
+		c.RequireInvoke(func(o dig.Original[int], i int) {
+			assert.Equal(t, 1, o.Get())
+			assert.Equal(t, 2, i)
+		})
+	})
+
+	t.Run("dig.In fields", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Primary dig.Original[*Logger] `name:"primary"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{Name: "primary"} }, dig.Name("primary"))
+		c.RequireDecorate(func(l *Logger) *Logger { return &Logger{Name: "decorated"} }, dig.DecorateNamed("primary"))
+		c.RequireInvoke(func(p params) {
+			assert.Equal(t, "primary", p.Primary.Get().Name)
+		})
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(dig.Original[*Logger]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Logger")
+	})
+
+	t.Run("invalid dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(dig.Original[dig.Lazy[int]]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "original dependencies must be single values")
+
+		err = c.Invoke(func(dig.Optional[dig.Original[int]]) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "optional dependencies must be single values")
+	})
+}
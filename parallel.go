@@ -0,0 +1,323 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// MaxConcurrency is an Option that allows the Container to call up to n
+// constructors at the same time.
+//
+// When a function needs several dependencies, or a dig.In struct has several
+// fields, the dependencies are built on separate goroutines so that
+// constructors with independent dependency subtrees run concurrently. A
+// constructor needed by several of these subtrees is still called only once.
+//
+//	c := dig.New(dig.MaxConcurrency(4))
+//
+// Constructors, decorators and invoked functions must be safe to call from
+// any goroutine. Decorators, and the dependencies they need, are always
+// resolved sequentially. When a dependency fails to build, dependencies
+// that were resolved concurrently with it may still be built.
+//
+// With n less than or equal to 1, constructors are called one at a time.
+// This is the default.
+//
+// A Container built with MaxConcurrency is safe for concurrent use, as with
+// ConcurrencySafe.
+func MaxConcurrency(n int) Option {
+	return maxConcurrencyOption(n)
+}
+
+type maxConcurrencyOption int
+
+func (o maxConcurrencyOption) String() string {
+	return fmt.Sprintf("MaxConcurrency(%d)", int(o))
+}
+
+func (o maxConcurrencyOption) applyOption(c *Container) {
+	if o <= 1 {
+		if p := c.scope.parallel; p != nil && p.sem != nil {
+			c.scope.parallel = nil
+		}
+		return
+	}
+	c.scope.parallel = &parallel{sem: make(chan struct{}, int(o))}
+}
+
+// ConcurrencySafe is an Option that makes the Container, and its Scopes,
+// safe for concurrent use: Invoke, InvokeCtx, Populate, Build, Provide,
+// Decorate and Scope may be called from several goroutines at the same
+// time.
+//
+//	c := dig.New(dig.ConcurrencySafe())
+//
+// Constructors are still called at most once. If several goroutines need
+// a value at the same time, even from different Scopes, one of them calls
+// its constructor while the others wait for the result, or for the error.
+// Constructors needed by concurrent Invokes may run at the same time, and
+// must be safe to call from any goroutine.
+// Unlike with MaxConcurrency, the dependencies of a single function are
+// built one at a time.
+//
+// Without this option, or MaxConcurrency, a Container must not be used
+// concurrently. A constructor that takes a context.Context receives the
+// context of one of the Invokes in progress that needed its results.
+func ConcurrencySafe() Option {
+	return concurrencySafeOption{}
+}
+
+type concurrencySafeOption struct{}
+
+func (concurrencySafeOption) String() string {
+	return "ConcurrencySafe()"
+}
+
+func (concurrencySafeOption) applyOption(c *Container) {
+	if c.scope.parallel == nil {
+		c.scope.parallel = new(parallel)
+	}
+}
+
+// parallel holds the state that allows a Container to build independent
+// dependencies concurrently. A nil *parallel builds everything sequentially,
+// and a *parallel without sem only serializes concurrent uses of the
+// Container.
+//
+// Values are always built with mu held, so that the state of the Container
+// is never accessed concurrently. mu is released while constructors run, and
+// while waiting for dependencies built on other goroutines.
+type parallel struct {
+	mu sync.Mutex
+
+	// sem limits the number of constructors that run at the same time. If
+	// nil, the dependencies of a function are built sequentially, and there
+	// is no limit.
+	sem chan struct{}
+
+	// exclusive is non-zero while a decorator is being called. mu is held
+	// for as long as exclusive is non-zero.
+	exclusive int
+
+	// flights holds the calls in progress, by key. See once.
+	flights map[interface{}]*flight
+}
+
+// flight is a call in progress on one goroutine, whose error is shared with
+// the goroutines waiting for it.
+type flight struct {
+	done chan struct{}
+	err  error
+}
+
+// do calls fn with mu held.
+func (p *parallel) do(fn func() error) error {
+	if p == nil {
+		return fn()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return fn()
+}
+
+// once calls fn with mu held, unless another goroutine is already calling
+// a function for the same key. In that case, once releases mu until that
+// call has completed, and returns its error without calling fn.
+//
+// Constructors are shared by a Scope and its descendants, so using them as
+// keys makes Scopes that need the same constructor at the same time wait
+// for a single call, whose results are committed to the Scope the
+// constructor was provided to and seen by all of them.
+func (p *parallel) once(key interface{}, fn func() error) (err error) {
+	if p == nil {
+		return fn()
+	}
+
+	if f, ok := p.flights[key]; ok {
+		p.mu.Unlock()
+		defer p.mu.Lock()
+
+		<-f.done
+		return f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	if p.flights == nil {
+		p.flights = make(map[interface{}]*flight)
+	}
+	p.flights[key] = f
+
+	completed := false
+	defer func() {
+		if !completed {
+			// fn panicked and the panic is propagated on this goroutine.
+			f.err = errFlightPanicked{}
+		}
+		delete(p.flights, key)
+		close(f.done)
+	}()
+
+	f.err = fn()
+	completed = true
+	return f.err
+}
+
+// errFlightPanicked is returned to the goroutines that waited for a call
+// that panicked.
+type errFlightPanicked struct{}
+
+var _ digError = errFlightPanicked{}
+
+func (e errFlightPanicked) Error() string { return fmt.Sprint(e) }
+
+func (e errFlightPanicked) writeMessage(w io.Writer, _ string) {
+	io.WriteString(w, "panicked while being called by another goroutine")
+}
+
+func (e errFlightPanicked) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// call calls fn with invoke. mu is released for the duration of the call,
+// unless a decorator is being called.
+func (p *parallel) call(invoke invokerFn, fn reflect.Value, args []reflect.Value) []reflect.Value {
+	if p == nil || p.exclusive > 0 {
+		return invoke(fn, args)
+	}
+
+	p.mu.Unlock()
+	defer p.mu.Lock()
+
+	if p.sem != nil {
+		p.sem <- struct{}{}
+		defer func() { <-p.sem }()
+	}
+
+	return invoke(fn, args)
+}
+
+// sleep waits for d, or until ctx is done, in which case it returns the
+// error of ctx. mu is released while waiting, unless a decorator is being
+// called.
+func (p *parallel) sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil || d <= 0 {
+		return err
+	}
+
+	if p != nil && p.exclusive == 0 {
+		p.mu.Unlock()
+		defer p.mu.Lock()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// lockExclusive prevents mu from being released until the returned function
+// is called.
+func (p *parallel) lockExclusive() (unlock func()) {
+	if p == nil {
+		return func() {}
+	}
+
+	p.exclusive++
+	return func() { p.exclusive-- }
+}
+
+// buildParams builds the given parameters from the given store and returns
+// their values in order. Parameters are built on separate goroutines if
+// possible. If any of them fails, the error of the first one is returned.
+func buildParams(c containerStore, params []param) ([]reflect.Value, error) {
+	args, errs := buildEachParam(c, params, true /* failFast */)
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// buildEachParam builds the given parameters, and returns their values
+// along with the error each of them failed with, if any. Unless failFast
+// is true, parameters are still built after one of them failed.
+func buildEachParam(c containerStore, params []param, failFast bool) ([]reflect.Value, []error) {
+	args := make([]reflect.Value, len(params))
+	errs := make([]error, len(params))
+	p := c.parallelism()
+	if p == nil || p.sem == nil || p.exclusive > 0 || len(params) < 2 {
+		for i, param := range params {
+			args[i], errs[i] = param.Build(c)
+			if errs[i] != nil && failFast {
+				break
+			}
+		}
+		return args, errs
+	}
+
+	var (
+		wg     sync.WaitGroup
+		panics = make([]interface{}, len(params))
+	)
+	for i := range params {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			// Panics are recovered here and re-raised on the calling
+			// goroutine, as if the parameters were built sequentially.
+			defer func() {
+				if r := recover(); r != nil {
+					panics[i] = r
+				}
+			}()
+
+			args[i], errs[i] = params[i].Build(c)
+		}(i)
+	}
+
+	p.mu.Unlock()
+	wg.Wait()
+	p.mu.Lock()
+
+	for _, r := range panics {
+		if r != nil {
+			panic(r)
+		}
+	}
+	return args, errs
+}
@@ -0,0 +1,449 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type D struct{}
+
+	t.Run("independent constructors run concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(2))
+
+		// Both constructors wait for each other, which only succeeds if
+		// they run at the same time.
+		var wg sync.WaitGroup
+		wg.Add(2)
+		barrier := func() error {
+			wg.Done()
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("constructors did not run concurrently")
+			}
+		}
+
+		c.RequireProvide(func() (*A, error) { return &A{}, barrier() })
+		c.RequireProvide(func() (*B, error) { return &B{}, barrier() })
+
+		type in struct {
+			dig.In
+
+			A *A
+			B *B
+		}
+		c.RequireInvoke(func(in) {})
+	})
+
+	t.Run("shared dependency is called once", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(4))
+
+		var calls int32
+		c.RequireProvide(func() *C {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+			return &C{}
+		})
+		c.RequireProvide(func(*C) *A { return &A{} })
+		c.RequireProvide(func(*C) *B { return &B{} })
+		c.RequireProvide(func(*C) *D { return &D{} })
+
+		c.RequireInvoke(func(a *A, b *B, d *D, cc *C) {
+			assert.NotNil(t, a)
+			assert.NotNil(t, b)
+			assert.NotNil(t, d)
+			assert.NotNil(t, cc)
+		})
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("concurrency is limited", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(2))
+
+		var running, maxRunning int32
+		track := func() {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}
+
+		c.RequireProvide(func() *A { track(); return &A{} })
+		c.RequireProvide(func() *B { track(); return &B{} })
+		c.RequireProvide(func() *C { track(); return &C{} })
+		c.RequireProvide(func() *D { track(); return &D{} })
+
+		c.RequireInvoke(func(*A, *B, *C, *D) {})
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+	})
+
+	t.Run("error of the first failing parameter is returned", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(4))
+
+		c.RequireProvide(func() (*A, error) { return nil, errors.New("great sadness") })
+		c.RequireProvide(func() *B { return &B{} })
+		c.RequireProvide(func() (*C, error) { return nil, errors.New("other sadness") })
+
+		err := c.Invoke(func(*B, *A, *C) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.NotContains(t, err.Error(), "other sadness")
+	})
+
+	t.Run("decorators are applied", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(4))
+
+		c.RequireProvide(func() string { return "hello" })
+		c.RequireProvide(func(s string) *A { return &A{} })
+		c.RequireProvide(func(s string) []byte { return []byte(s) })
+		c.RequireDecorate(func(s string) string { return s + " world" })
+
+		c.RequireInvoke(func(_ *A, b []byte, s string) {
+			assert.Equal(t, "hello world", string(b))
+			assert.Equal(t, "hello world", s)
+		})
+	})
+
+	t.Run("panics are propagated", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(4), dig.RecoverFromPanics())
+
+		c.RequireProvide(func() *A { panic("great sadness") })
+		c.RequireProvide(func() *B { return &B{} })
+
+		err := c.Invoke(func(*A, *B) {})
+		var pe dig.PanicError
+		require.True(t, errors.As(err, &pe), "expected a PanicError, got %v", err)
+		assert.Equal(t, "great sadness", pe.Panic)
+	})
+
+	t.Run("panics without recovery are raised by Invoke", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(4))
+
+		// Panics in constructors are always recovered, but not those in
+		// decorators.
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireDecorate(func(*A) *A { panic("great sadness") })
+		c.RequireProvide(func() *B { return &B{} })
+
+		assert.PanicsWithValue(t, "great sadness", func() {
+			c.Invoke(func(*A, *B) {})
+		})
+
+		// The container is still usable afterwards.
+		c.RequireInvoke(func(*B) {})
+	})
+
+	t.Run("child scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(4))
+		child := c.Scope("child")
+
+		c.RequireProvide(func() *A { return &A{} })
+		child.RequireProvide(func(*A) *B { return &B{} })
+		child.RequireProvide(func(*A) *C { return &C{} })
+
+		child.RequireInvoke(func(*B, *C) {})
+	})
+}
+
+func TestConcurrencySafe(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{ A *A }
+
+	t.Run("constructors are called once", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		var calls int32
+		c.RequireProvide(func() *A {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+			return &A{}
+		})
+		c.RequireProvide(func(a *A) *B { return &B{A: a} })
+
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			seen = make(map[*A]struct{})
+		)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Invoke(func(b *B) {
+					mu.Lock()
+					seen[b.A] = struct{}{}
+					mu.Unlock()
+				}))
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Len(t, seen, 1)
+	})
+
+	t.Run("concurrent provides, scopes and invokes", func(t *testing.T) {
+		t.Parallel()
+
+		type Param struct {
+			dig.In
+
+			Values []int `group:"values"`
+		}
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			i := i
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Provide(func() int { return i }, dig.Group("values")))
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Scope(fmt.Sprint("scope", i)).Invoke(func(Param) {}))
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Invoke(func(Param) {}))
+			}()
+		}
+		wg.Wait()
+
+		require.Len(t, c.Scopes(), 10)
+	})
+
+	t.Run("named scopes created concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		var (
+			wg      sync.WaitGroup
+			created atomic.Int32
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				if _, err := c.NewScope("request"); err == nil {
+					created.Add(1)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				c.ScopeByName("request")
+				c.Scopes()
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Scope("other").Invoke(func() {}))
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), created.Load(), "scope names must be unique")
+		assert.Len(t, c.Scopes(), 11)
+	})
+
+	t.Run("scopes share constructor calls", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		var calls int32
+		started, release := make(chan struct{}), make(chan struct{})
+		c.RequireProvide(func() (*A, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return &A{}, nil
+		})
+
+		var (
+			wg      sync.WaitGroup
+			results [2]*A
+		)
+		for i, s := range []*digtest.Scope{c.Scope("a"), c.Scope("b")} {
+			i, s := i, s
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, s.Invoke(func(a *A) { results[i] = a }))
+			}()
+			if i == 0 {
+				<-started
+			}
+		}
+
+		// Leave time for the second scope to wait for the call in
+		// progress.
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.NotNil(t, results[0])
+		assert.Same(t, results[0], results[1])
+	})
+
+	t.Run("scopes share constructor errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		var calls int32
+		started, release := make(chan struct{}), make(chan struct{})
+		c.RequireProvide(func() (*A, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+				<-release
+			}
+			return nil, errors.New("great sadness")
+		})
+
+		var (
+			wg   sync.WaitGroup
+			errs [2]error
+		)
+		for i, s := range []*digtest.Scope{c.Scope("a"), c.Scope("b")} {
+			i, s := i, s
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = s.Invoke(func(*A) {})
+			}()
+			if i == 0 {
+				<-started
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		for _, err := range errs {
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "great sadness")
+		}
+
+		// Failed calls are not shared with later callers.
+		require.Error(t, c.Invoke(func(*A) {}))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("contexts of concurrent invokes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		startedA, releaseA := make(chan struct{}), make(chan struct{})
+		startedB, releaseB := make(chan struct{}), make(chan struct{})
+		c.RequireProvide(func() *A {
+			close(startedA)
+			<-releaseA
+			return &A{}
+		})
+		c.RequireProvide(func() *B {
+			close(startedB)
+			<-releaseB
+			return &B{}
+		})
+
+		// The first invoke ends before the second one, and its context
+		// must not outlive it.
+		ctx, cancel := context.WithCancel(context.Background())
+		first, second := make(chan error), make(chan error)
+		go func() { first <- c.InvokeCtx(ctx, func(*A) {}) }()
+		<-startedA
+		go func() { second <- c.InvokeCtx(context.Background(), func(*B) {}) }()
+		<-startedB
+
+		close(releaseA)
+		require.NoError(t, <-first)
+		cancel()
+		close(releaseB)
+		require.NoError(t, <-second)
+
+		require.NoError(t, c.Invoke(func(ctx context.Context) {
+			assert.NoError(t, ctx.Err())
+		}))
+	})
+}
+
+func TestConcurrencySafeString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "ConcurrencySafe()", fmt.Sprint(dig.ConcurrencySafe()))
+}
+
+func TestMaxConcurrencyString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "MaxConcurrency(4)", fmt.Sprint(dig.MaxConcurrency(4)))
+}
@@ -23,6 +23,7 @@ package dig
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -42,6 +43,9 @@ import (
 //	              A slice consuming a value group. This will receive all
 //	              values produced with a `group:".."` tag with the same name
 //	              as a slice.
+//	paramLazy     A dig.Lazy dependency on an explicitly requested type.
+//	paramOptional A dig.Optional dependency on an explicitly requested type.
+//	paramOriginal A dig.Original dependency on an explicitly requested type.
 type param interface {
 	fmt.Stringer
 
@@ -78,6 +82,12 @@ func newParam(t reflect.Type, c containerStore) (param, error) {
 		return nil, newErrInvalidInput(fmt.Sprintf(
 			"cannot depend on a pointer to a parameter object, use a value instead: %v is a pointer to a struct that embeds dig.In",
 			t), nil)
+	case isLazy(t):
+		return newParamLazy(t)
+	case isOptional(t):
+		return newParamOptional(t)
+	case isOriginal(t):
+		return newParamOriginal(t)
 	default:
 		return paramSingle{Type: t}, nil
 	}
@@ -112,12 +122,12 @@ func (pl paramList) String() string {
 // newParamList builds a paramList from the provided constructor type.
 //
 // Variadic arguments of a constructor are ignored and not included as
-// dependencies.
+// dependencies. Annotate with Variadic to fill them from a value group.
 func newParamList(ctype reflect.Type, c containerStore) (paramList, error) {
 	numArgs := ctype.NumIn()
 	if ctype.IsVariadic() {
-		// NOTE: If the function is variadic, we skip the last argument
-		// because we're not filling variadic arguments yet. See #120.
+		// NOTE: If the function is variadic, we skip the last argument:
+		// it is left empty unless annotated with Variadic. See #120.
 		numArgs--
 	}
 
@@ -145,15 +155,7 @@ func (pl paramList) Build(containerStore) (reflect.Value, error) {
 // BuildList returns an ordered list of values which may be passed directly
 // to the underlying constructor.
 func (pl paramList) BuildList(c containerStore) ([]reflect.Value, error) {
-	args := make([]reflect.Value, len(pl.Params))
-	for i, p := range pl.Params {
-		var err error
-		args[i], err = p.Build(c)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return args, nil
+	return buildParams(c, pl.Params)
 }
 
 // paramSingle is an explicitly requested type, optionally with a name.
@@ -164,6 +166,20 @@ type paramSingle struct {
 	Name     string
 	Optional bool
 	Type     reflect.Type
+
+	// Selector holds the sorted labels selected with the `select:".."`
+	// tag, if any. The name of the parameter is then that of the only value
+	// whose labels match.
+	Selector []string
+
+	// Default is the value of the parameter, if it is optional and not
+	// provided, set with dig.Default. The zero value of Type is used if
+	// Default is invalid.
+	Default reflect.Value
+
+	// Undecorated is set if the parameter receives the value as it was
+	// provided, before any decorator, with the `decorated:"false"` tag.
+	Undecorated bool
 }
 
 func (ps paramSingle) DotParam() []*dot.Param {
@@ -189,6 +205,12 @@ func (ps paramSingle) String() string {
 	if ps.Name != "" {
 		opts = append(opts, fmt.Sprintf("name=%q", ps.Name))
 	}
+	if len(ps.Selector) > 0 {
+		opts = append(opts, fmt.Sprintf("select=%q", strings.Join(ps.Selector, ",")))
+	}
+	if ps.Undecorated {
+		opts = append(opts, "undecorated")
+	}
 
 	if len(opts) == 0 {
 		return fmt.Sprint(ps.Type)
@@ -199,7 +221,10 @@ func (ps paramSingle) String() string {
 
 // search the given container and its ancestors for a decorated value.
 func (ps paramSingle) getDecoratedValue(c containerStore) (reflect.Value, bool) {
-	for _, c := range c.storesToRoot() {
+	for i, c := range c.storesToRoot() {
+		if d, ok := c.getValueDecorator(ps.Name, ps.Type); ok && !decoratorApplies(d, i) {
+			continue
+		}
 		if v, ok := c.getDecoratedValue(ps.Name, ps.Type); ok {
 			return v, ok
 		}
@@ -218,10 +243,14 @@ func (ps paramSingle) buildWithDecorators(c containerStore) (v reflect.Value, fo
 	)
 	stores := c.storesToRoot()
 
-	for _, s := range stores {
+	for i, s := range stores {
 		if d, found = s.getValueDecorator(ps.Name, ps.Type); !found {
 			continue
 		}
+		if !decoratorApplies(d, i) {
+			d, found = nil, false
+			continue
+		}
 		if d.State() == decoratorOnStack {
 			// This decorator is already being run.
 			// Avoid a cycle and look further.
@@ -246,15 +275,77 @@ func (ps paramSingle) buildWithDecorators(c containerStore) (v reflect.Value, fo
 	return
 }
 
+// cachedValue returns the value of the parameter if it was already built
+// in s or one of its ancestors and no decorator applies to it. This is the
+// path of most parameters once the container is warm, which walks the
+// Scopes without allocating, unlike Build's general case.
+func (ps paramSingle) cachedValue(s *Scope) (reflect.Value, bool) {
+	k := key{t: ps.Type, name: ps.Name}
+	for cur := s; cur != nil; cur = cur.parentScope {
+		if cur.state != nil && cur.state.delegate != nil {
+			return _noValue, false
+		}
+		if _, ok := cur.decorators[k]; ok {
+			return _noValue, false
+		}
+		if _, ok := cur.decoratedValues[k]; ok {
+			return _noValue, false
+		}
+	}
+
+	for cur := s; cur != nil; cur = cur.parentScope {
+		if v, ok := cur.values[k]; ok {
+			for _, n := range cur.providers[k] {
+				n.notifyCached()
+			}
+			return v, true
+		}
+		if len(cur.providers[k]) > 0 {
+			return _noValue, false
+		}
+	}
+	return _noValue, false
+}
+
 func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
-	v, found, err := ps.buildWithDecorators(c)
-	if found {
-		return v, err
+	v, err := ps.build(c)
+	if err != nil && ps.Optional && skipDependency(c, key{t: ps.Type, name: ps.Name}, err) {
+		return ps.absent(), nil
 	}
+	return v, err
+}
 
-	// Check whether the value is a decorated value first.
-	if v, ok := ps.getDecoratedValue(c); ok {
-		return v, nil
+func (ps paramSingle) build(c containerStore) (reflect.Value, error) {
+	if len(ps.Selector) > 0 {
+		name, err := ps.selectName(c)
+		if err != nil {
+			return _noValue, err
+		}
+		if name == "" {
+			if ps.Optional {
+				return ps.absent(), nil
+			}
+			return _noValue, ps.noSelectedValue()
+		}
+		ps.Name, ps.Selector = name, nil
+	}
+
+	if s, ok := c.(*Scope); ok {
+		if v, ok := ps.cachedValue(s); ok {
+			return v, nil
+		}
+	}
+
+	if !ps.Undecorated {
+		v, found, err := ps.buildWithDecorators(c)
+		if found {
+			return v, err
+		}
+
+		// Check whether the value is a decorated value first.
+		if v, ok := ps.getDecoratedValue(c); ok {
+			return v, nil
+		}
 	}
 
 	// Starting at the given container and working our way up its parents,
@@ -268,6 +359,11 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 	for _, container := range c.storesToRoot() {
 		// first check if the scope already has cached a value for the type.
 		if v, ok := container.getValue(ps.Name, ps.Type); ok {
+			for _, p := range container.getValueProviders(ps.Name, ps.Type) {
+				if n, ok := p.(*constructorNode); ok {
+					n.notifyCached()
+				}
+			}
 			return v, nil
 		}
 		providers = container.getValueProviders(ps.Name, ps.Type)
@@ -278,37 +374,62 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 	}
 
 	if len(providers) == 0 {
+		if ps.isContext() {
+			ctx := c.invokeContext()
+			return reflect.ValueOf(&ctx).Elem(), nil
+		}
+		if s, ok := c.(*Scope); ok && ps.isScope() {
+			v := reflect.New(ps.Type).Elem()
+			v.Set(reflect.ValueOf(s))
+			return v, nil
+		}
+		if f, fs := ps.factory(c); f != nil {
+			return f.build(fs, ps.Type)
+		}
 		if ps.Optional {
-			return reflect.Zero(ps.Type), nil
+			return ps.absent(), nil
 		}
 		return _noValue, newErrMissingTypes(c, key{name: ps.Name, t: ps.Type})
 	}
 
-	for _, n := range providers {
-		err := n.Call(n.OrigScope())
-		if err == nil {
-			continue
-		}
-
-		// If we're missing dependencies but the parameter itself is optional,
-		// we can just move on.
-		if _, ok := err.(errMissingDependencies); ok && ps.Optional {
-			return reflect.Zero(ps.Type), nil
+	if n, ok := providers[0].(*constructorNode); ok && n.transient {
+		// Transient constructors are the only provider of their values,
+		// which are built again each time they are needed.
+		v, err := n.CallTransient(n.OrigScope(), key{t: ps.Type, name: ps.Name})
+		if err != nil {
+			return ps.providerFailed(c, n, err)
 		}
+		return v, nil
+	}
 
-		return _noValue, errParamSingleFailed{
-			CtorID: n.ID(),
-			Key:    key{t: ps.Type, name: ps.Name},
-			Reason: err,
+	for _, n := range providers {
+		if err := n.Call(n.OrigScope()); err != nil {
+			return ps.providerFailed(c, n, err)
 		}
 	}
 
 	// If we get here, it's impossible for the value to be absent from the
 	// container.
-	v, _ = providingContainer.getValue(ps.Name, ps.Type)
+	v, _ := providingContainer.getValue(ps.Name, ps.Type)
 	return v, nil
 }
 
+// providerFailed reports the failure of a provider of the param.
+func (ps paramSingle) providerFailed(c containerStore, n provider, err error) (reflect.Value, error) {
+	// If we're missing dependencies but the parameter itself is optional,
+	// we can just move on. TryInvoke still reports it.
+	if _, ok := err.(errMissingDependencies); ok && ps.Optional {
+		skipDependency(c, key{t: ps.Type, name: ps.Name}, err)
+		return ps.absent(), nil
+	}
+
+	return _noValue, errParamSingleFailed{
+		CtorID: n.ID(),
+		Key:    key{t: ps.Type, name: ps.Name},
+		Reason: err,
+	}
+}
+
 // paramObject is a dig.In struct where each field is another param.
 //
 // This object is not expected in the graph as-is.
@@ -339,8 +460,16 @@ func getParamOrder(gh *graphHolder, param param) []int {
 	var orders []int
 	switch p := param.(type) {
 	case paramSingle:
+		if len(p.Selector) > 0 {
+			if p.Name, _ = p.selectName(gh.s); p.Name == "" {
+				break
+			}
+		}
 		providers := gh.s.getAllValueProviders(p.Name, p.Type)
 		for _, provider := range providers {
+			if gh.delegated(provider) {
+				continue
+			}
 			orders = append(orders, provider.Order(gh.s))
 		}
 	case paramGroupedSlice:
@@ -351,6 +480,14 @@ func getParamOrder(gh *graphHolder, param param) []int {
 		for _, pf := range p.Fields {
 			orders = append(orders, getParamOrder(gh, pf.Param)...)
 		}
+	case paramLazy:
+		if !gh.s.rootScope().state.lazyCycles {
+			orders = append(orders, getParamOrder(gh, p.Elem)...)
+		}
+	case paramOptional:
+		orders = append(orders, getParamOrder(gh, p.Elem)...)
+	case paramOriginal:
+		orders = append(orders, getParamOrder(gh, p.Elem)...)
 	}
 	return orders
 }
@@ -374,8 +511,15 @@ func newParamObject(t reflect.Type, c containerStore) (paramObject, error) {
 		}
 	}
 
+	strict := isStrictIn(c)
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
+		if strict {
+			if err := checkStrictInField(f); err != nil {
+				return po, newErrInvalidInput(
+					fmt.Sprintf("bad field %q of %v", f.Name, t), err)
+			}
+		}
 		if f.Type == _inType {
 			// Skip over the dig.In embed.
 			continue
@@ -395,28 +539,60 @@ func newParamObject(t reflect.Type, c containerStore) (paramObject, error) {
 }
 
 func (po paramObject) Build(c containerStore) (reflect.Value, error) {
+	return po.build(c, true /* failFast */)
+}
+
+// build builds the dig.In struct. Unless failFast is true, all of its fields
+// are built even if some of them fail, and the errors of all the fields
+// that failed are returned.
+func (po paramObject) build(c containerStore, failFast bool) (reflect.Value, error) {
 	dest := reflect.New(po.Type).Elem()
 	// We have to build soft groups after all other fields, to avoid cases
 	// when a field calls a provider for a soft value group, but the value is
 	// not provided to it because the value group is declared before the field
 	var softGroupsQueue []paramObjectField
-	var fields []paramObjectField
+	fields := make([]paramObjectField, 0, len(po.Fields))
+	params := make([]param, 0, len(po.Fields))
 	for _, f := range po.Fields {
 		if p, ok := f.Param.(paramGroupedSlice); ok && p.Soft {
 			softGroupsQueue = append(softGroupsQueue, f)
 			continue
 		}
 		fields = append(fields, f)
+		params = append(params, f.Param)
+	}
+	var failures errDependencyFailures
+	values, errs := buildEachParam(c, params, failFast)
+	for i, f := range fields {
+		if err := errs[i]; err != nil {
+			if failFast {
+				return dest, err
+			}
+			failures = append(failures, err)
+			continue
+		}
+		dest.Field(f.FieldIndex).Set(values[i])
 	}
-	fields = append(fields, softGroupsQueue...)
-	for _, f := range fields {
+	for _, f := range softGroupsQueue {
 		v, err := f.Build(c)
 		if err != nil {
-			return dest, err
+			if failFast {
+				return dest, err
+			}
+			failures = append(failures, err)
+			continue
 		}
 		dest.Field(f.FieldIndex).Set(v)
 	}
-	return dest, nil
+
+	switch len(failures) {
+	case 0:
+		return dest, nil
+	case 1:
+		return dest, failures[0]
+	default:
+		return dest, failures
+	}
 }
 
 // paramObjectField is a single field of a dig.In struct.
@@ -466,6 +642,32 @@ func newParamObjectField(idx int, f reflect.StructField, c containerStore) (para
 		}
 	}
 
+	undecorated, err := isFieldUndecorated(f)
+	if err != nil {
+		return pof, err
+	}
+	if undecorated {
+		switch pt := p.(type) {
+		case paramSingle:
+			pt.Undecorated = true
+			p = pt
+		case paramGroupedSlice:
+			pt.Undecorated = true
+			p = pt
+		case paramLazy:
+			pt.Elem.Undecorated = true
+			p = pt
+		case paramOptional:
+			pt.Elem.Undecorated = true
+			p = pt
+		case paramOriginal:
+			// Already undecorated.
+		default:
+			return pof, newErrInvalidInput(fmt.Sprintf(
+				"cannot use %q tag on field %v: %v is not a value of the container", _decoratedTag, f.Name, f.Type), nil)
+		}
+	}
+
 	if ps, ok := p.(paramSingle); ok {
 		ps.Name = f.Tag.Get(_nameTag)
 
@@ -474,9 +676,39 @@ func newParamObjectField(idx int, f reflect.StructField, c containerStore) (para
 		if err != nil {
 			return pof, err
 		}
+		ps.Selector, err = fieldSelector(f)
+		if err != nil {
+			return pof, err
+		}
 
 		p = ps
 	}
+	if pl, ok := p.(paramLazy); ok {
+		pl.Elem.Name = f.Tag.Get(_nameTag)
+
+		var err error
+		pl.Elem.Optional, err = isFieldOptional(f)
+		if err != nil {
+			return pof, err
+		}
+
+		p = pl
+	}
+	if po, ok := p.(paramOriginal); ok {
+		po.Elem.Name = f.Tag.Get(_nameTag)
+		p = po
+	}
+	if po, ok := p.(paramOptional); ok {
+		po.Elem.Name = f.Tag.Get(_nameTag)
+
+		var err error
+		po.Elem.Selector, err = fieldSelector(f)
+		if err != nil {
+			return pof, err
+		}
+
+		p = po
+	}
 
 	pof.Param = p
 	return pof, nil
@@ -491,12 +723,12 @@ func (pof paramObjectField) Build(c containerStore) (reflect.Value, error) {
 }
 
 // paramGroupedSlice is a param which produces a slice of values with the same
-// group name.
+// group name, or a map of these values keyed by their names.
 type paramGroupedSlice struct {
 	// Name of the group as specified in the `group:".."` tag.
 	Group string
 
-	// Type of the slice.
+	// Type of the slice, or of the map.
 	Type reflect.Type
 
 	// Soft is used to denote a soft dependency between this param and its
@@ -504,6 +736,21 @@ type paramGroupedSlice struct {
 	// provide another value requested in the graph
 	Soft bool
 
+	// Tolerant is used to denote that failures of the constructors feeding
+	// this param are tolerated: values of the constructors that failed are
+	// left out of the slice instead of failing the whole resolution.
+	Tolerant bool
+
+	// Sorted is used to denote that the values of the slice are ordered
+	// deterministically: with the less function registered for the group,
+	// if any, or by ascending weight and then in the order their
+	// constructors were provided.
+	Sorted bool
+
+	// Undecorated is set if the slice holds the values as they were
+	// provided, before any decorator, with the `decorated:"false"` tag.
+	Undecorated bool
+
 	orders map[*Scope]int
 }
 
@@ -533,19 +780,29 @@ func newParamGroupedSlice(f reflect.StructField, c containerStore) (paramGrouped
 		return paramGroupedSlice{}, err
 	}
 	pg := paramGroupedSlice{
-		Group:  g.Name,
-		Type:   f.Type,
-		orders: make(map[*Scope]int),
-		Soft:   g.Soft,
+		Group:    g.Name,
+		Type:     f.Type,
+		orders:   make(map[*Scope]int),
+		Soft:     g.Soft,
+		Tolerant: g.Tolerant,
+		Sorted:   g.Sorted,
 	}
 
 	name := f.Tag.Get(_nameTag)
 	optional, _ := isFieldOptional(f)
 	switch {
-	case f.Type.Kind() != reflect.Slice:
+	case f.Type.Kind() == reflect.Map && f.Type.Key().Kind() != reflect.String:
+		return pg, newErrInvalidInput(
+			fmt.Sprintf("value groups may be consumed as maps keyed by strings only: field %q (%v) is not keyed by a string",
+				f.Name, f.Type), nil)
+	case f.Type.Kind() != reflect.Slice && f.Type.Kind() != reflect.Map:
 		return pg, newErrInvalidInput(
 			fmt.Sprintf("value groups may be consumed as slices only: field %q (%v) is not a slice", f.Name, f.Type),
 			nil)
+	case g.Sorted && f.Type.Kind() == reflect.Map:
+		return pg, newErrInvalidInput(
+			fmt.Sprintf("cannot use sorted with value groups consumed as maps: field %q (%v) specifies sorted",
+				f.Name, f.Type), nil)
 	case g.Flatten:
 		return pg, newErrInvalidInput(
 			fmt.Sprintf("cannot use flatten in parameter value groups: field %q (%v) specifies flatten", f.Name,
@@ -566,8 +823,11 @@ func newParamGroupedSlice(f reflect.StructField, c containerStore) (paramGrouped
 // are decorating the same type, the closest scope in effect will be replacing
 // any decorated value groups provided in further scopes.
 func (pt paramGroupedSlice) getDecoratedValues(c containerStore) (reflect.Value, bool) {
-	for _, c := range c.storesToRoot() {
-		if items, ok := c.getDecoratedValueGroup(pt.Group, pt.Type); ok {
+	for i, c := range c.storesToRoot() {
+		if d, ok := c.getGroupDecorator(pt.Group, pt.Type.Elem()); ok && !decoratorApplies(d, i) {
+			continue
+		}
+		if items, ok := c.getDecoratedValueGroup(pt.Group, reflect.SliceOf(pt.Type.Elem())); ok {
 			return items, true
 		}
 	}
@@ -585,6 +845,9 @@ func (pt paramGroupedSlice) callGroupDecorators(c containerStore) error {
 	for i := len(stores) - 1; i >= 0; i-- {
 		c := stores[i]
 		if d, found := c.getGroupDecorator(pt.Group, pt.Type.Elem()); found {
+			if !decoratorApplies(d, i) {
+				continue
+			}
 			if d.State() == decoratorOnStack {
 				// This decorator is already being run. Avoid cycle
 				// and look further.
@@ -605,6 +868,9 @@ func (pt paramGroupedSlice) callGroupDecorators(c containerStore) error {
 // search the given container and its parent for matching group providers and
 // call them to commit values. If an error is encountered, return the number
 // of providers called and a non-nil error from the first provided.
+//
+// If the group is tolerant, providers that fail are skipped and their values
+// are left out of the group. A GroupWarning is recorded for each of them.
 func (pt paramGroupedSlice) callGroupProviders(c containerStore) (int, error) {
 	itemCount := 0
 	for _, c := range c.storesToRoot() {
@@ -612,6 +878,17 @@ func (pt paramGroupedSlice) callGroupProviders(c containerStore) (int, error) {
 		itemCount += len(providers)
 		for _, n := range providers {
 			if err := n.Call(n.OrigScope()); err != nil {
+				if pt.Tolerant {
+					itemCount--
+					w := GroupWarning{Group: pt.Group, Type: pt.Type.Elem(), Reason: err}
+					if cn, ok := n.(*constructorNode); ok {
+						w.Provider = newProviderInfo(cn)
+					} else {
+						w.Provider.ID = ID(n.ID())
+					}
+					n.OrigScope().warn(w)
+					continue
+				}
 				return 0, errParamGroupFailed{
 					CtorID: n.ID(),
 					Key:    key{group: pt.Group, t: pt.Type.Elem()},
@@ -627,13 +904,19 @@ func (pt paramGroupedSlice) Build(c containerStore) (reflect.Value, error) {
 	// do not call this if we are already inside a decorator since
 	// it will result in an infinite recursion. (i.e. decorate -> params.BuildList() -> Decorate -> params.BuildList...)
 	// this is safe since a value can be decorated at most once in a given scope.
-	if err := pt.callGroupDecorators(c); err != nil {
-		return _noValue, err
-	}
+	if !pt.Undecorated {
+		if err := pt.callGroupDecorators(c); err != nil {
+			return _noValue, err
+		}
 
-	// Check if we have decorated values
-	if decoratedItems, ok := pt.getDecoratedValues(c); ok {
-		return decoratedItems, nil
+		// Check if we have decorated values
+		if decoratedItems, ok := pt.getDecoratedValues(c); ok {
+			if pt.Type.Kind() == reflect.Map {
+				return _noValue, newErrInvalidInput(
+					fmt.Sprintf("cannot consume decorated value group %q as a map (%v)", pt.Group, pt.Type), nil)
+			}
+			return decoratedItems, nil
+		}
 	}
 
 	// If we do not have any decorated values and the group isn't soft,
@@ -648,6 +931,13 @@ func (pt paramGroupedSlice) Build(c containerStore) (reflect.Value, error) {
 	}
 
 	stores := c.storesToRoot()
+	if pt.Type.Kind() == reflect.Map {
+		return pt.buildMap(stores, itemCount)
+	}
+	if pt.Sorted {
+		return pt.buildSorted(c, stores, itemCount)
+	}
+
 	result := reflect.MakeSlice(pt.Type, 0, itemCount)
 	for _, c := range stores {
 		result = reflect.Append(result, c.getValueGroup(pt.Group, pt.Type.Elem())...)
@@ -655,6 +945,60 @@ func (pt paramGroupedSlice) Build(c containerStore) (reflect.Value, error) {
 	return result, nil
 }
 
+// buildSorted builds a slice of the values of the group in a deterministic
+// order: with the less function registered for the group, if any, or by
+// ascending weight and then in the order their constructors were provided.
+// Values produced by the same constructor keep the order it returned them
+// in.
+func (pt paramGroupedSlice) buildSorted(c containerStore, stores []containerStore, itemCount int) (reflect.Value, error) {
+	gvs := make([]groupValue, 0, itemCount)
+	for _, s := range stores {
+		gvs = append(gvs, s.getOrderedValueGroup(pt.Group, pt.Type.Elem())...)
+	}
+	sort.SliceStable(gvs, func(i, j int) bool {
+		if gvs[i].Weight != gvs[j].Weight {
+			return gvs[i].Weight < gvs[j].Weight
+		}
+		return gvs[i].Order < gvs[j].Order
+	})
+	if less, ok := c.getGroupLess(pt.Group, pt.Type.Elem()); ok {
+		sort.SliceStable(gvs, func(i, j int) bool {
+			return less.Call([]reflect.Value{gvs[i].Value, gvs[j].Value})[0].Bool()
+		})
+	}
+
+	result := reflect.MakeSlice(pt.Type, len(gvs), len(gvs))
+	for i, gv := range gvs {
+		result.Index(i).Set(gv.Value)
+	}
+	return result, nil
+}
+
+// buildMap builds a map of the values of the group, keyed by their names.
+// Every value of the group must have a unique name.
+func (pt paramGroupedSlice) buildMap(stores []containerStore, itemCount int) (reflect.Value, error) {
+	result := reflect.MakeMapWithSize(pt.Type, itemCount)
+	for _, c := range stores {
+		named := c.getNamedValueGroup(pt.Group, pt.Type.Elem())
+		if n := len(c.getValueGroup(pt.Group, pt.Type.Elem())) - len(named); n > 0 {
+			return _noValue, newErrInvalidInput(fmt.Sprintf(
+				"cannot consume value group %q as a map (%v): %d value(s) were provided without a name",
+				pt.Group, pt.Type, n), nil)
+		}
+
+		for _, nv := range named {
+			k := reflect.ValueOf(nv.Name).Convert(pt.Type.Key())
+			if result.MapIndex(k).IsValid() {
+				return _noValue, newErrInvalidInput(fmt.Sprintf(
+					"cannot consume value group %q as a map (%v): name %q was provided more than once",
+					pt.Group, pt.Type, nv.Name), nil)
+			}
+			result.SetMapIndex(k, nv.Value)
+		}
+	}
+	return result, nil
+}
+
 // Checks if ignoring unexported files in an In struct is allowed.
 // The struct field MUST be an _inType.
 func isIgnoreUnexportedSet(f reflect.StructField) (bool, error) {
@@ -195,6 +195,16 @@ func TestParamGroupSliceErrors(t *testing.T) {
 			wantErr: "value groups may be consumed as slices only: " +
 				`field "Foo" (string) is not a slice`,
 		},
+		{
+			desc: "map must be keyed by strings",
+			shape: struct {
+				In
+
+				Foo map[int]string `group:"foo"`
+			}{},
+			wantErr: "value groups may be consumed as maps keyed by strings only: " +
+				`field "Foo" (map[int]string) is not keyed by a string`,
+		},
 		{
 			desc: "cannot provide name for a group",
 			shape: struct {
@@ -233,3 +243,93 @@ func TestParamGroupSliceErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestParamSingleCachedValue(t *testing.T) {
+	type A struct{}
+
+	c := New()
+	require.NoError(t, c.Provide(func() *A { return &A{} }))
+	child := c.Scope("child")
+	require.NoError(t, child.Invoke(func(*A) {}))
+
+	ps := paramSingle{Type: reflect.TypeOf(&A{})}
+	t.Run("does not allocate", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := ps.Build(child); err != nil {
+				t.Fatal(err)
+			}
+		})
+		assert.Zero(t, allocs)
+	})
+
+	t.Run("counts cache hits", func(t *testing.T) {
+		hits := c.scope.state.cacheHits.Load()
+		_, err := ps.Build(child)
+		require.NoError(t, err)
+		assert.Equal(t, hits+1, c.scope.state.cacheHits.Load())
+	})
+
+	t.Run("decorated values", func(t *testing.T) {
+		decorated := &A{}
+		require.NoError(t, child.Decorate(func(*A) *A { return decorated }))
+
+		_, ok := ps.cachedValue(child)
+		assert.False(t, ok, "decorated values are not served from the cache")
+
+		v, err := ps.Build(child)
+		require.NoError(t, err)
+		assert.Same(t, decorated, v.Interface())
+	})
+
+	t.Run("values not built yet", func(t *testing.T) {
+		type B struct{}
+		require.NoError(t, c.Provide(func() *B { return &B{} }))
+
+		_, ok := paramSingle{Type: reflect.TypeOf(&B{})}.cachedValue(child)
+		assert.False(t, ok)
+	})
+}
+
+func BenchmarkParamSingleBuild(b *testing.B) {
+	type A struct{}
+
+	c := New()
+	require.NoError(b, c.Provide(func() *A { return &A{} }))
+	s := c.Scope("child").Scope("grandchild")
+	require.NoError(b, s.Invoke(func(*A) {}))
+
+	ps := paramSingle{Type: reflect.TypeOf(&A{})}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ps.Build(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParamObjectBuild(b *testing.B) {
+	type A struct{}
+	type B struct{}
+	type params struct {
+		In
+
+		A *A
+		B *B `name:"b"`
+	}
+
+	c := New()
+	require.NoError(b, c.Provide(func() *A { return &A{} }))
+	require.NoError(b, c.Provide(func() *B { return &B{} }, Name("b")))
+	require.NoError(b, c.Invoke(func(params) {}))
+
+	po, err := newParamObject(reflect.TypeOf(params{}), c.scope)
+	require.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := po.Build(c.scope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ProvidePartial provides a constructor with some of its parameters bound to
+// the given arguments, and the others injected from the container. This
+// adapts constructors taking primitive values, such as those of third-party
+// packages, without wrapping them in a closure.
+//
+//	// func NewClient(addr string, timeout time.Duration, log *Logger) *Client
+//	err := c.ProvidePartial(NewClient, "localhost:8080", 5*time.Second)
+//
+// Each argument is bound to the first parameter of the constructor that is
+// not bound yet and whose type the argument is assignable to, so arguments
+// of the same type are bound in the order of the parameters, and arguments
+// may be bound to interface parameters:
+//
+//	// func NewPrinter(w io.Writer, log *Logger) *Printer
+//	err := c.ProvidePartial(NewPrinter, os.Stdout)
+//
+// Fields of dig.In parameter objects and named parameters are never bound.
+// Bound parameters are not dependencies of the constructor: they are not
+// looked up in the container, nor drawn by Visualize.
+//
+// ProvidePartial fails if an argument does not match a parameter. Use
+// Annotate to name the results of the constructor or to add them to value
+// groups.
+func (c *Container) ProvidePartial(constructor interface{}, args ...interface{}) error {
+	return c.scope.ProvidePartial(constructor, args...)
+}
+
+// ProvidePartial provides a constructor with some of its parameters bound to
+// the given arguments to the Scope. See Container.ProvidePartial.
+func (s *Scope) ProvidePartial(constructor interface{}, args ...interface{}) error {
+	return s.Provide(constructor, provideBoundArgsOption(args))
+}
+
+type provideBoundArgsOption []interface{}
+
+func (o provideBoundArgsOption) String() string {
+	types := make([]reflect.Type, len(o))
+	for i, v := range o {
+		types[i] = reflect.TypeOf(v)
+	}
+	return fmt.Sprintf("ProvidePartial(%v)", types)
+}
+
+func (o provideBoundArgsOption) applyProvideOption(opts *provideOptions) {
+	opts.BoundArgs = append(opts.BoundArgs, o...)
+}
+
+// bind returns a copy of the paramList where each of the given values
+// replaces the first unnamed parameter that it is assignable to and that
+// was not replaced yet.
+func (pl paramList) bind(values []interface{}) (paramList, error) {
+	params := append([]param(nil), pl.Params...)
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		if t == nil {
+			return pl, newErrInvalidInput("cannot bind an untyped nil with ProvidePartial", nil)
+		}
+
+		bound := false
+		for i, p := range params {
+			if ps, ok := p.(paramSingle); ok && t.AssignableTo(ps.Type) && ps.Name == "" {
+				value := reflect.New(ps.Type).Elem()
+				value.Set(reflect.ValueOf(v))
+				params[i] = paramSupplied{paramSingle: ps, Value: value}
+				bound = true
+				break
+			}
+		}
+		if !bound {
+			return pl, newErrInvalidInput(
+				fmt.Sprintf("argument of type %v bound with ProvidePartial does not match a parameter of the constructor", t), nil)
+		}
+	}
+	pl.Params = params
+	return pl, nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvidePartial(t *testing.T) {
+	t.Parallel()
+
+	type Logger struct{ Prefix string }
+	type Client struct {
+		Addr    string
+		User    string
+		Timeout time.Duration
+		Log     *Logger
+	}
+
+	newClient := func(addr string, timeout time.Duration, log *Logger, user string) *Client {
+		return &Client{Addr: addr, User: user, Timeout: timeout, Log: log}
+	}
+
+	t.Run("binds arguments", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{Prefix: "client"} })
+		require.NoError(t, c.ProvidePartial(newClient, "localhost:8080", "admin", 5*time.Second))
+
+		c.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, "localhost:8080", cl.Addr)
+			assert.Equal(t, "admin", cl.User)
+			assert.Equal(t, 5*time.Second, cl.Timeout)
+			assert.Equal(t, "client", cl.Log.Prefix)
+		})
+	})
+
+	t.Run("bound parameters are not dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "from container" })
+		c.RequireProvide(func() *Logger { return &Logger{} })
+		require.NoError(t, c.ProvidePartial(newClient, "bound", 5*time.Second))
+
+		c.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, "bound", cl.Addr)
+			assert.Equal(t, "from container", cl.User)
+		})
+	})
+
+	t.Run("interface parameters", func(t *testing.T) {
+		t.Parallel()
+
+		type Printer struct {
+			Out io.Writer
+			Err io.Writer
+		}
+
+		var out, errOut bytes.Buffer
+		c := digtest.New(t)
+		require.NoError(t, c.ProvidePartial(func(out, err io.Writer) *Printer {
+			return &Printer{Out: out, Err: err}
+		}, &out, &errOut))
+
+		c.RequireInvoke(func(p *Printer) {
+			assert.Same(t, &out, p.Out)
+			assert.Same(t, &errOut, p.Err)
+		})
+	})
+
+	t.Run("bound parameters are not drawn", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{} })
+		require.NoError(t, c.ProvidePartial(newClient, "localhost:8080", "admin", 5*time.Second))
+
+		var buf bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &buf))
+		assert.NotContains(t, buf.String(), `"string"`)
+		assert.NotContains(t, buf.String(), `"time.Duration"`)
+		assert.Contains(t, buf.String(), `"*dig_test.Logger"`)
+
+		providers := c.WhoProvides(new(*Client))
+		require.Len(t, providers, 1)
+		require.Len(t, providers[0].Inputs, 1)
+		assert.Equal(t, "*dig_test.Logger", providers[0].Inputs[0].String())
+	})
+
+	t.Run("missing dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.ProvidePartial(newClient, "localhost:8080", "admin", 5*time.Second))
+
+		err := c.Invoke(func(*Client) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Logger")
+		assert.NotContains(t, err.Error(), "missing type: string")
+	})
+
+	t.Run("annotated", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{} })
+		for _, addr := range []string{"a", "b"} {
+			require.NoError(t, c.ProvidePartial(
+				dig.Annotate(newClient, dig.ResultTags(`group:"clients"`)),
+				addr, addr+"-user", time.Second,
+			))
+		}
+
+		type params struct {
+			dig.In
+
+			Clients []*Client `group:"clients"`
+		}
+		c.RequireInvoke(func(p params) {
+			var addrs []string
+			for _, cl := range p.Clients {
+				addrs = append(addrs, cl.Addr+"/"+cl.User)
+			}
+			assert.ElementsMatch(t, []string{"a/a-user", "b/b-user"}, addrs)
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Logger { return &Logger{} })
+		s := c.Scope("child")
+		require.NoError(t, s.ProvidePartial(newClient, "child", "admin", time.Second))
+		s.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, "child", cl.Addr)
+		})
+	})
+
+	t.Run("unmatched argument", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.ProvidePartial(newClient, "a", "b", "c")
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			"cannot provide function",
+			"argument of type string bound with ProvidePartial does not match a parameter of the constructor")
+	})
+
+	t.Run("untyped nil", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.ProvidePartial(newClient, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot bind an untyped nil with ProvidePartial")
+	})
+
+	t.Run("named parameters are not bound", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.ProvidePartial(
+			dig.Annotate(func(addr string) *Client { return &Client{Addr: addr} }, dig.ParamTags(`name:"addr"`)),
+			"localhost",
+		)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match a parameter of the constructor")
+	})
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// PluginFunc sets up a container, typically by providing the constructors
+// of a package to it. See Registry.
+type PluginFunc func(c *Container) error
+
+// Registry holds plugins: setup functions registered under names, which
+// containers apply with Registry.Load. This lets packages add their
+// constructors to applications that only know them by name, such as the
+// names listed in a configuration file.
+//
+//	// In package postgres.
+//	func init() {
+//	  dig.Register("postgres", func(c *dig.Container) error {
+//	    return c.Provide(NewDB)
+//	  })
+//	}
+//
+//	// In the application.
+//	err := c.LoadPlugins(cfg.Plugins...)
+//
+// A Registry is safe for concurrent use. See the digplugin package to
+// register plugins built as Go plugins.
+type Registry struct {
+	mu      sync.RWMutex
+	plugins map[string]registeredPlugin
+}
+
+type registeredPlugin struct {
+	setup    PluginFunc
+	location *digreflect.Func
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{plugins: make(map[string]registeredPlugin)}
+}
+
+// DefaultRegistry is the Registry used by Register and
+// Container.LoadPlugins.
+var DefaultRegistry = NewRegistry()
+
+// Register registers the plugin to DefaultRegistry under the given name.
+// It panics if the name is empty or already registered, or if setup is
+// nil, as it is meant to be called from init functions.
+func Register(name string, setup PluginFunc) {
+	if err := DefaultRegistry.Register(name, setup); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the plugin under the given name. It fails if the name
+// is empty or already registered, or if setup is nil.
+func (r *Registry) Register(name string, setup PluginFunc) error {
+	switch {
+	case name == "":
+		return newErrInvalidInput("cannot register a plugin with an empty name", nil)
+	case setup == nil:
+		return newErrInvalidInput(fmt.Sprintf("cannot register plugin %q: nil setup function", name), nil)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.plugins[name]; ok {
+		return newErrInvalidInput(fmt.Sprintf(
+			"cannot register plugin %q: already registered as %v", name, p.location), nil)
+	}
+	r.plugins[name] = registeredPlugin{setup: setup, location: digreflect.InspectFunc(setup)}
+	return nil
+}
+
+// Names returns the names of the registered plugins, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.plugins))
+	for name := range r.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load applies the plugins with the given names to the container, in
+// order, or all the registered plugins, sorted by name, if no names are
+// given. Plugins already loaded into the container are skipped, so that
+// plugins may load the plugins they depend on.
+//
+// Load fails if a name is not registered, before applying any plugin, or
+// with the error of the first plugin that fails. A plugin that failed is
+// not considered loaded.
+func (r *Registry) Load(c *Container, names ...string) error {
+	if len(names) == 0 {
+		names = r.Names()
+	}
+
+	r.mu.RLock()
+	plugins := make([]registeredPlugin, len(names))
+	var unknown []string
+	for i, name := range names {
+		var ok bool
+		if plugins[i], ok = r.plugins[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	r.mu.RUnlock()
+	if len(unknown) > 0 {
+		return newErrInvalidInput(fmt.Sprintf("unknown plugins %q: registered plugins are %q", unknown, r.Names()), nil)
+	}
+
+	state := c.scope.state
+	if state.plugins == nil {
+		state.plugins = make(map[string]struct{})
+	}
+	for i, name := range names {
+		if _, ok := state.plugins[name]; ok {
+			continue
+		}
+		state.plugins[name] = struct{}{}
+		if err := plugins[i].setup(c); err != nil {
+			// The plugin is applied again by the next Load.
+			delete(state.plugins, name)
+			return errLoadPlugin{Name: name, Func: plugins[i].location, Reason: err}
+		}
+	}
+	return nil
+}
+
+// LoadPlugins applies the plugins of DefaultRegistry with the given names
+// to the container, or all of them if no names are given. See
+// Registry.Load.
+func (c *Container) LoadPlugins(names ...string) error {
+	return DefaultRegistry.Load(c, names...)
+}
+
+// errLoadPlugin is returned when a plugin fails to set up a container.
+type errLoadPlugin struct {
+	Name   string
+	Func   *digreflect.Func
+	Reason error
+}
+
+var _ digError = errLoadPlugin{}
+
+func (e errLoadPlugin) Error() string { return fmt.Sprint(e) }
+
+func (e errLoadPlugin) Unwrap() error { return e.Reason }
+
+func (e errLoadPlugin) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "cannot load plugin %q ("+verb+")", e.Name, e.Func)
+}
+
+func (e errLoadPlugin) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	type A struct{}
+	type B struct{ A *A }
+
+	t.Parallel()
+
+	provideA := func(c *dig.Container) error { return c.Provide(func() *A { return &A{} }) }
+	provideB := func(c *dig.Container) error { return c.Provide(func(a *A) *B { return &B{A: a} }) }
+
+	t.Run("load", func(t *testing.T) {
+		t.Parallel()
+
+		r := dig.NewRegistry()
+		require.NoError(t, r.Register("b", provideB))
+		require.NoError(t, r.Register("a", provideA))
+		assert.Equal(t, []string{"a", "b"}, r.Names())
+
+		c := digtest.New(t)
+		require.NoError(t, r.Load(c.Container, "a", "b"))
+		c.RequireInvoke(func(b *B) {
+			assert.NotNil(t, b.A)
+		})
+	})
+
+	t.Run("load all", func(t *testing.T) {
+		t.Parallel()
+
+		r := dig.NewRegistry()
+		require.NoError(t, r.Register("a", provideA))
+		require.NoError(t, r.Register("b", provideB))
+
+		c := digtest.New(t)
+		require.NoError(t, r.Load(c.Container))
+		c.RequireInvoke(func(*A, *B) {})
+	})
+
+	t.Run("plugins are loaded once", func(t *testing.T) {
+		t.Parallel()
+
+		r := dig.NewRegistry()
+		var calls int
+		require.NoError(t, r.Register("a", func(c *dig.Container) error {
+			calls++
+			return provideA(c)
+		}))
+		require.NoError(t, r.Register("b", func(c *dig.Container) error {
+			// Plugins may load the plugins they depend on.
+			if err := r.Load(c, "a"); err != nil {
+				return err
+			}
+			return provideB(c)
+		}))
+
+		c := digtest.New(t)
+		require.NoError(t, r.Load(c.Container, "b", "a"))
+		require.NoError(t, r.Load(c.Container, "a"))
+		assert.Equal(t, 1, calls)
+		c.RequireInvoke(func(*B) {})
+
+		// Clones remember the loaded plugins, other containers do not.
+		require.NoError(t, r.Load(c.Clone(), "a"))
+		assert.Equal(t, 1, calls)
+		require.NoError(t, r.Load(dig.New(), "a"))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		t.Parallel()
+
+		r := dig.NewRegistry()
+		require.NoError(t, r.Register("a", provideA))
+
+		err := r.Register("", provideA)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "empty name")
+
+		err = r.Register("b", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot register plugin "b": nil setup function`)
+
+		err = r.Register("a", provideB)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot register plugin "a": already registered as`)
+		assert.Contains(t, err.Error(), "TestRegistry")
+		assert.Equal(t, []string{"a"}, r.Names())
+	})
+
+	t.Run("unknown plugins", func(t *testing.T) {
+		t.Parallel()
+
+		r := dig.NewRegistry()
+		var loaded bool
+		require.NoError(t, r.Register("a", func(*dig.Container) error {
+			loaded = true
+			return nil
+		}))
+		require.NoError(t, r.Register("b", provideB))
+
+		err := r.Load(dig.New(), "a", "c", "d")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown plugins ["c" "d"]: registered plugins are ["a" "b"]`)
+		assert.False(t, loaded, "no plugin must be loaded")
+	})
+
+	t.Run("setup fails", func(t *testing.T) {
+		t.Parallel()
+
+		giveErr := errors.New("great sadness")
+		var calls int
+		r := dig.NewRegistry()
+		require.NoError(t, r.Register("a", func(*dig.Container) error {
+			calls++
+			return giveErr
+		}))
+
+		c := dig.New()
+		err := r.Load(c, "a")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, giveErr)
+		assert.Contains(t, err.Error(), `cannot load plugin "a" (`)
+		assert.Contains(t, err.Error(), "great sadness")
+
+		assert.ErrorIs(t, r.Load(c, "a"), giveErr, "failed plugins must be applied again")
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestRegister(t *testing.T) {
+	dig.Register("dig_test.register", func(c *dig.Container) error {
+		return c.Provide(func() string { return "registered" }, dig.Name("register"))
+	})
+	assert.Panics(t, func() {
+		dig.Register("dig_test.register", func(*dig.Container) error { return nil })
+	})
+	assert.Contains(t, dig.DefaultRegistry.Names(), "dig_test.register")
+
+	c := digtest.New(t)
+	require.NoError(t, c.LoadPlugins("dig_test.register"))
+	type params struct {
+		dig.In
+
+		S string `name:"register"`
+	}
+	c.RequireInvoke(func(p params) {
+		assert.Equal(t, "registered", p.S)
+	})
+}
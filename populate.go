@@ -0,0 +1,157 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+	"github.com/alexisvisco/dig/internal/graph"
+)
+
+// Populate fills the exported fields of the struct pointed to by target
+// with values from the container, as if target were a dig.In struct
+// requested by a function given to Invoke. The fields support the same name,
+// group, and optional tags as the fields of dig.In structs.
+//
+//	var target struct {
+//		DB      *sql.DB
+//		Cache   *Cache  `optional:"true"`
+//		Routes  []Route `group:"routes"`
+//		Primary *Conn   `name:"primary"`
+//	}
+//	err := c.Populate(&target)
+//
+// Unexported fields, and fields that are not populated because of an
+// error, are left untouched.
+func (c *Container) Populate(target interface{}) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return c.scope.populate(pc, target)
+}
+
+// Populate fills the exported fields of the struct pointed to by target
+// with values from the Scope. See Container.Populate for more details.
+func (s *Scope) Populate(target interface{}) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return s.populate(pc, target)
+}
+
+func (s *Scope) populate(pc uintptr, target interface{}) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return newErrInvalidInput(
+			fmt.Sprintf("can't populate %v (type %T): must be a non-nil pointer to a struct", target, target), nil)
+	}
+
+	var (
+		po      paramObject
+		built   reflect.Value
+		invalid error
+	)
+	err := s.parallelism().do(func() error {
+		if po, invalid = newPopulateObject(v.Elem().Type(), s); invalid != nil {
+			return nil
+		}
+
+		var err error
+		built, err = s.buildObject(po)
+		return err
+	})
+	if invalid != nil {
+		return invalid
+	}
+	if err != nil {
+		return errPopulate{
+			Func:   digreflect.InspectFuncPC(pc),
+			Target: v.Type(),
+			Reason: err,
+		}
+	}
+
+	for _, f := range po.Fields {
+		v.Elem().Field(f.FieldIndex).Set(built.Field(f.FieldIndex))
+	}
+	return nil
+}
+
+// buildObject builds the fields of po from the Scope. It must be called
+// through s.parallelism().do.
+func (s *Scope) buildObject(po paramObject) (reflect.Value, error) {
+	if err := shallowCheckDependencies(s, paramList{Params: []param{po}}); err != nil {
+		return _noValue, err
+	}
+
+	if !s.isVerifiedAcyclic {
+		if ok, cycle := graph.IsAcyclic(s.gh); !ok {
+			return _noValue, newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(cycle))
+		}
+		s.isVerifiedAcyclic = true
+	}
+
+	return po.Build(s)
+}
+
+// newPopulateObject builds a paramObject for the exported fields of the
+// struct type t, which needs not embed dig.In.
+func newPopulateObject(t reflect.Type, c containerStore) (paramObject, error) {
+	po := paramObject{Type: t}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type == _inType || f.PkgPath != "" {
+			continue
+		}
+		pof, err := newParamObjectField(i, f, c)
+		if err != nil {
+			return po, newErrInvalidInput(
+				fmt.Sprintf("bad field %q of %v", f.Name, t), err)
+		}
+		po.Fields = append(po.Fields, pof)
+	}
+	return po, nil
+}
+
+// errPopulate is returned when a struct could not be populated.
+type errPopulate struct {
+	Func   *digreflect.Func
+	Target reflect.Type
+	Reason error
+}
+
+var _ digError = errPopulate{}
+
+func (e errPopulate) Error() string { return fmt.Sprint(e) }
+
+func (e errPopulate) Unwrap() error { return e.Reason }
+
+func (e errPopulate) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "cannot populate %v at "+verb, e.Target, e.Func)
+}
+
+func (e errPopulate) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
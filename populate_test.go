@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopulate(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ name string }
+	type B struct{}
+
+	t.Run("fills tagged fields", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{"default"} })
+		c.RequireProvide(func() *A { return &A{"primary"} }, dig.Name("primary"))
+		c.RequireProvide(func() int { return 1 }, dig.Group("ints"))
+		c.RequireProvide(func() int { return 2 }, dig.Group("ints"))
+
+		var target struct {
+			A       *A
+			Primary *A    `name:"primary"`
+			Ints    []int `group:"ints"`
+			B       *B    `optional:"true"`
+
+			untouched string
+		}
+		target.untouched = "hello"
+		require.NoError(t, c.Populate(&target))
+
+		assert.Equal(t, "default", target.A.name)
+		assert.Equal(t, "primary", target.Primary.name)
+		assert.ElementsMatch(t, []int{1, 2}, target.Ints)
+		assert.Nil(t, target.B)
+		assert.Equal(t, "hello", target.untouched)
+	})
+
+	t.Run("from a scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		child := c.Scope("child")
+		child.RequireProvide(func(*A) *B { return &B{} })
+
+		var target struct {
+			A *A
+			B *B
+		}
+		require.NoError(t, child.Populate(&target))
+		assert.NotNil(t, target.A)
+		assert.NotNil(t, target.B)
+	})
+
+	t.Run("invalid targets", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var s struct{}
+		for _, target := range []interface{}{nil, s, (*struct{})(nil), new(int)} {
+			err := c.Populate(target)
+			require.Error(t, err, "%#v", target)
+			assert.Contains(t, err.Error(), "must be a non-nil pointer to a struct")
+		}
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var target struct{ A *A }
+		err := c.Populate(&target)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot populate *struct { A *dig_test.A } at")
+		assert.Contains(t, err.Error(), "populate_test.go")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("constructor failure leaves target untouched", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+		c.RequireProvide(func() (*B, error) { return nil, errors.New("great sadness") })
+
+		var target struct {
+			A *A
+			B *B
+		}
+		err := c.Populate(&target)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Nil(t, target.A)
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/pprof"
+	"strings"
+)
+
+// Labels set on the goroutines calling constructors with WithPprofLabels.
+const (
+	_pprofConstructorLabel = "dig.constructor"
+	_pprofTypeLabel        = "dig.type"
+)
+
+// WithPprofLabels is an Option that calls constructors with pprof labels
+// identifying them, so that CPU profiles taken while the container builds
+// its values attribute the time spent to the right constructors.
+//
+//	c := dig.New(dig.WithPprofLabels())
+//
+// The "dig.constructor" label holds the name of the constructor, and the
+// "dig.type" label the types it provides, separated by commas. Goroutines
+// started by constructors inherit the labels. The labels of the context of
+// InvokeCtx are kept.
+func WithPprofLabels() Option {
+	return pprofLabelsOption{}
+}
+
+type pprofLabelsOption struct{}
+
+func (pprofLabelsOption) String() string {
+	return "WithPprofLabels()"
+}
+
+func (pprofLabelsOption) applyOption(c *Container) {
+	c.scope.state.pprofLabels = true
+}
+
+// pprofInvoker returns an invokerFn that calls functions with invoke with
+// the pprof labels of this constructor added to ctx.
+func (n *constructorNode) pprofInvoker(ctx context.Context, invoke invokerFn) invokerFn {
+	outputs := newOutputs(n.resultList.DotResult())
+	types := make([]string, len(outputs))
+	for i, o := range outputs {
+		types[i] = o.String()
+	}
+	labels := pprof.Labels(
+		_pprofConstructorLabel, fmt.Sprintf("%v.%v", n.location.Package, n.location.Name),
+		_pprofTypeLabel, strings.Join(types, ","),
+	)
+
+	return func(fn reflect.Value, args []reflect.Value) (results []reflect.Value) {
+		pprof.Do(ctx, labels, func(context.Context) {
+			results = invoke(fn, args)
+		})
+		return results
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPprofLabels(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	// goroutines returns the goroutine profile, which lists the labels of
+	// each goroutine.
+	goroutines := func(t *testing.T) string {
+		var b bytes.Buffer
+		require.NoError(t, pprof.Lookup("goroutine").WriteTo(&b, 1))
+		return b.String()
+	}
+
+	t.Run("labels", func(t *testing.T) {
+		t.Parallel()
+
+		var profile string
+		c := digtest.New(t, dig.WithPprofLabels())
+		c.RequireProvide(func() (*A, *B) {
+			profile = goroutines(t)
+			return &A{}, &B{}
+		}, dig.Name("first"))
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			A *A `name:"first"`
+		}) {
+		})
+
+		assert.Contains(t, profile, `"dig.constructor":"github.com/alexisvisco/dig_test.TestWithPprofLabels.func2.1"`)
+		assert.Contains(t, profile, `"dig.type":"*dig_test.A[name = \"first\"],*dig_test.B[name = \"first\"]"`)
+	})
+
+	t.Run("context labels are kept", func(t *testing.T) {
+		t.Parallel()
+
+		var profile string
+		c := digtest.New(t, dig.WithPprofLabels(), dig.ConstructorTimeout(time.Minute))
+		c.RequireProvide(func() *A {
+			profile = goroutines(t)
+			return &A{}
+		})
+
+		ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "startup"))
+		require.NoError(t, c.InvokeCtx(ctx, func(*A) {}))
+		assert.Contains(t, profile, `"request":"startup"`)
+		assert.Contains(t, profile, `"dig.type":"*dig_test.A"`)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		var profile string
+		c := digtest.New(t)
+		c.RequireProvide(func() *B {
+			profile = goroutines(t)
+			return &B{}
+		})
+		c.RequireInvoke(func(*B) {})
+		assert.NotContains(t, profile, `"dig.constructor":"github.com/alexisvisco/dig_test.TestWithPprofLabels.func4.1"`)
+	})
+
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "WithPprofLabels()", dig.WithPprofLabels().(interface{ String() string }).String())
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"github.com/alexisvisco/dig/internal/digreflect"
+	"github.com/alexisvisco/dig/internal/graph"
+)
+
+// PreparedInvoke is a function whose dependencies were checked by
+// PrepareInvoke, ready to be invoked repeatedly.
+type PreparedInvoke struct {
+	s        *Scope
+	function interface{}
+}
+
+// PrepareInvoke checks that the given function may be invoked, to invoke it
+// repeatedly later on, such as on every request of a server, and returns
+// it as a PreparedInvoke.
+//
+//	handle, err := c.PrepareInvoke(func(db *sql.DB, log *zap.Logger) error {
+//		...
+//	})
+//	...
+//	err = handle.Invoke()
+//
+// PrepareInvoke parses the parameters of the function, and checks that all
+// its dependencies are provided and that the graph is acyclic, so wiring
+// errors surface before the function is first invoked. Later Invokes of a
+// function of the same type, prepared or not, reuse the parsed parameters.
+//
+// The dependencies are still resolved by every Invoke of the
+// PreparedInvoke, as with Scope.Invoke, so that it sees the constructors
+// and decorators provided after PrepareInvoke.
+func (c *Container) PrepareInvoke(function interface{}) (PreparedInvoke, error) {
+	return c.scope.PrepareInvoke(function)
+}
+
+// PrepareInvoke checks that the given function may be invoked from the
+// Scope, to invoke it repeatedly later on. See Container.PrepareInvoke for
+// details.
+func (s *Scope) PrepareInvoke(function interface{}) (PreparedInvoke, error) {
+	if err := s.checkOpen(); err != nil {
+		return PreparedInvoke{}, err
+	}
+
+	fn, location, err := unannotate(function)
+	if err != nil {
+		return PreparedInvoke{}, err
+	}
+	ftype, err := invokeType(fn)
+	if err != nil {
+		return PreparedInvoke{}, err
+	}
+
+	err = s.parallelism().do(func() error {
+		pl, err := s.invokeParamList(ftype)
+		if err != nil {
+			return err
+		}
+
+		if err := shallowCheckDependencies(s, pl); err != nil {
+			if location == nil {
+				location = digreflect.InspectFunc(fn)
+			}
+			return errMissingDependencies{
+				Func:   location,
+				Reason: err,
+			}
+		}
+
+		if !s.isVerifiedAcyclic {
+			if ok, cycle := graph.IsAcyclic(s.gh); !ok {
+				return newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(cycle))
+			}
+			s.isVerifiedAcyclic = true
+		}
+		return nil
+	})
+	if err != nil {
+		return PreparedInvoke{}, err
+	}
+	return PreparedInvoke{s: s, function: function}, nil
+}
+
+// Invoke invokes the prepared function with the given options, as
+// Scope.Invoke would.
+func (p PreparedInvoke) Invoke(opts ...InvokeOption) error {
+	if p.s == nil {
+		return newErrInvalidInput("can't invoke a PreparedInvoke not returned by PrepareInvoke", nil)
+	}
+	return p.s.Invoke(p.function, opts...)
+}
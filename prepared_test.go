@@ -0,0 +1,207 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareInvoke(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Requests int }
+	type Handler struct{ Name string }
+
+	type params struct {
+		dig.In
+
+		Config   *Config
+		Handlers []Handler `group:"handlers"`
+	}
+
+	t.Run("invokes repeatedly", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func() Handler { return Handler{Name: "a"} }, dig.Group("handlers"))
+
+		var handlers int
+		p, err := c.PrepareInvoke(func(p params) {
+			p.Config.Requests++
+			handlers = len(p.Handlers)
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, p.Invoke())
+		}
+		c.RequireInvoke(func(cfg *Config) {
+			assert.Equal(t, 3, cfg.Requests)
+		})
+		assert.Equal(t, 1, handlers)
+	})
+
+	t.Run("sees constructors provided later", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+
+		var names []string
+		p, err := c.PrepareInvoke(func(p params) {
+			names = names[:0]
+			for _, h := range p.Handlers {
+				names = append(names, h.Name)
+			}
+		})
+		require.NoError(t, err)
+		require.NoError(t, p.Invoke())
+		assert.Empty(t, names)
+
+		// A function of the same type reuses the parsed parameters.
+		c.RequireProvide(func() Handler { return Handler{Name: "a"} }, dig.Group("handlers"))
+		require.NoError(t, c.Invoke(func(p params) {
+			assert.Len(t, p.Handlers, 1)
+		}))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		p, err := c.PrepareInvoke(func(cfg *Config) error {
+			assert.Equal(t, 42, cfg.Requests)
+			return nil
+		})
+		require.Error(t, err, "no *Config provided")
+
+		c.RequireProvide(func() *Config { return &Config{Requests: 1} })
+		p, err = c.PrepareInvoke(func(cfg *Config) error {
+			assert.Equal(t, 42, cfg.Requests)
+			return nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, p.Invoke(dig.With(&Config{Requests: 42})))
+	})
+
+	t.Run("missing dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		_, err := c.PrepareInvoke(func(*Config) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing dependencies for function")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.Config")
+	})
+
+	t.Run("annotated", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Requests: 7} }, dig.Name("main"))
+
+		p, err := c.PrepareInvoke(dig.Annotate(func(cfg *Config) {
+			assert.Equal(t, 7, cfg.Requests)
+		}, dig.ParamTags(`name:"main"`)))
+		require.NoError(t, err)
+		require.NoError(t, p.Invoke())
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() *Config { return &Config{} })
+
+		_, err := c.PrepareInvoke(func(*Config) {})
+		require.Error(t, err)
+
+		p, err := child.PrepareInvoke(func(*Config) {})
+		require.NoError(t, err)
+		require.NoError(t, p.Invoke())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		_, err := c.PrepareInvoke(42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't invoke non-function 42 (type int)")
+
+		_, err = c.PrepareInvoke(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't invoke an untyped nil")
+
+		var p dig.PreparedInvoke
+		err = p.Invoke()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't invoke a PreparedInvoke not returned by PrepareInvoke")
+	})
+}
+
+func BenchmarkInvoke(b *testing.B) {
+	type Config struct{}
+	type Handler struct{}
+
+	type params struct {
+		dig.In
+
+		Config   *Config
+		Handlers []Handler `group:"handlers"`
+	}
+
+	c := dig.New()
+	if err := c.Provide(func() *Config { return &Config{} }); err != nil {
+		b.Fatal(err)
+	}
+	if err := c.Provide(func() Handler { return Handler{} }, dig.Group("handlers")); err != nil {
+		b.Fatal(err)
+	}
+	fn := func(params) {}
+
+	b.Run("Invoke", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := c.Invoke(fn); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("PreparedInvoke", func(b *testing.B) {
+		p, err := c.PrepareInvoke(fn)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for i := 0; i < b.N; i++ {
+			if err := p.Invoke(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ActiveProfiles is an Option that activates the given profiles in the
+// container, selecting the constructors provided with Profile.
+//
+//	c := dig.New(dig.ActiveProfiles("prod", "metrics"))
+//
+// Profiles apply to the container and all its Scopes. No profile is active
+// by default.
+func ActiveProfiles(profiles ...string) Option {
+	return activeProfilesOption(profiles)
+}
+
+type activeProfilesOption []string
+
+func (o activeProfilesOption) String() string {
+	return fmt.Sprintf("ActiveProfiles(%q)", []string(o))
+}
+
+func (o activeProfilesOption) applyOption(c *Container) {
+	if c.scope.state.profiles == nil {
+		c.scope.state.profiles = make(map[string]struct{}, len(o))
+	}
+	for _, p := range o {
+		c.scope.state.profiles[p] = struct{}{}
+	}
+}
+
+// Profile is a ProvideOption that provides the constructor only if at least
+// one of the given profiles is active in the container, as set with
+// ActiveProfiles. Otherwise, Provide ignores the constructor and returns
+// nil. This lets alternative implementations of the same types coexist in
+// a single composition root.
+//
+//	c := dig.New(dig.ActiveProfiles("prod"))
+//	c.Provide(NewSMTPMailer, dig.Profile("prod", "staging"))
+//	c.Provide(NewFakeMailer, dig.Profile("dev", "test"))
+//
+// A profile prefixed with "!" is active when the profile is not, so that
+// dig.Profile("!prod") provides a constructor everywhere but in production.
+// If Profile is given multiple times, the constructor is provided only if
+// each of them has an active profile.
+func Profile(profiles ...string) ProvideOption {
+	return provideProfileOption(profiles)
+}
+
+type provideProfileOption []string
+
+func (o provideProfileOption) String() string {
+	return fmt.Sprintf("Profile(%q)", []string(o))
+}
+
+func (o provideProfileOption) applyProvideOption(opts *provideOptions) {
+	opts.Profiles = append(opts.Profiles, append([]string(nil), o...))
+}
+
+// validateProfiles checks the profiles given with each Profile option.
+func validateProfiles(profiles [][]string) error {
+	for _, ps := range profiles {
+		if len(ps) == 0 {
+			return newErrInvalidInput("invalid dig.Profile(): at least one profile is required", nil)
+		}
+		for _, p := range ps {
+			if strings.TrimPrefix(p, "!") == "" {
+				return newErrInvalidInput(
+					fmt.Sprintf("invalid dig.Profile(%q): profiles must not be empty", ps), nil)
+			}
+		}
+	}
+	return nil
+}
+
+// profilesActive reports whether each of the Profile options given to a
+// constructor has an active profile.
+func (s *Scope) profilesActive(profiles [][]string) bool {
+	active := s.rootScope().state.profiles
+	for _, ps := range profiles {
+		ok := false
+		for _, p := range ps {
+			name := strings.TrimPrefix(p, "!")
+			_, isActive := active[name]
+			if isActive != (name != p) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+
+	type Mailer struct{ Name string }
+
+	provideMailers := func(c *digtest.Container) {
+		c.RequireProvide(func() *Mailer { return &Mailer{Name: "smtp"} }, dig.Profile("prod", "staging"))
+		c.RequireProvide(func() *Mailer { return &Mailer{Name: "fake"} }, dig.Profile("!prod"), dig.Profile("!staging"), dig.Profile("dev"))
+	}
+
+	tests := []struct {
+		desc     string
+		profiles []string
+		want     string // empty if no Mailer is provided
+	}{
+		{desc: "prod", profiles: []string{"prod"}, want: "smtp"},
+		{desc: "staging", profiles: []string{"metrics", "staging"}, want: "smtp"},
+		{desc: "dev", profiles: []string{"dev"}, want: "fake"},
+		{desc: "no active profile", profiles: nil},
+		{desc: "prod and dev", profiles: []string{"prod", "dev"}, want: "smtp"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := digtest.New(t, dig.ActiveProfiles(tt.profiles...))
+			provideMailers(c)
+
+			err := c.Invoke(func(m *Mailer) {
+				assert.Equal(t, tt.want, m.Name)
+			})
+			if tt.want == "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "missing type: *dig_test.Mailer")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ActiveProfiles("test"))
+		child := c.Scope("child")
+		child.RequireProvide(func() *Mailer { return &Mailer{Name: "fake"} }, dig.Profile("test"))
+		child.RequireProvide(func() string { return "prod" }, dig.Profile("prod"))
+		child.RequireInvoke(func(m *Mailer) {
+			assert.Equal(t, "fake", m.Name)
+		})
+		assert.Error(t, child.Invoke(func(string) {}), "string must not be provided")
+	})
+
+	t.Run("invalid profiles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() *Mailer { return nil }, dig.Profile())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.Profile(): at least one profile is required")
+
+		err = c.Provide(func() *Mailer { return nil }, dig.Profile("prod", "!"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid dig.Profile(["prod" "!"]): profiles must not be empty`)
+	})
+
+	t.Run("clones keep their profiles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ActiveProfiles("prod"))
+		cl := c.Clone()
+		require.NoError(t, cl.Provide(func() *Mailer { return &Mailer{Name: "smtp"} }, dig.Profile("prod")))
+		require.NoError(t, cl.Invoke(func(m *Mailer) {
+			assert.Equal(t, "smtp", m.Name)
+		}))
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `Profile(["prod" "!dev"])`, fmt.Sprint(dig.Profile("prod", "!dev")))
+		assert.Equal(t, `ActiveProfiles(["prod"])`, fmt.Sprint(dig.ActiveProfiles("prod")))
+	})
+}
@@ -22,9 +22,12 @@ package dig
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/alexisvisco/dig/internal/digreflect"
 	"github.com/alexisvisco/dig/internal/dot"
@@ -37,21 +40,141 @@ type ProvideOption interface {
 }
 
 type provideOptions struct {
-	Name     string
-	Group    string
-	Info     *ProvideInfo
-	As       []interface{}
-	Location *digreflect.Func
-	Exported bool
-	Callback Callback
+	Name      string
+	Group     string
+	Info      *ProvideInfo
+	As        []interface{}
+	Location  *digreflect.Func
+	Exported  bool
+	Callback  Callback
+	CacheHit  Callback
+	Tags      map[string]string
+	Hooks     []lifecycleHook
+	Lifecycle bool
+	Override  bool
+	Replace   bool
+	IfMissing bool
+	Eager     bool
+	Transient bool
+	Factory   bool
+
+	// Key set with Keyed, whose name replaces Name.
+	Key *provideKeyOption
+
+	// Labels given with Labels, from which the name of the values is
+	// derived.
+	Labels []string
+
+	// Timeout of the constructor set by ConstructorTimeout, replacing
+	// that of the Container.
+	Timeout *time.Duration
+
+	// How the constructor is called again when it fails, set by Retry.
+	Retry retryPolicy
+
+	// Health checks registered with WithHealthCheck.
+	HealthChecks []func(context.Context) error
+
+	// Predicates that must all hold for the constructor to be provided,
+	// set by When and WhenEnv.
+	Conditions []func() bool
+
+	// Profiles given with each Profile option, one of which must be active
+	// for the constructor to be provided.
+	Profiles [][]string
+
+	// Values of the optional dependencies that are not provided, set with
+	// Default.
+	Defaults []interface{}
+
+	// Arguments bound to parameters of the constructor by ProvidePartial.
+	BoundArgs []interface{}
+
+	// Interfaces among which those implemented by the results are
+	// provided as well, set by AsImplementedInterfaces.
+	Implemented []interface{}
+
+	// Names and groups of the results of specific types, set by NameFor
+	// and GroupFor.
+	ResultTags []resultTag
+
+	// Supplied is set if the constructor was built by Supply to return a
+	// value as-is.
+	Supplied *suppliedValues
 }
 
 func (o *provideOptions) Validate() error {
-	if len(o.Group) > 0 {
-		if len(o.Name) > 0 {
+	group := o.Group
+	for _, rt := range o.ResultTags {
+		if group == "" {
+			group = rt.Group
+		}
+	}
+	if len(group) > 0 {
+		if o.Override {
+			return newErrInvalidInput(
+				fmt.Sprintf("cannot use Override with value groups: group:%q", group), nil)
+		}
+		if o.Transient {
 			return newErrInvalidInput(
-				fmt.Sprintf("cannot use named values with value groups: name:%q provided with group:%q", o.Name,
-					o.Group), nil)
+				fmt.Sprintf("cannot use Transient with value groups: group:%q", group), nil)
+		}
+		if o.IfMissing {
+			return newErrInvalidInput(
+				fmt.Sprintf("cannot use IfMissing with value groups: group:%q", group), nil)
+		}
+	}
+	if o.Key != nil {
+		if o.Key.err != nil {
+			return o.Key.err
+		}
+		if o.Name != "" {
+			return newErrInvalidInput("cannot use Keyed with Name", nil)
+		}
+	}
+	if len(o.Labels) > 0 {
+		if _, err := parseLabels(o.Labels); err != nil {
+			return err
+		}
+		switch {
+		case o.Name != "" || o.Key != nil:
+			return newErrInvalidInput("cannot use Labels with Name or Keyed", nil)
+		case len(group) > 0:
+			return newErrInvalidInput(
+				fmt.Sprintf("cannot use Labels with value groups: group:%q", group), nil)
+		}
+	}
+	if o.Retry.Attempts < 0 {
+		return newErrInvalidInput(
+			fmt.Sprintf("cannot use Retry with %d attempts", o.Retry.Attempts), nil)
+	}
+	if o.IfMissing && o.Override {
+		return newErrInvalidInput("cannot use IfMissing with Override", nil)
+	}
+	if o.Transient {
+		switch {
+		case o.Eager:
+			return newErrInvalidInput("cannot use Transient with Eager", nil)
+		case o.Lifecycle || len(o.Hooks) > 0:
+			return newErrInvalidInput("cannot use Transient with lifecycle hooks", nil)
+		case len(o.HealthChecks) > 0:
+			return newErrInvalidInput("cannot use Transient with health checks", nil)
+		}
+	}
+	if o.Factory {
+		switch {
+		case o.Name != "" || o.Key != nil || len(o.Labels) > 0 || len(group) > 0:
+			return newErrInvalidInput("cannot use Factory with named values or value groups", nil)
+		case len(o.As) > 0 || len(o.Implemented) > 0 || len(o.ResultTags) > 0:
+			return newErrInvalidInput("cannot use Factory with As, AsImplementedInterfaces, NameFor or GroupFor", nil)
+		case o.Eager || o.Transient:
+			return newErrInvalidInput("cannot use Factory with Eager or Transient", nil)
+		case o.Override || o.Replace || o.IfMissing:
+			return newErrInvalidInput("cannot use Factory with Override, Replace or IfMissing", nil)
+		case o.Lifecycle || len(o.Hooks) > 0 || len(o.HealthChecks) > 0:
+			return newErrInvalidInput("cannot use Factory with lifecycle hooks or health checks", nil)
+		case o.Retry.Attempts > 0 || o.Timeout != nil || len(o.Defaults) > 0:
+			return newErrInvalidInput("cannot use Factory with Retry, ConstructorTimeout or Default", nil)
 		}
 	}
 
@@ -67,23 +190,78 @@ func (o *provideOptions) Validate() error {
 		return newErrInvalidInput(
 			fmt.Sprintf("invalid dig.Group(%q): group names cannot contain backquotes", o.Group), nil)
 	}
+	for _, rt := range o.ResultTags {
+		if rt.Type == nil {
+			return newErrInvalidInput(
+				fmt.Sprintf("invalid %v: argument must be a pointer to a type", rt), nil)
+		}
+		if strings.ContainsRune(rt.Name, '`') || strings.ContainsRune(rt.Group, '`') {
+			return newErrInvalidInput(
+				fmt.Sprintf("invalid %v: names cannot contain backquotes", rt), nil)
+		}
+	}
+
+	for _, cond := range o.Conditions {
+		if cond == nil {
+			return newErrInvalidInput("invalid dig.When(nil): predicate must not be nil", nil)
+		}
+	}
+	if err := validateProfiles(o.Profiles); err != nil {
+		return err
+	}
 
-	for _, i := range o.As {
+	if err := validateInterfacePointers("As", o.As); err != nil {
+		return err
+	}
+	return validateInterfacePointers("AsImplementedInterfaces", o.Implemented)
+}
+
+// conditionsHold reports whether all the predicates given with When and
+// WhenEnv hold.
+func (o *provideOptions) conditionsHold() bool {
+	for _, cond := range o.Conditions {
+		if !cond() {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveProvideOptions completes validated options given to Provide or
+// Supply: values provided with Keyed or Labels are named after their key or
+// labels. It reports whether the values are to be provided at all, given
+// the conditions and profiles of the options.
+func (s *Scope) resolveProvideOptions(options *provideOptions) bool {
+	if options.Key != nil {
+		options.Name = options.Key.name
+	}
+	if len(options.Labels) > 0 {
+		labels, _ := parseLabels(options.Labels)
+		options.Name = labelsName(labels)
+	}
+	return options.conditionsHold() && s.profilesActive(options.Profiles)
+}
+
+// validateInterfacePointers checks that the arguments given to the option
+// with the given name are all pointers to interfaces.
+func validateInterfacePointers(option string, ifaces []interface{}) error {
+	for _, i := range ifaces {
 		t := reflect.TypeOf(i)
 
 		if t == nil {
-			return newErrInvalidInput("invalid dig.As(nil): argument must be a pointer to an interface", nil)
+			return newErrInvalidInput(fmt.Sprintf(
+				"invalid dig.%v(nil): argument must be a pointer to an interface", option), nil)
 		}
 
 		if t.Kind() != reflect.Ptr {
 			return newErrInvalidInput(
-				fmt.Sprintf("invalid dig.As(%v): argument must be a pointer to an interface", t), nil)
+				fmt.Sprintf("invalid dig.%v(%v): argument must be a pointer to an interface", option, t), nil)
 		}
 
 		pointingTo := t.Elem()
 		if pointingTo.Kind() != reflect.Interface {
 			return newErrInvalidInput(
-				fmt.Sprintf("invalid dig.As(*%v): argument must be a pointer to an interface", pointingTo), nil)
+				fmt.Sprintf("invalid dig.%v(*%v): argument must be a pointer to an interface", option, pointingTo), nil)
 		}
 	}
 	return nil
@@ -120,6 +298,74 @@ func (o provideNameOption) applyProvideOption(opt *provideOptions) {
 	opt.Name = string(o)
 }
 
+// NameFor is a ProvideOption that specifies a name for the value of the type
+// pointed to by t produced by a constructor, leaving its other results
+// unnamed.
+//
+//	func NewConnections(...) (*ReadOnlyConn, *ReadWriteConn, error)
+//
+//	c.Provide(NewConnections, dig.NameFor(new(*ReadOnlyConn), "replica"))
+//
+// NameFor takes precedence over Name for the given type, and Provide fails
+// if the constructor does not produce a value of that type.
+func NameFor(t interface{}, name string) ProvideOption {
+	return provideResultTagOption(newResultTag("NameFor", t, name, ""))
+}
+
+// GroupFor is a ProvideOption that adds the value of the type pointed to by
+// t produced by a constructor to the given value group, leaving its other
+// results out of the group.
+//
+//	c.Provide(NewServer, dig.GroupFor(new(Route), "routes"))
+//
+// GroupFor takes precedence over Group for the given type, and Provide fails
+// if the constructor does not produce a value of that type. See also the
+// package documentation about Value Groups.
+func GroupFor(t interface{}, group string) ProvideOption {
+	return provideResultTagOption(newResultTag("GroupFor", t, "", group))
+}
+
+// resultTag is the name or group of the results of a type, given with
+// NameFor or GroupFor.
+type resultTag struct {
+	option      string
+	arg         interface{}
+	Type        reflect.Type // nil if the option was not given a pointer
+	Name, Group string
+}
+
+func newResultTag(option string, t interface{}, name, group string) resultTag {
+	rt := resultTag{option: option, arg: t, Name: name, Group: group}
+	if pt := reflect.TypeOf(t); pt != nil && pt.Kind() == reflect.Ptr {
+		rt.Type = pt.Elem()
+	}
+	return rt
+}
+
+func (rt resultTag) String() string {
+	value := rt.Name
+	if rt.option == "GroupFor" {
+		value = rt.Group
+	}
+	switch {
+	case rt.arg == nil:
+		return fmt.Sprintf("dig.%v(nil, %q)", rt.option, value)
+	case rt.Type == nil:
+		return fmt.Sprintf("dig.%v(%v, %q)", rt.option, reflect.TypeOf(rt.arg), value)
+	}
+	return fmt.Sprintf("dig.%v(new(%v), %q)", rt.option, rt.Type, value)
+}
+
+type provideResultTagOption resultTag
+
+func (o provideResultTagOption) String() string {
+	return strings.TrimPrefix(resultTag(o).String(), "dig.")
+}
+
+func (o provideResultTagOption) applyProvideOption(opt *provideOptions) {
+	opt.ResultTags = append(opt.ResultTags, resultTag(o))
+}
+
 // Group is a ProvideOption that specifies that all values produced by a
 // constructor should be added to the specified group. See also the package
 // documentation about Value Groups.
@@ -179,6 +425,20 @@ func (i *Input) String() string {
 	return fmt.Sprintf("%v[%v]", t, strings.Join(toks, ", "))
 }
 
+// Key returns the key of the value consumed by the input. For value groups,
+// the type of the key is the type of the values of the group.
+func (i *Input) Key() Key {
+	if i.group != "" {
+		return Key{Type: i.t.Elem(), Group: i.group}
+	}
+	return Key{Type: i.t, Name: i.name}
+}
+
+// Optional reports whether the input may be absent from the container.
+func (i *Input) Optional() bool {
+	return i.optional
+}
+
 // Output contains information on an output produced by a function.
 type Output struct {
 	t           reflect.Type
@@ -201,6 +461,38 @@ func (o *Output) String() string {
 	return fmt.Sprintf("%v[%v]", t, strings.Join(toks, ", "))
 }
 
+// Key returns the key of the value produced by the output.
+func (o *Output) Key() Key {
+	return Key{Type: o.t, Name: o.name, Group: o.group}
+}
+
+// newInputs builds the Inputs describing the given parameters.
+func newInputs(params []*dot.Param) []*Input {
+	inputs := make([]*Input, len(params))
+	for i, param := range params {
+		inputs[i] = &Input{
+			t:        param.Type,
+			optional: param.Optional,
+			name:     param.Name,
+			group:    param.Group,
+		}
+	}
+	return inputs
+}
+
+// newOutputs builds the Outputs describing the given results.
+func newOutputs(results []*dot.Result) []*Output {
+	outputs := make([]*Output, len(results))
+	for i, res := range results {
+		outputs[i] = &Output{
+			t:     res.Type,
+			name:  res.Name,
+			group: res.Group,
+		}
+	}
+	return outputs
+}
+
 // FillProvideInfo is a ProvideOption that writes info on what Dig was able to get
 // out of the provided constructor into the provided ProvideInfo.
 func FillProvideInfo(info *ProvideInfo) ProvideOption {
@@ -257,6 +549,13 @@ func (o fillProvideInfoOption) applyProvideOption(opts *provideOptions) {
 //	  }
 //	})
 //
+// As may also narrow an interface produced by the constructor to a
+// smaller interface, and applies to each element of slices produced by the
+// constructor. The following makes []io.Reader available in the container,
+// holding the same buffers as the slice produced by the constructor.
+//
+//	c.Provide(func(...) []*bytes.Buffer, dig.As(new(io.Reader)))
+//
 // This option cannot be provided for constructors which produce result
 // objects.
 func As(i ...interface{}) ProvideOption {
@@ -281,6 +580,47 @@ func (o provideAsOption) applyProvideOption(opts *provideOptions) {
 	opts.As = append(opts.As, o...)
 }
 
+// AsImplementedInterfaces is a ProvideOption that specifies that the values
+// produced by the constructor are also provided to the container as each of
+// the given interfaces they implement.
+//
+// AsImplementedInterfaces expects pointers to the interfaces that may be
+// bound. Unlike As, the values remain available as their own type, and
+// interfaces that a value does not implement are skipped instead of failing.
+//
+// For example, given a *bytes.Buffer constructor, the following makes
+// io.Reader, io.Writer, and *bytes.Buffer available in the container, but not
+// io.Closer.
+//
+//	c.Provide(newBuffer, dig.AsImplementedInterfaces(
+//	  new(io.Reader), new(io.Writer), new(io.Closer),
+//	))
+//
+// This allows a single list of the interfaces of an application to be given
+// to all its constructors. If used with dig.As, the values are provided as
+// the interfaces they implement in addition to those given to As.
+func AsImplementedInterfaces(i ...interface{}) ProvideOption {
+	return provideImplementedOption(i)
+}
+
+type provideImplementedOption []interface{}
+
+func (o provideImplementedOption) String() string {
+	buf := bytes.NewBufferString("AsImplementedInterfaces(")
+	for i, iface := range o {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(reflect.TypeOf(iface).Elem().String())
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func (o provideImplementedOption) applyProvideOption(opts *provideOptions) {
+	opts.Implemented = append(opts.Implemented, o...)
+}
+
 // LocationForPC is a ProvideOption which specifies an alternate function program
 // counter address to be used for debug information. The package, name, file and
 // line number of this alternate function address will be used in error messages
@@ -334,6 +674,151 @@ func (o provideExportOption) applyProvideOption(opts *provideOptions) {
 	opts.Exported = o.exported
 }
 
+// providesKey reports whether n is one of the constructors the Scope uses
+// for the given key.
+func (s *Scope) providesKey(n *constructorNode, k key) bool {
+	for _, p := range s.providers[k] {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Override is a ProvideOption that allows the constructor to replace the
+// constructors previously provided to the same Container or Scope for the
+// same types and names.
+//
+//	c.Provide(newRealClock)
+//	c.Provide(newFakeClock, dig.Override())
+//
+// Without this option, providing a type that was already provided fails.
+// Override only replaces the values the constructor produces: other values
+// produced by a replaced constructor are still provided by it. Values
+// contributed to value groups are never replaced.
+//
+// A value cannot be overridden once it was built. Use Container.Replace to
+// also drop the values built from the replaced constructors.
+func Override() ProvideOption {
+	return provideOverrideOption{}
+}
+
+type provideOverrideOption struct{}
+
+func (provideOverrideOption) String() string {
+	return "Override()"
+}
+
+func (provideOverrideOption) applyProvideOption(opts *provideOptions) {
+	opts.Override = true
+}
+
+// IfMissing is a ProvideOption that makes the constructor a fallback: it
+// provides its values only if no other constructor provides them.
+//
+//	c.Provide(newDefaultLogger, dig.IfMissing())
+//
+// If one of the values of the constructor was already provided to the same
+// Container or Scope, Provide ignores the constructor and returns nil.
+// Otherwise, constructors provided later for the same types and names
+// replace the fallback constructor, as with Override, instead of failing.
+// This allows libraries to provide sensible defaults that applications
+// replace by providing their own constructors.
+//
+// IfMissing cannot be used with value groups, as they accept any number of
+// constructors, nor with Override.
+func IfMissing() ProvideOption {
+	return provideIfMissingOption{}
+}
+
+type provideIfMissingOption struct{}
+
+func (provideIfMissingOption) String() string {
+	return "IfMissing()"
+}
+
+func (provideIfMissingOption) applyProvideOption(opts *provideOptions) {
+	opts.IfMissing = true
+}
+
+// Transient is a ProvideOption that makes the constructor transient: it is
+// called again each time one of the values it produces is needed, instead
+// of once per container.
+//
+//	c.Provide(func() *bytes.Buffer { return new(bytes.Buffer) }, dig.Transient())
+//
+// Each parameter resolved from a transient constructor, including multiple
+// parameters of the same function, receives a fresh value. The dependencies
+// of a transient constructor are resolved as usual, and are only built once
+// unless they are transient too.
+//
+// Transient constructors cannot provide values to value groups, nor be
+// combined with Eager or lifecycle hooks. Decorated values are built once
+// per Scope, even if the value they decorate is transient.
+//
+// The cleanup function returned by a transient constructor, if any, is
+// kept by the Scope until it is closed, along with those returned by the
+// previous calls of the constructor: transient constructors returning
+// cleanup functions are meant for Scopes that are closed after a short
+// while, such as the Scopes of requests.
+func Transient() ProvideOption {
+	return provideTransientOption{}
+}
+
+type provideTransientOption struct{}
+
+func (provideTransientOption) String() string {
+	return "Transient()"
+}
+
+func (provideTransientOption) applyProvideOption(opts *provideOptions) {
+	opts.Transient = true
+}
+
+// When is a ProvideOption that provides the constructor only if the given
+// predicate holds. The predicate is called once, when the constructor is
+// given to Provide; when it returns false, Provide ignores the constructor
+// and returns nil.
+//
+//	c.Provide(newFakeMailer, dig.When(func() bool { return cfg.DryRun }))
+//
+// If When is given multiple times, the constructor is provided only if all
+// the predicates hold.
+func When(predicate func() bool) ProvideOption {
+	return provideWhenOption{predicate}
+}
+
+type provideWhenOption struct{ predicate func() bool }
+
+func (o provideWhenOption) String() string {
+	return fmt.Sprintf("When(%p)", o.predicate)
+}
+
+func (o provideWhenOption) applyProvideOption(opts *provideOptions) {
+	opts.Conditions = append(opts.Conditions, o.predicate)
+}
+
+// WhenEnv is a ProvideOption that provides the constructor only if the
+// environment variable key is set to value when the constructor is given to
+// Provide. See When for more details.
+//
+//	c.Provide(newNewCheckout, dig.WhenEnv("FEATURE_NEW_CHECKOUT", "true"))
+func WhenEnv(key, value string) ProvideOption {
+	return provideWhenEnvOption{key: key, value: value}
+}
+
+type provideWhenEnvOption struct{ key, value string }
+
+func (o provideWhenEnvOption) String() string {
+	return fmt.Sprintf("WhenEnv(%q, %q)", o.key, o.value)
+}
+
+func (o provideWhenEnvOption) applyProvideOption(opts *provideOptions) {
+	opts.Conditions = append(opts.Conditions, func() bool {
+		return os.Getenv(o.key) == o.value
+	})
+}
+
 // provider encapsulates a user-provided constructor.
 type provider interface {
 	// ID is a unique numerical identifier for this provider.
@@ -404,13 +889,24 @@ func (c *Container) Provide(constructor interface{}, opts ...ProvideOption) erro
 // To provide a constructor to all the Scopes available, provide it to
 // Container, which is the root Scope.
 func (s *Scope) Provide(constructor interface{}, opts ...ProvideOption) error {
-	ctype := reflect.TypeOf(constructor)
-	if ctype == nil {
-		return newErrInvalidInput("can't provide an untyped nil", nil)
+	if err := s.checkOpen(); err != nil {
+		return err
 	}
-	if ctype.Kind() != reflect.Func {
-		return newErrInvalidInput(
-			fmt.Sprintf("must provide constructor function, got %v (type %v)", constructor, ctype), nil)
+
+	constructor, location, err := unannotate(constructor)
+	if err != nil {
+		if location != nil {
+			return errProvide{Func: location, Reason: err}
+		}
+		return err
+	}
+	if location != nil {
+		// Explicit locations take precedence.
+		opts = append([]ProvideOption{provideLocationOption{loc: location}}, opts...)
+	}
+
+	if err := validateConstructor(constructor); err != nil {
+		return err
 	}
 
 	var options provideOptions
@@ -420,8 +916,14 @@ func (s *Scope) Provide(constructor interface{}, opts ...ProvideOption) error {
 	if err := options.Validate(); err != nil {
 		return err
 	}
+	if !s.resolveProvideOptions(&options) {
+		return nil
+	}
 
-	if err := s.provide(constructor, options); err != nil {
+	err = s.parallelism().do(func() error {
+		return s.provide(constructor, options)
+	})
+	if err != nil {
 		var errFunc *digreflect.Func
 		if options.Location == nil {
 			errFunc = digreflect.InspectFunc(constructor)
@@ -437,6 +939,20 @@ func (s *Scope) Provide(constructor interface{}, opts ...ProvideOption) error {
 	return nil
 }
 
+// validateConstructor returns an error if the given value cannot be
+// provided as a constructor.
+func validateConstructor(ctor interface{}) error {
+	ctype := reflect.TypeOf(ctor)
+	if ctype == nil {
+		return newErrInvalidInput("can't provide an untyped nil", nil)
+	}
+	if ctype.Kind() != reflect.Func {
+		return newErrInvalidInput(
+			fmt.Sprintf("must provide constructor function, got %v (type %v)", ctor, ctype), nil)
+	}
+	return nil
+}
+
 func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 	// If Export option is provided to the constructor, this should be injected to the
 	// root-level Scope (Container) to allow it to propagate to all other Scopes.
@@ -445,6 +961,17 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 		s = s.rootScope()
 	}
 
+	if opts.Factory {
+		return s.provideFactory(ctor, opts)
+	}
+
+	if opts.IfMissing {
+		missing, err := s.providesMissing(ctor, opts)
+		if err != nil || !missing {
+			return err
+		}
+	}
+
 	// For all scopes affected by this change,
 	// take a snapshot of the current graph state before
 	// we start making changes to it as we may need to
@@ -460,6 +987,11 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 		}()
 	}
 
+	timeout := s.constructorTimeout
+	if opts.Timeout != nil {
+		timeout = *opts.Timeout
+	}
+
 	n, err := newConstructorNode(
 		ctor,
 		s,
@@ -467,16 +999,31 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 		constructorOptions{
 			ResultName:  opts.Name,
 			ResultGroup: opts.Group,
+			ResultTags:  opts.ResultTags,
 			ResultAs:    opts.As,
+			Implemented: opts.Implemented,
 			Location:    opts.Location,
 			Callback:    opts.Callback,
+			CacheHit:    opts.CacheHit,
+			Tags:        opts.Tags,
+			Hooks:       opts.Hooks,
+			Lifecycle:   opts.Lifecycle,
+			Supplied:    opts.Supplied,
+			Eager:       opts.Eager,
+			Transient:   opts.Transient,
+			Fallback:    opts.IfMissing,
+			Timeout:     timeout,
+			Retry:       opts.Retry,
+			Checks:      opts.HealthChecks,
+			Defaults:    opts.Defaults,
+			BoundArgs:   opts.BoundArgs,
 		},
 	)
 	if err != nil {
 		return err
 	}
 
-	keys, err := s.findAndValidateResults(n.ResultList())
+	keys, err := s.findAndValidateResults(n.ResultList(), opts.Override)
 	if err != nil {
 		return err
 	}
@@ -487,10 +1034,37 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 			fmt.Sprintf("%v must provide at least one non-error type", ctype), nil)
 	}
 
+	if opts.Transient {
+		for k := range keys {
+			if k.group != "" {
+				return newErrInvalidInput(fmt.Sprintf(
+					"transient constructors cannot provide value groups: %v provides group:%q", ctype, k.group), nil)
+			}
+		}
+	}
+
+	replaced := make(map[key]struct{})
+	for k := range keys {
+		if k.group != "" {
+			continue
+		}
+		if opts.Override || onlyFallbacks(s.providers[k]) {
+			if _, ok := s.values[k]; ok && !opts.Replace {
+				return newErrInvalidInput(
+					fmt.Sprintf("cannot override %v: the value was already built", k), nil)
+			}
+			replaced[k] = struct{}{}
+		}
+	}
+
 	oldProviders := make(map[key][]*constructorNode)
 	for k := range keys {
 		// Cache old providers before running cycle detection.
 		oldProviders[k] = s.providers[k]
+		if _, ok := replaced[k]; ok {
+			s.providers[k] = []*constructorNode{n}
+			continue
+		}
 		s.providers[k] = append(s.providers[k], n)
 	}
 
@@ -512,7 +1086,20 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 		s.isVerifiedAcyclic = true
 	}
 
+	if len(replaced) > 0 {
+		s.removeUnusedNodes(oldProviders)
+	}
 	s.nodes = append(s.nodes, n)
+	if opts.Replace {
+		s.invalidate(replaced)
+	}
+
+	if m := s.rootScope().state.metrics; m != nil {
+		m.ProviderRegistered(ProvideEvent{
+			Provider:  newProviderInfo(n),
+			ScopePath: s.path(),
+		})
+	}
 
 	// Record introspection info for caller if Info option is specified
 	if info := opts.Info; info != nil {
@@ -520,37 +1107,90 @@ func (s *Scope) provide(ctor interface{}, opts provideOptions) (err error) {
 		results := n.ResultList().DotResult()
 
 		info.ID = (ID)(n.id)
-		info.Inputs = make([]*Input, len(params))
-		info.Outputs = make([]*Output, len(results))
-
-		for i, param := range params {
-			info.Inputs[i] = &Input{
-				t:        param.Type,
-				optional: param.Optional,
-				name:     param.Name,
-				group:    param.Group,
-			}
+		info.Inputs = newInputs(params)
+		info.Outputs = newOutputs(results)
+	}
+	return nil
+}
+
+// providesMissing reports whether none of the values that the constructor
+// would provide was already provided to the Scope.
+func (s *Scope) providesMissing(ctor interface{}, opts provideOptions) (bool, error) {
+	rl, err := newResultList(reflect.TypeOf(ctor), resultOptions{
+		Name:        opts.Name,
+		Tags:        opts.ResultTags,
+		As:          opts.As,
+		Implemented: opts.Implemented,
+	})
+	if err != nil {
+		return false, err
+	}
+	keys, err := s.findAndValidateResults(rl, true /* override */)
+	if err != nil {
+		return false, err
+	}
+	for k := range keys {
+		if k.group == "" && len(s.providers[k]) > 0 {
+			return false, nil
 		}
+	}
+	return true, nil
+}
 
-		for i, res := range results {
-			info.Outputs[i] = &Output{
-				t:     res.Type,
-				name:  res.Name,
-				group: res.Group,
+// onlyFallbacks reports whether all the given constructors were provided
+// with IfMissing.
+func onlyFallbacks(providers []*constructorNode) bool {
+	for _, p := range providers {
+		if !p.fallback {
+			return false
+		}
+	}
+	return len(providers) > 0
+}
+
+// removeUnusedNodes removes the constructors among the given providers that
+// no longer provide any value to the Scope.
+func (s *Scope) removeUnusedNodes(providers map[key][]*constructorNode) {
+	used := make(map[*constructorNode]struct{})
+	for _, ps := range s.providers {
+		for _, p := range ps {
+			used[p] = struct{}{}
+		}
+	}
+
+	unused := make(map[*constructorNode]struct{})
+	for _, ps := range providers {
+		for _, p := range ps {
+			if _, ok := used[p]; !ok {
+				unused[p] = struct{}{}
 			}
 		}
 	}
-	return nil
+	if len(unused) == 0 {
+		return
+	}
+
+	nodes := s.nodes[:0]
+	for _, n := range s.nodes {
+		if _, ok := unused[n]; !ok {
+			nodes = append(nodes, n)
+		}
+	}
+	s.nodes = nodes
 }
 
 // Builds a collection of all result types produced by this constructor.
-func (s *Scope) findAndValidateResults(rl resultList) (map[key]struct{}, error) {
+//
+// If override is true, the results may replace values already provided to
+// the Scope.
+func (s *Scope) findAndValidateResults(rl resultList, override bool) (map[key]struct{}, error) {
 	var err error
 	keyPaths := make(map[key]string)
 	walkResult(rl, connectionVisitor{
 		s:        s,
 		err:      &err,
 		keyPaths: keyPaths,
+		override: override,
 	})
 
 	if err != nil {
@@ -582,6 +1222,10 @@ type connectionVisitor struct {
 	// constructor.
 	keyPaths map[key]string
 
+	// Whether the results may replace values already provided to the
+	// Scope.
+	override bool
+
 	// We track the path to the current result here. For example, this will
 	// be, ["[1]", "Foo", "Bar"] when we're visiting Bar in,
 	//
@@ -653,7 +1297,7 @@ func (cv connectionVisitor) checkKey(k key, path string) error {
 		return newErrInvalidInput(fmt.Sprintf("cannot provide %v from %v", k, path),
 			newErrInvalidInput(fmt.Sprintf("already provided by %v", conflict), nil))
 	}
-	if ps := cv.s.providers[k]; len(ps) > 0 {
+	if ps := cv.s.providers[k]; len(ps) > 0 && !cv.override && !onlyFallbacks(ps) {
 		cons := make([]string, len(ps))
 		for i, p := range ps {
 			cons[i] = fmt.Sprint(p.Location())
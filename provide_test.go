@@ -21,6 +21,7 @@
 package dig
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"reflect"
@@ -52,6 +53,16 @@ func TestProvideOptionStrings(t *testing.T) {
 			give: As(new(io.Reader), new(io.Writer)),
 			want: `As(io.Reader, io.Writer)`,
 		},
+		{
+			desc: "AsImplementedInterfaces",
+			give: AsImplementedInterfaces(new(io.Reader), new(io.Closer)),
+			want: `AsImplementedInterfaces(io.Reader, io.Closer)`,
+		},
+		{
+			desc: "WithTags",
+			give: WithTags(map[string]string{"layer": "infra", "owner": "core"}),
+			want: `WithTags("layer": "infra", "owner": "core")`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -87,6 +98,29 @@ func TestLocationForPCString(t *testing.T) {
 	assert.Contains(t, fmt.Sprint(opt), `LocationForPC("github.com/alexisvisco/dig".TestLocationForPCString.func1 `)
 }
 
+func TestOverrideString(t *testing.T) {
+	assert.Equal(t, "Override()", fmt.Sprint(Override()))
+}
+
+func TestTransientString(t *testing.T) {
+	assert.Equal(t, "Transient()", fmt.Sprint(Transient()))
+}
+
+func TestIfMissingString(t *testing.T) {
+	assert.Equal(t, "IfMissing()", fmt.Sprint(IfMissing()))
+}
+
+func TestWhenString(t *testing.T) {
+	assert.Contains(t, fmt.Sprint(When(func() bool { return true })), "When(0x")
+	assert.Equal(t, `WhenEnv("FEATURE_X", "true")`, fmt.Sprint(WhenEnv("FEATURE_X", "true")))
+}
+
+func TestNameForGroupForString(t *testing.T) {
+	assert.Equal(t, `NameFor(new(string), "foo")`, fmt.Sprint(NameFor(new(string), "foo")))
+	assert.Equal(t, `GroupFor(new(*bytes.Buffer), "bar")`, fmt.Sprint(GroupFor(new(*bytes.Buffer), "bar")))
+	assert.Equal(t, `NameFor(nil, "foo")`, fmt.Sprint(NameFor(nil, "foo")))
+}
+
 func TestExportString(t *testing.T) {
 	assert.Equal(t, fmt.Sprint(Export(true)), "Export(true)")
 	assert.Equal(t, fmt.Sprint(Export(false)), "Export(false)")
@@ -0,0 +1,167 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// AssertPath verifies that the constructors of the type pointed to by from
+// depend, directly or transitively, on values of the type pointed to by to,
+// without calling any constructor.
+//
+//	if err := dig.AssertPath(c, new(*Handler), new(*Service)); err != nil {
+//	  t.Fatal(err)
+//	}
+//
+// The constructors of the Container and of all its Scopes are considered,
+// and values are matched by type only, whatever their names or value
+// groups. It is an error for no constructor to provide from.
+func AssertPath(c *Container, from, to interface{}) error {
+	path, err := dependencyPath(c, from, to)
+	if err != nil || path.Path != nil {
+		return err
+	}
+	return path
+}
+
+// AssertNoPath verifies that the constructors of the type pointed to by
+// from do not depend, directly or transitively, on values of the type
+// pointed to by to, without calling any constructor. This allows enforcing
+// layering rules in tests, for example that handlers do not depend on
+// repositories:
+//
+//	if err := dig.AssertNoPath(c, new(*Handler), new(*Repository)); err != nil {
+//	  t.Fatal(err)
+//	}
+//
+// The returned error names one of the shortest chains of constructors
+// leading from from to to. Values are matched as with AssertPath.
+func AssertNoPath(c *Container, from, to interface{}) error {
+	path, err := dependencyPath(c, from, to)
+	if err != nil || path.Path == nil {
+		return err
+	}
+	return path
+}
+
+// errDependencyPath reports whether the values of type From depend on
+// values of type To. Path is the chain of constructors leading from a
+// constructor of From to one consuming To, or nil if there is none.
+type errDependencyPath struct {
+	From, To reflect.Type
+	Path     []ProviderInfo
+}
+
+var _ digError = errDependencyPath{}
+
+func (e errDependencyPath) Error() string { return fmt.Sprint(e) }
+
+func (e errDependencyPath) writeMessage(w io.Writer, _ string) {
+	if e.Path == nil {
+		fmt.Fprintf(w, "%v does not depend on %v", e.From, e.To)
+		return
+	}
+	names := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		names[i] = p.Name
+	}
+	fmt.Fprintf(w, "%v depends on %v through %v -> %v",
+		e.From, e.To, strings.Join(names, " -> "), e.To)
+}
+
+func (e errDependencyPath) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
+
+// dependencyPath searches the constructors of the container for the
+// shortest chain leading from a constructor of the type pointed to by from
+// to a constructor consuming the type pointed to by to.
+func dependencyPath(c *Container, from, to interface{}) (errDependencyPath, error) {
+	var (
+		path errDependencyPath
+		err  error
+	)
+	if path.From, err = pathType("from", from); err != nil {
+		return path, err
+	}
+	if path.To, err = pathType("to", to); err != nil {
+		return path, err
+	}
+
+	g := newProviderGraph(c)
+
+	// Breadth-first search from the constructors of From, recording the
+	// constructor each one was reached from, or -1.
+	prev := make(map[int]int)
+	var queue []int
+	for i, p := range g.providers {
+		for _, o := range p.Outputs {
+			if o.Key().Type == path.From {
+				prev[i] = -1
+				queue = append(queue, i)
+				break
+			}
+		}
+	}
+	if len(queue) == 0 {
+		return path, newErrInvalidInput(
+			fmt.Sprintf("no constructor provides %v", path.From), nil)
+	}
+
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, k := range g.inputs(i) {
+			if k.Type != path.To {
+				continue
+			}
+			for ; i >= 0; i = prev[i] {
+				path.Path = append(path.Path, g.providers[i])
+			}
+			for l, r := 0, len(path.Path)-1; l < r; l, r = l+1, r-1 {
+				path.Path[l], path.Path[r] = path.Path[r], path.Path[l]
+			}
+			return path, nil
+		}
+		for _, j := range g.deps(i) {
+			if _, ok := prev[j]; !ok {
+				prev[j] = i
+				queue = append(queue, j)
+			}
+		}
+	}
+	return path, nil
+}
+
+// pathType returns the type pointed to by the argument arg of AssertPath
+// or AssertNoPath.
+func pathType(arg string, v interface{}) (reflect.Type, error) {
+	pt := reflect.TypeOf(v)
+	if pt == nil || pt.Kind() != reflect.Ptr {
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"%v must be a pointer to a type, got %v (type %v)", arg, v, pt), nil)
+	}
+	return pt.Elem(), nil
+}
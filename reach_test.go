@@ -0,0 +1,134 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	layerRepo    struct{}
+	layerCache   struct{}
+	layerService struct{}
+	layerHandler struct{}
+	layerAudit   struct{}
+)
+
+func newLayerRepo() *layerRepo                    { return &layerRepo{} }
+func newLayerService(*layerRepo) *layerService    { return &layerService{} }
+func newLayerHandler(*layerService) *layerHandler { return &layerHandler{} }
+
+func TestAssertPath(t *testing.T) {
+	t.Parallel()
+
+	newContainer := func(t *testing.T) *digtest.Container {
+		c := digtest.New(t)
+		c.RequireProvide(newLayerRepo)
+		c.RequireProvide(func() *layerCache {
+			t.Fatal("constructors must not be called")
+			return nil
+		})
+		c.RequireProvide(newLayerService)
+		c.RequireProvide(newLayerHandler)
+		return c
+	}
+
+	t.Run("transitive dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		assert.NoError(t, dig.AssertPath(c.Container, new(*layerHandler), new(*layerRepo)))
+		assert.NoError(t, dig.AssertPath(c.Container, new(*layerHandler), new(*layerService)))
+
+		err := dig.AssertNoPath(c.Container, new(*layerHandler), new(*layerRepo))
+		require.Error(t, err)
+		assert.Equal(t, "*dig_test.layerHandler depends on *dig_test.layerRepo through "+
+			"github.com/alexisvisco/dig_test.newLayerHandler -> "+
+			"github.com/alexisvisco/dig_test.newLayerService -> *dig_test.layerRepo", err.Error())
+
+		var de dig.Error
+		assert.True(t, errors.As(err, &de), "expected a dig.Error")
+	})
+
+	t.Run("no dependency", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		assert.NoError(t, dig.AssertNoPath(c.Container, new(*layerHandler), new(*layerCache)))
+		assert.NoError(t, dig.AssertNoPath(c.Container, new(*layerRepo), new(*layerHandler)))
+
+		err := dig.AssertPath(c.Container, new(*layerRepo), new(*layerHandler))
+		require.Error(t, err)
+		assert.Equal(t, "*dig_test.layerRepo does not depend on *dig_test.layerHandler", err.Error())
+	})
+
+	t.Run("names, groups and scopes", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Repo   *layerRepo    `name:"primary"`
+			Audits []*layerAudit `group:"audits"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(newLayerRepo, dig.Name("primary"))
+		c.RequireProvide(func() *layerAudit { return &layerAudit{} }, dig.Group("audits"))
+		c.RequireProvide(func(params) *layerService { return &layerService{} })
+		c.Scope("child").RequireProvide(newLayerHandler)
+
+		assert.NoError(t, dig.AssertPath(c.Container, new(*layerHandler), new(*layerRepo)))
+		assert.NoError(t, dig.AssertPath(c.Container, new(*layerHandler), new(*layerAudit)))
+	})
+
+	t.Run("unknown from", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		for _, assertion := range []func(*dig.Container, interface{}, interface{}) error{
+			dig.AssertPath, dig.AssertNoPath,
+		} {
+			err := assertion(c.Container, new(*layerAudit), new(*layerRepo))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "no constructor provides *dig_test.layerAudit")
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		c := newContainer(t)
+		err := dig.AssertPath(c.Container, layerHandler{}, new(*layerRepo))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "from must be a pointer to a type")
+
+		err = dig.AssertNoPath(c.Container, new(*layerHandler), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "to must be a pointer to a type")
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "reflect"
+
+// Replace provides a constructor to the Container that replaces the
+// constructors previously provided for the same types and names, as with
+// Override, even if their values were already built.
+//
+// Values already built by the replaced constructors are dropped, along
+// with every value built from them, directly or transitively, in the
+// Container and all its Scopes. They are built again, from the new
+// constructor, the next time they are needed. This allows long-running
+// processes to swap a dependency, e.g. after a configuration reload.
+//
+//	c.Replace(func() *Config { return reloaded })
+//
+// Values already held by the application are not affected. Cleanup
+// functions and lifecycle hooks registered by the constructors of the
+// dropped values are kept, and these constructors register them again when
+// they are called again.
+//
+// See Scope.Replace to replace constructors of a Scope.
+func (c *Container) Replace(constructor interface{}, opts ...ProvideOption) error {
+	return c.scope.Replace(constructor, opts...)
+}
+
+// Replace provides a constructor to the Scope that replaces the
+// constructors previously provided to the Scope for the same types and
+// names. Values built from the replaced constructors are dropped from the
+// Scope and its descendants. See Container.Replace for details.
+func (s *Scope) Replace(constructor interface{}, opts ...ProvideOption) error {
+	opts = append(opts[:len(opts):len(opts)], Override(), provideReplaceOption{})
+	return s.Provide(constructor, opts...)
+}
+
+type provideReplaceOption struct{}
+
+func (provideReplaceOption) String() string {
+	return "Replace()"
+}
+
+func (provideReplaceOption) applyProvideOption(opts *provideOptions) {
+	opts.Replace = true
+}
+
+// invalidate drops the values of the given keys held by the Scope and its
+// descendants, along with the values built from them, directly or
+// transitively, so that they are built again when next needed.
+func (s *Scope) invalidate(keys map[key]struct{}) {
+	stale := make(map[key]struct{}, len(keys))
+	for k := range keys {
+		stale[k] = struct{}{}
+	}
+
+	// Constructors and decorators that consumed or produced a stale value
+	// must be called again, which makes all their values stale, until
+	// there is nothing left to invalidate.
+	scopes := s.appendSubscopes(nil)
+	for changed := true; changed; {
+		changed = false
+		for _, sc := range scopes {
+			for _, n := range sc.nodes {
+				if !n.called {
+					continue
+				}
+				outputs := resultKeys(n.resultList)
+				if !touchesKeys(stale, paramKeys(n.paramList), outputs) {
+					continue
+				}
+				n.called = false
+				addKeys(stale, outputs)
+				changed = true
+			}
+			for _, d := range sc.decoratorNodes {
+				if d.state != decoratorCalled {
+					continue
+				}
+				outputs, _ := findResultKeys(d.results)
+				if !touchesKeys(stale, paramKeys(d.params), outputs) {
+					continue
+				}
+				d.state = decoratorReady
+				addKeys(stale, outputs)
+				changed = true
+			}
+		}
+	}
+
+	for _, sc := range scopes {
+		for k := range stale {
+			if k.group == "" {
+				delete(sc.values, k)
+				delete(sc.decoratedValues, k)
+				continue
+			}
+			delete(sc.groups, k)
+			delete(sc.namedGroups, k)
+			delete(sc.decoratedGroups, key{group: k.group, t: reflect.SliceOf(k.t)})
+		}
+	}
+}
+
+// paramKeys returns the keys of the values consumed by the given
+// parameters.
+func paramKeys(pl paramList) []key {
+	var keys []key
+	for _, i := range newInputs(pl.DotParam()) {
+		keys = append(keys, internalKey(i.Key()))
+	}
+	return keys
+}
+
+// resultKeys returns the keys of the values produced by the given results.
+func resultKeys(rl resultList) []key {
+	var keys []key
+	for _, o := range newOutputs(rl.DotResult()) {
+		keys = append(keys, internalKey(o.Key()))
+	}
+	return keys
+}
+
+func internalKey(k Key) key {
+	if k.Group != "" {
+		// Values of a group are all stored together, whatever their names.
+		return key{t: k.Type, group: k.Group}
+	}
+	return key{t: k.Type, name: k.Name}
+}
+
+// touchesKeys reports whether any of the given key lists has a key in set.
+func touchesKeys(set map[key]struct{}, lists ...[]key) bool {
+	for _, keys := range lists {
+		for _, k := range keys {
+			if _, ok := set[k]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func addKeys(set map[key]struct{}, keys []key) {
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+}
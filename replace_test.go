@@ -0,0 +1,148 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Addr string }
+	type Client struct{ Addr string }
+	type Server struct{ Client *Client }
+	type Clock struct{}
+
+	t.Run("rebuilds dependent values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Addr: "old"} })
+		c.RequireProvide(func(cfg *Config) *Client { return &Client{Addr: cfg.Addr} })
+		c.RequireProvide(func(cl *Client) *Server { return &Server{Client: cl} })
+
+		clocks := 0
+		c.RequireProvide(func() *Clock {
+			clocks++
+			return &Clock{}
+		})
+
+		var before *Server
+		c.RequireInvoke(func(s *Server, _ *Clock) { before = s })
+		assert.Equal(t, "old", before.Client.Addr)
+
+		require.NoError(t, c.Replace(func() *Config { return &Config{Addr: "new"} }))
+
+		c.RequireInvoke(func(s *Server, _ *Clock) {
+			assert.NotSame(t, before, s)
+			assert.Equal(t, "new", s.Client.Addr)
+		})
+		assert.Equal(t, 1, clocks, "values that don't depend on the replaced one are kept")
+	})
+
+	t.Run("rebuilds value groups", func(t *testing.T) {
+		t.Parallel()
+
+		type Param struct {
+			dig.In
+
+			Addrs []string `group:"addrs"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Addr: "old"} })
+		c.RequireProvide(func(cfg *Config) string { return cfg.Addr }, dig.Group("addrs"))
+		c.RequireProvide(func() string { return "static" }, dig.Group("addrs"))
+		c.RequireProvide(func(p Param) []string { return p.Addrs })
+
+		c.RequireInvoke(func(addrs []string) {
+			assert.ElementsMatch(t, []string{"old", "static"}, addrs)
+		})
+
+		require.NoError(t, c.Replace(func() *Config { return &Config{Addr: "new"} }))
+
+		c.RequireInvoke(func(addrs []string) {
+			assert.ElementsMatch(t, []string{"new", "static"}, addrs)
+		})
+	})
+
+	t.Run("drops values of child scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Addr: "old"} })
+
+		child := c.Scope("child")
+		child.RequireProvide(func(cfg *Config) *Client { return &Client{Addr: cfg.Addr} })
+		child.RequireDecorate(func(cfg *Config) *Config { return &Config{Addr: cfg.Addr + "'"} })
+
+		child.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, "old'", cl.Addr)
+		})
+
+		require.NoError(t, c.Replace(func() *Config { return &Config{Addr: "new"} }))
+
+		child.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, "new'", cl.Addr)
+		})
+	})
+
+	t.Run("replace a value that was not built", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Addr: "old"} })
+		require.NoError(t, c.Replace(func() *Config { return &Config{Addr: "new"} }))
+
+		c.RequireInvoke(func(cfg *Config) {
+			assert.Equal(t, "new", cfg.Addr)
+		})
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+
+		err := c.Replace(func() *Config { return &Config{} }, dig.Group("configs"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use Override with value groups")
+	})
+
+	t.Run("override still fails once built", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireInvoke(func(*Config) {})
+
+		err := c.Provide(func() *Config { return &Config{} }, dig.Override())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the value was already built")
+	})
+}
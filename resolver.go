@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"reflect"
+)
+
+var (
+	_scopeType    = reflect.TypeOf((*Scope)(nil))
+	_resolverType = reflect.TypeOf((*Resolver)(nil)).Elem()
+)
+
+// A Resolver builds values from the Container or Scope it was obtained from.
+// It is implemented by Container and Scope.
+//
+// Constructors, decorators and invoked functions may take a Resolver, or a
+// *Scope, as a parameter to receive the Scope they are called in, unless
+// one was explicitly provided to the container. This allows factories to
+// build further values lazily without holding a reference to a global
+// container.
+//
+//	type HandlerFactory struct{ r dig.Resolver }
+//
+//	c.Provide(func(r dig.Resolver) *HandlerFactory {
+//	  return &HandlerFactory{r: r}
+//	})
+//
+//	func (f *HandlerFactory) Handler(name string) (h Handler, err error) {
+//	  err = f.r.Invoke(func(p HandlerParams) { h = p.Handlers[name] })
+//	  return h, err
+//	}
+//
+// The Resolver must not be used from within the constructor that received
+// it to build the values of that constructor, which would never complete.
+// Prefer Resolver to *Scope: it does not allow providing new constructors
+// to the Scope.
+type Resolver interface {
+	Invoke(function interface{}, opts ...InvokeOption) error
+	InvokeCtx(ctx context.Context, function interface{}, opts ...InvokeOption) error
+}
+
+var (
+	_ Resolver = (*Container)(nil)
+	_ Resolver = (*Scope)(nil)
+)
+
+// isScope reports whether ps may be satisfied with the Scope it is being
+// built in.
+func (ps paramSingle) isScope() bool {
+	return (ps.Type == _scopeType || ps.Type == _resolverType) && ps.Name == ""
+}
+
+// isImplicit reports whether ps may be satisfied without a provider.
+func (ps paramSingle) isImplicit() bool {
+	return ps.isContext() || ps.isScope()
+}
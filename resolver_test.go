@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverInjection(t *testing.T) {
+	t.Parallel()
+
+	type Handler struct{ Name string }
+	type Factory struct{ r dig.Resolver }
+
+	t.Run("constructors receive a Resolver", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(r dig.Resolver) *Factory { return &Factory{r: r} })
+		c.RequireProvide(func() *Handler { return &Handler{Name: "root"} })
+
+		var f *Factory
+		c.RequireInvoke(func(got *Factory) { f = got })
+		require.NoError(t, f.r.Invoke(func(h *Handler) {
+			assert.Equal(t, "root", h.Name)
+		}))
+	})
+
+	t.Run("constructors receive the scope they are built in", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func(s *dig.Scope) *Factory { return &Factory{r: s} })
+		child.RequireProvide(func() *Handler { return &Handler{Name: "child"} })
+
+		child.RequireInvoke(func(f *Factory, s *dig.Scope) {
+			assert.Equal(t, "child", s.Name())
+			assert.Same(t, s, f.r)
+			require.NoError(t, f.r.Invoke(func(h *Handler) {
+				assert.Equal(t, "child", h.Name)
+			}))
+		})
+	})
+
+	t.Run("dig.In fields", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Resolver dig.Resolver
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Handler { return &Handler{Name: "root"} })
+		c.RequireInvoke(func(p params) {
+			require.NotNil(t, p.Resolver)
+			require.NoError(t, p.Resolver.Invoke(func(h *Handler) {
+				assert.Equal(t, "root", h.Name)
+			}))
+		})
+	})
+
+	t.Run("used from within a constructor", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe())
+		c.RequireProvide(func() *Handler { return &Handler{Name: "root"} })
+		c.RequireProvide(func(r dig.Resolver) (f *Factory, err error) {
+			err = r.Invoke(func(*Handler) { f = &Factory{r: r} })
+			return f, err
+		})
+		c.RequireInvoke(func(f *Factory) {
+			assert.NotNil(t, f)
+		})
+	})
+
+	t.Run("provided Resolver takes precedence", func(t *testing.T) {
+		t.Parallel()
+
+		other := digtest.New(t)
+		c := digtest.New(t)
+		c.RequireProvide(func() dig.Resolver { return other.Container })
+		c.RequireInvoke(func(r dig.Resolver) {
+			assert.Same(t, other.Container, r)
+		})
+	})
+
+	t.Run("named Resolvers must be provided", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			Resolver dig.Resolver `name:"other"`
+		}
+
+		c := digtest.New(t)
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing type: dig.Resolver[name="other"]`)
+	})
+}
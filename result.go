@@ -23,6 +23,7 @@ package dig
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/alexisvisco/dig/internal/digerror"
 	"github.com/alexisvisco/dig/internal/dot"
@@ -63,6 +64,32 @@ type resultOptions struct {
 	Name  string
 	Group string
 	As    []interface{}
+
+	// Interfaces, given as pointers, under which the results are provided
+	// in addition to their types if they implement them.
+	Implemented []interface{}
+
+	// Names and groups of the results of specific types, overriding Name
+	// and Group. Only used by newResultList.
+	Tags []resultTag
+}
+
+// forType returns the options of the result of the given type.
+func (o resultOptions) forType(t reflect.Type) (resultOptions, bool) {
+	var found bool
+	for _, rt := range o.Tags {
+		if rt.Type != t {
+			continue
+		}
+		found = true
+		if rt.option == "NameFor" {
+			o.Name = rt.Name
+		} else {
+			o.Group = rt.Group
+		}
+	}
+	o.Tags = nil
+	return o, found
 }
 
 // newResult builds a result from the given type.
@@ -73,6 +100,9 @@ func newResult(t reflect.Type, opts resultOptions) (result, error) {
 			"cannot provide parameter objects: %v embeds a dig.In", t), nil)
 	case isError(t):
 		return nil, newErrInvalidInput("cannot return an error here, return it from the constructor instead", nil)
+	case isLazy(t):
+		return nil, newErrInvalidInput(fmt.Sprintf(
+			"cannot provide lazy dependencies: %v is a dig.Lazy", t), nil)
 	case IsOut(t):
 		return newResultObject(t, opts)
 	case embedsType(t, _outPtrType):
@@ -88,29 +118,43 @@ func newResult(t reflect.Type, opts resultOptions) (result, error) {
 			return nil, newErrInvalidInput(
 				fmt.Sprintf("cannot parse group %q", opts.Group), err)
 		}
-		rg := resultGrouped{Type: t, Group: g.Name, Flatten: g.Flatten}
+		rg := resultGrouped{Type: t, Group: g.Name, Name: opts.Name, Flatten: g.Flatten}
+		if g.Flatten && rg.Name != "" {
+			return nil, errFlattenNamedGroup(rg)
+		}
 		if len(opts.As) > 0 {
 			var asTypes []reflect.Type
 			for _, as := range opts.As {
-				ifaceType := reflect.TypeOf(as).Elem()
-				if ifaceType == t {
-					continue
+				asType, err := newAsType(t, as)
+				if err != nil {
+					return nil, err
 				}
-				if !t.Implements(ifaceType) {
-					return nil, newErrInvalidInput(
-						fmt.Sprintf("invalid dig.As: %v does not implement %v", t, ifaceType), nil)
+				if asType == t {
+					continue
 				}
-				asTypes = append(asTypes, ifaceType)
+				asTypes = append(asTypes, asType)
 			}
 			if len(asTypes) > 0 {
 				rg.Type = asTypes[0]
 				rg.As = asTypes[1:]
 			}
 		}
+		if !g.Flatten {
+			exclude := append([]reflect.Type{rg.Type}, rg.As...)
+			rg.As = append(rg.As, implementedInterfaces(t, opts.Implemented, exclude...)...)
+		}
 		if g.Soft {
 			return nil, newErrInvalidInput(fmt.Sprintf(
 				"cannot use soft with result value groups: soft was used with group:%q", g.Name), nil)
 		}
+		if g.Tolerant {
+			return nil, newErrInvalidInput(fmt.Sprintf(
+				"cannot use tolerant with result value groups: tolerant was used with group:%q", g.Name), nil)
+		}
+		if g.Sorted {
+			return nil, newErrInvalidInput(fmt.Sprintf(
+				"cannot use sorted with result value groups: sorted was used with group:%q", g.Name), nil)
+		}
 		if g.Flatten {
 			if t.Kind() != reflect.Slice {
 				return nil, newErrInvalidInput(fmt.Sprintf(
@@ -200,8 +244,13 @@ type resultList struct {
 
 	// For each item at index i returned by the constructor, resultIndexes[i]
 	// is the index in .Results for the corresponding result object.
-	// resultIndexes[i] is -1 for errors returned by constructors.
+	// resultIndexes[i] is -1 for errors and cleanup functions returned by
+	// constructors.
 	resultIndexes []int
+
+	// Index of the cleanup function returned by the constructor, or -1 if
+	// it doesn't return one.
+	cleanupIndex int
 }
 
 func (rl resultList) DotResult() []*dot.Result {
@@ -218,8 +267,10 @@ func newResultList(ctype reflect.Type, opts resultOptions) (resultList, error) {
 		ctype:         ctype,
 		Results:       make([]result, 0, numOut),
 		resultIndexes: make([]int, numOut),
+		cleanupIndex:  -1,
 	}
 
+	tagged := make(map[reflect.Type]struct{}, len(opts.Tags))
 	resultIdx := 0
 	for i := 0; i < numOut; i++ {
 		t := ctype.Out(i)
@@ -227,8 +278,17 @@ func newResultList(ctype reflect.Type, opts resultOptions) (resultList, error) {
 			rl.resultIndexes[i] = -1
 			continue
 		}
+		if isCleanup(ctype, i) {
+			rl.resultIndexes[i] = -1
+			rl.cleanupIndex = i
+			continue
+		}
 
-		r, err := newResult(t, opts)
+		ropts, found := opts.forType(t)
+		if found {
+			tagged[t] = struct{}{}
+		}
+		r, err := newResult(t, ropts)
 		if err != nil {
 			return rl, newErrInvalidInput(fmt.Sprintf("bad result %d", i+1), err)
 		}
@@ -238,9 +298,80 @@ func newResultList(ctype reflect.Type, opts resultOptions) (resultList, error) {
 		resultIdx++
 	}
 
+	for _, rt := range opts.Tags {
+		if _, ok := tagged[rt.Type]; !ok {
+			return rl, newErrInvalidInput(
+				fmt.Sprintf("cannot use %v: %v does not produce a %v", rt, ctype, rt.Type), nil)
+		}
+	}
+
 	return rl, nil
 }
 
+// isCleanup reports whether the result i of the constructor is a cleanup
+// function: a func() that follows at least one other result, and is either
+// the last result or followed by an error.
+func isCleanup(ctype reflect.Type, i int) bool {
+	numOut := ctype.NumOut()
+	if ctype.Out(i) != _cleanupType || i == 0 {
+		return false
+	}
+	return i == numOut-1 || (i == numOut-2 && isError(ctype.Out(numOut-1)))
+}
+
+// cleanup returns the cleanup function among the values returned by the
+// constructor, if any.
+func (rl resultList) cleanup(values []reflect.Value) func() {
+	if rl.cleanupIndex < 0 {
+		return nil
+	}
+	f, _ := values[rl.cleanupIndex].Interface().(func())
+	return f
+}
+
+// eachResultValue calls fn with the key and value of each non-nil value
+// returned by a constructor with the given resultList. The values of
+// flattened value groups are passed one at a time.
+func eachResultValue(rl resultList, values []reflect.Value, fn func(key, reflect.Value)) {
+	for i, v := range values {
+		if idx := rl.resultIndexes[i]; idx >= 0 {
+			eachValueOf(rl.Results[idx], v, fn)
+		}
+	}
+}
+
+func eachValueOf(r result, v reflect.Value, fn func(key, reflect.Value)) {
+	switch r := r.(type) {
+	case resultSingle:
+		callNonNil(key{t: r.Type, name: r.Name}, v, fn)
+	case resultGrouped:
+		k := key{t: r.Type, group: r.Group}
+		if !r.Flatten {
+			callNonNil(k, v, fn)
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			callNonNil(k, v.Index(i), fn)
+		}
+	case resultObject:
+		for _, f := range r.Fields {
+			eachValueOf(f.Result, v.Field(f.FieldIndex), fn)
+		}
+	}
+}
+
+func callNonNil(k key, v reflect.Value, fn func(key, reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+	}
+	fn(k, v)
+}
+
 func (resultList) Extract(containerWriter, bool, reflect.Value) {
 	digerror.BugPanicf("resultList.Extract() must never be called")
 }
@@ -282,31 +413,88 @@ func newResultSingle(t reflect.Type, opts resultOptions) (resultSingle, error) {
 	var asTypes []reflect.Type
 
 	for _, as := range opts.As {
-		ifaceType := reflect.TypeOf(as).Elem()
-		if ifaceType == t {
+		asType, err := newAsType(t, as)
+		if err != nil {
+			return r, err
+		}
+		if asType == t {
 			// Special case:
 			//   c.Provide(func() io.Reader, As(new(io.Reader)))
 			// Ignore instead of erroring out.
 			continue
 		}
-		if !t.Implements(ifaceType) {
-			return r, newErrInvalidInput(
-				fmt.Sprintf("invalid dig.As: %v does not implement %v", t, ifaceType), nil)
-		}
-		asTypes = append(asTypes, ifaceType)
+		asTypes = append(asTypes, asType)
 	}
 
+	implemented := implementedInterfaces(t, opts.Implemented, asTypes...)
 	if len(asTypes) == 0 {
+		r.As = implemented
 		return r, nil
 	}
 
 	return resultSingle{
 		Type: asTypes[0],
 		Name: opts.Name,
-		As:   asTypes[1:],
+		As:   append(asTypes[1:], implemented...),
 	}, nil
 }
 
+// newAsType returns the type under which a value of type t is provided
+// when the pointer to an interface as is given to dig.As: the interface
+// itself if t implements it, or a slice of the interface if t is a slice
+// whose elements implement it.
+func newAsType(t reflect.Type, as interface{}) (reflect.Type, error) {
+	ifaceType := reflect.TypeOf(as).Elem()
+	switch {
+	case t == ifaceType || t.Implements(ifaceType):
+		return ifaceType, nil
+	case t.Kind() == reflect.Slice && t.Elem().Implements(ifaceType):
+		return reflect.SliceOf(ifaceType), nil
+	}
+	return nil, newErrInvalidInput(
+		fmt.Sprintf("invalid dig.As: %v does not implement %v", t, ifaceType), nil)
+}
+
+// asValue returns v as a value of the type t it is provided as. Slices
+// provided as slices of interfaces are copied into a slice of type t.
+func asValue(v reflect.Value, t reflect.Type) reflect.Value {
+	if v.Type() == t || v.Kind() != reflect.Slice || t.Kind() != reflect.Slice {
+		return v
+	}
+	if v.IsNil() {
+		return reflect.Zero(t)
+	}
+	s := reflect.MakeSlice(t, v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		s.Index(i).Set(v.Index(i))
+	}
+	return s
+}
+
+// implementedInterfaces returns the interfaces among ifaces, pointers to
+// interfaces, that t implements, leaving out t itself and the types in
+// exclude.
+func implementedInterfaces(t reflect.Type, ifaces []interface{}, exclude ...reflect.Type) []reflect.Type {
+	var types []reflect.Type
+	for _, i := range ifaces {
+		iface := reflect.TypeOf(i).Elem()
+		if iface == t || !t.Implements(iface) || containsType(exclude, iface) || containsType(types, iface) {
+			continue
+		}
+		types = append(types, iface)
+	}
+	return types
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, other := range types {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
+
 func (rs resultSingle) DotResult() []*dot.Result {
 	dotResults := make([]*dot.Result, 0, len(rs.As)+1)
 	dotResults = append(dotResults, &dot.Result{
@@ -330,10 +518,10 @@ func (rs resultSingle) Extract(cw containerWriter, decorated bool, v reflect.Val
 		cw.setDecoratedValue(rs.Name, rs.Type, v)
 		return
 	}
-	cw.setValue(rs.Name, rs.Type, v)
+	cw.setValue(rs.Name, rs.Type, asValue(v, rs.Type))
 
 	for _, asType := range rs.As {
-		cw.setValue(rs.Name, asType, v)
+		cw.setValue(rs.Name, asType, asValue(v, asType))
 	}
 }
 
@@ -430,6 +618,11 @@ func newResultObjectField(idx int, f reflect.StructField, opts resultOptions) (r
 			return rof, err
 		}
 
+	case f.Tag.Get(_weightTag) != "":
+		return rof, newErrInvalidInput(fmt.Sprintf(
+			"weight can be applied to value groups only: field %q (%v) is not part of a value group", f.Name, f.Type),
+			nil)
+
 	default:
 		var err error
 		if name := f.Tag.Get(_nameTag); len(name) > 0 {
@@ -454,6 +647,11 @@ type resultGrouped struct {
 	// Name of the group as specified in the `group:".."` tag.
 	Group string
 
+	// Name of the value within the group as specified in the `name:".."`
+	// tag, if any. This is the key of the value when the group is consumed
+	// as a map.
+	Name string
+
 	// Type of value produced.
 	Type reflect.Type
 
@@ -462,6 +660,11 @@ type resultGrouped struct {
 	// the type of individual elements rather than the group.
 	Flatten bool
 
+	// Weight of the value as specified in the `weight:".."` tag. Values of
+	// a group consumed with the sorted option are ordered by ascending
+	// weight.
+	Weight int
+
 	// If specified, this is a list of types which the value will be made
 	// available as, in addition to its own type.
 	As []reflect.Type
@@ -472,13 +675,16 @@ func (rt resultGrouped) DotResult() []*dot.Result {
 	dotResults = append(dotResults, &dot.Result{
 		Node: &dot.Node{
 			Type:  rt.Type,
+			Name:  rt.Name,
 			Group: rt.Group,
 		},
+		Flatten: rt.Flatten,
 	})
 
 	for _, asType := range rt.As {
 		dotResults = append(dotResults, &dot.Result{
-			Node: &dot.Node{Type: asType, Group: rt.Group},
+			Node:    &dot.Node{Type: asType, Name: rt.Name, Group: rt.Group},
+			Flatten: rt.Flatten,
 		})
 	}
 	return dotResults
@@ -492,10 +698,10 @@ func newResultGrouped(f reflect.StructField) (resultGrouped, error) {
 	}
 	rg := resultGrouped{
 		Group:   g.Name,
+		Name:    f.Tag.Get(_nameTag),
 		Flatten: g.Flatten,
 		Type:    f.Type,
 	}
-	name := f.Tag.Get(_nameTag)
 	optional, _ := isFieldOptional(f)
 	switch {
 	case g.Flatten && f.Type.Kind() != reflect.Slice:
@@ -504,25 +710,53 @@ func newResultGrouped(f reflect.StructField) (resultGrouped, error) {
 	case g.Soft:
 		return rg, newErrInvalidInput(fmt.Sprintf(
 			"cannot use soft with result value groups: soft was used with group %q", rg.Group), nil)
-	case name != "":
+	case g.Tolerant:
 		return rg, newErrInvalidInput(fmt.Sprintf(
-			"cannot use named values with value groups: name:%q provided with group:%q", name, rg.Group), nil)
+			"cannot use tolerant with result value groups: tolerant was used with group %q", rg.Group), nil)
+	case g.Sorted:
+		return rg, newErrInvalidInput(fmt.Sprintf(
+			"cannot use sorted with result value groups: sorted was used with group %q", rg.Group), nil)
+	case g.Flatten && rg.Name != "":
+		return rg, errFlattenNamedGroup(rg)
 	case optional:
 		return rg, newErrInvalidInput("value groups cannot be optional", nil)
 	}
 	if g.Flatten {
 		rg.Type = f.Type.Elem()
 	}
+	if w := f.Tag.Get(_weightTag); w != "" {
+		weight, err := strconv.Atoi(w)
+		if err != nil {
+			return rg, newErrInvalidInput(fmt.Sprintf(
+				"invalid weight %q for field %q (%v): weight must be an integer", w, f.Name, f.Type), nil)
+		}
+		rg.Weight = weight
+	}
 
 	return rg, nil
 }
 
+// errFlattenNamedGroup is returned when a flattened value group result is
+// given a name: each of its elements would be submitted with the same name.
+func errFlattenNamedGroup(rg resultGrouped) error {
+	return newErrInvalidInput(fmt.Sprintf(
+		"cannot use flatten with named values in value groups: name:%q provided with group:%q", rg.Name, rg.Group), nil)
+}
+
 func (rt resultGrouped) Extract(cw containerWriter, decorated bool, v reflect.Value) {
+	if !decorated && rt.Name != "" {
+		cw.submitNamedGroupedValue(rt.Group, rt.Name, rt.Type, rt.groupValue(asValue(v, rt.Type)))
+		for _, asType := range rt.As {
+			cw.submitNamedGroupedValue(rt.Group, rt.Name, asType, rt.groupValue(asValue(v, asType)))
+		}
+		return
+	}
+
 	// Decorated values are always flattened.
 	if !decorated && !rt.Flatten {
-		cw.submitGroupedValue(rt.Group, rt.Type, v)
+		cw.submitGroupedValue(rt.Group, rt.Type, rt.groupValue(asValue(v, rt.Type)))
 		for _, asType := range rt.As {
-			cw.submitGroupedValue(rt.Group, asType, v)
+			cw.submitGroupedValue(rt.Group, asType, rt.groupValue(asValue(v, asType)))
 		}
 		return
 	}
@@ -532,6 +766,10 @@ func (rt resultGrouped) Extract(cw containerWriter, decorated bool, v reflect.Va
 		return
 	}
 	for i := 0; i < v.Len(); i++ {
-		cw.submitGroupedValue(rt.Group, rt.Type, v.Index(i))
+		cw.submitGroupedValue(rt.Group, rt.Type, rt.groupValue(v.Index(i)))
 	}
 }
+
+func (rt resultGrouped) groupValue(v reflect.Value) groupValue {
+	return groupValue{Value: v, Weight: rt.Weight}
+}
@@ -70,7 +70,7 @@ func TestResultListExtractFails(t *testing.T) {
 	}), resultOptions{})
 	require.NoError(t, err)
 	assert.Panics(t, func() {
-		rl.Extract(newStagingContainerWriter(), false, reflect.ValueOf("irrelevant"))
+		rl.Extract(newStagingContainerWriter(0), false, reflect.ValueOf("irrelevant"))
 	})
 }
 
@@ -230,13 +230,13 @@ func TestNewResultObjectErrors(t *testing.T) {
 			err: `bad field "Nested"`,
 		},
 		{
-			desc: "group with name should fail",
+			desc: "flattened group with name should fail",
 			give: struct {
 				Out
 
-				Foo string `group:"foo" name:"bar"`
+				Foo []string `group:"foo,flatten" name:"bar"`
 			}{},
-			err: "cannot use named values with value groups: " +
+			err: "cannot use flatten with named values in value groups: " +
 				`name:"bar" provided with group:"foo"`,
 		},
 		{
@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retry is a ProvideOption that calls the constructor up to attempts times
+// while it fails, so that constructors connecting to external systems
+// survive transient failures.
+//
+//	c.Provide(connectDB, dig.Retry(5, func(attempt int) time.Duration {
+//		return time.Duration(attempt) * 100 * time.Millisecond
+//	}))
+//
+// After the nth failed attempt, the constructor is called again once
+// backoff(n) has elapsed. A nil backoff retries immediately. Retries stop
+// when the context of the invocation is done, and the error of the last
+// attempt is returned.
+//
+// Every failure of the constructor is retried, including panics and
+// timeouts set with ConstructorTimeout, but failures to build its
+// dependencies are not. A callback registered with WithProviderCallback
+// is called after each attempt, with CallbackInfo.Attempt set accordingly.
+func Retry(attempts int, backoff func(attempt int) time.Duration) ProvideOption {
+	return provideRetryOption{Attempts: attempts, Backoff: backoff}
+}
+
+// retryPolicy is how a constructor is called again when it fails.
+type retryPolicy struct {
+	// Total number of calls. The constructor is only called once if
+	// Attempts is 1 or less.
+	Attempts int
+
+	// Wait after the given failed attempt, if not nil.
+	Backoff func(attempt int) time.Duration
+}
+
+// backoff returns how long to wait after the given failed attempt.
+func (rp retryPolicy) backoff(attempt int) time.Duration {
+	if rp.Backoff == nil {
+		return 0
+	}
+	return rp.Backoff(attempt)
+}
+
+type provideRetryOption retryPolicy
+
+func (o provideRetryOption) String() string {
+	return fmt.Sprintf("Retry(%d, %p)", o.Attempts, o.Backoff)
+}
+
+func (o provideRetryOption) applyProvideOption(opts *provideOptions) {
+	opts.Retry = retryPolicy(o)
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+
+	// flaky returns a constructor that fails the given number of times
+	// before succeeding.
+	flaky := func(failures int, calls *int) func() (*A, error) {
+		return func() (*A, error) {
+			*calls++
+			if *calls <= failures {
+				return nil, fmt.Errorf("attempt %d failed", *calls)
+			}
+			return &A{}, nil
+		}
+	}
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls    int
+			attempts []int
+			waits    []int
+		)
+		c := digtest.New(t)
+		c.RequireProvide(
+			flaky(2, &calls),
+			dig.Retry(3, func(attempt int) time.Duration {
+				waits = append(waits, attempt)
+				return time.Millisecond
+			}),
+			dig.WithProviderCallback(func(ci dig.CallbackInfo) {
+				attempts = append(attempts, ci.Attempt)
+			}),
+		)
+
+		c.RequireInvoke(func(a *A) { assert.NotNil(t, a) })
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []int{1, 2, 3}, attempts)
+		assert.Equal(t, []int{1, 2}, waits)
+	})
+
+	t.Run("error of the last attempt", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls int
+			errs  []error
+		)
+		c := digtest.New(t)
+		c.RequireProvide(
+			flaky(5, &calls),
+			dig.Retry(3, nil),
+			dig.WithProviderCallback(func(ci dig.CallbackInfo) {
+				errs = append(errs, ci.Error)
+			}),
+		)
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "attempt 3 failed")
+		assert.Equal(t, 3, calls)
+		require.Len(t, errs, 3)
+		for _, err := range errs {
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("panics are retried", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			calls++
+			if calls == 1 {
+				panic("great sadness")
+			}
+			return &A{}
+		}, dig.Retry(2, nil))
+
+		c.RequireInvoke(func(*A) {})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("dependency failures are not retried", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		c := digtest.New(t)
+		c.RequireProvide(func() (string, error) {
+			calls++
+			return "", errors.New("great sadness")
+		})
+		c.RequireProvide(func(string) *A { return &A{} }, dig.Retry(3, nil))
+
+		require.Error(t, c.Invoke(func(*A) {}))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("retries stop when the context is done", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		ctx, cancel := context.WithCancel(context.Background())
+		c := digtest.New(t)
+		c.RequireProvide(flaky(5, &calls), dig.Retry(5, func(int) time.Duration {
+			cancel()
+			return time.Minute
+		}))
+
+		err := c.InvokeCtx(ctx, func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "attempt 1 failed")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("negative attempts", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(func() *A { return &A{} }, dig.Retry(-1, nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use Retry with -1 attempts")
+	})
+}
+
+func TestRetryString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Retry(3, 0x0)", fmt.Sprint(dig.Retry(3, nil)))
+}
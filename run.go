@@ -0,0 +1,157 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// registeredInvoke is a function recorded with RegisterInvoke, to be
+// invoked on its Scope by Run.
+type registeredInvoke struct {
+	s        *Scope
+	fn       interface{}
+	opts     []InvokeOption
+	location *digreflect.Func
+}
+
+// RegisterInvoke records a function to invoke later, when Run is called,
+// instead of invoking it right away.
+//
+// This allows the parts of an application to register their own side
+// effects, such as registering routes or running migrations, and the
+// application to trigger all of them at once.
+//
+//	c.RegisterInvoke(registerRoutes)
+//	c.RegisterInvoke(runMigrations)
+//	// ...
+//	if err := c.Run(); err != nil {
+//	  log.Fatal(err)
+//	}
+//
+// RegisterInvoke fails if the function cannot be invoked, but its
+// dependencies are only looked up by Run. Use Validate to check them
+// beforehand.
+func (c *Container) RegisterInvoke(function interface{}, opts ...InvokeOption) error {
+	return c.scope.RegisterInvoke(function, opts...)
+}
+
+// RegisterInvoke records a function to invoke on the Scope when Run is
+// called on its Container. See Container.RegisterInvoke for details.
+func (s *Scope) RegisterInvoke(function interface{}, opts ...InvokeOption) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	function, location, err := unannotate(function)
+	if err != nil {
+		return err
+	}
+
+	ftype := reflect.TypeOf(function)
+	if ftype == nil {
+		return newErrInvalidInput("can't invoke an untyped nil", nil)
+	}
+	if ftype.Kind() != reflect.Func {
+		return newErrInvalidInput(
+			fmt.Sprintf("can't invoke non-function %v (type %v)", function, ftype), nil)
+	}
+	if _, err := newParamList(ftype, s); err != nil {
+		return err
+	}
+	if location == nil {
+		location = digreflect.InspectFunc(function)
+	}
+
+	state := s.rootScope().state
+	state.registeredInvokes = append(state.registeredInvokes, registeredInvoke{
+		s:        s,
+		fn:       function,
+		opts:     opts,
+		location: location,
+	})
+	return nil
+}
+
+// Run invokes the functions registered with RegisterInvoke on the Container
+// and its Scopes, in the order they were registered.
+//
+// Run stops at the first function that fails and returns its error, which
+// names the function. Each function is invoked only once: functions that
+// were invoked, successfully or not, are not invoked again by later calls
+// to Run, which only invoke the functions registered since.
+func (c *Container) Run() error {
+	state := c.scope.state
+	for len(state.registeredInvokes) > 0 {
+		ri := state.registeredInvokes[0]
+		state.registeredInvokes = state.registeredInvokes[1:]
+		if err := ri.s.Invoke(ri.fn, ri.opts...); err != nil {
+			return errRegisteredInvoke{Func: ri.location, Reason: err}
+		}
+	}
+	state.registeredInvokes = nil
+	return nil
+}
+
+// validateRegisteredInvokes reports the missing dependencies of the
+// functions registered with RegisterInvoke that were not run yet.
+func (s *Scope) validateRegisteredInvokes() []error {
+	var problems []error
+	for _, ri := range s.rootScope().state.registeredInvokes {
+		if ri.s.closed {
+			continue
+		}
+		pl, err := newParamList(reflect.TypeOf(ri.fn), ri.s)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		if err := shallowCheckDependencies(ri.s, pl); err != nil {
+			problems = append(problems, errMissingDependencies{Func: ri.location, Reason: err})
+		}
+	}
+	return problems
+}
+
+// errRegisteredInvoke is returned by Run when a function registered with
+// RegisterInvoke failed.
+type errRegisteredInvoke struct {
+	Func   *digreflect.Func
+	Reason error
+}
+
+var _ digError = errRegisteredInvoke{}
+
+func (e errRegisteredInvoke) Error() string { return fmt.Sprint(e) }
+
+func (e errRegisteredInvoke) Unwrap() error { return e.Reason }
+
+func (e errRegisteredInvoke) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "registered invoke of "+verb+" failed", e.Func)
+}
+
+func (e errRegisteredInvoke) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterInvoke(t *testing.T) {
+	t.Parallel()
+
+	t.Run("run in registration order", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		c := digtest.New(t)
+		require.NoError(t, c.RegisterInvoke(func(s string) { calls = append(calls, "first "+s) }))
+		child := c.Scope("child")
+		child.RequireProvide(func() int { return 42 })
+		require.NoError(t, child.RegisterInvoke(func(int) { calls = append(calls, "second") }))
+
+		m := dig.NewModule("routes")
+		m.RegisterInvoke(func() { calls = append(calls, "third") })
+		require.NoError(t, c.Apply(m))
+
+		// Dependencies may be provided after registration.
+		c.RequireProvide(func() string { return "foo" })
+		assert.Empty(t, calls, "nothing must be invoked before Run")
+
+		require.NoError(t, c.Run())
+		assert.Equal(t, []string{"first foo", "second", "third"}, calls)
+
+		require.NoError(t, c.Run())
+		assert.Len(t, calls, 3, "invokes must run only once")
+
+		require.NoError(t, c.RegisterInvoke(func() { calls = append(calls, "fourth") }))
+		require.NoError(t, c.Run())
+		assert.Equal(t, "fourth", calls[3])
+	})
+
+	t.Run("run stops at the first failure", func(t *testing.T) {
+		t.Parallel()
+
+		var called bool
+		c := digtest.New(t)
+		require.NoError(t, c.RegisterInvoke(func() error { return errors.New("great sadness") }))
+		require.NoError(t, c.RegisterInvoke(func() { called = true }))
+
+		err := c.Run()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "registered invoke of")
+		assert.Contains(t, err.Error(), "TestRegisterInvoke")
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.False(t, called)
+
+		require.NoError(t, c.Run())
+		assert.True(t, called, "invokes after the failure must run on the next Run")
+	})
+
+	t.Run("invalid functions", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		assert.ErrorContains(t, c.RegisterInvoke(nil), "can't invoke an untyped nil")
+		assert.ErrorContains(t, c.RegisterInvoke(42), "can't invoke non-function 42 (type int)")
+		assert.Error(t, c.RegisterInvoke(func(struct {
+			dig.In
+
+			foo string
+		}) {
+		}))
+		require.NoError(t, c.Run())
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.RegisterInvoke(func(string) {}))
+
+		err := c.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: string")
+
+		c.RequireProvide(func() string { return "" })
+		require.NoError(t, c.Validate())
+	})
+}
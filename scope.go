@@ -43,17 +43,26 @@ type Scope struct {
 
 	// Name of the Scope
 	name string
+
+	// Name of the Module this Scope was created for, if any.
+	module string
+
 	// Mapping from key to all the constructor node that can provide a value for that
 	// key.
 	providers map[key][]*constructorNode
 
-	// Mapping from key to the decorator that decorates a value for that key.
-	decorators map[key]*decoratorNode
+	// Mapping from key to the decorators that decorate a value for that key,
+	// in the order they are applied.
+	decorators map[key][]*decoratorNode
 
 	// constructorNodes provided directly to this Scope. i.e. it does not include
 	// any nodes that were provided to the parent Scope this inherited from.
 	nodes []*constructorNode
 
+	// decoratorNodes provided directly to this Scope, in the order they
+	// were provided.
+	decoratorNodes []*decoratorNode
+
 	// Values that generated via decorators in the Scope.
 	decoratedValues map[key]reflect.Value
 
@@ -61,11 +70,19 @@ type Scope struct {
 	values map[key]reflect.Value
 
 	// Values groups that generated directly in the Scope.
-	groups map[key][]reflect.Value
+	groups map[key][]groupValue
+
+	// Values of groups that were submitted with a name. These values are
+	// also part of groups.
+	namedGroups map[key][]namedGroupValue
 
 	// Values groups that generated via decoraters in the Scope.
 	decoratedGroups map[key]reflect.Value
 
+	// Templates provided with Factory directly to this Scope, by the type
+	// of the values they build.
+	factories map[reflect.Type]*factoryTemplate
+
 	// Source of randomness.
 	rand *rand.Rand
 
@@ -78,9 +95,28 @@ type Scope struct {
 	// Recover from panics in user-provided code and wrap in an exported error type.
 	recoverFromPanics bool
 
+	// How long constructors may run, if positive. See ConstructorTimeout.
+	constructorTimeout time.Duration
+
+	// Cleanup functions returned by the constructors whose values are held
+	// by the Scope, in the order they were called.
+	cleanups []func()
+
+	// Whether the Scope was closed.
+	closed bool
+
 	// invokerFn calls a function with arguments provided to Provide or Invoke.
 	invokerFn invokerFn
 
+	// Parameters of the functions invoked from this Scope, keyed by the
+	// type of the functions, so that they are parsed only once.
+	invokeParams map[reflect.Type]paramList
+
+	// parallel allows building independent values concurrently. It is
+	// shared by all the Scopes of a Container and nil unless MaxConcurrency
+	// was specified.
+	parallel *parallel
+
 	// graph of this Scope. Note that this holds the dependency graph of all the
 	// nodes that affect this Scope, not just the ones provided directly to this Scope.
 	gh *graphHolder
@@ -90,16 +126,31 @@ type Scope struct {
 
 	// All the child scopes of this Scope.
 	childScopes []*Scope
+
+	// State of the Container, shared by all its Scopes. Only the root Scope
+	// has one: it is reached through rootScope.
+	state *containerState
 }
 
+// newScope returns the root Scope of a new Container.
 func newScope() *Scope {
+	s := newEmptyScope()
+	s.state = newContainerState()
+	return s
+}
+
+// newEmptyScope returns a Scope without parent, child Scopes or
+// containerState.
+func newEmptyScope() *Scope {
 	s := &Scope{
 		providers:       make(map[key][]*constructorNode),
-		decorators:      make(map[key]*decoratorNode),
+		decorators:      make(map[key][]*decoratorNode),
 		values:          make(map[key]reflect.Value),
 		decoratedValues: make(map[key]reflect.Value),
-		groups:          make(map[key][]reflect.Value),
+		groups:          make(map[key][]groupValue),
+		namedGroups:     make(map[key][]namedGroupValue),
 		decoratedGroups: make(map[key]reflect.Value),
+		factories:       make(map[reflect.Type]*factoryTemplate),
 		invokerFn:       defaultInvoker,
 		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
@@ -112,16 +163,35 @@ func newScope() *Scope {
 // made to it in the future will be propagated to the child scope.
 // However, no modifications made to the child scope being created will be propagated
 // to the parent Scope.
-func (s *Scope) Scope(name string, opts ...ScopeOption) *Scope {
-	child := newScope()
+func (s *Scope) Scope(name string, opts ...ScopeOption) (child *Scope) {
+	_ = s.parallelism().do(func() error {
+		child = s.newChildScope(name, opts...)
+		return nil
+	})
+	return child
+}
+
+func (s *Scope) newChildScope(name string, opts ...ScopeOption) *Scope {
+	child := newEmptyScope()
 	child.name = name
 	child.parentScope = s
 	child.invokerFn = s.invokerFn
 	child.deferAcyclicVerification = s.deferAcyclicVerification
 	child.recoverFromPanics = s.recoverFromPanics
+	child.constructorTimeout = s.constructorTimeout
+	child.parallel = s.parallel
 
-	// child copies the parent's graph nodes.
+	// child copies the parent's graph nodes, which have the same orders in
+	// both graphs.
 	child.gh.nodes = append(child.gh.nodes, s.gh.nodes...)
+	for i, n := range child.gh.nodes {
+		switch w := n.Wrapped.(type) {
+		case *constructorNode:
+			w.orders[child] = i
+		case *paramGroupedSlice:
+			w.orders[child] = i
+		}
+	}
 
 	for _, opt := range opts {
 		opt.noScopeOption()
@@ -131,13 +201,75 @@ func (s *Scope) Scope(name string, opts ...ScopeOption) *Scope {
 	return child
 }
 
+// NewScope creates a new child Scope like Scope does, but fails if the
+// current Scope already has a child Scope with the given name. This allows
+// retrieving the child Scope later with ScopeByName.
+//
+//	reqScope, err := c.NewScope("request-" + reqID)
+//	...
+//	reqScope, ok := c.ScopeByName("request-" + reqID)
+func (s *Scope) NewScope(name string, opts ...ScopeOption) (child *Scope, err error) {
+	err = s.parallelism().do(func() error {
+		if _, ok := s.scopeByName(name); ok {
+			parent := "the container"
+			if s.parentScope != nil {
+				parent = fmt.Sprintf("scope %q", s.name)
+			}
+			return newErrInvalidInput(
+				fmt.Sprintf("cannot create scope %q: %v already has a child scope with this name", name, parent), nil)
+		}
+		child = s.newChildScope(name, opts...)
+		return nil
+	})
+	return child, err
+}
+
+// Name returns the name of the Scope.
+func (s *Scope) Name() string {
+	return s.name
+}
+
+// Scopes returns the child Scopes of the current Scope, in the order in
+// which they were created.
+func (s *Scope) Scopes() (scopes []*Scope) {
+	_ = s.parallelism().do(func() error {
+		scopes = append([]*Scope(nil), s.childScopes...)
+		return nil
+	})
+	return scopes
+}
+
+// ScopeByName returns the child Scope of the current Scope with the given
+// name, if any. If several child Scopes were created with this name using
+// Scope, the first one is returned: use NewScope to enforce unique names.
+func (s *Scope) ScopeByName(name string) (child *Scope, ok bool) {
+	_ = s.parallelism().do(func() error {
+		child, ok = s.scopeByName(name)
+		return nil
+	})
+	return child, ok
+}
+
+func (s *Scope) scopeByName(name string) (*Scope, bool) {
+	for _, cs := range s.childScopes {
+		if cs.name == name {
+			return cs, true
+		}
+	}
+	return nil, false
+}
+
 // ancestors returns a list of scopes of ancestors of this scope up to the
-// root. The scope at at index 0 is this scope itself.
+// root, followed by the scopes of the parent Container, if any. The scope at
+// at index 0 is this scope itself.
 func (s *Scope) ancestors() []*Scope {
 	var scopes []*Scope
 	for s := s; s != nil; s = s.parentScope {
 		scopes = append(scopes, s)
 	}
+	if d := s.rootScope().state.delegate; d != nil {
+		scopes = append(scopes, d.ancestors()...)
+	}
 	return scopes
 }
 
@@ -172,6 +304,17 @@ func (s *Scope) knownTypes() []reflect.Type {
 	return types
 }
 
+func (s *Scope) knownNames(t reflect.Type) []string {
+	var names []string
+	for k, ps := range s.providers {
+		if k.t == t && k.group == "" && len(ps) > 0 {
+			names = append(names, k.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (s *Scope) getValue(name string, t reflect.Type) (v reflect.Value, ok bool) {
 	v, ok = s.values[key{name: name, t: t}]
 	return
@@ -191,21 +334,44 @@ func (s *Scope) setDecoratedValue(name string, t reflect.Type, v reflect.Value)
 }
 
 func (s *Scope) getValueGroup(name string, t reflect.Type) []reflect.Value {
-	items := s.groups[key{group: name, t: t}]
+	gvs := s.groups[key{group: name, t: t}]
+	items := make([]reflect.Value, len(gvs))
+	for i, gv := range gvs {
+		items[i] = gv.Value
+	}
 	// shuffle the list so users don't rely on the ordering of grouped values
 	return shuffledCopy(s.rand, items)
 }
 
+func (s *Scope) getOrderedValueGroup(name string, t reflect.Type) []groupValue {
+	return s.groups[key{group: name, t: t}]
+}
+
+func (s *Scope) getGroupLess(name string, t reflect.Type) (reflect.Value, bool) {
+	less, ok := s.rootScope().state.groupLess[key{group: name, t: t}]
+	return less, ok
+}
+
 func (s *Scope) getDecoratedValueGroup(name string, t reflect.Type) (reflect.Value, bool) {
 	items, ok := s.decoratedGroups[key{group: name, t: t}]
 	return items, ok
 }
 
-func (s *Scope) submitGroupedValue(name string, t reflect.Type, v reflect.Value) {
+func (s *Scope) submitGroupedValue(name string, t reflect.Type, v groupValue) {
 	k := key{group: name, t: t}
 	s.groups[k] = append(s.groups[k], v)
 }
 
+func (s *Scope) getNamedValueGroup(group string, t reflect.Type) []namedGroupValue {
+	return s.namedGroups[key{group: group, t: t}]
+}
+
+func (s *Scope) submitNamedGroupedValue(group, name string, t reflect.Type, v groupValue) {
+	s.submitGroupedValue(group, t, v)
+	k := key{group: group, t: t}
+	s.namedGroups[k] = append(s.namedGroups[k], namedGroupValue{Name: name, Value: v.Value})
+}
+
 func (s *Scope) submitDecoratedGroupedValue(name string, t reflect.Type, v reflect.Value) {
 	k := key{group: name, t: t}
 	s.decoratedGroups[k] = v
@@ -227,9 +393,26 @@ func (s *Scope) getGroupDecorator(name string, t reflect.Type) (decorator, bool)
 	return s.getDecorators(key{group: name, t: t})
 }
 
+// getDecorators returns the decorator of the key whose value is resolved:
+// the last one of its chain, or the one right before the first decorator of
+// the chain that is running, so that each decorator receives the value of
+// the decorator before it. If the first decorator is running, it is
+// returned, and the value is resolved from the parent Scope. See
+// decoratorNode.Call.
 func (s *Scope) getDecorators(k key) (decorator, bool) {
-	d, found := s.decorators[k]
-	return d, found
+	chain := s.decorators[k]
+	if len(chain) == 0 {
+		return nil, false
+	}
+	for i, d := range chain {
+		if d.state == decoratorOnStack {
+			if i > 0 {
+				return chain[i-1], true
+			}
+			return d, true
+		}
+	}
+	return chain[len(chain)-1], true
 }
 
 func (s *Scope) getProviders(k key) []provider {
@@ -262,6 +445,32 @@ func (s *Scope) invoker() invokerFn {
 	return s.invokerFn
 }
 
+func (s *Scope) parallelism() *parallel {
+	if s.parallel == nil {
+		if state := s.rootScope().state; state.lazyDepth > 0 {
+			return &state.lazyLock
+		}
+	}
+	return s.parallel
+}
+
+// lazyGet calls fn to resolve a dig.Lazy dependency. Resolutions are
+// serialized by the lock of the Container: without parallelism, lazyLock
+// becomes that lock for their duration, and is released while constructors
+// run as with ConcurrencySafe, so that constructors may call Get too.
+func (s *Scope) lazyGet(fn func() error) error {
+	if s.parallel != nil {
+		return s.parallel.do(fn)
+	}
+
+	state := s.rootScope().state
+	return state.lazyLock.do(func() error {
+		state.lazyDepth++
+		defer func() { state.lazyDepth-- }()
+		return fn()
+	})
+}
+
 // adds a new graphNode to this Scope and all of its descendent
 // scope.
 func (s *Scope) newGraphNode(wrapped interface{}, orders map[*Scope]int) {
@@ -295,6 +504,19 @@ func (s *Scope) rootScope() *Scope {
 	return curr
 }
 
+// path returns the names of the scopes leading from the root Scope to this
+// Scope, excluding the root Scope itself.
+func (s *Scope) path() []string {
+	var names []string
+	for curr := s; curr.parentScope != nil; curr = curr.parentScope {
+		names = append(names, curr.name)
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return names
+}
+
 // String representation of the entire Scope
 func (s *Scope) String() string {
 	b := &bytes.Buffer{}
@@ -312,7 +534,7 @@ func (s *Scope) String() string {
 	}
 	for k, vs := range s.groups {
 		for _, v := range vs {
-			fmt.Fprintln(b, "\t", k, "=>", v)
+			fmt.Fprintln(b, "\t", k, "=>", v.Value)
 		}
 	}
 	fmt.Fprintln(b, "}")
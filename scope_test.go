@@ -24,8 +24,9 @@ import (
 	"testing"
 
 	"github.com/alexisvisco/dig"
-	"github.com/alexisvisco/dig/internal/digtest"
+	"github.com/alexisvisco/dig/digtest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestScopedOperations(t *testing.T) {
@@ -398,4 +399,74 @@ func TestScopeValueGroups(t *testing.T) {
 		// the parent.
 		child.RequireInvoke(func(T1) {})
 	})
+
+	t.Run("child scope created after the group was consumed", func(t *testing.T) {
+		type param struct {
+			dig.In
+
+			Values []int `group:"values"`
+		}
+
+		root := digtest.New(t)
+		root.RequireInvoke(func(param) {})
+		root.RequireProvide(func() int { return 1 }, dig.Group("values"))
+
+		child := root.Scope("child")
+		child.RequireInvoke(func(p param) {
+			assert.Equal(t, []int{1}, p.Values)
+		})
+	})
+}
+
+func TestScopeLookup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enumerate and look up child scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		child1 := c.Scope("child 1")
+		child2 := c.Scope("child 2")
+		grandchild := child1.Scope("grandchild")
+
+		assert.Equal(t, []*dig.Scope{child1, child2}, c.Scopes())
+		assert.Equal(t, []*dig.Scope{grandchild}, child1.Scopes())
+		assert.Empty(t, child2.Scopes())
+		assert.Equal(t, "grandchild", grandchild.Name())
+
+		s, ok := c.ScopeByName("child 2")
+		require.True(t, ok)
+		assert.Same(t, child2, s)
+
+		s, ok = child1.ScopeByName("grandchild")
+		require.True(t, ok)
+		assert.Same(t, grandchild, s)
+
+		_, ok = c.ScopeByName("grandchild")
+		assert.False(t, ok, "only direct children must be looked up")
+	})
+
+	t.Run("unique names", func(t *testing.T) {
+		t.Parallel()
+
+		c := dig.New()
+		child, err := c.NewScope("child")
+		require.NoError(t, err)
+
+		_, err = c.NewScope("child")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot create scope "child": the container already has a child scope with this name`)
+
+		_, err = child.NewScope("grandchild")
+		require.NoError(t, err)
+		_, err = child.NewScope("grandchild")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`cannot create scope "grandchild": scope "child" already has a child scope with this name`)
+
+		_, err = child.NewScope("child")
+		assert.NoError(t, err, "names must be unique among siblings only")
+		assert.Len(t, c.Scopes(), 1)
+	})
 }
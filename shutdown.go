@@ -0,0 +1,151 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// Shutdowner is implemented by values that must be shut down when the
+// application exits. See AutoClose.
+type Shutdowner interface {
+	Shutdown(context.Context) error
+}
+
+// AutoClose is an Option that collects the values produced by constructors
+// that implement Shutdowner or io.Closer, so that Container.Shutdown shuts
+// them down.
+//
+//	c := dig.New(dig.AutoClose())
+//	defer c.Shutdown(ctx)
+//
+// Values are collected when their constructor is called, in any Scope.
+// Values given to Supply are not collected, since the container did not
+// construct them.
+func AutoClose() Option {
+	return autoCloseOption{}
+}
+
+type autoCloseOption struct{}
+
+func (autoCloseOption) String() string {
+	return "AutoClose()"
+}
+
+func (autoCloseOption) applyOption(c *Container) {
+	c.scope.state.autoClose = true
+}
+
+// shutdownFunc shuts down a value collected by AutoClose.
+type shutdownFunc func(context.Context) error
+
+// closable is a value collected by AutoClose.
+type closable struct {
+	// The value, if it is comparable, so that it is collected only once.
+	value    interface{}
+	shutdown shutdownFunc
+}
+
+// Shutdown shuts down the values collected with AutoClose, in the reverse
+// order their constructors were called. Constructors are called after
+// those of their dependencies, so values are shut down before their
+// dependencies, except for dependencies reached through dig.Lazy: these
+// are built when Get is called, after the constructors that receive the
+// dig.Lazy, and are shut down first. The Shutdown method of a Shutdowner
+// is called with ctx. Otherwise, the Close method of an io.Closer is
+// called.
+//
+// All values are shut down even if some of them fail, in which case the
+// returned error holds all the failures. Values are shut down once, even if
+// they were returned by several constructors or in several fields of a
+// dig.Out struct: calling Shutdown again only shuts down the values
+// constructed since.
+func (c *Container) Shutdown(ctx context.Context) error {
+	var shutdowns []closable
+	_ = c.scope.parallelism().do(func() error {
+		shutdowns, c.scope.state.shutdowns = c.scope.state.shutdowns, nil
+		return nil
+	})
+
+	var errs []error
+	for i := len(shutdowns) - 1; i >= 0; i-- {
+		errs = append(errs, c.scope.runShutdown(ctx, shutdowns[i].shutdown))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scope) runShutdown(ctx context.Context, f shutdownFunc) (err error) {
+	if s.recoverFromPanics {
+		defer func() {
+			if p := recover(); p != nil {
+				err = newPanicError(digreflect.InspectFunc(f), false /* constructor */, p)
+			}
+		}()
+	}
+	return f(ctx)
+}
+
+// shutdowns returns how to shut down the values this constructor returned
+// that implement Shutdowner or io.Closer.
+func (n *constructorNode) shutdowns(values []reflect.Value) []closable {
+	if n.supplied != nil {
+		return nil
+	}
+
+	var shutdowns []closable
+	eachResultValue(n.resultList, values, func(_ key, v reflect.Value) {
+		var c closable
+		switch x := v.Interface().(type) {
+		case Shutdowner:
+			c.shutdown = x.Shutdown
+		case io.Closer:
+			c.shutdown = func(context.Context) error { return x.Close() }
+		default:
+			return
+		}
+		if x := v.Interface(); reflect.ValueOf(x).Comparable() {
+			c.value = x
+		}
+		shutdowns = append(shutdowns, c)
+	})
+	return shutdowns
+}
+
+// collectShutdowns adds the given values to the values collected by
+// AutoClose, leaving out the ones that are already collected.
+func (st *containerState) collectShutdowns(values []closable) {
+collect:
+	for _, c := range values {
+		if c.value != nil {
+			for _, prev := range st.shutdowns {
+				if prev.value == c.value {
+					continue collect
+				}
+			}
+		}
+		st.shutdowns = append(st.shutdowns, c)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+type shutdownerFunc func(context.Context) error
+
+func (f shutdownerFunc) Shutdown(ctx context.Context) error { return f(ctx) }
+
+func TestAutoClose(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ io.Closer }
+	type B struct{ dig.Shutdowner }
+
+	t.Run("reverse dependency order", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			closed []string
+			ctxs   []context.Context
+		)
+		c := digtest.New(t, dig.AutoClose())
+		c.RequireProvide(func() *A {
+			return &A{closerFunc(func() error {
+				closed = append(closed, "A")
+				return nil
+			})}
+		})
+		child := c.Scope("child")
+		child.RequireProvide(func(*A) *B {
+			return &B{shutdownerFunc(func(ctx context.Context) error {
+				closed = append(closed, "B")
+				ctxs = append(ctxs, ctx)
+				return nil
+			})}
+		})
+		child.RequireInvoke(func(*B) {})
+
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		require.NoError(t, c.Shutdown(ctx))
+		assert.Equal(t, []string{"B", "A"}, closed)
+		assert.Equal(t, []context.Context{ctx}, ctxs)
+
+		require.NoError(t, c.Shutdown(ctx))
+		assert.Equal(t, []string{"B", "A"}, closed, "values must be shut down once")
+	})
+
+	t.Run("all values are shut down", func(t *testing.T) {
+		t.Parallel()
+
+		var closed []string
+		closer := func(name string, err error) io.Closer {
+			return closerFunc(func() error {
+				closed = append(closed, name)
+				return err
+			})
+		}
+
+		type out struct {
+			dig.Out
+
+			Closers []io.Closer `group:"closers,flatten"`
+		}
+		c := digtest.New(t, dig.AutoClose())
+		c.RequireProvide(func() out {
+			return out{Closers: []io.Closer{
+				closer("a", errors.New("great sadness")),
+				closer("b", nil),
+				closer("c", errors.New("terrible sadness")),
+			}}
+		})
+
+		type in struct {
+			dig.In
+
+			Closers []io.Closer `group:"closers"`
+		}
+		c.RequireInvoke(func(in) {})
+
+		err := c.Shutdown(context.Background())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "great sadness")
+		assert.ErrorContains(t, err, "terrible sadness")
+		assert.Equal(t, []string{"c", "b", "a"}, closed)
+	})
+
+	t.Run("values returned twice", func(t *testing.T) {
+		t.Parallel()
+
+		var closed int
+		a := &A{closerFunc(func() error {
+			closed++
+			return nil
+		})}
+
+		type out struct {
+			dig.Out
+
+			A      *A
+			Shared *A `name:"shared"`
+		}
+		c := digtest.New(t, dig.AutoClose())
+		c.RequireProvide(func() out { return out{A: a, Shared: a} })
+		c.RequireProvide(func(a *A) io.Closer { return a })
+		c.RequireInvoke(func(io.Closer) {})
+
+		require.NoError(t, c.Shutdown(context.Background()))
+		assert.Equal(t, 1, closed, "value must be shut down once")
+	})
+
+	t.Run("without option", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A {
+			return &A{closerFunc(func() error {
+				t.Error("value must not be closed")
+				return nil
+			})}
+		})
+		c.RequireInvoke(func(*A) {})
+		require.NoError(t, c.Shutdown(context.Background()))
+	})
+
+	t.Run("supplied values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.AutoClose())
+		require.NoError(t, c.Supply(&A{closerFunc(func() error {
+			t.Error("supplied value must not be closed")
+			return nil
+		})}))
+		c.RequireInvoke(func(*A) {})
+		require.NoError(t, c.Shutdown(context.Background()))
+	})
+}
+
+func TestAutoCloseString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "AutoClose()", fmt.Sprint(dig.AutoClose()))
+}
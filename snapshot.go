@@ -0,0 +1,210 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "reflect"
+
+// Restore rolls a Container back to the state it was in when the Snapshot
+// that returned it was taken. It may be called several times.
+type Restore func()
+
+// Snapshot captures the current state of the Container: the constructors
+// and decorators provided to it and to its Scopes, and the values they
+// built. The returned Restore rolls the Container back to that state,
+// which lets test suites share a Container whose test cases provide,
+// replace or build values of their own.
+//
+//	restore := c.Snapshot()
+//	defer restore()
+//
+//	c.Replace(newFakeClock)
+//	c.Invoke(...)
+//
+// Restoring drops the constructors, decorators, values and Scopes added
+// since the snapshot, and runs the cleanup functions of the values it
+// drops. Scopes added since the snapshot are closed, and Scopes closed
+// since the snapshot remain closed. Lifecycle hooks
+// registered since the snapshot are dropped without being stopped: call
+// Container.Stop before restoring if they were started. Health checks,
+// and values collected by AutoClose, registered since the snapshot are
+// dropped as well.
+func (c *Container) Snapshot() Restore {
+	root, state := c.scope, c.scope.state
+	scopes := root.appendSubscopes(nil)
+	states := make([]scopeState, len(scopes))
+	for i, s := range scopes {
+		states[i] = newScopeState(s)
+	}
+
+	var (
+		numConstructors   = state.constructorCount
+		warnings          = append([]GroupWarning(nil), state.warnings...)
+		registeredInvokes = append([]registeredInvoke(nil), state.registeredInvokes...)
+		numHooks          = len(state.lifecycle.hooks)
+		numHealthChecks   = len(state.healthChecks)
+		numShutdowns      = len(state.shutdowns)
+		groupLess         = copyMap(state.groupLess)
+	)
+	return func() {
+		for _, st := range states {
+			st.restore()
+		}
+		state.constructorCount = numConstructors
+		state.warnings = warnings
+		state.healthChecks = state.healthChecks[:numHealthChecks:numHealthChecks]
+		if len(state.shutdowns) > numShutdowns {
+			state.shutdowns = state.shutdowns[:numShutdowns:numShutdowns]
+		}
+		state.registeredInvokes = append([]registeredInvoke(nil), registeredInvokes...)
+		state.groupLess = copyMap(groupLess)
+
+		lc := state.lifecycle
+		if len(lc.hooks) > numHooks {
+			lc.hooks = lc.hooks[:numHooks:numHooks]
+		}
+		if lc.numStarted > len(lc.hooks) {
+			lc.numStarted = len(lc.hooks)
+		}
+	}
+}
+
+// scopeState is the state of a Scope captured by Snapshot.
+type scopeState struct {
+	s *Scope
+
+	providers       map[key][]*constructorNode
+	decorators      map[key][]*decoratorNode
+	nodes           []*constructorNode
+	decoratorNodes  []*decoratorNode
+	values          map[key]reflect.Value
+	decoratedValues map[key]reflect.Value
+	groups          map[key][]groupValue
+	namedGroups     map[key][]namedGroupValue
+	decoratedGroups map[key]reflect.Value
+	factories       map[reflect.Type]*factoryTemplate
+	childScopes     []*Scope
+	numCleanups     int
+	numGraphNodes   int
+	verifiedAcyclic bool
+
+	// Whether the constructors of the Scope were called, and the states of
+	// its decorators.
+	called          map[*constructorNode]bool
+	decoratorStates map[*decoratorNode]decoratorState
+}
+
+func newScopeState(s *Scope) scopeState {
+	st := scopeState{
+		s:               s,
+		providers:       copyMap(s.providers),
+		decorators:      copyMap(s.decorators),
+		nodes:           append([]*constructorNode(nil), s.nodes...),
+		decoratorNodes:  append([]*decoratorNode(nil), s.decoratorNodes...),
+		values:          copyMap(s.values),
+		decoratedValues: copyMap(s.decoratedValues),
+		groups:          copyMap(s.groups),
+		namedGroups:     copyMap(s.namedGroups),
+		decoratedGroups: copyMap(s.decoratedGroups),
+		factories:       copyMap(s.factories),
+		childScopes:     append([]*Scope(nil), s.childScopes...),
+		numCleanups:     len(s.cleanups),
+		numGraphNodes:   len(s.gh.nodes),
+		verifiedAcyclic: s.isVerifiedAcyclic,
+		called:          make(map[*constructorNode]bool, len(s.nodes)),
+		decoratorStates: make(map[*decoratorNode]decoratorState, len(s.decoratorNodes)),
+	}
+	for _, n := range s.nodes {
+		st.called[n] = n.called
+	}
+	for _, d := range s.decoratorNodes {
+		st.decoratorStates[d] = d.state
+	}
+	return st
+}
+
+func (st scopeState) restore() {
+	s := st.s
+	if s.closed {
+		return
+	}
+
+	// Scopes created since the snapshot are closed first, as their values
+	// may depend on those of s.
+	known := make(map[*Scope]struct{}, len(st.childScopes))
+	for _, cs := range st.childScopes {
+		known[cs] = struct{}{}
+	}
+	for i := len(s.childScopes) - 1; i >= 0; i-- {
+		if _, ok := known[s.childScopes[i]]; !ok {
+			_ = s.childScopes[i].close()
+		}
+	}
+
+	for i := len(s.cleanups) - 1; i >= st.numCleanups; i-- {
+		// As with Close, panics are only recovered with RecoverFromPanics,
+		// and there is nobody to report them to.
+		_ = s.runCleanup(s.cleanups[i])
+	}
+	s.cleanups = s.cleanups[:st.numCleanups:st.numCleanups]
+
+	s.providers = copyMap(st.providers)
+	s.decorators = copyMap(st.decorators)
+	s.nodes = append([]*constructorNode(nil), st.nodes...)
+	s.decoratorNodes = append([]*decoratorNode(nil), st.decoratorNodes...)
+	s.values = copyMap(st.values)
+	s.decoratedValues = copyMap(st.decoratedValues)
+	s.groups = copyMap(st.groups)
+	s.namedGroups = copyMap(st.namedGroups)
+	s.decoratedGroups = copyMap(st.decoratedGroups)
+	s.factories = copyMap(st.factories)
+	s.gh.nodes = s.gh.nodes[:st.numGraphNodes:st.numGraphNodes]
+	// The parameters of invoked functions may refer to graph nodes that
+	// were just dropped.
+	s.invokeParams = nil
+	s.isVerifiedAcyclic = st.verifiedAcyclic
+
+	s.childScopes = s.childScopes[:0]
+	for _, cs := range st.childScopes {
+		if !cs.closed {
+			s.childScopes = append(s.childScopes, cs)
+		}
+	}
+
+	for n, called := range st.called {
+		n.called = called
+		if !called {
+			n.duration = 0
+		}
+	}
+	for d, state := range st.decoratorStates {
+		d.state = state
+	}
+}
+
+// copyMap returns a shallow copy of m. Slices held by m are shared with the
+// copy, which is safe as the Scope never modifies them in place.
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Name string }
+	type Client struct{ Config *Config }
+
+	t.Run("restores providers and values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{Name: "real"} })
+
+		var real *Config
+		c.RequireInvoke(func(cfg *Config) { real = cfg })
+
+		restore := c.Snapshot()
+
+		clients := 0
+		c.RequireProvide(func(cfg *Config) *Client {
+			clients++
+			return &Client{Config: cfg}
+		})
+		require.NoError(t, c.Replace(func() *Config { return &Config{Name: "fake"} }))
+		c.RequireInvoke(func(cl *Client) {
+			assert.Equal(t, "fake", cl.Config.Name)
+		})
+
+		restore()
+
+		c.RequireInvoke(func(cfg *Config) {
+			assert.Same(t, real, cfg)
+		})
+		assert.Error(t, c.Invoke(func(*Client) {}), "constructor provided after the snapshot")
+
+		// Restoring again rolls back the changes made since the last
+		// restore.
+		c.RequireProvide(func(cfg *Config) *Client { return &Client{Config: cfg} })
+		restore()
+		assert.Error(t, c.Invoke(func(*Client) {}))
+		assert.Equal(t, 1, clients)
+	})
+
+	t.Run("unbuilt values are built again", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		calls := 0
+		c.RequireProvide(func() *Config {
+			calls++
+			return &Config{}
+		})
+
+		restore := c.Snapshot()
+		c.RequireInvoke(func(*Config) {})
+		restore()
+		c.RequireInvoke(func(*Config) {})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("value groups and decorators", func(t *testing.T) {
+		t.Parallel()
+
+		type Param struct {
+			dig.In
+
+			Names []string `group:"names"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("names"))
+
+		restore := c.Snapshot()
+		c.RequireProvide(func() string { return "b" }, dig.Group("names"))
+		c.RequireDecorate(func(p Param) []string { return append(p.Names, "c") }, dig.DecorateGroup("names"))
+		c.RequireInvoke(func(p Param) {
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, p.Names)
+		})
+
+		restore()
+		c.RequireInvoke(func(p Param) {
+			assert.Equal(t, []string{"a"}, p.Names)
+		})
+	})
+
+	t.Run("scopes created after the snapshot are dropped", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+
+		restore := c.Snapshot()
+		child.Scope("grandchild")
+		c.Scope("other")
+		restore()
+
+		require.Len(t, c.Scopes(), 1)
+		assert.Equal(t, "child", c.Scopes()[0].Name())
+		assert.Empty(t, child.Scopes())
+	})
+
+	t.Run("cleanups of dropped values are run", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		var cleaned []string
+		child.RequireProvide(func() (*Config, func()) {
+			return &Config{}, func() { cleaned = append(cleaned, "config") }
+		})
+		child.RequireProvide(func(cfg *Config) (*Client, func()) {
+			return &Client{Config: cfg}, func() { cleaned = append(cleaned, "client") }
+		})
+		child.RequireInvoke(func(*Config) {})
+
+		restore := c.Snapshot()
+		child.RequireInvoke(func(*Client) {})
+		restore()
+		assert.Equal(t, []string{"client"}, cleaned)
+
+		require.NoError(t, child.Close())
+		assert.Equal(t, []string{"client", "config"}, cleaned)
+	})
+	t.Run("cleanups of dropped scopes are run", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var cleaned []string
+		c.RequireProvide(func() (*Config, func()) {
+			return &Config{}, func() { cleaned = append(cleaned, "config") }
+		})
+
+		restore := c.Snapshot()
+		child := c.Scope("child")
+		child.RequireProvide(func(cfg *Config) (*Client, func()) {
+			return &Client{Config: cfg}, func() { cleaned = append(cleaned, "client") }
+		})
+		child.RequireInvoke(func(*Client) {})
+		restore()
+
+		assert.Equal(t, []string{"client", "config"}, cleaned,
+			"scopes created after the snapshot must be cleaned up before their parent")
+		assert.Error(t, child.Invoke(func() {}), "dropped scopes must be closed")
+	})
+}
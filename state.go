@@ -0,0 +1,130 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// containerState is the state of a Container that is shared by all its
+// Scopes. It is held by the root Scope, and reached through rootScope.
+type containerState struct {
+	// Less functions registered with RegisterGroupLess to order value
+	// groups consumed with the sorted option.
+	groupLess map[key]reflect.Value
+
+	// Number of constructors provided to the Container so far.
+	constructorCount int
+
+	// Whether all the constructors are called by Container.Build.
+	eagerAll bool
+
+	// Profiles activated with ActiveProfiles.
+	profiles map[string]struct{}
+
+	// Interceptors wrapping every constructor call, outermost first.
+	interceptors []ProviderInterceptorCtx
+
+	// Whether dig.Lazy dependencies are left out of cycle detection.
+	lazyCycles bool
+
+	// Serializes the resolution of dig.Lazy dependencies of containers
+	// without parallelism, and the number of resolutions in progress, during
+	// which lazyLock is the parallelism of the Container.
+	lazyLock  parallel
+	lazyDepth int
+
+	// Whether dig.In structs are checked for ignored fields. See StrictIn.
+	strictIn bool
+
+	// Handler supplying the values that no constructor provides, and its
+	// location. See WithMissingHandler.
+	missingHandler         MissingHandler
+	missingHandlerLocation *digreflect.Func
+
+	// Names of the plugins loaded into the Container. See Registry.Load.
+	plugins map[string]struct{}
+
+	// Whether constructors are called with pprof labels. See
+	// WithPprofLabels.
+	pprofLabels bool
+
+	// Number of values reused because their constructor was already called.
+	// See Stats.
+	cacheHits atomic.Int64
+
+	// Collector receiving the events of the container.
+	metrics Collector
+
+	// Invokes registered with RegisterInvoke on any Scope, in order, until
+	// they are executed by Run.
+	registeredInvokes []registeredInvoke
+
+	// Constructors left out of tolerant value groups, in the order they
+	// first failed, with their latest failure.
+	warnings []GroupWarning
+
+	// Warnings of the Invoke in progress, if any.
+	invokeWarnings *[]GroupWarning
+
+	// Contexts of the Invokes in progress that were given one, the latest
+	// last.
+	contexts []*context.Context
+
+	// Tracers of the Invokes in progress that were given WithTrace, the
+	// latest last.
+	tracers []*tracer
+
+	// Errors of the constructors that failed while the arguments of an
+	// Invoke were built, so that the other arguments do not call them
+	// again. Only set while the arguments of an Invoke are built.
+	failedCalls map[*constructorNode]error
+
+	// Report of the TryInvoke in progress, if any.
+	report *InvokeReport
+
+	// Scope of another Container that values missing from this Container
+	// are looked up in, if it was created with NewWithParent.
+	delegate *Scope
+
+	// Lifecycle hooks registered by constructors.
+	lifecycle *lifecycle
+
+	// Health checks registered by constructors, in the order they were
+	// called.
+	healthChecks []healthCheck
+
+	// Whether values implementing Shutdowner or io.Closer are collected
+	// into shutdowns, in the order their constructors were called.
+	autoClose bool
+	shutdowns []closable
+}
+
+func newContainerState() *containerState {
+	return &containerState{
+		groupLess: make(map[key]reflect.Value),
+		lifecycle: new(lifecycle),
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+// Stats summarizes the graph of a Container and how its values were used.
+// They are returned by Container.Stats.
+type Stats struct {
+	// Providers is the number of constructors provided to the container and
+	// its Scopes, including the ones built by Supply.
+	Providers int
+
+	// Decorators is the number of decorators registered to the container
+	// and its Scopes.
+	Decorators int
+
+	// Groups is the number of value groups produced by the constructors,
+	// identified by their name and the type of their values.
+	Groups int
+
+	// NamedValues is the number of named values produced by the
+	// constructors, identified by their name and type.
+	NamedValues int
+
+	// Scopes is the number of Scopes, including the container.
+	Scopes int
+
+	// MaxDepth is the length of the longest chain of constructors depending
+	// on each other. It is 1 if no constructor depends on another.
+	MaxDepth int
+
+	// MaxFanIn is the constructor with the most distinct dependencies, and
+	// MaxFanOut the one whose values are consumed by the most constructors.
+	// They are zero if the container has no constructor.
+	MaxFanIn  ProviderDegree
+	MaxFanOut ProviderDegree
+
+	// CacheHits is the number of times the value of a constructor was
+	// reused rather than built, because the constructor was already called.
+	CacheHits int
+}
+
+// ProviderDegree is a constructor along with a number of edges of the graph
+// it is at the end of.
+type ProviderDegree struct {
+	Provider ProviderInfo
+	Degree   int
+}
+
+// Stats returns statistics about the graph of the container and its Scopes,
+// such as the number of constructors and value groups or the constructor
+// with the most dependencies. This helps track the wiring of large
+// applications on dashboards and spot smells, such as constructors that too
+// much of the application depends on.
+func (c *Container) Stats() (stats Stats) {
+	// Constructors may be running concurrently with ConcurrencySafe.
+	_ = c.scope.parallelism().do(func() error {
+		stats = c.stats()
+		return nil
+	})
+	return stats
+}
+
+func (c *Container) stats() Stats {
+	scopes := c.scope.appendSubscopes(nil)
+	stats := Stats{
+		Scopes:    len(scopes),
+		CacheHits: int(c.scope.state.cacheHits.Load()),
+	}
+	for _, s := range scopes {
+		stats.Decorators += len(s.decoratorNodes)
+	}
+
+	g := newProviderGraph(c)
+	stats.Providers = len(g.providers)
+
+	groups := make(map[Key]struct{})
+	named := make(map[Key]struct{})
+	for i, p := range g.providers {
+		for _, o := range p.Outputs {
+			switch k := o.Key(); {
+			case k.Group != "":
+				groups[k] = struct{}{}
+			case k.Name != "":
+				named[k] = struct{}{}
+			}
+		}
+		if n := len(g.inputs(i)); n > stats.MaxFanIn.Degree || i == 0 {
+			stats.MaxFanIn = ProviderDegree{Provider: p, Degree: n}
+		}
+		if n := len(g.dependents(i)); n > stats.MaxFanOut.Degree || i == 0 {
+			stats.MaxFanOut = ProviderDegree{Provider: p, Degree: n}
+		}
+		if d := g.depth(i); d > stats.MaxDepth {
+			stats.MaxDepth = d
+		}
+	}
+	stats.Groups = len(groups)
+	stats.NamedValues = len(named)
+	return stats
+}
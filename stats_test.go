@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{}
+	type Logger struct{}
+	type DB struct{}
+	type Handler struct{}
+	type Server struct{}
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, dig.Stats{Scopes: 1}, digtest.New(t).Stats())
+	})
+
+	t.Run("graph", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *Logger { return &Logger{} })
+		c.RequireProvide(func(*Config, *Logger) *DB { return &DB{} }, dig.Name("primary"))
+		c.RequireProvide(func(*Config, *Logger) *DB { return &DB{} }, dig.Name("replica"))
+		c.RequireProvide(func(p struct {
+			dig.In
+
+			DB     *DB `name:"primary"`
+			Logger *Logger
+			Config *Config
+		}) *Handler {
+			return &Handler{}
+		}, dig.Group("handlers"))
+		c.RequireDecorate(func(l *Logger) *Logger { return l })
+
+		child := c.Scope("child")
+		child.RequireProvide(func(p struct {
+			dig.In
+
+			Handlers []*Handler `group:"handlers"`
+		}) *Server {
+			return &Server{}
+		})
+		c.Scope("other")
+
+		stats := c.Stats()
+		assert.Equal(t, 6, stats.Providers)
+		assert.Equal(t, 1, stats.Decorators)
+		assert.Equal(t, 1, stats.Groups)
+		assert.Equal(t, 2, stats.NamedValues)
+		assert.Equal(t, 3, stats.Scopes)
+		assert.Equal(t, 5, stats.MaxDepth, "Config, Logger, DB, Handler, Server")
+
+		assert.Equal(t, 3, stats.MaxFanIn.Degree)
+		assert.Equal(t, "github.com/alexisvisco/dig_test.TestStats.func2.5", stats.MaxFanIn.Provider.Name)
+		assert.Equal(t, 4, stats.MaxFanOut.Degree, "Config is consumed by Logger, both DBs and Handler")
+		assert.Equal(t, "github.com/alexisvisco/dig_test.TestStats.func2.1", stats.MaxFanOut.Provider.Name)
+		assert.Zero(t, stats.CacheHits)
+	})
+
+	t.Run("cache hits", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *Logger { return &Logger{} })
+
+		c.RequireInvoke(func(*Logger) {})
+		assert.Equal(t, 0, c.Stats().CacheHits)
+
+		c.RequireInvoke(func(*Logger, *Config) {})
+		assert.Equal(t, 2, c.Stats().CacheHits)
+
+		c.Scope("child").RequireInvoke(func(*Config) {})
+		assert.Equal(t, 3, c.Stats().CacheHits)
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.AllowCyclesVia(dig.LazyDependency))
+		c.RequireProvide(func(dig.Lazy[*DB]) *Logger { return &Logger{} })
+		c.RequireProvide(func(*Logger) *DB { return &DB{} })
+
+		stats := c.Stats()
+		assert.Equal(t, 2, stats.Providers)
+		assert.Equal(t, 2, stats.MaxDepth, "the cycle is followed once")
+	})
+	t.Run("concurrent constructors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConcurrencySafe(), dig.MaxConcurrency(4))
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *Logger { return &Logger{} })
+		c.RequireProvide(func(*Config) *DB { return &DB{} })
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Scope("child").Invoke(func(*Logger, *DB) {}))
+			}()
+			go func() {
+				defer wg.Done()
+				assert.Equal(t, 3, c.Stats().Providers)
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StrictIn is an Option that rejects dig.In structs with fields that dig
+// would otherwise ignore or misread, instead of silently building them.
+// With StrictIn, Provide, Decorate and Invoke fail on dig.In structs with:
+//
+//   - unexported fields, even with the ignore-unexported tag set
+//   - tags that look like misspelled dig tags, such as `optionnal:"true"`,
+//     or tags that are not in the conventional key:"value" format
+//   - embedded structs which are not dig.In structs themselves
+//
+// Tags unrelated to dig, such as `json:"name"`, are left alone.
+func StrictIn() Option {
+	return strictInOption{}
+}
+
+type strictInOption struct{}
+
+func (strictInOption) String() string {
+	return "StrictIn()"
+}
+
+func (strictInOption) applyOption(c *Container) {
+	c.scope.state.strictIn = true
+}
+
+// Tags that dig reads on the fields of dig.In structs.
+var _inTags = []string{_nameTag, _optionalTag, _groupTag, _selectTag, _decoratedTag, _ignoreUnexportedTag}
+
+// isStrictIn reports whether the container was created with StrictIn.
+func isStrictIn(c containerStore) bool {
+	s, ok := c.(*Scope)
+	return ok && s.rootScope().state.strictIn
+}
+
+// checkStrictInField returns an error if the given field of a dig.In
+// struct is rejected by StrictIn.
+func checkStrictInField(f reflect.StructField) error {
+	if err := checkFieldTags(f, _inTags); err != nil {
+		return err
+	}
+
+	switch {
+	case f.Type == _inType:
+		return nil
+	case f.PkgPath != "":
+		return newErrInvalidInput(
+			fmt.Sprintf("unexported fields not allowed in dig.In with dig.StrictIn, did you mean to export %q (%v)?", f.Name, f.Type),
+			nil)
+	case f.Anonymous && !IsIn(f.Type):
+		return errEmbeddedField(f, "dig.In")
+	}
+	return nil
+}
+
+// errEmbeddedField is returned when a dig.In or dig.Out struct embeds a
+// struct of another kind.
+func errEmbeddedField(f reflect.StructField, kind string) error {
+	return newErrInvalidInput(
+		fmt.Sprintf("embedded field %v is not a %v struct, did you mean to name the field?", f.Type, kind), nil)
+}
+
+// checkFieldTags returns an error if the tag of the given field is
+// malformed or has a key that looks like a misspelling of one of the
+// known tags.
+func checkFieldTags(f reflect.StructField, known []string) error {
+	keys, ok := tagKeys(f.Tag)
+	if !ok {
+		return newErrInvalidInput(
+			fmt.Sprintf("malformed tag %q on field %v", f.Tag, f.Name), nil)
+	}
+	for _, k := range keys {
+		if tag, ok := misspelledTag(k, known); ok {
+			return newErrInvalidInput(
+				fmt.Sprintf("unknown tag %q on field %v, did you mean %q?", k, f.Name, tag), nil)
+		}
+	}
+	return nil
+}
+
+// misspelledTag returns the tag among the known ones that the given tag
+// key is a likely misspelling of, if any.
+func misspelledTag(key string, known []string) (string, bool) {
+	for _, tag := range known {
+		if key == tag {
+			return "", false
+		}
+	}
+	for _, tag := range known {
+		// Allow one typo in short tags and two in longer ones, so that
+		// unrelated tags such as yaml are not mistaken for name.
+		maxDist := 1
+		if len(tag) > 5 {
+			maxDist = 2
+		}
+		if strings.EqualFold(key, tag) || editDistance(key, tag) <= maxDist {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// tagKeys returns the keys of the given struct tag, and false if the tag
+// does not follow the key:"value" convention of reflect.StructTag.
+func tagKeys(tag reflect.StructTag) ([]string, bool) {
+	var keys []string
+	s := string(tag)
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return keys, true
+		}
+
+		i := 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' && s[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(s) || s[i] != ':' || s[i+1] != '"' {
+			return keys, false
+		}
+		key := s[:i]
+		s = s[i+1:]
+
+		// Scan the quoted value, skipping escaped characters.
+		i = 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			return keys, false
+		}
+		if _, err := strconv.Unquote(s[:i+1]); err != nil {
+			return keys, false
+		}
+		keys = append(keys, key)
+		s = s[i+1:]
+	}
+}
+
+// editDistance returns the number of single-character insertions,
+// deletions, substitutions and adjacent transpositions needed to turn a
+// into b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictConfig struct{ Addr string }
+
+func TestStrictIn(t *testing.T) {
+	t.Parallel()
+
+	type Server struct{ Addr string }
+
+	tests := []struct {
+		desc        string
+		constructor interface{}
+		wantErr     []string // empty if the constructor is valid
+	}{
+		{
+			desc: "valid",
+			constructor: func(p struct {
+				dig.In
+
+				Config *strictConfig `name:"main" optional:"true" json:"config"`
+				Other  *strictConfig `yaml:"other" optional:"true"`
+			}) *Server {
+				return &Server{}
+			},
+		},
+		{
+			desc: "nested dig.In",
+			constructor: func(p struct {
+				dig.In
+
+				Params struct {
+					dig.In
+
+					Config *strictConfig
+				}
+			}) *Server {
+				return &Server{Addr: p.Params.Config.Addr}
+			},
+		},
+		{
+			desc: "ignored unexported field",
+			constructor: func(p struct {
+				dig.In `ignore-unexported:"true"`
+
+				config *strictConfig
+			}) *Server {
+				return &Server{}
+			},
+			wantErr: []string{
+				`bad field "config"`,
+				`unexported fields not allowed in dig.In with dig.StrictIn, did you mean to export "config" (*dig_test.strictConfig)?`,
+			},
+		},
+		{
+			desc: "misspelled optional tag",
+			constructor: func(p struct {
+				dig.In
+
+				Config *strictConfig `optionnal:"true"`
+			}) *Server {
+				return &Server{}
+			},
+			wantErr: []string{
+				`bad field "Config"`,
+				`unknown tag "optionnal" on field Config, did you mean "optional"?`,
+			},
+		},
+		{
+			desc: "misspelled name tag",
+			constructor: func(p struct {
+				dig.In
+
+				Config *strictConfig `nmae:"main"`
+			}) *Server {
+				return &Server{}
+			},
+			wantErr: []string{`unknown tag "nmae" on field Config, did you mean "name"?`},
+		},
+		{
+			desc: "misspelled decorated tag",
+			constructor: func(p struct {
+				dig.In
+
+				Config *strictConfig `decorate:"false"`
+			}) *Server {
+				return &Server{}
+			},
+			wantErr: []string{`unknown tag "decorate" on field Config, did you mean "decorated"?`},
+		},
+		{
+			desc: "misspelled ignore-unexported tag",
+			constructor: func(p struct {
+				dig.In `ignore_unexported:"true"`
+			}) *Server {
+				return &Server{}
+			},
+			wantErr: []string{`unknown tag "ignore_unexported" on field In, did you mean "ignore-unexported"?`},
+		},
+		{
+			desc: "embedded non-In struct",
+			constructor: func(p struct {
+				dig.In
+
+				*strictConfig
+			}) *Server {
+				return &Server{}
+			},
+			wantErr: []string{`bad field "strictConfig"`, "unexported fields not allowed"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := digtest.New(t, dig.StrictIn())
+			c.RequireProvide(func() *strictConfig { return &strictConfig{} })
+			c.RequireProvide(func() *strictConfig { return &strictConfig{} }, dig.Name("main"))
+
+			err := c.Provide(tt.constructor)
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err)
+				c.RequireInvoke(func(*Server) {})
+				return
+			}
+
+			require.Error(t, err)
+			for _, msg := range tt.wantErr {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+
+	t.Run("embedded exported non-In struct", func(t *testing.T) {
+		t.Parallel()
+
+		type Config struct{ Addr string }
+		type Params struct {
+			dig.In
+
+			Config
+		}
+
+		c := digtest.New(t, dig.StrictIn())
+		err := c.Invoke(func(Params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "embedded field dig_test.Config is not a dig.In struct, did you mean to name the field?")
+	})
+
+	t.Run("malformed tag", func(t *testing.T) {
+		t.Parallel()
+
+		// Built at runtime since go vet rejects malformed tags.
+		params := reflect.StructOf([]reflect.StructField{
+			{Name: "In", Type: reflect.TypeOf(dig.In{}), Anonymous: true},
+			{Name: "Config", Type: reflect.TypeOf(&strictConfig{}), Tag: `name:main`},
+		})
+		fn := reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{params}, nil, false),
+			func([]reflect.Value) []reflect.Value { return nil },
+		)
+
+		c := digtest.New(t, dig.StrictIn())
+		err := c.Invoke(fn.Interface())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `malformed tag "name:main" on field Config`)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		type Params struct {
+			dig.In
+
+			Config *strictConfig `optionl:"true"`
+		}
+
+		c := digtest.New(t, dig.StrictIn())
+		err := c.Scope("child").Invoke(func(Params) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown tag "optionl" on field Config, did you mean "optional"?`)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		type Params struct {
+			dig.In `ignore-unexported:"true"`
+
+			Config *strictConfig `optionnal:"true"`
+			config *strictConfig
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *strictConfig { return &strictConfig{} })
+		c.RequireInvoke(func(p Params) {
+			assert.NotNil(t, p.Config)
+			assert.Nil(t, p.config)
+		})
+	})
+}
+
+func TestStrictInString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "StrictIn()", dig.StrictIn().(interface{ String() string }).String())
+}
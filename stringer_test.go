@@ -25,7 +25,7 @@ import (
 	"testing"
 
 	"github.com/alexisvisco/dig"
-	"github.com/alexisvisco/dig/internal/digtest"
+	"github.com/alexisvisco/dig/digtest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// Struct returns a constructor of *T, for a struct type T, that fills the
+// exported fields of T from the container, as if T were a dig.In struct.
+// The fields support the same tags as the fields of dig.In structs, like
+// name, group and optional. Unexported fields are left zero.
+//
+//	type Handlers struct {
+//	  Users  *UserHandler
+//	  Orders *OrderHandler
+//	  Admin  *AdminHandler `optional:"true"`
+//	}
+//
+//	c.Provide(dig.Struct[Handlers]())
+//
+// is equivalent to providing a hand-written constructor taking a dig.In
+// struct with the fields of Handlers, and returning a *Handlers holding its
+// values. If T is a pointer to a struct, the constructor returns T.
+//
+// The constructor may be given to Provide along with any ProvideOption.
+// Errors report the location where Struct was called.
+func Struct[T any]() interface{} {
+	pc, _, _, _ := runtime.Caller(1)
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Struct {
+		t = reflect.PointerTo(t)
+	}
+	return structConstructor(t, digreflect.InspectFuncPC(pc))
+}
+
+// ProvideStruct provides a constructor of the type of prototype, which must
+// be a pointer to a struct, that fills the exported fields of the struct
+// from the container. The value of prototype is ignored. See Struct.
+//
+//	err := c.ProvideStruct(&Handlers{})
+func (c *Container) ProvideStruct(prototype interface{}, opts ...ProvideOption) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return c.scope.provideStruct(pc, prototype, opts)
+}
+
+// ProvideStruct provides a constructor of the type of prototype, which must
+// be a pointer to a struct, to the Scope. See Container.ProvideStruct.
+func (s *Scope) ProvideStruct(prototype interface{}, opts ...ProvideOption) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return s.provideStruct(pc, prototype, opts)
+}
+
+func (s *Scope) provideStruct(pc uintptr, prototype interface{}, opts []ProvideOption) error {
+	t := reflect.TypeOf(prototype)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return newErrInvalidInput(fmt.Sprintf(
+			"can't provide struct %v (type %T): must be a pointer to a struct", prototype, prototype), nil)
+	}
+	return s.Provide(structConstructor(t, digreflect.InspectFuncPC(pc)), opts...)
+}
+
+// structConstructor returns a constructor of t, a pointer to a struct,
+// reporting the given location. The constructor takes a dig.In struct with
+// the exported fields of the struct, and copies them to a new struct.
+func structConstructor(t reflect.Type, location *digreflect.Func) interface{} {
+	a := annotated{Location: location}
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		a.err = newErrInvalidInput(fmt.Sprintf(
+			"cannot build a constructor of %v: must be a struct or a pointer to a struct", t), nil)
+		return a
+	}
+
+	st := t.Elem()
+	fields := []reflect.StructField{{Name: _inType.Name(), Type: _inType, Anonymous: true}}
+	var indexes []int
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if f.PkgPath != "" || f.Type == _inType {
+			continue
+		}
+		name := f.Name
+		if name == _inType.Name() {
+			// Avoid colliding with the embedded dig.In.
+			name = fmt.Sprintf("Field%d", i)
+		}
+		fields = append(fields, reflect.StructField{Name: name, Type: f.Type, Tag: f.Tag})
+		indexes = append(indexes, i)
+	}
+	in := reflect.StructOf(fields)
+
+	ctype := reflect.FuncOf([]reflect.Type{in}, []reflect.Type{t}, false /* variadic */)
+	a.Target = reflect.MakeFunc(ctype, func(args []reflect.Value) []reflect.Value {
+		v := reflect.New(st)
+		for j, i := range indexes {
+			v.Elem().Field(i).Set(args[0].Field(j + 1))
+		}
+		return []reflect.Value{v}
+	}).Interface()
+	return a
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStruct(t *testing.T) {
+	t.Parallel()
+
+	type Users struct{}
+	type Orders struct{}
+	type Handlers struct {
+		Users   *Users
+		Orders  *Orders  `name:"orders"`
+		Admin   *string  `optional:"true"`
+		Routes  []string `group:"routes"`
+		In      int
+		private int
+	}
+
+	provide := func(c *digtest.Container) {
+		c.RequireProvide(func() *Users { return &Users{} })
+		c.RequireProvide(func() *Orders { return &Orders{} }, dig.Name("orders"))
+		c.RequireProvide(func() string { return "/users" }, dig.Group("routes"))
+		c.RequireProvide(func() int { return 42 })
+	}
+
+	check := func(t *testing.T, h *Handlers) {
+		assert.NotNil(t, h.Users)
+		assert.NotNil(t, h.Orders)
+		assert.Nil(t, h.Admin)
+		assert.Equal(t, []string{"/users"}, h.Routes)
+		assert.Equal(t, 42, h.In)
+		assert.Zero(t, h.private)
+	}
+
+	t.Run("Struct", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provide(c)
+		c.RequireProvide(dig.Struct[Handlers]())
+		c.RequireInvoke(func(h *Handlers) { check(t, h) })
+	})
+
+	t.Run("Struct of a pointer", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provide(c)
+		c.RequireProvide(dig.Struct[*Handlers](), dig.Name("handlers"))
+
+		type params struct {
+			dig.In
+
+			Handlers *Handlers `name:"handlers"`
+		}
+		c.RequireInvoke(func(p params) { check(t, p.Handlers) })
+	})
+
+	t.Run("ProvideStruct", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		provide(c)
+		require.NoError(t, c.ProvideStruct(&Handlers{}))
+		c.RequireInvoke(func(h *Handlers) { check(t, h) })
+
+		child := c.Scope("child")
+		require.NoError(t, child.ProvideStruct(&struct{ Users *Users }{}))
+	})
+
+	t.Run("missing fields report the call site", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(dig.Struct[Handlers]())
+		err := c.Invoke(func(*Handlers) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TestStruct")
+		assert.Contains(t, err.Error(), "missing type")
+	})
+
+	t.Run("invalid types", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Provide(dig.Struct[int]())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot build a constructor of int: must be a struct or a pointer to a struct")
+		assert.NotContains(t, err.Error(), "invalid annotations")
+
+		err = c.ProvideStruct(Handlers{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be a pointer to a struct")
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/dot"
+)
+
+// Style holds the Graphviz attributes a node or an edge of the graph
+// rendered by Visualize is drawn with. Empty attributes are left out, so
+// that Graphviz draws them with its defaults.
+//
+// Colors and shapes are given as Graphviz names or values, such as "red",
+// "#ff0000" or "box". Style is the Graphviz style, such as "dashed" or
+// "filled,bold", and ArrowHead the shape of the head of an edge. Tooltip is
+// shown by the viewers of SVG output.
+type Style struct {
+	Color     string
+	Shape     string
+	Style     string
+	ArrowHead string
+	Tooltip   string
+}
+
+// StyleKind is the kind of an element of the graph rendered by Visualize.
+type StyleKind int
+
+const (
+	// StyleConstructor is a constructor. Its shape applies to its name,
+	// and its other attributes to the cluster holding it and its results.
+	StyleConstructor StyleKind = iota
+
+	// StyleDecorator is a decorator.
+	StyleDecorator
+
+	// StyleValue is a value produced by a constructor, or missing from the
+	// container.
+	StyleValue
+
+	// StyleGroup is a value group.
+	StyleGroup
+
+	// StyleDependency is an edge from a constructor or a decorator to a
+	// value or a value group it depends on.
+	StyleDependency
+
+	// StyleGroupMember is an edge from a value group to one of its values.
+	StyleGroupMember
+
+	// StyleDecoration is an edge from a decorator to a value or a value
+	// group it decorates.
+	StyleDecoration
+
+	// StyleSuggestion is an edge from a missing value to a value of the
+	// container it may have been meant as.
+	StyleSuggestion
+)
+
+func (k StyleKind) String() string {
+	switch k {
+	case StyleConstructor:
+		return "StyleConstructor"
+	case StyleDecorator:
+		return "StyleDecorator"
+	case StyleValue:
+		return "StyleValue"
+	case StyleGroup:
+		return "StyleGroup"
+	case StyleDependency:
+		return "StyleDependency"
+	case StyleGroupMember:
+		return "StyleGroupMember"
+	case StyleDecoration:
+		return "StyleDecoration"
+	case StyleSuggestion:
+		return "StyleSuggestion"
+	default:
+		return fmt.Sprintf("StyleKind(%d)", int(k))
+	}
+}
+
+// StyleElement describes a node or an edge of the graph rendered by
+// Visualize to a StyleFunc.
+type StyleElement struct {
+	Kind StyleKind
+
+	// Type, Name and Group identify the value or the value group of the
+	// element: the one a node stands for, or the one an edge points to.
+	// Type is the type of the values of value groups.
+	Type  reflect.Type
+	Name  string
+	Group string
+
+	// Constructor, Package and Module describe the constructor or the
+	// decorator of the element, if any: the one a node stands for, the
+	// one producing a value, or the one an edge starts from.
+	Constructor string
+	Package     string
+	Module      string
+
+	// Optional is true for the edges of optional dependencies.
+	Optional bool
+
+	// Failed is true for the constructors, values and value groups that
+	// failed to build in the error given to VisualizeError, and RootCause
+	// for the ones that are the root cause of the error.
+	Failed    bool
+	RootCause bool
+}
+
+// A StyleFunc customizes the Style of an element of the graph rendered by
+// Visualize. It is given the Style the element is drawn with by default.
+type StyleFunc func(StyleElement, *Style)
+
+// VisualizeStyle is a VisualizeOption that customizes the attributes of
+// the nodes and the edges of the DOT graph with f.
+//
+//	dig.Visualize(c, w, dig.VisualizeStyle(func(e dig.StyleElement, s *dig.Style) {
+//		if e.Kind == dig.StyleConstructor && e.Module == "storage" {
+//			s.Color = "darkgreen"
+//		}
+//	}))
+//
+// The default Styles draw failed elements in red or orange, supplied values
+// as parallelograms and dashed edges for optional dependencies.
+//
+// Styled graphs hold an attribute list for every node, in a fixed order,
+// so their output differs from the graphs drawn without VisualizeStyle,
+// which are left unchanged. This option only affects the DOT format.
+func VisualizeStyle(f StyleFunc) VisualizeOption {
+	return visualizeStyleOption{f}
+}
+
+type visualizeStyleOption struct{ f StyleFunc }
+
+func (o visualizeStyleOption) String() string {
+	return fmt.Sprintf("VisualizeStyle(%p)", o.f)
+}
+
+func (o visualizeStyleOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.Style = o.f
+}
+
+// styler adapts the StyleFunc to the elements of the DOT graph.
+func (f StyleFunc) styler() func(dot.Element, *dot.Style) {
+	if f == nil {
+		return nil
+	}
+	return func(e dot.Element, s *dot.Style) {
+		el := StyleElement{
+			Kind:      StyleKind(e.Kind),
+			Optional:  e.Optional,
+			Failed:    e.Failed(),
+			RootCause: e.RootCause(),
+		}
+		switch {
+		case e.Node != nil:
+			el.Type, el.Name, el.Group = e.Node.Type, e.Node.Name, e.Node.Group
+		case e.Group != nil:
+			el.Type, el.Group = e.Group.Type, e.Group.Name
+		}
+		switch {
+		case e.Ctor != nil:
+			el.Constructor, el.Package, el.Module = e.Ctor.Name, e.Ctor.Package, e.Ctor.Module
+		case e.Decorator != nil:
+			el.Constructor, el.Package = e.Decorator.Name, e.Decorator.Package
+		}
+
+		style := Style(*s)
+		f(el, &style)
+		*s = dot.Style(style)
+	}
+}
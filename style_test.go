@@ -0,0 +1,147 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisualizeStyle(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+	type t3 struct{}
+
+	t.Parallel()
+
+	t.Run("elements", func(t *testing.T) {
+		t.Parallel()
+
+		type in struct {
+			dig.In
+
+			T1 t1   `optional:"true"`
+			T2 []t2 `group:"g"`
+		}
+
+		c := digtest.New(t)
+		m := dig.NewModule("storage")
+		m.Provide(func() t1 { return t1{} }, dig.Export(true))
+		require.NoError(t, c.Apply(m))
+		c.RequireProvide(func() t2 { return t2{} }, dig.Group("g"))
+		c.RequireProvide(func(in) t3 { return t3{} })
+		c.RequireDecorate(func(t1) t1 { return t1{} })
+
+		var elements []dig.StyleElement
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b, dig.VisualizeStyle(func(e dig.StyleElement, s *dig.Style) {
+			elements = append(elements, e)
+			switch {
+			case e.Kind == dig.StyleConstructor && e.Module == "storage":
+				s.Color = "darkgreen"
+				s.Tooltip = "provided by " + e.Module
+			case e.Kind == dig.StyleValue && e.Type == reflect.TypeOf(t3{}):
+				s.Shape = "box"
+				s.Color = "#336699"
+			case e.Kind == dig.StyleDependency && e.Optional:
+				s.Style = "dotted"
+			case e.Kind == dig.StyleGroup:
+				s.Shape = ""
+			}
+		})))
+
+		out := b.String()
+		assert.Contains(t, out, `color=darkgreen;tooltip="provided by storage";`)
+		assert.Contains(t, out, `"dig_test.t3" [shape=box label=<dig_test.t3> color="#336699"];`)
+		assert.Contains(t, out, `-> "dig_test.t1" [ltail=cluster_2 style=dotted];`)
+		assert.Contains(t, out, `"[type=dig_test.t2 group=g]" [label=<dig_test.t2<BR /><FONT POINT-SIZE="10">Group: g</FONT>>];`)
+		assert.Contains(t, out, `decorator_0 [shape=box label=`)
+
+		kinds := make(map[dig.StyleKind]int)
+		for _, e := range elements {
+			kinds[e.Kind]++
+			assert.False(t, e.Failed, "%v must not be failed", e.Kind)
+		}
+		assert.Equal(t, map[dig.StyleKind]int{
+			dig.StyleConstructor: 3,
+			dig.StyleDecorator:   1,
+			dig.StyleValue:       3,
+			dig.StyleGroup:       1,
+			dig.StyleDependency:  3,
+			dig.StyleGroupMember: 1,
+			dig.StyleDecoration:  1,
+		}, kinds)
+
+		for _, e := range elements {
+			if e.Kind == dig.StyleDependency && e.Group != "" {
+				assert.Equal(t, reflect.TypeOf(t2{}), e.Type)
+				assert.Equal(t, "g", e.Group)
+				assert.Equal(t, "github.com/alexisvisco/dig_test", e.Package)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(t1) (t2, error) { return t2{}, errors.New("great sadness") })
+		c.RequireProvide(func(t2) t3 { return t3{} })
+		err := c.Invoke(func(t3) {})
+		require.Error(t, err)
+
+		failed := make(map[reflect.Type]bool)
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b,
+			dig.VisualizeError(err),
+			dig.VisualizeStyle(func(e dig.StyleElement, s *dig.Style) {
+				if e.Kind == dig.StyleValue && e.Failed {
+					failed[e.Type] = e.RootCause
+					if e.RootCause {
+						s.Color = "purple"
+					}
+				}
+			})))
+		assert.Equal(t, map[reflect.Type]bool{
+			reflect.TypeOf(t1{}): true,
+			reflect.TypeOf(t2{}): false,
+			reflect.TypeOf(t3{}): false,
+		}, failed)
+		assert.Contains(t, b.String(), `"dig_test.t1" [color=purple];`)
+		assert.Contains(t, b.String(), `"dig_test.t2" [label=<dig_test.t2> color=orange];`)
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Contains(t, fmt.Sprint(dig.VisualizeStyle(func(dig.StyleElement, *dig.Style) {})), "VisualizeStyle(0x")
+		assert.Equal(t, "StyleDecoration", dig.StyleDecoration.String())
+		assert.Equal(t, "StyleKind(42)", dig.StyleKind(42).String())
+	})
+}
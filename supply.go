@@ -0,0 +1,301 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+	"github.com/alexisvisco/dig/internal/dot"
+)
+
+// Supply adds values that were already built to the container, as if they
+// were returned by a constructor without dependencies.
+//
+//	err := c.Supply(cfg, logger)
+//
+// is equivalent to
+//
+//	err := c.Provide(func() (*Config, *zap.Logger) { return cfg, logger })
+//
+// ProvideOptions may be given among the values, in which case they apply to
+// all the values of the call. For example,
+//
+//	err := c.Supply(&bytes.Buffer{}, dig.As(new(io.Writer)))
+//	err := c.Supply(handler, dig.Group("handlers"))
+//
+// The type of each value is its dynamic type: to supply a value as an
+// interface, use the As option. Values may be dig.Out structs, but not
+// errors.
+//
+// Supplied values appear in the graph rendered by Visualize as value nodes
+// rather than constructors.
+func (c *Container) Supply(values ...interface{}) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return c.scope.supply(pc, values)
+}
+
+// Supply adds values that were already built to the Scope, as if they were
+// returned by a constructor without dependencies. See Container.Supply for
+// more details.
+func (s *Scope) Supply(values ...interface{}) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return s.supply(pc, values)
+}
+
+func (s *Scope) supply(pc uintptr, args []interface{}) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	var (
+		options provideOptions
+		values  []reflect.Value
+	)
+	for _, arg := range args {
+		if o, ok := arg.(ProvideOption); ok {
+			o.applyProvideOption(&options)
+			continue
+		}
+		values = append(values, reflect.ValueOf(arg))
+	}
+	if options.Location == nil {
+		options.Location = digreflect.InspectFuncPC(pc)
+	}
+
+	if err := s.supplyValues(values, options); err != nil {
+		return errSupply{Func: options.Location, Reason: err}
+	}
+	return nil
+}
+
+func (s *Scope) supplyValues(values []reflect.Value, options provideOptions) error {
+	if len(values) == 0 {
+		return newErrInvalidInput("must supply at least one value", nil)
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if !s.resolveProvideOptions(&options) {
+		return nil
+	}
+
+	for _, v := range values {
+		if !v.IsValid() {
+			return newErrInvalidInput("can't supply an untyped nil", nil)
+		}
+		if isError(v.Type()) {
+			return newErrInvalidInput(fmt.Sprintf("can't supply an error: %v", v.Interface()), nil)
+		}
+	}
+
+	return s.supplyValuesAs(&suppliedValues{values: values}, options)
+}
+
+// supplyValuesAs provides a constructor returning the given supplied values.
+func (s *Scope) supplyValuesAs(sv *suppliedValues, options provideOptions) error {
+	types := make([]reflect.Type, len(sv.values))
+	for i, v := range sv.values {
+		types[i] = v.Type()
+	}
+	ctor := reflect.MakeFunc(reflect.FuncOf(nil, types, false), sv.call)
+	options.Supplied = sv
+	return s.provide(ctor.Interface(), options)
+}
+
+// SupplyNamed adds a value of a primitive type, such as a string, a number
+// or a bool, to the container under the given name. This lets small
+// configuration values flow through the container without defining a type
+// for each of them.
+//
+//	err := c.SupplyNamed("http.port", 8080)
+//
+// The value is consumed by its name, with the exact type it was supplied
+// with:
+//
+//	type ServerParams struct {
+//	  dig.In
+//
+//	  Port int `name:"http.port"`
+//	}
+//
+// A name may be supplied only once to a Scope, its ancestors and its
+// descendants, whatever the types of the values: supplying it again fails
+// with the location of the first call. Use NamedValues to list the values
+// supplied with SupplyNamed.
+func (c *Container) SupplyNamed(name string, value interface{}) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return c.scope.supplyNamed(pc, name, value)
+}
+
+// SupplyNamed adds a value of a primitive type to the Scope under the given
+// name. See Container.SupplyNamed for more details.
+func (s *Scope) SupplyNamed(name string, value interface{}) error {
+	pc, _, _, _ := runtime.Caller(1)
+	return s.supplyNamed(pc, name, value)
+}
+
+func (s *Scope) supplyNamed(pc uintptr, name string, value interface{}) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	location := digreflect.InspectFuncPC(pc)
+	if err := s.checkNamedValue(name, value); err != nil {
+		return errSupply{Func: location, Reason: err}
+	}
+
+	options := provideOptions{Name: name, Location: location}
+	if err := options.Validate(); err != nil {
+		return errSupply{Func: location, Reason: err}
+	}
+	sv := &suppliedValues{values: []reflect.Value{reflect.ValueOf(value)}, name: name}
+	if err := s.supplyValuesAs(sv, options); err != nil {
+		return errSupply{Func: location, Reason: err}
+	}
+	return nil
+}
+
+// checkNamedValue returns an error if the given value cannot be supplied
+// with the given name by SupplyNamed.
+func (s *Scope) checkNamedValue(name string, value interface{}) error {
+	if name == "" {
+		return newErrInvalidInput("cannot supply a value with an empty name", nil)
+	}
+	t := reflect.TypeOf(value)
+	if t == nil {
+		return newErrInvalidInput("can't supply an untyped nil", nil)
+	}
+	if !isPrimitive(t) {
+		return newErrInvalidInput(fmt.Sprintf(
+			"SupplyNamed supports values of primitive types only: %q is a %v, use Supply with dig.Name instead", name, t), nil)
+	}
+
+	// Names supplied to ancestors are visible to the Scope, and names supplied
+	// to descendants would be shadowed.
+	scopes := s.ancestors()
+	scopes = append(scopes, s.appendSubscopes(nil)[1:]...)
+	for _, ss := range scopes {
+		for _, n := range ss.nodes {
+			if n.supplied != nil && n.supplied.name == name {
+				return newErrInvalidInput(fmt.Sprintf(
+					"name %q was already supplied with SupplyNamed at %v", name, n.location), nil)
+			}
+		}
+	}
+	return nil
+}
+
+// isPrimitive reports whether t is a boolean, numeric or string type.
+func isPrimitive(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// NamedValue is a value supplied to a container with SupplyNamed.
+type NamedValue struct {
+	// Name the value was supplied with.
+	Name string
+
+	// Value as it was supplied.
+	Value interface{}
+
+	// Location of the SupplyNamed call.
+	Location *digreflect.Func
+}
+
+// NamedValues returns the values supplied to the container with
+// SupplyNamed, sorted by name. Values supplied to its Scopes are not
+// included.
+func (c *Container) NamedValues() []NamedValue {
+	return c.scope.NamedValues()
+}
+
+// NamedValues returns the values supplied with SupplyNamed that are visible
+// to the Scope, including the ones supplied to its ancestors, sorted by
+// name.
+func (s *Scope) NamedValues() []NamedValue {
+	var values []NamedValue
+	for _, ss := range s.ancestors() {
+		for _, n := range ss.nodes {
+			if n.supplied != nil && n.supplied.name != "" {
+				values = append(values, NamedValue{
+					Name:     n.supplied.name,
+					Value:    n.supplied.values[0].Interface(),
+					Location: n.location,
+				})
+			}
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+// suppliedValues holds the values given to a Supply call, and is the
+// identity of the constructor built for them.
+type suppliedValues struct {
+	values []reflect.Value
+
+	// Name of the value given to SupplyNamed, if any.
+	name string
+
+	// Whether the value was returned by the missing handler of the
+	// container. See WithMissingHandler.
+	missing bool
+}
+
+func (sv *suppliedValues) call([]reflect.Value) []reflect.Value {
+	return sv.values
+}
+
+func (sv *suppliedValues) id() dot.CtorID {
+	return dot.CtorID(reflect.ValueOf(sv).Pointer())
+}
+
+// errSupply is returned when values could not be supplied to the container.
+type errSupply struct {
+	Func   *digreflect.Func
+	Reason error
+}
+
+var _ digError = errSupply{}
+
+func (e errSupply) Error() string { return fmt.Sprint(e) }
+
+func (e errSupply) Unwrap() error { return e.Reason }
+
+func (e errSupply) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "cannot supply values at "+verb, e.Func)
+}
+
+func (e errSupply) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
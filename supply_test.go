@@ -0,0 +1,361 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupply(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ name string }
+	type B struct{ a *A }
+
+	t.Run("values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		a := &A{name: "a"}
+		require.NoError(t, c.Supply(a, "foo", 42))
+		c.RequireProvide(func(a *A) *B { return &B{a: a} })
+
+		c.RequireInvoke(func(b *B, s string, i int) {
+			assert.Same(t, a, b.a)
+			assert.Equal(t, "foo", s)
+			assert.Equal(t, 42, i)
+		})
+	})
+
+	t.Run("name", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Supply("primary", dig.Name("primary")))
+		require.NoError(t, c.Supply("replica", dig.Name("replica")))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Primary string `name:"primary"`
+			Replica string `name:"replica"`
+		}) {
+			assert.Equal(t, "primary", p.Primary)
+			assert.Equal(t, "replica", p.Replica)
+		})
+	})
+
+	t.Run("keyed", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Supply(&A{name: "prod"}, dig.Keyed(keyProd)))
+		require.NoError(t, c.Supply(&A{name: "unnamed"}))
+
+		c.RequireInvoke(dig.Annotate(func(prod, unnamed *A) {
+			assert.Equal(t, "prod", prod.name)
+			assert.Equal(t, "unnamed", unnamed.name)
+		}, dig.KeyedParam(0, keyProd)))
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Supply(&A{name: "eu"}, dig.Labels("region=eu")))
+		require.NoError(t, c.Supply(&A{name: "us"}, dig.Labels("region=us", "tier=hot")))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			EU *A `select:"region=eu"`
+			US *A `select:"tier=hot"`
+		}) {
+			assert.Equal(t, "eu", p.EU.name)
+			assert.Equal(t, "us", p.US.name)
+		})
+	})
+
+	t.Run("profiles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ActiveProfiles("dev"))
+		require.NoError(t, c.Supply(&A{name: "prod"}, dig.Profile("prod")))
+		require.NoError(t, c.Supply(&A{name: "dev"}, dig.Profile("dev")))
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "dev", a.name)
+		})
+
+		c = digtest.New(t)
+		require.NoError(t, c.Supply(&A{name: "prod"}, dig.Profile("prod")))
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("group", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.Supply(1, dig.Group("values")))
+		require.NoError(t, c.Supply(2, dig.Group("values")))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Values []int `group:"values"`
+		}) {
+			assert.ElementsMatch(t, []int{1, 2}, p.Values)
+		})
+	})
+
+	t.Run("as", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		buf := new(bytes.Buffer)
+		require.NoError(t, c.Supply(buf, dig.As(new(io.Reader), new(io.Writer))))
+
+		c.RequireInvoke(func(r io.Reader, w io.Writer) {
+			assert.Same(t, buf, r)
+			assert.Same(t, buf, w)
+		})
+	})
+
+	t.Run("result object", func(t *testing.T) {
+		t.Parallel()
+
+		type out struct {
+			dig.Out
+
+			A *A `name:"a"`
+		}
+
+		c := digtest.New(t)
+		require.NoError(t, c.Supply(out{A: &A{name: "a"}}))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			A *A `name:"a"`
+		}) {
+			assert.Equal(t, "a", p.A.name)
+		})
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		require.NoError(t, child.Supply(&A{name: "child"}))
+
+		child.RequireInvoke(func(a *A) {
+			assert.Equal(t, "child", a.name)
+		})
+		assert.Error(t, c.Invoke(func(*A) {}))
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Supply(dig.Name("foo"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must supply at least one value")
+		assert.Contains(t, err.Error(), "cannot supply values at")
+		assert.Contains(t, err.Error(), "supply_test.go")
+	})
+
+	t.Run("untyped nil", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Supply(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't supply an untyped nil")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Supply(errors.New("great sadness"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't supply an error: great sadness")
+	})
+
+	t.Run("already provided", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{} })
+
+		err := c.Supply(&A{}, "foo")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already provided")
+
+		// Supply is atomic: none of the values were added.
+		assert.Error(t, c.Invoke(func(string) {}))
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Supply("foo", dig.Name("`"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.Name")
+	})
+
+	t.Run("distinct identities", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		var infoA, infoB dig.ProvideInfo
+		require.NoError(t, c.Supply(&A{}, dig.FillProvideInfo(&infoA)))
+		require.NoError(t, c.Supply(&B{}, dig.FillProvideInfo(&infoB)))
+		assert.NotEqual(t, infoA.ID, infoB.ID)
+	})
+}
+
+func TestSupplyNamed(t *testing.T) {
+	t.Parallel()
+
+	type Port int
+
+	t.Run("consumed by name", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.SupplyNamed("http.port", 8080))
+		require.NoError(t, c.SupplyNamed("http.host", "localhost"))
+		require.NoError(t, c.SupplyNamed("http.tls", true))
+		require.NoError(t, c.SupplyNamed("grpc.port", Port(9090)))
+
+		c.RequireInvoke(func(p struct {
+			dig.In
+
+			Port     int    `name:"http.port"`
+			Host     string `name:"http.host"`
+			TLS      bool   `name:"http.tls"`
+			GRPCPort Port   `name:"grpc.port"`
+		}) {
+			assert.Equal(t, 8080, p.Port)
+			assert.Equal(t, "localhost", p.Host)
+			assert.True(t, p.TLS)
+			assert.Equal(t, Port(9090), p.GRPCPort)
+		})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.SupplyNamed("http.port", 8080))
+		require.NoError(t, c.SupplyNamed("db.url", "postgres://"))
+		require.NoError(t, c.Supply("unnamed"))
+
+		child := c.Scope("child")
+		require.NoError(t, child.SupplyNamed("child.value", 1.5))
+
+		values := c.NamedValues()
+		require.Len(t, values, 2)
+		assert.Equal(t, "db.url", values[0].Name)
+		assert.Equal(t, "postgres://", values[0].Value)
+		assert.Equal(t, "http.port", values[1].Name)
+		assert.Equal(t, 8080, values[1].Value)
+		assert.Equal(t, "TestSupplyNamed.func2", values[1].Location.Name)
+
+		var names []string
+		for _, v := range child.NamedValues() {
+			names = append(names, v.Name)
+		}
+		assert.Equal(t, []string{"child.value", "db.url", "http.port"}, names)
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		require.NoError(t, c.SupplyNamed("http.port", 8080))
+
+		err := c.SupplyNamed("http.port", "8080")
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			"cannot supply values at",
+			`name "http.port" was already supplied with SupplyNamed at .*TestSupplyNamed.func3`)
+
+		child := c.Scope("child")
+		err = child.SupplyNamed("http.port", 80)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `name "http.port" was already supplied with SupplyNamed`)
+
+		require.NoError(t, child.SupplyNamed("child.port", 80))
+		err = c.SupplyNamed("child.port", 80)
+		require.Error(t, err, "names supplied to descendants must not be shadowed")
+		require.NoError(t, c.Scope("sibling").SupplyNamed("child.port", 80))
+	})
+
+	t.Run("collision with Provide", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.Name("http.port"))
+
+		err := c.SupplyNamed("http.port", 8080)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already provided")
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+
+		err := c.SupplyNamed("", 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot supply a value with an empty name")
+
+		err = c.SupplyNamed("nil", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't supply an untyped nil")
+
+		err = c.SupplyNamed("buffer", &bytes.Buffer{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			`SupplyNamed supports values of primitive types only: "buffer" is a *bytes.Buffer, use Supply with dig.Name instead`)
+
+		err = c.SupplyNamed("`bad`", 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid dig.Name")
+
+		assert.Empty(t, c.NamedValues())
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"time"
+)
+
+// A TimeoutOption is both an Option and a ProvideOption.
+type TimeoutOption interface {
+	Option
+	ProvideOption
+}
+
+// ConstructorTimeout limits how long constructors may run. Given to New, it
+// applies to every constructor of the Container and its Scopes. Given to
+// Provide, it applies to that constructor only, replacing the timeout of
+// the Container.
+//
+//	c := dig.New(dig.ConstructorTimeout(5 * time.Second))
+//	c.Provide(connectDB, dig.ConstructorTimeout(time.Minute))
+//
+// A constructor that has not returned when its timeout elapses fails with
+// an error matching [context.DeadlineExceeded] with errors.Is. The
+// constructor cannot be stopped: it keeps running on a separate goroutine,
+// and the values it eventually returns are discarded.
+//
+// A timeout of zero or less, given to Provide, lets the constructor run for
+// as long as it needs even if the Container has a timeout.
+func ConstructorTimeout(d time.Duration) TimeoutOption {
+	return constructorTimeoutOption(d)
+}
+
+type constructorTimeoutOption time.Duration
+
+func (o constructorTimeoutOption) String() string {
+	return fmt.Sprintf("ConstructorTimeout(%v)", time.Duration(o))
+}
+
+func (o constructorTimeoutOption) applyOption(c *Container) {
+	c.scope.constructorTimeout = time.Duration(o)
+}
+
+func (o constructorTimeoutOption) applyProvideOption(opts *provideOptions) {
+	d := time.Duration(o)
+	opts.Timeout = &d
+}
+
+// timeoutInvoker returns an invokerFn that calls functions with invoke on a
+// separate goroutine, and returns no results if they don't return within d.
+// timedOut is set when that happens.
+//
+// Panics are propagated to the calling goroutine as goroutinePanic values.
+func timeoutInvoker(invoke invokerFn, d time.Duration, timedOut *bool) invokerFn {
+	return func(fn reflect.Value, args []reflect.Value) []reflect.Value {
+		type outcome struct {
+			results []reflect.Value
+			panic   *goroutinePanic
+		}
+
+		// Buffered so that the goroutine of a constructor that timed out
+		// does not leak once it returns.
+		done := make(chan outcome, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					done <- outcome{panic: &goroutinePanic{Value: p, Stack: debug.Stack()}}
+				}
+			}()
+			done <- outcome{results: invoke(fn, args)}
+		}()
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case o := <-done:
+			if o.panic != nil {
+				panic(*o.panic)
+			}
+			return o.results
+		case <-timer.C:
+			*timedOut = true
+			return nil
+		}
+	}
+}
+
+// goroutinePanic is a panic recovered on another goroutine, raised again
+// on the goroutine waiting for it.
+type goroutinePanic struct {
+	Value interface{}
+	Stack []byte
+}
+
+// errConstructorTimedOut is returned when a constructor does not return
+// within its timeout.
+type errConstructorTimedOut struct {
+	Timeout time.Duration
+}
+
+var _ digError = errConstructorTimedOut{}
+
+func (e errConstructorTimedOut) Error() string { return fmt.Sprint(e) }
+
+func (e errConstructorTimedOut) Unwrap() error { return context.DeadlineExceeded }
+
+func (e errConstructorTimedOut) writeMessage(w io.Writer, _ string) {
+	fmt.Fprintf(w, "did not return within %v", e.Timeout)
+}
+
+func (e errConstructorTimedOut) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructorTimeout(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	t.Run("container timeout", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		c := digtest.New(t, dig.ConstructorTimeout(10*time.Millisecond))
+		c.RequireProvide(func() *A {
+			<-release
+			return &A{}
+		})
+		c.RequireProvide(func() *B { return &B{} })
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "did not return within 10ms")
+
+		// Constructors that return in time are unaffected.
+		c.RequireInvoke(func(*B) {})
+	})
+
+	t.Run("provide timeout replaces that of the container", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConstructorTimeout(time.Millisecond))
+		c.RequireProvide(func() *A {
+			time.Sleep(20 * time.Millisecond)
+			return &A{}
+		}, dig.ConstructorTimeout(time.Minute))
+		c.RequireProvide(func() *B {
+			time.Sleep(20 * time.Millisecond)
+			return &B{}
+		}, dig.ConstructorTimeout(0))
+
+		c.RequireInvoke(func(*A, *B) {})
+	})
+
+	t.Run("provide timeout", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		c := digtest.New(t)
+		child := c.Scope("child")
+		child.RequireProvide(func() (*A, error) {
+			<-release
+			return &A{}, nil
+		}, dig.ConstructorTimeout(10*time.Millisecond))
+
+		err := child.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("panics are recovered", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.ConstructorTimeout(time.Minute))
+		c.RequireProvide(func() *A { panic("great sadness") })
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, dig.ErrConstructorPanicked)
+
+		var pe dig.PanicError
+		require.True(t, errors.As(err, &pe), "expected a PanicError, got %v", err)
+		assert.Equal(t, "great sadness", pe.Panic)
+		assert.Contains(t, string(pe.Stack), "TestConstructorTimeout")
+	})
+}
+
+func TestConstructorPanicked(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stack is attached", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { panic("great sadness") })
+
+		err := c.Invoke(func(int) {})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, dig.ErrConstructorPanicked)
+
+		var pe dig.PanicError
+		require.True(t, errors.As(err, &pe), "expected a PanicError, got %v", err)
+		assert.Contains(t, string(pe.Stack), "TestConstructorPanicked")
+
+		// The container is still usable afterwards.
+		c.RequireProvide(func() string { return "hello" })
+		c.RequireInvoke(func(string) {})
+	})
+
+	t.Run("panics in invoked functions are not matched", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.RecoverFromPanics())
+		err := c.Invoke(func() { panic("great sadness") })
+		require.Error(t, err)
+
+		var pe dig.PanicError
+		require.True(t, errors.As(err, &pe), "expected a PanicError, got %v", err)
+		assert.NotErrorIs(t, err, dig.ErrConstructorPanicked)
+	})
+}
+
+func TestConstructorTimeoutString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "ConstructorTimeout(1.5s)", fmt.Sprint(dig.ConstructorTimeout(1500*time.Millisecond)))
+}
@@ -0,0 +1,361 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithTrace is an InvokeOption that records into trace how the
+// dependencies of the invoked function were built: the constructors that
+// were called, in the order they were called, how long they took, the
+// values that were served from the cache, and the Scopes the constructors
+// belong to.
+//
+//	var trace dig.Trace
+//	err := c.Invoke(run, dig.WithTrace(&trace))
+//	trace.WriteJSON(os.Stdout)
+//
+// The trace is filled in once the dependencies of the function are built,
+// even if building them failed.
+func WithTrace(trace *Trace) InvokeOption {
+	return withTraceOption{trace}
+}
+
+type withTraceOption struct{ trace *Trace }
+
+func (o withTraceOption) String() string {
+	return fmt.Sprintf("WithTrace(%p)", o.trace)
+}
+
+func (o withTraceOption) applyInvokeOption(opts *invokeOptions) {
+	opts.Trace = o.trace
+}
+
+// Trace is the execution trace of an Invoke, recorded with WithTrace.
+type Trace struct {
+	// Function is the name of the invoked function, and ScopePath the
+	// names of the Scopes, starting below the container, leading to the
+	// Scope it was invoked from.
+	Function  string
+	ScopePath []string
+
+	// Start is when the Invoke started building the dependencies of the
+	// function, and Duration how long it took. Duration does not include
+	// the call of the function.
+	Start    time.Time
+	Duration time.Duration
+
+	// Spans lists the constructor calls and cache hits of the Invoke, in
+	// the order they started.
+	Spans []TraceSpan
+}
+
+// TraceSpanKind is the kind of a TraceSpan.
+type TraceSpanKind int
+
+const (
+	// TraceCall is a call of a constructor.
+	TraceCall TraceSpanKind = iota
+
+	// TraceCacheHit is a value of a constructor that was already called,
+	// reused rather than built.
+	TraceCacheHit
+)
+
+func (k TraceSpanKind) String() string {
+	switch k {
+	case TraceCall:
+		return "TraceCall"
+	case TraceCacheHit:
+		return "TraceCacheHit"
+	default:
+		return fmt.Sprintf("TraceSpanKind(%d)", int(k))
+	}
+}
+
+// TraceSpan is a constructor call or a cache hit recorded in a Trace.
+type TraceSpan struct {
+	Kind TraceSpanKind
+
+	// Provider is the constructor. Its ScopePath leads to the Scope the
+	// values it builds belong to.
+	Provider ProviderInfo
+
+	// Parent is the index in Trace.Spans of the constructor call that
+	// needed this value, or -1 if the invoked function needed it. With
+	// MaxConcurrency, constructors may be called concurrently and Parent
+	// is always -1.
+	Parent int
+
+	// Start is when the span started, relative to Trace.Start. Duration is
+	// how long the call took, including building the dependencies of the
+	// constructor, and Runtime the time spent in the constructor itself,
+	// over all its attempts. They are zero for cache hits.
+	Start    time.Duration
+	Duration time.Duration
+	Runtime  time.Duration
+
+	// Error is the error the call failed with, if any.
+	Error error
+}
+
+// WriteJSON writes the trace to w as a JSON document. Durations are in
+// nanoseconds.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	jt := jsonTrace{
+		Function:   t.Function,
+		ScopePath:  t.ScopePath,
+		Start:      t.Start,
+		DurationNS: int64(t.Duration),
+		Spans:      make([]jsonTraceSpan, 0, len(t.Spans)),
+	}
+	for _, s := range t.Spans {
+		js := jsonTraceSpan{
+			Kind:       "call",
+			Name:       s.Provider.Name,
+			Package:    s.Provider.Package,
+			ScopePath:  s.Provider.ScopePath,
+			Parent:     s.Parent,
+			StartNS:    int64(s.Start),
+			DurationNS: int64(s.Duration),
+			RuntimeNS:  int64(s.Runtime),
+		}
+		if s.Kind == TraceCacheHit {
+			js.Kind = "cacheHit"
+		}
+		if s.Error != nil {
+			js.Error = s.Error.Error()
+		}
+		jt.Spans = append(jt.Spans, js)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(jt)
+}
+
+type jsonTrace struct {
+	Function   string          `json:"function"`
+	ScopePath  []string        `json:"scopePath,omitempty"`
+	Start      time.Time       `json:"start"`
+	DurationNS int64           `json:"durationNs"`
+	Spans      []jsonTraceSpan `json:"spans"`
+}
+
+type jsonTraceSpan struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Package    string   `json:"package"`
+	ScopePath  []string `json:"scopePath,omitempty"`
+	Parent     int      `json:"parent"`
+	StartNS    int64    `json:"startNs"`
+	DurationNS int64    `json:"durationNs"`
+	RuntimeNS  int64    `json:"runtimeNs"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// WriteFolded writes the constructor calls of the trace to w in the folded
+// stacks format read by flame graph tools such as flamegraph.pl and
+// speedscope: one line per call, with the invoked function and the
+// constructors needing the value, separated by semicolons, followed by the
+// runtime of the constructor in nanoseconds.
+//
+//	main.run;dig.NewServer;dig.NewDB 1500000
+//
+// When a constructor belongs to another Scope than its parent, a
+// "scope(child/grandchild)" frame marks the boundary, or "scope(container)"
+// for the constructors of the container.
+func (t *Trace) WriteFolded(w io.Writer) error {
+	stacks := make([]string, len(t.Spans))
+	for i, s := range t.Spans {
+		if s.Kind != TraceCall {
+			continue
+		}
+
+		stack, scope := frameName(t.Function), t.ScopePath
+		if s.Parent >= 0 && s.Parent < i {
+			stack, scope = stacks[s.Parent], t.Spans[s.Parent].Provider.ScopePath
+		}
+		if path := s.Provider.ScopePath; !equalPaths(scope, path) {
+			name := "container"
+			if len(path) > 0 {
+				name = strings.Join(path, "/")
+			}
+			stack += ";" + frameName(fmt.Sprintf("scope(%v)", name))
+		}
+		stacks[i] = stack + ";" + frameName(s.Provider.Name)
+
+		if _, err := fmt.Fprintf(w, "%v %d\n", stacks[i], int64(s.Runtime)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// frameName returns name, without the characters that separate frames and
+// counts in the folded stacks format.
+func frameName(name string) string {
+	return strings.NewReplacer(";", "_", " ", "_").Replace(name)
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tracer records the spans of an Invoke given WithTrace.
+type tracer struct {
+	mu    sync.Mutex
+	start time.Time
+	spans []*TraceSpan
+
+	// open holds the calls in progress. stack holds the indexes of the
+	// ones being built sequentially, the latest last.
+	open  map[*constructorNode]int
+	stack []int
+
+	// concurrent is true if constructors may be called concurrently, in
+	// which case the parents of the calls are not known.
+	concurrent bool
+}
+
+// withTrace records the spans of the Invoke of the named function into
+// trace until the returned function is called.
+func (s *Scope) withTrace(trace *Trace, function string) (finish func()) {
+	state := s.rootScope().state
+	t := &tracer{
+		start:      time.Now(),
+		open:       make(map[*constructorNode]int),
+		concurrent: s.parallel != nil && s.parallel.sem != nil,
+	}
+	state.tracers = append(state.tracers, t)
+	return func() {
+		for i, e := range state.tracers {
+			if e == t {
+				state.tracers = append(state.tracers[:i:i], state.tracers[i+1:]...)
+				break
+			}
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*trace = Trace{
+			Function:  function,
+			ScopePath: s.path(),
+			Start:     t.start,
+			Duration:  time.Since(t.start),
+			Spans:     make([]TraceSpan, len(t.spans)),
+		}
+		for i, span := range t.spans {
+			trace.Spans[i] = *span
+		}
+	}
+}
+
+// tracer returns the tracer of the latest Invoke given WithTrace, or nil.
+func (s *Scope) tracer() *tracer {
+	if ts := s.rootScope().state.tracers; len(ts) > 0 {
+		return ts[len(ts)-1]
+	}
+	return nil
+}
+
+// parent returns the index of the call in progress. It must be called with
+// t.mu held.
+func (t *tracer) parent() int {
+	if t.concurrent || len(t.stack) == 0 {
+		return -1
+	}
+	return t.stack[len(t.stack)-1]
+}
+
+// begin records the start of a call of n.
+func (t *tracer) begin(n *constructorNode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := len(t.spans)
+	t.spans = append(t.spans, &TraceSpan{
+		Kind:     TraceCall,
+		Provider: newProviderInfo(n),
+		Parent:   t.parent(),
+		Start:    time.Since(t.start),
+	})
+	t.open[n] = i
+	if !t.concurrent {
+		t.stack = append(t.stack, i)
+	}
+}
+
+// ran adds to the call of n in progress the runtime of one of its
+// attempts.
+func (t *tracer) ran(n *constructorNode, runtime time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i, ok := t.open[n]; ok {
+		t.spans[i].Runtime += runtime
+	}
+}
+
+// end records the end of the call of n in progress.
+func (t *tracer) end(n *constructorNode, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i, ok := t.open[n]
+	if !ok {
+		return
+	}
+	delete(t.open, n)
+	span := t.spans[i]
+	span.Duration = time.Since(t.start) - span.Start
+	span.Error = err
+	if !t.concurrent && len(t.stack) > 0 {
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+}
+
+// cacheHit records that the values of n were served from the cache.
+func (t *tracer) cacheHit(n *constructorNode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.spans = append(t.spans, &TraceSpan{
+		Kind:     TraceCacheHit,
+		Provider: newProviderInfo(n),
+		Parent:   t.parent(),
+		Start:    time.Since(t.start),
+	})
+}
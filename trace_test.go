@@ -0,0 +1,206 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTrace(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{}
+	type DB struct{}
+	type Server struct{}
+
+	names := func(trace dig.Trace) []string {
+		var names []string
+		for _, s := range trace.Spans {
+			names = append(names, s.Kind.String()+" "+s.Provider.Outputs[0].String())
+		}
+		return names
+	}
+
+	t.Run("calls and cache hits", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *DB {
+			time.Sleep(5 * time.Millisecond)
+			return &DB{}
+		})
+		c.RequireProvide(func(*Config, *DB) *Server { return &Server{} })
+
+		var trace dig.Trace
+		c.RequireInvoke(func(*Server) {}, dig.WithTrace(&trace))
+
+		assert.Equal(t, "github.com/alexisvisco/dig_test.TestWithTrace.func2.4", trace.Function)
+		assert.Equal(t, []string{
+			"TraceCall *dig_test.Server",
+			"TraceCall *dig_test.Config",
+			"TraceCall *dig_test.DB",
+			"TraceCacheHit *dig_test.Config",
+		}, names(trace))
+		assert.Equal(t, []int{-1, 0, 0, 2}, []int{
+			trace.Spans[0].Parent, trace.Spans[1].Parent, trace.Spans[2].Parent, trace.Spans[3].Parent,
+		})
+
+		server, db := trace.Spans[0], trace.Spans[2]
+		assert.GreaterOrEqual(t, db.Runtime, 5*time.Millisecond)
+		assert.GreaterOrEqual(t, db.Duration, db.Runtime)
+		assert.GreaterOrEqual(t, server.Duration, db.Duration)
+		assert.Less(t, server.Runtime, db.Runtime)
+		assert.GreaterOrEqual(t, trace.Duration, server.Duration)
+		assert.Zero(t, trace.Spans[3].Duration)
+		assert.False(t, trace.Start.IsZero())
+
+		var again dig.Trace
+		c.RequireInvoke(func(*Server) {}, dig.WithTrace(&again))
+		assert.Equal(t, []string{"TraceCacheHit *dig_test.Server"}, names(again))
+	})
+
+	t.Run("nested calls", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *DB { return &DB{} })
+		c.RequireProvide(func(*DB) *Server { return &Server{} })
+
+		var trace dig.Trace
+		c.RequireInvoke(func(*Server) {}, dig.WithTrace(&trace))
+
+		// Dependencies are built inside of the call of the constructor
+		// needing them, before the constructor itself is called.
+		assert.Equal(t, []string{
+			"TraceCall *dig_test.Server",
+			"TraceCall *dig_test.DB",
+			"TraceCall *dig_test.Config",
+		}, names(trace))
+		assert.Equal(t, []int{-1, 0, 1}, []int{
+			trace.Spans[0].Parent, trace.Spans[1].Parent, trace.Spans[2].Parent,
+		})
+
+		var b bytes.Buffer
+		require.NoError(t, trace.WriteFolded(&b))
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Regexp(t, `^(github.com/alexisvisco/dig_test.TestWithTrace.func3.\d;){3}github.com/alexisvisco/dig_test.TestWithTrace.func3.1 \d+$`, lines[2])
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		child := c.Scope("child")
+		child.RequireProvide(func(*Config) *DB { return &DB{} })
+
+		var trace dig.Trace
+		child.RequireInvoke(func(*DB) {}, dig.WithTrace(&trace))
+		assert.Equal(t, []string{"child"}, trace.ScopePath)
+		require.Len(t, trace.Spans, 2)
+		assert.Equal(t, []string{"child"}, trace.Spans[0].Provider.ScopePath)
+		assert.Empty(t, trace.Spans[1].Provider.ScopePath)
+
+		var b bytes.Buffer
+		require.NoError(t, trace.WriteFolded(&b))
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Regexp(t, `dig_test.TestWithTrace.func4.2;scope\(container\);github.com/alexisvisco/dig_test.TestWithTrace.func4.1 \d+$`, lines[1])
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Config, error) { return nil, errors.New("great sadness") })
+
+		var trace dig.Trace
+		require.Error(t, c.Invoke(func(*Config) {}, dig.WithTrace(&trace)))
+		require.Len(t, trace.Spans, 1)
+		assert.ErrorContains(t, trace.Spans[0].Error, "great sadness")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+
+		var trace dig.Trace
+		c.RequireInvoke(func(*Config, *Config) {}, dig.WithTrace(&trace))
+
+		var b bytes.Buffer
+		require.NoError(t, trace.WriteJSON(&b))
+
+		var doc struct {
+			Function string `json:"function"`
+			Spans    []struct {
+				Kind    string `json:"kind"`
+				Name    string `json:"name"`
+				Package string `json:"package"`
+				Parent  int    `json:"parent"`
+			} `json:"spans"`
+		}
+		require.NoError(t, json.Unmarshal(b.Bytes(), &doc))
+		assert.Equal(t, trace.Function, doc.Function)
+		require.Len(t, doc.Spans, 2)
+		assert.Equal(t, "call", doc.Spans[0].Kind)
+		assert.Equal(t, "cacheHit", doc.Spans[1].Kind)
+		assert.Equal(t, "github.com/alexisvisco/dig_test.TestWithTrace.func6.1", doc.Spans[0].Name)
+		assert.Equal(t, "github.com/alexisvisco/dig_test", doc.Spans[0].Package)
+		assert.Equal(t, -1, doc.Spans[1].Parent)
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.MaxConcurrency(2))
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *DB { return &DB{} })
+
+		var trace dig.Trace
+		c.RequireInvoke(func(*DB) {}, dig.WithTrace(&trace))
+		require.Len(t, trace.Spans, 2)
+		for _, s := range trace.Spans {
+			assert.Equal(t, -1, s.Parent)
+		}
+	})
+
+	t.Run("stringers", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "TraceSpanKind(42)", dig.TraceSpanKind(42).String())
+		assert.Contains(t, dig.WithTrace(new(dig.Trace)).(interface{ String() string }).String(), "WithTrace(0x")
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// An InvokeReport describes the dependencies that TryInvoke could not build.
+type InvokeReport struct {
+	// Skipped lists the optional dependencies of the function that were
+	// provided but could not be built, and were passed to it as absent, in
+	// the order they failed.
+	Skipped []SkippedDependency
+
+	// Warnings lists the constructors that were left out of tolerant value
+	// groups while the dependencies of the function were built.
+	Warnings []GroupWarning
+}
+
+// Degraded reports whether the function was called without some of the
+// dependencies that the container provides.
+func (r InvokeReport) Degraded() bool {
+	return len(r.Skipped) > 0 || len(r.Warnings) > 0
+}
+
+// A SkippedDependency reports an optional dependency that TryInvoke passed
+// as absent because it could not be built.
+type SkippedDependency struct {
+	// Type and Name identify the dependency.
+	Type reflect.Type
+	Name string
+
+	// Reason is the error that the dependency failed to build with.
+	Reason error
+}
+
+func (d SkippedDependency) String() string {
+	k := key{t: d.Type, name: d.Name}
+	return fmt.Sprintf("skipped %v: %v", k, d.Reason)
+}
+
+// TryInvoke runs the given function after instantiating its dependencies,
+// as Invoke does, except that optional dependencies that are provided but
+// fail to build, because their constructors or decorators fail or lack
+// dependencies, are passed to the function as absent instead of failing it.
+// This lets applications with many optional integrations start in a
+// degraded mode.
+//
+//	type integrations struct {
+//	  dig.In
+//
+//	  Slack  *slack.Client  `optional:"true"`
+//	  GitHub *github.Client `optional:"true"`
+//	}
+//
+//	report, err := c.TryInvoke(func(i integrations) { ... })
+//	for _, s := range report.Skipped {
+//	  log.Printf("integration disabled: %v", s)
+//	}
+//
+// Fields tagged optional and dig.Optional dependencies are tolerated at any
+// depth of the graph, along with the constructors left out of tolerant
+// value groups, which are also reported. TryInvoke still fails if a
+// required dependency cannot be built or if the function returns an error,
+// in which case the report describes what was skipped before the failure.
+func (c *Container) TryInvoke(function interface{}, opts ...InvokeOption) (InvokeReport, error) {
+	return c.scope.TryInvoke(function, opts...)
+}
+
+// TryInvoke runs the given function after instantiating its dependencies,
+// tolerating the optional dependencies that fail to build. See
+// Container.TryInvoke for details.
+func (s *Scope) TryInvoke(function interface{}, opts ...InvokeOption) (InvokeReport, error) {
+	var report InvokeReport
+	opts = append(opts[:len(opts):len(opts)], tryInvokeOption{&report})
+	err := s.Invoke(function, opts...)
+	return report, err
+}
+
+type tryInvokeOption struct{ report *InvokeReport }
+
+func (o tryInvokeOption) String() string {
+	return fmt.Sprintf("TryInvoke(%p)", o.report)
+}
+
+func (o tryInvokeOption) applyInvokeOption(opts *invokeOptions) {
+	opts.report = o.report
+}
+
+// withReport makes the optional dependencies that fail to build absent,
+// and records them to the report, until the returned function is called.
+func (s *Scope) withReport(report *InvokeReport) func() {
+	state := s.rootScope().state
+	prev := state.report
+	state.report = report
+	return func() { state.report = prev }
+}
+
+// skipDependency records the optional dependency of the given key that
+// failed with err, returning whether a TryInvoke is in progress, in which
+// case the dependency must be absent rather than failed.
+func skipDependency(c containerStore, k key, err error) bool {
+	s, ok := c.(*Scope)
+	if !ok {
+		return false
+	}
+	report := s.rootScope().state.report
+	if report == nil {
+		return false
+	}
+	report.Skipped = append(report.Skipped, SkippedDependency{Type: k.t, Name: k.name, Reason: err})
+	return true
+}
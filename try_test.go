@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryInvoke(t *testing.T) {
+	type Slack struct{}
+	type GitHub struct{}
+	type Config struct{}
+	type Server struct{ Slack *Slack }
+
+	t.Parallel()
+
+	giveErr := errors.New("great sadness")
+
+	t.Run("optional dependencies are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Slack, error) { return nil, giveErr })
+		c.RequireProvide(func(*Config) *GitHub { return &GitHub{} }, dig.Name("gh"))
+		c.RequireProvide(func(p struct {
+			dig.In
+
+			Slack *Slack `optional:"true"`
+		}) *Server {
+			return &Server{Slack: p.Slack}
+		})
+
+		type params struct {
+			dig.In
+
+			Server *Server
+			GitHub *GitHub `name:"gh" optional:"true"`
+			Opt    dig.Optional[*Slack]
+		}
+		var called bool
+		report, err := c.TryInvoke(func(p params) {
+			called = true
+			assert.Nil(t, p.Server.Slack)
+			assert.Nil(t, p.GitHub)
+			assert.False(t, p.Opt.IsPresent())
+		})
+		require.NoError(t, err)
+		assert.True(t, called)
+		assert.True(t, report.Degraded())
+
+		require.Len(t, report.Skipped, 3)
+		types := make(map[reflect.Type]int)
+		for _, s := range report.Skipped {
+			types[s.Type]++
+		}
+		assert.Equal(t, map[reflect.Type]int{reflect.TypeOf(&Slack{}): 2, reflect.TypeOf(&GitHub{}): 1}, types)
+		for _, s := range report.Skipped {
+			if s.Type == reflect.TypeOf(&GitHub{}) {
+				assert.Equal(t, "gh", s.Name)
+				assert.Contains(t, s.String(), `skipped *dig_test.GitHub[name="gh"]: missing dependencies`)
+			} else {
+				assert.ErrorIs(t, s.Reason, giveErr)
+			}
+		}
+
+		// Invoke still fails on the optional dependency that cannot be built.
+		err = c.Invoke(func(p params) {})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, giveErr)
+	})
+
+	t.Run("required dependencies fail", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() (*Slack, error) { return nil, giveErr })
+		c.RequireProvide(func() (*GitHub, error) { return nil, giveErr })
+
+		type params struct {
+			dig.In
+
+			GitHub *GitHub `optional:"true"`
+			Slack  *Slack
+		}
+		report, err := c.TryInvoke(func(params) {
+			t.Fatal("function must not be called")
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, giveErr)
+		require.Len(t, report.Skipped, 1)
+		assert.Equal(t, reflect.TypeOf(&GitHub{}), report.Skipped[0].Type)
+	})
+
+	t.Run("function error", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		report, err := c.TryInvoke(func() error { return giveErr })
+		assert.ErrorIs(t, err, giveErr)
+		assert.False(t, report.Degraded())
+	})
+
+	t.Run("tolerant value groups", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() string { return "a" }, dig.Group("values"))
+		c.RequireProvide(func() (string, error) { return "", giveErr }, dig.Group("values"))
+
+		type params struct {
+			dig.In
+
+			Values []string `group:"values,tolerant"`
+		}
+		s := c.Scope("child")
+		report, err := s.TryInvoke(func(p params) {
+			assert.Equal(t, []string{"a"}, p.Values)
+		})
+		require.NoError(t, err)
+		assert.True(t, report.Degraded())
+		assert.Empty(t, report.Skipped)
+		require.Len(t, report.Warnings, 1)
+		assert.ErrorIs(t, report.Warnings[0].Reason, giveErr)
+	})
+
+	t.Run("not degraded", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+
+		type params struct {
+			dig.In
+
+			Config *Config `optional:"true"`
+			Slack  *Slack  `optional:"true"`
+		}
+		report, err := c.TryInvoke(func(p params) {
+			assert.NotNil(t, p.Config)
+		})
+		require.NoError(t, err)
+		assert.False(t, report.Degraded(), "absent dependencies must not be skipped")
+	})
+}
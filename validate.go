@@ -0,0 +1,377 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+	"github.com/alexisvisco/dig/internal/graph"
+)
+
+// AssertWireable verifies that the dependencies of the given entrypoint
+// functions can be satisfied by the Container, without calling any of the
+// entrypoints or the constructors they depend on.
+//
+// This is intended to be used in tests to verify that an application's
+// graph is fully wired for its real entrypoints.
+//
+//	func TestWiring(t *testing.T) {
+//	  c := newAppContainer()
+//	  if err := dig.AssertWireable(c, runServer, runWorker); err != nil {
+//	    t.Fatal(err)
+//	  }
+//	}
+//
+// All problems found across all the entrypoints are reported at once in the
+// returned error, each of them naming the entrypoint it affects.
+func AssertWireable(c *Container, entrypoints ...interface{}) error {
+	var problems []error
+	for _, fn := range entrypoints {
+		problems = append(problems, c.scope.checkFunction(fn)...)
+	}
+	if len(problems) > 0 {
+		return errInvalidGraph(problems)
+	}
+	return nil
+}
+
+// Validate verifies that the dependencies of every constructor and
+// decorator provided to the Container, or to any of its Scopes, and of every
+// function registered with RegisterInvoke, can be satisfied, without calling
+// any of them.
+//
+// This allows failing fast at startup or in CI, rather than on the first
+// Invoke that needs a constructor with missing dependencies.
+//
+//	if err := c.Validate(); err != nil {
+//	  log.Fatal(err)
+//	}
+//
+// Validate reports the direct problems of each function: missing
+// dependencies, taking into account names, optional dependencies and value
+// groups, and dependency cycles. Constructors that fail only because one of
+// their dependencies cannot be built are not reported. All the problems
+// found are reported at once in the returned error.
+func (c *Container) Validate() error {
+	var problems []error
+	for _, s := range c.scope.appendSubscopes(nil) {
+		problems = append(problems, s.validate()...)
+	}
+	problems = append(problems, c.scope.validateRegisteredInvokes()...)
+	if len(problems) > 0 {
+		return errInvalidGraph(problems)
+	}
+	return nil
+}
+
+// validate reports the problems of the constructors and decorators provided
+// directly to the Scope.
+func (s *Scope) validate() []error {
+	var problems []error
+	if !s.isVerifiedAcyclic {
+		if ok, cycle := graph.IsAcyclic(s.gh); !ok {
+			problems = append(problems,
+				newErrInvalidInput("cycle detected in dependency graph", s.cycleDetectedError(cycle)))
+		} else {
+			s.isVerifiedAcyclic = true
+		}
+	}
+
+	for _, n := range s.nodes {
+		if err := staticCheckDependencies(n.OrigScope(), n.paramList); err != nil {
+			problems = append(problems, errMissingDependencies{Func: n.location, Reason: err})
+		}
+	}
+	for _, n := range s.decoratorNodes {
+		if err := staticCheckDependencies(n.s, n.params); err != nil {
+			problems = append(problems, errMissingDependencies{Func: n.location, Reason: err})
+		}
+	}
+	return problems
+}
+
+// checkFunction statically checks that the dependencies of the given
+// function can be built by the Scope, returning the problems found.
+func (s *Scope) checkFunction(fn interface{}) []error {
+	ftype := reflect.TypeOf(fn)
+	if ftype == nil {
+		return []error{newErrInvalidInput("can't invoke an untyped nil", nil)}
+	}
+	if ftype.Kind() != reflect.Func {
+		return []error{newErrInvalidInput(
+			fmt.Sprintf("can't invoke non-function %v (type %v)", fn, ftype), nil)}
+	}
+
+	pl, err := newParamList(ftype, s)
+	if err != nil {
+		return []error{err}
+	}
+
+	return newGraphChecker().checkFunc(s, digreflect.InspectFunc(fn), pl)
+}
+
+// graphChecker walks the dependency graph to find problems that would
+// prevent values from being built, without calling any function.
+type graphChecker struct {
+	// Constructors already checked, along with the problem found for each
+	// of them, if any.
+	checked map[*constructorNode]error
+
+	// Constructors currently being checked, in order. Used to detect cycles.
+	stack []*constructorNode
+
+	// Decorators already checked, along with the problem found for each of
+	// them, if any.
+	checkedDecorators map[*decoratorNode]error
+}
+
+func newGraphChecker() *graphChecker {
+	return &graphChecker{
+		checked:           make(map[*constructorNode]error),
+		checkedDecorators: make(map[*decoratorNode]error),
+	}
+}
+
+// checkFunc reports the problems that prevent building the arguments of a
+// function with the given parameters from the given store.
+//
+// Each problem is reported as a separate error, attributed to the function.
+func (gc *graphChecker) checkFunc(c containerStore, fn *digreflect.Func, pl paramList) []error {
+	if err := staticCheckDependencies(c, pl); err != nil {
+		return []error{errMissingDependencies{Func: fn, Reason: err}}
+	}
+
+	var errs []error
+	for _, err := range gc.checkParams(c, pl.Params) {
+		errs = append(errs, errArgumentsFailed{Func: fn, Reason: err})
+	}
+	return errs
+}
+
+func (gc *graphChecker) checkParams(c containerStore, params []param) []error {
+	var errs []error
+	for _, p := range params {
+		switch p := p.(type) {
+		case paramSingle:
+			errs = append(errs, gc.checkParamSingle(c, p)...)
+		case paramOptional:
+			errs = append(errs, gc.checkParamSingle(c, p.Elem)...)
+		case paramOriginal:
+			errs = append(errs, gc.checkParamSingle(c, p.Elem)...)
+		case paramGroupedSlice:
+			errs = append(errs, gc.checkParamGroupedSlice(c, p)...)
+		case paramObject:
+			for _, f := range p.Fields {
+				errs = append(errs, gc.checkParams(c, []param{f.Param})...)
+			}
+		}
+	}
+	return errs
+}
+
+// checkParamSingle mirrors paramSingle.Build.
+func (gc *graphChecker) checkParamSingle(c containerStore, ps paramSingle) []error {
+	if len(ps.Selector) > 0 {
+		name, err := ps.selectName(c)
+		if err != nil {
+			return []error{err}
+		}
+		if name == "" {
+			if ps.Optional {
+				return nil
+			}
+			return []error{ps.noSelectedValue()}
+		}
+		ps.Name, ps.Selector = name, nil
+	}
+	k := key{t: ps.Type, name: ps.Name}
+
+	var errs []error
+	if !ps.Undecorated {
+		for i, s := range c.storesToRoot() {
+			if d, ok := s.getValueDecorator(ps.Name, ps.Type); ok && decoratorApplies(d, i) {
+				if err := gc.checkDecorator(d); err != nil {
+					errs = append(errs, errParamSingleFailed{CtorID: d.ID(), Key: k, Reason: err})
+				}
+			}
+		}
+	}
+
+	for _, s := range c.storesToRoot() {
+		if _, ok := s.getValue(ps.Name, ps.Type); ok {
+			return errs
+		}
+
+		providers := s.getValueProviders(ps.Name, ps.Type)
+		if len(providers) == 0 {
+			continue
+		}
+
+		for _, p := range providers {
+			err := gc.checkProvider(p)
+			if err == nil {
+				continue
+			}
+			if _, ok := err.(errMissingDependencies); ok && ps.Optional {
+				continue
+			}
+			errs = append(errs, errParamSingleFailed{CtorID: p.ID(), Key: k, Reason: err})
+		}
+		return errs
+	}
+
+	// Nothing provides this value. If it wasn't optional, this has
+	// already been reported by staticCheckDependencies.
+	return errs
+}
+
+// checkParamGroupedSlice mirrors paramGroupedSlice.Build.
+func (gc *graphChecker) checkParamGroupedSlice(c containerStore, pt paramGroupedSlice) []error {
+	k := key{group: pt.Group, t: pt.Type.Elem()}
+
+	var errs []error
+	if !pt.Undecorated {
+		for i, s := range c.storesToRoot() {
+			if d, ok := s.getGroupDecorator(pt.Group, pt.Type.Elem()); ok && decoratorApplies(d, i) {
+				if err := gc.checkDecorator(d); err != nil {
+					errs = append(errs, errParamGroupFailed{CtorID: d.ID(), Key: k, Reason: err})
+				}
+			}
+		}
+	}
+
+	if pt.Soft {
+		return errs
+	}
+
+	for _, s := range c.storesToRoot() {
+		for _, p := range s.getGroupProviders(pt.Group, pt.Type.Elem()) {
+			if err := gc.checkProvider(p); err != nil && !pt.Tolerant {
+				errs = append(errs, errParamGroupFailed{CtorID: p.ID(), Key: k, Reason: err})
+			}
+		}
+	}
+	return errs
+}
+
+// checkProvider reports the first problem that prevents the given provider
+// from being called, if any.
+func (gc *graphChecker) checkProvider(p provider) error {
+	n, ok := p.(*constructorNode)
+	if !ok || n.called {
+		return nil
+	}
+
+	if err, ok := gc.checked[n]; ok {
+		return err
+	}
+
+	for i, m := range gc.stack {
+		if m == n {
+			return gc.cycleError(gc.stack[i:])
+		}
+	}
+
+	gc.stack = append(gc.stack, n)
+	var err error
+	if errs := gc.checkFunc(n.OrigScope(), n.location, n.paramList); len(errs) > 0 {
+		err = errs[0]
+	}
+	gc.stack = gc.stack[:len(gc.stack)-1]
+
+	gc.checked[n] = err
+	return err
+}
+
+// checkDecorator reports the first problem that prevents the given
+// decorator from being called, if any.
+func (gc *graphChecker) checkDecorator(d decorator) error {
+	n, ok := d.(*decoratorNode)
+	if !ok || n.state == decoratorCalled {
+		return nil
+	}
+
+	if err, ok := gc.checkedDecorators[n]; ok {
+		return err
+	}
+
+	// Record a result before checking the parameters: a decorator may
+	// depend on the value it decorates.
+	gc.checkedDecorators[n] = nil
+
+	var err error
+	if errs := gc.checkFunc(n.s, n.location, n.params); len(errs) > 0 {
+		err = errs[0]
+	}
+	gc.checkedDecorators[n] = err
+	return err
+}
+
+func (gc *graphChecker) cycleError(cycle []*constructorNode) error {
+	path := make([]cycleErrPathEntry, 0, len(cycle)+1)
+	for _, n := range append(cycle, cycle[0]) {
+		path = append(path, cycleErrPathEntry{
+			Key:  key{t: n.CType()},
+			Func: n.Location(),
+		})
+	}
+	return newErrInvalidInput("cycle detected in dependency graph",
+		errCycleDetected{Path: path, scope: cycle[0].s})
+}
+
+// errInvalidGraph is returned when one or more problems were found while
+// checking the dependency graph without running any function.
+type errInvalidGraph []error // inv: len > 0
+
+var _ digError = errInvalidGraph(nil)
+
+func (e errInvalidGraph) Error() string { return fmt.Sprint(e) }
+
+// Unwrap returns the problems found in the graph.
+func (e errInvalidGraph) Unwrap() []error { return e }
+
+func (e errInvalidGraph) writeMessage(w io.Writer, v string) {
+	multiline := v == "%+v"
+
+	if len(e) == 1 {
+		io.WriteString(w, "found 1 problem in the dependency graph:")
+	} else {
+		fmt.Fprintf(w, "found %d problems in the dependency graph:", len(e))
+	}
+
+	for i, err := range e {
+		if multiline {
+			io.WriteString(w, "\n\t- ")
+		} else if i > 0 {
+			io.WriteString(w, "; ")
+		} else {
+			io.WriteString(w, " ")
+		}
+		fmt.Fprintf(w, v, err)
+	}
+}
+
+func (e errInvalidGraph) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
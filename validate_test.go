@@ -0,0 +1,233 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type wireableConfig struct{}
+
+type wireableDB struct{}
+
+type wireableCache struct{}
+
+func runWireableServer(*wireableDB) {}
+
+func runWireableWorker(*wireableCache) {}
+
+func TestAssertWireable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("satisfiable entrypoints", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *wireableConfig { return &wireableConfig{} })
+		c.RequireProvide(func(*wireableConfig) *wireableDB {
+			t.Fatal("constructors must not be called")
+			return nil
+		})
+
+		assert.NoError(t, dig.AssertWireable(c.Container, runWireableServer))
+	})
+
+	t.Run("reports the missing type and entrypoint", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *wireableDB { return &wireableDB{} })
+
+		err := dig.AssertWireable(c.Container, runWireableServer, runWireableWorker)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "found 1 problem in the dependency graph")
+		assert.Contains(t, err.Error(), "runWireableWorker")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.wireableCache")
+		assert.NotContains(t, err.Error(), "runWireableServer")
+
+		var de dig.Error
+		assert.True(t, errors.As(err, &de), "expected a dig.Error")
+	})
+
+	t.Run("reports transitive problems of every entrypoint", func(t *testing.T) {
+		t.Parallel()
+
+		type In struct {
+			dig.In
+
+			DB    *wireableDB
+			Cache *wireableCache
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*wireableConfig) *wireableDB { return &wireableDB{} })
+		c.RequireProvide(func(*wireableConfig) *wireableCache { return &wireableCache{} })
+
+		err := dig.AssertWireable(c.Container, runWireableServer, func(In) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "found 3 problems in the dependency graph")
+		assert.Contains(t, err.Error(), "runWireableServer")
+		assert.Contains(t, err.Error(), "failed to build *dig_test.wireableDB")
+		assert.Contains(t, err.Error(), "failed to build *dig_test.wireableCache")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.wireableConfig")
+	})
+
+	t.Run("optional dependencies may be missing", func(t *testing.T) {
+		t.Parallel()
+
+		type In struct {
+			dig.In
+
+			DB *wireableDB `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*wireableConfig) *wireableDB { return &wireableDB{} })
+
+		assert.NoError(t, dig.AssertWireable(c.Container, func(In) {}))
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		t.Parallel()
+
+		type In struct {
+			dig.In
+
+			Values []int `group:"values"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() int { return 1 }, dig.Group("values"))
+		c.RequireProvide(func(*wireableConfig) int { return 2 }, dig.Group("values"))
+
+		err := dig.AssertWireable(c.Container, func(In) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `could not build value group int[group="values"]`)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.wireableConfig")
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeferAcyclicVerification())
+		c.RequireProvide(func(*wireableCache) *wireableDB { return &wireableDB{} })
+		c.RequireProvide(func(*wireableDB) *wireableCache { return &wireableCache{} })
+
+		err := dig.AssertWireable(c.Container, runWireableServer)
+		require.Error(t, err)
+		assert.True(t, dig.IsCycleDetected(err), "expected a cycle: %v", err)
+	})
+
+	t.Run("non-function entrypoint", func(t *testing.T) {
+		t.Parallel()
+
+		err := dig.AssertWireable(digtest.New(t).Container, 42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't invoke non-function 42 (type int)")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("complete graph", func(t *testing.T) {
+		t.Parallel()
+
+		type In struct {
+			dig.In
+
+			DB       *wireableDB
+			Cache    *wireableCache    `optional:"true"`
+			Configs  []*wireableConfig `group:"configs"`
+			Replicas []*wireableDB     `group:"replicas,soft"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *wireableConfig {
+			t.Fatal("constructors must not be called")
+			return nil
+		}, dig.Group("configs"))
+		c.RequireProvide(func() *wireableDB {
+			t.Fatal("constructors must not be called")
+			return nil
+		}, dig.Name("primary"))
+		c.RequireProvide(func(db struct {
+			dig.In
+
+			DB *wireableDB `name:"primary"`
+		}) *wireableDB {
+			t.Fatal("constructors must not be called")
+			return nil
+		})
+		c.RequireProvide(func(In) *wireableCache {
+			t.Fatal("constructors must not be called")
+			return nil
+		}, dig.Name("cache"))
+
+		assert.NoError(t, c.Validate())
+	})
+
+	t.Run("reports every constructor with missing dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(*wireableConfig) *wireableDB { return &wireableDB{} })
+		c.RequireProvide(func(*wireableDB) *wireableCache { return &wireableCache{} })
+
+		child := c.Scope("child")
+		child.RequireProvide(func(struct {
+			dig.In
+
+			DB *wireableDB `name:"replica"`
+		}) string {
+			return ""
+		})
+		child.RequireDecorate(func(*wireableDB, int) *wireableDB { return &wireableDB{} })
+
+		err := c.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "found 3 problems in the dependency graph")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.wireableConfig")
+		assert.Contains(t, err.Error(), `missing type: *dig_test.wireableDB[name="replica"]`)
+		assert.Contains(t, err.Error(), "missing type: int")
+
+		var de dig.Error
+		assert.True(t, errors.As(err, &de), "expected a dig.Error")
+	})
+
+	t.Run("reports cycles", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t, dig.DeferAcyclicVerification())
+		c.RequireProvide(func(*wireableCache) *wireableDB { return &wireableDB{} })
+		c.RequireProvide(func(*wireableDB) *wireableCache { return &wireableCache{} })
+
+		err := c.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle detected in dependency graph")
+	})
+}
@@ -21,12 +21,15 @@
 package dig
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 	"text/template"
 
+	"github.com/alexisvisco/dig/internal/digreflect"
 	"github.com/alexisvisco/dig/internal/dot"
 )
 
@@ -37,6 +40,85 @@ type VisualizeOption interface {
 
 type visualizeOptions struct {
 	VisualizeError error
+	Format         Format
+	Focus          reflect.Type
+	Invoke         interface{}
+	Depth          int
+	GroupBy        GroupBy
+	Durations      bool
+	Style          StyleFunc
+}
+
+// Format is an output format supported by Visualize.
+type Format int
+
+const (
+	// FormatDOT renders the graph in the Graphviz DOT language. This is the
+	// default format.
+	FormatDOT Format = iota
+
+	// FormatMermaid renders the graph as a Mermaid flowchart, which may be
+	// embedded as-is in GitHub Markdown.
+	FormatMermaid
+
+	// FormatJSON renders the graph as a JSON document meant to be consumed
+	// by external tooling. See Container.GraphJSON.
+	FormatJSON
+
+	// FormatHTML renders the graph as a self-contained HTML page to explore
+	// it interactively. See Container.VisualizeHTML.
+	FormatHTML
+
+	// FormatD2 renders the graph as a D2 diagram.
+	FormatD2
+
+	// FormatPlantUML renders the graph as a PlantUML component diagram.
+	FormatPlantUML
+)
+
+// _renderers holds the renderers of the text formats that are not
+// rendered from a template.
+var _renderers = map[Format]dot.Renderer{
+	FormatMermaid:  dot.MermaidRenderer{},
+	FormatD2:       dot.D2Renderer{},
+	FormatPlantUML: dot.PlantUMLRenderer{},
+}
+
+func (f Format) String() string {
+	switch f {
+	case FormatDOT:
+		return "FormatDOT"
+	case FormatMermaid:
+		return "FormatMermaid"
+	case FormatJSON:
+		return "FormatJSON"
+	case FormatHTML:
+		return "FormatHTML"
+	case FormatD2:
+		return "FormatD2"
+	case FormatPlantUML:
+		return "FormatPlantUML"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// VisualizeFormat is a VisualizeOption that specifies the format in which
+// Visualize renders the graph.
+//
+//	dig.Visualize(c, w, dig.VisualizeFormat(dig.FormatMermaid))
+func VisualizeFormat(f Format) VisualizeOption {
+	return visualizeFormatOption(f)
+}
+
+type visualizeFormatOption Format
+
+func (o visualizeFormatOption) String() string {
+	return fmt.Sprintf("VisualizeFormat(%v)", Format(o))
+}
+
+func (o visualizeFormatOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.Format = Format(o)
 }
 
 // VisualizeError includes a visualization of the given error in the output of
@@ -62,7 +144,190 @@ func (o visualizeErrorOption) applyVisualizeOption(opt *visualizeOptions) {
 	opt.VisualizeError = o.err
 }
 
+// VisualizeFocus restricts the output of Visualize to the values of the
+// type pointed to by t, along with the constructors it transitively depends
+// on and the ones that transitively depend on it.
+//
+//	dig.Visualize(c, w, dig.VisualizeFocus(new(*http.Server)))
+//
+// Use VisualizeDepth to limit how far from the type the graph extends.
+func VisualizeFocus(t interface{}) VisualizeOption {
+	return visualizeFocusOption{t}
+}
+
+type visualizeFocusOption struct{ t interface{} }
+
+func (o visualizeFocusOption) String() string {
+	if t := reflect.TypeOf(o.t); t != nil && t.Kind() == reflect.Ptr {
+		return fmt.Sprintf("VisualizeFocus(%v)", t.Elem())
+	}
+	return fmt.Sprintf("VisualizeFocus(%v)", o.t)
+}
+
+func (o visualizeFocusOption) applyVisualizeOption(opt *visualizeOptions) {
+	if t := reflect.TypeOf(o.t); t != nil && t.Kind() == reflect.Ptr {
+		opt.Focus = t.Elem()
+	}
+}
+
+// VisualizeInvoke restricts the output of Visualize to the constructors that
+// invoking the given function would call: the constructors of its
+// parameters, and the ones they transitively depend on. The function is
+// not invoked, and its dependencies are resolved from their types only.
+//
+//	// What does starting the HTTP server pull in?
+//	dig.Visualize(c, w, dig.VisualizeInvoke(func(*http.Server) {}))
+//
+// The function may be annotated with Annotate. Use VisualizeDepth to limit
+// how far from its parameters the graph extends.
+func VisualizeInvoke(function interface{}) VisualizeOption {
+	return visualizeInvokeOption{function}
+}
+
+type visualizeInvokeOption struct{ function interface{} }
+
+func (o visualizeInvokeOption) String() string {
+	if a, ok := o.function.(annotated); ok {
+		return fmt.Sprintf("VisualizeInvoke(%v)", a)
+	}
+	return fmt.Sprintf("VisualizeInvoke(%v)", reflect.TypeOf(o.function))
+}
+
+func (o visualizeInvokeOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.Invoke = o.function
+}
+
+// invokeDotParams returns the parameters of the function given to
+// VisualizeInvoke.
+func invokeDotParams(function interface{}) ([]*dot.Param, error) {
+	function, _, err := unannotate(function)
+	if err != nil {
+		return nil, err
+	}
+	ftype := reflect.TypeOf(function)
+	if ftype == nil || ftype.Kind() != reflect.Func {
+		return nil, newErrInvalidInput(
+			fmt.Sprintf("can't visualize the invoke of non-function %v (type %v)", function, ftype), nil)
+	}
+
+	// Parameters are parsed against a Scope of their own so that Visualize
+	// does not modify the graph of the container.
+	pl, err := newParamList(ftype, newScope())
+	if err != nil {
+		return nil, err
+	}
+	return pl.DotParam(), nil
+}
+
+// VisualizeDepth limits the graph rendered with VisualizeFocus or
+// VisualizeInvoke to the constructors at most n constructors away from the
+// focused type or the parameters of the function. A depth of zero or less
+// does not limit the graph.
+//
+// This option has no effect without VisualizeFocus or VisualizeInvoke.
+func VisualizeDepth(n int) VisualizeOption {
+	return visualizeDepthOption(n)
+}
+
+type visualizeDepthOption int
+
+func (o visualizeDepthOption) String() string {
+	return fmt.Sprintf("VisualizeDepth(%d)", int(o))
+}
+
+func (o visualizeDepthOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.Depth = int(o)
+}
+
+// GroupBy specifies how Visualize clusters the constructors of the graph.
+type GroupBy int
+
+const (
+	// GroupByNone does not cluster constructors. This is the default.
+	GroupByNone GroupBy = iota
+
+	// GroupByPackage clusters constructors by the Go package they are
+	// defined in.
+	GroupByPackage
+
+	// GroupByModule clusters constructors by the Module they were provided
+	// through. Constructors provided outside of Modules are not clustered.
+	GroupByModule
+)
+
+func (g GroupBy) String() string {
+	switch g {
+	case GroupByNone:
+		return "GroupByNone"
+	case GroupByPackage:
+		return "GroupByPackage"
+	case GroupByModule:
+		return "GroupByModule"
+	default:
+		return fmt.Sprintf("GroupBy(%d)", int(g))
+	}
+}
+
+// VisualizeGroupBy is a VisualizeOption that clusters the constructors of
+// the DOT graph, which makes large graphs easier to read.
+//
+//	dig.Visualize(c, w, dig.VisualizeGroupBy(dig.GroupByPackage))
+//
+// This option only affects the DOT format.
+func VisualizeGroupBy(g GroupBy) VisualizeOption {
+	return visualizeGroupByOption(g)
+}
+
+type visualizeGroupByOption GroupBy
+
+func (o visualizeGroupByOption) String() string {
+	return fmt.Sprintf("VisualizeGroupBy(%v)", GroupBy(o))
+}
+
+func (o visualizeGroupByOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.GroupBy = GroupBy(o)
+}
+
+// VisualizeDurations is a VisualizeOption that annotates the constructors
+// of the DOT graph with the time they took to build their values, and draws
+// the constructors of the critical path of the container in bold. See
+// Container.CriticalPath.
+//
+//	if err := c.Invoke(run); err == nil {
+//		dig.Visualize(c, w, dig.VisualizeDurations())
+//	}
+//
+// This option only affects the DOT format, and has no effect on
+// VisualizeConstructors, whose constructors are never called.
+func VisualizeDurations() VisualizeOption {
+	return visualizeDurationsOption{}
+}
+
+type visualizeDurationsOption struct{}
+
+func (visualizeDurationsOption) String() string {
+	return "VisualizeDurations()"
+}
+
+func (visualizeDurationsOption) applyVisualizeOption(opt *visualizeOptions) {
+	opt.Durations = true
+}
+
 func updateGraph(dg *dot.Graph, err error) error {
+	// If there are no errVisualizers included, we do not modify the graph.
+	if !visualizeChain(dg, err) {
+		return nil
+	}
+
+	// Remove non-error entries from the graph for readability.
+	dg.PruneSuccess()
+
+	return nil
+}
+
+// visualizeChain updates the graph with the errVisualizers in the chain of
+// wrapped errors of err, and reports whether there were any.
+func visualizeChain(dg *dot.Graph, err error) bool {
 	var errs []errVisualizer
 	// Unwrap error to find the root cause.
 	for {
@@ -76,21 +341,81 @@ func updateGraph(dg *dot.Graph, err error) error {
 		err = e
 	}
 
-	// If there are no errVisualizers included, we do not modify the graph.
-	if len(errs) == 0 {
-		return nil
-	}
-
 	// We iterate in reverse because the last element is the root cause.
 	for i := len(errs) - 1; i >= 0; i-- {
 		errs[i].updateGraph(dg)
 	}
+	return len(errs) > 0
+}
 
-	// Remove non-error entries from the graph for readability.
-	dg.PruneSuccess()
+// _styledGraphTmpl renders DOT graphs with the attributes computed by a
+// VisualizeStyle, and _graphTmpl renders them with the default attributes.
+var _styledGraphTmpl = template.Must(
+	template.New("StyledDotGraph").
+		Funcs(template.FuncMap{
+			"quote": strconv.Quote,
+			"ltail": func(i int) string { return fmt.Sprintf("cluster_%d", i) },
+		}).
+		Parse(`digraph {
+	rankdir=RL;
+	graph [compound=true];
+	{{range $g := .Groups}}
+		{{- quote .String}} [{{$.Styles.Group .}}];
+		{{range .Results}}
+			{{- quote $g.String}} -> {{quote .String}}{{with $.Styles.Member $g .}} [{{.}}]{{end}};
+		{{end}}
+	{{end -}}
+	{{range $index, $ctor := .Ctors}}
+		{{- if .Supplied}}
+		{{range .Results}}
+			{{- quote .String}} [{{$.Styles.Value $ctor .}}];
+		{{end -}}
+		{{else}}
+		{{- with .Cluster}}
+		subgraph {{.ID}} {
+		label = {{quote .Label}};
+		{{- end}}
+		subgraph cluster_{{$index}} {
+			{{ with .Label }}label = {{ quote .}};
+			{{ end -}}
 
-	return nil
-}
+			constructor_{{$index}} [{{$.Styles.Ctor .}}];
+			{{$.Styles.Cluster .}}
+			{{range .Results}}
+				{{- quote .String}} [{{$.Styles.Value $ctor .}}];
+			{{end}}
+		}{{if .Cluster}}
+		}{{end}}
+		{{range .Params}}
+			constructor_{{$index}} -> {{quote .String}} [{{$.Styles.Dependency $ctor . (ltail $index)}}];
+		{{end}}
+		{{range .GroupParams}}
+			constructor_{{$index}} -> {{quote .String}} [{{$.Styles.GroupDependency $ctor . (ltail $index)}}];
+		{{end -}}
+		{{end -}}
+	{{end}}
+	{{- range $index, $d := .Decorators}}
+		decorator_{{$index}} [{{$.Styles.Decorator .}}];
+		{{range .Params}}
+			decorator_{{$index}} -> {{quote .String}}{{with $.Styles.DecoratorDependency $d .}} [{{.}}]{{end}};
+		{{end}}
+		{{range .GroupParams}}
+			decorator_{{$index}} -> {{quote .String}}{{with $.Styles.DecoratorGroupDependency $d .}} [{{.}}]{{end}};
+		{{end}}
+		{{range .Results}}
+			decorator_{{$index}} -> {{quote .String}} [{{$.Styles.Decoration $d .}}];
+		{{end}}
+		{{range .Groups}}
+			decorator_{{$index}} -> {{quote .String}} [{{$.Styles.GroupDecoration $d .}}];
+		{{end -}}
+	{{end}}
+	{{range .Styles.Failures}}
+		{{- quote .String}} [{{$.Styles.Failure .}}];
+	{{end}}
+	{{- range .Failed.Suggestions}}
+		{{- quote .Missing.String}} -> {{quote .Suggested.String}} [{{$.Styles.Suggestion .}}];
+	{{end}}
+}`))
 
 var _graphTmpl = template.Must(
 	template.New("DotGraph").
@@ -107,22 +432,49 @@ var _graphTmpl = template.Must(
 		{{end}}
 	{{end -}}
 	{{range $index, $ctor := .Ctors}}
+		{{- if .Supplied}}
+		{{range .Results}}
+			{{- quote .String}} [{{.Attributes}} shape=parallelogram{{if $ctor.Fallback}} style=dashed{{end}}];
+		{{end -}}
+		{{else}}
+		{{- with .Cluster}}
+		subgraph {{.ID}} {
+		label = {{quote .Label}};
+		{{- end}}
 		subgraph cluster_{{$index}} {
-			{{ with .Package }}label = {{ quote .}};
+			{{ with .Label }}label = {{ quote .}};
 			{{ end -}}
 
-			constructor_{{$index}} [shape=plaintext label={{quote .Name}}];
+			constructor_{{$index}} [shape=plaintext label={{quote .Caption}}];
 			{{with .ErrorType}}color={{.Color}};{{end}}
+			{{- if .Critical}}style=bold;{{end}}
 			{{range .Results}}
 				{{- quote .String}} [{{.Attributes}}];
 			{{end}}
-		}
+		}{{if .Cluster}}
+		}{{end}}
 		{{range .Params}}
 			constructor_{{$index}} -> {{quote .String}} [ltail=cluster_{{$index}}{{if .Optional}} style=dashed{{end}}];
 		{{end}}
 		{{range .GroupParams}}
 			constructor_{{$index}} -> {{quote .String}} [ltail=cluster_{{$index}}];
 		{{end -}}
+		{{end -}}
+	{{end}}
+	{{- range $index, $d := .Decorators}}
+		decorator_{{$index}} [shape=box style=dashed label={{quote .Name}}];
+		{{range .Params}}
+			decorator_{{$index}} -> {{quote .String}}{{if .Optional}} [style=dashed]{{end}};
+		{{end}}
+		{{range .GroupParams}}
+			decorator_{{$index}} -> {{quote .String}};
+		{{end}}
+		{{range .Results}}
+			decorator_{{$index}} -> {{quote .String}} [{{$d.EdgeAttributes}}];
+		{{end}}
+		{{range .Groups}}
+			decorator_{{$index}} -> {{quote .String}} [{{$d.EdgeAttributes}}];
+		{{end -}}
 	{{end}}
 	{{range .Failed.TransitiveFailures}}
 		{{- quote .String}} [color=orange];
@@ -130,13 +482,72 @@ var _graphTmpl = template.Must(
 	{{range .Failed.RootCauses}}
 		{{- quote .String}} [color=red];
 	{{end}}
+	{{- range .Failed.Suggestions}}
+		{{- quote .Missing.String}} -> {{quote .Suggested.String}} [style=dotted label="did you mean?"];
+	{{end}}
 }`))
 
+// dotTemplateData is the data of the template rendering DOT graphs.
+type dotTemplateData struct {
+	*dot.Graph
+
+	Styles *dot.Styles
+}
+
 // Visualize parses the graph in Container c into DOT format and writes it to
-// io.Writer w.
+// io.Writer w. Use VisualizeFormat to render the graph in another format.
 func Visualize(c *Container, w io.Writer, opts ...VisualizeOption) error {
+	var options visualizeOptions
+	for _, o := range opts {
+		o.applyVisualizeOption(&options)
+	}
+
 	dg := c.createGraph()
+	if options.Durations {
+		c.annotateDurations(dg)
+	}
+	return visualizeGraph(dg, w, opts...)
+}
+
+// annotateDurations records the durations of the constructors and the
+// critical path of the container in the graph.
+func (c *Container) annotateDurations(dg *dot.Graph) {
+	cp := c.CriticalPath()
+	nodes := make(map[dot.CtorID]PathNode, len(cp.Nodes))
+	for _, n := range cp.Nodes {
+		nodes[dot.CtorID(n.Provider.ID)] = n
+	}
+	critical := make(map[dot.CtorID]struct{}, len(cp.Path))
+	for _, n := range cp.Path {
+		critical[dot.CtorID(n.Provider.ID)] = struct{}{}
+	}
+	for _, ctor := range dg.Ctors {
+		ctor.Duration = nodes[ctor.ID].Duration
+		_, ctor.Critical = critical[ctor.ID]
+	}
+}
 
+// VisualizeConstructors writes the graph of the given constructors to w, as
+// Visualize would for a container they were provided to. The graph is built
+// from the signatures of the constructors only: no container is created and
+// the constructors are never called, so build-time tooling may render the
+// shape of an application without running any of its code.
+//
+//	err := dig.VisualizeConstructors(w, []interface{}{NewConfig, NewServer},
+//		dig.VisualizeFormat(dig.FormatJSON))
+//
+// Constructors may be annotated with Annotate. The options of Provide are
+// not supported: use Annotate with ResultTags to name the results of a
+// constructor or add them to value groups.
+func VisualizeConstructors(w io.Writer, constructors []interface{}, opts ...VisualizeOption) error {
+	dg, err := graphOfConstructors(constructors)
+	if err != nil {
+		return err
+	}
+	return visualizeGraph(dg, w, opts...)
+}
+
+func visualizeGraph(dg *dot.Graph, w io.Writer, opts ...VisualizeOption) error {
 	var options visualizeOptions
 	for _, o := range opts {
 		o.applyVisualizeOption(&options)
@@ -148,7 +559,119 @@ func Visualize(c *Container, w io.Writer, opts ...VisualizeOption) error {
 		}
 	}
 
-	return _graphTmpl.Execute(w, dg)
+	if options.Focus != nil {
+		dg.Focus(options.Focus, options.Depth)
+	}
+	if options.Invoke != nil {
+		params, err := invokeDotParams(options.Invoke)
+		if err != nil {
+			return err
+		}
+		dg.FocusParams(params, options.Depth)
+	}
+
+	switch options.GroupBy {
+	case GroupByNone:
+	case GroupByPackage:
+		dg.ClusterBy(func(c *dot.Ctor) string { return c.Package })
+	case GroupByModule:
+		dg.ClusterBy(func(c *dot.Ctor) string {
+			if c.Module == "" {
+				return ""
+			}
+			return "module " + c.Module
+		})
+	default:
+		return newErrInvalidInput(fmt.Sprintf("unknown visualization grouping %v", options.GroupBy), nil)
+	}
+
+	if r, ok := _renderers[options.Format]; ok {
+		return r.Render(w, dg)
+	}
+
+	switch options.Format {
+	case FormatDOT:
+		if options.Style == nil {
+			return _graphTmpl.Execute(w, dg)
+		}
+		return _styledGraphTmpl.Execute(w, dotTemplateData{dg, dg.Styles(options.Style.styler())})
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(dg)
+	case FormatHTML:
+		return dg.WriteHTML(w)
+	default:
+		return newErrInvalidInput(fmt.Sprintf("unknown visualization format %v", options.Format), nil)
+	}
+}
+
+// GraphJSON writes a JSON representation of the graph in the container to w.
+//
+// The document lists the constructors of the container with their location,
+// parameters and results, and the value groups with the constructors that
+// contribute to them. Types are identified by their string representation
+// and constructors by their position in the "constructors" array, so the
+// output is stable across runs of the same program.
+//
+// GraphJSON is equivalent to
+//
+//	dig.Visualize(c, w, dig.VisualizeFormat(dig.FormatJSON))
+func (c *Container) GraphJSON(w io.Writer) error {
+	return Visualize(c, w, VisualizeFormat(FormatJSON))
+}
+
+// VisualizeHTML writes the graph in the container to w as a self-contained
+// HTML page, which is easier to explore than static output for graphs with
+// many constructors.
+//
+// The page may be opened in a browser as-is: the graph can be zoomed and
+// panned, searched by type name, and clicking a constructor highlights its
+// dependencies and the constructors that depend on it.
+//
+// VisualizeHTML is equivalent to
+//
+//	dig.Visualize(c, w, dig.VisualizeFormat(dig.FormatHTML))
+func (c *Container) VisualizeHTML(w io.Writer) error {
+	return Visualize(c, w, VisualizeFormat(FormatHTML))
+}
+
+// VisualizeDiff writes to w a DOT graph of the differences between the
+// graphs of two containers, which helps review changes to the wiring of an
+// application.
+//
+// Constructors are identified by their name and the values they produce.
+// Constructors and dependencies that are only in after are drawn in green,
+// and the ones that are only in before are drawn dashed in red.
+//
+// See VisualizeDiffJSON to compare graphs saved by other programs.
+func VisualizeDiff(before, after *Container, w io.Writer) error {
+	return dot.Diff(before.createGraph().DiffGraph(), after.createGraph().DiffGraph()).WriteDOT(w)
+}
+
+// VisualizeDiffJSON writes to w a DOT graph of the differences between two
+// graphs saved with Container.GraphJSON, like VisualizeDiff does for two
+// containers. This allows comparing the wiring of an application across
+// builds, such as that of a main branch with that of a pull request:
+//
+//	// In each build:
+//	err := c.GraphJSON(f)
+//
+//	// To compare them:
+//	err := dig.VisualizeDiffJSON(mainGraph, prGraph, os.Stdout)
+//
+// A saved graph may be compared with a container by saving the graph of
+// the container to a buffer with GraphJSON first.
+func VisualizeDiffJSON(before, after io.Reader, w io.Writer) error {
+	bg, err := dot.ReadDiffGraph(before)
+	if err != nil {
+		return newErrInvalidInput("cannot read the graph to compare from", err)
+	}
+	ag, err := dot.ReadDiffGraph(after)
+	if err != nil {
+		return newErrInvalidInput("cannot read the graph to compare to", err)
+	}
+	return dot.Diff(bg, ag).WriteDOT(w)
 }
 
 // CanVisualizeError returns true if the error is an errVisualizer.
@@ -178,15 +701,79 @@ func (s *Scope) createGraph() *dot.Graph {
 		dg.AddCtor(newDotCtor(n), n.paramList.DotParam(), n.resultList.DotResult())
 	}
 
+	for _, n := range s.decoratorNodes {
+		dg.AddDecorator(newDotDecorator(n), n.params.DotParam(), n.results.DotResult())
+	}
+
 	return dg
 }
 
+// graphOfConstructors builds the graph of the given constructors without
+// providing them to a container.
+func graphOfConstructors(constructors []interface{}) (*dot.Graph, error) {
+	// Parameters are parsed against a Scope that nothing is provided to.
+	s := newScope()
+	dg := dot.NewGraph()
+	for _, ctor := range constructors {
+		ctor, location, err := unannotate(ctor)
+		if err == nil {
+			err = validateConstructor(ctor)
+		}
+		if err != nil {
+			if location != nil {
+				return nil, errProvide{Func: location, Reason: err}
+			}
+			return nil, err
+		}
+		if location == nil {
+			location = digreflect.InspectFunc(ctor)
+		}
+
+		ctype := reflect.TypeOf(ctor)
+		params, err := newParamList(ctype, s)
+		if err != nil {
+			return nil, errProvide{Func: location, Reason: err}
+		}
+		results, err := newResultList(ctype, resultOptions{})
+		if err != nil {
+			return nil, errProvide{Func: location, Reason: err}
+		}
+		dotResults := results.DotResult()
+		if len(dotResults) == 0 {
+			return nil, errProvide{Func: location, Reason: newErrInvalidInput(
+				fmt.Sprintf("%v must provide at least one non-error type", ctype), nil)}
+		}
+		dg.AddCtor(&dot.Ctor{
+			ID:      dot.CtorID(reflect.ValueOf(ctor).Pointer()),
+			Name:    location.Name,
+			Package: location.Package,
+			File:    location.File,
+			Line:    location.Line,
+		}, params.DotParam(), dotResults)
+	}
+	return dg, nil
+}
+
 func newDotCtor(n *constructorNode) *dot.Ctor {
 	return &dot.Ctor{
+		ID:       n.id,
+		Name:     n.location.Name,
+		Package:  n.location.Package,
+		Module:   n.origS.module,
+		File:     n.location.File,
+		Line:     n.location.Line,
+		Supplied: n.supplied != nil,
+		Fallback: n.supplied != nil && n.supplied.missing,
+	}
+}
+
+func newDotDecorator(n *decoratorNode) *dot.Decorator {
+	return &dot.Decorator{
 		ID:      n.id,
 		Name:    n.location.Name,
 		Package: n.location.Package,
 		File:    n.location.File,
 		Line:    n.location.Line,
+		Local:   n.local,
 	}
 }
@@ -37,7 +37,20 @@ func VerifyVisualization(t *testing.T, testname string, c *Container, opts ...Vi
 	var b bytes.Buffer
 	require.NoError(t, Visualize(c, &b, opts...))
 
-	dotFile := filepath.Join("testdata", testname+".dot")
+	var options visualizeOptions
+	for _, o := range opts {
+		o.applyVisualizeOption(&options)
+	}
+	ext := ".dot"
+	switch options.Format {
+	case FormatMermaid:
+		ext = ".mmd"
+	case FormatD2:
+		ext = ".d2"
+	case FormatPlantUML:
+		ext = ".puml"
+	}
+	dotFile := filepath.Join("testdata", testname+ext)
 
 	if *generate {
 		err := os.WriteFile(dotFile, b.Bytes(), 0644)
@@ -22,6 +22,7 @@ package dig_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -30,9 +31,10 @@ import (
 	"testing"
 
 	"github.com/alexisvisco/dig"
-	"github.com/alexisvisco/dig/internal/digtest"
+	"github.com/alexisvisco/dig/digtest"
 	"github.com/alexisvisco/dig/internal/dot"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDotGraph(t *testing.T) {
@@ -582,6 +584,762 @@ func TestVisualize(t *testing.T) {
 
 		dig.VerifyVisualization(t, "missingDep", c.Container, dig.VisualizeError(err))
 	})
+
+	t.Run("decorated types", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type in struct {
+			dig.In
+
+			A t1
+			B t2 `optional:"true"`
+		}
+
+		type groupIn struct {
+			dig.In
+
+			A []t3 `group:"foo"`
+		}
+
+		type groupOut struct {
+			dig.Out
+
+			A []t3 `group:"foo"`
+		}
+
+		c.Provide(func() t1 { return t1{} })
+		c.Provide(func() t3 { return t3{} }, dig.Group("foo"))
+		c.RequireDecorate(func(in) t1 { return t1{} })
+		c.RequireDecorate(func(in groupIn) groupOut {
+			return groupOut{A: in.A}
+		}, dig.PropagateToDescendants(false))
+
+		dig.VerifyVisualization(t, "decorated", c.Container)
+	})
+
+	t.Run("supplied values", func(t *testing.T) {
+		c := digtest.New(t)
+
+		require.NoError(t, c.Supply(t1{}, t2{}))
+		require.NoError(t, c.Supply(t3{}, dig.Group("foo")))
+		c.RequireProvide(func(t1, t2) t4 { return t4{} })
+
+		dig.VerifyVisualization(t, "supplied", c.Container)
+	})
+
+	t.Run("missing type suggestions", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.RequireProvide(func() *t1 { return &t1{} })
+		err := c.Invoke(func(t1) {})
+
+		dig.VerifyVisualization(t, "missingSuggestion", c.Container, dig.VisualizeError(err))
+	})
+
+	t.Run("focus", func(t *testing.T) {
+		type t5 struct{}
+
+		newContainer := func(t *testing.T) *digtest.Container {
+			c := digtest.New(t)
+			c.RequireProvide(func() t1 { return t1{} })
+			c.RequireProvide(func(t1) t2 { return t2{} })
+			c.RequireProvide(func(t2) t3 { return t3{} })
+			c.RequireProvide(func(t3) t4 { return t4{} })
+			c.RequireProvide(func() t5 { return t5{} })
+			return c
+		}
+
+		t.Run("unlimited depth", func(t *testing.T) {
+			c := newContainer(t)
+			dig.VerifyVisualization(t, "focus", c.Container, dig.VisualizeFocus(new(t2)))
+		})
+
+		t.Run("limited depth", func(t *testing.T) {
+			c := newContainer(t)
+			dig.VerifyVisualization(t, "focusDepth", c.Container,
+				dig.VisualizeFocus(new(t3)), dig.VisualizeDepth(1))
+		})
+	})
+
+	t.Run("group by", func(t *testing.T) {
+		newContainer := func(t *testing.T) *digtest.Container {
+			m := dig.NewModule("db")
+			m.Provide(func() t1 { return t1{} }, dig.Export(true))
+
+			c := digtest.New(t)
+			require.NoError(t, c.Apply(m))
+			c.RequireProvide(func(t1) t2 { return t2{} })
+			return c
+		}
+
+		t.Run("package", func(t *testing.T) {
+			c := newContainer(t)
+			dig.VerifyVisualization(t, "groupByPackage", c.Container, dig.VisualizeGroupBy(dig.GroupByPackage))
+		})
+
+		t.Run("module", func(t *testing.T) {
+			c := newContainer(t)
+			dig.VerifyVisualization(t, "groupByModule", c.Container, dig.VisualizeGroupBy(dig.GroupByModule))
+		})
+
+		t.Run("unknown", func(t *testing.T) {
+			c := newContainer(t)
+			err := dig.Visualize(c.Container, io.Discard, dig.VisualizeGroupBy(dig.GroupBy(42)))
+			assert.ErrorContains(t, err, "unknown visualization grouping GroupBy(42)")
+		})
+	})
+
+	t.Run("flattened grouped types", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type in struct {
+			dig.In
+
+			A []t3 `group:"foo"`
+		}
+
+		type out struct {
+			dig.Out
+
+			A []t3 `group:"foo,flatten"`
+		}
+
+		c.RequireProvide(func() out { return out{A: []t3{{}, {}}} })
+		c.RequireProvide(func() t3 { return t3{} }, dig.Group("foo"))
+		c.RequireProvide(func(in) t2 { return t2{} })
+
+		dig.VerifyVisualization(t, "groupedFlatten", c.Container)
+	})
+
+	t.Run("decorated named value", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.RequireProvide(func() t1 { return t1{} }, dig.Name("primary"))
+		c.RequireProvide(func() t1 { return t1{} }, dig.Name("replica"))
+		c.RequireProvide(func() t2 { return t2{} })
+		c.RequireDecorate(func(t1, t2) t1 { return t1{} }, dig.DecorateNamed("primary"))
+
+		dig.VerifyVisualization(t, "decorated_named", c.Container)
+	})
+}
+
+func TestVisualizeMermaid(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+	type t3 struct{}
+	type t4 struct{}
+
+	t.Parallel()
+
+	mermaid := dig.VisualizeFormat(dig.FormatMermaid)
+
+	t.Run("empty graph in container", func(t *testing.T) {
+		c := digtest.New(t)
+		dig.VerifyVisualization(t, "empty", c.Container, mermaid)
+	})
+
+	t.Run("simple graph", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.Provide(func() (t1, t2) { return t1{}, t2{} })
+		c.Provide(func(A t1, B t2) (t3, t4) { return t3{}, t4{} })
+		dig.VerifyVisualization(t, "simple", c.Container, mermaid)
+	})
+
+	t.Run("named and optional types", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type in struct {
+			dig.In
+
+			A t1 `name:"foo"`
+			B t2 `optional:"true"`
+		}
+
+		c.Provide(func() t1 { return t1{} }, dig.Name("foo"))
+		c.Provide(func(in) t3 { return t3{} })
+		dig.VerifyVisualization(t, "named_optional", c.Container, mermaid)
+	})
+
+	t.Run("grouped types", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type in struct {
+			dig.In
+
+			A []t3 `group:"foo"`
+		}
+
+		c.Provide(func() t3 { return t3{} }, dig.Group("foo"))
+		c.Provide(func() t3 { return t3{} }, dig.Group("foo"))
+		c.Provide(func(in) t2 { return t2{} })
+
+		dig.VerifyVisualization(t, "grouped", c.Container, mermaid)
+	})
+
+	t.Run("missing types", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.Provide(func(A t1, B t2) t4 { return t4{} })
+		err := c.Invoke(func(t4 t4) {})
+
+		dig.VerifyVisualization(t, "missing", c.Container, mermaid, dig.VisualizeError(err))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		c := digtest.New(t)
+
+		err := dig.Visualize(c.Container, io.Discard, dig.VisualizeFormat(dig.Format(42)))
+		assert.ErrorContains(t, err, "unknown visualization format Format(42)")
+	})
+
+	t.Run("decorated types", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type in struct {
+			dig.In
+
+			A t1
+			B t2 `optional:"true"`
+		}
+
+		type groupIn struct {
+			dig.In
+
+			A []t3 `group:"foo"`
+		}
+
+		type groupOut struct {
+			dig.Out
+
+			A []t3 `group:"foo"`
+		}
+
+		c.Provide(func() t1 { return t1{} })
+		c.Provide(func() t3 { return t3{} }, dig.Group("foo"))
+		c.RequireDecorate(func(in) t1 { return t1{} })
+		c.RequireDecorate(func(in groupIn) groupOut {
+			return groupOut{A: in.A}
+		}, dig.PropagateToDescendants(false))
+
+		dig.VerifyVisualization(t, "decorated", c.Container, mermaid)
+	})
+
+	t.Run("supplied values", func(t *testing.T) {
+		c := digtest.New(t)
+
+		require.NoError(t, c.Supply(t1{}, dig.Name("foo")))
+		c.RequireProvide(func(p struct {
+			dig.In
+
+			A t1 `name:"foo"`
+		}) t2 {
+			return t2{}
+		})
+
+		dig.VerifyVisualization(t, "supplied", c.Container, mermaid)
+	})
+}
+
+func TestVisualizeTextFormats(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+	type t3 struct{}
+	type t4 struct{}
+
+	t.Parallel()
+
+	for _, f := range []dig.Format{dig.FormatD2, dig.FormatPlantUML} {
+		f := f
+		t.Run(f.String(), func(t *testing.T) {
+			c := digtest.New(t)
+
+			type in struct {
+				dig.In
+
+				A t1   `name:"foo"`
+				B t2   `optional:"true"`
+				C []t3 `group:"bar"`
+			}
+
+			require.NoError(t, c.Supply(t3{}, dig.Group("bar")))
+			c.RequireProvide(func() t1 { return t1{} }, dig.Name("foo"))
+			c.RequireProvide(func(in) t4 { return t4{} })
+			c.RequireDecorate(func(t4) t4 { return t4{} })
+
+			dig.VerifyVisualization(t, "formats", c.Container, dig.VisualizeFormat(f))
+		})
+	}
+}
+
+func TestVisualizeFormatString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "VisualizeFormat(FormatDOT)", fmt.Sprint(dig.VisualizeFormat(dig.FormatDOT)))
+	assert.Equal(t, "VisualizeFormat(FormatMermaid)", fmt.Sprint(dig.VisualizeFormat(dig.FormatMermaid)))
+	assert.Equal(t, "VisualizeFormat(FormatJSON)", fmt.Sprint(dig.VisualizeFormat(dig.FormatJSON)))
+	assert.Equal(t, "VisualizeFormat(FormatHTML)", fmt.Sprint(dig.VisualizeFormat(dig.FormatHTML)))
+	assert.Equal(t, "VisualizeFormat(FormatD2)", fmt.Sprint(dig.VisualizeFormat(dig.FormatD2)))
+	assert.Equal(t, "VisualizeFormat(FormatPlantUML)", fmt.Sprint(dig.VisualizeFormat(dig.FormatPlantUML)))
+}
+
+func TestVisualizeFocusString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "VisualizeFocus(io.Reader)", fmt.Sprint(dig.VisualizeFocus(new(io.Reader))))
+	assert.Equal(t, "VisualizeDepth(2)", fmt.Sprint(dig.VisualizeDepth(2)))
+}
+
+func TestVisualizeInvoke(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+	type t3 struct{}
+	type t4 struct{}
+
+	t.Parallel()
+
+	type in struct {
+		dig.In
+
+		T2 t2
+		T3 []t3 `group:"foo"`
+	}
+
+	c := digtest.New(t)
+	c.RequireProvide(func() t1 { return t1{} })
+	c.RequireProvide(func(t1) t2 { return t2{} })
+	c.RequireProvide(func() t3 { return t3{} }, dig.Group("foo"))
+	c.RequireProvide(func(t2) t4 { return t4{} })
+
+	parse := func(t *testing.T, opts ...dig.VisualizeOption) []string {
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b, append(opts, dig.VisualizeFormat(dig.FormatJSON))...))
+
+		var got struct {
+			Constructors []struct {
+				Name string `json:"name"`
+			} `json:"constructors"`
+		}
+		require.NoError(t, json.Unmarshal(b.Bytes(), &got))
+		var names []string
+		for _, ctor := range got.Constructors {
+			names = append(names, ctor.Name)
+		}
+		return names
+	}
+
+	t.Run("dependencies of the function", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t,
+			[]string{"TestVisualizeInvoke.func1", "TestVisualizeInvoke.func2"},
+			parse(t, dig.VisualizeInvoke(func(t2) {})))
+	})
+
+	t.Run("parameter objects and value groups", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t,
+			[]string{"TestVisualizeInvoke.func1", "TestVisualizeInvoke.func2", "TestVisualizeInvoke.func3"},
+			parse(t, dig.VisualizeInvoke(func(in) {})))
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t,
+			[]string{"TestVisualizeInvoke.func4"},
+			parse(t, dig.VisualizeInvoke(func(t4) {}), dig.VisualizeDepth(1)))
+	})
+
+	t.Run("annotated", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, parse(t, dig.VisualizeInvoke(dig.Annotate(func(t1) {}, dig.ParamTags(`name:"other"`)))))
+	})
+
+	t.Run("the container is not modified", func(t *testing.T) {
+		t.Parallel()
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b, dig.VisualizeInvoke(func(in) {})))
+		assert.Len(t, parse(t), 4)
+	})
+
+	t.Run("not a function", func(t *testing.T) {
+		t.Parallel()
+
+		err := dig.Visualize(c.Container, io.Discard, dig.VisualizeInvoke(t1{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't visualize the invoke of non-function {} (type dig_test.t1)")
+	})
+}
+
+func TestVisualizeInvokeString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "VisualizeInvoke(func(io.Reader) error)",
+		fmt.Sprint(dig.VisualizeInvoke(func(io.Reader) error { return nil })))
+	assert.Regexp(t, `^VisualizeInvoke\(Annotate\(.*TestVisualizeInvokeString.func2 \(.*visualize_test.go:\d+\)\)\)$`,
+		fmt.Sprint(dig.VisualizeInvoke(dig.Annotate(func(string) {}, dig.ParamTags(`name:"foo"`)))))
+}
+
+func TestVisualizeGroupByString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "VisualizeGroupBy(GroupByNone)", fmt.Sprint(dig.VisualizeGroupBy(dig.GroupByNone)))
+	assert.Equal(t, "VisualizeGroupBy(GroupByPackage)", fmt.Sprint(dig.VisualizeGroupBy(dig.GroupByPackage)))
+	assert.Equal(t, "VisualizeGroupBy(GroupByModule)", fmt.Sprint(dig.VisualizeGroupBy(dig.GroupByModule)))
+	assert.Equal(t, "VisualizeGroupBy(GroupBy(42))", fmt.Sprint(dig.VisualizeGroupBy(dig.GroupBy(42))))
+}
+
+func TestGraphJSON(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+	type t3 struct{}
+	type t4 struct{}
+
+	t.Parallel()
+
+	type node struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		Group    string `json:"group"`
+		Optional bool   `json:"optional"`
+		Flatten  bool   `json:"flatten"`
+	}
+	type graph struct {
+		Constructors []struct {
+			Name        string `json:"name"`
+			Package     string `json:"package"`
+			Supplied    bool   `json:"supplied"`
+			File        string `json:"file"`
+			Line        int    `json:"line"`
+			Params      []node `json:"params"`
+			GroupParams []node `json:"groupParams"`
+			Results     []node `json:"results"`
+			Error       string `json:"error"`
+		} `json:"constructors"`
+		Decorators []struct {
+			Name    string `json:"name"`
+			Params  []node `json:"params"`
+			Results []node `json:"results"`
+			Groups  []node `json:"groups"`
+			Local   bool   `json:"local"`
+		} `json:"decorators"`
+		Groups []struct {
+			Type         string `json:"type"`
+			Name         string `json:"name"`
+			Constructors []int  `json:"constructors"`
+		} `json:"groups"`
+		Failed *struct {
+			RootCauses         []node `json:"rootCauses"`
+			TransitiveFailures []node `json:"transitiveFailures"`
+		} `json:"failed"`
+	}
+
+	t.Run("empty graph", func(t *testing.T) {
+		c := digtest.New(t)
+
+		var b bytes.Buffer
+		require.NoError(t, c.GraphJSON(&b))
+		assert.JSONEq(t, `{"constructors": [], "groups": []}`, b.String())
+	})
+
+	t.Run("constructors and groups", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type in struct {
+			dig.In
+
+			A t1   `name:"foo"`
+			B t2   `optional:"true"`
+			C []t3 `group:"bar"`
+		}
+
+		c.Provide(func() t1 { return t1{} }, dig.Name("foo"))
+		c.Provide(func() t3 { return t3{} }, dig.Group("bar"))
+		c.Provide(func(in) t4 { return t4{} })
+
+		var b bytes.Buffer
+		require.NoError(t, c.GraphJSON(&b))
+
+		var g graph
+		require.NoError(t, json.Unmarshal(b.Bytes(), &g))
+		require.Len(t, g.Constructors, 3)
+		assert.Nil(t, g.Failed)
+
+		foo := g.Constructors[0]
+		assert.Equal(t, "github.com/alexisvisco/dig_test", foo.Package)
+		assert.Equal(t, "TestGraphJSON.func2.1", foo.Name)
+		assert.Contains(t, foo.File, "visualize_test.go")
+		assert.NotZero(t, foo.Line)
+		assert.Empty(t, foo.Params)
+		assert.Equal(t, []node{{Type: "dig_test.t1", Name: "foo"}}, foo.Results)
+
+		bar := g.Constructors[1]
+		assert.Equal(t, []node{{Type: "dig_test.t3", Group: "bar"}}, bar.Results)
+
+		consumer := g.Constructors[2]
+		assert.Equal(t, []node{
+			{Type: "dig_test.t1", Name: "foo"},
+			{Type: "dig_test.t2", Optional: true},
+		}, consumer.Params)
+		assert.Equal(t, []node{{Type: "dig_test.t3", Group: "bar"}}, consumer.GroupParams)
+
+		require.Len(t, g.Groups, 1)
+		assert.Equal(t, "dig_test.t3", g.Groups[0].Type)
+		assert.Equal(t, "bar", g.Groups[0].Name)
+		assert.Equal(t, []int{1}, g.Groups[0].Constructors)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.Provide(func(t1) t2 { return t2{} })
+		err := c.Invoke(func(t2) {})
+		require.Error(t, err)
+
+		var b bytes.Buffer
+		require.NoError(t, dig.Visualize(c.Container, &b,
+			dig.VisualizeFormat(dig.FormatJSON), dig.VisualizeError(err)))
+
+		var g graph
+		require.NoError(t, json.Unmarshal(b.Bytes(), &g))
+		require.Len(t, g.Constructors, 1)
+		assert.Equal(t, "transitiveFailure", g.Constructors[0].Error)
+		require.NotNil(t, g.Failed)
+		assert.Equal(t, []node{{Type: "dig_test.t1"}}, g.Failed.RootCauses)
+		assert.Equal(t, []node{{Type: "dig_test.t2"}}, g.Failed.TransitiveFailures)
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.Provide(func() t1 { return t1{} })
+		c.RequireDecorate(func(t1) t1 { return t1{} }, dig.PropagateToDescendants(false))
+
+		var b bytes.Buffer
+		require.NoError(t, c.GraphJSON(&b))
+
+		var g graph
+		require.NoError(t, json.Unmarshal(b.Bytes(), &g))
+		require.Len(t, g.Decorators, 1)
+		assert.Equal(t, []node{{Type: "dig_test.t1"}}, g.Decorators[0].Params)
+		assert.Equal(t, []node{{Type: "dig_test.t1"}}, g.Decorators[0].Results)
+		assert.Empty(t, g.Decorators[0].Groups)
+		assert.True(t, g.Decorators[0].Local)
+	})
+
+	t.Run("named decorators", func(t *testing.T) {
+		c := digtest.New(t)
+
+		c.Provide(func() t1 { return t1{} }, dig.Name("primary"))
+		c.RequireDecorate(func(t1) t1 { return t1{} }, dig.DecorateNamed("primary"))
+
+		var b bytes.Buffer
+		require.NoError(t, c.GraphJSON(&b))
+
+		var g graph
+		require.NoError(t, json.Unmarshal(b.Bytes(), &g))
+		require.Len(t, g.Decorators, 1)
+		assert.Equal(t, []node{{Type: "dig_test.t1", Name: "primary"}}, g.Decorators[0].Params)
+		assert.Equal(t, []node{{Type: "dig_test.t1", Name: "primary"}}, g.Decorators[0].Results)
+	})
+
+	t.Run("supplied values", func(t *testing.T) {
+		c := digtest.New(t)
+
+		require.NoError(t, c.Supply(t1{}))
+		c.RequireProvide(func(t1) t2 { return t2{} })
+
+		var b bytes.Buffer
+		require.NoError(t, c.GraphJSON(&b))
+
+		var g graph
+		require.NoError(t, json.Unmarshal(b.Bytes(), &g))
+		require.Len(t, g.Constructors, 2)
+		assert.True(t, g.Constructors[0].Supplied)
+		assert.Equal(t, []node{{Type: "dig_test.t1"}}, g.Constructors[0].Results)
+		assert.False(t, g.Constructors[1].Supplied)
+	})
+
+	t.Run("flattened groups", func(t *testing.T) {
+		c := digtest.New(t)
+
+		type out struct {
+			dig.Out
+
+			A []t1 `group:"foo,flatten"`
+		}
+
+		c.RequireProvide(func() out { return out{} })
+
+		var b bytes.Buffer
+		require.NoError(t, c.GraphJSON(&b))
+
+		var g graph
+		require.NoError(t, json.Unmarshal(b.Bytes(), &g))
+		require.Len(t, g.Constructors, 1)
+		assert.Equal(t, []node{{Type: "dig_test.t1", Group: "foo", Flatten: true}}, g.Constructors[0].Results)
+	})
+}
+
+func TestVisualizeHTML(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+
+	t.Parallel()
+
+	c := digtest.New(t)
+	c.RequireProvide(func() t1 { return t1{} }, dig.Group("</script>"))
+	c.RequireProvide(func(struct {
+		dig.In
+
+		T1 []t1 `group:"</script>"`
+	}) t2 {
+		return t2{}
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, c.VisualizeHTML(&buf))
+	page := buf.String()
+
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"), "must be an HTML page")
+	assert.Equal(t, 1, strings.Count(page, "</script>"), "group name must be escaped")
+
+	// The graph is embedded as JSON in the page.
+	_, graph, ok := strings.Cut(page, "var graph = ")
+	require.True(t, ok, "graph not found in page")
+	graph, _, _ = strings.Cut(graph, ";\n")
+
+	var got struct {
+		Constructors []struct {
+			Name string `json:"name"`
+		} `json:"constructors"`
+		Groups []struct {
+			Name string `json:"name"`
+		} `json:"groups"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(graph), &got))
+	require.Len(t, got.Constructors, 2)
+	require.Len(t, got.Groups, 1)
+	assert.Equal(t, "</script>", got.Groups[0].Name)
+}
+
+func TestVisualizeDiff(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+
+	t.Parallel()
+
+	newT1 := func() t1 { return t1{} }
+	newT2 := func(t1) t2 { return t2{} }
+
+	before := digtest.New(t)
+	before.RequireProvide(newT1)
+
+	after := digtest.New(t)
+	after.RequireProvide(newT1)
+	after.RequireProvide(newT2)
+
+	var b bytes.Buffer
+	require.NoError(t, dig.VisualizeDiff(before.Container, after.Container, &b))
+	out := b.String()
+
+	assert.Contains(t, out, `label="TestVisualizeDiff.func1"];`, "unchanged constructor")
+	assert.Contains(t, out, `label="TestVisualizeDiff.func2" color=green];`, "added constructor")
+	assert.Contains(t, out, `"dig_test.t2" -> "github.com/alexisvisco/dig_test.TestVisualizeDiff.func2(dig_test.t2)" [color=green];`)
+	assert.NotContains(t, out, "color=red")
+
+	t.Run("saved graphs", func(t *testing.T) {
+		t.Parallel()
+
+		var beforeJSON, afterJSON bytes.Buffer
+		require.NoError(t, before.GraphJSON(&beforeJSON))
+		require.NoError(t, after.GraphJSON(&afterJSON))
+
+		var got bytes.Buffer
+		require.NoError(t, dig.VisualizeDiffJSON(&beforeJSON, &afterJSON, &got))
+		assert.Equal(t, out, got.String(), "saved graphs must compare like containers")
+	})
+
+	t.Run("invalid saved graph", func(t *testing.T) {
+		t.Parallel()
+
+		var afterJSON bytes.Buffer
+		require.NoError(t, after.GraphJSON(&afterJSON))
+
+		err := dig.VisualizeDiffJSON(strings.NewReader("not json"), &afterJSON, io.Discard)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot read the graph to compare from")
+	})
+}
+
+func TestVisualizeConstructors(t *testing.T) {
+	type t1 struct{}
+	type t2 struct{}
+	type t3 struct{}
+
+	t.Parallel()
+
+	type in struct {
+		dig.In
+
+		T1 t1   `name:"foo"`
+		T2 []t2 `group:"bar"`
+	}
+
+	called := false
+	constructors := []interface{}{
+		dig.Annotate(func() t1 { called = true; return t1{} }, dig.ResultTags(`name:"foo"`)),
+		dig.Annotate(func() t2 { called = true; return t2{} }, dig.ResultTags(`group:"bar"`)),
+		func(in) t3 { called = true; return t3{} },
+	}
+
+	t.Run("matches Visualize", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		for _, ctor := range constructors {
+			c.RequireProvide(ctor)
+		}
+
+		for _, format := range []dig.Format{dig.FormatDOT, dig.FormatJSON, dig.FormatMermaid} {
+			var want, got bytes.Buffer
+			require.NoError(t, dig.Visualize(c.Container, &want, dig.VisualizeFormat(format)))
+			require.NoError(t, dig.VisualizeConstructors(&got, constructors, dig.VisualizeFormat(format)))
+			assert.Equal(t, want.String(), got.String(), "format %v", format)
+		}
+		assert.False(t, called, "constructors must not be called")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+
+		var b bytes.Buffer
+		require.NoError(t, dig.VisualizeConstructors(&b, constructors, dig.VisualizeFocus(t3{}), dig.VisualizeDepth(1)))
+		assert.Contains(t, b.String(), `"dig_test.t3"`)
+		assert.Contains(t, b.String(), `"dig_test.t1[name=foo]"`)
+	})
+
+	t.Run("not a function", func(t *testing.T) {
+		t.Parallel()
+
+		err := dig.VisualizeConstructors(io.Discard, []interface{}{t1{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must provide constructor function, got {} (type dig_test.t1)")
+	})
+
+	t.Run("invalid constructor", func(t *testing.T) {
+		t.Parallel()
+
+		err := dig.VisualizeConstructors(io.Discard, []interface{}{func() error { return nil }})
+		require.Error(t, err)
+		dig.AssertErrorMatches(t, err,
+			"cannot provide function",
+			"must provide at least one non-error type")
+	})
 }
 
 func TestVisualizeErrorString(t *testing.T) {
@@ -0,0 +1,168 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/alexisvisco/dig/internal/digreflect"
+)
+
+// WarmupReport lists the constructors called by Container.Warmup.
+type WarmupReport struct {
+	// Duration is how long Warmup took.
+	Duration time.Duration
+
+	// Built holds the constructors that were called, in the order they
+	// were called.
+	Built []WarmupEntry
+}
+
+// WarmupEntry is a constructor called by Container.Warmup.
+type WarmupEntry struct {
+	Provider ProviderInfo
+
+	// Duration is the time spent in the constructor itself, not counting
+	// its dependencies.
+	Duration time.Duration
+
+	// Error is the error the constructor failed with, if any.
+	Error error
+}
+
+// Warmup builds the values of the given keys, along with their
+// dependencies, so that latency-sensitive services pay the cost of
+// resolving the graph and calling the constructors before they serve
+// traffic rather than on the first request.
+//
+//	report, err := c.Warmup(new(*sql.DB), dig.Key{Type: reflect.TypeOf(Route{}), Group: "routes"})
+//	for _, b := range report.Built {
+//		log.Printf("%v took %v", b.Provider.Name, b.Duration)
+//	}
+//
+// Each key is either a pointer to a type, such as new(*sql.DB), or a Key to
+// warm up a named value or a value group. With MaxConcurrency, the keys
+// are built concurrently. Values that were already built are not built
+// again.
+//
+// The report lists the constructors that were called, even if Warmup
+// failed.
+func (c *Container) Warmup(keys ...interface{}) (WarmupReport, error) {
+	pc, _, _, _ := runtime.Caller(1)
+	s := c.scope
+	if err := s.checkOpen(); err != nil {
+		return WarmupReport{}, err
+	}
+
+	fields := make([]reflect.StructField, len(keys))
+	for i, k := range keys {
+		f, err := warmupField(i, k)
+		if err != nil {
+			return WarmupReport{}, err
+		}
+		fields[i] = f
+	}
+
+	var (
+		trace   Trace
+		invalid error
+	)
+	err := s.parallelism().do(func() error {
+		var po paramObject
+		if po, invalid = newPopulateObject(reflect.StructOf(fields), s); invalid != nil {
+			return nil
+		}
+
+		defer s.withTrace(&trace, "Warmup")()
+		_, err := s.buildObject(po)
+		return err
+	})
+	if invalid != nil {
+		return WarmupReport{}, invalid
+	}
+
+	report := WarmupReport{Duration: trace.Duration}
+	for _, span := range trace.Spans {
+		if span.Kind == TraceCall {
+			report.Built = append(report.Built, WarmupEntry{
+				Provider: span.Provider,
+				Duration: span.Runtime,
+				Error:    span.Error,
+			})
+		}
+	}
+	if err != nil {
+		return report, errWarmup{Func: digreflect.InspectFuncPC(pc), Reason: err}
+	}
+	return report, nil
+}
+
+// warmupField returns the field of the struct built by Warmup for the i-th
+// key.
+func warmupField(i int, k interface{}) (reflect.StructField, error) {
+	key, ok := k.(Key)
+	if !ok {
+		if t := reflect.TypeOf(k); t != nil && t.Kind() == reflect.Ptr {
+			key = Key{Type: t.Elem()}
+		}
+	}
+	if key.Type == nil {
+		return reflect.StructField{}, newErrInvalidInput(
+			fmt.Sprintf("can't warm up %v (type %T): keys must be pointers to types or dig.Key values", k, k), nil)
+	}
+
+	f := reflect.StructField{
+		Name: fmt.Sprintf("Key%d", i),
+		Type: key.Type,
+	}
+	switch {
+	case key.Group != "":
+		f.Type = reflect.SliceOf(key.Type)
+		f.Tag = reflect.StructTag(fmt.Sprintf("group:%q", key.Group))
+	case key.Name != "":
+		f.Tag = reflect.StructTag(fmt.Sprintf("name:%q", key.Name))
+	}
+	return f, nil
+}
+
+// errWarmup is returned when Container.Warmup could not build a value.
+type errWarmup struct {
+	Func   *digreflect.Func
+	Reason error
+}
+
+var _ digError = errWarmup{}
+
+func (e errWarmup) Error() string { return fmt.Sprint(e) }
+
+func (e errWarmup) Unwrap() error { return e.Reason }
+
+func (e errWarmup) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "cannot warm up the container at "+verb, e.Func)
+}
+
+func (e errWarmup) Format(w fmt.State, c rune) {
+	formatError(e, w, c)
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{}
+	type DB struct{}
+	type Route struct{ Path string }
+
+	names := func(report dig.WarmupReport) []string {
+		var names []string
+		for _, b := range report.Built {
+			names = append(names, b.Provider.Outputs[0].String())
+		}
+		return names
+	}
+
+	t.Run("builds values", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) *DB {
+			time.Sleep(5 * time.Millisecond)
+			return &DB{}
+		}, dig.Name("primary"))
+		c.RequireProvide(func() Route { return Route{Path: "/a"} }, dig.Group("routes"))
+		c.RequireProvide(func() Route { return Route{Path: "/b"} }, dig.Group("routes"))
+
+		report, err := c.Warmup(
+			dig.Key{Type: reflect.TypeOf(&DB{}), Name: "primary"},
+			dig.Key{Type: reflect.TypeOf(Route{}), Group: "routes"},
+		)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			`*dig_test.DB[name = "primary"]`,
+			"*dig_test.Config",
+			`dig_test.Route[group = "routes"]`,
+			`dig_test.Route[group = "routes"]`,
+		}, names(report))
+		for _, b := range report.Built {
+			if b.Provider.Outputs[0].String() == `*dig_test.DB[name = "primary"]` {
+				assert.GreaterOrEqual(t, b.Duration, 5*time.Millisecond)
+			}
+		}
+		assert.GreaterOrEqual(t, report.Duration, 5*time.Millisecond)
+
+		report, err = c.Warmup(new(*Config))
+		require.NoError(t, err)
+		assert.Empty(t, report.Built, "values are built once")
+	})
+
+	t.Run("concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		type A struct{}
+		type B struct{}
+
+		// Each constructor waits for the other one to be called.
+		var started sync.WaitGroup
+		started.Add(2)
+		barrier := func() error {
+			started.Done()
+			done := make(chan struct{})
+			go func() {
+				started.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-time.After(time.Second):
+				return errors.New("constructors were not called concurrently")
+			}
+		}
+
+		c := digtest.New(t, dig.MaxConcurrency(2))
+		c.RequireProvide(func() (*A, error) { return &A{}, barrier() })
+		c.RequireProvide(func() (*B, error) { return &B{}, barrier() })
+
+		report, err := c.Warmup(new(*A), new(*B))
+		require.NoError(t, err)
+		assert.Len(t, report.Built, 2)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *Config { return &Config{} })
+		c.RequireProvide(func(*Config) (*DB, error) { return nil, errors.New("great sadness") })
+
+		report, err := c.Warmup(new(*DB))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot warm up the container at")
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Equal(t, []string{"*dig_test.DB", "*dig_test.Config"}, names(report))
+		assert.ErrorContains(t, report.Built[0].Error, "great sadness")
+		assert.NoError(t, report.Built[1].Error)
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := digtest.New(t).Warmup(new(*DB))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *dig_test.DB")
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		_, err := c.Warmup(DB{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't warm up {} (type dig_test.DB): keys must be pointers to types or dig.Key values")
+
+		_, err = c.Warmup(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "keys must be pointers to types")
+
+		_, err = c.Warmup(dig.Key{Name: "primary"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "keys must be pointers to types")
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/alexisvisco/dig/internal/dot"
+)
+
+// With is an InvokeOption that supplies values for the dependencies of the
+// invoked function, for this Invoke only.
+//
+//	err := c.Invoke(func(cfg *Config, db *sql.DB) {
+//	  ..
+//	}, dig.With(testConfig))
+//
+// Supplied values take precedence over the constructors and values of the
+// container for the parameters of the function, including the fields of its
+// dig.In parameter objects. They are matched to the parameters by their
+// exact type and are never given to named parameters. Supplied values are
+// not added to the container: the constructors that the function depends on
+// still receive the values of the container.
+//
+// Invoke fails if two supplied values have the same type, or if a supplied
+// value does not match any parameter of the function.
+func With(values ...interface{}) InvokeOption {
+	return withOption(values)
+}
+
+type withOption []interface{}
+
+func (o withOption) String() string {
+	buf := bytes.NewBufferString("With(")
+	for i, v := range o {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprint(buf, reflect.TypeOf(v))
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func (o withOption) applyInvokeOption(opts *invokeOptions) {
+	opts.With = append(opts.With, o...)
+}
+
+// paramSupplied is a paramSingle whose value was supplied with With.
+type paramSupplied struct {
+	paramSingle
+
+	Value reflect.Value
+}
+
+var _ param = paramSupplied{}
+
+// DotParam reports no dependency: supplied parameters are not looked up in
+// the container.
+func (ps paramSupplied) DotParam() []*dot.Param {
+	return nil
+}
+
+func (ps paramSupplied) Build(containerStore) (reflect.Value, error) {
+	return ps.Value, nil
+}
+
+// supplyValues returns a copy of the paramList where the parameters matching
+// the given values are replaced by these values.
+func (pl paramList) supplyValues(values []interface{}) (paramList, error) {
+	supplied := make(map[reflect.Type]reflect.Value, len(values))
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		if t == nil {
+			return pl, newErrInvalidInput("cannot supply an untyped nil with dig.With", nil)
+		}
+		if _, ok := supplied[t]; ok {
+			return pl, newErrInvalidInput(
+				fmt.Sprintf("cannot supply more than one value of type %v with dig.With", t), nil)
+		}
+		supplied[t] = reflect.ValueOf(v)
+	}
+
+	pl, used := pl.supply(supplied)
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		if _, ok := used[t]; !ok {
+			return pl, newErrInvalidInput(
+				fmt.Sprintf("value of type %v supplied with dig.With is not a dependency of the function", t), nil)
+		}
+	}
+	return pl, nil
+}
+
+// supply returns a copy of the paramList where the unnamed parameters of
+// the types of supplied are replaced by their values, along with the types
+// that matched a parameter.
+func (pl paramList) supply(supplied map[reflect.Type]reflect.Value) (paramList, map[reflect.Type]struct{}) {
+	used := make(map[reflect.Type]struct{}, len(supplied))
+	params := make([]param, len(pl.Params))
+	for i, p := range pl.Params {
+		params[i] = supplyParam(p, supplied, used)
+	}
+	pl.Params = params
+	return pl, used
+}
+
+func supplyParam(p param, supplied map[reflect.Type]reflect.Value, used map[reflect.Type]struct{}) param {
+	switch p := p.(type) {
+	case paramSingle:
+		if v, ok := supplied[p.Type]; ok && p.Name == "" {
+			used[p.Type] = struct{}{}
+			return paramSupplied{paramSingle: p, Value: v}
+		}
+	case paramObject:
+		fields := make([]paramObjectField, len(p.Fields))
+		for i, f := range p.Fields {
+			f.Param = supplyParam(f.Param, supplied, used)
+			fields[i] = f
+		}
+		p.Fields = fields
+		return p
+	}
+	return p
+}
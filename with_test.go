@@ -0,0 +1,122 @@
+// Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alexisvisco/dig"
+	"github.com/alexisvisco/dig/digtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWith(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ name string }
+	type B struct{ a *A }
+
+	t.Run("supplied values take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "provided"} })
+
+		require.NoError(t, c.Invoke(func(a *A) {
+			assert.Equal(t, "supplied", a.name)
+		}, dig.With(&A{name: "supplied"})))
+
+		c.RequireInvoke(func(a *A) {
+			assert.Equal(t, "provided", a.name, "supplied value must not be added to the container")
+		})
+	})
+
+	t.Run("missing dependencies may be supplied", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		c.RequireProvide(func(a *A) *B { return &B{a: a} })
+
+		require.NoError(t, c.Invoke(func(b *B, s string) {
+			assert.Equal(t, "b", s)
+		}, dig.With(&B{}, "b")))
+
+		err := c.Invoke(func(*B) {})
+		require.Error(t, err, "constructors must not receive supplied values")
+		assert.Contains(t, err.Error(), "missing type: *dig_test.A")
+	})
+
+	t.Run("fields of parameter objects", func(t *testing.T) {
+		t.Parallel()
+
+		type params struct {
+			dig.In
+
+			A     *A
+			Named *A  `name:"named"`
+			N     int `optional:"true"`
+		}
+
+		c := digtest.New(t)
+		c.RequireProvide(func() *A { return &A{name: "named"} }, dig.Name("named"))
+
+		require.NoError(t, c.Invoke(func(p params) {
+			assert.Equal(t, "supplied", p.A.name)
+			assert.Equal(t, "named", p.Named.name, "named parameters must not be supplied")
+			assert.Equal(t, 42, p.N)
+		}, dig.With(&A{name: "supplied"}), dig.With(42)))
+	})
+
+	t.Run("duplicate types", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(*A) {}, dig.With(&A{}, &A{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot supply more than one value of type *dig_test.A with dig.With")
+	})
+
+	t.Run("untyped nil", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(*A) {}, dig.With(nil))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot supply an untyped nil with dig.With")
+	})
+
+	t.Run("unused value", func(t *testing.T) {
+		t.Parallel()
+
+		c := digtest.New(t)
+		err := c.Invoke(func(*A) {}, dig.With(&A{}, "unused"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "value of type string supplied with dig.With is not a dependency of the function")
+	})
+}
+
+func TestWithString(t *testing.T) {
+	type A struct{}
+
+	assert.Equal(t, "With(string, *dig_test.A)", fmt.Sprint(dig.With("a", &A{})))
+}